@@ -26,13 +26,28 @@ func runActivate(args []string) error {
 		logger.Debug("activating shell", "args", args)
 	}
 
+	// Parse flags and the shell name
+	var positional []string
+	for _, arg := range args {
+		switch {
+		case arg == "--with-dir-hooks":
+			// The activation script already re-applies directory-local tool
+			// versions, removing those of the directory left, whenever the
+			// directory changes; the flag is accepted for RC files using it
+		case strings.HasPrefix(arg, "-"):
+			return fmt.Errorf("unknown option: %s\nusage: zerb activate <shell>", arg)
+		default:
+			positional = append(positional, arg)
+		}
+	}
+
 	// Validate arguments
-	if len(args) < 1 {
-		return fmt.Errorf("usage: zerb activate <shell>\nSupported shells: bash, zsh, fish")
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: zerb activate <shell>\nSupported shells: bash, zsh, fish, nu, elvish, pwsh")
 	}
 
 	// Parse shell type
-	shellName := args[0]
+	shellName := positional[0]
 	logger.Debug("parsed shell type", "shell", shellName)
 	var shellType shell.ShellType
 	switch shellName {
@@ -42,8 +57,14 @@ func runActivate(args []string) error {
 		shellType = shell.ShellZsh
 	case "fish":
 		shellType = shell.ShellFish
+	case "nu":
+		shellType = shell.ShellNushell
+	case "elvish":
+		shellType = shell.ShellElvish
+	case "pwsh":
+		shellType = shell.ShellPwsh
 	default:
-		return fmt.Errorf("unsupported shell: %s\nSupported shells: bash, zsh, fish", shellName)
+		return fmt.Errorf("unsupported shell: %s\nSupported shells: bash, zsh, fish, nu, elvish, pwsh", shellName)
 	}
 
 	// Validate shell type
@@ -56,6 +77,9 @@ func runActivate(args []string) error {
 	if err != nil {
 		return fmt.Errorf("get ZERB directory: %w", err)
 	}
+	if err := ensureLayout(zerbDir); err != nil {
+		return err
+	}
 	logger.Debug("using ZERB directory", "dir", zerbDir)
 
 	// Validate ZERB directory path (security: prevent command injection)
@@ -75,6 +99,11 @@ func runActivate(args []string) error {
 		return fmt.Errorf("failed to access ZERB environment: %w", err)
 	}
 
+	// Refuse to run core components that were tampered with
+	if err := quickVerifyBinaries(ctx, zerbDir); err != nil {
+		return err
+	}
+
 	// Check for .zerb-no-git marker and warn if git is not initialized
 	noGitMarkerPath := filepath.Join(zerbDir, ".zerb-no-git")
 	if _, err := os.Stat(noGitMarkerPath); err == nil {
@@ -129,8 +158,17 @@ func runActivate(args []string) error {
 
 	logger.Debug("activation successful", "output_length", len(output))
 
-	// Write activation output to stdout (this is what the shell will eval)
+	// Write activation output to stdout (this is what the shell will eval),
+	// followed by the line marking the shell as activated
+	marker, err := shell.GenerateActiveMarker(shellType)
+	if err != nil {
+		return fmt.Errorf("prepare shell activation: %w", err)
+	}
 	fmt.Print(string(output))
+	if len(output) > 0 && output[len(output)-1] != '\n' {
+		fmt.Println()
+	}
+	fmt.Println(marker)
 
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/ZebulonRouseFrantzich/zerb/internal/chezmoi"
@@ -12,11 +13,14 @@ import (
 	"github.com/ZebulonRouseFrantzich/zerb/internal/service"
 )
 
-// runConfigAdd handles the `zerb config add` subcommand
-func runConfigAdd(args []string) error {
+// runConfigAdd handles the `zerb config add` subcommand and returns what
+// was added. The result is empty when only help was shown.
+func runConfigAdd(args []string) (*service.AddResult, error) {
 	// Parse flags and paths
 	showHelp := false
 	dryRun := false
+	noCommit := false
+	forceUnlock := false
 
 	// Default options
 	globalOpts := service.ConfigOptions{
@@ -27,6 +31,8 @@ func runConfigAdd(args []string) error {
 	}
 
 	var paths []string
+	target := ""
+	var timeout time.Duration
 
 	for i := 0; i < len(args); i++ {
 		arg := args[i]
@@ -35,57 +41,123 @@ func runConfigAdd(args []string) error {
 			showHelp = true
 		case "--dry-run", "-n":
 			dryRun = true
+		case "--no-commit":
+			noCommit = true
+		case "--force-unlock":
+			forceUnlock = true
 		case "--recursive", "-r":
 			globalOpts.Recursive = true
 		case "--template", "-t":
 			globalOpts.Template = true
+			globalOpts.Explicit = append(globalOpts.Explicit, service.FlagTemplate)
+		case "--no-template":
+			globalOpts.Template = false
+			globalOpts.Explicit = append(globalOpts.Explicit, service.FlagTemplate)
 		case "--secrets", "-s":
 			globalOpts.Secrets = true
+			globalOpts.Explicit = append(globalOpts.Explicit, service.FlagSecrets)
+		case "--no-secrets":
+			globalOpts.Secrets = false
+			globalOpts.Explicit = append(globalOpts.Explicit, service.FlagSecrets)
 		case "--private", "-p":
 			globalOpts.Private = true
+			globalOpts.Explicit = append(globalOpts.Explicit, service.FlagPrivate)
+		case "--no-private":
+			globalOpts.Private = false
+			globalOpts.Explicit = append(globalOpts.Explicit, service.FlagPrivate)
+		case "--follow-symlinks":
+			globalOpts.FollowSymlinks = true
+		case "--as":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("--as requires a target path\nRun 'zerb config add --help' for usage")
+			}
+			i++
+			target = args[i]
+		case "--timeout":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("--timeout requires a duration\nRun 'zerb config add --help' for usage")
+			}
+			i++
+			d, err := parseAddTimeout(args[i])
+			if err != nil {
+				return nil, err
+			}
+			timeout = d
 		default:
+			if strings.HasPrefix(arg, "--as=") {
+				target = strings.TrimPrefix(arg, "--as=")
+				continue
+			}
+			if strings.HasPrefix(arg, "--timeout=") {
+				d, err := parseAddTimeout(strings.TrimPrefix(arg, "--timeout="))
+				if err != nil {
+					return nil, err
+				}
+				timeout = d
+				continue
+			}
 			// Anything not starting with - is a path
 			if len(arg) > 0 && arg[0] != '-' {
 				paths = append(paths, arg)
 			} else {
-				return fmt.Errorf("unknown option: %s\nRun 'zerb config add --help' for usage", arg)
+				return nil, fmt.Errorf("unknown option: %s\nRun 'zerb config add --help' for usage", arg)
 			}
 		}
 	}
 
 	if showHelp {
 		printConfigAddHelp()
-		return nil
+		return &service.AddResult{}, nil
 	}
 
 	if len(paths) == 0 {
-		return fmt.Errorf("no paths specified; run 'zerb config add --help' for usage")
+		return nil, fmt.Errorf("no paths specified; run 'zerb config add --help' for usage")
+	}
+	if target != "" {
+		if len(paths) > 1 {
+			return nil, fmt.Errorf("--as applies to a single path, got %d", len(paths))
+		}
+		globalOpts.Target = target
 	}
 
-	// Create context with timeout (2 minutes for potentially large directories)
+	// Create context with timeout (2 minutes for potentially large
+	// directories). With --timeout each path is bounded instead, so a
+	// longer timeout is not cut short.
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	if timeout > 0 {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
 	defer cancel()
 
 	// Get ZERB directory
 	zerbDir, err := getZerbDir()
 	if err != nil {
-		return fmt.Errorf("get ZERB directory: %w", err)
+		return nil, fmt.Errorf("get ZERB directory: %w", err)
+	}
+	if err := ensureLayout(zerbDir); err != nil {
+		return nil, err
 	}
 
 	// Check if ZERB is initialized
 	if _, err := os.Stat(zerbDir); err != nil {
 		if os.IsNotExist(err) {
-			return fmt.Errorf("ZERB not initialized\nRun 'zerb init' to set up ZERB first")
+			return nil, fmt.Errorf("ZERB not initialized\nRun 'zerb init' to set up ZERB first")
+		}
+		return nil, fmt.Errorf("check ZERB directory: %w", err)
+	}
+
+	if !dryRun {
+		if err := applyEncryptionConfig(ctx, zerbDir); err != nil {
+			return nil, err
 		}
-		return fmt.Errorf("check ZERB directory: %w", err)
 	}
 
 	// Create dependencies
 	chezmoiClient := chezmoi.NewClient(zerbDir)
 	gitClient := git.NewClient(zerbDir)
 	parser := config.NewParser(nil)
-	generator := config.NewGenerator()
 	clock := service.RealClock{}
+	generator := config.NewGenerator().WithClock(clock)
 
 	// Create service
 	svc := service.NewConfigAddService(
@@ -95,7 +167,7 @@ func runConfigAdd(args []string) error {
 		generator,
 		clock,
 		zerbDir,
-	)
+	).WithAddTimeouts(timeout, timeout)
 
 	// Build options map (apply global options to all paths)
 	optionsMap := make(map[string]service.ConfigOptions)
@@ -105,17 +177,34 @@ func runConfigAdd(args []string) error {
 
 	// Execute
 	req := service.AddRequest{
-		Paths:   paths,
-		Options: optionsMap,
-		DryRun:  dryRun,
+		Paths:       paths,
+		Options:     optionsMap,
+		DryRun:      dryRun,
+		NoCommit:    noCommit,
+		ForceUnlock: forceUnlock,
 	}
 
 	result, err := svc.Execute(ctx, req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Print results
+	printConfigAddResult(result, dryRun)
+	return result, nil
+}
+
+// parseAddTimeout parses a --timeout value: a positive duration such as
+// 30s or 5m
+func parseAddTimeout(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid --timeout %q: must be a positive duration such as 30s or 5m", value)
+	}
+	return d, nil
+}
+
+// printConfigAddResult prints what config add did (or, with dryRun, would do)
+func printConfigAddResult(result *service.AddResult, dryRun bool) {
 	if dryRun {
 		fmt.Println("Dry run - no changes made")
 		fmt.Println()
@@ -142,15 +231,15 @@ func runConfigAdd(args []string) error {
 
 	if !dryRun && len(result.AddedPaths) > 0 {
 		fmt.Println()
-		if result.CommitHash != "" {
+		if result.Uncommitted {
+			fmt.Println("Changes staged but not committed (review with 'git status' in the ZERB directory)")
+		} else if result.CommitHash != "" {
 			fmt.Printf("Committed: %s\n", result.CommitHash[:8])
 		}
 		if result.ConfigVersion != "" {
 			fmt.Printf("Config version: %s\n", result.ConfigVersion)
 		}
 	}
-
-	return nil
 }
 
 // printConfigAddHelp prints help for the config add command
@@ -162,10 +251,24 @@ func printConfigAddHelp() {
 	fmt.Println("Options:")
 	fmt.Println("  -h, --help       Show this help message")
 	fmt.Println("  -n, --dry-run    Show what would be added without making changes")
+	fmt.Println("      --no-commit  Stage changes in git but do not commit them")
 	fmt.Println("  -r, --recursive  Add directory and all contents recursively")
 	fmt.Println("  -t, --template   Enable template processing (for dynamic configs)")
 	fmt.Println("  -s, --secrets    Encrypt file with GPG (for sensitive data)")
 	fmt.Println("  -p, --private    Set file permissions to 600 (user-only access)")
+	fmt.Println("      --no-template, --no-secrets, --no-private")
+	fmt.Println("                   Turn off a flag the config's defaults turn on")
+	fmt.Println("      --as <path>  Apply the file at <path> instead (must be within home)")
+	fmt.Println("      --follow-symlinks")
+	fmt.Println("                   Track the file a symlink points to, under its real")
+	fmt.Println("                   path, instead of the link itself")
+	fmt.Println("      --timeout <d>")
+	fmt.Println("                   Give up on a path after <d> (e.g. 5m; default 30s")
+	fmt.Println("                   for a file, 90s for a directory with --recursive)")
+	fmt.Println("      --force-unlock")
+	fmt.Println("                   Remove the lock of another zerb command first; use")
+	fmt.Println("                   only when none is running but the lock is reported")
+	fmt.Println("                   as held")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  zerb config add ~/.zshrc              Add shell config")
@@ -174,12 +277,17 @@ func printConfigAddHelp() {
 	fmt.Println("  zerb config add ~/.ssh/config -p      Add SSH config as private")
 	fmt.Println("  zerb config add ~/.env -s             Add env file as encrypted")
 	fmt.Println("  zerb config add --dry-run ~/.bashrc   Preview without changes")
+	fmt.Println("  zerb config add ~/dotfiles/work.gitconfig --as ~/.gitconfig")
+	fmt.Println("                                        Apply a file to another location")
 	fmt.Println()
 	fmt.Println("Notes:")
 	fmt.Println("  - Paths are normalized (~ is expanded to home directory)")
 	fmt.Println("  - Directories require --recursive flag")
+	fmt.Println("  - Symlinks are tracked as links unless --follow-symlinks is given")
 	fmt.Println("  - Already-tracked files are skipped")
-	fmt.Println("  - Changes are committed to git automatically")
+	fmt.Println("  - Template, secrets and private default to the config's")
+	fmt.Println("    config.defaults block, e.g. defaults = { private = true }")
+	fmt.Println("  - Changes are committed to git automatically (unless --no-commit)")
 	fmt.Println()
 	os.Exit(0)
 }
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ZebulonRouseFrantzich/zerb/internal/service"
 )
@@ -178,7 +180,7 @@ func TestRunConfigAdd_ParseFlags(t *testing.T) {
 }
 
 func TestRunConfigAdd_NoPaths(t *testing.T) {
-	err := runConfigAdd([]string{})
+	_, err := runConfigAdd([]string{})
 	if err == nil {
 		t.Error("expected error for no paths, got nil")
 	}
@@ -190,12 +192,38 @@ func TestRunConfigAdd_NoPaths(t *testing.T) {
 }
 
 func TestRunConfigAdd_UnknownFlag(t *testing.T) {
-	err := runConfigAdd([]string{"--invalid-flag"})
+	_, err := runConfigAdd([]string{"--invalid-flag"})
 	if err == nil {
 		t.Error("expected error for unknown flag, got nil")
 	}
 }
 
+func TestRunConfigAdd_InvalidTimeout(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "missing value", args: []string{"--timeout"}},
+		{name: "not a duration", args: []string{"--timeout", "soon", "~/.zshrc"}},
+		{name: "zero", args: []string{"--timeout=0s", "~/.zshrc"}},
+		{name: "negative", args: []string{"--timeout=-1m", "~/.zshrc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := runConfigAdd(tt.args); err == nil || !strings.Contains(err.Error(), "--timeout") {
+				t.Errorf("runConfigAdd(%v) error = %v, want a --timeout error", tt.args, err)
+			}
+		})
+	}
+}
+
+func TestParseAddTimeout(t *testing.T) {
+	if got, err := parseAddTimeout("5m"); err != nil || got != 5*time.Minute {
+		t.Errorf("parseAddTimeout(\"5m\") = %v, %v; want 5m", got, err)
+	}
+}
+
 func TestRunConfigAdd_NotInitialized(t *testing.T) {
 	// Set up a temporary directory without ZERB initialization
 	tmpDir := t.TempDir()
@@ -203,7 +231,7 @@ func TestRunConfigAdd_NotInitialized(t *testing.T) {
 
 	// Running config add without initialization should fail
 	// (after the path check, it will fail trying to access the ZERB directory)
-	err := runConfigAdd([]string{"~/.zshrc"})
+	_, err := runConfigAdd([]string{"~/.zshrc"})
 	if err == nil {
 		t.Error("expected error for uninitialized ZERB, got nil")
 	}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+)
+
+// activeVersionAlias refers to the config version named in .zerb-active
+const activeVersionAlias = "active"
+
+// errConfigVersionNotFound means no snapshot in configs/ matches a version reference
+var errConfigVersionNotFound = errors.New("config version not found")
+
+// ANSI escape sequences used for colorized diff output
+const (
+	colorRed   = "\033[31m"
+	colorGreen = "\033[32m"
+	colorReset = "\033[0m"
+)
+
+// runConfigDiff handles the `zerb config diff` subcommand
+func runConfigDiff(args []string) error {
+	// Parse flags
+	showHelp := false
+	jsonOutput := false
+	var versions []string
+
+	for _, arg := range args {
+		switch arg {
+		case "--help", "-h":
+			showHelp = true
+		case "--json":
+			jsonOutput = true
+		default:
+			if strings.HasPrefix(arg, "-") {
+				return fmt.Errorf("unknown option: %s\nRun 'zerb config diff --help' for usage", arg)
+			}
+			versions = append(versions, arg)
+		}
+	}
+
+	if showHelp {
+		printConfigDiffHelp()
+		return nil
+	}
+
+	if len(versions) != 2 {
+		return fmt.Errorf("expected two config versions, got %d\nUsage: zerb config diff <version-a> <version-b>", len(versions))
+	}
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Get ZERB directory
+	zerbDir, err := getZerbDir()
+	if err != nil {
+		return fmt.Errorf("get ZERB directory: %w", err)
+	}
+	if err := ensureLayout(zerbDir); err != nil {
+		return err
+	}
+
+	// Check if ZERB is initialized
+	if _, err := os.Stat(filepath.Join(zerbDir, "configs")); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("ZERB not initialized\nRun 'zerb init' to set up ZERB first")
+		}
+		return fmt.Errorf("check ZERB directory: %w", err)
+	}
+
+	before, err := loadConfigVersion(ctx, zerbDir, versions[0])
+	if err != nil {
+		return err
+	}
+	after, err := loadConfigVersion(ctx, zerbDir, versions[1])
+	if err != nil {
+		return err
+	}
+
+	diff := config.DiffConfigs(before, after)
+
+	if jsonOutput {
+		return writeConfigDiffJSON(os.Stdout, diff)
+	}
+
+	printConfigDiff(os.Stdout, diff, useColor(os.Stdout))
+	return nil
+}
+
+// loadConfigVersion resolves a version reference and parses the snapshot it names
+func loadConfigVersion(ctx context.Context, zerbDir, ref string) (*config.Config, error) {
+	filename, err := resolveConfigVersion(zerbDir, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(zerbDir, "configs", filename))
+	if err != nil {
+		return nil, fmt.Errorf("read config version %s: %w", filename, err)
+	}
+
+	cfg, err := config.NewParser(nil).ParseString(ctx, string(data))
+	if err != nil {
+		return nil, fmt.Errorf("config version %s is invalid: %s", filename, config.FormatError(err, false))
+	}
+
+	return cfg, nil
+}
+
+// resolveConfigVersion maps a user-supplied version reference to a snapshot
+// filename in configs/. It accepts the "active" alias, full filenames, and
+// unambiguous prefixes of the timestamp (with or without the "zerb." prefix).
+func resolveConfigVersion(zerbDir, ref string) (string, error) {
+	if ref == activeVersionAlias {
+		data, err := os.ReadFile(filepath.Join(zerbDir, ".zerb-active"))
+		if err != nil {
+			return "", fmt.Errorf("read active config marker: %w", err)
+		}
+		filename := strings.TrimSpace(string(data))
+		if filename == "" || filename != filepath.Base(filename) {
+			return "", fmt.Errorf("active config marker is invalid: %q", filename)
+		}
+		return filename, nil
+	}
+
+	entries, err := os.ReadDir(filepath.Join(zerbDir, "configs"))
+	if err != nil {
+		return "", fmt.Errorf("read configs directory: %w", err)
+	}
+
+	want := trimVersionAffixes(ref)
+	var matches []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "zerb.") || !strings.HasSuffix(name, ".lua") {
+			continue
+		}
+		version := trimVersionAffixes(name)
+		if version == want {
+			return name, nil
+		}
+		if strings.HasPrefix(version, want) {
+			matches = append(matches, name)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("%w: %s", errConfigVersionNotFound, ref)
+	case 1:
+		return matches[0], nil
+	default:
+		sort.Strings(matches)
+		return "", fmt.Errorf("config version %q is ambiguous (matches %d versions: %s)", ref, len(matches), strings.Join(matches, ", "))
+	}
+}
+
+// trimVersionAffixes strips the "zerb." prefix and ".lua" suffix from a version reference
+func trimVersionAffixes(ref string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(ref, "zerb."), ".lua")
+}
+
+// writeConfigDiffJSON writes the diff as indented JSON
+func writeConfigDiffJSON(w io.Writer, diff *config.Diff) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(diff); err != nil {
+		return fmt.Errorf("encode diff: %w", err)
+	}
+	return nil
+}
+
+// printConfigDiff prints the diff grouped by section
+func printConfigDiff(w io.Writer, diff *config.Diff, color bool) {
+	if diff.IsEmpty() {
+		fmt.Fprintln(w, "No differences.")
+		return
+	}
+
+	added := func(format string, a ...interface{}) {
+		fmt.Fprintln(w, colorize("  + "+fmt.Sprintf(format, a...), colorGreen, color))
+	}
+	removed := func(format string, a ...interface{}) {
+		fmt.Fprintln(w, colorize("  - "+fmt.Sprintf(format, a...), colorRed, color))
+	}
+
+	sections := 0
+	section := func(title string) {
+		if sections > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s:\n", title)
+		sections++
+	}
+
+	// tools prints tool changes, each line starting with prefix
+	tools := func(prefix string, tools config.ToolsDiff) {
+		for _, tool := range tools.Removed {
+			removed("%s%s", prefix, tool)
+		}
+		for _, tool := range tools.Added {
+			added("%s%s", prefix, tool)
+		}
+		for _, change := range tools.Changed {
+			removed("%s%s@%s", prefix, change.Name, change.From)
+			added("%s%s@%s", prefix, change.Name, change.To)
+		}
+	}
+	// fields prints field changes, each line starting with prefix
+	fields := func(prefix string, changes []config.FieldChange) {
+		for _, change := range changes {
+			if change.From != "" {
+				removed("%s%s = %s", prefix, change.Field, change.From)
+			}
+			if change.To != "" {
+				added("%s%s = %s", prefix, change.Field, change.To)
+			}
+		}
+	}
+
+	if len(diff.Tools.Added)+len(diff.Tools.Removed)+len(diff.Tools.Changed) > 0 {
+		section("Tools")
+		tools("", diff.Tools)
+	}
+
+	if len(diff.Profiles) > 0 {
+		section("Profiles")
+		for _, profile := range diff.Profiles {
+			tools(profile.Name+": ", profile.Tools)
+		}
+	}
+
+	if len(diff.Backends.Added)+len(diff.Backends.Removed) > 0 {
+		section("Backends")
+		for _, backend := range diff.Backends.Removed {
+			removed("%s", backend)
+		}
+		for _, backend := range diff.Backends.Added {
+			added("%s", backend)
+		}
+	}
+
+	if len(diff.Configs.Added)+len(diff.Configs.Removed)+len(diff.Configs.Changed) > 0 {
+		section("Configs")
+		for _, path := range diff.Configs.Removed {
+			removed("%s", path)
+		}
+		for _, path := range diff.Configs.Added {
+			added("%s", path)
+		}
+		for _, change := range diff.Configs.Changed {
+			fields(change.Path+" ", change.Fields)
+		}
+	}
+
+	for _, group := range []struct {
+		title   string
+		changes []config.FieldChange
+	}{
+		{"Version probes", diff.VersionProbes},
+		{"Encryption", diff.Encryption},
+		{"Git", diff.Git},
+		{"Options", diff.Options},
+	} {
+		if len(group.changes) == 0 {
+			continue
+		}
+		section(group.title)
+		fields("", group.changes)
+	}
+}
+
+// colorize wraps s in an ANSI color sequence when enabled
+func colorize(s, color string, enabled bool) string {
+	if !enabled {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// useColor reports whether colorized output should be written to f.
+// Color is disabled when NO_COLOR is set or f is not a terminal.
+func useColor(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// printConfigDiffHelp prints help for the config diff command
+func printConfigDiffHelp() {
+	fmt.Println("Usage: zerb config diff [options] <version-a> <version-b>")
+	fmt.Println()
+	fmt.Println("Compare two config versions and show what changed from a to b.")
+	fmt.Println()
+	fmt.Println("Versions can be:")
+	fmt.Println("  active                          The currently active config")
+	fmt.Println("  zerb.20250115T103000.000Z.lua   A full config filename")
+	fmt.Println("  20250115T1030                   An unambiguous timestamp prefix")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -h, --help    Show this help message")
+	fmt.Println("  --json        Output the diff as JSON")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  zerb config diff 20250115 active     Compare a past version with the active one")
+	fmt.Println("  zerb config diff --json 20250115T1030 active")
+	fmt.Println()
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/chezmoi"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/service"
+)
+
+// runConfigDiffFile handles the `zerb config diff-file` subcommand
+func runConfigDiffFile(args []string) error {
+	// Parse flags
+	showHelp := false
+	var paths []string
+
+	for _, arg := range args {
+		switch arg {
+		case "--help", "-h":
+			showHelp = true
+		default:
+			if strings.HasPrefix(arg, "-") {
+				return fmt.Errorf("unknown option: %s\nRun 'zerb config diff-file --help' for usage", arg)
+			}
+			paths = append(paths, arg)
+		}
+	}
+
+	if showHelp {
+		printConfigDiffFileHelp()
+		return nil
+	}
+
+	if len(paths) != 1 {
+		return fmt.Errorf("expected one config path, got %d\nUsage: zerb config diff-file <path>", len(paths))
+	}
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	// Get ZERB directory
+	zerbDir, err := getZerbDir()
+	if err != nil {
+		return fmt.Errorf("get ZERB directory: %w", err)
+	}
+	if err := ensureLayout(zerbDir); err != nil {
+		return err
+	}
+
+	if err := applyEncryptionConfig(ctx, zerbDir); err != nil {
+		return err
+	}
+
+	svc := service.NewConfigDiffFileService(chezmoi.NewClient(zerbDir), config.NewParser(nil), zerbDir)
+	result, err := svc.DiffFile(ctx, paths[0])
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotInitialized):
+			return fmt.Errorf("ZERB not initialized\nRun 'zerb init' to set up ZERB first")
+		case errors.Is(err, service.ErrNotTracked):
+			return fmt.Errorf("%s is not tracked\nRun 'zerb config add %s' to track it", paths[0], paths[0])
+		}
+		return err
+	}
+
+	printConfigDiffFile(os.Stdout, result)
+	return nil
+}
+
+// printConfigDiffFile prints how a tracked file on disk differs from its tracked content
+func printConfigDiffFile(w io.Writer, result *service.DiffFileResult) {
+	if result.Identical() {
+		fmt.Fprintf(w, "✓ %s matches its tracked content.\n", result.Path)
+		return
+	}
+	if result.Config != result.Path {
+		fmt.Fprintf(w, "Tracked by %s\n", result.Config)
+	}
+	fmt.Fprint(w, result.Diff)
+}
+
+// printConfigDiffFileHelp prints help for the config diff-file command
+func printConfigDiffFileHelp() {
+	fmt.Println("Usage: zerb config diff-file [options] <path>")
+	fmt.Println()
+	fmt.Println("Show how a tracked file on disk differs from its tracked content,")
+	fmt.Println("as a unified diff. Lines starting with '-' are the tracked content;")
+	fmt.Println("lines starting with '+' are what is on disk.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -h, --help    Show this help message")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  zerb config diff-file ~/.zshrc")
+	fmt.Println("  zerb config diff-file ~/.config/nvim/init.lua")
+	fmt.Println()
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/service"
+)
+
+func TestRunConfigDiffFile_InvalidArgs(t *testing.T) {
+	for _, args := range [][]string{{}, {"--bogus", "~/.zshrc"}, {"~/.zshrc", "~/.vimrc"}} {
+		if err := runConfigDiffFile(args); err == nil {
+			t.Errorf("runConfigDiffFile(%v) expected error, got nil", args)
+		}
+	}
+}
+
+func TestPrintConfigDiffFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *service.DiffFileResult
+		want   []string
+	}{
+		{
+			name:   "identical",
+			result: &service.DiffFileResult{Path: "~/.zshrc", Config: "~/.zshrc"},
+			want:   []string{"~/.zshrc matches its tracked content."},
+		},
+		{
+			name: "modified inside a tracked directory",
+			result: &service.DiffFileResult{
+				Path:   "~/.config/nvim/init.lua",
+				Config: "~/.config/nvim",
+				Diff:   "--- a/init.lua\n+++ b/init.lua\n@@ -1 +1 @@\n-set number\n+set relativenumber\n",
+			},
+			want: []string{"Tracked by ~/.config/nvim", "-set number\n+set relativenumber\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			printConfigDiffFile(&buf, tt.result)
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
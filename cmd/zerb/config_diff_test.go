@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+)
+
+// setupDiffZerbDir creates a ZERB directory with the given config snapshots.
+// active is written to the .zerb-active marker.
+func setupDiffZerbDir(t *testing.T, snapshots map[string]string, active string) string {
+	t.Helper()
+
+	zerbDir := t.TempDir()
+	configsDir := filepath.Join(zerbDir, "configs")
+	if err := os.MkdirAll(configsDir, 0755); err != nil {
+		t.Fatalf("failed to create configs dir: %v", err)
+	}
+	for name, content := range snapshots {
+		if err := os.WriteFile(filepath.Join(configsDir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(zerbDir, ".zerb-active"), []byte(active+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write active marker: %v", err)
+	}
+
+	return zerbDir
+}
+
+func TestResolveConfigVersion(t *testing.T) {
+	zerbDir := setupDiffZerbDir(t, map[string]string{
+		"zerb.20250115T103000.000Z.lua": "zerb = {}",
+		"zerb.20250115T113000.000Z.lua": "zerb = {}",
+		"zerb.20250201T090000.000Z.lua": "zerb = {}",
+	}, "zerb.20250201T090000.000Z.lua")
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr string
+	}{
+		{name: "active alias", ref: "active", want: "zerb.20250201T090000.000Z.lua"},
+		{name: "full filename", ref: "zerb.20250115T103000.000Z.lua", want: "zerb.20250115T103000.000Z.lua"},
+		{name: "timestamp without affixes", ref: "20250115T113000.000Z", want: "zerb.20250115T113000.000Z.lua"},
+		{name: "unique prefix", ref: "202502", want: "zerb.20250201T090000.000Z.lua"},
+		{name: "prefix with zerb", ref: "zerb.20250115T10", want: "zerb.20250115T103000.000Z.lua"},
+		{name: "ambiguous prefix", ref: "20250115", wantErr: "ambiguous"},
+		{name: "not found", ref: "2024", wantErr: "not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveConfigVersion(zerbDir, tt.ref)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("resolveConfigVersion(%q) error = %v, want error containing %q", tt.ref, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveConfigVersion(%q) error = %v", tt.ref, err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveConfigVersion(%q) = %q, want %q", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunConfigDiff_Unparseable(t *testing.T) {
+	zerbDir := setupDiffZerbDir(t, map[string]string{
+		"zerb.20250115T103000.000Z.lua": "zerb = {}",
+		"zerb.20250116T103000.000Z.lua": "zerb = {",
+	}, "zerb.20250115T103000.000Z.lua")
+	t.Setenv("ZERB_DIR", zerbDir)
+
+	err := runConfigDiff([]string{"20250115", "20250116"})
+	if err == nil {
+		t.Fatal("expected error for unparseable config, got nil")
+	}
+	if !strings.Contains(err.Error(), "zerb.20250116T103000.000Z.lua") {
+		t.Errorf("error = %q, want it to name the invalid version", err.Error())
+	}
+	if strings.Contains(err.Error(), "goroutine") || strings.Contains(err.Error(), "stack traceback") {
+		t.Errorf("error exposes internals: %q", err.Error())
+	}
+}
+
+func TestRunConfigDiff_MissingVersion(t *testing.T) {
+	zerbDir := setupDiffZerbDir(t, map[string]string{
+		"zerb.20250115T103000.000Z.lua": "zerb = {}",
+	}, "zerb.20250115T103000.000Z.lua")
+	t.Setenv("ZERB_DIR", zerbDir)
+
+	if err := runConfigDiff([]string{"active", "2024"}); err == nil {
+		t.Error("expected error for missing version, got nil")
+	}
+}
+
+func TestRunConfigDiff_ArgCount(t *testing.T) {
+	if err := runConfigDiff([]string{"active"}); err == nil {
+		t.Error("expected error for a single version, got nil")
+	}
+	if err := runConfigDiff([]string{"--bogus", "a", "b"}); err == nil {
+		t.Error("expected error for unknown flag, got nil")
+	}
+}
+
+func TestPrintConfigDiff(t *testing.T) {
+	diff := config.DiffConfigs(
+		&config.Config{
+			Tools:    []string{"node@20.11.0", "python@3.12.1"},
+			Profiles: map[string][]string{"work": {"kubectl@1.29.0"}},
+			Configs:  []config.ConfigFile{{Path: "~/.vimrc"}, {Path: "~/.gitconfig"}},
+		},
+		&config.Config{
+			Tools:      []string{"node@22.1.0"},
+			Backends:   []string{"cargo"},
+			Configs:    []config.ConfigFile{{Path: "~/.zshrc"}, {Path: "~/.gitconfig", Template: true}},
+			Encryption: config.EncryptionConfig{Recipient: "user@example.com"},
+			Git:        config.GitConfig{Branch: "main"},
+		},
+	)
+
+	var buf bytes.Buffer
+	printConfigDiff(&buf, diff, false)
+	out := buf.String()
+
+	for _, want := range []string{
+		"Tools:",
+		"  - python@3.12.1",
+		"  - node@20.11.0",
+		"  + node@22.1.0",
+		"Profiles:",
+		"  - work: kubectl@1.29.0",
+		"Backends:",
+		"  + cargo",
+		"Configs:",
+		"  - ~/.vimrc",
+		"  + ~/.zshrc",
+		"  + ~/.gitconfig template = true",
+		"Encryption:",
+		"  + recipient = user@example.com",
+		"Git:",
+		"  + branch = main",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "\033[") {
+		t.Error("output contains color codes with color disabled")
+	}
+
+	buf.Reset()
+	printConfigDiff(&buf, diff, true)
+	if !strings.Contains(buf.String(), colorGreen+"  + node@22.1.0"+colorReset) {
+		t.Errorf("colorized output missing green addition:\n%q", buf.String())
+	}
+}
+
+func TestPrintConfigDiff_NoDifferences(t *testing.T) {
+	var buf bytes.Buffer
+	printConfigDiff(&buf, config.DiffConfigs(&config.Config{}, &config.Config{}), true)
+	if strings.TrimSpace(buf.String()) != "No differences." {
+		t.Errorf("output = %q, want %q", buf.String(), "No differences.")
+	}
+}
+
+func TestWriteConfigDiffJSON(t *testing.T) {
+	diff := config.DiffConfigs(
+		&config.Config{Tools: []string{"node@20.11.0"}},
+		&config.Config{Tools: []string{"node@22.1.0", "go@1.22.0"}},
+	)
+
+	var buf bytes.Buffer
+	if err := writeConfigDiffJSON(&buf, diff); err != nil {
+		t.Fatalf("writeConfigDiffJSON() error = %v", err)
+	}
+
+	var decoded config.Diff
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if len(decoded.Tools.Added) != 1 || decoded.Tools.Added[0] != "go@1.22.0" {
+		t.Errorf("Tools.Added = %v, want [go@1.22.0]", decoded.Tools.Added)
+	}
+	if len(decoded.Tools.Changed) != 1 || decoded.Tools.Changed[0].To != "22.1.0" {
+		t.Errorf("Tools.Changed = %+v, want node -> 22.1.0", decoded.Tools.Changed)
+	}
+}
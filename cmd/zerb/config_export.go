@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/fsutil"
+)
+
+// runConfigExport handles the `zerb config export` subcommand
+func runConfigExport(args []string) error {
+	// Parse flags
+	showHelp := false
+	version := activeVersionAlias
+	output := ""
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--help" || arg == "-h":
+			showHelp = true
+		case arg == "--version":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--version requires a config version\nRun 'zerb config export --help' for usage")
+			}
+			i++
+			version = args[i]
+		case strings.HasPrefix(arg, "--version="):
+			version = strings.TrimPrefix(arg, "--version=")
+		case arg == "--output" || arg == "-o":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a file path\nRun 'zerb config export --help' for usage", arg)
+			}
+			i++
+			output = args[i]
+		case strings.HasPrefix(arg, "--output="):
+			output = strings.TrimPrefix(arg, "--output=")
+		default:
+			return fmt.Errorf("unknown option: %s\nRun 'zerb config export --help' for usage", arg)
+		}
+	}
+
+	if showHelp {
+		printConfigExportHelp()
+		return nil
+	}
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Get ZERB directory
+	zerbDir, err := getZerbDir()
+	if err != nil {
+		return fmt.Errorf("get ZERB directory: %w", err)
+	}
+	if err := ensureLayout(zerbDir); err != nil {
+		return err
+	}
+
+	// Check if ZERB is initialized
+	if _, err := os.Stat(filepath.Join(zerbDir, ".zerb-active")); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("ZERB not initialized\nRun 'zerb init' to set up ZERB first")
+		}
+		return fmt.Errorf("check ZERB directory: %w", err)
+	}
+
+	cfg, err := loadConfigVersion(ctx, zerbDir, version)
+	if err != nil {
+		return err
+	}
+	data, err := config.ExportJSON(cfg)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := fsutil.WriteFileAtomic(output, data, 0644); err != nil {
+		return fmt.Errorf("write export: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "✓ Exported config to %s\n", output)
+	return nil
+}
+
+// printConfigExportHelp prints help for the config export command
+func printConfigExportHelp() {
+	fmt.Println("Usage: zerb config export [options]")
+	fmt.Println()
+	fmt.Println("Write the active config as JSON, to share with another machine and")
+	fmt.Println("compare there with 'zerb diff-env'.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -h, --help          Show this help message")
+	fmt.Println("  -o, --output <file> Write the export to <file> instead of stdout")
+	fmt.Println("  --version <v>       Export an earlier config version instead; accepts a")
+	fmt.Println("                      full filename or an unambiguous timestamp prefix")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  zerb config export -o laptop.json")
+	fmt.Println("  zerb config export --version 20250115T1030 > before.json")
+	fmt.Println()
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+)
+
+// TestConfigExport_DiffEnvRoundTrip exports the active config on one
+// machine and compares it with diff-env on another
+func TestConfigExport_DiffEnvRoundTrip(t *testing.T) {
+	laptop := setupDiffZerbDir(t, map[string]string{
+		"zerb.20250115T103000.000Z.lua": `zerb = {
+  tools = { "node@20.11.0", "python@3.12.1" },
+  configs = { "~/.zshrc" },
+}`,
+	}, "zerb.20250115T103000.000Z.lua")
+	t.Setenv("ZERB_DIR", laptop)
+
+	export := filepath.Join(t.TempDir(), "laptop.json")
+	if err := runConfigExport([]string{"-o", export}); err != nil {
+		t.Fatalf("runConfigExport() error = %v", err)
+	}
+
+	// Exporting to stdout writes the same file
+	var err error
+	stdout := captureStdout(t, func() { err = runConfigExport(nil) })
+	if err != nil {
+		t.Fatalf("runConfigExport() to stdout error = %v", err)
+	}
+	exported, err := loadExportedConfig(export)
+	if err != nil {
+		t.Fatalf("loadExportedConfig() error = %v", err)
+	}
+	if parsed, err := config.ParseJSON([]byte(stdout)); err != nil || len(parsed.Tools) != len(exported.Tools) {
+		t.Errorf("stdout export = %+v, %v, want the exported config", parsed, err)
+	}
+
+	desktop := setupDiffZerbDir(t, map[string]string{
+		"zerb.20250116T090000.000Z.lua": `zerb = {
+  tools = { "node@21.0.0", "python@3.12.1", "go@1.22.0" },
+  configs = { "~/.zshrc" },
+}`,
+	}, "zerb.20250116T090000.000Z.lua")
+	t.Setenv("ZERB_DIR", desktop)
+
+	output := captureStdout(t, func() { err = runDiffEnv([]string{export}) })
+	if err != nil {
+		t.Fatalf("runDiffEnv() error = %v", err)
+	}
+	for _, line := range []string{
+		"Comparing this machine (-) with laptop.json (+)",
+		"  - go@1.22.0",
+		"  - node@21.0.0",
+		"  + node@20.11.0",
+	} {
+		if !strings.Contains(output, line) {
+			t.Errorf("output missing %q:\n%s", line, output)
+		}
+	}
+	if strings.Contains(output, "python") || strings.Contains(output, ".zshrc") {
+		t.Errorf("output reports shared entries:\n%s", output)
+	}
+}
+
+func TestRunConfigExport_Errors(t *testing.T) {
+	t.Setenv("ZERB_DIR", setupDiffZerbDir(t, map[string]string{
+		"zerb.20250115T103000.000Z.lua": "zerb = {}",
+	}, "zerb.20250115T103000.000Z.lua"))
+
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{[]string{"--bogus"}, "unknown option: --bogus"},
+		{[]string{"-o"}, "-o requires a file path"},
+		{[]string{"--version", "2019"}, "2019"},
+	}
+	for _, tt := range tests {
+		if err := runConfigExport(tt.args); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("runConfigExport(%v) error = %v, want %q", tt.args, err, tt.wantErr)
+		}
+	}
+}
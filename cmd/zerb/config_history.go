@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/service"
+)
+
+// runConfigHistory handles the `zerb config history` subcommand
+func runConfigHistory(args []string) error {
+	// Parse flags
+	showHelp := false
+	limit := 0
+	var paths []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--help" || arg == "-h":
+			showHelp = true
+		case arg == "--limit" || strings.HasPrefix(arg, "--limit="):
+			value := strings.TrimPrefix(arg, "--limit=")
+			if arg == "--limit" {
+				if i+1 >= len(args) {
+					return fmt.Errorf("--limit requires a number\nRun 'zerb config history --help' for usage")
+				}
+				i++
+				value = args[i]
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid --limit %q: must be a positive number", value)
+			}
+			limit = n
+		case strings.HasPrefix(arg, "-"):
+			return fmt.Errorf("unknown option: %s\nRun 'zerb config history --help' for usage", arg)
+		default:
+			paths = append(paths, arg)
+		}
+	}
+
+	if showHelp {
+		printConfigHistoryHelp()
+		return nil
+	}
+
+	if len(paths) > 1 {
+		return fmt.Errorf("expected at most one config path, got %d\nUsage: zerb config history [path]", len(paths))
+	}
+	if len(paths) == 1 && limit != 0 {
+		return fmt.Errorf("--limit applies to the config version list, not to the history of a path")
+	}
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	// Get ZERB directory
+	zerbDir, err := getZerbDir()
+	if err != nil {
+		return fmt.Errorf("get ZERB directory: %w", err)
+	}
+	if err := ensureLayout(zerbDir); err != nil {
+		return err
+	}
+
+	svc := service.NewConfigHistoryService(git.NewClient(zerbDir), config.NewParser(nil), zerbDir)
+	if len(paths) == 0 {
+		commits, err := svc.Snapshots(ctx, limit)
+		if err != nil {
+			return historyError(err)
+		}
+		printSnapshotHistory(os.Stdout, commits)
+		return nil
+	}
+
+	result, err := svc.History(ctx, paths[0])
+	if err != nil {
+		return historyError(err)
+	}
+
+	printConfigHistory(os.Stdout, result)
+	return nil
+}
+
+// historyError explains a history failure caused by a missing ZERB setup
+func historyError(err error) error {
+	if errors.Is(err, service.ErrNotInitialized) {
+		return fmt.Errorf("ZERB not initialized\nRun 'zerb init' to set up ZERB first")
+	}
+	return err
+}
+
+// printSnapshotHistory prints the commits that changed the config, newest
+// first, with the config version each introduced
+func printSnapshotHistory(w io.Writer, commits []service.SnapshotCommit) {
+	if len(commits) == 0 {
+		fmt.Fprintln(w, "No config versions have been committed yet.")
+		return
+	}
+
+	fmt.Fprintln(w, "Config history (newest first):")
+	fmt.Fprintln(w)
+	for _, commit := range commits {
+		fmt.Fprintf(w, "  %s  %s  %s\n", commit.Hash[:8], commit.Time.Local().Format("2006-01-02 15:04"), commit.Subject)
+		if commit.Snapshot != "" {
+			fmt.Fprintf(w, "            %s\n", commit.Snapshot)
+		}
+	}
+}
+
+// printConfigHistory prints the snapshots where a path was added or removed
+func printConfigHistory(w io.Writer, result *service.HistoryResult) {
+	if len(result.Events) == 0 {
+		fmt.Fprintf(w, "%s has never been tracked.\n", result.Path)
+	} else {
+		fmt.Fprintf(w, "History of %s:\n", result.Path)
+		fmt.Fprintln(w)
+		for _, event := range result.Events {
+			symbol := "+"
+			if event.Type == service.HistoryRemoved {
+				symbol = "-"
+			}
+			commit := "uncommitted"
+			if event.Commit != "" {
+				commit = "commit " + event.Commit[:8]
+			}
+			fmt.Fprintf(w, "  %s %-8s %s (%s)\n", symbol, event.Type, event.Snapshot, commit)
+		}
+		fmt.Fprintln(w)
+		if result.Tracked {
+			fmt.Fprintln(w, "Currently tracked.")
+		} else {
+			fmt.Fprintln(w, "Not currently tracked.")
+		}
+	}
+
+	if len(result.Skipped) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "⚠  Skipped %d config version(s) that could not be parsed:\n", len(result.Skipped))
+		for _, snapshot := range result.Skipped {
+			fmt.Fprintf(w, "  %s\n", snapshot)
+		}
+	}
+}
+
+// printConfigHistoryHelp prints help for the config history command
+func printConfigHistoryHelp() {
+	fmt.Println("Usage: zerb config history [options] [path]")
+	fmt.Println()
+	fmt.Println("Without a path, list the committed config versions, newest first,")
+	fmt.Println("with when and why each was committed.")
+	fmt.Println()
+	fmt.Println("With a path, show the config versions in which that file started")
+	fmt.Println("or stopped being tracked, with the commit that recorded each change.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -h, --help     Show this help message")
+	fmt.Println("  --limit <n>    List only the newest n config versions")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  zerb config history")
+	fmt.Println("  zerb config history --limit 5")
+	fmt.Println("  zerb config history ~/.zshrc")
+	fmt.Println()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/service"
+)
+
+func TestRunConfigHistory_InvalidArgs(t *testing.T) {
+	t.Setenv("ZERB_DIR", t.TempDir())
+	for _, args := range [][]string{{"--bogus", "~/.zshrc"}, {"~/.zshrc", "~/.vimrc"}, {"--limit"}, {"--limit=0"}, {"--limit", "5", "~/.zshrc"}} {
+		if err := runConfigHistory(args); err == nil {
+			t.Errorf("runConfigHistory(%v) expected error, got nil", args)
+		}
+	}
+}
+
+func TestPrintConfigHistory(t *testing.T) {
+	var buf bytes.Buffer
+	printConfigHistory(&buf, &service.HistoryResult{
+		Path: "~/.zshrc",
+		Events: []service.HistoryEvent{
+			{Type: service.HistoryAdded, Snapshot: "zerb.20250102T000000Z.lua", Commit: "0123456789abcdef0123456789abcdef01234567"},
+			{Type: service.HistoryRemoved, Snapshot: "zerb.20250104T000000Z.lua"},
+		},
+	})
+
+	out := buf.String()
+	for _, want := range []string{
+		"History of ~/.zshrc:",
+		"+ added    zerb.20250102T000000Z.lua (commit 01234567)",
+		"- removed  zerb.20250104T000000Z.lua (uncommitted)",
+		"Not currently tracked.",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	printConfigHistory(&buf, &service.HistoryResult{Path: "~/.vimrc"})
+	if !strings.Contains(buf.String(), "~/.vimrc has never been tracked.") {
+		t.Errorf("output = %q, want never-tracked message", buf.String())
+	}
+}
+
+func TestPrintSnapshotHistory(t *testing.T) {
+	var buf bytes.Buffer
+	printSnapshotHistory(&buf, []service.SnapshotCommit{
+		{
+			Commit:   git.Commit{Hash: "0123456789abcdef0123456789abcdef01234567", Subject: "Add ~/.zshrc to tracked configs", Time: time.Date(2025, 1, 2, 3, 4, 5, 0, time.Local)},
+			Snapshot: "zerb.20250102T030405.000Z.lua",
+		},
+		{
+			Commit: git.Commit{Hash: "89abcdef0123456789abcdef0123456789abcdef", Subject: "Prune old config versions", Time: time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)},
+		},
+	})
+
+	out := buf.String()
+	for _, want := range []string{
+		"Config history (newest first):",
+		"  01234567  2025-01-02 03:04  Add ~/.zshrc to tracked configs\n            zerb.20250102T030405.000Z.lua\n",
+		"  89abcdef  2025-01-01 00:00  Prune old config versions\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	printSnapshotHistory(&buf, nil)
+	if !strings.Contains(buf.String(), "No config versions have been committed yet.") {
+		t.Errorf("output = %q, want empty-history message", buf.String())
+	}
+}
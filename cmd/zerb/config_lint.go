@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/service"
+)
+
+// runConfigLint handles the `zerb config lint` subcommand.
+// Returns exit code 1 if any error-severity findings were reported.
+func runConfigLint(args []string) (int, error) {
+	// Parse flags
+	showHelp := false
+	fix := false
+	var paths []string
+
+	for _, arg := range args {
+		switch arg {
+		case "--help", "-h":
+			showHelp = true
+		case "--fix":
+			fix = true
+		default:
+			if strings.HasPrefix(arg, "-") {
+				return 1, fmt.Errorf("unknown option: %s\nRun 'zerb config lint --help' for usage", arg)
+			}
+			paths = append(paths, arg)
+		}
+	}
+
+	if showHelp {
+		printConfigLintHelp()
+		return 0, nil
+	}
+
+	if len(paths) > 1 {
+		return 1, fmt.Errorf("expected at most one config file, got %d\nUsage: zerb config lint [options] [path]", len(paths))
+	}
+	if fix && len(paths) > 0 {
+		return 1, fmt.Errorf("--fix only applies to the active config\nRun 'zerb config lint --fix' without a path")
+	}
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	zerbDir := ""
+	var configPath string
+	if len(paths) == 1 {
+		configPath = paths[0]
+	} else {
+		// Get ZERB directory
+		dir, err := getZerbDir()
+		if err != nil {
+			return 1, fmt.Errorf("get ZERB directory: %w", err)
+		}
+		zerbDir = dir
+		if err := ensureLayout(zerbDir); err != nil {
+			return 1, err
+		}
+
+		configPath = filepath.Join(zerbDir, "zerb.active.lua")
+		if _, err := os.Stat(configPath); err != nil {
+			if os.IsNotExist(err) {
+				return 1, fmt.Errorf("ZERB not initialized\nRun 'zerb init' to set up ZERB first")
+			}
+			return 1, fmt.Errorf("check active config: %w", err)
+		}
+	}
+
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return 1, fmt.Errorf("read config: %w", err)
+	}
+
+	cfg, findings := config.NewParser(nil).Lint(ctx, string(content))
+	printLintFindings(os.Stdout, filepath.Base(configPath), findings)
+
+	if fix && cfg != nil {
+		if err := fixConfig(ctx, zerbDir, cfg); err != nil {
+			return 1, err
+		}
+	}
+
+	if config.HasLintErrors(findings) {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// fixConfig removes duplicate tools from the active config and activates
+// and commits the result as a new timestamped snapshot
+func fixConfig(ctx context.Context, zerbDir string, cfg *config.Config) error {
+	tools, removed := config.DedupeTools(cfg.Tools)
+	if len(removed) == 0 {
+		fmt.Println()
+		fmt.Println("Nothing to fix automatically.")
+		return nil
+	}
+
+	fixed := *cfg
+	fixed.Tools = tools
+
+	filename, content, err := config.NewGenerator().GenerateTimestamped(ctx, &fixed, "")
+	if err != nil {
+		return fmt.Errorf("generate config: %w", err)
+	}
+
+	message := fmt.Sprintf("Remove %d duplicate tools", len(removed))
+	body := "Removed tools:\n- " + strings.Join(removed, "\n- ") + "\n"
+	if len(removed) == 1 {
+		message, body = fmt.Sprintf("Remove duplicate tool %s", removed[0]), ""
+	}
+	svc := service.NewSnapshotCommitService(git.NewClient(zerbDir), config.NewParser(nil), zerbDir)
+	if _, err := svc.SaveActive(ctx, filename, content, message, body); err != nil {
+		return fmt.Errorf("save fixed config: %w", err)
+	}
+
+	fmt.Println()
+	fmt.Printf("✓ Removed %d duplicate tool(s):\n", len(removed))
+	for _, tool := range removed {
+		fmt.Printf("  - %s\n", tool)
+	}
+	fmt.Printf("Config version: %s\n", filename)
+	return nil
+}
+
+// printLintFindings prints each finding followed by a summary line
+func printLintFindings(w io.Writer, name string, findings []config.LintFinding) {
+	if len(findings) == 0 {
+		fmt.Fprintf(w, "%s: no problems found\n", name)
+		return
+	}
+
+	counts := make(map[config.Severity]int)
+	for _, f := range findings {
+		fmt.Fprintf(w, "%s: %s\n", name, f)
+		counts[f.Severity]++
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%d error(s), %d warning(s), %d info\n",
+		counts[config.SeverityError], counts[config.SeverityWarning], counts[config.SeverityInfo])
+}
+
+// printConfigLintHelp prints help for the config lint command
+func printConfigLintHelp() {
+	fmt.Println("Usage: zerb config lint [options] [path]")
+	fmt.Println()
+	fmt.Println("Check a config for problems. Lints the active config unless a")
+	fmt.Println("config file is given.")
+	fmt.Println()
+	fmt.Println("Reports:")
+	fmt.Println("  error     Invalid tools, config paths or git remote; syntax errors")
+	fmt.Println("  warning   Duplicate tools, implausible versions, config paths")
+	fmt.Println("            missing on this machine, http:// git remotes")
+	fmt.Println("  info      Tools not pinned to a version")
+	fmt.Println()
+	fmt.Println("Exits with status 1 if any errors are found.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -h, --help    Show this help message")
+	fmt.Println("  --fix         Remove duplicate tools and save the result as a new")
+	fmt.Println("                config version (active config only)")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  zerb config lint                    Lint the active config")
+	fmt.Println("  zerb config lint ./zerb.lua         Lint a config file")
+	fmt.Println("  zerb config lint --fix              Lint and remove duplicate tools")
+	fmt.Println()
+}
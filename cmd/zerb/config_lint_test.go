@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/layout"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/service"
+)
+
+// writeLintConfig writes content to a config file in a temp dir
+func writeLintConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "zerb.lua")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestRunConfigLint_ExitCode(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{"clean", `zerb = { tools = { "node@20.11.0" } }`, 0},
+		{"warnings only", `zerb = { tools = { "node@20.11.0", "node@18.0.0" } }`, 0},
+		{"validation error", `zerb = { tools = { "bad tool" } }`, 1},
+		{"syntax error", `zerb = {`, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := runConfigLint([]string{writeLintConfig(t, tt.content)})
+			if err != nil {
+				t.Fatalf("runConfigLint() error = %v", err)
+			}
+			if code != tt.want {
+				t.Errorf("runConfigLint() exit code = %d, want %d", code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunConfigLint_InvalidArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"unknown flag", []string{"--bogus"}},
+		{"two paths", []string{"a.lua", "b.lua"}},
+		{"fix with path", []string{"--fix", "a.lua"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := runConfigLint(tt.args)
+			if err == nil {
+				t.Error("runConfigLint() expected error, got nil")
+			}
+			if code != 1 {
+				t.Errorf("runConfigLint() exit code = %d, want 1", code)
+			}
+		})
+	}
+}
+
+func TestRunConfigLint_Fix(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	active := "zerb.20250115T103000.000Z.lua"
+	zerbDir := setupDiffZerbDir(t, map[string]string{
+		active: `zerb = { tools = { "node@20.11.0", "python@3.12.1", "node@18.0.0" } }`,
+	}, active)
+	if err := os.Symlink(filepath.Join("configs", active), filepath.Join(zerbDir, "zerb.active.lua")); err != nil {
+		t.Fatalf("failed to create active symlink: %v", err)
+	}
+	t.Setenv("ZERB_DIR", zerbDir)
+
+	code, err := runConfigLint([]string{"--fix"})
+	if err != nil {
+		t.Fatalf("runConfigLint(--fix) error = %v", err)
+	}
+	if code != 0 {
+		t.Errorf("runConfigLint(--fix) exit code = %d, want 0", code)
+	}
+
+	marker, err := os.ReadFile(filepath.Join(zerbDir, ".zerb-active"))
+	if err != nil {
+		t.Fatalf("failed to read marker: %v", err)
+	}
+	if strings.TrimSpace(string(marker)) == active {
+		t.Fatal("active marker was not updated to a new snapshot")
+	}
+
+	data, err := os.ReadFile(filepath.Join(zerbDir, "zerb.active.lua"))
+	if err != nil {
+		t.Fatalf("failed to read active config: %v", err)
+	}
+	cfg, err := config.NewParser(nil).ParseString(context.Background(), string(data))
+	if err != nil {
+		t.Fatalf("fixed config does not parse: %v", err)
+	}
+	if got := strings.Join(cfg.Tools, ","); got != "node@20.11.0,python@3.12.1" {
+		t.Errorf("tools after fix = %s, want node@20.11.0,python@3.12.1", got)
+	}
+}
+
+func TestPrintLintFindings(t *testing.T) {
+	var buf bytes.Buffer
+	printLintFindings(&buf, "zerb.lua", []config.LintFinding{
+		{Severity: config.SeverityError, Line: 2, Field: "tools[0]", Message: "invalid"},
+		{Severity: config.SeverityWarning, Line: 3, Field: "tools[1]", Message: "duplicate"},
+	})
+
+	out := buf.String()
+	for _, want := range []string{
+		"zerb.lua: line 2: error: tools[0]: invalid",
+		"zerb.lua: line 3: warning: tools[1]: duplicate",
+		"1 error(s), 1 warning(s), 0 info",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	printLintFindings(&buf, "zerb.lua", nil)
+	if got := buf.String(); got != "zerb.lua: no problems found\n" {
+		t.Errorf("output = %q, want no problems message", got)
+	}
+}
+
+// TestRunConfigLint_FixCommits tests that --fix commits the deduplicated
+// config in a git-versioned ZERB directory
+func TestRunConfigLint_FixCommits(t *testing.T) {
+	zerbDir := t.TempDir()
+	t.Setenv("ZERB_DIR", zerbDir)
+	if err := layout.Create(zerbDir); err != nil {
+		t.Fatalf("layout.Create() error = %v", err)
+	}
+
+	snapshot := "zerb.20250101T120000.000Z.lua"
+	content := "zerb = {\n  tools = { \"node@20.11.0\", \"python@3.12.1\", \"node@18.0.0\" },\n}\n"
+	if err := os.WriteFile(filepath.Join(zerbDir, "configs", snapshot), []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if err := service.ActivateSnapshot(zerbDir, snapshot); err != nil {
+		t.Fatalf("ActivateSnapshot() error = %v", err)
+	}
+	gitClient := git.NewClient(zerbDir)
+	ctx := context.Background()
+	if err := gitClient.InitRepo(ctx); err != nil {
+		t.Fatalf("InitRepo() error = %v", err)
+	}
+	if err := gitClient.ConfigureUser(ctx, git.GitUserInfo{Name: "Test User", Email: "test@example.com"}); err != nil {
+		t.Fatalf("ConfigureUser() error = %v", err)
+	}
+	if err := gitClient.CreateInitialCommit(ctx, "Initialize ZERB environment", []string{filepath.Join("configs", snapshot)}); err != nil {
+		t.Fatalf("CreateInitialCommit() error = %v", err)
+	}
+
+	var code int
+	var err error
+	captureStdout(t, func() { code, err = runConfigLint([]string{"--fix"}) })
+	if err != nil || code != 0 {
+		t.Fatalf("runConfigLint(--fix) = %d, %v", code, err)
+	}
+
+	out, err := exec.Command("git", "-C", zerbDir, "log", "-1", "--format=%s").Output()
+	if err != nil {
+		t.Fatalf("git log error = %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "Remove duplicate tool node@18.0.0" {
+		t.Errorf("last commit = %q, want the fix committed", got)
+	}
+}
@@ -2,8 +2,13 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/ZebulonRouseFrantzich/zerb/internal/chezmoi"
@@ -15,10 +20,50 @@ import (
 func runConfigList(args []string) error {
 	// Parse flags
 	showHelp := false
-	for _, arg := range args {
-		switch arg {
-		case "--help", "-h":
+	tree := false
+	jsonOutput := false
+	porcelain := false
+	var req service.ListRequest
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--help" || arg == "-h":
 			showHelp = true
+		case arg == "--tree":
+			tree = true
+		case arg == "--json":
+			jsonOutput = true
+		case arg == "--porcelain":
+			porcelain = true
+		case arg == "--status" || strings.HasPrefix(arg, "--status="):
+			value := strings.TrimPrefix(arg, "--status=")
+			if arg == "--status" {
+				if i+1 >= len(args) {
+					return fmt.Errorf("--status requires a status\nRun 'zerb config list --help' for usage")
+				}
+				i++
+				value = args[i]
+			}
+			for _, name := range strings.Split(value, ",") {
+				status, err := config.ParseConfigStatus(strings.TrimSpace(name))
+				if err != nil {
+					return err
+				}
+				req.Statuses = append(req.Statuses, status)
+			}
+		case arg == "--sort" || strings.HasPrefix(arg, "--sort="):
+			value := strings.TrimPrefix(arg, "--sort=")
+			if arg == "--sort" {
+				if i+1 >= len(args) {
+					return fmt.Errorf("--sort requires path or status\nRun 'zerb config list --help' for usage")
+				}
+				i++
+				value = args[i]
+			}
+			req.Sort = service.ListSort(value)
+			if req.Sort != service.ListSortPath && req.Sort != service.ListSortStatus {
+				return fmt.Errorf("invalid --sort %q: must be path or status", value)
+			}
 		}
 	}
 
@@ -27,6 +72,16 @@ func runConfigList(args []string) error {
 		return nil
 	}
 
+	formats := 0
+	for _, set := range []bool{tree, jsonOutput, porcelain} {
+		if set {
+			formats++
+		}
+	}
+	if formats > 1 {
+		return fmt.Errorf("--tree, --json and --porcelain cannot be combined")
+	}
+
 	// Create context with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -36,6 +91,9 @@ func runConfigList(args []string) error {
 	if err != nil {
 		return fmt.Errorf("get ZERB directory: %w", err)
 	}
+	if err := ensureLayout(zerbDir); err != nil {
+		return err
+	}
 
 	// Create dependencies
 	parser := config.NewParser(nil) // No platform detection needed for listing
@@ -44,12 +102,27 @@ func runConfigList(args []string) error {
 
 	// Create service and execute
 	svc := service.NewConfigListService(parser, detector, zerbDir)
-	result, err := svc.List(ctx, service.ListRequest{})
+	result, err := svc.List(ctx, req)
 	if err != nil {
 		return err
 	}
 
+	// Machine-readable output, also when nothing is tracked
+	if jsonOutput || porcelain {
+		home, _ := os.UserHomeDir()
+		entries := newConfigListEntries(result, home)
+		if jsonOutput {
+			return writeConfigListJSON(os.Stdout, entries)
+		}
+		writeConfigListPorcelain(os.Stdout, entries)
+		return nil
+	}
+
 	// Format and print output
+	if len(result.Configs) == 0 && len(req.Statuses) > 0 {
+		fmt.Printf("No tracked configuration files are %s.\n", joinStatuses(req.Statuses))
+		return nil
+	}
 	if len(result.Configs) == 0 {
 		fmt.Println("No configuration files are being tracked.")
 		fmt.Println()
@@ -61,12 +134,23 @@ func runConfigList(args []string) error {
 	fmt.Println("Tracked configuration files:")
 	fmt.Println()
 
+	if tree {
+		home, _ := os.UserHomeDir()
+		renderConfigTree(os.Stdout, result.Configs, home)
+		fmt.Println()
+		fmt.Println("Legend: ✓ synced, ~ modified, ✗ missing, ? partial, ! unknown")
+		return nil
+	}
+
 	for _, cfg := range result.Configs {
 		// Format status symbol
 		symbol := cfg.Status.Symbol()
 
 		// Format path with options
 		path := cfg.ConfigFile.Path
+		if cfg.ConfigFile.Target != "" {
+			path += " → " + cfg.ConfigFile.Target
+		}
 		opts := formatConfigOptions(cfg.ConfigFile)
 
 		if opts != "" {
@@ -77,11 +161,201 @@ func runConfigList(args []string) error {
 	}
 
 	fmt.Println()
-	fmt.Println("Legend: ✓ synced, ✗ missing, ? partial")
+	fmt.Println("Legend: ✓ synced, ~ modified, ✗ missing, ? partial, ! unknown")
 
 	return nil
 }
 
+// joinStatuses lists status names for a message, e.g. "modified or missing"
+func joinStatuses(statuses []config.ConfigStatus) string {
+	names := make([]string, len(statuses))
+	for i, status := range statuses {
+		names[i] = status.String()
+	}
+	return strings.Join(names, " or ")
+}
+
+// configListEntry is a tracked config in machine-readable list output
+type configListEntry struct {
+	Path          string `json:"path"`         // Under home, relative to ~
+	AbsolutePath  string `json:"absolutePath"` // Normalized
+	Target        string `json:"target,omitempty"`
+	Template      bool   `json:"template"`
+	Secrets       bool   `json:"secrets"`
+	Private       bool   `json:"private"`
+	Recursive     bool   `json:"recursive"`
+	Status        string `json:"status"`
+	ActiveVersion string `json:"activeVersion"`
+}
+
+// newConfigListEntries converts list results for machine-readable output
+func newConfigListEntries(result *service.ListResult, home string) []configListEntry {
+	entries := make([]configListEntry, 0, len(result.Configs))
+	for _, cfg := range result.Configs {
+		absPath, err := config.NormalizeConfigPath(cfg.ConfigFile.Path)
+		if err != nil {
+			absPath = cfg.ConfigFile.Path
+		}
+		entries = append(entries, configListEntry{
+			Path:          tildePath(absPath, home),
+			AbsolutePath:  absPath,
+			Target:        cfg.ConfigFile.Target,
+			Template:      cfg.ConfigFile.Template,
+			Secrets:       cfg.ConfigFile.Secrets,
+			Private:       cfg.ConfigFile.Private,
+			Recursive:     cfg.ConfigFile.Recursive,
+			Status:        cfg.Status.String(),
+			ActiveVersion: result.ActiveVersion,
+		})
+	}
+	return entries
+}
+
+// tildePath shows a path under home relative to ~
+func tildePath(path, home string) string {
+	if home == "" {
+		return path
+	}
+	rel, err := filepath.Rel(home, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+		return path
+	}
+	if rel == "." {
+		return "~"
+	}
+	return "~" + string(filepath.Separator) + rel
+}
+
+// writeConfigListJSON writes the configs as an indented JSON array
+func writeConfigListJSON(w io.Writer, entries []configListEntry) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(entries); err != nil {
+		return fmt.Errorf("encode config list: %w", err)
+	}
+	return nil
+}
+
+// writeConfigListPorcelain writes one tab-separated line per config:
+// status, path, absolute path, target and options. An empty target or
+// option list is written as "-"; options are comma-separated.
+func writeConfigListPorcelain(w io.Writer, entries []configListEntry) {
+	for _, e := range entries {
+		target := e.Target
+		if target == "" {
+			target = "-"
+		}
+
+		var opts []string
+		for _, opt := range []struct {
+			set  bool
+			name string
+		}{{e.Template, "template"}, {e.Secrets, "secrets"}, {e.Private, "private"}, {e.Recursive, "recursive"}} {
+			if opt.set {
+				opts = append(opts, opt.name)
+			}
+		}
+		options := strings.Join(opts, ",")
+		if options == "" {
+			options = "-"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Status, e.Path, e.AbsolutePath, target, options)
+	}
+}
+
+// configTreeNode is a path component in the config tree; entry is set when
+// a tracked config lives at this path
+type configTreeNode struct {
+	children map[string]*configTreeNode
+	entry    *config.ConfigWithStatus
+}
+
+// child returns the child node for name, creating it if needed
+func (n *configTreeNode) child(name string) *configTreeNode {
+	if n.children == nil {
+		n.children = make(map[string]*configTreeNode)
+	}
+	c, ok := n.children[name]
+	if !ok {
+		c = &configTreeNode{}
+		n.children[name] = c
+	}
+	return c
+}
+
+// sortedChildren returns the child names in order
+func (n *configTreeNode) sortedChildren() []string {
+	names := make([]string, 0, len(n.children))
+	for name := range n.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// renderConfigTree writes configs as an indented tree grouped by parent
+// directory. Paths under home are shown relative to ~, and directories
+// holding a single subdirectory are joined onto one line.
+func renderConfigTree(w io.Writer, configs []config.ConfigWithStatus, home string) {
+	root := &configTreeNode{}
+	for i := range configs {
+		path := filepath.Clean(configs[i].ConfigFile.Path)
+		top := string(filepath.Separator)
+		if home != "" {
+			if rel, err := filepath.Rel(home, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				top, path = "~", rel
+			}
+		}
+
+		node := root.child(top)
+		for _, part := range strings.Split(path, string(filepath.Separator)) {
+			if part != "" && part != "." {
+				node = node.child(part)
+			}
+		}
+		node.entry = &configs[i]
+	}
+
+	for _, name := range root.sortedChildren() {
+		renderConfigTreeNode(w, root.children[name], name, "  ")
+	}
+}
+
+// renderConfigTreeNode writes node, labelled label, and its children
+func renderConfigTreeNode(w io.Writer, node *configTreeNode, label, indent string) {
+	// Join chains of directories that only hold one subdirectory
+	for node.entry == nil && len(node.children) == 1 {
+		name := node.sortedChildren()[0]
+		next := node.children[name]
+		if len(next.children) == 0 {
+			break
+		}
+		label, node = filepath.Join(label, name), next
+	}
+
+	if len(node.children) > 0 {
+		label = strings.TrimSuffix(label, string(filepath.Separator)) + string(filepath.Separator)
+	}
+
+	if node.entry != nil {
+		line := label
+		if node.entry.ConfigFile.Target != "" {
+			line += " → " + node.entry.ConfigFile.Target
+		}
+		if opts := formatConfigOptions(node.entry.ConfigFile); opts != "" {
+			line += " " + opts
+		}
+		fmt.Fprintf(w, "%s%s %s\n", indent, node.entry.Status.Symbol(), line)
+	} else {
+		fmt.Fprintf(w, "%s%s\n", indent, label)
+	}
+
+	for _, name := range node.sortedChildren() {
+		renderConfigTreeNode(w, node.children[name], name, indent+"  ")
+	}
+}
+
 // formatConfigOptions formats config file options for display
 func formatConfigOptions(cfg config.ConfigFile) string {
 	var opts []string
@@ -123,14 +397,30 @@ func printConfigListHelp() {
 	fmt.Println()
 	fmt.Println("Options:")
 	fmt.Println("  -h, --help    Show this help message")
+	fmt.Println("  --tree        Group configs by directory in an indented tree")
+	fmt.Println("  --status <s>  Only list configs with status s: synced, modified,")
+	fmt.Println("                missing, partial or unknown (comma-separate for")
+	fmt.Println("                several)")
+	fmt.Println("  --sort <key>  Sort by path (default) or status")
+	fmt.Println("  --json        Output a JSON array with one object per config")
+	fmt.Println("  --porcelain   Output stable tab-separated lines for scripts:")
+	fmt.Println("                status, path, absolute path, target, options")
+	fmt.Println("                (\"-\" when there is no target or option)")
 	fmt.Println()
 	fmt.Println("Status indicators:")
-	fmt.Println("  ✓  synced   File exists and is managed by ZERB")
-	fmt.Println("  ✗  missing  File is declared but doesn't exist on disk")
-	fmt.Println("  ?  partial  File exists but not fully managed by ZERB")
+	fmt.Println("  ✓  synced    File exists and matches its tracked content")
+	fmt.Println("  ~  modified  File was changed since it was tracked")
+	fmt.Println("  ✗  missing   File is declared but doesn't exist on disk")
+	fmt.Println("  ?  partial   File exists but not fully managed by ZERB")
+	fmt.Println("  !  unknown   File could not be compared (e.g. encrypted without")
+	fmt.Println("               its key)")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  zerb config list          List all tracked configs")
+	fmt.Println("  zerb config list --tree   List configs grouped by directory")
+	fmt.Println("  zerb config list --json   List configs for scripts")
+	fmt.Println("  zerb config list --status modified")
+	fmt.Println("                            List configs changed since they were tracked")
 	fmt.Println()
 	os.Exit(0)
 }
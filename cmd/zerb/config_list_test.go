@@ -1,9 +1,15 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/service"
 )
 
 func TestFormatConfigOptions(t *testing.T) {
@@ -69,6 +75,39 @@ func TestFormatConfigOptions(t *testing.T) {
 	}
 }
 
+func TestRenderConfigTree(t *testing.T) {
+	home := "/home/user"
+	configs := []config.ConfigWithStatus{
+		{ConfigFile: config.ConfigFile{Path: "/home/user/.zshrc"}, Status: config.StatusSynced},
+		{ConfigFile: config.ConfigFile{Path: "/home/user/.config/nvim/init.lua", Template: true}, Status: config.StatusSynced},
+		{ConfigFile: config.ConfigFile{Path: "/home/user/.config/nvim/lua/plugins.lua"}, Status: config.StatusMissing},
+		{ConfigFile: config.ConfigFile{Path: "/home/user/.config/git", Recursive: true}, Status: config.StatusPartial},
+		{ConfigFile: config.ConfigFile{Path: "/home/user/dotfiles/work.gitconfig", Target: "/home/user/.gitconfig"}, Status: config.StatusSynced},
+		{ConfigFile: config.ConfigFile{Path: "/etc/hosts"}, Status: config.StatusSynced},
+	}
+
+	var buf bytes.Buffer
+	renderConfigTree(&buf, configs, home)
+
+	// ~/.config/nvim/init.lua is nested under ~/.config/nvim
+	want := `  /etc/
+    ✓ hosts
+  ~/
+    .config/
+      ? git (recursive)
+      nvim/
+        ✓ init.lua (template)
+        lua/
+          ✗ plugins.lua
+    ✓ .zshrc
+    dotfiles/
+      ✓ work.gitconfig → /home/user/.gitconfig
+`
+	if got := buf.String(); got != want {
+		t.Errorf("renderConfigTree() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
 func TestRunConfigList_Help(t *testing.T) {
 	// Test that --help doesn't panic
 	// Note: printConfigListHelp calls os.Exit(0), so we can't test the full function
@@ -116,3 +155,110 @@ func TestRunConfigList_ParseFlags(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigListMachineOutput(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
+
+	result := &service.ListResult{
+		ActiveVersion: "zerb.20250101T000000.000Z.lua",
+		Configs: []config.ConfigWithStatus{
+			{ConfigFile: config.ConfigFile{Path: "~/.zshrc"}, Status: config.StatusSynced},
+			{ConfigFile: config.ConfigFile{Path: filepath.Join(home, ".ssh", "config"), Template: true, Private: true}, Status: config.StatusModified},
+			{ConfigFile: config.ConfigFile{Path: "~/dotfiles/work.gitconfig", Target: "~/.gitconfig"}, Status: config.StatusMissing},
+		},
+	}
+	entries := newConfigListEntries(result, home)
+
+	var buf bytes.Buffer
+	if err := writeConfigListJSON(&buf, entries); err != nil {
+		t.Fatalf("writeConfigListJSON() error = %v", err)
+	}
+	var decoded []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("output is not a JSON array: %v\n%s", err, buf.String())
+	}
+	if len(decoded) != 3 {
+		t.Fatalf("got %d objects, want 3", len(decoded))
+	}
+	want := map[string]any{
+		"path":          "~/.ssh/config",
+		"absolutePath":  filepath.Join(home, ".ssh", "config"),
+		"template":      true,
+		"secrets":       false,
+		"private":       true,
+		"recursive":     false,
+		"status":        "modified",
+		"activeVersion": "zerb.20250101T000000.000Z.lua",
+	}
+	if !reflect.DeepEqual(decoded[1], want) {
+		t.Errorf("JSON object = %v, want %v", decoded[1], want)
+	}
+	if decoded[2]["target"] != "~/.gitconfig" {
+		t.Errorf("target = %v, want ~/.gitconfig", decoded[2]["target"])
+	}
+
+	buf.Reset()
+	writeConfigListPorcelain(&buf, entries)
+	wantLines := "synced\t~/.zshrc\t" + filepath.Join(home, ".zshrc") + "\t-\t-\n" +
+		"modified\t~/.ssh/config\t" + filepath.Join(home, ".ssh", "config") + "\t-\ttemplate,private\n" +
+		"missing\t~/dotfiles/work.gitconfig\t" + filepath.Join(home, "dotfiles", "work.gitconfig") + "\t~/.gitconfig\t-\n"
+	if buf.String() != wantLines {
+		t.Errorf("porcelain output =\n%q\nwant\n%q", buf.String(), wantLines)
+	}
+
+	// No configs is an empty array and no lines
+	buf.Reset()
+	if err := writeConfigListJSON(&buf, newConfigListEntries(&service.ListResult{}, home)); err != nil {
+		t.Fatalf("writeConfigListJSON() error = %v", err)
+	}
+	if got := strings.TrimSpace(buf.String()); got != "[]" {
+		t.Errorf("JSON without configs = %q, want []", got)
+	}
+}
+
+func TestTildePath(t *testing.T) {
+	tests := []struct {
+		path, want string
+	}{
+		{"/home/user/.zshrc", "~/.zshrc"},
+		{"/home/user", "~"},
+		{"/home/username/.zshrc", "/home/username/.zshrc"},
+		{"/etc/hosts", "/etc/hosts"},
+	}
+	for _, tt := range tests {
+		if got := tildePath(tt.path, "/home/user"); got != tt.want {
+			t.Errorf("tildePath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+	if got := tildePath("/home/user/.zshrc", ""); got != "/home/user/.zshrc" {
+		t.Errorf("tildePath() without home = %q", got)
+	}
+}
+
+func TestRunConfigList_ConflictingFormats(t *testing.T) {
+	t.Setenv("ZERB_DIR", t.TempDir())
+	for _, args := range [][]string{{"--json", "--porcelain"}, {"--tree", "--json"}} {
+		if err := runConfigList(args); err == nil {
+			t.Errorf("runConfigList(%v) expected error, got nil", args)
+		}
+	}
+}
+
+func TestRunConfigList_InvalidFilterAndSort(t *testing.T) {
+	t.Setenv("ZERB_DIR", t.TempDir())
+	for _, args := range [][]string{{"--status", "bogus"}, {"--status=synced,stale"}, {"--status"}, {"--sort", "size"}, {"--sort"}} {
+		if err := runConfigList(args); err == nil {
+			t.Errorf("runConfigList(%v) expected error, got nil", args)
+		}
+	}
+}
+
+func TestJoinStatuses(t *testing.T) {
+	got := joinStatuses([]config.ConfigStatus{config.StatusModified, config.StatusMissing})
+	if got != "modified or missing" {
+		t.Errorf("joinStatuses() = %q, want %q", got, "modified or missing")
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/chezmoi"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/service"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/transaction"
+)
+
+// runConfigRecover handles the `zerb config recover` subcommand
+func runConfigRecover(args []string) error {
+	// Parse flags
+	showHelp := false
+	dryRun := false
+	forceUnlock := false
+
+	for _, arg := range args {
+		switch arg {
+		case "--help", "-h":
+			showHelp = true
+		case "--dry-run", "-n":
+			dryRun = true
+		case "--force-unlock":
+			forceUnlock = true
+		default:
+			return fmt.Errorf("unknown option: %s\nRun 'zerb config recover --help' for usage", arg)
+		}
+	}
+
+	if showHelp {
+		printConfigRecoverHelp()
+		return nil
+	}
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	// Get ZERB directory
+	zerbDir, err := getZerbDir()
+	if err != nil {
+		return fmt.Errorf("get ZERB directory: %w", err)
+	}
+	if err := ensureLayout(zerbDir); err != nil {
+		return err
+	}
+
+	// Check if ZERB is initialized
+	if _, err := os.Stat(zerbDir); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("ZERB not initialized\nRun 'zerb init' to set up ZERB first")
+		}
+		return fmt.Errorf("check ZERB directory: %w", err)
+	}
+
+	// Create service
+	clock := service.RealClock{}
+	svc := service.NewConfigAddService(
+		chezmoi.NewClient(zerbDir),
+		git.NewClient(zerbDir),
+		config.NewParser(nil),
+		config.NewGenerator().WithClock(clock),
+		clock,
+		zerbDir,
+	)
+
+	result, err := svc.Recover(ctx, service.RecoverRequest{DryRun: dryRun, ForceUnlock: forceUnlock})
+	if err != nil {
+		return err
+	}
+
+	printConfigRecoverResult(os.Stdout, result, dryRun)
+	return nil
+}
+
+// printConfigRecoverResult prints what recovery did, or would do
+func printConfigRecoverResult(w io.Writer, result *service.RecoverResult, dryRun bool) {
+	if result.Action == transaction.RecoveryNone {
+		fmt.Fprintln(w, "Nothing to recover.")
+		return
+	}
+
+	if dryRun {
+		fmt.Fprintln(w, "Dry run - no changes made")
+		fmt.Fprintln(w)
+	}
+
+	switch result.Action {
+	case transaction.RecoveryRollBack:
+		if dryRun {
+			fmt.Fprintln(w, "Would roll back the interrupted config add:")
+		} else {
+			fmt.Fprintln(w, "✓ Rolled back the interrupted config add")
+		}
+		for _, path := range result.Paths {
+			fmt.Fprintf(w, "  - %s (untracked)\n", path)
+		}
+		for _, path := range result.Pending {
+			fmt.Fprintf(w, "  - %s (never started)\n", path)
+		}
+		if result.ConfigVersion != "" {
+			fmt.Fprintf(w, "  Discarded config version: %s\n", result.ConfigVersion)
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Files on disk were left untouched. Run 'zerb config add' again to track them.")
+	case transaction.RecoveryResume:
+		if dryRun {
+			fmt.Fprintln(w, "Would finish the interrupted config add:")
+		} else {
+			fmt.Fprintln(w, "✓ Finished the interrupted config add")
+		}
+		for _, path := range result.Paths {
+			fmt.Fprintf(w, "  - %s\n", path)
+		}
+		fmt.Fprintf(w, "  Config version: %s\n", result.ConfigVersion)
+		if result.CommitHash != "" {
+			fmt.Fprintf(w, "  Commit: %s\n", result.CommitHash[:8])
+		}
+	}
+}
+
+// printConfigRecoverHelp prints help for the config recover command
+func printConfigRecoverHelp() {
+	fmt.Println("Usage: zerb config recover [options]")
+	fmt.Println()
+	fmt.Println("Recover from a 'zerb config add' that was interrupted or failed.")
+	fmt.Println()
+	fmt.Println("If every file was added and the new config version was written,")
+	fmt.Println("the add is finished: the version is activated and committed.")
+	fmt.Println("Otherwise it is rolled back: files it added are untracked and a")
+	fmt.Println("partially written config version is discarded. Files on disk are")
+	fmt.Println("never changed. Running recover again does nothing.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -h, --help     Show this help message")
+	fmt.Println("  -n, --dry-run  Show what would be recovered without changing anything")
+	fmt.Println("      --force-unlock")
+	fmt.Println("                 Remove the lock of another zerb command first; use")
+	fmt.Println("                 only when none is running but the lock is reported")
+	fmt.Println("                 as held")
+	fmt.Println()
+}
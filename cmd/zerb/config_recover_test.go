@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/service"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/transaction"
+)
+
+func TestPrintConfigRecoverResult(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *service.RecoverResult
+		dryRun bool
+		want   []string
+	}{
+		{
+			name:   "nothing to recover",
+			result: &service.RecoverResult{Action: transaction.RecoveryNone},
+			want:   []string{"Nothing to recover."},
+		},
+		{
+			name: "roll back",
+			result: &service.RecoverResult{
+				Action:        transaction.RecoveryRollBack,
+				Paths:         []string{"~/.zshrc"},
+				Pending:       []string{"~/.vimrc"},
+				ConfigVersion: "zerb.20250102T000000.000Z.lua",
+			},
+			want: []string{"✓ Rolled back", "~/.zshrc (untracked)", "~/.vimrc (never started)", "Discarded config version: zerb.20250102T000000.000Z.lua"},
+		},
+		{
+			name: "resume dry run",
+			result: &service.RecoverResult{
+				Action:        transaction.RecoveryResume,
+				Paths:         []string{"~/.zshrc"},
+				ConfigVersion: "zerb.20250102T000000.000Z.lua",
+			},
+			dryRun: true,
+			want:   []string{"Dry run - no changes made", "Would finish the interrupted config add:", "Config version: zerb.20250102T000000.000Z.lua"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			printConfigRecoverResult(&buf, tt.result, tt.dryRun)
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
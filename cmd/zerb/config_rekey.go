@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/chezmoi"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/prompt"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/service"
+)
+
+// runConfigRekey handles the `zerb config rekey` subcommand
+func runConfigRekey(args []string) error {
+	// Parse flags
+	showHelp := false
+	dryRun := false
+	skipConfirm := false
+	noCommit := false
+	recipient := ""
+	identity := ""
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--help" || arg == "-h":
+			showHelp = true
+		case arg == "--dry-run" || arg == "-n":
+			dryRun = true
+		case arg == "--yes" || arg == "-y":
+			skipConfirm = true
+		case arg == "--no-commit":
+			noCommit = true
+		case arg == "--to":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--to requires a recipient\nRun 'zerb config rekey --help' for usage")
+			}
+			i++
+			recipient = args[i]
+		case strings.HasPrefix(arg, "--to="):
+			recipient = strings.TrimPrefix(arg, "--to=")
+		case arg == "--identity":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--identity requires a file path\nRun 'zerb config rekey --help' for usage")
+			}
+			i++
+			identity = args[i]
+		case strings.HasPrefix(arg, "--identity="):
+			identity = strings.TrimPrefix(arg, "--identity=")
+		default:
+			return fmt.Errorf("unknown option: %s\nRun 'zerb config rekey --help' for usage", arg)
+		}
+	}
+
+	if showHelp {
+		printConfigRekeyHelp()
+		return nil
+	}
+
+	if recipient == "" {
+		return fmt.Errorf("--to is required\nRun 'zerb config rekey --help' for usage")
+	}
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	// Get ZERB directory
+	zerbDir, err := getZerbDir()
+	if err != nil {
+		return fmt.Errorf("get ZERB directory: %w", err)
+	}
+	if err := ensureLayout(zerbDir); err != nil {
+		return err
+	}
+
+	// Check if ZERB is initialized
+	if _, err := os.Stat(zerbDir); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("ZERB not initialized\nRun 'zerb init' to set up ZERB first")
+		}
+		return fmt.Errorf("check ZERB directory: %w", err)
+	}
+
+	svc := service.NewConfigRekeyService(
+		chezmoi.NewClient(zerbDir),
+		git.NewClient(zerbDir),
+		config.NewParser(nil),
+		config.NewGenerator().WithClock(service.RealClock{}),
+		zerbDir,
+	)
+
+	// Enumerate what would be re-encrypted
+	preview, err := svc.Execute(ctx, service.RekeyRequest{Recipient: recipient, Identity: identity, DryRun: true})
+	if err != nil {
+		return err
+	}
+
+	if len(preview.Files) == 0 {
+		fmt.Println("No secret configuration files are being tracked.")
+		return nil
+	}
+
+	if dryRun {
+		fmt.Println("Dry run - no changes made")
+		fmt.Println()
+		fmt.Println("Would re-encrypt:")
+	} else {
+		fmt.Println("The following secret configuration files will be re-encrypted:")
+	}
+	for _, file := range preview.Files {
+		fmt.Printf("  - %s\n", file)
+	}
+	fmt.Println()
+	fmt.Printf("From: %s\n", displayRecipient(preview.OldRecipient))
+	fmt.Printf("To:   %s\n", recipient)
+
+	if dryRun {
+		return nil
+	}
+
+	p := newPrompter()
+	if skipConfirm {
+		p = p.WithAssumeYes(true)
+	}
+	confirmed, err := confirmRekey(p, len(preview.Files))
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Println("Rekey cancelled.")
+		return nil
+	}
+
+	result, err := svc.Execute(ctx, service.RekeyRequest{Recipient: recipient, Identity: identity, NoCommit: noCommit})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Printf("✓ Re-encrypted %d secret file(s) to %s\n", len(result.Files), recipient)
+	if result.Uncommitted {
+		fmt.Println("Changes are staged but not committed")
+	}
+	if result.CommitHash != "" {
+		fmt.Printf("Committed: %s\n", result.CommitHash[:8])
+	}
+	fmt.Println("  Other machines need the new key to apply these files.")
+
+	return nil
+}
+
+// applyEncryptionConfig applies the encryption settings of the active
+// config, so secrets are encrypted and decrypted with the repository's key
+// even on a fresh clone
+func applyEncryptionConfig(ctx context.Context, zerbDir string) error {
+	data, err := os.ReadFile(filepath.Join(zerbDir, "zerb.active.lua"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("read active config: %w", err)
+	}
+	cfg, err := config.NewParser(nil).ParseString(ctx, string(data))
+	if err != nil {
+		return fmt.Errorf("parse active config: %w", err)
+	}
+	return service.ApplyEncryptionConfig(chezmoi.NewClient(zerbDir), cfg)
+}
+
+// displayRecipient formats a recipient for display
+func displayRecipient(recipient string) string {
+	if recipient == "" {
+		return "(default key)"
+	}
+	return recipient
+}
+
+// confirmRekey prompts the user before re-encrypting secret configs
+func confirmRekey(p *prompt.Prompter, count int) (bool, error) {
+	fmt.Println()
+	confirmed, err := p.Confirm(fmt.Sprintf("Re-encrypt %d secret file(s)? (yes/no): ", count))
+	if err != nil {
+		return false, fmt.Errorf("read confirmation: %w", err)
+	}
+	return confirmed, nil
+}
+
+// printConfigRekeyHelp prints help for the config rekey command
+func printConfigRekeyHelp() {
+	fmt.Println("Usage: zerb config rekey --to <recipient> [options]")
+	fmt.Println()
+	fmt.Println("Re-encrypt every secret configuration file to a new key, e.g. after")
+	fmt.Println("rotating your GPG or age key. Every file is decrypted with the current")
+	fmt.Println("key first; nothing is changed if any of them cannot be decrypted.")
+	fmt.Println("Each file must also decrypt with the new key before the change is")
+	fmt.Println("committed. The new recipient is recorded in the config, so every")
+	fmt.Println("machine encrypts to it.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -h, --help           Show this help message")
+	fmt.Println("  --to <recipient>     New recipient: a GPG key ID or email, or an")
+	fmt.Println("                       age public key (age1...)")
+	fmt.Println("  --identity <file>    age private key to decrypt with (default: the")
+	fmt.Printf("                       configured one, or %s)\n", config.DefaultAgeIdentity)
+	fmt.Println("  -n, --dry-run        Show what would be re-encrypted without changing anything")
+	fmt.Println("  -y, --yes            Skip the confirmation prompt")
+	fmt.Println("  --no-commit          Stage the changes without committing them")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  zerb config rekey --to 0x1234ABCD")
+	fmt.Println("  zerb config rekey --to age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p \\")
+	fmt.Println("    --identity ~/.config/age/keys.txt")
+	fmt.Println()
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/chezmoi"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/service"
+)
+
+// runConfigRemove handles the `zerb config remove` subcommand
+func runConfigRemove(args []string) error {
+	// Parse flags and paths
+	showHelp := false
+	dryRun := false
+	noCommit := false
+	var paths []string
+
+	for _, arg := range args {
+		switch arg {
+		case "--help", "-h":
+			showHelp = true
+		case "--dry-run", "-n":
+			dryRun = true
+		case "--no-commit":
+			noCommit = true
+		default:
+			// Anything not starting with - is a path
+			if len(arg) > 0 && arg[0] != '-' {
+				paths = append(paths, arg)
+			} else {
+				return fmt.Errorf("unknown option: %s\nRun 'zerb config remove --help' for usage", arg)
+			}
+		}
+	}
+
+	if showHelp {
+		printConfigRemoveHelp()
+		return nil
+	}
+
+	if len(paths) == 0 {
+		return fmt.Errorf("no paths specified; run 'zerb config remove --help' for usage")
+	}
+
+	// Reject paths that cannot be normalized before taking the lock.
+	// The service matches entries by normalized path, so the paths are
+	// passed on as typed and reported back that way.
+	for _, path := range paths {
+		if _, err := config.NormalizeConfigPath(path); err != nil {
+			return fmt.Errorf("invalid path %q: %w", path, err)
+		}
+	}
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	// Get ZERB directory
+	zerbDir, err := getZerbDir()
+	if err != nil {
+		return fmt.Errorf("get ZERB directory: %w", err)
+	}
+	if err := ensureLayout(zerbDir); err != nil {
+		return err
+	}
+
+	// Check if ZERB is initialized
+	if _, err := os.Stat(zerbDir); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("ZERB not initialized\nRun 'zerb init' to set up ZERB first")
+		}
+		return fmt.Errorf("check ZERB directory: %w", err)
+	}
+
+	// Create service
+	clock := service.RealClock{}
+	svc := service.NewConfigRemoveService(
+		chezmoi.NewClient(zerbDir),
+		git.NewClient(zerbDir),
+		config.NewParser(nil),
+		config.NewGenerator().WithClock(clock),
+		clock,
+		zerbDir,
+	)
+
+	result, err := svc.Execute(ctx, service.RemoveRequest{
+		Paths:    paths,
+		DryRun:   dryRun,
+		NoCommit: noCommit,
+	})
+	if err != nil {
+		return err
+	}
+
+	printConfigRemoveResult(os.Stdout, result, dryRun)
+	return nil
+}
+
+// printConfigRemoveResult prints what config remove did (or, with dryRun, would do)
+func printConfigRemoveResult(w io.Writer, result *service.RemoveResult, dryRun bool) {
+	if dryRun {
+		fmt.Fprintln(w, "Dry run - no changes made")
+		fmt.Fprintln(w)
+	}
+
+	if len(result.RemovedPaths) > 0 {
+		if dryRun {
+			fmt.Fprintln(w, "Would untrack:")
+		} else {
+			fmt.Fprintln(w, "Untracked:")
+		}
+		for _, path := range result.RemovedPaths {
+			fmt.Fprintf(w, "  ✓ %s\n", path)
+		}
+	}
+
+	if len(result.NotTrackedPaths) > 0 {
+		if len(result.RemovedPaths) > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, "Skipped (not tracked):")
+		for _, path := range result.NotTrackedPaths {
+			fmt.Fprintf(w, "  - %s\n", path)
+		}
+	}
+
+	if !dryRun && len(result.RemovedPaths) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Files on disk were left untouched.")
+		if result.Uncommitted {
+			fmt.Fprintln(w, "Changes staged but not committed (review with 'git status' in the ZERB directory)")
+		} else if result.CommitHash != "" {
+			fmt.Fprintf(w, "Committed: %s\n", result.CommitHash[:8])
+		}
+		if result.ConfigVersion != "" {
+			fmt.Fprintf(w, "Config version: %s\n", result.ConfigVersion)
+		}
+	}
+}
+
+// printConfigRemoveHelp prints help for the config remove command
+func printConfigRemoveHelp() {
+	fmt.Println("Usage: zerb config remove [options] <path>...")
+	fmt.Println()
+	fmt.Println("Stop tracking one or more configuration files.")
+	fmt.Println("Files on disk are not removed or modified.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -h, --help       Show this help message")
+	fmt.Println("  -n, --dry-run    Show what would be untracked without making changes")
+	fmt.Println("  --no-commit      Stage the changes but leave the git commit to you")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  zerb config remove ~/.zshrc")
+	fmt.Println("  zerb config remove ~/.vimrc ~/.tmux.conf")
+	fmt.Println("  zerb config remove --dry-run ~/.config/nvim")
+	fmt.Println()
+	fmt.Println("Notes:")
+	fmt.Println("  - A path tracked under another name (see 'zerb config add --as')")
+	fmt.Println("    can be given by either name")
+	fmt.Println("  - A new config version without the removed files is created")
+	fmt.Println("  - Changes are committed to git automatically")
+	fmt.Println()
+}
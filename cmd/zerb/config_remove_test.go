@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/service"
+)
+
+func TestRunConfigRemove_InvalidArgs(t *testing.T) {
+	t.Setenv("ZERB_DIR", t.TempDir())
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "unknown flag", args: []string{"--invalid-flag", "~/.zshrc"}},
+		{name: "no paths", args: []string{"--dry-run"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := runConfigRemove(tt.args); err == nil {
+				t.Errorf("runConfigRemove(%v) expected error, got nil", tt.args)
+			}
+		})
+	}
+}
+
+func TestRunConfigRemove_NotInitialized(t *testing.T) {
+	// Point ZERB_DIR at a directory that does not exist
+	t.Setenv("ZERB_DIR", filepath.Join(t.TempDir(), "missing"))
+
+	if err := runConfigRemove([]string{"~/.zshrc"}); err == nil {
+		t.Error("expected error for uninitialized ZERB, got nil")
+	}
+}
+
+func TestPrintConfigRemoveResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  *service.RemoveResult
+		dryRun  bool
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "removed and committed",
+			result: &service.RemoveResult{
+				RemovedPaths:    []string{"~/.zshrc"},
+				NotTrackedPaths: []string{"~/.vimrc"},
+				CommitHash:      "0123456789abcdef",
+				ConfigVersion:   "zerb.20250102T030405.000Z.lua",
+			},
+			want: []string{"Untracked:", "✓ ~/.zshrc", "Skipped (not tracked):", "- ~/.vimrc", "left untouched", "Committed: 01234567", "Config version: zerb.20250102T030405.000Z.lua"},
+		},
+		{
+			name:    "dry run",
+			result:  &service.RemoveResult{RemovedPaths: []string{"~/.zshrc"}},
+			dryRun:  true,
+			want:    []string{"Dry run - no changes made", "Would untrack:", "✓ ~/.zshrc"},
+			notWant: []string{"Committed", "left untouched"},
+		},
+		{
+			name:    "nothing tracked",
+			result:  &service.RemoveResult{NotTrackedPaths: []string{"~/.vimrc"}},
+			want:    []string{"Skipped (not tracked):", "- ~/.vimrc"},
+			notWant: []string{"Untracked:", "Committed"},
+		},
+		{
+			name:   "not committed",
+			result: &service.RemoveResult{RemovedPaths: []string{"~/.zshrc"}, Uncommitted: true},
+			want:   []string{"Changes staged but not committed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			printConfigRemoveResult(&buf, tt.result, tt.dryRun)
+			out := buf.String()
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output missing %q:\n%s", want, out)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(out, notWant) {
+					t.Errorf("output contains %q:\n%s", notWant, out)
+				}
+			}
+		})
+	}
+}
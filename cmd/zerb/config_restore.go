@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/service"
+)
+
+// runConfigRestore handles the `zerb config restore` subcommand
+func runConfigRestore(args []string) error {
+	// Parse flags
+	showHelp := false
+	var versions []string
+
+	for _, arg := range args {
+		switch {
+		case arg == "--help" || arg == "-h":
+			showHelp = true
+		case strings.HasPrefix(arg, "-"):
+			return fmt.Errorf("unknown option: %s\nRun 'zerb config restore --help' for usage", arg)
+		default:
+			versions = append(versions, arg)
+		}
+	}
+
+	if showHelp {
+		printConfigRestoreHelp()
+		return nil
+	}
+
+	if len(versions) != 1 {
+		return fmt.Errorf("expected one config version, got %d\nUsage: zerb config restore <version>", len(versions))
+	}
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	// Get ZERB directory
+	zerbDir, err := getZerbDir()
+	if err != nil {
+		return fmt.Errorf("get ZERB directory: %w", err)
+	}
+	if err := ensureLayout(zerbDir); err != nil {
+		return err
+	}
+
+	// Versions still in configs/ may be abbreviated; pruned ones are
+	// looked up in the history by their full name
+	version, err := resolveConfigVersion(zerbDir, versions[0])
+	if errors.Is(err, errConfigVersionNotFound) {
+		version = "zerb." + trimVersionAffixes(versions[0]) + ".lua"
+	} else if err != nil {
+		return historyError(err)
+	}
+
+	svc := service.NewConfigHistoryService(git.NewClient(zerbDir), config.NewParser(nil), zerbDir)
+	result, err := svc.RestoreSnapshot(ctx, version)
+	if err != nil {
+		if errors.Is(err, service.ErrSnapshotNotFound) {
+			return fmt.Errorf("%w\nRun 'zerb config history' to list config versions", err)
+		}
+		return historyError(err)
+	}
+
+	printConfigRestoreResult(os.Stdout, result)
+	return nil
+}
+
+// printConfigRestoreResult prints the outcome of a restore
+func printConfigRestoreResult(w io.Writer, result *service.RestoreResult) {
+	fmt.Fprintf(w, "✓ Restored config version %s\n", result.Snapshot)
+	fmt.Fprintf(w, "  Previously active: %s\n", result.Previous)
+	if result.FromHistory {
+		fmt.Fprintln(w, "  The version had been pruned and was recovered from history.")
+	}
+	if result.CommitHash != "" {
+		fmt.Fprintf(w, "  Commit: %s\n", result.CommitHash[:8])
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Tools and config files on disk are unchanged.")
+	fmt.Fprintln(w, "Run 'zerb drift' to bring them in line with the restored version.")
+}
+
+// printConfigRestoreHelp prints help for the config restore command
+func printConfigRestoreHelp() {
+	fmt.Println("Usage: zerb config restore <version>")
+	fmt.Println()
+	fmt.Println("Make an earlier config version the active config and commit the")
+	fmt.Println("rollback. The version must be valid; if anything fails, the active")
+	fmt.Println("config is left as it was. Versions pruned from disk are recovered")
+	fmt.Println("from the git history.")
+	fmt.Println()
+	fmt.Println("Versions can be given as a full filename (zerb.20250115T103000.000Z.lua)")
+	fmt.Println("or an unambiguous timestamp prefix (20250115).")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -h, --help     Show this help message")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  zerb config history")
+	fmt.Println("  zerb config restore 20250115")
+	fmt.Println()
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/service"
+)
+
+func TestRunConfigRestore_InvalidArgs(t *testing.T) {
+	t.Setenv("ZERB_DIR", t.TempDir())
+	for _, args := range [][]string{{}, {"--bogus", "20250115"}, {"20250115", "20250116"}} {
+		if err := runConfigRestore(args); err == nil {
+			t.Errorf("runConfigRestore(%v) expected error, got nil", args)
+		}
+	}
+}
+
+func TestPrintConfigRestoreResult(t *testing.T) {
+	var buf bytes.Buffer
+	printConfigRestoreResult(&buf, &service.RestoreResult{
+		Snapshot:    "zerb.20250102T000000.000Z.lua",
+		Previous:    "zerb.20250103T000000.000Z.lua",
+		FromHistory: true,
+		CommitHash:  "0123456789abcdef0123456789abcdef01234567",
+	})
+
+	out := buf.String()
+	for _, want := range []string{
+		"✓ Restored config version zerb.20250102T000000.000Z.lua",
+		"Previously active: zerb.20250103T000000.000Z.lua",
+		"recovered from history",
+		"Commit: 01234567",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+)
+
+// runConfigShow handles the `zerb config show` subcommand
+func runConfigShow(args []string) error {
+	// Parse flags
+	showHelp := false
+	raw := false
+	version := activeVersionAlias
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--help" || arg == "-h":
+			showHelp = true
+		case arg == "--raw":
+			raw = true
+		case arg == "--version":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--version requires a config version\nRun 'zerb config show --help' for usage")
+			}
+			i++
+			version = args[i]
+		case strings.HasPrefix(arg, "--version="):
+			version = strings.TrimPrefix(arg, "--version=")
+		default:
+			return fmt.Errorf("unknown option: %s\nRun 'zerb config show --help' for usage", arg)
+		}
+	}
+
+	if showHelp {
+		printConfigShowHelp()
+		return nil
+	}
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Get ZERB directory
+	zerbDir, err := getZerbDir()
+	if err != nil {
+		return fmt.Errorf("get ZERB directory: %w", err)
+	}
+	if err := ensureLayout(zerbDir); err != nil {
+		return err
+	}
+
+	// Check if ZERB is initialized
+	if _, err := os.Stat(filepath.Join(zerbDir, ".zerb-active")); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("ZERB not initialized\nRun 'zerb init' to set up ZERB first")
+		}
+		return fmt.Errorf("check ZERB directory: %w", err)
+	}
+
+	filename, err := resolveConfigVersion(zerbDir, version)
+	if err != nil {
+		return err
+	}
+
+	if raw {
+		data, err := os.ReadFile(filepath.Join(zerbDir, "configs", filename))
+		if err != nil {
+			return fmt.Errorf("read config version %s: %w", filename, err)
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	cfg, err := loadConfigVersion(ctx, zerbDir, filename)
+	if err != nil {
+		return err
+	}
+
+	active, _ := resolveConfigVersion(zerbDir, activeVersionAlias)
+	return printConfigShow(os.Stdout, filename, filename == active, cfg, config.ProfilesFromEnv())
+}
+
+// printConfigShow prints a parsed config version section by section. Tools
+// are resolved for the active profiles and config paths are shown with the
+// absolute path they are applied to.
+func printConfigShow(w io.Writer, filename string, isActive bool, cfg *config.Config, profiles []string) error {
+	tools, err := cfg.ResolveTools(profiles)
+	if err != nil {
+		return fmt.Errorf("resolve tools: %w", err)
+	}
+
+	if isActive {
+		fmt.Fprintf(w, "Config version: %s (active)\n", filename)
+	} else {
+		fmt.Fprintf(w, "Config version: %s\n", filename)
+	}
+
+	if cfg.Meta.Name != "" || cfg.Meta.Description != "" {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Meta:")
+		if cfg.Meta.Name != "" {
+			fmt.Fprintf(w, "  Name:        %s\n", cfg.Meta.Name)
+		}
+		if cfg.Meta.Description != "" {
+			fmt.Fprintf(w, "  Description: %s\n", cfg.Meta.Description)
+		}
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Tools (%d):\n", len(tools))
+	if len(tools) == 0 {
+		fmt.Fprintln(w, "  (none)")
+	}
+	for _, tool := range tools {
+		fmt.Fprintf(w, "  %s\n", tool)
+	}
+	if names := cfg.ProfileNames(); len(names) > 0 {
+		active := make(map[string]bool, len(profiles))
+		for _, name := range profiles {
+			active[name] = true
+		}
+		for i, name := range names {
+			if active[name] {
+				names[i] = name + " (active)"
+			}
+		}
+		fmt.Fprintf(w, "  Profiles: %s\n", strings.Join(names, ", "))
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Configs (%d):\n", len(cfg.Configs))
+	if len(cfg.Configs) == 0 {
+		fmt.Fprintln(w, "  (none)")
+	}
+	for _, file := range cfg.Configs {
+		line := file.Path
+		if file.Target != "" {
+			line += " as " + file.Target
+		}
+		if resolved, err := config.NormalizeConfigPath(file.TargetPath()); err == nil {
+			line += " → " + resolved
+		}
+		if opts := formatConfigOptions(file); opts != "" {
+			line += " " + opts
+		}
+		fmt.Fprintf(w, "  %s\n", line)
+	}
+
+	if len(cfg.VersionProbes) > 0 {
+		names := make([]string, 0, len(cfg.VersionProbes))
+		for name := range cfg.VersionProbes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Version probes:")
+		for _, name := range names {
+			probe := cfg.VersionProbes[name]
+			if probe.Regex != "" {
+				fmt.Fprintf(w, "  %s: %s (match %s)\n", name, probe.Flag, probe.Regex)
+			} else {
+				fmt.Fprintf(w, "  %s: %s\n", name, probe.Flag)
+			}
+		}
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Git:")
+	fmt.Fprintf(w, "  Remote:            %s\n", valueOrNone(cfg.Git.Remote))
+	fmt.Fprintf(w, "  Branch:            %s\n", valueOrNone(cfg.Git.Branch))
+	fmt.Fprintf(w, "  Per-host branches: %s\n", yesNo(cfg.Git.PerHostBranches))
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Options:")
+	if cfg.Options.BackupRetention > 0 {
+		fmt.Fprintf(w, "  Backup retention:  %d\n", cfg.Options.BackupRetention)
+	} else {
+		fmt.Fprintln(w, "  Backup retention:  (default)")
+	}
+	var defaults []string
+	if cfg.Options.Defaults.Template {
+		defaults = append(defaults, "template")
+	}
+	if cfg.Options.Defaults.Secrets {
+		defaults = append(defaults, "encrypted")
+	}
+	if cfg.Options.Defaults.Private {
+		defaults = append(defaults, "private")
+	}
+	fmt.Fprintf(w, "  Config defaults:   %s\n", valueOrNone(strings.Join(defaults, ", ")))
+
+	return nil
+}
+
+// valueOrNone returns s, or "(none)" if s is empty
+func valueOrNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+// yesNo formats a boolean setting
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// printConfigShowHelp prints help for the config show command
+func printConfigShowHelp() {
+	fmt.Println("Usage: zerb config show [options]")
+	fmt.Println()
+	fmt.Println("Show the active config: tools (with the profiles in ZERB_PROFILES")
+	fmt.Println("applied), tracked config files and where they are applied, git")
+	fmt.Println("settings and options.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -h, --help        Show this help message")
+	fmt.Println("  --version <v>     Show an earlier config version instead; accepts a")
+	fmt.Println("                    full filename or an unambiguous timestamp prefix")
+	fmt.Println("  --raw             Print the config file as written")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  zerb config show")
+	fmt.Println("  zerb config show --version 20250115T1030")
+	fmt.Println("  zerb config show --raw")
+	fmt.Println()
+}
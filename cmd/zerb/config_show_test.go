@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+)
+
+func TestRunConfigShow_InvalidArgs(t *testing.T) {
+	t.Setenv("ZERB_DIR", t.TempDir())
+	for _, args := range [][]string{{"--invalid-flag"}, {"--version"}} {
+		if err := runConfigShow(args); err == nil {
+			t.Errorf("runConfigShow(%v) expected error, got nil", args)
+		}
+	}
+}
+
+func TestRunConfigShow_NotInitialized(t *testing.T) {
+	t.Setenv("ZERB_DIR", filepath.Join(t.TempDir(), "missing"))
+
+	err := runConfigShow(nil)
+	if err == nil || !strings.Contains(err.Error(), "ZERB not initialized") {
+		t.Errorf("runConfigShow() error = %v, want not initialized", err)
+	}
+}
+
+func TestRunConfigShow_MissingVersion(t *testing.T) {
+	zerbDir := setupDiffZerbDir(t, map[string]string{
+		"zerb.20250115T103000.000Z.lua": "zerb = {}",
+	}, "zerb.20250115T103000.000Z.lua")
+	t.Setenv("ZERB_DIR", zerbDir)
+
+	err := runConfigShow([]string{"--version=2024"})
+	if !errors.Is(err, errConfigVersionNotFound) {
+		t.Errorf("runConfigShow() error = %v, want errConfigVersionNotFound", err)
+	}
+}
+
+func TestPrintConfigShow(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	cfg := &config.Config{
+		Meta:     config.Meta{Name: "laptop"},
+		Tools:    []string{"node@20", "go@1.24"},
+		Profiles: map[string][]string{"work": {"node@22", "kubectl@1.31"}, "gui": {"alacritty"}},
+		Configs: []config.ConfigFile{
+			{Path: "~/.zshrc", Template: true},
+			{Path: "~/dotfiles/gitconfig", Target: "~/.gitconfig", Private: true},
+		},
+		Git:     config.GitConfig{Branch: "main"},
+		Options: config.Options{BackupRetention: 5, Defaults: config.ConfigDefaults{Private: true}},
+	}
+
+	var buf bytes.Buffer
+	if err := printConfigShow(&buf, "zerb.20250115T103000.000Z.lua", true, cfg, []string{"work"}); err != nil {
+		t.Fatalf("printConfigShow() error = %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"Config version: zerb.20250115T103000.000Z.lua (active)",
+		"Name:        laptop",
+		"Tools (3):",
+		"  node@22\n",
+		"  kubectl@1.31\n",
+		"Profiles: gui, work (active)",
+		"~/.zshrc → " + filepath.Join(home, ".zshrc") + " (template)",
+		"~/dotfiles/gitconfig as ~/.gitconfig → " + filepath.Join(home, ".gitconfig") + " (private)",
+		"Remote:            (none)",
+		"Branch:            main",
+		"Per-host branches: no",
+		"Backup retention:  5",
+		"Config defaults:   private",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "node@20") {
+		t.Errorf("output lists the tool the profile replaced:\n%s", out)
+	}
+
+	if err := printConfigShow(&buf, "zerb.20250115T103000.000Z.lua", false, cfg, []string{"missing"}); !errors.Is(err, config.ErrUnknownProfile) {
+		t.Errorf("printConfigShow() with unknown profile error = %v, want ErrUnknownProfile", err)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/chezmoi"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/prompt"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/service"
+)
+
+// runConfigUntrackAll handles the `zerb config untrack-all` subcommand
+func runConfigUntrackAll(args []string) error {
+	// Parse flags
+	showHelp := false
+	dryRun := false
+	skipConfirm := false
+
+	for _, arg := range args {
+		switch arg {
+		case "--help", "-h":
+			showHelp = true
+		case "--dry-run", "-n":
+			dryRun = true
+		case "--yes", "-y":
+			skipConfirm = true
+		default:
+			return fmt.Errorf("unknown option: %s\nRun 'zerb config untrack-all --help' for usage", arg)
+		}
+	}
+
+	if showHelp {
+		printConfigUntrackAllHelp()
+		return nil
+	}
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	// Get ZERB directory
+	zerbDir, err := getZerbDir()
+	if err != nil {
+		return fmt.Errorf("get ZERB directory: %w", err)
+	}
+	if err := ensureLayout(zerbDir); err != nil {
+		return err
+	}
+
+	// Check if ZERB is initialized
+	if _, err := os.Stat(zerbDir); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("ZERB not initialized\nRun 'zerb init' to set up ZERB first")
+		}
+		return fmt.Errorf("check ZERB directory: %w", err)
+	}
+
+	// Create service
+	clock := service.RealClock{}
+	svc := service.NewConfigRemoveService(
+		chezmoi.NewClient(zerbDir),
+		git.NewClient(zerbDir),
+		config.NewParser(nil),
+		config.NewGenerator().WithClock(clock),
+		clock,
+		zerbDir,
+	)
+
+	// Enumerate what would be untracked
+	preview, err := svc.Execute(ctx, service.RemoveRequest{All: true, DryRun: true})
+	if err != nil {
+		return err
+	}
+
+	if len(preview.RemovedPaths) == 0 {
+		fmt.Println("No configuration files are being tracked.")
+		return nil
+	}
+
+	if dryRun {
+		fmt.Println("Dry run - no changes made")
+		fmt.Println()
+		fmt.Println("Would untrack:")
+	} else {
+		fmt.Println("The following configuration files will be untracked:")
+	}
+	for _, path := range preview.RemovedPaths {
+		fmt.Printf("  - %s\n", path)
+	}
+
+	if dryRun {
+		return nil
+	}
+
+	p := newPrompter()
+	if skipConfirm {
+		p = p.WithAssumeYes(true)
+	}
+	confirmed, err := confirmUntrackAll(p, len(preview.RemovedPaths))
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Println("Untrack cancelled.")
+		return nil
+	}
+
+	result, err := svc.Execute(ctx, service.RemoveRequest{All: true})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Printf("✓ Untracked %d configuration file(s)\n", len(result.RemovedPaths))
+	fmt.Println("  Files on disk were left untouched.")
+	if result.CommitHash != "" {
+		fmt.Printf("Committed: %s\n", result.CommitHash[:8])
+	}
+	if result.ConfigVersion != "" {
+		fmt.Printf("Config version: %s\n", result.ConfigVersion)
+	}
+
+	return nil
+}
+
+// confirmUntrackAll prompts the user before untracking every config
+func confirmUntrackAll(p *prompt.Prompter, count int) (bool, error) {
+	fmt.Println()
+	confirmed, err := p.Confirm(fmt.Sprintf("Untrack all %d configuration file(s)? (yes/no): ", count))
+	if err != nil {
+		return false, fmt.Errorf("read confirmation: %w", err)
+	}
+	return confirmed, nil
+}
+
+// printConfigUntrackAllHelp prints help for the config untrack-all command
+func printConfigUntrackAllHelp() {
+	fmt.Println("Usage: zerb config untrack-all [options]")
+	fmt.Println()
+	fmt.Println("Stop tracking every configuration file in one step.")
+	fmt.Println("Files on disk are not removed or modified.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -h, --help       Show this help message")
+	fmt.Println("  -n, --dry-run    Show what would be untracked without making changes")
+	fmt.Println("  -y, --yes        Skip the confirmation prompt")
+	fmt.Println()
+	fmt.Println("Notes:")
+	fmt.Println("  - A new config version with no tracked files is created")
+	fmt.Println("  - Changes are committed to git automatically")
+	fmt.Println()
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRunConfigUntrackAll_UnknownFlag(t *testing.T) {
+	err := runConfigUntrackAll([]string{"--invalid-flag"})
+	if err == nil {
+		t.Error("expected error for unknown flag, got nil")
+	}
+}
+
+func TestRunConfigUntrackAll_NotInitialized(t *testing.T) {
+	// Point ZERB_DIR at a directory that does not exist
+	t.Setenv("ZERB_DIR", filepath.Join(t.TempDir(), "missing"))
+
+	err := runConfigUntrackAll([]string{"--yes"})
+	if err == nil {
+		t.Error("expected error for uninitialized ZERB, got nil")
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+)
+
+// runDiffEnv handles the `zerb diff-env` subcommand
+func runDiffEnv(args []string) error {
+	// Parse flags
+	showHelp := false
+	jsonOutput := false
+	var paths []string
+
+	for _, arg := range args {
+		switch arg {
+		case "--help", "-h":
+			showHelp = true
+		case "--json":
+			jsonOutput = true
+		default:
+			if strings.HasPrefix(arg, "-") {
+				return fmt.Errorf("unknown option: %s\nRun 'zerb diff-env --help' for usage", arg)
+			}
+			paths = append(paths, arg)
+		}
+	}
+
+	if showHelp {
+		printDiffEnvHelp()
+		return nil
+	}
+
+	if len(paths) != 1 {
+		return fmt.Errorf("expected one exported config, got %d\nUsage: zerb diff-env <other-export.json>", len(paths))
+	}
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Get ZERB directory
+	zerbDir, err := getZerbDir()
+	if err != nil {
+		return fmt.Errorf("get ZERB directory: %w", err)
+	}
+	if err := ensureLayout(zerbDir); err != nil {
+		return err
+	}
+
+	// Check if ZERB is initialized
+	if _, err := os.Stat(filepath.Join(zerbDir, "configs")); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("ZERB not initialized\nRun 'zerb init' to set up ZERB first")
+		}
+		return fmt.Errorf("check ZERB directory: %w", err)
+	}
+
+	local, err := loadConfigVersion(ctx, zerbDir, activeVersionAlias)
+	if err != nil {
+		return err
+	}
+	other, err := loadExportedConfig(paths[0])
+	if err != nil {
+		return err
+	}
+
+	diff := config.DiffConfigs(local, other)
+
+	if jsonOutput {
+		return writeConfigDiffJSON(os.Stdout, diff)
+	}
+
+	printDiffEnv(os.Stdout, paths[0], diff, useColor(os.Stdout))
+	return nil
+}
+
+// loadExportedConfig reads another machine's exported config
+func loadExportedConfig(path string) (*config.Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("exported config not found: %s", path)
+		}
+		return nil, fmt.Errorf("read exported config: %w", err)
+	}
+
+	cfg, err := config.ParseJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("exported config %s is invalid: %w", filepath.Base(path), err)
+	}
+	return cfg, nil
+}
+
+// printDiffEnv prints the differences between the local config and an
+// exported one: "-" lines are only on this machine, "+" lines only on the other
+func printDiffEnv(w io.Writer, otherPath string, diff *config.Diff, color bool) {
+	fmt.Fprintf(w, "Comparing this machine (-) with %s (+)\n\n", filepath.Base(otherPath))
+	printConfigDiff(w, diff, color)
+}
+
+// printDiffEnvHelp prints help for the diff-env command
+func printDiffEnvHelp() {
+	fmt.Println("Usage: zerb diff-env [options] <other-export.json>")
+	fmt.Println()
+	fmt.Println("Compare the active config on this machine with a config exported")
+	fmt.Println("as JSON on another machine with 'zerb config export'. Lines marked -")
+	fmt.Println("are only on this machine, lines marked + only on the other.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -h, --help    Show this help message")
+	fmt.Println("  --json        Output the diff as JSON")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  zerb diff-env laptop.json           Compare with a teammate's export")
+	fmt.Println("  zerb diff-env --json laptop.json")
+	fmt.Println()
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+)
+
+// writeExport writes cfg as an exported config and returns its path
+func writeExport(t *testing.T, name string, cfg *config.Config) string {
+	t.Helper()
+
+	data, err := config.ExportJSON(cfg)
+	if err != nil {
+		t.Fatalf("ExportJSON() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("failed to write export: %v", err)
+	}
+	return path
+}
+
+func TestDiffEnv_Exports(t *testing.T) {
+	laptop := writeExport(t, "laptop.json", &config.Config{
+		Tools:   []string{"node@20.11.0", "python@3.12.1", "cargo:ripgrep@14.0.0"},
+		Configs: []config.ConfigFile{{Path: "~/.gitconfig"}, {Path: "~/.zshrc"}},
+	})
+	desktop := writeExport(t, "desktop.json", &config.Config{
+		Tools:   []string{"node@21.0.0", "cargo:ripgrep@14.0.0", "go@1.22.0"},
+		Configs: []config.ConfigFile{{Path: "~/.gitconfig"}, {Path: "~/.tmux.conf"}},
+	})
+
+	a, err := loadExportedConfig(laptop)
+	if err != nil {
+		t.Fatalf("loadExportedConfig(laptop) error = %v", err)
+	}
+	b, err := loadExportedConfig(desktop)
+	if err != nil {
+		t.Fatalf("loadExportedConfig(desktop) error = %v", err)
+	}
+
+	diff := config.DiffConfigs(a, b)
+
+	// The shared ripgrep and .gitconfig entries are not reported
+	want := config.ToolsDiff{
+		Added:   []string{"go@1.22.0"},
+		Removed: []string{"python@3.12.1"},
+		Changed: []config.ToolChange{{Name: "node", From: "20.11.0", To: "21.0.0"}},
+	}
+	if !reflect.DeepEqual(diff.Tools, want) {
+		t.Errorf("Tools = %+v, want %+v", diff.Tools, want)
+	}
+	wantConfigs := config.ConfigsDiff{Added: []string{"~/.tmux.conf"}, Removed: []string{"~/.zshrc"}}
+	if !reflect.DeepEqual(diff.Configs, wantConfigs) {
+		t.Errorf("Configs = %+v, want %+v", diff.Configs, wantConfigs)
+	}
+
+	var out bytes.Buffer
+	printDiffEnv(&out, desktop, diff, false)
+	for _, line := range []string{
+		"Comparing this machine (-) with desktop.json (+)",
+		"  - python@3.12.1",
+		"  + go@1.22.0",
+		"  - node@20.11.0",
+		"  + node@21.0.0",
+		"  + ~/.tmux.conf",
+	} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("output missing %q:\n%s", line, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "ripgrep") || strings.Contains(out.String(), ".gitconfig") {
+		t.Errorf("output reports shared entries:\n%s", out.String())
+	}
+
+	// Identical exports have no differences
+	out.Reset()
+	printDiffEnv(&out, laptop, config.DiffConfigs(a, a), false)
+	if !strings.Contains(out.String(), "No differences.") {
+		t.Errorf("identical exports output = %q, want No differences.", out.String())
+	}
+}
+
+func TestRunDiffEnv_AgainstActiveConfig(t *testing.T) {
+	zerbDir := setupDiffZerbDir(t, map[string]string{
+		"zerb.20250115T103000.000Z.lua": `zerb = { tools = { "node@20.11.0", "jq@1.7.1" } }`,
+	}, "zerb.20250115T103000.000Z.lua")
+	t.Setenv("ZERB_DIR", zerbDir)
+
+	other := writeExport(t, "other.json", &config.Config{Tools: []string{"node@20.11.0"}})
+	if err := runDiffEnv([]string{other}); err != nil {
+		t.Errorf("runDiffEnv() error = %v", err)
+	}
+}
+
+func TestRunDiffEnv_Errors(t *testing.T) {
+	zerbDir := setupDiffZerbDir(t, map[string]string{
+		"zerb.20250115T103000.000Z.lua": "zerb = {}",
+	}, "zerb.20250115T103000.000Z.lua")
+	t.Setenv("ZERB_DIR", zerbDir)
+
+	invalid := filepath.Join(t.TempDir(), "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`{"tools": ["bad tool!"]}`), 0644); err != nil {
+		t.Fatalf("failed to write export: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"no export", nil, "expected one exported config"},
+		{"two exports", []string{"a.json", "b.json"}, "expected one exported config"},
+		{"unknown option", []string{"--bogus", "a.json"}, "unknown option: --bogus"},
+		{"missing export", []string{filepath.Join(t.TempDir(), "nope.json")}, "exported config not found"},
+		{"invalid export", []string{invalid}, "exported config invalid.json is invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runDiffEnv(tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("runDiffEnv(%v) error = %v, want %q", tt.args, err, tt.wantErr)
+			}
+		})
+	}
+}
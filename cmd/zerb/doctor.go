@@ -0,0 +1,473 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/binary"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/drift"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/platform"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/service"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/shell"
+)
+
+// doctorCheck is one health check of a ZERB installation. check returns
+// the problem found, nil when healthy; fix, if set, repairs it and
+// describes what it did. suggest tells the user how to resolve a problem
+// that is left. A problem of an advisory check is a warning and does not
+// fail the run.
+type doctorCheck struct {
+	name     string
+	check    func(ctx context.Context) error
+	fix      func(ctx context.Context) (string, error)
+	suggest  string
+	advisory bool
+}
+
+// reinstallBinaries installs the core components missing from zerbDir.
+// Tests replace it to avoid downloads.
+var reinstallBinaries = func(ctx context.Context, zerbDir string) error {
+	return reinstallCoreComponents(ctx, zerbDir, "", binary.Version{})
+}
+
+// runDoctor handles the `zerb doctor` subcommand
+// Returns an exit code (0 = healthy, 1 = problems remain) and an error
+func runDoctor(args []string) (int, error) {
+	// Parse flags
+	showHelp := false
+	fix := false
+
+	for _, arg := range args {
+		switch arg {
+		case "--help", "-h":
+			showHelp = true
+		case "--fix":
+			fix = true
+		default:
+			return 1, fmt.Errorf("unknown option: %s\nRun 'zerb doctor --help' for usage", arg)
+		}
+	}
+
+	if showHelp {
+		printDoctorHelp()
+		return 0, nil
+	}
+
+	// Create context with timeout (repairs may download binaries)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	// Get ZERB directory
+	zerbDir, err := getZerbDir()
+	if err != nil {
+		return 1, fmt.Errorf("get ZERB directory: %w", err)
+	}
+	if err := ensureLayout(zerbDir); err != nil {
+		return 1, err
+	}
+	if !isAlreadyInitialized(zerbDir) {
+		return 1, fmt.Errorf("ZERB not initialized\nRun 'zerb init' to set up ZERB first")
+	}
+
+	platformInfo, err := detectPlatform(ctx)
+	if err != nil {
+		return 1, err
+	}
+	manager, err := newBinaryManager(zerbDir, platformInfo)
+	if err != nil {
+		return 1, err
+	}
+
+	return runDoctorChecks(ctx, os.Stdout, doctorChecks(zerbDir, manager), fix), nil
+}
+
+// runDoctorChecks runs each check and prints its result: passed (✓),
+// warning (⚠) or failed (✗). With fix, each failing check's repair is run
+// and the check repeated. Returns 1 if any failure remains; warnings alone
+// do not fail the run.
+func runDoctorChecks(ctx context.Context, w io.Writer, checks []doctorCheck, fix bool) int {
+	failed, warned, fixable := 0, 0, 0
+	for _, c := range checks {
+		problem := c.check(ctx)
+		if problem == nil {
+			fmt.Fprintf(w, "✓ %s\n", c.name)
+			continue
+		}
+		if c.advisory {
+			fmt.Fprintf(w, "⚠ %s: %v\n", c.name, problem)
+		} else {
+			fmt.Fprintf(w, "✗ %s: %v\n", c.name, problem)
+		}
+
+		if fix && c.fix != nil {
+			done, err := c.fix(ctx)
+			if err == nil {
+				err = c.check(ctx)
+			}
+			if err == nil {
+				fmt.Fprintf(w, "  Fixed: %s\n", done)
+				continue
+			}
+			fmt.Fprintf(w, "  Still needs attention: %v\n", err)
+		} else if c.fix != nil {
+			fixable++
+		}
+		if c.suggest != "" {
+			fmt.Fprintf(w, "  Suggested fix: %s\n", c.suggest)
+		}
+		if c.advisory {
+			warned++
+		} else {
+			failed++
+		}
+	}
+
+	fmt.Fprintln(w)
+	if failed == 0 && warned == 0 {
+		fmt.Fprintln(w, "✓ No problems found")
+		return 0
+	}
+	if failed == 0 {
+		fmt.Fprintf(w, "No problems found, %d warning(s)\n", warned)
+		return 0
+	}
+	if warned > 0 {
+		fmt.Fprintf(w, "%d problem(s) found, %d warning(s)\n", failed, warned)
+	} else {
+		fmt.Fprintf(w, "%d problem(s) found\n", failed)
+	}
+	if fixable > 0 {
+		fmt.Fprintf(w, "Run 'zerb doctor --fix' to repair %d of them\n", fixable)
+	}
+	return 1
+}
+
+// doctorChecks returns the checks of the installation in zerbDir, using
+// manager for the verification keys and core components
+func doctorChecks(zerbDir string, manager *binary.Manager) []doctorCheck {
+	checks := []doctorCheck{
+		{
+			name:    "zerb on PATH",
+			check:   func(ctx context.Context) error { return checkZerbPath() },
+			suggest: "add the directory containing zerb to PATH in your shell's rc file",
+		},
+		{
+			name:  "Verification keys",
+			check: func(ctx context.Context) error { return checkKeyrings(manager) },
+			fix: func(ctx context.Context) (string, error) {
+				// Replace mismatched keys too, not only missing ones
+				force := manager.VerifyKeyrings() != nil
+				if err := repairKeyrings(io.Discard, manager, force); err != nil {
+					return "", err
+				}
+				return "re-extracted the verification keys", nil
+			},
+		},
+	}
+
+	components := []struct {
+		name   string
+		binary binary.Binary
+	}{
+		{"Tool manager", binary.BinaryMise},
+		{"Configuration manager", binary.BinaryChezmoi},
+	}
+	for _, component := range components {
+		checks = append(checks, doctorCheck{
+			name:  component.name,
+			check: func(ctx context.Context) error { return checkComponent(manager, component.binary) },
+			fix: func(ctx context.Context) (string, error) {
+				// Install skips binaries that are present, so remove a broken one first
+				if err := os.Remove(manager.GetBinaryPath(component.binary)); err != nil && !os.IsNotExist(err) {
+					return "", fmt.Errorf("remove broken %s: %w", component.binary.Label(), err)
+				}
+				if err := manager.ClearIntegrity(component.binary); err != nil {
+					return "", err
+				}
+				if err := reinstallBinaries(ctx, zerbDir); err != nil {
+					return "", err
+				}
+				return "reinstalled the " + component.binary.Label(), nil
+			},
+		})
+	}
+
+	checks = append(checks,
+		doctorCheck{
+			name:  "Active config",
+			check: func(ctx context.Context) error { return checkActiveConfig(ctx, zerbDir) },
+			fix:   func(ctx context.Context) (string, error) { return repairActiveConfig(ctx, zerbDir) },
+		},
+		doctorCheck{
+			name:  "Shell integration",
+			check: func(ctx context.Context) error { return checkShellIntegration() },
+			fix: func(ctx context.Context) (string, error) {
+				shellManager, err := newShellManager(zerbDir)
+				if err != nil {
+					return "", err
+				}
+				result, err := shellManager.DetectAndSetup(ctx, shell.SetupOptions{Backup: true, BackupRetention: backupRetention(ctx, zerbDir)})
+				if err != nil {
+					return "", err
+				}
+				return "added activation to " + result.RCFile, nil
+			},
+		},
+		doctorCheck{
+			name:     "Shell activation",
+			check:    func(ctx context.Context) error { return checkShellActivation(ctx, zerbDir) },
+			advisory: true,
+		},
+		doctorCheck{
+			name:     "Tool drift",
+			check:    func(ctx context.Context) error { return checkDrift(ctx, zerbDir) },
+			suggest:  "run 'zerb drift' for details and 'zerb drift --fix' to resolve",
+			advisory: true,
+		},
+	)
+	return checks
+}
+
+// checkZerbPath reports a zerb that the activation line in rc files
+// cannot run because it is not on PATH
+func checkZerbPath() error {
+	if checkZerbOnPath() == "" {
+		return fmt.Errorf("zerb is not on PATH, so shells cannot activate ZERB")
+	}
+	return nil
+}
+
+// checkDrift reports declared tools that are missing, in another version
+// or not the ones in use, as a count by kind. Drifts that need no action
+// (e.g. an undetected version) are not reported.
+func checkDrift(ctx context.Context, zerbDir string) error {
+	baseline, declared, err := queryDriftBaseline(ctx, filepath.Join(zerbDir, "zerb.active.lua"))
+	if err != nil {
+		return err
+	}
+	if len(baseline) == 0 {
+		return nil
+	}
+	detection, err := detectDrift(ctx, zerbDir, baseline, declared, driftQuery{Strict: true})
+	if err != nil {
+		return err
+	}
+
+	summary := drift.SummarizeDrift(detection.Results)
+	if summary.WorstSeverity() < drift.SeverityWarning {
+		return nil
+	}
+	var kinds []string
+	for d := drift.DriftVersionMismatch; d <= drift.DriftInactiveProfile; d++ {
+		if n := summary.Count(d); n > 0 && d.Severity() >= drift.SeverityWarning {
+			kinds = append(kinds, fmt.Sprintf("%d %s", n, strings.ToLower(strings.ReplaceAll(d.String(), "_", " "))))
+		}
+	}
+	return fmt.Errorf("%d tool(s) drifted from the config (%s)", summary.Drifted(), strings.Join(kinds, ", "))
+}
+
+// checkKeyrings reports missing verification keys or keys that do not
+// match the ones built into zerb
+func checkKeyrings(manager *binary.Manager) error {
+	missing := 0
+	for _, k := range manager.ListKeyrings() {
+		if !k.Present {
+			missing++
+		}
+	}
+	if missing > 0 {
+		return fmt.Errorf("%d verification key(s) missing", missing)
+	}
+	// The underlying error names internal key files, so it is not shown
+	if err := manager.VerifyKeyrings(); err != nil {
+		return fmt.Errorf("verification keys do not match the keys built into zerb")
+	}
+	return nil
+}
+
+// checkComponent reports a core component that is missing, differs from
+// the binary verified at install, or does not run (e.g. a truncated
+// download)
+func checkComponent(manager *binary.Manager, b binary.Binary) error {
+	installed, err := manager.IsInstalled(b)
+	if err != nil {
+		return err
+	}
+	if !installed {
+		return fmt.Errorf("%s is missing", b.Label())
+	}
+	// Checked before running it, which a tampered binary must not be
+	var tampered *binary.TamperedError
+	if err := manager.CheckIntegrity(b); errors.As(err, &tampered) {
+		return fmt.Errorf("%s has changed since it was verified; it may have been tampered with", b.Label())
+	} else if err != nil {
+		return err
+	}
+	if _, err := manager.InstalledVersion(b); err != nil {
+		return fmt.Errorf("%s does not run; it may be corrupted", b.Label())
+	}
+	return nil
+}
+
+// checkActiveConfig reports a .zerb-active marker and zerb.active.lua
+// symlink that do not both name the same valid snapshot in configs/
+func checkActiveConfig(ctx context.Context, zerbDir string) error {
+	marker, markerErr := activeMarker(zerbDir)
+	link, linkErr := activeLinkTarget(zerbDir)
+	switch {
+	case markerErr != nil:
+		return markerErr
+	case linkErr != nil:
+		return linkErr
+	case marker != link:
+		return fmt.Errorf(".zerb-active names %s but zerb.active.lua points to %s", marker, link)
+	}
+	return parseSnapshot(ctx, zerbDir, marker)
+}
+
+// repairActiveConfig points the marker and the symlink at one valid
+// snapshot: the marker's, else the symlink's, else the newest that parses
+func repairActiveConfig(ctx context.Context, zerbDir string) (string, error) {
+	var candidates []string
+	if marker, err := activeMarker(zerbDir); err == nil {
+		candidates = append(candidates, marker)
+	}
+	if link, err := activeLinkTarget(zerbDir); err == nil {
+		candidates = append(candidates, link)
+	}
+	snapshots, err := filepath.Glob(filepath.Join(zerbDir, "configs", "zerb.*.lua"))
+	if err != nil {
+		return "", fmt.Errorf("list config snapshots: %w", err)
+	}
+	// Timestamped names sort chronologically
+	sort.Sort(sort.Reverse(sort.StringSlice(snapshots)))
+	for _, snapshot := range snapshots {
+		candidates = append(candidates, filepath.Base(snapshot))
+	}
+
+	for _, filename := range candidates {
+		if parseSnapshot(ctx, zerbDir, filename) != nil {
+			continue
+		}
+		if err := service.ActivateSnapshot(zerbDir, filename); err != nil {
+			return "", err
+		}
+		return "activated " + filename, nil
+	}
+	return "", fmt.Errorf("no valid config snapshot in %s\nRun 'zerb init' to create a new config", filepath.Join(zerbDir, "configs"))
+}
+
+// activeMarker returns the snapshot filename in the .zerb-active marker
+func activeMarker(zerbDir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(zerbDir, ".zerb-active"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf(".zerb-active is missing")
+		}
+		return "", fmt.Errorf("read .zerb-active: %w", err)
+	}
+	filename := strings.TrimSpace(string(data))
+	if filename == "" || filepath.Base(filename) != filename {
+		return "", fmt.Errorf(".zerb-active does not name a config snapshot")
+	}
+	return filename, nil
+}
+
+// activeLinkTarget returns the snapshot filename zerb.active.lua points to,
+// which must exist
+func activeLinkTarget(zerbDir string) (string, error) {
+	target, err := os.Readlink(filepath.Join(zerbDir, "zerb.active.lua"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("zerb.active.lua is missing")
+		}
+		return "", fmt.Errorf("zerb.active.lua is not a symlink into configs/")
+	}
+	if filepath.Dir(target) != "configs" {
+		return "", fmt.Errorf("zerb.active.lua points outside configs/: %s", target)
+	}
+	if _, err := os.Stat(filepath.Join(zerbDir, target)); err != nil {
+		return "", fmt.Errorf("zerb.active.lua is a dangling link to %s", target)
+	}
+	return filepath.Base(target), nil
+}
+
+// parseSnapshot reports whether configs/<filename> parses as a valid config
+func parseSnapshot(ctx context.Context, zerbDir, filename string) error {
+	content, err := os.ReadFile(filepath.Join(zerbDir, "configs", filename))
+	if err != nil {
+		return fmt.Errorf("read %s: %w", filename, err)
+	}
+	if _, err := config.NewParser(platform.NewDetector()).ParseString(ctx, string(content)); err != nil {
+		return fmt.Errorf("%s is not a valid config: %w", filename, err)
+	}
+	return nil
+}
+
+// checkShellIntegration reports a detected shell whose rc file does not
+// activate ZERB
+func checkShellIntegration() error {
+	detected, _ := detectUserShell()
+	if !detected.IsValid() {
+		return fmt.Errorf("could not detect a supported shell; see 'zerb init --help' to set it up manually")
+	}
+	rcPath, err := shell.GetRCFilePath(detected)
+	if err != nil {
+		return fmt.Errorf("get rc file path: %w", err)
+	}
+	present, err := shell.HasActivationLine(rcPath)
+	if err != nil {
+		return fmt.Errorf("check %s: %w", rcPath, err)
+	}
+	if !present {
+		return fmt.Errorf("%s does not activate ZERB", rcPath)
+	}
+	return nil
+}
+
+// checkShellActivation starts the detected shell and reports an rc file
+// that has the activation line but does not activate ZERB when sourced.
+// zerb missing from PATH and a missing activation line are left to the
+// checks before it.
+func checkShellActivation(ctx context.Context, zerbDir string) error {
+	detected, _ := detectUserShell()
+	check, err := verifyShellActivation(ctx, zerbDir, detected)
+	if err != nil {
+		return err
+	}
+	if check != nil && !check.OK() {
+		return errors.New(strings.ReplaceAll(check.Message, "\n", "\n  "))
+	}
+	return nil
+}
+
+// printDoctorHelp prints help for the doctor command
+func printDoctorHelp() {
+	fmt.Println("Usage: zerb doctor [options]")
+	fmt.Println()
+	fmt.Println("Check the ZERB installation for common problems: zerb not on PATH,")
+	fmt.Println("missing verification keys, missing or broken core components, an")
+	fmt.Println("active config that does not parse or whose marker and link disagree")
+	fmt.Println("or dangle, and missing shell integration. Tools that drifted from the")
+	fmt.Println("config, and an rc file that does not activate ZERB in a new shell, are")
+	fmt.Println("reported as warnings.")
+	fmt.Println()
+	fmt.Println("Each check is reported as passed (✓), warning (⚠) or failed (✗),")
+	fmt.Println("with a suggested fix for problems found.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -h, --help  Show this help message")
+	fmt.Println("  --fix       Repair each problem found, then check it again, and")
+	fmt.Println("              report what still needs manual attention")
+	fmt.Println()
+	fmt.Println("Exits 1 if any problem remains; warnings do not change the exit code.")
+	fmt.Println()
+}
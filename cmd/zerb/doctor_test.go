@@ -0,0 +1,296 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/binary"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/platform"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/service"
+)
+
+// doctorTestScripts are stand-ins for the core components that report a version
+var doctorTestScripts = map[string]string{
+	"mise":    "#!/bin/sh\necho 2024.12.7 linux-x64\n",
+	"chezmoi": "#!/bin/sh\necho chezmoi version v2.46.1\n",
+}
+
+// setupDoctorTest creates a healthy installation: zerb on PATH, core
+// components, verification keys, two config snapshots (the newer active)
+// and a bash rc file activating ZERB. Reinstalling restores the component scripts.
+func setupDoctorTest(t *testing.T) (zerbDir string, manager *binary.Manager) {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SHELL", "/bin/bash")
+	if err := os.WriteFile(filepath.Join(home, ".bashrc"), []byte("eval \"$(zerb activate bash)\"\n"), 0644); err != nil {
+		t.Fatalf("failed to write .bashrc: %v", err)
+	}
+
+	// zerb itself, on PATH, activating like `zerb activate`
+	pathDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(pathDir, "zerb"), []byte("#!/bin/sh\necho 'export ZERB_ACTIVE=1'\n"), 0755); err != nil {
+		t.Fatalf("failed to write zerb: %v", err)
+	}
+	t.Setenv("PATH", pathDir+string(os.PathListSeparator)+"/usr/bin:/bin")
+	t.Setenv("ZERB_ACTIVE", "")
+
+	zerbDir = filepath.Join(home, ".config", "zerb")
+	for _, dir := range []string{"bin", "configs"} {
+		if err := os.MkdirAll(filepath.Join(zerbDir, dir), 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+	}
+	writeScripts := func(ctx context.Context, zerbDir string) error {
+		for name, script := range doctorTestScripts {
+			path := filepath.Join(zerbDir, "bin", name)
+			if _, err := os.Stat(path); err == nil {
+				continue
+			}
+			if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := writeScripts(context.Background(), zerbDir); err != nil {
+		t.Fatalf("failed to write components: %v", err)
+	}
+	old := reinstallBinaries
+	reinstallBinaries = writeScripts
+	t.Cleanup(func() { reinstallBinaries = old })
+
+	for _, name := range []string{"zerb.20250101T000000.000Z.lua", "zerb.20250102T000000.000Z.lua"} {
+		if err := os.WriteFile(filepath.Join(zerbDir, "configs", name), []byte("zerb = { tools = {} }\n"), 0600); err != nil {
+			t.Fatalf("failed to write snapshot: %v", err)
+		}
+	}
+	if err := service.ActivateSnapshot(zerbDir, "zerb.20250102T000000.000Z.lua"); err != nil {
+		t.Fatalf("ActivateSnapshot() error = %v", err)
+	}
+
+	manager, err := binary.NewManager(binary.Config{
+		ZerbDir:      zerbDir,
+		PlatformInfo: &platform.Info{OS: "linux", Arch: "amd64"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	if err := manager.EnsureKeyrings(); err != nil {
+		t.Fatalf("EnsureKeyrings() error = %v", err)
+	}
+	return zerbDir, manager
+}
+
+func TestRunDoctorChecks_Fix(t *testing.T) {
+	tests := []struct {
+		name      string
+		check     string
+		breakIt   func(t *testing.T, zerbDir string)
+		wantFixed string
+	}{
+		{
+			name:  "missing keyrings",
+			check: "Verification keys",
+			breakIt: func(t *testing.T, zerbDir string) {
+				if err := os.RemoveAll(filepath.Join(zerbDir, "keyrings")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantFixed: "re-extracted the verification keys",
+		},
+		{
+			name:  "corrupted binary",
+			check: "Tool manager",
+			breakIt: func(t *testing.T, zerbDir string) {
+				if err := os.WriteFile(filepath.Join(zerbDir, "bin", "mise"), []byte("\x7fELF truncated"), 0755); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantFixed: "reinstalled the tool manager",
+		},
+		{
+			name:  "tampered binary",
+			check: "Tool manager",
+			breakIt: func(t *testing.T, zerbDir string) {
+				// Record the installed binary, then swap it for one that still runs
+				recordIntegrity(t, zerbDir)
+				if err := os.WriteFile(filepath.Join(zerbDir, "bin", "mise"), []byte("#!/bin/sh\necho 2024.12.7 linux-x64 # swapped\n"), 0755); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantFixed: "reinstalled the tool manager",
+		},
+		{
+			name:  "missing binary",
+			check: "Configuration manager",
+			breakIt: func(t *testing.T, zerbDir string) {
+				if err := os.Remove(filepath.Join(zerbDir, "bin", "chezmoi")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantFixed: "reinstalled the configuration manager",
+		},
+		{
+			name:  "dangling active symlink",
+			check: "Active config",
+			breakIt: func(t *testing.T, zerbDir string) {
+				// The marker is gone too; the newest snapshot is used
+				link := filepath.Join(zerbDir, "zerb.active.lua")
+				if err := os.Remove(link); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Symlink(filepath.Join("configs", "zerb.20250103T000000.000Z.lua"), link); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Remove(filepath.Join(zerbDir, ".zerb-active")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantFixed: "activated zerb.20250102T000000.000Z.lua",
+		},
+		{
+			name:  "marker and symlink mismatch",
+			check: "Active config",
+			breakIt: func(t *testing.T, zerbDir string) {
+				if err := os.WriteFile(filepath.Join(zerbDir, ".zerb-active"), []byte("zerb.20250101T000000.000Z.lua\n"), 0600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			// The marker decides
+			wantFixed: "activated zerb.20250101T000000.000Z.lua",
+		},
+		{
+			name:  "missing shell integration",
+			check: "Shell integration",
+			breakIt: func(t *testing.T, zerbDir string) {
+				if err := os.WriteFile(filepath.Join(os.Getenv("HOME"), ".bashrc"), []byte("alias ll='ls -l'\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantFixed: "added activation to " + filepath.Join("~", ".bashrc"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zerbDir, manager := setupDoctorTest(t)
+			ctx := context.Background()
+			checks := doctorChecks(zerbDir, manager)
+
+			var out bytes.Buffer
+			if code := runDoctorChecks(ctx, &out, checks, false); code != 0 {
+				t.Fatalf("healthy install: exit code %d\n%s", code, out.String())
+			}
+
+			tt.breakIt(t, zerbDir)
+			out.Reset()
+			if code := runDoctorChecks(ctx, &out, checks, false); code != 1 {
+				t.Errorf("exit code = %d, want 1\n%s", code, out.String())
+			}
+			if !strings.Contains(out.String(), "✗ "+tt.check+":") || !strings.Contains(out.String(), "zerb doctor --fix") {
+				t.Errorf("output does not report %s as fixable:\n%s", tt.check, out.String())
+			}
+
+			out.Reset()
+			if code := runDoctorChecks(ctx, &out, checks, true); code != 0 {
+				t.Errorf("--fix exit code = %d, want 0\n%s", code, out.String())
+			}
+			wantFixed := strings.Replace(tt.wantFixed, "~", os.Getenv("HOME"), 1)
+			if !strings.Contains(out.String(), "Fixed: "+wantFixed) {
+				t.Errorf("--fix output missing %q:\n%s", wantFixed, out.String())
+			}
+
+			out.Reset()
+			if code := runDoctorChecks(ctx, &out, checks, false); code != 0 {
+				t.Errorf("after --fix: exit code %d\n%s", code, out.String())
+			}
+		})
+	}
+}
+
+func TestRunDoctorChecks_NeedsAttention(t *testing.T) {
+	zerbDir, manager := setupDoctorTest(t)
+
+	// No snapshot parses, so there is nothing to activate
+	snapshots, _ := filepath.Glob(filepath.Join(zerbDir, "configs", "*.lua"))
+	for _, snapshot := range snapshots {
+		if err := os.WriteFile(snapshot, []byte("zerb = {"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	if code := runDoctorChecks(context.Background(), &out, doctorChecks(zerbDir, manager), true); code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	if !strings.Contains(out.String(), "Still needs attention: no valid config snapshot") {
+		t.Errorf("output = %s", out.String())
+	}
+	if !strings.Contains(out.String(), "1 problem(s) found") {
+		t.Errorf("output = %s", out.String())
+	}
+}
+
+func TestRunDoctorChecks_ZerbNotOnPath(t *testing.T) {
+	zerbDir, manager := setupDoctorTest(t)
+	t.Setenv("PATH", t.TempDir())
+
+	var out bytes.Buffer
+	if code := runDoctorChecks(context.Background(), &out, doctorChecks(zerbDir, manager), false); code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	for _, want := range []string{"✗ zerb on PATH: zerb is not on PATH", "Suggested fix: add the directory containing zerb to PATH", "1 problem(s) found"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunDoctorChecks_DriftWarning(t *testing.T) {
+	zerbDir, manager := setupDoctorTest(t)
+
+	// A declared tool that is neither installed by ZERB nor on PATH
+	if err := os.WriteFile(filepath.Join(zerbDir, "configs", "zerb.20250102T000000.000Z.lua"), []byte("zerb = { tools = { \"zerbdoctortool@1.0.0\" } }\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	mise := "#!/bin/sh\nif [ \"$1\" = ls ]; then\n  [ \"$2\" = --json ] && echo '{}'\n  exit 0\nfi\necho 2024.12.7 linux-x64\n"
+	if err := os.WriteFile(filepath.Join(zerbDir, "bin", "mise"), []byte(mise), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if code := runDoctorChecks(context.Background(), &out, doctorChecks(zerbDir, manager), false); code != 0 {
+		t.Errorf("exit code = %d, want 0 (drift is a warning)\n%s", code, out.String())
+	}
+	for _, want := range []string{"⚠ Tool drift: 1 tool(s) drifted from the config (1 missing)", "Suggested fix: run 'zerb drift'", "No problems found, 1 warning(s)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunDoctorChecks_ShellActivationWarning(t *testing.T) {
+	zerbDir, manager := setupDoctorTest(t)
+
+	// The activation line is there, but the rc file stops before it
+	bashrc := "return\neval \"$(zerb activate bash)\"\n"
+	if err := os.WriteFile(filepath.Join(os.Getenv("HOME"), ".bashrc"), []byte(bashrc), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if code := runDoctorChecks(context.Background(), &out, doctorChecks(zerbDir, manager), false); code != 0 {
+		t.Errorf("exit code = %d, want 0 (activation is a warning)\n%s", code, out.String())
+	}
+	for _, want := range []string{"✓ Shell integration", "⚠ Shell activation: sourcing", "does not activate ZERB", "No problems found, 1 warning(s)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
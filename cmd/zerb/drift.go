@@ -1,37 +1,151 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/ZebulonRouseFrantzich/zerb/internal/binary"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
 	"github.com/ZebulonRouseFrantzich/zerb/internal/drift"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/fsutil"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/service"
 )
 
+// driftRunResult is the outcome of `zerb drift`. The text output is
+// printed from it, so tests and embedders can assert on it directly.
+type driftRunResult struct {
+	Results  []drift.DriftResult // One entry per checked tool, nil if none were checked
+	Summary  drift.DriftSummary  // Summary of Results
+	Adopted  int                 // Extras adopted into the configuration (--adopt-extras)
+	Resolved int                 // Drifts resolved (--fix)
+	PlanFile string              // Plan written with --plan-out
+	ExitCode int                 // 0 = no drifts left (or --exit-zero), 1 = drifts left
+}
+
+// Remaining returns the number of drifts neither adopted nor resolved
+func (r *driftRunResult) Remaining() int {
+	return r.Summary.Drifted() - r.Adopted - r.Resolved
+}
+
 // runDrift handles the `zerb drift` subcommand
 // Returns an exit code (0 = no drifts, 1 = drifts detected) and an error
 func runDrift(args []string) (int, error) {
+	result, err := runDriftResult(args)
+	if err != nil {
+		return 1, err
+	}
+	return result.ExitCode, nil
+}
+
+// runDriftResult runs `zerb drift` and returns its outcome. A non-nil error
+// always means exit code 1.
+func runDriftResult(args []string) (*driftRunResult, error) {
 	// Parse flags
 	showHelp := false
 	dryRun := false
 	forceRefresh := false
+	adoptExtras := false
+	fix := false
+	shimsOnly := false
+	failFast := false
+	exitZero := false
+	format := "text"
+	var only, ignore []string
+	planOut := ""
+	applyPlan := ""
 
-	for _, arg := range args {
-		switch arg {
-		case "--help", "-h":
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--help" || arg == "-h":
 			showHelp = true
-		case "--dry-run", "-n":
+		case arg == "--dry-run" || arg == "-n":
 			dryRun = true
-		case "--refresh":
+		case arg == "--refresh" || arg == "--no-cache":
 			forceRefresh = true
+		case arg == "--adopt-extras":
+			adoptExtras = true
+		case arg == "--fix":
+			fix = true
+		case arg == "--shims":
+			shimsOnly = true
+		case arg == "--fail-fast":
+			failFast = true
+		case arg == "--exit-zero":
+			exitZero = true
+		case arg == "--format":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("--format requires a value (text, json or sarif)")
+			}
+			i++
+			format = args[i]
+		case strings.HasPrefix(arg, "--format="):
+			format = strings.TrimPrefix(arg, "--format=")
+		case arg == "--only" || arg == "--ignore":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s requires a comma-separated list of tools", arg)
+			}
+			i++
+			if arg == "--only" {
+				only = append(only, drift.ParseToolList(args[i])...)
+			} else {
+				ignore = append(ignore, drift.ParseToolList(args[i])...)
+			}
+		case strings.HasPrefix(arg, "--only="):
+			only = append(only, drift.ParseToolList(strings.TrimPrefix(arg, "--only="))...)
+		case strings.HasPrefix(arg, "--ignore="):
+			ignore = append(ignore, drift.ParseToolList(strings.TrimPrefix(arg, "--ignore="))...)
+		case arg == "--plan-out" || arg == "--apply":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s requires a plan file path", arg)
+			}
+			i++
+			if arg == "--plan-out" {
+				planOut = args[i]
+			} else {
+				applyPlan = args[i]
+			}
+		case strings.HasPrefix(arg, "--plan-out="):
+			planOut = strings.TrimPrefix(arg, "--plan-out=")
+		case strings.HasPrefix(arg, "--apply="):
+			applyPlan = strings.TrimPrefix(arg, "--apply=")
 		}
 	}
 
 	if showHelp {
 		printDriftHelp()
-		return 0, nil
+		return &driftRunResult{}, nil
+	}
+
+	switch format {
+	case "text":
+	case "json", "sarif":
+		if fix || adoptExtras {
+			return nil, fmt.Errorf("--format %s cannot be combined with --fix or --adopt-extras", format)
+		}
+	default:
+		return nil, fmt.Errorf("unknown format: %s (supported: text, json, sarif)", format)
+	}
+
+	if applyPlan != "" && (planOut != "" || fix || adoptExtras || format != "text") {
+		return nil, fmt.Errorf("--apply cannot be combined with --plan-out, --fix, --adopt-extras or --format")
+	}
+	if planOut != "" && (fix || adoptExtras) {
+		return nil, fmt.Errorf("--plan-out cannot be combined with --fix or --adopt-extras")
+	}
+
+	// Machine-readable output owns stdout; progress goes to stderr
+	progress := os.Stdout
+	if format != "text" {
+		progress = os.Stderr
 	}
 
 	// Create context with timeout (2 minutes for potentially slow version detection)
@@ -41,92 +155,545 @@ func runDrift(args []string) (int, error) {
 	// Get ZERB directory
 	zerbDir, err := getZerbDir()
 	if err != nil {
-		return 1, fmt.Errorf("get ZERB directory: %w", err)
+		return nil, fmt.Errorf("get ZERB directory: %w", err)
+	}
+	if err := ensureLayout(zerbDir); err != nil {
+		return nil, err
 	}
 
 	// Check if ZERB is initialized
 	activeConfigPath := filepath.Join(zerbDir, "zerb.active.lua")
 	if _, err := os.Stat(activeConfigPath); err != nil {
 		if os.IsNotExist(err) {
-			return 1, fmt.Errorf("ZERB not initialized\nRun 'zerb init' to set up ZERB first")
+			return nil, fmt.Errorf("ZERB not initialized\nRun 'zerb init' to set up ZERB first")
 		}
-		return 1, fmt.Errorf("check ZERB initialization: %w", err)
+		return nil, fmt.Errorf("check ZERB initialization: %w", err)
+	}
+
+	// Refuse to run core components that were tampered with
+	if err := quickVerifyBinaries(ctx, zerbDir); err != nil {
+		return nil, err
+	}
+
+	// Apply a reviewed plan instead of detecting drift
+	if applyPlan != "" {
+		exitCode, err := applyDriftPlanFile(ctx, applyPlan, activeConfigPath, zerbDir, dryRun)
+		if err != nil {
+			return nil, err
+		}
+		return &driftRunResult{ExitCode: exitCode}, nil
 	}
 
 	if dryRun {
-		fmt.Println("Drift detection (dry-run mode)")
-		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-		fmt.Println()
+		fmt.Fprintln(progress, "Drift detection (dry-run mode)")
+		fmt.Fprintln(progress, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		fmt.Fprintln(progress)
 	}
 
 	// Step 1: Query baseline (declared tools in config)
-	fmt.Println("Reading baseline configuration...")
-	baseline, err := drift.QueryBaseline(ctx, activeConfigPath)
+	fmt.Fprintln(progress, "Reading baseline configuration...")
+	baseline, declared, err := queryDriftBaseline(ctx, activeConfigPath)
 	if err != nil {
-		return 1, fmt.Errorf("query baseline: %w", err)
+		return nil, err
 	}
 
-	if len(baseline) == 0 {
+	// With --adopt-extras an empty baseline is still worth reconciling
+	if len(baseline) == 0 && !adoptExtras {
+		if format != "text" {
+			return &driftRunResult{}, writeDriftResults(format, nil)
+		}
 		fmt.Println()
 		fmt.Println("No tools declared in configuration.")
 		fmt.Println()
 		fmt.Println("To add tools:")
 		fmt.Println("  zerb add node@20")
 		fmt.Println("  zerb add python@3.12")
-		return 0, nil
+		return &driftRunResult{}, nil
+	}
+
+	// Restrict detection to the selected tools, so the others are never queried
+	filter := drift.NewToolFilter(only, ignore)
+	if !filter.IsEmpty() {
+		var unknown []string
+		baseline, unknown = filter.FilterBaseline(baseline)
+		for _, name := range unknown {
+			fmt.Fprintf(os.Stderr, "Warning: %s is not declared in the configuration\n", name)
+		}
+		if len(baseline) == 0 && !adoptExtras {
+			if format != "text" {
+				return &driftRunResult{}, writeDriftResults(format, nil)
+			}
+			fmt.Println()
+			fmt.Println("No declared tools match --only/--ignore.")
+			return &driftRunResult{}, nil
+		}
+	}
+
+	// Steps 2-4: Query managed and active tools (in PATH, or in ZERB's
+	// shims with --shims so the result doesn't depend on whether this shell
+	// is activated) and detect drift
+	detection, err := detectDrift(ctx, zerbDir, baseline, declared, driftQuery{
+		Filter:       filter,
+		ShimsOnly:    shimsOnly,
+		ForceRefresh: forceRefresh,
+		Progress:     progress,
+	})
+	if err != nil {
+		return nil, err
+	}
+	managed, results := detection.Managed, detection.Results
+
+	result := &driftRunResult{Results: results, Summary: drift.SummarizeDrift(results)}
+
+	// Step 5: Format and print report
+	if format != "text" {
+		if err := writeDriftResults(format, results); err != nil {
+			return nil, err
+		}
+		if planOut != "" {
+			if err := writeDriftPlanFile(progress, planOut, results); err != nil {
+				return nil, err
+			}
+			result.PlanFile = planOut
+		}
+		result.ExitCode = driftExitCode(result, exitZero)
+		return result, nil
+	}
+	fmt.Print(drift.FormatDriftReport(result.Results))
+
+	// Optionally write the drifts and suggested actions to a plan file
+	if planOut != "" {
+		if err := writeDriftPlanFile(progress, planOut, results); err != nil {
+			return nil, err
+		}
+		result.PlanFile = planOut
+	}
+
+	// Step 6: Optionally adopt tools installed outside the configuration
+	if adoptExtras {
+		adopted, err := reconcileExtras(ctx, declared, managed, activeConfigPath, zerbDir, dryRun)
+		if err != nil {
+			return nil, err
+		}
+		// Adopted extras are now part of the baseline, so they are not
+		// resolved again
+		result.Adopted = len(adopted)
+		results = withoutAdopted(results, adopted)
+	}
+
+	// Step 7: Optionally resolve drifts
+	if fix && result.Remaining() > 0 {
+		resolved, err := resolveDrifts(ctx, results, activeConfigPath, zerbDir, drift.ApplyOptions{DryRun: dryRun, FailFast: failFast})
+		if err != nil {
+			return nil, err
+		}
+		result.Resolved = resolved
+	}
+
+	// Print remediation hints if there are drifts
+	if result.Remaining() > 0 && !dryRun && !fix {
+		fmt.Println()
+		fmt.Println("To fix drifts:")
+		fmt.Println("  zerb sync        Sync tools to baseline")
+		fmt.Println("  zerb drift --fix Resolve drifts interactively")
+		fmt.Println("  zerb drift --help  Show more options")
+	}
+
+	result.ExitCode = driftExitCode(result, exitZero)
+	return result, nil
+}
+
+// queryDriftBaseline reads the tools the active config declares for this
+// machine (base tools and active profiles) and every tool it could declare
+func queryDriftBaseline(ctx context.Context, activeConfigPath string) (baseline, declared []drift.ToolSpec, err error) {
+	baseline, err = drift.QueryBaseline(ctx, activeConfigPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("query baseline: %w", err)
+	}
+	declared, err = drift.QueryDeclared(ctx, activeConfigPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("query declared tools: %w", err)
+	}
+	return baseline, declared, nil
+}
+
+// driftQuery configures detectDrift
+type driftQuery struct {
+	Filter       drift.ToolFilter // Extras outside it are not reported
+	ShimsOnly    bool             // Detect active tools in ZERB's shims instead of PATH
+	ForceRefresh bool             // Detect versions again instead of using the cache
+	Progress     io.Writer        // Receives a line per step; nil for none
+	Strict       bool             // Fail if managed or active tools cannot be queried
+}
+
+// driftDetection is what detectDrift found
+type driftDetection struct {
+	Managed []drift.Tool
+	Active  []drift.Tool
+	Results []drift.DriftResult
+}
+
+// detectDrift queries the tools installed by ZERB and the active tools of
+// baseline, reading versions with the active config's version probes and
+// the version records in zerbDir, and compares them. It is the detection
+// behind zerb drift, the drift check of zerb sync and zerb doctor.
+//
+// Unless q.Strict, tools that cannot be queried are reported on stderr and
+// treated as absent. A missing tool manager is always an error, as every
+// tool would be reported as missing.
+func detectDrift(ctx context.Context, zerbDir string, baseline, declared []drift.ToolSpec, q driftQuery) (*driftDetection, error) {
+	progress := q.Progress
+	if progress == nil {
+		progress = io.Discard
 	}
+	activeConfigPath := filepath.Join(zerbDir, "zerb.active.lua")
 
-	// Step 2: Query managed tools (ZERB-installed via mise)
-	fmt.Println("Querying managed tools...")
+	fmt.Fprintln(progress, "Querying managed tools...")
 	managed, err := drift.QueryManaged(ctx, zerbDir)
+	if errors.Is(err, binary.ErrBinaryMissing) {
+		return nil, err
+	}
 	if err != nil {
-		// Non-fatal: continue with empty managed list
+		if q.Strict {
+			return nil, fmt.Errorf("could not query managed tools: %w", err)
+		}
 		fmt.Fprintf(os.Stderr, "Warning: could not query managed tools: %v\n", err)
 		managed = []drift.Tool{}
 	}
+	if !q.Filter.IsEmpty() {
+		managed = q.Filter.FilterTools(managed)
+	}
 
-	// Step 3: Query active tools (in PATH)
-	fmt.Println("Detecting active tools in environment...")
+	probes, err := drift.LoadVersionProbes(ctx, activeConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("load version probes: %w", err)
+	}
+	versionOpts := drift.VersionOptions{
+		Probes:  probes,
+		Records: drift.NewVersionRecordStore(drift.VersionRecordsDir(zerbDir)),
+	}
 	toolNames := make([]string, len(baseline))
 	for i, spec := range baseline {
 		toolNames[i] = spec.Name
 	}
-	active, err := drift.QueryActive(ctx, toolNames, forceRefresh)
+	var active []drift.Tool
+	if q.ShimsOnly {
+		fmt.Fprintln(progress, "Detecting active tools in ZERB's environment...")
+		active, err = drift.QueryShimsWithOptions(ctx, zerbDir, toolNames, q.ForceRefresh, versionOpts)
+	} else {
+		fmt.Fprintln(progress, "Detecting active tools in environment...")
+		active, err = drift.QueryActiveWithOptions(ctx, toolNames, q.ForceRefresh, versionOpts)
+	}
 	if err != nil {
-		// Non-fatal: continue with empty active list
+		if q.Strict {
+			return nil, fmt.Errorf("could not query active tools: %w", err)
+		}
 		fmt.Fprintf(os.Stderr, "Warning: could not query active tools: %v\n", err)
 		active = []drift.Tool{}
 	}
 
-	// Step 4: Detect drift
-	results := drift.DetectDrift(baseline, managed, active, zerbDir)
+	return &driftDetection{
+		Managed: managed,
+		Active:  active,
+		Results: drift.DetectDrift(baseline, declared, managed, active, zerbDir),
+	}, nil
+}
 
-	// Step 5: Format and print report
-	report := drift.FormatDriftReport(results)
-	fmt.Print(report)
+// driftExitCode returns 1 if drifts are left, for scripting, unless
+// --exit-zero was given
+func driftExitCode(result *driftRunResult, exitZero bool) int {
+	if result.Remaining() > 0 && !exitZero {
+		return 1
+	}
+	return 0
+}
 
-	// Count drifts for exit code
-	driftCount := 0
+// writeDriftResults writes drift results to stdout in a machine-readable format
+func writeDriftResults(format string, results []drift.DriftResult) error {
+	if results == nil {
+		results = []drift.DriftResult{}
+	}
+	if format == "sarif" {
+		return drift.WriteDriftSARIF(os.Stdout, results, Version, "zerb.active.lua")
+	}
+	return drift.WriteDriftJSON(os.Stdout, results)
+}
+
+// writeDriftPlanFile writes a plan resolving each drift with its suggested
+// action to path, and tells w how to apply it
+func writeDriftPlanFile(w io.Writer, path string, results []drift.DriftResult) error {
+	plan := drift.NewPlanFile(results)
+	var buf bytes.Buffer
+	if err := drift.WritePlanFile(&buf, plan); err != nil {
+		return err
+	}
+	if err := fsutil.WriteFileAtomic(path, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("write plan: %w", err)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "✓ Wrote a plan for %d drift(s) to %s\n", len(plan.Entries), path)
+	if len(plan.Entries) > 0 {
+		fmt.Fprintf(w, "  Review it, then run 'zerb drift --apply %s'\n", path)
+	}
+	return nil
+}
+
+// applyDriftPlanFile applies the entries of the plan at path in order,
+// stopping at the first error, and reports which entries were applied.
+// In dry-run mode it validates the plan and lists the entries.
+func applyDriftPlanFile(ctx context.Context, path, activeConfigPath, zerbDir string, dryRun bool) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 1, fmt.Errorf("open plan: %w", err)
+	}
+	plan, err := drift.ReadPlanFile(f)
+	f.Close()
+	if err != nil {
+		return 1, fmt.Errorf("%s: %w", path, err)
+	}
+
+	miseBinary := filepath.Join(zerbDir, "bin", "mise")
+	if dryRun {
+		fmt.Println("Dry run - no changes made")
+		fmt.Println()
+		fmt.Printf("Would apply %d plan entries:\n", len(plan.Entries))
+		for _, entry := range plan.Entries {
+			fmt.Printf("  %-6s %s (%s)\n", entry.Action, entry.Tool, entry.DriftType)
+		}
+		fmt.Println()
+		if _, err := drift.ApplyPlanFile(ctx, plan, activeConfigPath, zerbDir, miseBinary, drift.ActionOptions{DryRun: true, Out: os.Stdout}); err != nil {
+			return 1, fmt.Errorf("apply plan: %w", err)
+		}
+		return 0, nil
+	}
+
+	applied, err := drift.ApplyPlanFile(ctx, plan, activeConfigPath, zerbDir, miseBinary, drift.ActionOptions{})
+	var adopted []string
+	for _, entry := range applied {
+		fmt.Printf("✓ %-6s %s\n", entry.Action, entry.Tool)
+		if entry.Action == drift.ActionAdopt.PlanName() {
+			adopted = append(adopted, entry.Tool)
+		}
+	}
+	if commitErr := commitAdoptedSnapshot(ctx, zerbDir, adopted); commitErr != nil {
+		return 1, commitErr
+	}
+	if err != nil {
+		fmt.Println()
+		fmt.Printf("Applied %d of %d plan entries; the rest were not attempted.\n", len(applied), len(plan.Entries))
+		return 1, fmt.Errorf("apply plan: %w", err)
+	}
+
+	fmt.Println()
+	fmt.Printf("✓ Applied %d plan entries\n", len(applied))
+	return 0, nil
+}
+
+// reconcileExtras offers to adopt tools installed in ZERB's tool environment
+// that are missing from the configuration, including its inactive profiles.
+// Returns the extras adopted.
+func reconcileExtras(ctx context.Context, declared []drift.ToolSpec, managed []drift.Tool, activeConfigPath, zerbDir string, dryRun bool) ([]drift.DriftResult, error) {
+	extras := drift.FindManagedExtras(declared, managed)
+	if len(extras) == 0 {
+		fmt.Println()
+		fmt.Println("No tools installed outside your configuration.")
+		return nil, nil
+	}
+
+	if dryRun {
+		fmt.Println()
+		fmt.Println("Would adopt into configuration:")
+		for _, extra := range extras {
+			fmt.Printf("  + %s@%s\n", extra.Tool, extra.ManagedVersion)
+		}
+		return nil, nil
+	}
+
+	confirmed, err := drift.PromptAdoptExtras(newPrompter(), extras)
+	if err != nil {
+		return nil, fmt.Errorf("prompt: %w", err)
+	}
+	if !confirmed {
+		fmt.Println("No changes made.")
+		return nil, nil
+	}
+
+	newConfig, err := drift.AdoptManagedExtras(extras, activeConfigPath, zerbDir)
+	if err != nil {
+		return nil, fmt.Errorf("adopt extra tools: %w", err)
+	}
+	tools := make([]string, len(extras))
+	for i, extra := range extras {
+		tools[i] = extra.Tool
+	}
+	if err := commitAdoptedSnapshot(ctx, zerbDir, tools); err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("✓ Adopted %d tool(s) into configuration\n", len(extras))
+	fmt.Printf("Config version: %s\n", newConfig)
+	return extras, nil
+}
+
+// withoutAdopted returns results without the extras reconcileExtras adopted
+func withoutAdopted(results, adopted []drift.DriftResult) []drift.DriftResult {
+	if len(adopted) == 0 {
+		return results
+	}
+	tools := make(map[string]bool, len(adopted))
+	for _, a := range adopted {
+		tools[a.Tool] = true
+	}
+	var kept []drift.DriftResult
+	for _, r := range results {
+		if r.DriftType == drift.DriftExtra && tools[r.Tool] {
+			continue
+		}
+		kept = append(kept, r)
+	}
+	return kept
+}
+
+// resolveDrifts prompts for how to resolve each drift and applies the chosen
+// actions as one batch. In dry-run mode it prints the config diff and tool
+// commands that would result, without changing anything. Drifts that fail to
+// resolve are reported and don't stop the others, unless opts.FailFast is set.
+// Returns the number of drifts resolved.
+func resolveDrifts(ctx context.Context, results []drift.DriftResult, activeConfigPath, zerbDir string, opts drift.ApplyOptions) (int, error) {
+	var drifts []drift.DriftResult
 	for _, r := range results {
 		if r.DriftType != drift.DriftOK {
-			driftCount++
+			drifts = append(drifts, r)
 		}
 	}
 
-	// Print remediation hints if there are drifts
-	if driftCount > 0 && !dryRun {
+	p := newPrompter()
+	mode, err := drift.PromptResolutionMode(p)
+	if err != nil {
+		return 0, fmt.Errorf("prompt: %w", err)
+	}
+
+	actions := make([]drift.DriftAction, len(drifts))
+	for i, r := range drifts {
+		switch mode {
+		case drift.ResolutionAdoptAll:
+			actions[i] = drift.ActionAdopt
+		case drift.ResolutionRevertAll:
+			actions[i] = drift.ActionRevert
+			switch r.DriftType {
+			case drift.DriftManagedButNotActive:
+				// Needs manual PATH investigation; nothing to revert
+				actions[i] = drift.ActionSkip
+			case drift.DriftInactiveProfile:
+				// Declared by another profile; never uninstalled
+				actions[i] = drift.ActionSkip
+			}
+		case drift.ResolutionIndividual:
+			action, err := drift.PromptDriftAction(p, r)
+			if err != nil {
+				return 0, fmt.Errorf("prompt: %w", err)
+			}
+			actions[i] = action
+		default:
+			fmt.Println("No changes made.")
+			return 0, nil
+		}
+	}
+
+	miseBinary := filepath.Join(zerbDir, "bin", "mise")
+	plan, err := drift.ApplyDriftActions(ctx, drifts, actions, activeConfigPath, zerbDir, miseBinary, opts)
+	if err != nil {
+		return 0, fmt.Errorf("apply drift resolutions: %w", err)
+	}
+
+	if opts.DryRun {
+		printDriftPlan(plan)
+		return 0, nil
+	}
+
+	if plan.ConfigVersion != "" {
+		tools := make([]string, len(plan.Adopted))
+		for i, r := range plan.Adopted {
+			tools[i] = r.Tool
+		}
+		if err := commitAdoptedSnapshot(ctx, zerbDir, tools); err != nil {
+			return 0, err
+		}
+	}
+
+	resolved := plan.Resolved()
+	fmt.Println()
+	fmt.Printf("✓ Resolved %d drift(s)\n", resolved)
+	if plan.ConfigVersion != "" {
+		fmt.Printf("Config version: %s\n", plan.ConfigVersion)
+	}
+
+	if len(plan.Failures) > 0 {
+		printApplyFailures(plan.Failures)
+		return resolved, fmt.Errorf("%d drift(s) could not be resolved", len(plan.Failures))
+	}
+	return resolved, nil
+}
+
+// commitAdoptedSnapshot commits the snapshot that tools were adopted into,
+// on this machine's branch when the config enables per-host branches.
+// Nothing is committed if no tools were adopted.
+func commitAdoptedSnapshot(ctx context.Context, zerbDir string, tools []string) error {
+	if len(tools) == 0 {
+		return nil
+	}
+
+	message := fmt.Sprintf("Adopt %d tools from drift", len(tools))
+	body := "Adopted tools:\n- " + strings.Join(tools, "\n- ") + "\n"
+	if len(tools) == 1 {
+		message, body = fmt.Sprintf("Adopt %s from drift", tools[0]), ""
+	}
+
+	svc := service.NewSnapshotCommitService(git.NewClient(zerbDir), config.NewParser(nil), zerbDir)
+	if _, err := svc.CommitActive(ctx, message, body); err != nil {
+		return fmt.Errorf("commit adopted tools: %w", err)
+	}
+	return nil
+}
+
+// printApplyFailures prints the drifts a batch failed to resolve and why
+func printApplyFailures(failures []drift.ApplyFailure) {
+	fmt.Println()
+	fmt.Printf("✗ Failed to resolve %d drift(s):\n", len(failures))
+	for _, f := range failures {
+		fmt.Printf("  %s: %s\n", f.Tool, f.Command)
+		fmt.Printf("    %v\n", f.Err)
+	}
+	fmt.Println()
+	fmt.Println("The other drifts were resolved. Fix the errors above and run")
+	fmt.Println("'zerb drift --fix' again to retry.")
+}
+
+// printDriftPlan prints what resolving drifts would change
+func printDriftPlan(plan *drift.ApplyPlan) {
+	fmt.Println()
+	fmt.Println("Dry run - no changes made")
+
+	if len(plan.Commands) == 0 && plan.Diff.IsEmpty() {
 		fmt.Println()
-		fmt.Println("To fix drifts:")
-		fmt.Println("  zerb sync        Sync tools to baseline")
-		fmt.Println("  zerb drift --help  Show more options")
+		fmt.Println("Nothing would change.")
+		return
 	}
 
-	// Return non-zero exit code if drifts detected (for scripting)
-	if driftCount > 0 {
-		return 1, nil
+	if !plan.Diff.IsEmpty() {
+		fmt.Println()
+		fmt.Println("Configuration changes:")
+		printConfigDiff(os.Stdout, plan.Diff, useColor(os.Stdout))
 	}
 
-	return 0, nil
+	if len(plan.Commands) > 0 {
+		fmt.Println()
+		fmt.Println("Would run:")
+		for _, cmd := range plan.Commands {
+			fmt.Printf("  %s\n", cmd)
+		}
+	}
 }
 
 // printDriftHelp prints help for the drift command
@@ -139,6 +706,26 @@ func printDriftHelp() {
 	fmt.Println("  -h, --help     Show this help message")
 	fmt.Println("  -n, --dry-run  Show what would be detected without side effects")
 	fmt.Println("  --refresh      Force refresh version cache (slower but more accurate)")
+	fmt.Println("  --no-cache     Same as --refresh: detect every version again, ignoring")
+	fmt.Println("                 versions cached by earlier runs")
+	fmt.Println("  --adopt-extras Offer to add tools installed outside the config to it")
+	fmt.Println("  --fix          Resolve drifts by adopting or reverting them")
+	fmt.Println("  --shims        Check ZERB's shims instead of PATH, so results are the")
+	fmt.Println("                 same whether or not this shell is activated")
+	fmt.Println("  --fail-fast    With --fix, stop at the first drift that fails to resolve")
+	fmt.Println("                 instead of resolving the rest and reporting failures")
+	fmt.Println("  --format <fmt> Output format: text (default), json, or sarif for")
+	fmt.Println("                 code-scanning dashboards")
+	fmt.Println("  --exit-zero    Exit 0 even when drifts are detected")
+	fmt.Println("  --plan-out <file>")
+	fmt.Println("                 Write the drifts and a suggested action for each")
+	fmt.Println("                 (adopt, revert or skip) to a JSON plan file to review")
+	fmt.Println("  --apply <file> Apply a plan file's actions in order, stopping at the")
+	fmt.Println("                 first error (with --dry-run, only validate them and show")
+	fmt.Println("                 the tool commands they would run)")
+	fmt.Println("  --only <tools> Only check these tools (comma-separated, case-insensitive)")
+	fmt.Println("  --ignore <tools>")
+	fmt.Println("                 Skip these tools (comma-separated, case-insensitive)")
 	fmt.Println()
 	fmt.Println("Drift types:")
 	fmt.Println("  OK                    Tool matches baseline")
@@ -148,14 +735,23 @@ func printDriftHelp() {
 	fmt.Println("  EXTERNAL_OVERRIDE     External installation taking precedence")
 	fmt.Println("  MANAGED_BUT_NOT_ACTIVE Tool installed but not in PATH")
 	fmt.Println("  VERSION_UNKNOWN       Could not detect version")
+	fmt.Println("  INACTIVE_PROFILE      Tool belongs to a profile not active on this machine (never auto-removed)")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  zerb drift             Check for drift")
 	fmt.Println("  zerb drift --dry-run   Preview drift detection")
 	fmt.Println("  zerb drift --refresh   Force version re-detection")
+	fmt.Println("  zerb drift --adopt-extras  Adopt tools installed outside the config")
+	fmt.Println("  zerb drift --fix --dry-run Preview the changes resolving drifts would make")
+	fmt.Println("  zerb drift --shims     Check drift the same way from any shell")
+	fmt.Println("  zerb drift --only node,go  Check only node and go")
+	fmt.Println("  zerb drift --plan-out plan.json  Write a plan to review and edit")
+	fmt.Println("  zerb drift --apply plan.json     Apply the reviewed plan")
+	fmt.Println("  zerb drift --format json > drift.json  Fail a CI job on drift")
+	fmt.Println("  zerb drift --format sarif --exit-zero > drift.sarif")
 	fmt.Println()
 	fmt.Println("Exit codes:")
 	fmt.Println("  0  No drifts detected")
-	fmt.Println("  1  One or more drifts detected")
+	fmt.Println("  1  One or more drifts detected (0 with --exit-zero)")
 	fmt.Println()
 }
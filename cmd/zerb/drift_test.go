@@ -1,9 +1,18 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/drift"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/prompt"
 )
 
 func TestRunDrift_Help(t *testing.T) {
@@ -111,6 +120,31 @@ func TestRunDrift_NotInitialized(t *testing.T) {
 	}
 }
 
+func TestRunDrift_InvalidFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "unknown format", args: []string{"--format", "xml"}},
+		{name: "missing value", args: []string{"--format"}},
+		{name: "json with fix", args: []string{"--format=json", "--fix"}},
+		{name: "sarif with adopt-extras", args: []string{"--format", "sarif", "--adopt-extras"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ZERB_DIR", t.TempDir())
+			exitCode, err := runDrift(tt.args)
+			if err == nil {
+				t.Error("expected error, got nil")
+			}
+			if exitCode != 1 {
+				t.Errorf("expected exit code 1, got %d", exitCode)
+			}
+		})
+	}
+}
+
 func TestRunDrift_NoTools(t *testing.T) {
 	// Set up a temporary ZERB directory with empty config
 	tmpDir := t.TempDir()
@@ -162,3 +196,186 @@ return zerb`
 		t.Errorf("expected exit code 0, got %d", exitCode)
 	}
 }
+
+// captureStdout returns what fn prints to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	oldStdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = oldStdout }()
+
+	done := make(chan string)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- string(out)
+	}()
+
+	fn()
+	w.Close()
+	return <-done
+}
+
+func TestRunDriftResult_MatchesReport(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("ZERB_DIR", tmpDir)
+
+	for _, dir := range []string{"configs", "bin"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatalf("failed to create %s dir: %v", dir, err)
+		}
+	}
+
+	// The tool manager has nothing installed
+	miseStub := "#!/bin/sh\nif [ \"$2\" = \"--json\" ]; then echo '{}'; fi\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "bin", "mise"), []byte(miseStub), 0755); err != nil {
+		t.Fatalf("failed to create tool manager stub: %v", err)
+	}
+
+	// node is installed outside ZERB at the declared version; python is missing
+	pathDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(pathDir, "node"), []byte("#!/bin/sh\necho v20.11.0\n"), 0755); err != nil {
+		t.Fatalf("failed to create node stub: %v", err)
+	}
+	t.Setenv("PATH", pathDir)
+
+	configContent := `zerb = {
+    tools = { "node@20.11.0", "python@3.12.1" },
+}
+return zerb`
+	configFilename := "zerb.20250101T120000.000Z.lua"
+	if err := os.WriteFile(filepath.Join(tmpDir, "configs", configFilename), []byte(configContent), 0644); err != nil {
+		t.Fatalf("failed to create config: %v", err)
+	}
+	if err := os.Symlink(filepath.Join("configs", configFilename), filepath.Join(tmpDir, "zerb.active.lua")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	var result *driftRunResult
+	var err error
+	output := captureStdout(t, func() {
+		result, err = runDriftResult([]string{"--refresh"})
+	})
+	if err != nil {
+		t.Fatalf("runDriftResult() error = %v", err)
+	}
+
+	if len(result.Results) != 2 {
+		t.Fatalf("Results = %+v, want one per declared tool", result.Results)
+	}
+	if result.Summary.Drifted() != 2 || result.Remaining() != 2 {
+		t.Errorf("Drifted() = %d, Remaining() = %d, want 2", result.Summary.Drifted(), result.Remaining())
+	}
+	if result.ExitCode != 1 {
+		t.Errorf("ExitCode = %d, want 1 with drifts left", result.ExitCode)
+	}
+
+	// The printed report is the result's report
+	if !strings.Contains(output, drift.FormatDriftReport(result.Results)) {
+		t.Errorf("output does not contain the result's report:\n%s", output)
+	}
+	if want := fmt.Sprintf("SUMMARY: %d drifts detected", result.Summary.Drifted()); !strings.Contains(output, want) {
+		t.Errorf("output missing %q:\n%s", want, output)
+	}
+	for _, r := range result.Results {
+		if r.DriftType != drift.DriftOK && !strings.Contains(output, r.Tool) {
+			t.Errorf("output missing drifted tool %s:\n%s", r.Tool, output)
+		}
+	}
+	if !strings.Contains(output, "To fix drifts:") {
+		t.Errorf("output missing remediation hints:\n%s", output)
+	}
+
+	// The drift check before `zerb sync --push` finds the same drifts
+	synced, err := detectDriftResults(context.Background(), tmpDir)
+	if err != nil {
+		t.Fatalf("detectDriftResults() error = %v", err)
+	}
+	if !reflect.DeepEqual(synced, result.Results) {
+		t.Errorf("sync drift check = %+v, want %+v", synced, result.Results)
+	}
+
+	// --exit-zero only changes the exit code
+	output = captureStdout(t, func() {
+		result, err = runDriftResult([]string{"--exit-zero"})
+	})
+	if err != nil {
+		t.Fatalf("runDriftResult(--exit-zero) error = %v", err)
+	}
+	if result.ExitCode != 0 || result.Remaining() != 2 {
+		t.Errorf("--exit-zero: ExitCode = %d, Remaining() = %d, want 0 and 2", result.ExitCode, result.Remaining())
+	}
+}
+
+func TestRunDriftResult_AdoptExtrasAndFix(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("ZERB_DIR", tmpDir)
+	t.Setenv(prompt.EnvAssumeYes, "1")
+
+	for _, dir := range []string{"configs", "bin"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatalf("failed to create %s dir: %v", dir, err)
+		}
+	}
+
+	// ripgrep was installed outside the config; python is missing
+	miseStub := `#!/bin/sh
+if [ "$1" = "ls" ] && [ "$2" = "--json" ]; then
+    echo '{"ripgrep": [{"version": "14.1.0"}]}'
+elif [ "$1" = "ls" ] && [ "$2" = "--current" ]; then
+    echo 'ripgrep  14.1.0'
+fi
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "bin", "mise"), []byte(miseStub), 0755); err != nil {
+		t.Fatalf("failed to create tool manager stub: %v", err)
+	}
+	t.Setenv("PATH", t.TempDir())
+
+	configContent := `zerb = {
+    tools = { "python@3.12.1" },
+}
+return zerb`
+	configFilename := "zerb.20250101T120000.000Z.lua"
+	if err := os.WriteFile(filepath.Join(tmpDir, "configs", configFilename), []byte(configContent), 0644); err != nil {
+		t.Fatalf("failed to create config: %v", err)
+	}
+	if err := os.Symlink(filepath.Join("configs", configFilename), filepath.Join(tmpDir, "zerb.active.lua")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	var result *driftRunResult
+	var err error
+	captureStdout(t, func() {
+		result, err = runDriftResult([]string{"--refresh", "--adopt-extras", "--fix"})
+	})
+	if err != nil {
+		t.Fatalf("runDriftResult() error = %v", err)
+	}
+
+	// ripgrep is adopted once, and only python is left to resolve
+	if result.Adopted != 1 || result.Resolved != 1 || result.Remaining() != 0 {
+		t.Errorf("Adopted = %d, Resolved = %d, Remaining() = %d, want 1, 1 and 0", result.Adopted, result.Resolved, result.Remaining())
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "zerb.active.lua"))
+	if err != nil {
+		t.Fatalf("failed to read active config: %v", err)
+	}
+	cfg, err := config.NewParser(nil).ParseString(context.Background(), string(content))
+	if err != nil {
+		t.Fatalf("failed to parse active config: %v", err)
+	}
+	count := 0
+	for _, tool := range cfg.Tools {
+		if strings.HasPrefix(tool, "ripgrep@") {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("active config declares ripgrep %d times, want once: %v", count, cfg.Tools)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/drift"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/shell"
+)
+
+// runEnv handles the `zerb env` subcommand
+func runEnv(args []string) error {
+	// Parse flags
+	showHelp := false
+	format := shell.EnvFormatBash
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--help" || arg == "-h":
+			showHelp = true
+		case arg == "--shell":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--shell requires a value (bash, fish, or json)\nRun 'zerb env --help' for usage")
+			}
+			i++
+			format = args[i]
+		case strings.HasPrefix(arg, "--shell="):
+			format = strings.TrimPrefix(arg, "--shell=")
+		default:
+			return fmt.Errorf("unknown option: %s\nRun 'zerb env --help' for usage", arg)
+		}
+	}
+
+	if showHelp {
+		printEnvHelp()
+		return nil
+	}
+
+	switch format {
+	case shell.EnvFormatBash, shell.EnvFormatFish, shell.EnvFormatJSON:
+	default:
+		return fmt.Errorf("unsupported shell: %s\nSupported: bash, fish, json", format)
+	}
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Get ZERB directory
+	zerbDir, err := getZerbDir()
+	if err != nil {
+		return fmt.Errorf("get ZERB directory: %w", err)
+	}
+	if err := ensureLayout(zerbDir); err != nil {
+		return err
+	}
+
+	// Check if ZERB is initialized
+	if _, err := os.Stat(filepath.Join(zerbDir, "bin", "mise")); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("ZERB not initialized\nRun 'zerb init' to set up ZERB first")
+		}
+		return fmt.Errorf("check ZERB directory: %w", err)
+	}
+
+	return writeEnv(ctx, os.Stdout, zerbDir, format)
+}
+
+// writeEnv writes the environment of the active config's tools to w in the
+// given format
+func writeEnv(ctx context.Context, w io.Writer, zerbDir, format string) error {
+	env, err := drift.QueryEnv(ctx, zerbDir)
+	if err != nil {
+		return err
+	}
+	out, err := shell.FormatEnv(env, format)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, out)
+	return err
+}
+
+// printEnvHelp prints help for the env command
+func printEnvHelp() {
+	fmt.Println("Usage: zerb env [--shell <shell>]")
+	fmt.Println()
+	fmt.Println("Print the environment your tools need (PATH entries and tool variables)")
+	fmt.Println("for the active config, without activating a shell. Useful in scripts,")
+	fmt.Println("CI jobs, and editors that cannot run the shell activation hook.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -h, --help           Show this help message")
+	fmt.Println("  --shell <shell>      Output format: bash (default, also zsh and sh),")
+	fmt.Println("                       fish, or json")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  eval \"$(zerb env)\"")
+	fmt.Println("  zerb env --shell fish | source")
+	fmt.Println("  zerb env --shell json")
+	fmt.Println()
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupEnvTest creates a ZERB directory whose tool manager stub prints the
+// given output for `env --json` and returns the directory
+func setupEnvTest(t *testing.T, envJSON string) string {
+	t.Helper()
+
+	zerbDir := t.TempDir()
+	binDir := filepath.Join(zerbDir, "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatalf("failed to create bin dir: %v", err)
+	}
+	script := "#!/bin/sh\nif [ \"$1 $2\" != \"env --json\" ]; then\n  echo \"unexpected args: $*\" >&2\n  exit 1\nfi\ncat <<'EOF'\n" + envJSON + "\nEOF\n"
+	if err := os.WriteFile(filepath.Join(binDir, "mise"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to create mock mise: %v", err)
+	}
+	return zerbDir
+}
+
+func TestWriteEnv(t *testing.T) {
+	zerbDir := setupEnvTest(t, `{"PATH": "/zerb/installs/node/20.11.0/bin:/usr/bin", "NODE_ENV": "dev's"}`)
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: "bash",
+			want:   "export NODE_ENV='dev'\\''s'\nexport PATH='/zerb/installs/node/20.11.0/bin:/usr/bin'\n",
+		},
+		{
+			format: "fish",
+			want:   "set -gx NODE_ENV 'dev\\'s'\nset -gx PATH '/zerb/installs/node/20.11.0/bin:/usr/bin'\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := writeEnv(context.Background(), &out, zerbDir, tt.format); err != nil {
+				t.Fatalf("writeEnv() error = %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("writeEnv() =\n%s\nwant:\n%s", out.String(), tt.want)
+			}
+		})
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := writeEnv(context.Background(), &out, zerbDir, "json"); err != nil {
+			t.Fatalf("writeEnv() error = %v", err)
+		}
+		var got map[string]string
+		if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+			t.Fatalf("output is not JSON: %v\n%s", err, out.String())
+		}
+		if got["NODE_ENV"] != "dev's" || len(got) != 2 {
+			t.Errorf("writeEnv() = %v", got)
+		}
+	})
+}
+
+func TestWriteEnv_InvalidOutput(t *testing.T) {
+	zerbDir := setupEnvTest(t, "not json")
+
+	var out bytes.Buffer
+	err := writeEnv(context.Background(), &out, zerbDir, "bash")
+	if err == nil || !strings.Contains(err.Error(), "parse tool environment") {
+		t.Fatalf("writeEnv() error = %v, want parse error", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("writeEnv() wrote %q on error", out.String())
+	}
+}
+
+func TestRunEnv_InvalidShell(t *testing.T) {
+	err := runEnv([]string{"--shell", "tcsh"})
+	if err == nil || !strings.Contains(err.Error(), "unsupported shell") {
+		t.Errorf("runEnv() error = %v, want unsupported shell", err)
+	}
+}
@@ -2,7 +2,10 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -13,58 +16,13 @@ import (
 	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
 	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
 	"github.com/ZebulonRouseFrantzich/zerb/internal/platform"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/service"
 	"github.com/ZebulonRouseFrantzich/zerb/internal/shell"
 )
 
-// createDirectoryStructure creates all required ZERB directories
-// This is idempotent - safe to call multiple times
-func createDirectoryStructure(zerbDir string) error {
-	if zerbDir == "" {
-		return fmt.Errorf("zerbDir cannot be empty")
-	}
-
-	// Define all directories to create
-	dirs := []string{
-		zerbDir,
-		filepath.Join(zerbDir, "bin"),
-		filepath.Join(zerbDir, "keyrings"),
-		filepath.Join(zerbDir, "cache", "downloads"),
-		filepath.Join(zerbDir, "cache", "versions"),
-		filepath.Join(zerbDir, "configs"),
-		filepath.Join(zerbDir, "tmp"),
-		filepath.Join(zerbDir, "logs"),
-		filepath.Join(zerbDir, "mise"),
-		filepath.Join(zerbDir, "chezmoi", "source"),
-	}
-
-	// Create each directory with 0700 permissions (user-only access for security)
-	// This protects git history and config files on multi-user systems
-	for _, dir := range dirs {
-		if err := os.MkdirAll(dir, 0700); err != nil {
-			return fmt.Errorf("create directory %s: %w", dir, err)
-		}
-	}
-
-	return nil
-}
-
 // isAlreadyInitialized checks if ZERB is already initialized in the given directory
 func isAlreadyInitialized(zerbDir string) bool {
-	// Check for key indicators of an initialized ZERB environment
-	indicators := []string{
-		filepath.Join(zerbDir, "bin", "mise"),
-		filepath.Join(zerbDir, "configs"),
-		filepath.Join(zerbDir, ".zerb-active"),
-	}
-
-	// If any key indicator exists, consider it initialized
-	for _, path := range indicators {
-		if _, err := os.Stat(path); err == nil {
-			return true
-		}
-	}
-
-	return false
+	return service.IsInitialized(zerbDir)
 }
 
 // detectPlatform wraps platform detection with context support
@@ -77,119 +35,108 @@ func detectPlatform(ctx context.Context) (*platform.Info, error) {
 	return platformInfo, nil
 }
 
-// installBinaries installs mise and chezmoi binaries
-func installBinaries(ctx context.Context, zerbDir string, platformInfo *platform.Info) error {
-	// Create binary manager
-	binManager, err := binary.NewManager(binary.Config{
-		ZerbDir:      zerbDir,
-		PlatformInfo: platformInfo,
-	})
-	if err != nil {
-		return fmt.Errorf("create binary manager: %w", err)
-	}
-
-	// Extract embedded keyrings
-	if err := binManager.EnsureKeyrings(); err != nil {
-		return fmt.Errorf("extract keyrings: %w", err)
-	}
-
-	// Install mise binary
-	if err := binManager.Install(ctx, binary.DownloadOptions{
-		Binary:  binary.BinaryMise,
-		Version: binary.DefaultVersions.Mise,
-	}); err != nil {
-		return fmt.Errorf("install mise: %w", err)
-	}
-
-	// Install chezmoi binary
-	if err := binManager.Install(ctx, binary.DownloadOptions{
-		Binary:  binary.BinaryChezmoi,
-		Version: binary.DefaultVersions.Chezmoi,
-	}); err != nil {
-		return fmt.Errorf("install chezmoi: %w", err)
-	}
+// downloadProgress renders the progress of one download on a single line,
+// redrawn in place as data arrives
+type downloadProgress struct {
+	w       io.Writer
+	label   string
+	last    string
+	started bool
+}
 
-	return nil
+// newDownloadProgress returns a renderer writing to w; a nil w renders
+// nothing
+func newDownloadProgress(w io.Writer, label string) *downloadProgress {
+	return &downloadProgress{w: w, label: label}
 }
 
-// generateInitialConfig creates an empty initial configuration
-func generateInitialConfig(ctx context.Context, zerbDir string) error {
-	// Create initial minimal config
-	initialConfig := &config.Config{
-		Meta: config.Meta{
-			Name:        "My ZERB Environment",
-			Description: "Created by zerb init",
-		},
-		Tools:   []string{}, // Empty initially - user adds tools with 'zerb add'
-		Configs: []config.ConfigFile{},
-		Git: config.GitConfig{
-			Remote: "", // User can configure later
-			Branch: "main",
-		},
-		Options: config.Options{
-			BackupRetention: 5,
-		},
-	}
-
-	// Generate Lua code
-	generator := config.NewGenerator()
-	luaCode, err := generator.Generate(ctx, initialConfig)
-	if err != nil {
-		return fmt.Errorf("generate config: %w", err)
+// Update redraws the line for downloaded of total bytes (total is -1 when
+// unknown). It is a binary.ProgressFunc.
+func (p *downloadProgress) Update(downloaded, total int64) {
+	if p.w == nil {
+		return
 	}
 
-	// Check for sensitive data in generated config
-	// Note: Empty initial config shouldn't have sensitive data, but we check anyway
-	// This will be more important when users modify their configs
-	findings := config.DetectSensitiveData(luaCode)
-	if len(findings) > 0 {
-		warning := config.FormatSensitiveDataWarning(findings)
-		fmt.Fprint(os.Stderr, warning)
-		// For init, we just warn but don't block since the initial config is safe
-		// Users will see this warning if they later add sensitive data
+	status := formatSize(downloaded)
+	if total > 0 {
+		status = fmt.Sprintf("%d%%", downloaded*100/total)
 	}
-
-	// Create timestamped config filename with milliseconds to ensure uniqueness
-	// Format: zerb.TIMESTAMP.lua (ending in .lua for editor syntax highlighting)
-	timestamp := time.Now().UTC().Format("20060102T150405.000Z")
-	configFilename := fmt.Sprintf("zerb.%s.lua", timestamp)
-	configPath := filepath.Join(zerbDir, "configs", configFilename)
-
-	// Write config file (0600 for security - may contain sensitive data)
-	if err := os.WriteFile(configPath, []byte(luaCode), 0600); err != nil {
-		return fmt.Errorf("write config file: %w", err)
+	// Only redraw when the visible status changes
+	if status == p.last {
+		return
 	}
+	p.last = status
+	p.started = true
+	fmt.Fprintf(p.w, "\r    %s: %s\033[K", p.label, status)
+}
 
-	// Create .zerb-active marker file (0600 for consistency)
-	markerPath := filepath.Join(zerbDir, ".zerb-active")
-	if err := os.WriteFile(markerPath, []byte(configFilename), 0600); err != nil {
-		return fmt.Errorf("write marker file: %w", err)
+// Done ends the progress line, if anything was drawn
+func (p *downloadProgress) Done() {
+	if p.started {
+		fmt.Fprintln(p.w)
+		p.started = false
 	}
+}
 
-	// Create symlink to active config (idempotent: remove existing first)
-	symlinkPath := filepath.Join(zerbDir, "zerb.active.lua")
-	symlinkTarget := filepath.Join("configs", configFilename)
+// initProgress renders a downloadProgress line per core component as init
+// downloads them, ending each line when the next download starts
+type initProgress struct {
+	w       io.Writer
+	binary  binary.Binary
+	current *downloadProgress
+}
 
-	// Remove existing symlink/file if present
-	if err := os.Remove(symlinkPath); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("remove existing active symlink: %w", err)
+// Update is an InitOptions.Progress callback
+func (p *initProgress) Update(b binary.Binary, downloaded, total int64) {
+	if p.current == nil || b != p.binary {
+		p.Done()
+		p.binary = b
+		p.current = newDownloadProgress(p.w, b.Label())
 	}
+	p.current.Update(downloaded, total)
+}
 
-	// Create new symlink
-	if err := os.Symlink(symlinkTarget, symlinkPath); err != nil {
-		return fmt.Errorf("create symlink: %w", err)
+// Done ends the current progress line
+func (p *initProgress) Done() {
+	if p.current != nil {
+		p.current.Done()
 	}
+}
 
-	return nil
+// isTerminal reports whether f is a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
 }
 
-// detectUserShell detects the user's shell without modifying any files
-func detectUserShell() shell.ShellType {
+// detectUserShell detects the user's shell without modifying any files and
+// returns a description of how it was detected
+func detectUserShell() (shell.ShellType, string) {
 	detection, err := shell.DetectShell()
-	if err != nil || !detection.Shell.IsValid() {
-		return shell.ShellUnknown
+	if err != nil {
+		return shell.ShellUnknown, "detection failed"
 	}
-	return detection.Shell
+	return detection.Shell, describeShellDetection(detection)
+}
+
+// describeShellDetection explains how DetectShell reached its result
+func describeShellDetection(d *shell.DetectionResult) string {
+	var how string
+	switch d.Method {
+	case shell.MethodShellEnv:
+		how = "from $SHELL"
+	case shell.MethodParentProcess:
+		how = "from the parent process"
+	default:
+		how = "$SHELL is not set to a supported shell"
+	}
+	if d.Multiplexer != "" {
+		how += fmt.Sprintf("; running inside %s, so the parent process was not used", d.Multiplexer)
+	}
+	return how
 }
 
 // checkZerbOnPath checks if 'zerb' command is accessible on PATH
@@ -201,6 +148,46 @@ func checkZerbOnPath() string {
 	return path
 }
 
+// verifyShellActivation starts a new shellType shell and checks that its
+// rc file activates ZERB. Returns nil without a check when zerb is not on
+// PATH, the rc file has no activation line or the shell cannot be checked.
+func verifyShellActivation(ctx context.Context, zerbDir string, shellType shell.ShellType) (*shell.ActivationCheck, error) {
+	if !shellType.IsValid() || checkZerbOnPath() == "" {
+		return nil, nil
+	}
+	rcPath, err := shell.GetRCFilePath(shellType)
+	if err != nil {
+		return nil, fmt.Errorf("get rc file path: %w", err)
+	}
+	if present, err := shell.HasActivationLine(rcPath); err != nil || !present {
+		return nil, err
+	}
+
+	manager, err := newShellManager(zerbDir)
+	if err != nil {
+		return nil, err
+	}
+	check, err := manager.VerifyActivation(ctx, shellType)
+	if errors.Is(err, shell.ErrVerifyUnsupported) {
+		return nil, nil
+	}
+	return check, err
+}
+
+// printActivationCheck prints the result of verifyShellActivation, if
+// there is one
+func printActivationCheck(w io.Writer, check *shell.ActivationCheck, err error) {
+	switch {
+	case err != nil:
+		fmt.Fprintf(w, "⚠ Could not check shell activation: %v\n", err)
+	case check == nil:
+	case check.OK():
+		fmt.Fprintf(w, "✓ %s\n", check.Message)
+	default:
+		fmt.Fprintf(w, "⚠ %s\n", strings.ReplaceAll(check.Message, "\n", "\n  "))
+	}
+}
+
 // isOnPath checks if a directory is on the PATH by properly splitting and comparing paths
 func isOnPath(dirPath string, pathEnv string) bool {
 	// Clean and get absolute path for comparison
@@ -279,6 +266,101 @@ func printPathWarning() {
 	fmt.Println()
 }
 
+// setupAllShells adds shell integration for every shell on this machine and
+// prints one line per shell. A failure for one shell is printed, not returned.
+// With printOnly, no rc file is changed and the changes are printed instead.
+func setupAllShells(ctx context.Context, zerbDir string, w io.Writer, printOnly bool) error {
+	if printOnly {
+		fmt.Fprintln(w, "Not changing rc files when running non-interactively; add these lines yourself:")
+		return previewShellIntegration(ctx, w, zerbDir, true)
+	}
+
+	manager, err := newShellManager(zerbDir)
+	if err != nil {
+		return err
+	}
+
+	reports, err := manager.SetupAll(ctx, shell.SetupOptions{BackupRetention: backupRetention(ctx, zerbDir)})
+	if err != nil {
+		return err
+	}
+
+	for _, report := range reports {
+		switch report.Status {
+		case shell.SetupAdded:
+			fmt.Fprintf(w, "✓ %s: added activation to %s (backup: %s)\n", report.Shell, report.Result.RCFile, report.Result.BackupPath)
+			if n := len(report.Result.PrunedBackups); n > 0 {
+				fmt.Fprintf(w, "  Removed %d old backup(s)\n", n)
+			}
+		case shell.SetupSkipped:
+			fmt.Fprintf(w, "- %s: skipped (%s)\n", report.Shell, report.Reason)
+		case shell.SetupFailed:
+			fmt.Fprintf(w, "✗ %s: %v\n", report.Shell, report.Err)
+		}
+	}
+	return nil
+}
+
+// backupRetention returns options.backup_retention from the active config:
+// how many rc file backups to keep (0, keep all, if the config can't be read)
+func backupRetention(ctx context.Context, zerbDir string) int {
+	cfg, err := loadConfigVersion(ctx, zerbDir, activeVersionAlias)
+	if err != nil {
+		return 0
+	}
+	return cfg.Options.BackupRetention
+}
+
+// previewShellIntegration prints, as a unified diff, the change shell
+// integration would make to the detected shell's rc file (every shell's with
+// allShells), including the backup that would be taken
+func previewShellIntegration(ctx context.Context, w io.Writer, zerbDir string, allShells bool) error {
+	manager, err := newShellManager(zerbDir)
+	if err != nil {
+		return err
+	}
+	opts := shell.SetupOptions{DryRun: true, Backup: true}
+
+	var results []*shell.SetupResult
+	if allShells {
+		reports, err := manager.SetupAll(ctx, opts)
+		if err != nil {
+			return err
+		}
+		for _, report := range reports {
+			switch report.Status {
+			case shell.SetupFailed:
+				fmt.Fprintf(w, "\n✗ %s: %v\n", report.Shell, report.Err)
+			case shell.SetupSkipped:
+				fmt.Fprintf(w, "\n- %s: skipped (%s)\n", report.Shell, report.Reason)
+			default:
+				results = append(results, report.Result)
+			}
+		}
+	} else {
+		detected, how := detectUserShell()
+		if !detected.IsValid() {
+			fmt.Fprintf(w, "\n⚠ Could not detect your shell (%s)\n", how)
+			fmt.Fprintln(w, "  Use --all-shells to preview every shell")
+			return nil
+		}
+		result, err := manager.SetupIntegration(ctx, detected, opts)
+		if err != nil {
+			return fmt.Errorf("preview %s integration: %w", detected, err)
+		}
+		results = append(results, result)
+	}
+
+	for _, result := range results {
+		if result.Diff == "" {
+			fmt.Fprintf(w, "\n✓ %s: %s already activates ZERB\n", result.Shell, result.RCFile)
+			continue
+		}
+		fmt.Fprintf(w, "\n%s: %s\n%s", result.Shell, result.RCFile, result.Diff)
+	}
+	return nil
+}
+
 // printShellIntegrationInstructions prints instructions for manually adding shell integration
 func printShellIntegrationInstructions(detectedShell shell.ShellType) {
 	fmt.Println()
@@ -406,8 +488,309 @@ func printSuccessMessage(zerbDir string, detectedShell shell.ShellType) {
 	fmt.Println()
 }
 
+// componentVersionFlags maps the init flags pinning a core component
+// version to the component and an example of its version format
+var componentVersionFlags = map[string]struct {
+	binary  binary.Binary
+	example string
+}{
+	"--tool-manager-version":   {binary.BinaryMise, "a date version like 2024.12.7"},
+	"--config-manager-version": {binary.BinaryChezmoi, "a version like v2.46.1"},
+}
+
+// setComponentVersion validates value as the version pinned by flag and
+// sets it in versions
+func setComponentVersion(versions *binary.Version, flag, value string) error {
+	component := componentVersionFlags[flag]
+	if _, err := binary.NormalizeVersion(component.binary, value); err != nil {
+		return fmt.Errorf("invalid %s %q: expected %s", flag, value, component.example)
+	}
+	switch component.binary {
+	case binary.BinaryMise:
+		versions.Mise = value
+	case binary.BinaryChezmoi:
+		versions.Chezmoi = value
+	}
+	return nil
+}
+
+// printInitHelp prints help for the init command
+func printInitHelp() {
+	fmt.Println("Usage: zerb init [options]")
+	fmt.Println()
+	fmt.Println("Set up ZERB: create the ZERB directory, install core components")
+	fmt.Println("and write the initial config. Running it again resumes an init that")
+	fmt.Println("was interrupted, e.g. by a failed download.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -h, --help              Show this help message")
+	fmt.Println("  --template <path|url>   Start from a zerb.lua template instead of an")
+	fmt.Println("                          empty config (local file or https:// URL)")
+	fmt.Println("  --all-shells            Add shell integration to the rc file of every")
+	fmt.Println("                          bash, zsh and fish shell on this machine")
+	fmt.Println("  -n, --dry-run           Show the changes shell integration would make")
+	fmt.Println("                          to your rc files, without changing anything")
+	fmt.Println("  --adopt-existing-repo   Use a git repository already in the ZERB")
+	fmt.Println("                          directory even if it has unrelated history")
+	fmt.Println("  --offline <dir>         Install core components from release files")
+	fmt.Println("                          already downloaded to <dir>, without network")
+	fmt.Println("                          access (they are still verified)")
+	fmt.Println("  --tool-manager-version <v>")
+	fmt.Println("                          Install this version of the tool manager")
+	fmt.Println("                          instead of the tested one (e.g. 2024.12.7)")
+	fmt.Println("  --config-manager-version <v>")
+	fmt.Println("                          Install this version of the configuration")
+	fmt.Println("                          manager instead of the tested one (e.g. v2.46.1)")
+	fmt.Println("  --reinstall             Restore missing or broken core components of")
+	fmt.Println("                          an existing setup, keeping its config")
+	fmt.Println("  --regenerate-config     Write a new initial config (or the template)")
+	fmt.Println("                          even if ZERB is set up; history is kept")
+	fmt.Println("  --non-interactive       Never prompt and use defaults (the shell is")
+	fmt.Println("                          detected from $SHELL or the parent process).")
+	fmt.Println("                          rc files are not changed: --all-shells prints")
+	fmt.Println("                          the lines to add instead. Enabled automatically")
+	fmt.Println("                          when stdin is not a terminal")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  zerb init")
+	fmt.Println("  zerb init --template ~/team/zerb.lua")
+	fmt.Println("  zerb init --all-shells")
+	fmt.Println("  zerb init --all-shells --dry-run")
+	fmt.Println("  zerb init --template https://example.com/team/zerb.lua")
+	fmt.Println("  zerb init --offline /media/usb/zerb-bundle")
+	fmt.Println("  zerb init --reinstall")
+	fmt.Println("  zerb init --tool-manager-version 2024.11.37")
+	fmt.Println("  zerb init --regenerate-config --template ~/team/zerb.lua")
+	fmt.Println("  zerb init --non-interactive --template https://example.com/team/zerb.lua")
+	fmt.Println()
+}
+
+// reinstallCoreComponents installs the core components missing from an
+// initialized ZERB directory (Install skips those present and executable),
+// leaving configs and history alone.
+func reinstallCoreComponents(ctx context.Context, zerbDir, offlineDir string, versions binary.Version) error {
+	if !isAlreadyInitialized(zerbDir) {
+		return fmt.Errorf("ZERB not initialized at %s\nRun 'zerb init' to set up ZERB first", zerbDir)
+	}
+
+	fmt.Println("Restoring core components...")
+	progress := &initProgress{w: progressWriter()}
+	report, err := newInitService(zerbDir).Reinstall(ctx, service.InitOptions{
+		OfflineDir: offlineDir,
+		Versions:   versions,
+		Progress:   progress.Update,
+	})
+	progress.Done()
+	if err != nil {
+		return err
+	}
+	printInstalledBinaries(os.Stdout, report.Binaries)
+	printInitWarnings(os.Stderr, report.Warnings)
+	fmt.Println("✓ Core components are installed")
+	return nil
+}
+
+// newInitService creates the init service for zerbDir (replaced in tests)
+var newInitService = func(zerbDir string) *service.InitService {
+	return service.NewInitService(git.NewClient(zerbDir), platform.NewDetector(), service.RealClock{}, zerbDir).
+		WithInstaller(func(zerbDir string, platformInfo *platform.Info) (service.BinaryInstaller, error) {
+			manager, err := newBinaryManager(zerbDir, platformInfo)
+			if err != nil {
+				return nil, err
+			}
+			return manager, nil
+		})
+}
+
+// progressWriter returns where download progress is drawn: stdout on a
+// terminal, nowhere when output is redirected
+func progressWriter() io.Writer {
+	if isTerminal(os.Stdout) {
+		return os.Stdout
+	}
+	return nil
+}
+
+// printInitReport prints what init did. Problems init worked around go to
+// errW.
+func printInitReport(w, errW io.Writer, report *service.InitReport) {
+	if len(report.Directories) > 0 {
+		fmt.Fprintf(w, "✓ Created directory structure in %s\n", report.ZerbDir)
+	} else {
+		fmt.Fprintf(w, "✓ Directory structure already exists in %s\n", report.ZerbDir)
+	}
+
+	switch report.Git {
+	case service.InitGitCreated:
+		fmt.Fprintln(w, "✓ Initialized git repository")
+		if user := report.GitUser; user != nil && user.IsDefault {
+			fmt.Fprintf(errW, "⚠ Note: Using placeholder git identity (%s <%s>)\n", user.Name, user.Email)
+			fmt.Fprintf(errW, "  ZERB maintains complete isolation and does not read global git config.\n")
+			fmt.Fprintf(errW, "  To set your git identity for ZERB, use environment variables:\n")
+			fmt.Fprintf(errW, "    export ZERB_GIT_NAME=\"Your Name\"\n")
+			fmt.Fprintf(errW, "    export ZERB_GIT_EMAIL=\"you@example.com\"\n")
+		} else if user != nil {
+			fmt.Fprintf(w, "✓ Configured git user: %s <%s>\n", user.Name, user.Email)
+		}
+	case service.InitGitExisting:
+		fmt.Fprintln(w, "✓ Git repository already exists")
+	case service.InitGitInvalid:
+		fmt.Fprintf(errW, "⚠ Warning: Invalid git repository detected\n")
+		fmt.Fprintf(errW, "  Skipping git initialization.\n")
+		fmt.Fprintf(errW, "  Fix or remove .git directory to enable versioning.\n")
+	case service.InitGitFailed:
+		fmt.Fprintf(errW, "⚠ Warning: Unable to initialize git repository\n")
+		fmt.Fprintf(errW, "  Git versioning not available.\n")
+		fmt.Fprintf(errW, "  \n")
+		fmt.Fprintf(errW, "  To set up git versioning later:\n")
+		fmt.Fprintf(errW, "    1. Ensure write permissions in %s\n", report.ZerbDir)
+		fmt.Fprintf(errW, "    2. Run: zerb git init\n")
+		fmt.Fprintf(errW, "  \n")
+		fmt.Fprintf(errW, "  ZERB will continue without version control.\n")
+	}
+
+	if info := report.Platform; info != nil {
+		if distro := info.GetDistro(); distro != nil {
+			fmt.Fprintf(w, "✓ Detected %s (%s family, %s)\n", distro.ID, distro.Family, info.Arch)
+		} else {
+			fmt.Fprintf(w, "✓ Detected %s, %s\n", info.OS, info.Arch)
+		}
+	}
+
+	printInstalledBinaries(w, report.Binaries)
+	fmt.Fprintf(w, "✓ Extracted verification keys to %s/keyrings/\n", report.ZerbDir)
+
+	switch {
+	case report.ConfigCreated && report.FromTemplate:
+		fmt.Fprintf(w, "✓ Created initial config %s from template\n", report.ConfigFile)
+	case report.ConfigCreated:
+		fmt.Fprintf(w, "✓ Created initial config %s\n", report.ConfigFile)
+	default:
+		fmt.Fprintf(w, "✓ Using existing config %s\n", report.ConfigFile)
+	}
+	if len(report.SensitiveData) > 0 {
+		fmt.Fprint(errW, config.FormatSensitiveDataWarning(report.SensitiveData))
+	}
+	if report.InitialCommit {
+		fmt.Fprintln(w, "✓ Created initial commit")
+	}
+
+	if report.Shell.IsValid() {
+		fmt.Fprintf(w, "✓ Detected %s shell (%s)\n", report.Shell, describeShellDetection(report.ShellDetection))
+	} else if report.ShellDetection != nil {
+		fmt.Fprintf(w, "⚠ Could not detect your shell (%s)\n", describeShellDetection(report.ShellDetection))
+	} else {
+		fmt.Fprintln(w, "⚠ Could not detect your shell (detection failed)")
+	}
+
+	printInitWarnings(errW, report.Warnings)
+}
+
+// printInstalledBinaries prints one line per core component with the
+// version it reports and how its download was verified
+func printInstalledBinaries(w io.Writer, binaries []service.InstalledBinary) {
+	for _, b := range binaries {
+		name := b.Binary.Label()
+		if b.Version != "" {
+			name += " " + b.Version
+		}
+		switch {
+		case b.AlreadyInstalled:
+			fmt.Fprintf(w, "✓ %s already installed\n", name)
+		case b.Verified == binary.VerificationNone:
+			fmt.Fprintf(w, "✓ Installed %s (not verified)\n", name)
+		default:
+			fmt.Fprintf(w, "✓ Installed %s (verified with %s)\n", name, b.Verified)
+		}
+	}
+}
+
+// printInitWarnings prints the problems init worked around
+func printInitWarnings(w io.Writer, warnings []string) {
+	for _, warning := range warnings {
+		fmt.Fprintf(w, "⚠ Warning: %s\n", warning)
+	}
+}
+
 // runInit handles the `zerb init` subcommand
 func runInit(args []string) error {
+	// Parse flags
+	showHelp := false
+	templateSource := ""
+	allShells := false
+	dryRun := false
+	adoptRepo := false
+	offlineDir := ""
+	noPrompt := false
+	reinstall := false
+	regenerateConfig := false
+	var versions binary.Version
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--help" || arg == "-h":
+			showHelp = true
+		case arg == "--template":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--template requires a path or URL\nRun 'zerb init --help' for usage")
+			}
+			i++
+			templateSource = args[i]
+		case strings.HasPrefix(arg, "--template="):
+			templateSource = strings.TrimPrefix(arg, "--template=")
+		case arg == "--all-shells":
+			allShells = true
+		case arg == "--dry-run" || arg == "-n":
+			dryRun = true
+		case arg == "--adopt-existing-repo":
+			adoptRepo = true
+		case arg == "--non-interactive":
+			noPrompt = true
+		case arg == "--reinstall":
+			reinstall = true
+		case arg == "--regenerate-config":
+			regenerateConfig = true
+		case arg == "--offline":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--offline requires a directory\nRun 'zerb init --help' for usage")
+			}
+			i++
+			offlineDir = args[i]
+		case strings.HasPrefix(arg, "--offline="):
+			offlineDir = strings.TrimPrefix(arg, "--offline=")
+		case arg == "--tool-manager-version" || arg == "--config-manager-version":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a version\nRun 'zerb init --help' for usage", arg)
+			}
+			i++
+			if err := setComponentVersion(&versions, arg, args[i]); err != nil {
+				return err
+			}
+		case strings.HasPrefix(arg, "--tool-manager-version=") || strings.HasPrefix(arg, "--config-manager-version="):
+			flag, value, _ := strings.Cut(arg, "=")
+			if err := setComponentVersion(&versions, flag, value); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown option: %s\nRun 'zerb init --help' for usage", arg)
+		}
+	}
+
+	if showHelp {
+		printInitHelp()
+		return nil
+	}
+
+	// Without a terminal on stdin nobody can answer a prompt, e.g. in a
+	// provisioning pipeline
+	if !isTerminal(os.Stdin) {
+		noPrompt = true
+	}
+	if noPrompt {
+		nonInteractive = true
+	}
+
 	// Create context with timeout (5 minutes for downloads)
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
@@ -418,138 +801,83 @@ func runInit(args []string) error {
 		return fmt.Errorf("get ZERB directory: %w", err)
 	}
 
-	fmt.Println("🚀 Initializing ZERB...")
-	fmt.Println()
+	if offlineDir != "" {
+		if info, err := os.Stat(offlineDir); err != nil || !info.IsDir() {
+			return fmt.Errorf("offline directory %s does not exist or is not a directory", offlineDir)
+		}
+	}
 
-	// Check if already initialized
-	if isAlreadyInitialized(zerbDir) {
-		return fmt.Errorf("ZERB already initialized at %s\nThe environment is already set up", zerbDir)
+	if reinstall {
+		if dryRun || templateSource != "" || regenerateConfig {
+			return fmt.Errorf("--reinstall cannot be combined with --dry-run, --template or --regenerate-config")
+		}
+		return reinstallCoreComponents(ctx, zerbDir, offlineDir, versions)
 	}
 
-	// Step 1: Create directory structure
-	fmt.Printf("Creating directory structure...\n")
-	if err := createDirectoryStructure(zerbDir); err != nil {
-		return fmt.Errorf("create directories: %w", err)
+	if dryRun {
+		fmt.Println("Dry run: previewing shell integration, nothing will be changed")
+		return previewShellIntegration(ctx, os.Stdout, zerbDir, allShells)
 	}
-	fmt.Printf("✓ Created %s\n", zerbDir)
-	fmt.Printf("✓ Created configs/ subdirectory\n")
 
-	// Step 2: Write .gitignore file
-	fmt.Printf("\nSetting up git repository...\n")
-	gitignorePath := filepath.Join(zerbDir, ".gitignore")
-	if err := git.WriteGitignore(gitignorePath); err != nil {
-		return fmt.Errorf("write .gitignore: %w", err)
+	fmt.Println("🚀 Initializing ZERB...")
+	fmt.Println()
+	if noPrompt {
+		fmt.Println("Running non-interactively: prompts are disabled, defaults are used and rc files are not changed.")
+		fmt.Println()
 	}
 
-	// Step 3: Initialize git repository
-	gitClient := git.NewClient(zerbDir)
+	// Check if already initialized (also checked by the service, but not
+	// before a template is fetched). An interrupted init is resumed.
+	svc := newInitService(zerbDir)
+	if !regenerateConfig && svc.IsComplete(ctx) {
+		return fmt.Errorf("ZERB already initialized at %s\nThe environment is already set up\n"+
+			"Run 'zerb init --regenerate-config' to replace the config with a new initial one", zerbDir)
+	}
 
-	// Check if git repo already exists
-	isRepo, err := gitClient.IsGitRepo(ctx)
-	if err != nil {
-		// Invalid/corrupted repository - warn and skip git initialization
-		fmt.Fprintf(os.Stderr, "⚠ Warning: Invalid git repository detected\n")
-		fmt.Fprintf(os.Stderr, "  Skipping git initialization.\n")
-		fmt.Fprintf(os.Stderr, "  Fix or remove .git directory to enable versioning.\n")
-		// Create .zerb-no-git marker
-		markerPath := filepath.Join(zerbDir, ".zerb-no-git")
-		if writeErr := os.WriteFile(markerPath, []byte("git initialization failed: invalid repository\n"), 0600); writeErr != nil {
-			fmt.Fprintf(os.Stderr, "⚠ Warning: Failed to create marker file: %v\n", writeErr)
-		}
-	} else if isRepo {
-		fmt.Printf("✓ Git repository already exists\n")
-	} else {
-		// Initialize new git repository
-		if err := gitClient.InitRepo(ctx); err != nil {
-			// Git initialization failed - warn but continue
-			fmt.Fprintf(os.Stderr, "\n⚠ Warning: Unable to initialize git repository\n")
-			fmt.Fprintf(os.Stderr, "  Git versioning not available.\n")
-			fmt.Fprintf(os.Stderr, "  \n")
-			fmt.Fprintf(os.Stderr, "  To set up git versioning later:\n")
-			fmt.Fprintf(os.Stderr, "    1. Ensure write permissions in %s\n", zerbDir)
-			fmt.Fprintf(os.Stderr, "    2. Run: zerb git init\n")
-			fmt.Fprintf(os.Stderr, "  \n")
-			fmt.Fprintf(os.Stderr, "  ZERB will continue without version control.\n\n")
-
-			// Create .zerb-no-git marker
-			markerPath := filepath.Join(zerbDir, ".zerb-no-git")
-			if writeErr := os.WriteFile(markerPath, []byte("git initialization failed\n"), 0600); writeErr != nil {
-				fmt.Fprintf(os.Stderr, "⚠ Warning: Failed to create marker file: %v\n", writeErr)
-			}
-		} else {
-			fmt.Printf("✓ Initialized git repository\n")
-
-			// Step 4: Configure git user
-			userInfo := git.DetectGitUser()
-			if err := gitClient.ConfigureUser(ctx, userInfo); err != nil {
-				fmt.Fprintf(os.Stderr, "⚠ Warning: Failed to configure git user: %v\n", err)
-			} else {
-				if userInfo.IsDefault {
-					fmt.Fprintf(os.Stderr, "⚠ Note: Using placeholder git identity (%s <%s>)\n", userInfo.Name, userInfo.Email)
-					fmt.Fprintf(os.Stderr, "  ZERB maintains complete isolation and does not read global git config.\n")
-					fmt.Fprintf(os.Stderr, "  To set your git identity for ZERB, use environment variables:\n")
-					fmt.Fprintf(os.Stderr, "    export ZERB_GIT_NAME=\"Your Name\"\n")
-					fmt.Fprintf(os.Stderr, "    export ZERB_GIT_EMAIL=\"you@example.com\"\n")
-				} else {
-					fmt.Printf("✓ Configured git user: %s <%s>\n", userInfo.Name, userInfo.Email)
-				}
-			}
+	// Load and validate the template before changing anything on disk
+	var template string
+	if templateSource != "" {
+		fmt.Printf("Loading config template...\n")
+		template, err = loadInitTemplate(ctx, templateSource, &http.Client{})
+		if err != nil {
+			return fmt.Errorf("load template: %w", err)
 		}
+		fmt.Printf("✓ Loaded template %s\n\n", templateSource)
 	}
 
-	// Step 5: Detect platform
-	fmt.Printf("\nDetecting platform...\n")
-	platformInfo, err := detectPlatform(ctx)
+	if offlineDir != "" {
+		fmt.Printf("Installing core components from %s...\n", offlineDir)
+	} else {
+		fmt.Printf("Downloading core components...\n")
+	}
+	progress := &initProgress{w: progressWriter()}
+	report, err := svc.Run(ctx, service.InitOptions{
+		Template:          template,
+		AdoptExistingRepo: adoptRepo,
+		OfflineDir:        offlineDir,
+		Versions:          versions,
+		RegenerateConfig:  regenerateConfig,
+		Progress:          progress.Update,
+	})
+	progress.Done()
 	if err != nil {
-		return fmt.Errorf("detect platform: %w", err)
+		return err
 	}
-	distro := platformInfo.GetDistro()
-	if distro != nil {
-		fmt.Printf("✓ Detected %s (%s family, %s)\n", distro.ID, distro.Family, platformInfo.Arch)
-	} else {
-		fmt.Printf("✓ Detected %s, %s\n", platformInfo.OS, platformInfo.Arch)
-	}
-
-	// Step 3: Install binaries
-	fmt.Printf("\nInstalling core components...\n")
-	fmt.Printf("  Downloading tool manager and configuration manager...\n")
-	if err := installBinaries(ctx, zerbDir, platformInfo); err != nil {
-		return fmt.Errorf("install binaries: %w", err)
-	}
-	fmt.Printf("✓ Installed core components\n")
-	fmt.Printf("✓ Extracted verification keys to %s/keyrings/\n", zerbDir)
-
-	// Step 4: Generate initial config
-	fmt.Printf("\nGenerating initial configuration...\n")
-	if err := generateInitialConfig(ctx, zerbDir); err != nil {
-		return fmt.Errorf("generate config: %w", err)
-	}
-	fmt.Printf("✓ Created initial config\n")
-
-	// Step 5: Create initial commit (if git is initialized)
-	isRepo, _ = gitClient.IsGitRepo(ctx)
-	if isRepo {
-		// Find the timestamped config file that was just created
-		markerPath := filepath.Join(zerbDir, ".zerb-active")
-		markerContent, err := os.ReadFile(markerPath)
-		if err == nil {
-			configFilename := strings.TrimSpace(string(markerContent))
-			configPath := filepath.Join("configs", configFilename)
-
-			// Create initial commit with .gitignore and config
-			files := []string{".gitignore", configPath}
-			if err := gitClient.CreateInitialCommit(ctx, "Initialize ZERB environment", files); err != nil {
-				fmt.Fprintf(os.Stderr, "⚠ Warning: Failed to create initial commit: %v\n", err)
-			} else {
-				fmt.Printf("✓ Created initial commit\n")
-			}
+	fmt.Println()
+	printInitReport(os.Stdout, os.Stderr, report)
+	detectedShell := report.Shell
+
+	if allShells {
+		fmt.Printf("\nAdding shell integration...\n")
+		if err := setupAllShells(ctx, zerbDir, os.Stdout, noPrompt); err != nil {
+			return fmt.Errorf("set up shell integration: %w", err)
 		}
 	}
 
-	// Step 6: Detect shell (for showing appropriate instructions)
-	detectedShell := detectUserShell()
+	check, err := verifyShellActivation(ctx, zerbDir, detectedShell)
+	printActivationCheck(os.Stdout, check, err)
 
-	// Step 7: Check if zerb is on PATH and show appropriate success message
+	// Check if zerb is on PATH and show appropriate success message
 	zerbPath := checkZerbOnPath()
 	if zerbPath == "" {
 		// zerb is not on PATH - print warning with install instructions
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/binary"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/platform"
+)
+
+// templateFetchTimeout bounds how long downloading a template URL may take
+const templateFetchTimeout = 30 * time.Second
+
+// loadInitTemplate reads a config template from a local path or an https://
+// URL and checks that it parses as a valid config. Returns the template
+// source unchanged, so comments and platform conditionals are preserved.
+func loadInitTemplate(ctx context.Context, source string, client *http.Client) (string, error) {
+	if strings.TrimSpace(source) == "" {
+		return "", fmt.Errorf("template path cannot be empty")
+	}
+
+	var content string
+	var err error
+	if strings.Contains(source, "://") {
+		content, err = fetchTemplate(ctx, source, client)
+	} else {
+		content, err = readTemplateFile(source)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	parser := config.NewParser(platform.NewDetector())
+	if _, err := parser.ParseString(ctx, content); err != nil {
+		return "", fmt.Errorf("invalid template %s: %s", source, config.FormatError(err, false))
+	}
+
+	return content, nil
+}
+
+// readTemplateFile reads a template from a local file. Paths may start
+// with ~/ or be relative to the working directory.
+func readTemplateFile(path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot determine home directory: %w", err)
+		}
+		path = filepath.Join(home, strings.TrimPrefix(path[1:], "/"))
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("template not found: %s", path)
+		}
+		return "", fmt.Errorf("check template: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("template is not a regular file: %s", path)
+	}
+	if info.Size() > config.MaxConfigSize {
+		return "", fmt.Errorf("template too large: %d bytes exceeds maximum %d bytes", info.Size(), config.MaxConfigSize)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read template: %w", err)
+	}
+	return string(data), nil
+}
+
+// fetchTemplate downloads a template over https
+func fetchTemplate(ctx context.Context, rawURL string, client *http.Client) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid template URL: %w", err)
+	}
+	if u.Scheme != "https" {
+		return "", fmt.Errorf("template URL must use https:// (got: %s)", u.Scheme)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("invalid template URL: missing host")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, templateFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", binary.DefaultUserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("download template: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download template: HTTP %d", resp.StatusCode)
+	}
+
+	// Read one byte past the limit to detect oversized templates
+	data, err := io.ReadAll(io.LimitReader(resp.Body, config.MaxConfigSize+1))
+	if err != nil {
+		return "", fmt.Errorf("download template: %w", err)
+	}
+	if len(data) > config.MaxConfigSize {
+		return "", fmt.Errorf("template too large: exceeds maximum %d bytes", config.MaxConfigSize)
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const teamTemplate = `-- Team template
+zerb = {
+  meta = { name = "Team Environment" },
+  tools = {
+    "node@20.11.0",
+    platform.is_linux and "ripgrep@14.0.0" or nil,
+  },
+  git = { branch = "main" },
+}
+`
+
+func TestInitTemplate_SeedsFromLocalFile(t *testing.T) {
+	templatePath := filepath.Join(t.TempDir(), "zerb.lua")
+	if err := os.WriteFile(templatePath, []byte(teamTemplate), 0644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	ctx := context.Background()
+	template, err := loadInitTemplate(ctx, templatePath, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("loadInitTemplate() error = %v", err)
+	}
+
+	// The template is kept verbatim, conditionals and comments included
+	if template != teamTemplate {
+		t.Errorf("template =\n%s\nwant:\n%s", template, teamTemplate)
+	}
+}
+
+func TestInitTemplate_TildePath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.WriteFile(filepath.Join(home, "team.lua"), []byte(teamTemplate), 0644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	if _, err := loadInitTemplate(context.Background(), "~/team.lua", http.DefaultClient); err != nil {
+		t.Fatalf("loadInitTemplate() error = %v", err)
+	}
+}
+
+func TestInitTemplate_RejectsInvalid(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		source  string
+		wantErr string
+	}{
+		{
+			name:    "syntax error",
+			source:  write("syntax.lua", "zerb = {"),
+			wantErr: "invalid template",
+		},
+		{
+			name:    "invalid tool",
+			source:  write("tool.lua", `zerb = { tools = { "bad tool!" } }`),
+			wantErr: "invalid template",
+		},
+		{
+			name:    "missing zerb table",
+			source:  write("empty.lua", "-- nothing here"),
+			wantErr: "invalid template",
+		},
+		{
+			name:    "missing file",
+			source:  filepath.Join(dir, "nope.lua"),
+			wantErr: "template not found",
+		},
+		{
+			name:    "directory",
+			source:  dir,
+			wantErr: "not a regular file",
+		},
+		{
+			name:    "empty source",
+			source:  "",
+			wantErr: "cannot be empty",
+		},
+		{
+			name:    "http URL",
+			source:  "http://example.com/zerb.lua",
+			wantErr: "must use https://",
+		},
+		{
+			name:    "file URL",
+			source:  "file:///etc/passwd",
+			wantErr: "must use https://",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadInitTemplate(context.Background(), tt.source, http.DefaultClient)
+			if err == nil {
+				t.Fatal("loadInitTemplate() expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInitTemplate_URL(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/zerb.lua":
+			fmt.Fprint(w, teamTemplate)
+		case "/invalid.lua":
+			fmt.Fprint(w, `zerb = { tools = { "bad tool!" } }`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+
+	template, err := loadInitTemplate(ctx, server.URL+"/zerb.lua", server.Client())
+	if err != nil {
+		t.Fatalf("loadInitTemplate() error = %v", err)
+	}
+	if template != teamTemplate {
+		t.Errorf("template = %q, want %q", template, teamTemplate)
+	}
+
+	if _, err := loadInitTemplate(ctx, server.URL+"/invalid.lua", server.Client()); err == nil || !strings.Contains(err.Error(), "invalid template") {
+		t.Errorf("invalid template error = %v, want invalid template", err)
+	}
+
+	if _, err := loadInitTemplate(ctx, server.URL+"/missing.lua", server.Client()); err == nil || !strings.Contains(err.Error(), "HTTP 404") {
+		t.Errorf("missing template error = %v, want HTTP 404", err)
+	}
+}
+
+func TestRunInit_TemplateFlagErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"missing value", []string{"--template"}, "--template requires a path or URL"},
+		{"unknown option", []string{"--bogus"}, "unknown option: --bogus"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runInit(tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("runInit(%v) error = %v, want %q", tt.args, err, tt.wantErr)
+			}
+		})
+	}
+}
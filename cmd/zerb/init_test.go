@@ -1,161 +1,26 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
-	"regexp"
+	"reflect"
 	"strings"
 	"testing"
-	"time"
 
-	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/binary"
 	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/layout"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/platform"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/prompt"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/service"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/shell"
 )
 
-// TestCreateDirectoryStructure tests that all required directories are created
-func TestCreateDirectoryStructure(t *testing.T) {
-	// Create temp directory for test
-	tmpDir := t.TempDir()
-
-	// Call function under test
-	err := createDirectoryStructure(tmpDir)
-	if err != nil {
-		t.Fatalf("createDirectoryStructure failed: %v", err)
-	}
-
-	// Verify all expected directories exist
-	expectedDirs := []string{
-		"bin",
-		"keyrings",
-		filepath.Join("cache", "downloads"),
-		filepath.Join("cache", "versions"),
-		"configs",
-		"tmp",
-		"logs",
-		"mise",
-		filepath.Join("chezmoi", "source"),
-	}
-
-	for _, dir := range expectedDirs {
-		fullPath := filepath.Join(tmpDir, dir)
-		info, err := os.Stat(fullPath)
-		if err != nil {
-			t.Errorf("directory %s does not exist: %v", dir, err)
-			continue
-		}
-		if !info.IsDir() {
-			t.Errorf("%s exists but is not a directory", dir)
-		}
-		// Check permissions (should be 0700 for security)
-		if info.Mode().Perm() != 0700 {
-			t.Errorf("directory %s has wrong permissions: got %o, want 0700", dir, info.Mode().Perm())
-		}
-	}
-}
-
-// TestCreateDirectoryStructure_RootPermissions verifies ZERB root directory has 0700 permissions
-func TestCreateDirectoryStructure_RootPermissions(t *testing.T) {
-	// Create a parent directory to contain our test ZERB root
-	parent := t.TempDir()
-	zerbRoot := filepath.Join(parent, "zerb-test")
-
-	// Create the directory structure (which includes creating the root)
-	err := createDirectoryStructure(zerbRoot)
-	if err != nil {
-		t.Fatalf("createDirectoryStructure failed: %v", err)
-	}
-
-	// Verify the root directory itself has 0700 permissions
-	info, err := os.Stat(zerbRoot)
-	if err != nil {
-		t.Fatalf("failed to stat ZERB root directory: %v", err)
-	}
-
-	if !info.IsDir() {
-		t.Fatal("ZERB root exists but is not a directory")
-	}
-
-	// Check permissions (must be 0700 for security on multi-user systems)
-	if info.Mode().Perm() != 0700 {
-		t.Errorf("ZERB root directory has wrong permissions: got %o, want 0700", info.Mode().Perm())
-	}
-}
-
-// TestCreateDirectoryStructure_IdempotentOperations tests that calling createDirectoryStructure twice doesn't fail
-func TestCreateDirectoryStructure_Idempotent(t *testing.T) {
-	tmpDir := t.TempDir()
-
-	// Call first time
-	err := createDirectoryStructure(tmpDir)
-	if err != nil {
-		t.Fatalf("first call failed: %v", err)
-	}
-
-	// Call second time (should be idempotent)
-	err = createDirectoryStructure(tmpDir)
-	if err != nil {
-		t.Fatalf("second call failed: %v", err)
-	}
-
-	// Verify directories still exist
-	binDir := filepath.Join(tmpDir, "bin")
-	if _, err := os.Stat(binDir); err != nil {
-		t.Errorf("directory not present after second call: %v", err)
-	}
-}
-
-// TestCreateDirectoryStructure_PermissionDenied tests behavior when directory creation fails
-func TestCreateDirectoryStructure_PermissionDenied(t *testing.T) {
-	// Skip on systems where we can't reliably test permissions
-	if os.Getuid() == 0 {
-		t.Skip("skipping permission test when running as root")
-	}
-
-	// Create a read-only directory
-	tmpDir := t.TempDir()
-	readOnlyDir := filepath.Join(tmpDir, "readonly")
-	err := os.Mkdir(readOnlyDir, 0555) // r-xr-xr-x (no write)
-	if err != nil {
-		t.Fatalf("failed to create read-only dir: %v", err)
-	}
-
-	// Try to create structure inside read-only directory
-	err = createDirectoryStructure(readOnlyDir)
-	if err == nil {
-		t.Error("expected error when creating directories in read-only location, got nil")
-	}
-}
-
-// TestCreateDirectoryStructure_InvalidPath tests behavior with invalid paths
-func TestCreateDirectoryStructure_InvalidPath(t *testing.T) {
-	tests := []struct {
-		name    string
-		path    string
-		wantErr bool
-	}{
-		{
-			name:    "empty path",
-			path:    "",
-			wantErr: true,
-		},
-		{
-			name:    "valid path",
-			path:    t.TempDir(),
-			wantErr: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			err := createDirectoryStructure(tt.path)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("createDirectoryStructure() error = %v, wantErr %v", err, tt.wantErr)
-			}
-		})
-	}
-}
-
 // TestIsAlreadyInitialized tests detection of existing ZERB installations
 func TestIsAlreadyInitialized(t *testing.T) {
 	tests := []struct {
@@ -231,8 +96,8 @@ func TestIsAlreadyInitialized_FullyInitialized(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	// Create full structure
-	if err := createDirectoryStructure(tmpDir); err != nil {
-		t.Fatalf("createDirectoryStructure failed: %v", err)
+	if err := layout.Create(tmpDir); err != nil {
+		t.Fatalf("layout.Create failed: %v", err)
 	}
 
 	// Create mise binary
@@ -253,203 +118,96 @@ func TestIsAlreadyInitialized_FullyInitialized(t *testing.T) {
 	}
 }
 
-// TestGenerateInitialConfig tests initial config generation
-func TestGenerateInitialConfig(t *testing.T) {
-	tmpDir := t.TempDir()
-
-	// Create directory structure first
-	if err := createDirectoryStructure(tmpDir); err != nil {
-		t.Fatalf("createDirectoryStructure failed: %v", err)
-	}
-
-	// Generate initial config
-	ctx := context.Background()
-	err := generateInitialConfig(ctx, tmpDir)
-	if err != nil {
-		t.Fatalf("generateInitialConfig failed: %v", err)
-	}
-
-	// Verify .zerb-active marker exists and contains timestamp
-	markerPath := filepath.Join(tmpDir, ".zerb-active")
-	markerContent, err := os.ReadFile(markerPath)
-	if err != nil {
-		t.Fatalf("failed to read marker file: %v", err)
+// TestRunInit_AlreadyInitialized tests that init refuses a ZERB directory
+// whose init completed, pointing at --regenerate-config
+func TestRunInit_AlreadyInitialized(t *testing.T) {
+	zerbDir := t.TempDir()
+	t.Setenv("ZERB_DIR", zerbDir)
+	if err := layout.Create(zerbDir); err != nil {
+		t.Fatalf("layout.Create() error = %v", err)
 	}
 
-	configFilename := strings.TrimSpace(string(markerContent))
-	if configFilename == "" {
-		t.Error("marker file is empty")
+	// A valid, committed active config
+	snapshot := "zerb.20250101T120000.000Z.lua"
+	if err := os.WriteFile(filepath.Join(zerbDir, "configs", snapshot), []byte("zerb = {}\n"), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
 	}
-
-	// Verify filename format (zerb.YYYYMMDDTHHMMSS.SSSZ.lua with milliseconds)
-	filenameRegex := regexp.MustCompile(`^zerb\.\d{8}T\d{6}\.\d{3}Z\.lua$`)
-	if !filenameRegex.MatchString(configFilename) {
-		t.Errorf("marker filename has invalid format: %s (expected zerb.YYYYMMDDTHHMMSS.SSSZ.lua)", configFilename)
+	if err := os.WriteFile(filepath.Join(zerbDir, ".zerb-active"), []byte(snapshot), 0600); err != nil {
+		t.Fatalf("failed to write marker: %v", err)
 	}
-
-	// Verify timestamped config file exists
-	configPath := filepath.Join(tmpDir, "configs", configFilename)
-	configContent, err := os.ReadFile(configPath)
-	if err != nil {
-		t.Fatalf("failed to read config file: %v", err)
+	if err := os.Symlink(filepath.Join("configs", snapshot), filepath.Join(zerbDir, "zerb.active.lua")); err != nil {
+		t.Fatalf("failed to link active config: %v", err)
 	}
-
-	// Verify config contains expected minimal content
-	configStr := string(configContent)
-	requiredStrings := []string{
-		"zerb = {",
-		"-- ZERB Configuration",
+	gitClient := git.NewClient(zerbDir)
+	ctx := context.Background()
+	if err := gitClient.InitRepo(ctx); err != nil {
+		t.Fatalf("InitRepo() error = %v", err)
 	}
-
-	for _, required := range requiredStrings {
-		if !strings.Contains(configStr, required) {
-			t.Errorf("config missing required content: %s", required)
-		}
+	if err := gitClient.ConfigureUser(ctx, git.GitUserInfo{Name: "Test User", Email: "test@example.com"}); err != nil {
+		t.Fatalf("ConfigureUser() error = %v", err)
 	}
-
-	// Verify meta section exists (config with name should have meta)
-	if !strings.Contains(configStr, "meta = {") {
-		t.Error("config should contain meta section")
+	if err := gitClient.CreateInitialCommit(ctx, "Initialize ZERB environment", []string{filepath.Join("configs", snapshot)}); err != nil {
+		t.Fatalf("CreateInitialCommit() error = %v", err)
 	}
 
-	// Verify it doesn't contain tool definitions (empty list)
-	if strings.Contains(configStr, `"node@`) || strings.Contains(configStr, `"python@`) {
-		t.Error("initial config should not contain any pre-defined tools")
+	err := runInit(nil)
+	if err == nil || !strings.Contains(err.Error(), "already initialized") || !strings.Contains(err.Error(), "--regenerate-config") {
+		t.Fatalf("runInit() error = %v, want already initialized with a --regenerate-config hint", err)
 	}
-
-	// Verify symlink exists and points to correct file
-	symlinkPath := filepath.Join(tmpDir, "zerb.active.lua")
-	linkTarget, err := os.Readlink(symlinkPath)
-	if err != nil {
-		t.Fatalf("failed to read symlink: %v", err)
-	}
-
-	expectedTarget := filepath.Join("configs", configFilename)
-	if linkTarget != expectedTarget {
-		t.Errorf("symlink target = %s, want %s", linkTarget, expectedTarget)
-	}
-
-	// Verify symlink resolves correctly
-	_, err = os.Stat(symlinkPath)
-	if err != nil {
-		t.Errorf("symlink does not resolve: %v", err)
+	if err := runInit([]string{"--reinstall", "--regenerate-config"}); err == nil || !strings.Contains(err.Error(), "cannot be combined") {
+		t.Errorf("runInit(--reinstall --regenerate-config) error = %v, want cannot be combined", err)
 	}
 }
 
-// TestGenerateInitialConfig_ParseableByParser tests that generated config can be parsed
-func TestGenerateInitialConfig_ParseableByParser(t *testing.T) {
-	tmpDir := t.TempDir()
-
-	// Create directory structure
-	if err := createDirectoryStructure(tmpDir); err != nil {
-		t.Fatalf("createDirectoryStructure failed: %v", err)
-	}
-
-	// Generate initial config
-	ctx := context.Background()
-	if err := generateInitialConfig(ctx, tmpDir); err != nil {
-		t.Fatalf("generateInitialConfig failed: %v", err)
-	}
-
-	// Read generated config
-	symlinkPath := filepath.Join(tmpDir, "zerb.active.lua")
-	configContent, err := os.ReadFile(symlinkPath)
-	if err != nil {
-		t.Fatalf("failed to read generated config: %v", err)
-	}
-
-	// Try to parse it with config parser
-	parser := config.NewParser(nil) // No platform detection needed for this test
-	parsedConfig, err := parser.ParseString(ctx, string(configContent))
-	if err != nil {
-		t.Fatalf("generated config cannot be parsed: %v", err)
-	}
+// TestRunInit_ReinstallRequiresInit tests that --reinstall refuses a ZERB
+// directory that was never initialized
+func TestRunInit_ReinstallRequiresInit(t *testing.T) {
+	zerbDir := t.TempDir()
+	t.Setenv("ZERB_DIR", zerbDir)
 
-	// Verify parsed config has expected structure
-	if parsedConfig.Meta.Name == "" {
-		t.Error("parsed config missing meta.name")
+	err := runInit([]string{"--reinstall"})
+	if err == nil || !strings.Contains(err.Error(), "ZERB not initialized") {
+		t.Fatalf("runInit(--reinstall) error = %v, want not initialized", err)
 	}
-	if len(parsedConfig.Tools) != 0 {
-		t.Errorf("parsed config should have 0 tools, got %d", len(parsedConfig.Tools))
-	}
-	if parsedConfig.Git.Branch != "main" {
-		t.Errorf("parsed config git.branch = %s, want main", parsedConfig.Git.Branch)
+	if _, err := os.Stat(filepath.Join(zerbDir, "bin")); !os.IsNotExist(err) {
+		t.Errorf("bin directory created for an uninitialized ZERB directory")
 	}
 }
 
-// TestGenerateInitialConfig_Idempotent tests that calling twice doesn't break things
-func TestGenerateInitialConfig_Idempotent(t *testing.T) {
-	tmpDir := t.TempDir()
-
-	// Create directory structure
-	if err := createDirectoryStructure(tmpDir); err != nil {
-		t.Fatalf("createDirectoryStructure failed: %v", err)
-	}
+// TestRunInit_ForeignRepo tests that init refuses a ZERB directory holding
+// an unrelated git repository
+func TestRunInit_ForeignRepo(t *testing.T) {
+	zerbDir := t.TempDir()
+	t.Setenv("ZERB_DIR", zerbDir)
 
+	gitClient := git.NewClient(zerbDir)
 	ctx := context.Background()
-
-	// Generate first config
-	if err := generateInitialConfig(ctx, tmpDir); err != nil {
-		t.Fatalf("first generateInitialConfig failed: %v", err)
-	}
-
-	// Read first timestamp
-	markerPath := filepath.Join(tmpDir, ".zerb-active")
-	firstTimestamp, err := os.ReadFile(markerPath)
-	if err != nil {
-		t.Fatalf("failed to read marker: %v", err)
-	}
-
-	// Wait a moment to ensure different timestamp
-	time.Sleep(2 * time.Second)
-
-	// Generate second config (symlink creation is now idempotent)
-	if err := generateInitialConfig(ctx, tmpDir); err != nil {
-		t.Fatalf("second generateInitialConfig failed: %v", err)
-	}
-
-	// Read second timestamp
-	secondTimestamp, err := os.ReadFile(markerPath)
-	if err != nil {
-		t.Fatalf("failed to read marker after second call: %v", err)
+	if err := gitClient.InitRepo(ctx); err != nil {
+		t.Fatalf("InitRepo() error = %v", err)
 	}
-
-	// Timestamps should be different (new snapshot created)
-	if string(firstTimestamp) == string(secondTimestamp) {
-		t.Error("expected different timestamps after second call")
+	if err := gitClient.ConfigureUser(ctx, git.GitUserInfo{Name: "Test User", Email: "test@example.com"}); err != nil {
+		t.Fatalf("ConfigureUser() error = %v", err)
 	}
-
-	// Both config files should exist
-	configsDir := filepath.Join(tmpDir, "configs")
-	entries, err := os.ReadDir(configsDir)
-	if err != nil {
-		t.Fatalf("failed to read configs dir: %v", err)
+	if err := os.WriteFile(filepath.Join(zerbDir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
 	}
-
-	if len(entries) < 2 {
-		t.Errorf("expected at least 2 config files, got %d", len(entries))
+	if err := gitClient.CreateInitialCommit(ctx, "Start project", []string{"main.go"}); err != nil {
+		t.Fatalf("CreateInitialCommit() error = %v", err)
 	}
-}
-
-// TestRunInit_AlreadyInitialized tests that running init twice returns an error
-func TestRunInit_AlreadyInitialized(t *testing.T) {
-	// This test would require mocking or a full integration setup
-	// For now, we test the isAlreadyInitialized function directly
-	tmpDir := t.TempDir()
 
-	// Create minimal initialization marker
-	if err := createDirectoryStructure(tmpDir); err != nil {
-		t.Fatalf("setup failed: %v", err)
+	err := runInit(nil)
+	if !errors.Is(err, git.ErrForeignRepo) {
+		t.Fatalf("runInit() error = %v, want ErrForeignRepo", err)
 	}
-
-	markerPath := filepath.Join(tmpDir, ".zerb-active")
-	if err := os.WriteFile(markerPath, []byte("zerb.20250101T120000.000Z.lua"), 0644); err != nil {
-		t.Fatalf("create marker failed: %v", err)
+	if !strings.Contains(err.Error(), "--adopt-existing-repo") {
+		t.Errorf("error should mention --adopt-existing-repo: %v", err)
 	}
 
-	// Should be detected as initialized
-	if !isAlreadyInitialized(tmpDir) {
-		t.Error("directory with .zerb-active should be detected as initialized")
+	// Nothing was written into the repository
+	for _, name := range []string{".gitignore", "configs", "bin"} {
+		if _, err := os.Stat(filepath.Join(zerbDir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s was created in the foreign repository", name)
+		}
 	}
 }
 
@@ -548,13 +306,13 @@ func TestIsOnPath(t *testing.T) {
 	}
 }
 
-// TestCreateDirectoryStructure_CorruptedGitRepo tests handling of corrupted .git directory
-func TestCreateDirectoryStructure_CorruptedGitRepo(t *testing.T) {
+// TestCorruptedGitRepo tests handling of corrupted .git directory
+func TestCorruptedGitRepo(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	// Create directory structure first
-	if err := createDirectoryStructure(tmpDir); err != nil {
-		t.Fatalf("createDirectoryStructure failed: %v", err)
+	if err := layout.Create(tmpDir); err != nil {
+		t.Fatalf("layout.Create failed: %v", err)
 	}
 
 	// Create a corrupted .git directory (just a regular file instead of directory)
@@ -588,8 +346,8 @@ func TestGitWorkflow_IntegrationEndToEnd(t *testing.T) {
 	ctx := context.Background()
 
 	// Step 1: Create directory structure (simulating part of runInit)
-	if err := createDirectoryStructure(tmpDir); err != nil {
-		t.Fatalf("createDirectoryStructure failed: %v", err)
+	if err := layout.Create(tmpDir); err != nil {
+		t.Fatalf("layout.Create failed: %v", err)
 	}
 
 	// Step 2: Write .gitignore (simulating runInit)
@@ -664,3 +422,411 @@ func TestGitWorkflow_IntegrationEndToEnd(t *testing.T) {
 
 	t.Logf("Git workflow integration test completed successfully. Commit: %s", hash)
 }
+
+func TestDescribeShellDetection(t *testing.T) {
+	tests := []struct {
+		name      string
+		detection shell.DetectionResult
+		want      string
+	}{
+		{
+			name:      "SHELL",
+			detection: shell.DetectionResult{Shell: shell.ShellZsh, Method: shell.MethodShellEnv},
+			want:      "from $SHELL",
+		},
+		{
+			name:      "SHELL inside tmux",
+			detection: shell.DetectionResult{Shell: shell.ShellFish, Method: shell.MethodShellEnv, Multiplexer: "tmux"},
+			want:      "from $SHELL; running inside tmux, so the parent process was not used",
+		},
+		{
+			name:      "parent process",
+			detection: shell.DetectionResult{Shell: shell.ShellBash, Method: shell.MethodParentProcess},
+			want:      "from the parent process",
+		},
+		{
+			name:      "failed",
+			detection: shell.DetectionResult{Shell: shell.ShellUnknown, Method: shell.MethodFailed},
+			want:      "$SHELL is not set to a supported shell",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeShellDetection(&tt.detection); got != tt.want {
+				t.Errorf("describeShellDetection() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDownloadProgress tests the in-place download progress line
+func TestDownloadProgress(t *testing.T) {
+	tests := []struct {
+		name    string
+		updates [][2]int64
+		want    string
+	}{
+		{
+			name:    "known size",
+			updates: [][2]int64{{0, 200}, {1, 200}, {100, 200}, {200, 200}},
+			want:    "\r    tool manager: 0%\033[K\r    tool manager: 50%\033[K\r    tool manager: 100%\033[K\n",
+		},
+		{
+			name:    "unknown size",
+			updates: [][2]int64{{512, -1}, {2048, -1}},
+			want:    "\r    tool manager: 512 B\033[K\r    tool manager: 2.0 KB\033[K\n",
+		},
+		{
+			name:    "no download",
+			updates: nil,
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			p := newDownloadProgress(&buf, "tool manager")
+			for _, u := range tt.updates {
+				p.Update(u[0], u[1])
+			}
+			p.Done()
+			if buf.String() != tt.want {
+				t.Errorf("output = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+
+	// A nil writer renders nothing
+	p := newDownloadProgress(nil, "tool manager")
+	p.Update(1, 2)
+	p.Done()
+}
+
+// TestSetupAllShells tests the per-shell output of init --all-shells
+func TestSetupAllShells(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("PATH", t.TempDir())
+	if err := os.WriteFile(filepath.Join(home, ".bashrc"), []byte("# bashrc\n"), 0644); err != nil {
+		t.Fatalf("failed to write .bashrc: %v", err)
+	}
+
+	var out strings.Builder
+	if err := setupAllShells(context.Background(), t.TempDir(), &out, false); err != nil {
+		t.Fatalf("setupAllShells() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"✓ bash: added activation to " + filepath.Join(home, ".bashrc"),
+		"- zsh: skipped (zsh is not installed and has no rc file)",
+		"- fish: skipped (fish is not installed and has no rc file)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+// TestVerifyShellActivation tests the activation check init prints for the
+// detected shell
+func TestVerifyShellActivation(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not installed")
+	}
+	activation := "eval \"$(zerb activate bash)\"\n"
+
+	tests := []struct {
+		name   string
+		bashrc string
+		want   string
+	}{
+		{"activates", "export EDITOR=vim\n" + activation, "✓ ZERB activates in bash"},
+		{"stops before activation", "return\n" + activation, "⚠ sourcing " + filepath.Join("~", ".bashrc") + " does not activate ZERB\n  Check"},
+		{"no activation line", "export EDITOR=vim\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv("ZERB_ACTIVE", "")
+			if err := os.WriteFile(filepath.Join(home, ".bashrc"), []byte(tt.bashrc), 0644); err != nil {
+				t.Fatal(err)
+			}
+			binDir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(binDir, "zerb"), []byte("#!/bin/sh\necho 'export ZERB_ACTIVE=1'\n"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("PATH", binDir+string(os.PathListSeparator)+"/usr/bin:/bin")
+
+			check, err := verifyShellActivation(context.Background(), t.TempDir(), shell.ShellBash)
+			var out strings.Builder
+			printActivationCheck(&out, check, err)
+
+			want := strings.Replace(tt.want, "~", home, 1)
+			if want == "" && out.Len() > 0 {
+				t.Errorf("output = %q, want none", out.String())
+			}
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("output = %q, want it to contain %q", out.String(), want)
+			}
+		})
+	}
+}
+
+// TestPreviewShellIntegration tests that init --dry-run shows a diff and
+// changes nothing
+func TestPreviewShellIntegration(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SHELL", "/bin/bash")
+	bashrc := filepath.Join(home, ".bashrc")
+	if err := os.WriteFile(bashrc, []byte("alias ll='ls -l'\n"), 0644); err != nil {
+		t.Fatalf("failed to write .bashrc: %v", err)
+	}
+
+	var out strings.Builder
+	if err := previewShellIntegration(context.Background(), &out, t.TempDir(), false); err != nil {
+		t.Fatalf("previewShellIntegration() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"bash: " + bashrc,
+		"# Backup: " + bashrc + shell.BackupSuffix + ".",
+		"# Write: atomic",
+		"--- " + bashrc,
+		" alias ll='ls -l'",
+		"+eval \"$(zerb activate bash)\"",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	entries, err := os.ReadDir(home)
+	if err != nil {
+		t.Fatalf("failed to read home: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("dry run changed home directory: %v", entries)
+	}
+	if content, _ := os.ReadFile(bashrc); string(content) != "alias ll='ls -l'\n" {
+		t.Errorf(".bashrc changed to %q", content)
+	}
+}
+
+// stubInstaller installs placeholder core components instead of
+// downloading them
+type stubInstaller struct {
+	binDir   string
+	versions map[binary.Binary]string // Version requested for each component
+}
+
+// useStubInstaller makes init install core components with a stub for the
+// rest of the test and returns it
+func useStubInstaller(t *testing.T) *stubInstaller {
+	t.Helper()
+	stub := &stubInstaller{versions: map[binary.Binary]string{}}
+	oldNewInitService := newInitService
+	newInitService = func(zerbDir string) *service.InitService {
+		return oldNewInitService(zerbDir).WithInstaller(func(dir string, _ *platform.Info) (service.BinaryInstaller, error) {
+			stub.binDir = filepath.Join(dir, "bin")
+			return stub, nil
+		})
+	}
+	t.Cleanup(func() { newInitService = oldNewInitService })
+	return stub
+}
+
+func (s *stubInstaller) EnsureKeyrings() error { return nil }
+
+func (s *stubInstaller) InstallWithResult(ctx context.Context, opts binary.DownloadOptions) (*binary.DownloadResult, error) {
+	if err := os.MkdirAll(s.binDir, 0755); err != nil {
+		return nil, err
+	}
+	s.versions[opts.Binary] = opts.Version
+	if err := os.WriteFile(filepath.Join(s.binDir, opts.Binary.String()), []byte("#!/bin/sh\n"), 0755); err != nil {
+		return nil, err
+	}
+	return &binary.DownloadResult{Binary: opts.Binary, Version: opts.Version, Verified: binary.VerificationSHA256}, nil
+}
+
+func (s *stubInstaller) InstalledVersion(b binary.Binary) (string, error) {
+	return "", errors.New("not a real binary")
+}
+
+// TestRunInit_NonInteractive tests that init with stdin detached from a
+// terminal never reads an answer from stdin and only prints the shell
+// integration it would add
+func TestRunInit_NonInteractive(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SHELL", "/bin/bash")
+	t.Setenv("ZERB_DIR", filepath.Join(home, ".config", "zerb"))
+	t.Setenv("GIT_AUTHOR_NAME", "Test User")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv(prompt.EnvAssumeYes, "")
+	setAssumeYes(t, false)
+	t.Cleanup(func() { nonInteractive = false })
+
+	const bashrcContent = "alias ll='ls -l'\n"
+	bashrc := filepath.Join(home, ".bashrc")
+	if err := os.WriteFile(bashrc, []byte(bashrcContent), 0644); err != nil {
+		t.Fatalf("failed to write .bashrc: %v", err)
+	}
+
+	useStubInstaller(t)
+
+	// stdin is a pipe holding an answer any prompt would consume
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	if _, err := w.WriteString("yes\n"); err != nil {
+		t.Fatalf("failed to write to pipe: %v", err)
+	}
+	w.Close()
+	oldStdin := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() { os.Stdin = oldStdin })
+
+	var runErr error
+	out := captureStdout(t, func() {
+		runErr = runInit([]string{"--all-shells"})
+	})
+	if runErr != nil {
+		t.Fatalf("runInit() error = %v\n%s", runErr, out)
+	}
+	if !nonInteractive {
+		t.Fatal("init did not switch to non-interactive mode without a terminal")
+	}
+
+	if content, _ := os.ReadFile(bashrc); string(content) != bashrcContent {
+		t.Errorf(".bashrc changed to %q", content)
+	}
+	if backups, _ := filepath.Glob(shell.BackupGlob(bashrc)); len(backups) > 0 {
+		t.Errorf(".bashrc was backed up: %v", backups)
+	}
+	if !strings.Contains(out, "+eval \"$(zerb activate bash)\"") {
+		t.Errorf("output missing the activation line to add:\n%s", out)
+	}
+
+	rest, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("failed to read pipe: %v", err)
+	}
+	if string(rest) != "yes\n" {
+		t.Errorf("stdin was read during init; %q left, want %q", rest, "yes\n")
+	}
+}
+
+// TestRunInit_ComponentVersions tests pinning core component versions
+func TestRunInit_ComponentVersions(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    map[binary.Binary]string
+		wantErr string
+	}{
+		{
+			name: "defaults",
+			want: map[binary.Binary]string{binary.BinaryMise: binary.DefaultVersions.Mise, binary.BinaryChezmoi: binary.DefaultVersions.Chezmoi},
+		},
+		{
+			name: "both pinned",
+			args: []string{"--tool-manager-version", "2024.11.37", "--config-manager-version=v2.45.0"},
+			want: map[binary.Binary]string{binary.BinaryMise: "2024.11.37", binary.BinaryChezmoi: "v2.45.0"},
+		},
+		{
+			name: "one pinned",
+			args: []string{"--config-manager-version", "2.45.0"},
+			want: map[binary.Binary]string{binary.BinaryMise: binary.DefaultVersions.Mise, binary.BinaryChezmoi: "2.45.0"},
+		},
+		{name: "invalid", args: []string{"--tool-manager-version", "2.45.0"}, wantErr: "invalid --tool-manager-version"},
+		{name: "missing value", args: []string{"--config-manager-version"}, wantErr: "requires a version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv("SHELL", "/bin/bash")
+			t.Setenv("ZERB_DIR", filepath.Join(home, ".config", "zerb"))
+			t.Setenv("GIT_AUTHOR_NAME", "Test User")
+			t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+			t.Cleanup(func() { nonInteractive = false })
+			stub := useStubInstaller(t)
+
+			var err error
+			out := captureStdout(t, func() {
+				err = runInit(append([]string{"--non-interactive"}, tt.args...))
+			})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("runInit() error = %v, want %q", err, tt.wantErr)
+				}
+				if len(stub.versions) > 0 {
+					t.Errorf("installed %v despite the error", stub.versions)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("runInit() error = %v\n%s", err, out)
+			}
+			if !reflect.DeepEqual(stub.versions, tt.want) {
+				t.Errorf("requested versions = %v, want %v", stub.versions, tt.want)
+			}
+		})
+	}
+}
+
+// TestPrintInitReport tests the init summary for a report
+func TestPrintInitReport(t *testing.T) {
+	report := &service.InitReport{
+		ZerbDir:       "/home/user/.config/zerb",
+		Directories:   []string{"/home/user/.config/zerb"},
+		Git:           service.InitGitCreated,
+		GitUser:       &git.GitUserInfo{Name: "ZERB User", Email: "zerb@localhost", IsDefault: true},
+		InitialCommit: true,
+		Platform:      &platform.Info{OS: "darwin", Arch: "arm64"},
+		Binaries: []service.InstalledBinary{
+			{Binary: binary.BinaryMise, Version: "2024.12.7", Verified: binary.VerificationCosign},
+			{Binary: binary.BinaryChezmoi, Version: "2.52.1", AlreadyInstalled: true},
+		},
+		ConfigFile:     "zerb.20250115T103000.000Z.lua",
+		ConfigCreated:  true,
+		FromTemplate:   true,
+		Shell:          shell.ShellFish,
+		ShellDetection: &shell.DetectionResult{Shell: shell.ShellFish, Method: shell.MethodShellEnv},
+		Warnings:       []string{"installed tool manager reports version 1, expected 2"},
+	}
+
+	var out, errOut strings.Builder
+	printInitReport(&out, &errOut, report)
+
+	wantOut := strings.Join([]string{
+		"✓ Created directory structure in /home/user/.config/zerb",
+		"✓ Initialized git repository",
+		"✓ Detected darwin, arm64",
+		"✓ Installed tool manager 2024.12.7 (verified with Cosign)",
+		"✓ configuration manager 2.52.1 already installed",
+		"✓ Extracted verification keys to /home/user/.config/zerb/keyrings/",
+		"✓ Created initial config zerb.20250115T103000.000Z.lua from template",
+		"✓ Created initial commit",
+		"✓ Detected fish shell (from $SHELL)",
+	}, "\n") + "\n"
+	if out.String() != wantOut {
+		t.Errorf("output =\n%s\nwant:\n%s", out.String(), wantOut)
+	}
+	for _, want := range []string{
+		"⚠ Note: Using placeholder git identity (ZERB User <zerb@localhost>)",
+		"⚠ Warning: installed tool manager reports version 1, expected 2",
+	} {
+		if !strings.Contains(errOut.String(), want) {
+			t.Errorf("stderr missing %q:\n%s", want, errOut.String())
+		}
+	}
+}
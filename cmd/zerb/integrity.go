@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/binary"
+)
+
+// envVerifyBinaries names the environment variable that makes activate and
+// drift check the core components for tampering before running them:
+// "1", "true" or "yes"
+const envVerifyBinaries = "ZERB_VERIFY_BINARIES"
+
+// verifyBinariesRequested reports whether ZERB_VERIFY_BINARIES is set to a
+// true value
+func verifyBinariesRequested() bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(envVerifyBinaries))) {
+	case "1", "true", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// quickVerifyBinaries checks the core components in zerbDir against the
+// hashes recorded when they were verified, when ZERB_VERIFY_BINARIES is
+// set. A component that was changed makes it fail, pointing to zerb
+// doctor. A component that could only not be verified yet (e.g. offline)
+// is warned about instead. Tests replace it.
+var quickVerifyBinaries = func(ctx context.Context, zerbDir string) error {
+	if !verifyBinariesRequested() {
+		return nil
+	}
+
+	platformInfo, err := detectPlatform(ctx)
+	if err != nil {
+		return err
+	}
+	manager, err := newBinaryManager(zerbDir, platformInfo)
+	if err != nil {
+		return err
+	}
+	err = manager.QuickVerify(ctx)
+	if onlyUnverified(err) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\nIt will be verified again on the next run.\n", err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("verify core components: %w", err)
+	}
+	return nil
+}
+
+// onlyUnverified reports whether err is made up of *binary.UnverifiedError
+// alone, so no component is known to be tampered with
+func onlyUnverified(err error) bool {
+	if err == nil {
+		return false
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			if !onlyUnverified(e) {
+				return false
+			}
+		}
+		return true
+	}
+	var unverified *binary.UnverifiedError
+	return errors.As(err, &unverified)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/binary"
+)
+
+// recordIntegrity writes the integrity records of the installed core
+// components of zerbDir, as installing them does
+func recordIntegrity(t *testing.T, zerbDir string) {
+	t.Helper()
+	for _, b := range []binary.Binary{binary.BinaryMise, binary.BinaryChezmoi} {
+		data, err := os.ReadFile(filepath.Join(zerbDir, "bin", b.String()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum := sha256.Sum256(data)
+		record, err := json.Marshal(binary.IntegrityRecord{Binary: b.String(), SHA256: hex.EncodeToString(sum[:])})
+		if err != nil {
+			t.Fatal(err)
+		}
+		dir := filepath.Join(zerbDir, "cache", "versions")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, b.String()+".integrity.json"), record, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestQuickVerifyBinaries(t *testing.T) {
+	zerbDir, manager := setupDoctorTest(t)
+	ctx := context.Background()
+
+	// Record the installed components, then swap one
+	recordIntegrity(t, zerbDir)
+	if err := manager.QuickVerify(ctx); err != nil {
+		t.Fatalf("QuickVerify() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(zerbDir, "bin", "chezmoi"), []byte("#!/bin/sh\necho swapped\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{value: "", wantErr: false},
+		{value: "0", wantErr: false},
+		{value: "1", wantErr: true},
+		{value: "true", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run("ZERB_VERIFY_BINARIES="+tt.value, func(t *testing.T) {
+			t.Setenv(envVerifyBinaries, tt.value)
+			err := quickVerifyBinaries(ctx, zerbDir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("quickVerifyBinaries() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			for _, want := range []string{"configuration manager has changed", "zerb doctor"} {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error = %v, want it to contain %q", err, want)
+				}
+			}
+			if strings.Contains(err.Error(), "chezmoi") {
+				t.Errorf("error names the underlying tool: %v", err)
+			}
+		})
+	}
+}
+
+func TestOnlyUnverified(t *testing.T) {
+	unverified := &binary.UnverifiedError{Binary: binary.BinaryMise, Version: "2024.12.7", Err: errors.New("offline")}
+	tampered := &binary.TamperedError{Binary: binary.BinaryChezmoi}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"unverified", unverified, true},
+		{"all unverified", errors.Join(unverified, unverified), true},
+		{"tampered", tampered, false},
+		{"unverified and tampered", errors.Join(unverified, tampered), false},
+		{"unverified and other", errors.Join(unverified, errors.New("stat binary")), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := onlyUnverified(tt.err); got != tt.want {
+				t.Errorf("onlyUnverified() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/layout"
+)
+
+// ensureLayout upgrades an initialized ZERB directory with an older layout
+// to the current one and reports what changed. Directories that are not
+// initialized are left for the command to report.
+func ensureLayout(zerbDir string) error {
+	if !isAlreadyInitialized(zerbDir) {
+		return nil
+	}
+
+	changes, err := layout.Migrate(zerbDir, clock.Real{})
+	if err != nil {
+		return fmt.Errorf("upgrade ZERB directory: %w", err)
+	}
+	if len(changes) > 0 {
+		fmt.Fprintf(os.Stderr, "✓ Upgraded ZERB directory to layout version %d:\n", layout.Version)
+		for _, change := range changes {
+			fmt.Fprintf(os.Stderr, "  - %s\n", change)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/layout"
+)
+
+func TestEnsureLayout(t *testing.T) {
+	t.Run("uninitialized directory is left alone", func(t *testing.T) {
+		zerbDir := t.TempDir()
+		if err := ensureLayout(zerbDir); err != nil {
+			t.Fatalf("ensureLayout() error = %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(zerbDir, layout.MarkerFile)); !os.IsNotExist(err) {
+			t.Errorf("layout marker written to an uninitialized directory")
+		}
+	})
+
+	t.Run("legacy directory is migrated", func(t *testing.T) {
+		zerbDir := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(zerbDir, "configs"), 0700); err != nil {
+			t.Fatalf("failed to create configs: %v", err)
+		}
+		if err := ensureLayout(zerbDir); err != nil {
+			t.Fatalf("ensureLayout() error = %v", err)
+		}
+		if version, err := layout.ReadVersion(zerbDir); err != nil || version != layout.Version {
+			t.Errorf("ReadVersion() = %d, %v; want %d", version, err, layout.Version)
+		}
+		if _, err := os.Stat(filepath.Join(zerbDir, "installs")); err != nil {
+			t.Errorf("installs/ not created: %v", err)
+		}
+	})
+
+	t.Run("newer layout is refused", func(t *testing.T) {
+		zerbDir := t.TempDir()
+		if err := layout.Create(zerbDir); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(zerbDir, layout.MarkerFile), []byte("99\n"), 0600); err != nil {
+			t.Fatalf("failed to write marker: %v", err)
+		}
+		if err := ensureLayout(zerbDir); !errors.Is(err, layout.ErrNewerLayout) {
+			t.Errorf("ensureLayout() error = %v, want ErrNewerLayout", err)
+		}
+	})
+}
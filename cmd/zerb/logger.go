@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/binary"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/platform"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/shell"
+)
+
+// envLogLevel names the environment variable that turns on diagnostic
+// logging to stderr: debug, info, warn or error
+const envLogLevel = "ZERB_LOG"
+
+// logger receives structured diagnostics from the binary and shell
+// managers. It discards everything unless applyLogLevel enables it.
+var logger = slog.New(slog.DiscardHandler)
+
+// applyLogLevel points logger at w when ZERB_LOG sets a level. Without
+// ZERB_LOG nothing is logged.
+func applyLogLevel(w io.Writer) error {
+	value := strings.TrimSpace(os.Getenv(envLogLevel))
+	if value == "" {
+		return nil
+	}
+
+	var level slog.Level
+	switch strings.ToLower(value) {
+	case "debug":
+		level = slog.LevelDebug
+	case "info":
+		level = slog.LevelInfo
+	case "warn", "warning":
+		level = slog.LevelWarn
+	case "error":
+		level = slog.LevelError
+	default:
+		return fmt.Errorf("%s: invalid level %q (use debug, info, warn or error)", envLogLevel, value)
+	}
+
+	logger = slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level}))
+	return nil
+}
+
+// newBinaryManager creates a binary manager for zerbDir that logs to logger
+func newBinaryManager(zerbDir string, platformInfo *platform.Info) (*binary.Manager, error) {
+	manager, err := binary.NewManager(binary.Config{
+		ZerbDir:      zerbDir,
+		PlatformInfo: platformInfo,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create binary manager: %w", err)
+	}
+	return manager.WithLogger(logger), nil
+}
+
+// newShellManager creates a shell manager for zerbDir that logs to logger
+func newShellManager(zerbDir string) (*shell.Manager, error) {
+	manager, err := shell.NewManager(shell.Config{ZerbDir: zerbDir})
+	if err != nil {
+		return nil, fmt.Errorf("create shell manager: %w", err)
+	}
+	return manager.WithLogger(logger), nil
+}
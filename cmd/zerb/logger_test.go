@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestApplyLogLevel(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		wantDebug bool
+		wantWarn  bool
+		wantErr   bool
+	}{
+		{"unset", "", false, false, false},
+		{"debug", "debug", true, true, false},
+		{"warn, any case", "WARN", false, true, false},
+		{"error", "error", false, false, false},
+		{"invalid", "loud", false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := logger
+			t.Cleanup(func() { logger = original })
+			t.Setenv(envLogLevel, tt.value)
+
+			var buf bytes.Buffer
+			err := applyLogLevel(&buf)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyLogLevel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			logger.Debug("debug event", "key", "value")
+			logger.Warn("warn event")
+			out := buf.String()
+			if got := strings.Contains(out, "debug event"); got != tt.wantDebug {
+				t.Errorf("debug logged = %v, want %v (output %q)", got, tt.wantDebug, out)
+			}
+			if got := strings.Contains(out, "warn event"); got != tt.wantWarn {
+				t.Errorf("warn logged = %v, want %v (output %q)", got, tt.wantWarn, out)
+			}
+		})
+	}
+}
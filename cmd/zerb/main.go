@@ -9,6 +9,22 @@ import (
 var Version = "v0.0.1-alpha"
 
 func main() {
+	// Strip global flags so subcommands only see their own options
+	args, yes := extractGlobalFlags(os.Args[1:])
+	args, parallelismValue, err := extractParallelismFlag(args)
+	if err == nil {
+		err = applyParallelism(parallelismValue)
+	}
+	if err == nil {
+		err = applyLogLevel(os.Stderr)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	os.Args = append([]string{os.Args[0]}, args...)
+	assumeYes = yes
+
 	// Handle subcommands
 	if len(os.Args) > 1 {
 		switch os.Args[1] {
@@ -37,6 +53,34 @@ func main() {
 				os.Exit(1)
 			}
 			return
+		case "repair-keyrings":
+			// Handle zerb repair-keyrings subcommand
+			if err := runRepairKeyrings(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "diff-env":
+			// Handle zerb diff-env subcommand
+			if err := runDiffEnv(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "env":
+			// Handle zerb env subcommand
+			if err := runEnv(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "sync":
+			// Handle zerb sync subcommand
+			if err := runSync(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
 		case "drift":
 			// Handle zerb drift subcommand
 			exitCode, err := runDrift(os.Args[2:])
@@ -45,17 +89,36 @@ func main() {
 				os.Exit(1)
 			}
 			os.Exit(exitCode)
+		case "doctor":
+			// Handle zerb doctor subcommand
+			exitCode, err := runDoctor(os.Args[2:])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(exitCode)
 		case "config":
 			// Handle zerb config subcommand
 			if len(os.Args) < 3 {
 				fmt.Fprintln(os.Stderr, "Error: config subcommand requires an action")
 				fmt.Fprintln(os.Stderr, "Usage: zerb config add [options] <path>...")
 				fmt.Fprintln(os.Stderr, "       zerb config list [options]")
+				fmt.Fprintln(os.Stderr, "       zerb config remove [options] <path>...")
+				fmt.Fprintln(os.Stderr, "       zerb config show [options]")
+				fmt.Fprintln(os.Stderr, "       zerb config export [options]")
+				fmt.Fprintln(os.Stderr, "       zerb config diff [options] <version-a> <version-b>")
+				fmt.Fprintln(os.Stderr, "       zerb config diff-file <path>")
+				fmt.Fprintln(os.Stderr, "       zerb config lint [options] [path]")
+				fmt.Fprintln(os.Stderr, "       zerb config history [path]")
+				fmt.Fprintln(os.Stderr, "       zerb config restore <version>")
+				fmt.Fprintln(os.Stderr, "       zerb config recover [options]")
+				fmt.Fprintln(os.Stderr, "       zerb config untrack-all [options]")
+				fmt.Fprintln(os.Stderr, "       zerb config rekey --to <recipient>")
 				os.Exit(1)
 			}
 			switch os.Args[2] {
 			case "add":
-				if err := runConfigAdd(os.Args[3:]); err != nil {
+				if _, err := runConfigAdd(os.Args[3:]); err != nil {
 					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 					os.Exit(1)
 				}
@@ -64,10 +127,78 @@ func main() {
 					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 					os.Exit(1)
 				}
+			case "remove":
+				if err := runConfigRemove(os.Args[3:]); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+			case "show":
+				if err := runConfigShow(os.Args[3:]); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+			case "export":
+				if err := runConfigExport(os.Args[3:]); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+			case "diff":
+				if err := runConfigDiff(os.Args[3:]); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+			case "diff-file":
+				if err := runConfigDiffFile(os.Args[3:]); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+			case "lint":
+				exitCode, err := runConfigLint(os.Args[3:])
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+				os.Exit(exitCode)
+			case "history":
+				if err := runConfigHistory(os.Args[3:]); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+			case "restore":
+				if err := runConfigRestore(os.Args[3:]); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+			case "recover":
+				if err := runConfigRecover(os.Args[3:]); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+			case "untrack-all":
+				if err := runConfigUntrackAll(os.Args[3:]); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+			case "rekey":
+				if err := runConfigRekey(os.Args[3:]); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
 			default:
 				fmt.Fprintf(os.Stderr, "Error: unknown config action: %s\n", os.Args[2])
 				fmt.Fprintln(os.Stderr, "Usage: zerb config add [options] <path>...")
 				fmt.Fprintln(os.Stderr, "       zerb config list [options]")
+				fmt.Fprintln(os.Stderr, "       zerb config remove [options] <path>...")
+				fmt.Fprintln(os.Stderr, "       zerb config show [options]")
+				fmt.Fprintln(os.Stderr, "       zerb config export [options]")
+				fmt.Fprintln(os.Stderr, "       zerb config diff [options] <version-a> <version-b>")
+				fmt.Fprintln(os.Stderr, "       zerb config diff-file <path>")
+				fmt.Fprintln(os.Stderr, "       zerb config lint [options] [path]")
+				fmt.Fprintln(os.Stderr, "       zerb config history [path]")
+				fmt.Fprintln(os.Stderr, "       zerb config restore <version>")
+				fmt.Fprintln(os.Stderr, "       zerb config recover [options]")
+				fmt.Fprintln(os.Stderr, "       zerb config untrack-all [options]")
+				fmt.Fprintln(os.Stderr, "       zerb config rekey --to <recipient>")
 				os.Exit(1)
 			}
 			return
@@ -85,12 +216,37 @@ func main() {
 	fmt.Println("  zerb --version             Show version information")
 	fmt.Println("  zerb init                  Initialize ZERB environment")
 	fmt.Println("  zerb uninit                Remove ZERB from your system")
-	fmt.Println("  zerb activate <shell>      Generate shell activation script (bash, zsh, fish)")
+	fmt.Println("  zerb activate <shell>      Generate shell activation script (bash, zsh, fish, nu, elvish, pwsh)")
+	fmt.Println("  zerb env [--shell <s>]     Print tool environment exports (bash, fish, json)")
 	fmt.Println("  zerb drift [options]       Check for environment drift")
+	fmt.Println("  zerb sync --pull|--push    Pull or push config history via the git remote")
 	fmt.Println("  zerb config add [options]  Add config files to tracking")
 	fmt.Println("  zerb config list [options] List tracked config files")
+	fmt.Println("  zerb config remove <path>  Stop tracking config files")
+	fmt.Println("  zerb config show [--raw]   Show the active config")
+	fmt.Println("  zerb config export [-o f]  Export the active config as JSON for diff-env")
+	fmt.Println("  zerb config diff <a> <b>   Compare two config versions")
+	fmt.Println("  zerb config diff-file <p>  Show how a tracked file differs on disk")
+	fmt.Println("  zerb config lint [path]    Check a config for problems")
+	fmt.Println("  zerb config history [path] Show config versions, or when a file was tracked")
+	fmt.Println("  zerb config restore <v>    Roll back to an earlier config version")
+	fmt.Println("  zerb config recover        Finish or undo an interrupted config add")
+	fmt.Println("  zerb config untrack-all    Stop tracking all config files")
+	fmt.Println("  zerb config rekey --to <r> Re-encrypt secret configs to a new key")
+	fmt.Println("  zerb diff-env <export>     Compare with another machine's exported config")
+	fmt.Println("  zerb repair-keyrings       Restore missing verification keys")
+	fmt.Println("  zerb doctor [--fix]        Check the installation for problems (and repair them)")
+	fmt.Println()
+	fmt.Println("Global options (before the command):")
+	fmt.Println("  -y, --yes                  Answer yes to all confirmation prompts")
+	fmt.Println("                             (or set ZERB_ASSUME_YES=1)")
+	fmt.Println("  --parallelism <n>          Run at most n tool operations at once")
+	fmt.Println("                             (or set ZERB_PARALLELISM; default: CPU count)")
+	fmt.Println()
+	fmt.Println("Environment:")
+	fmt.Println("  ZERB_LOG=<level>           Log diagnostics to stderr (debug, info, warn, error)")
+	fmt.Println("  ZERB_VERIFY_BINARIES=1     Check core components for tampering before activate and drift")
 	fmt.Println()
 	fmt.Println("Coming soon:")
 	fmt.Println("  zerb add                   Add tools to your environment")
-	fmt.Println("  zerb sync                  Sync tools and configs")
 }
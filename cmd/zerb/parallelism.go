@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/drift"
+)
+
+// extractParallelismFlag removes the global --parallelism flag given before
+// the subcommand from the command line and returns its value, or "" if it
+// was not given. Everything from the subcommand on is left to it, as with
+// extractGlobalFlags.
+func extractParallelismFlag(args []string) ([]string, string, error) {
+	value := ""
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--parallelism":
+			if i+1 >= len(args) {
+				return nil, "", fmt.Errorf("--parallelism requires a number of workers")
+			}
+			i++
+			value = args[i]
+		case strings.HasPrefix(arg, "--parallelism="):
+			value = strings.TrimPrefix(arg, "--parallelism=")
+		default:
+			return args[i:], value, nil
+		}
+	}
+	return []string{}, value, nil
+}
+
+// applyParallelism sets how many tool operations run at once from the
+// --parallelism value, else ZERB_PARALLELISM. Without either the default
+// (one per CPU) is kept.
+func applyParallelism(flagValue string) error {
+	value, source := flagValue, "--parallelism"
+	if value == "" {
+		value, source = os.Getenv(drift.EnvParallelism), drift.EnvParallelism
+	}
+	if value == "" {
+		return nil
+	}
+
+	n, err := drift.ParseParallelism(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+	return drift.SetParallelism(n)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/drift"
+)
+
+func TestExtractParallelismFlag(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantArgs  []string
+		wantValue string
+		wantErr   bool
+	}{
+		{"not given", []string{"drift", "--fix"}, []string{"drift", "--fix"}, "", false},
+		{"separate value", []string{"--parallelism", "2", "drift"}, []string{"drift"}, "2", false},
+		{"equals form", []string{"--parallelism=8", "drift"}, []string{"drift"}, "8", false},
+		{"after subcommand", []string{"drift", "--parallelism=8"}, []string{"drift", "--parallelism=8"}, "", false},
+		{"subcommand argument", []string{"config", "add", "--parallelism", "2"}, []string{"config", "add", "--parallelism", "2"}, "", false},
+		{"only flag", []string{"--parallelism", "4"}, []string{}, "4", false},
+		{"missing value", []string{"--parallelism"}, nil, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotArgs, gotValue, err := extractParallelismFlag(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("extractParallelismFlag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) || gotValue != tt.wantValue {
+				t.Errorf("extractParallelismFlag() = %v, %q, want %v, %q", gotArgs, gotValue, tt.wantArgs, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestApplyParallelism(t *testing.T) {
+	old := drift.Parallelism()
+	t.Cleanup(func() { _ = drift.SetParallelism(old) })
+
+	tests := []struct {
+		name    string
+		flag    string
+		env     string
+		want    int
+		wantErr bool
+	}{
+		{name: "flag", flag: "2", env: "6", want: 2},
+		{name: "environment", env: "6", want: 6},
+		{name: "flag below one", flag: "0", wantErr: true},
+		{name: "invalid environment", env: "many", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(drift.EnvParallelism, tt.env)
+			_ = drift.SetParallelism(old)
+
+			err := applyParallelism(tt.flag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyParallelism() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if drift.Parallelism() != old {
+					t.Errorf("Parallelism() = %d after an invalid value, want unchanged %d", drift.Parallelism(), old)
+				}
+				return
+			}
+			if got := drift.Parallelism(); got != tt.want {
+				t.Errorf("Parallelism() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
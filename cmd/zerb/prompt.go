@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/prompt"
+)
+
+// assumeYes is set by the global --yes/-y flag
+var assumeYes bool
+
+// nonInteractive is set by commands running without a terminal (e.g. init
+// --non-interactive) so that no prompt waits for input
+var nonInteractive bool
+
+// extractGlobalFlags removes global flags given before the subcommand from
+// the command line and reports whether --yes/-y was given. Everything from
+// the subcommand on is left to it, so a subcommand's own -y is not taken.
+// Other global options (--parallelism) are kept for their own extraction.
+func extractGlobalFlags(args []string) ([]string, bool) {
+	rest := make([]string, 0, len(args))
+	yes := false
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--yes" || arg == "-y":
+			yes = true
+		case arg == "--parallelism" && i+1 < len(args):
+			rest = append(rest, arg, args[i+1])
+			i++
+		case strings.HasPrefix(arg, "--parallelism="):
+			rest = append(rest, arg)
+		default:
+			return append(rest, args[i:]...), yes
+		}
+	}
+	return rest, yes
+}
+
+// newPrompter returns a Prompter on stdin/stdout that auto-confirms when
+// --yes or ZERB_ASSUME_YES is set, and never reads stdin when running
+// non-interactively
+func newPrompter() *prompt.Prompter {
+	return prompt.NewPrompter(os.Stdin, os.Stdout).
+		WithAssumeYes(assumeYes || prompt.AssumeYesFromEnv()).
+		WithNonInteractive(nonInteractive)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/prompt"
+)
+
+func TestExtractGlobalFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantArgs []string
+		wantYes  bool
+	}{
+		{"no flags", []string{"uninit", "--dry-run"}, []string{"uninit", "--dry-run"}, false},
+		{"before subcommand", []string{"--yes", "uninit"}, []string{"uninit"}, true},
+		{"after subcommand", []string{"uninit", "-y", "--keep-cache"}, []string{"uninit", "-y", "--keep-cache"}, false},
+		{"before and after subcommand", []string{"-y", "config", "untrack-all", "--yes"}, []string{"config", "untrack-all", "--yes"}, true},
+		{"before other global flags", []string{"--yes", "--parallelism", "2", "drift"}, []string{"--parallelism", "2", "drift"}, true},
+		{"after other global flags", []string{"--parallelism", "2", "-y", "drift", "-y"}, []string{"--parallelism", "2", "drift", "-y"}, true},
+		{"after terminator", []string{"config", "add", "--", "-y"}, []string{"config", "add", "--", "-y"}, false},
+		{"empty", []string{}, []string{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotArgs, gotYes := extractGlobalFlags(tt.args)
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", gotArgs, tt.wantArgs)
+			}
+			if gotYes != tt.wantYes {
+				t.Errorf("yes = %v, want %v", gotYes, tt.wantYes)
+			}
+		})
+	}
+}
+
+func TestNewPrompter_AssumeYes(t *testing.T) {
+	t.Run("flag", func(t *testing.T) {
+		t.Setenv(prompt.EnvAssumeYes, "")
+		setAssumeYes(t, true)
+		if !newPrompter().AssumeYes() {
+			t.Error("expected --yes to auto-confirm prompts")
+		}
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		t.Setenv(prompt.EnvAssumeYes, "1")
+		setAssumeYes(t, false)
+		if !newPrompter().AssumeYes() {
+			t.Errorf("expected %s=1 to auto-confirm prompts", prompt.EnvAssumeYes)
+		}
+	})
+
+	t.Run("neither", func(t *testing.T) {
+		t.Setenv(prompt.EnvAssumeYes, "")
+		setAssumeYes(t, false)
+		if newPrompter().AssumeYes() {
+			t.Error("expected prompts to require an answer by default")
+		}
+	})
+}
+
+func TestAssumeYes_ConfirmsUninitPrompt(t *testing.T) {
+	// No input is available, so the prompt only succeeds if it is auto-confirmed
+	p := prompt.NewPrompter(strings.NewReader(""), &bytes.Buffer{}).WithAssumeYes(true)
+
+	confirmed, err := confirmUninit(p, &UninitFlags{})
+	if err != nil {
+		t.Fatalf("confirmUninit() error = %v", err)
+	}
+	if !confirmed {
+		t.Error("expected --yes to confirm the uninit prompt")
+	}
+}
+
+func TestAssumeYes_DoesNotBypassSystemDirRefusal(t *testing.T) {
+	t.Setenv("ZERB_DIR", "/")
+	t.Setenv(prompt.EnvAssumeYes, "1")
+	setAssumeYes(t, true)
+
+	err := runUninit([]string{"--force"})
+	if err == nil {
+		t.Fatal("expected uninit to refuse removing a system directory, got nil")
+	}
+	if !strings.Contains(err.Error(), "system directory") {
+		t.Errorf("error = %v, want system directory refusal", err)
+	}
+}
+
+// setAssumeYes sets the global --yes flag for the duration of a test
+func setAssumeYes(t *testing.T, v bool) {
+	t.Helper()
+	old := assumeYes
+	assumeYes = v
+	t.Cleanup(func() { assumeYes = old })
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/binary"
+)
+
+// runRepairKeyrings handles the `zerb repair-keyrings` subcommand
+func runRepairKeyrings(args []string) error {
+	// Parse flags
+	showHelp := false
+	force := false
+
+	for _, arg := range args {
+		switch arg {
+		case "--help", "-h":
+			showHelp = true
+		case "--force":
+			force = true
+		default:
+			return fmt.Errorf("unknown option: %s\nRun 'zerb repair-keyrings --help' for usage", arg)
+		}
+	}
+
+	if showHelp {
+		printRepairKeyringsHelp()
+		return nil
+	}
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Get ZERB directory
+	zerbDir, err := getZerbDir()
+	if err != nil {
+		return fmt.Errorf("get ZERB directory: %w", err)
+	}
+	if err := ensureLayout(zerbDir); err != nil {
+		return err
+	}
+	if !isAlreadyInitialized(zerbDir) {
+		return fmt.Errorf("ZERB not initialized\nRun 'zerb init' to set up ZERB first")
+	}
+
+	platformInfo, err := detectPlatform(ctx)
+	if err != nil {
+		return err
+	}
+	manager, err := newBinaryManager(zerbDir, platformInfo)
+	if err != nil {
+		return err
+	}
+
+	return repairKeyrings(os.Stdout, manager, force)
+}
+
+// repairKeyrings re-extracts missing verification keys (all of them with
+// force) and checks the result against the keys built into zerb
+func repairKeyrings(w io.Writer, keyrings *binary.Manager, force bool) error {
+	missing := 0
+	for _, k := range keyrings.ListKeyrings() {
+		if !k.Present {
+			missing++
+		}
+	}
+
+	if force {
+		if err := keyrings.ExtractKeyrings(); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "✓ Re-extracted all verification keys\n")
+	} else {
+		if err := keyrings.EnsureKeyrings(); err != nil {
+			return err
+		}
+		if missing > 0 {
+			fmt.Fprintf(w, "✓ Restored %d missing verification key(s)\n", missing)
+		} else {
+			fmt.Fprintf(w, "✓ All verification keys present\n")
+		}
+	}
+
+	// The underlying error names internal key files, so it is not shown
+	if err := keyrings.VerifyKeyrings(); err != nil {
+		return fmt.Errorf("verification keys do not match the keys built into zerb\nRun 'zerb repair-keyrings --force' to replace them")
+	}
+	fmt.Fprintf(w, "✓ Verification keys match\n")
+
+	return nil
+}
+
+// printRepairKeyringsHelp prints help for the repair-keyrings command
+func printRepairKeyringsHelp() {
+	fmt.Println("Usage: zerb repair-keyrings [options]")
+	fmt.Println()
+	fmt.Println("Restore the verification keys ZERB uses to check downloaded")
+	fmt.Println("binaries, without re-running init. Missing keys are re-extracted")
+	fmt.Println("from the keys built into zerb; keys already present are left")
+	fmt.Println("untouched and checked against the built-in copies.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -h, --help    Show this help message")
+	fmt.Println("  --force       Re-extract every key, replacing the copies on disk")
+	fmt.Println()
+}
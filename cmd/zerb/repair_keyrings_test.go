@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/binary"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/platform"
+)
+
+func newTestKeyringManager(t *testing.T) (*binary.Manager, string) {
+	t.Helper()
+	zerbDir := t.TempDir()
+	manager, err := binary.NewManager(binary.Config{
+		ZerbDir:      zerbDir,
+		PlatformInfo: &platform.Info{OS: "linux", Arch: "amd64"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	if err := manager.EnsureKeyrings(); err != nil {
+		t.Fatalf("EnsureKeyrings() error = %v", err)
+	}
+	return manager, filepath.Join(zerbDir, "keyrings")
+}
+
+func TestRepairKeyrings(t *testing.T) {
+	t.Run("restores a deleted keyring", func(t *testing.T) {
+		manager, keyringDir := newTestKeyringManager(t)
+		if err := os.RemoveAll(keyringDir); err != nil {
+			t.Fatalf("failed to delete keyrings: %v", err)
+		}
+
+		var out bytes.Buffer
+		if err := repairKeyrings(&out, manager, false); err != nil {
+			t.Fatalf("repairKeyrings() error = %v", err)
+		}
+		if !strings.Contains(out.String(), "Restored 2 missing verification key(s)") {
+			t.Errorf("output = %q", out.String())
+		}
+		for _, k := range manager.ListKeyrings() {
+			if !k.Present {
+				t.Errorf("%s not restored", k.Path)
+			}
+		}
+	})
+
+	t.Run("intact keyrings", func(t *testing.T) {
+		manager, _ := newTestKeyringManager(t)
+
+		var out bytes.Buffer
+		if err := repairKeyrings(&out, manager, false); err != nil {
+			t.Fatalf("repairKeyrings() error = %v", err)
+		}
+		if !strings.Contains(out.String(), "All verification keys present") {
+			t.Errorf("output = %q", out.String())
+		}
+	})
+
+	t.Run("corrupted keyring needs force", func(t *testing.T) {
+		manager, keyringDir := newTestKeyringManager(t)
+		if err := os.WriteFile(filepath.Join(keyringDir, "mise.gpg"), []byte("garbage"), 0644); err != nil {
+			t.Fatalf("failed to corrupt keyring: %v", err)
+		}
+
+		var out bytes.Buffer
+		err := repairKeyrings(&out, manager, false)
+		if err == nil || !strings.Contains(err.Error(), "--force") {
+			t.Fatalf("repairKeyrings() error = %v, want a hint to use --force", err)
+		}
+
+		out.Reset()
+		if err := repairKeyrings(&out, manager, true); err != nil {
+			t.Fatalf("repairKeyrings(force) error = %v", err)
+		}
+		if !strings.Contains(out.String(), "Verification keys match") {
+			t.Errorf("output = %q", out.String())
+		}
+	})
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/drift"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+)
+
+// runSync handles the `zerb sync` subcommand
+func runSync(args []string) error {
+	// Parse flags
+	showHelp := false
+	push := false
+	pull := false
+	allowDrift := false
+
+	for _, arg := range args {
+		switch arg {
+		case "--help", "-h":
+			showHelp = true
+		case "--push":
+			push = true
+		case "--pull":
+			pull = true
+		case "--allow-drift":
+			allowDrift = true
+		default:
+			return fmt.Errorf("unknown option: %s\nRun 'zerb sync --help' for usage", arg)
+		}
+	}
+
+	if showHelp {
+		printSyncHelp()
+		return nil
+	}
+
+	if !push && !pull {
+		return fmt.Errorf("nothing to sync: specify --pull and/or --push\nRun 'zerb sync --help' for usage")
+	}
+
+	// Create context with timeout (drift detection and the push can both be slow)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	// Get ZERB directory
+	zerbDir, err := getZerbDir()
+	if err != nil {
+		return fmt.Errorf("get ZERB directory: %w", err)
+	}
+	if err := ensureLayout(zerbDir); err != nil {
+		return err
+	}
+	activeConfigPath := filepath.Join(zerbDir, "zerb.active.lua")
+	data, err := os.ReadFile(activeConfigPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("ZERB not initialized\nRun 'zerb init' to set up ZERB first")
+		}
+		return fmt.Errorf("check ZERB initialization: %w", err)
+	}
+	cfg, err := config.NewParser(nil).ParseString(ctx, string(data))
+	if err != nil {
+		return fmt.Errorf("parse active config: %w", err)
+	}
+
+	client := git.NewClient(zerbDir)
+	if err := configureRemote(ctx, client, cfg.Git.Remote); err != nil {
+		return err
+	}
+
+	if pull {
+		if err := syncPull(ctx, os.Stdout, client); err != nil {
+			return err
+		}
+	}
+	if push {
+		return syncPush(ctx, os.Stdout, zerbDir, client, allowDrift)
+	}
+	return nil
+}
+
+// configureRemote points the repository's remote at git.remote from the
+// config. Without git.remote an existing remote is used as is.
+func configureRemote(ctx context.Context, client git.Git, remote string) error {
+	if remote == "" {
+		return nil
+	}
+	if err := client.SetRemote(ctx, remote); err != nil {
+		return fmt.Errorf("configure remote: %w", err)
+	}
+	return nil
+}
+
+// syncPull fast-forwards the ZERB repository to its remote
+func syncPull(ctx context.Context, w io.Writer, client git.Git) error {
+	if err := client.Pull(ctx, git.DefaultRemote, ""); err != nil {
+		return remoteHint(err, "pull")
+	}
+	fmt.Fprintf(w, "✓ Pulled from %s\n", git.DefaultRemote)
+	return nil
+}
+
+// detectDriftResults runs drift detection against the active config without
+// printing progress. Tests replace it to simulate drift.
+var detectDriftResults = func(ctx context.Context, zerbDir string) ([]drift.DriftResult, error) {
+	baseline, declared, err := queryDriftBaseline(ctx, filepath.Join(zerbDir, "zerb.active.lua"))
+	if err != nil || len(baseline) == 0 {
+		return nil, err
+	}
+	detection, err := detectDrift(ctx, zerbDir, baseline, declared, driftQuery{})
+	if err != nil {
+		return nil, err
+	}
+	return detection.Results, nil
+}
+
+// syncPush pushes the ZERB repository to its remote. Unless allowDrift is
+// set it first runs drift detection and refuses to push a config that does
+// not match the installed tools.
+func syncPush(ctx context.Context, w io.Writer, zerbDir string, client git.Git, allowDrift bool) error {
+	if !allowDrift {
+		fmt.Fprintln(w, "Checking for drift before pushing...")
+		results, err := detectDriftResults(ctx, zerbDir)
+		if err != nil {
+			return fmt.Errorf("check drift: %w", err)
+		}
+
+		if summary := drift.SummarizeDrift(results); summary.HasDrift() {
+			fmt.Fprint(w, drift.FormatDriftReport(results))
+			return fmt.Errorf("refusing to push: %d tool(s) do not match the configuration\n"+
+				"Resolve the drift with 'zerb drift --fix', or push anyway with 'zerb sync --push --allow-drift'", summary.Drifted())
+		}
+		fmt.Fprintln(w, "✓ No drift detected")
+	}
+
+	if err := client.Push(ctx, git.DefaultRemote, ""); err != nil {
+		return remoteHint(err, "push")
+	}
+	fmt.Fprintf(w, "✓ Pushed to %s\n", git.DefaultRemote)
+
+	return nil
+}
+
+// remoteHint adds what to do next to a failed pull or push
+func remoteHint(err error, op string) error {
+	switch {
+	case errors.Is(err, git.ErrAuthFailed):
+		return fmt.Errorf("%w\nCheck that your SSH key or access token can %s to the remote", err, op)
+	case errors.Is(err, git.ErrNonFastForward) && op == "push":
+		return fmt.Errorf("%w\nRun 'zerb sync --pull' to bring in the remote changes, then push again", err)
+	case errors.Is(err, git.ErrNonFastForward):
+		return fmt.Errorf("%w\nThis machine and the remote have diverged; merge them with git in the ZERB directory", err)
+	case errors.Is(err, git.ErrRemoteNotFound):
+		return fmt.Errorf("%w\nCheck git.remote in your config", err)
+	default:
+		return err
+	}
+}
+
+// printSyncHelp prints help for the sync command
+func printSyncHelp() {
+	fmt.Println("Usage: zerb sync [options]")
+	fmt.Println()
+	fmt.Println("Share your ZERB environment through its git remote.")
+	fmt.Println()
+	fmt.Println("Before pushing, ZERB checks for drift and refuses to push a")
+	fmt.Println("configuration that does not match the tools actually installed,")
+	fmt.Println("so you don't share a config you forgot to update.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -h, --help       Show this help message")
+	fmt.Println("  --pull           Fast-forward to the config history on the remote")
+	fmt.Println("  --push           Push config history to the remote")
+	fmt.Println("  --allow-drift    Push even if drift is detected")
+	fmt.Println()
+	fmt.Println("The remote is git.remote from your config, if set.")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  zerb sync --pull")
+	fmt.Println("  zerb sync --pull --push")
+	fmt.Println("  zerb sync --push")
+	fmt.Println("  zerb sync --push --allow-drift")
+	fmt.Println()
+}
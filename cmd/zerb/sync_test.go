@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/drift"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+)
+
+// pushRecorder is a git.Git that records pushes, pulls and remotes set
+type pushRecorder struct {
+	git.Git
+	pushes  []string
+	pulls   []string
+	remotes []string
+	pullErr error
+}
+
+func (p *pushRecorder) Push(ctx context.Context, remote, branch string) error {
+	p.pushes = append(p.pushes, remote)
+	return nil
+}
+
+func (p *pushRecorder) Pull(ctx context.Context, remote, branch string) error {
+	p.pulls = append(p.pulls, remote)
+	return p.pullErr
+}
+
+func (p *pushRecorder) SetRemote(ctx context.Context, url string) error {
+	p.remotes = append(p.remotes, url)
+	return nil
+}
+
+func TestSyncPush_DriftCheck(t *testing.T) {
+	clean := []drift.DriftResult{
+		{Tool: "node", DriftType: drift.DriftOK, BaselineVersion: "20.11.0", ActiveVersion: "20.11.0"},
+	}
+	drifted := append(clean, drift.DriftResult{
+		Tool: "python", DriftType: drift.DriftVersionMismatch, BaselineVersion: "3.12.1", ActiveVersion: "3.11.0",
+	})
+
+	tests := []struct {
+		name       string
+		results    []drift.DriftResult
+		allowDrift bool
+		wantPush   bool
+		wantErr    string
+		wantOutput string
+		wantChecks int
+	}{
+		{
+			name:       "clean config is pushed",
+			results:    clean,
+			wantPush:   true,
+			wantOutput: "✓ No drift detected",
+			wantChecks: 1,
+		},
+		{
+			name:       "drift refuses push",
+			results:    drifted,
+			wantErr:    "refusing to push: 1 tool(s) do not match the configuration",
+			wantOutput: "python",
+			wantChecks: 1,
+		},
+		{
+			name:       "allow-drift skips the check",
+			results:    drifted,
+			allowDrift: true,
+			wantPush:   true,
+			wantOutput: "✓ Pushed to origin",
+			wantChecks: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks := 0
+			orig := detectDriftResults
+			detectDriftResults = func(context.Context, string) ([]drift.DriftResult, error) {
+				checks++
+				return tt.results, nil
+			}
+			t.Cleanup(func() { detectDriftResults = orig })
+
+			client := &pushRecorder{}
+			var out bytes.Buffer
+			err := syncPush(context.Background(), &out, t.TempDir(), client, tt.allowDrift)
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("syncPush() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("syncPush() error = %v, want %q", err, tt.wantErr)
+			}
+			if pushed := len(client.pushes) > 0; pushed != tt.wantPush {
+				t.Errorf("pushed = %v, want %v", pushed, tt.wantPush)
+			}
+			if checks != tt.wantChecks {
+				t.Errorf("drift checks = %d, want %d", checks, tt.wantChecks)
+			}
+			if !strings.Contains(out.String(), tt.wantOutput) {
+				t.Errorf("output missing %q:\n%s", tt.wantOutput, out.String())
+			}
+		})
+	}
+}
+
+func TestRunSync_RequiresPush(t *testing.T) {
+	if err := runSync(nil); err == nil || !strings.Contains(err.Error(), "--pull and/or --push") {
+		t.Errorf("runSync() error = %v, want hint about --pull and --push", err)
+	}
+	if err := runSync([]string{"--bogus"}); err == nil || !strings.Contains(err.Error(), "unknown option") {
+		t.Errorf("runSync(--bogus) error = %v, want unknown option", err)
+	}
+}
+
+func TestSyncPull(t *testing.T) {
+	tests := []struct {
+		name     string
+		pullErr  error
+		wantErr  error
+		wantHint string
+	}{
+		{name: "success"},
+		{
+			name:     "no remote",
+			pullErr:  fmt.Errorf("pull main from origin: %w: set git.remote in your config", git.ErrNoRemote),
+			wantErr:  git.ErrNoRemote,
+			wantHint: "set git.remote",
+		},
+		{
+			name:     "authentication failure",
+			pullErr:  fmt.Errorf("pull main from origin: %w", git.ErrAuthFailed),
+			wantErr:  git.ErrAuthFailed,
+			wantHint: "SSH key or access token can pull",
+		},
+		{
+			name:     "diverged",
+			pullErr:  fmt.Errorf("pull main from origin: %w", git.ErrNonFastForward),
+			wantErr:  git.ErrNonFastForward,
+			wantHint: "have diverged",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &pushRecorder{pullErr: tt.pullErr}
+			var out bytes.Buffer
+			err := syncPull(context.Background(), &out, client)
+
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("syncPull() error = %v", err)
+				}
+				if !strings.Contains(out.String(), "✓ Pulled from origin") {
+					t.Errorf("output = %q, want pull confirmation", out.String())
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("syncPull() error = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantHint) {
+				t.Errorf("syncPull() error = %q, want hint %q", err, tt.wantHint)
+			}
+		})
+	}
+}
+
+func TestRemoteHint_Push(t *testing.T) {
+	err := remoteHint(fmt.Errorf("push main to origin: %w", git.ErrNonFastForward), "push")
+	if !errors.Is(err, git.ErrNonFastForward) || !strings.Contains(err.Error(), "zerb sync --pull") {
+		t.Errorf("remoteHint() = %v, want a hint to pull first", err)
+	}
+}
+
+func TestConfigureRemote(t *testing.T) {
+	client := &pushRecorder{}
+	if err := configureRemote(context.Background(), client, ""); err != nil {
+		t.Fatalf("configureRemote() without git.remote error = %v", err)
+	}
+	if err := configureRemote(context.Background(), client, "git@github.com:user/dotfiles.git"); err != nil {
+		t.Fatalf("configureRemote() error = %v", err)
+	}
+	if len(client.remotes) != 1 || client.remotes[0] != "git@github.com:user/dotfiles.git" {
+		t.Errorf("remotes set = %v, want only git.remote", client.remotes)
+	}
+}
@@ -8,8 +8,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-	"time"
 
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/prompt"
 	"github.com/ZebulonRouseFrantzich/zerb/internal/shell"
 )
 
@@ -21,6 +22,12 @@ type UninitFlags struct {
 	keepBackups bool
 	noBackup    bool
 	dryRun      bool
+	// removeShellIntegration removes the activation line from rc files
+	// instead of leaving it for the user to remove
+	removeShellIntegration bool
+	// restoreRC restores rc files from their oldest backup, taken before
+	// ZERB changed them
+	restoreRC bool
 }
 
 // validateZerbDirForRemoval checks if zerbDir is safe to remove (no path traversal)
@@ -68,6 +75,10 @@ func parseUninitFlags(args []string) (*UninitFlags, error) {
 			flags.noBackup = true
 		case "--dry-run":
 			flags.dryRun = true
+		case "--remove-shell-integration":
+			flags.removeShellIntegration = true
+		case "--restore-rc":
+			flags.restoreRC = true
 		case "--help", "-h":
 			printUninitHelp()
 			return nil, fmt.Errorf("help requested")
@@ -78,6 +89,10 @@ func parseUninitFlags(args []string) (*UninitFlags, error) {
 		}
 	}
 
+	if flags.removeShellIntegration && flags.restoreRC {
+		return nil, fmt.Errorf("--remove-shell-integration and --restore-rc cannot be used together")
+	}
+
 	return flags, nil
 }
 
@@ -92,14 +107,27 @@ func printUninitHelp() {
 	fmt.Println("  --keep-configs     Preserve the configs/ directory")
 	fmt.Println("  --keep-cache       Preserve the cache/ directory")
 	fmt.Println("  --keep-backups     Don't remove old backup files")
+	fmt.Println("  --remove-shell-integration")
+	fmt.Println("                     Remove the activation line from shell rc files")
+	fmt.Println("                     (each rc file is backed up first)")
+	fmt.Println("  --restore-rc       Restore shell rc files from the backup taken before ZERB")
+	fmt.Println("                     changed them, moving the current files aside")
+	fmt.Println("                     (shows a diff and asks first unless --force)")
+	fmt.Println("  --no-backup        Don't back up rc files before removing the activation line")
 	fmt.Println("  --dry-run          Show what would be removed without removing")
 	fmt.Println("  --help, -h         Show this help message")
 	fmt.Println()
+	fmt.Println("Without --remove-shell-integration or --restore-rc, rc files are left untouched and")
+	fmt.Println("instructions for removing the activation line are shown instead.")
+	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  zerb uninit                    # Remove ZERB (with confirmation)")
 	fmt.Println("  zerb uninit --keep-configs     # Remove ZERB but keep configs")
 	fmt.Println("  zerb uninit --dry-run          # Preview what would be removed")
 	fmt.Println("  zerb uninit --force            # Remove without confirmation")
+	fmt.Println("  zerb uninit --remove-shell-integration")
+	fmt.Println("                                 # Also clean up shell rc files")
+	fmt.Println("  zerb uninit --restore-rc       # Also restore pre-ZERB rc files")
 }
 
 // RemovalPlan describes what will be removed
@@ -120,6 +148,11 @@ type ShellIntegration struct {
 	Shell  string
 	RCFile string
 	Line   int
+	// Backup is the oldest ZERB backup of RCFile ("" if none), which
+	// --restore-rc restores
+	Backup string
+	// Restored is set once RCFile has been restored from Backup
+	Restored bool
 }
 
 // analyzeInstallation analyzes the current ZERB installation
@@ -164,7 +197,7 @@ func analyzeInstallation(ctx context.Context, zerbDir string) (*RemovalPlan, err
 	// Detect shell integrations
 	homeDir, err := os.UserHomeDir()
 	if err == nil {
-		shells := []shell.ShellType{shell.ShellBash, shell.ShellZsh, shell.ShellFish}
+		shells := shell.GetSupportedShells()
 		for _, sh := range shells {
 			rcPath, err := shell.GetRCFilePath(sh)
 			if err != nil {
@@ -178,10 +211,12 @@ func analyzeInstallation(ctx context.Context, zerbDir string) (*RemovalPlan, err
 
 			// Find line number (for display)
 			lineNum := findActivationLineNumber(rcPath)
+			backup, _ := shell.PristineBackup(rcPath)
 			plan.ShellIntegrations = append(plan.ShellIntegrations, ShellIntegration{
 				Shell:  sh.String(),
 				RCFile: rcPath,
 				Line:   lineNum,
+				Backup: backup,
 			})
 		}
 
@@ -222,7 +257,7 @@ func findActivationLineNumber(rcPath string) int {
 	lineNum := 0
 	for scanner.Scan() {
 		lineNum++
-		if strings.Contains(scanner.Text(), shell.ActivationMarker) {
+		if shell.IsActivationLine(scanner.Text()) {
 			return lineNum
 		}
 	}
@@ -235,9 +270,12 @@ func findBackupFiles(homeDir string) []string {
 
 	// Look for .bashrc, .zshrc backup files
 	patterns := []string{
-		filepath.Join(homeDir, ".bashrc"+shell.BackupSuffix+".*"),
-		filepath.Join(homeDir, ".zshrc"+shell.BackupSuffix+".*"),
-		filepath.Join(homeDir, ".config", "fish", "config.fish"+shell.BackupSuffix+".*"),
+		shell.BackupGlob(filepath.Join(homeDir, ".bashrc")),
+		shell.BackupGlob(filepath.Join(homeDir, ".zshrc")),
+		shell.BackupGlob(filepath.Join(homeDir, ".config", "fish", "config.fish")),
+		shell.BackupGlob(filepath.Join(homeDir, ".config", "nushell", "config.nu")),
+		shell.BackupGlob(filepath.Join(homeDir, ".config", "elvish", "rc.elv")),
+		shell.BackupGlob(filepath.Join(homeDir, ".config", "powershell", "Microsoft.PowerShell_profile.ps1")),
 	}
 
 	for _, pattern := range patterns {
@@ -291,8 +329,29 @@ func showRemovalPlan(plan *RemovalPlan, flags *UninitFlags) {
 	}
 	fmt.Println("      - keyrings/, logs/, tmp/")
 
-	// Shell integrations (informational only - not automatically removed)
-	if len(plan.ShellIntegrations) > 0 {
+	// Shell integrations (informational only unless --remove-shell-integration
+	// or --restore-rc)
+	if len(plan.ShellIntegrations) > 0 && flags.restoreRC {
+		fmt.Println()
+		fmt.Println("  [×] Shell rc files restored from backup:")
+		for _, si := range plan.ShellIntegrations {
+			if si.Backup == "" {
+				fmt.Printf("      - %s (no backup found; remove line %d by hand)\n", si.RCFile, si.Line)
+				continue
+			}
+			fmt.Printf("      - %s from %s\n", si.RCFile, filepath.Base(si.Backup))
+		}
+		fmt.Println("      The current files are moved aside first.")
+	} else if len(plan.ShellIntegrations) > 0 && flags.removeShellIntegration {
+		fmt.Println()
+		fmt.Println("  [×] Shell integration in:")
+		for _, si := range plan.ShellIntegrations {
+			fmt.Printf("      - %s (line %d)\n", si.RCFile, si.Line)
+		}
+		if !flags.noBackup {
+			fmt.Println("      Each rc file is backed up before the line is removed.")
+		}
+	} else if len(plan.ShellIntegrations) > 0 {
 		fmt.Println()
 		fmt.Println("  [!] Shell integration found in:")
 		for _, si := range plan.ShellIntegrations {
@@ -300,7 +359,8 @@ func showRemovalPlan(plan *RemovalPlan, flags *UninitFlags) {
 		}
 		fmt.Println()
 		fmt.Println("      You'll need to manually remove this after uninstall.")
-		fmt.Println("      (Instructions will be shown after removal)")
+		fmt.Println("      (Instructions will be shown after removal, or rerun with")
+		fmt.Println("      --remove-shell-integration to remove it automatically)")
 	} else {
 		fmt.Println()
 		fmt.Println("  [✓] No shell integration detected")
@@ -325,7 +385,7 @@ func showRemovalPlan(plan *RemovalPlan, flags *UninitFlags) {
 }
 
 // confirmUninit prompts user for confirmation
-func confirmUninit(flags *UninitFlags) (bool, error) {
+func confirmUninit(p *prompt.Prompter, flags *UninitFlags) (bool, error) {
 	if flags.force {
 		return true, nil
 	}
@@ -348,19 +408,12 @@ func confirmUninit(flags *UninitFlags) (bool, error) {
 		fmt.Println()
 	}
 
-	fmt.Print("Are you sure you want to continue? (yes/no): ")
-	reader := bufio.NewReader(os.Stdin)
-	response, err := reader.ReadString('\n')
-	if err != nil {
-		return false, fmt.Errorf("read input: %w", err)
-	}
-
-	response = strings.TrimSpace(strings.ToLower(response))
-	return response == "yes" || response == "y", nil
+	return p.Confirm("Are you sure you want to continue? (yes/no): ")
 }
 
-// removeShellIntegrations removes ZERB from shell RC files
-func removeShellIntegrations(plan *RemovalPlan, flags *UninitFlags) error {
+// removeShellIntegrations removes ZERB from shell RC files, backing each up
+// under a name taken from clk
+func removeShellIntegrations(plan *RemovalPlan, flags *UninitFlags, clk clock.Clock) error {
 	if len(plan.ShellIntegrations) == 0 {
 		return nil
 	}
@@ -375,10 +428,11 @@ func removeShellIntegrations(plan *RemovalPlan, flags *UninitFlags) error {
 
 		// Create backup unless --no-backup
 		if !flags.noBackup {
-			backupPath, err := shell.BackupRCFile(si.RCFile)
+			backupPath, err := shell.BackupRCFile(si.RCFile, clk)
 			if err != nil {
 				fmt.Printf("  ⚠  Failed to backup %s: %v\n", si.RCFile, err)
 			} else {
+				plan.ActualBackupPaths = append(plan.ActualBackupPaths, backupPath)
 				fmt.Printf("  ✓ Backed up to %s\n", filepath.Base(backupPath))
 			}
 		}
@@ -394,8 +448,63 @@ func removeShellIntegrations(plan *RemovalPlan, flags *UninitFlags) error {
 	return nil
 }
 
-// removeZerbDirectory removes the ZERB directory
-func removeZerbDirectory(zerbDir string, flags *UninitFlags) error {
+// restoreRCFiles restores each rc file with shell integration from its
+// oldest backup, which must predate ZERB, showing the change and asking
+// first unless --force. The current file is moved aside to a new backup.
+// Files without a usable backup are left for the user to clean up.
+func restoreRCFiles(p *prompt.Prompter, plan *RemovalPlan, flags *UninitFlags, clk clock.Clock) error {
+	if len(plan.ShellIntegrations) == 0 {
+		return nil
+	}
+
+	fmt.Println("Restoring shell rc files...")
+
+	for i := range plan.ShellIntegrations {
+		si := &plan.ShellIntegrations[i]
+		if si.Backup == "" {
+			fmt.Printf("  ⚠  No backup of %s found\n", si.RCFile)
+			continue
+		}
+
+		change, err := shell.PreviewRestore(si.RCFile, si.Backup)
+		if err != nil {
+			fmt.Printf("  ⚠  Cannot restore %s: %v\n", si.RCFile, err)
+			continue
+		}
+
+		if !flags.force && change.Changed {
+			fmt.Println()
+			fmt.Printf("Restoring %s from %s:\n", si.RCFile, filepath.Base(si.Backup))
+			fmt.Print(change.Diff)
+			confirmed, err := p.Confirm(fmt.Sprintf("Restore %s? (yes/no): ", si.RCFile))
+			if err != nil {
+				return fmt.Errorf("confirm restore of %s: %w", si.RCFile, err)
+			}
+			if !confirmed {
+				fmt.Printf("  Skipped %s\n", si.RCFile)
+				continue
+			}
+		}
+
+		aside, err := shell.RestoreBackup(si.RCFile, si.Backup, clk)
+		if err != nil {
+			return fmt.Errorf("restore %s: %w", si.RCFile, err)
+		}
+		si.Restored = true
+		if aside != "" {
+			plan.ActualBackupPaths = append(plan.ActualBackupPaths, aside)
+			fmt.Printf("  ✓ Moved current file aside to %s\n", filepath.Base(aside))
+		}
+		fmt.Printf("  ✓ Restored %s from %s\n", si.RCFile, filepath.Base(si.Backup))
+	}
+
+	return nil
+}
+
+// removeZerbDirectory removes the ZERB directory. Configs and cache kept
+// with --keep-configs and --keep-cache are moved to directories named
+// after the time on clk.
+func removeZerbDirectory(zerbDir string, flags *UninitFlags, clk clock.Clock) error {
 	if !flags.dryRun {
 		fmt.Println()
 		fmt.Println("Removing ZERB directory...")
@@ -405,7 +514,7 @@ func removeZerbDirectory(zerbDir string, flags *UninitFlags) error {
 	if flags.keepConfigs {
 		configsDir := filepath.Join(zerbDir, "configs")
 		if _, err := os.Stat(configsDir); err == nil {
-			timestamp := time.Now().Format("20060102-150405")
+			timestamp := clk.Now().Format("20060102-150405")
 			backupDir := filepath.Join(os.Getenv("HOME"), fmt.Sprintf(".zerb-configs-backup-%s", timestamp))
 
 			if flags.dryRun {
@@ -424,7 +533,7 @@ func removeZerbDirectory(zerbDir string, flags *UninitFlags) error {
 	if flags.keepCache {
 		cacheDir := filepath.Join(zerbDir, "cache")
 		if _, err := os.Stat(cacheDir); err == nil {
-			timestamp := time.Now().Format("20060102-150405")
+			timestamp := clk.Now().Format("20060102-150405")
 			backupDir := filepath.Join(os.Getenv("HOME"), fmt.Sprintf(".zerb-cache-backup-%s", timestamp))
 
 			if flags.dryRun {
@@ -486,7 +595,7 @@ func removeBackupFiles(backupFiles []string, flags *UninitFlags) error {
 }
 
 // printUninitSuccessMessage prints the success message after uninstall
-func printUninitSuccessMessage(plan *RemovalPlan, flags *UninitFlags) {
+func printUninitSuccessMessage(plan *RemovalPlan, flags *UninitFlags, clk clock.Clock) {
 	fmt.Println()
 	fmt.Println("╔════════════════════════════════════════════════════════════╗")
 	fmt.Println("║  ZERB Successfully Uninstalled                             ║")
@@ -498,6 +607,12 @@ func printUninitSuccessMessage(plan *RemovalPlan, flags *UninitFlags) {
 	if len(plan.BackupFiles) > 0 && !flags.keepBackups {
 		fmt.Printf("  • %d backup files\n", len(plan.BackupFiles))
 	}
+	if len(plan.ShellIntegrations) > 0 && flags.removeShellIntegration {
+		fmt.Printf("  • Shell integration from %d rc files\n", len(plan.ShellIntegrations))
+	}
+	if restored := countRestored(plan); restored > 0 {
+		fmt.Printf("  • Shell integration, by restoring %d rc files from backup\n", restored)
+	}
 
 	fmt.Println()
 	totalSize := plan.ZerbDirSize
@@ -506,13 +621,33 @@ func printUninitSuccessMessage(plan *RemovalPlan, flags *UninitFlags) {
 	}
 	fmt.Printf("Freed %s of disk space\n", formatSize(totalSize))
 
+	// Shell integration was removed; point at the rc file backups
+	if len(plan.ShellIntegrations) > 0 && (flags.removeShellIntegration || countRestored(plan) > 0) {
+		if len(plan.ActualBackupPaths) > 0 {
+			fmt.Println()
+			fmt.Println("Your previous rc files were backed up to:")
+			for _, backup := range plan.ActualBackupPaths {
+				fmt.Printf("  %s\n", backup)
+			}
+		}
+		fmt.Println()
+		fmt.Println("Open a new shell to finish.")
+	}
+
 	// Show manual shell integration removal instructions
-	if len(plan.ShellIntegrations) > 0 {
+	if len(plan.ShellIntegrations) > countRestored(plan) && !flags.removeShellIntegration {
 		fmt.Println()
 		fmt.Println("⚠️  Don't forget to remove shell integration:")
 		fmt.Println()
 
+		var reload string
 		for _, si := range plan.ShellIntegrations {
+			if si.Restored {
+				continue
+			}
+			if reload == "" {
+				reload = si.RCFile
+			}
 			// Parse shell type from string
 			var shellType shell.ShellType
 			switch si.Shell {
@@ -522,6 +657,12 @@ func printUninitSuccessMessage(plan *RemovalPlan, flags *UninitFlags) {
 				shellType = shell.ShellZsh
 			case "fish":
 				shellType = shell.ShellFish
+			case "nu":
+				shellType = shell.ShellNushell
+			case "elvish":
+				shellType = shell.ShellElvish
+			case "pwsh":
+				shellType = shell.ShellPwsh
 			default:
 				continue
 			}
@@ -537,24 +678,22 @@ func printUninitSuccessMessage(plan *RemovalPlan, flags *UninitFlags) {
 			fmt.Println()
 		}
 
-		if len(plan.ShellIntegrations) > 0 {
-			fmt.Println("   Then reload your shell:")
-			fmt.Printf("     source %s\n", plan.ShellIntegrations[0].RCFile)
-			fmt.Println()
-		}
+		fmt.Println("   Then reload your shell:")
+		fmt.Printf("     source %s\n", reload)
+		fmt.Println()
 	}
 
 	if flags.keepConfigs {
 		fmt.Println()
 		fmt.Println("Your configs were preserved and can be found at:")
-		timestamp := time.Now().Format("20060102-150405")
+		timestamp := clk.Now().Format("20060102-150405")
 		fmt.Printf("  ~/.zerb-configs-backup-%s\n", timestamp)
 	}
 
 	if flags.keepCache {
 		fmt.Println()
 		fmt.Println("Your cache was preserved and can be found at:")
-		timestamp := time.Now().Format("20060102-150405")
+		timestamp := clk.Now().Format("20060102-150405")
 		fmt.Printf("  ~/.zerb-cache-backup-%s\n", timestamp)
 	}
 
@@ -562,6 +701,17 @@ func printUninitSuccessMessage(plan *RemovalPlan, flags *UninitFlags) {
 	fmt.Println("To reinstall ZERB, run: zerb init")
 }
 
+// countRestored returns the number of rc files restored from backup
+func countRestored(plan *RemovalPlan) int {
+	count := 0
+	for _, si := range plan.ShellIntegrations {
+		if si.Restored {
+			count++
+		}
+	}
+	return count
+}
+
 // runUninit handles the `zerb uninit` subcommand
 func runUninit(args []string) error {
 	// Parse flags
@@ -581,6 +731,11 @@ func runUninit(args []string) error {
 		return fmt.Errorf("get ZERB directory: %w", err)
 	}
 
+	// Refuse unsafe directories up front; neither --force nor --yes overrides this
+	if err := validateZerbDirForRemoval(zerbDir); err != nil {
+		return err
+	}
+
 	// Analyze current installation
 	plan, err := analyzeInstallation(ctx, zerbDir)
 	if err != nil {
@@ -605,7 +760,8 @@ func runUninit(args []string) error {
 	}
 
 	// Confirmation
-	confirmed, err := confirmUninit(flags)
+	p := newPrompter()
+	confirmed, err := confirmUninit(p, flags)
 	if err != nil {
 		return fmt.Errorf("confirmation: %w", err)
 	}
@@ -617,12 +773,25 @@ func runUninit(args []string) error {
 
 	fmt.Println()
 
-	// Note: Shell integration is NOT automatically removed
-	// Users must manually remove it from their rc files
-	// Instructions will be shown in the success message
+	// Names the rc file backups and the preserved configs and cache
+	clk := clock.Real{}
+
+	// Shell integration is only removed on request; otherwise users remove
+	// it from their rc files following the success message. Removing it
+	// first means a failure leaves the ZERB directory intact.
+	if flags.removeShellIntegration {
+		if err := removeShellIntegrations(plan, flags, clk); err != nil {
+			return fmt.Errorf("remove shell integration: %w", err)
+		}
+	}
+	if flags.restoreRC {
+		if err := restoreRCFiles(p, plan, flags, clk); err != nil {
+			return fmt.Errorf("restore shell rc files: %w", err)
+		}
+	}
 
 	// Remove ZERB directory
-	if err := removeZerbDirectory(zerbDir, flags); err != nil {
+	if err := removeZerbDirectory(zerbDir, flags, clk); err != nil {
 		return fmt.Errorf("remove ZERB directory: %w", err)
 	}
 
@@ -632,7 +801,7 @@ func runUninit(args []string) error {
 	}
 
 	// Print success message
-	printUninitSuccessMessage(plan, flags)
+	printUninitSuccessMessage(plan, flags, clk)
 
 	return nil
 }
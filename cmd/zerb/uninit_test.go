@@ -1,11 +1,17 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/prompt"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/shell"
 )
 
 func TestParseUninitFlags(t *testing.T) {
@@ -106,6 +112,28 @@ func TestParseUninitFlags(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Remove shell integration flag",
+			args: []string{"--remove-shell-integration"},
+			wantFlags: &UninitFlags{
+				removeShellIntegration: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Restore rc flag",
+			args: []string{"--restore-rc"},
+			wantFlags: &UninitFlags{
+				restoreRC: true,
+			},
+			wantErr: false,
+		},
+		{
+			name:      "Remove and restore shell integration",
+			args:      []string{"--remove-shell-integration", "--restore-rc"},
+			wantFlags: nil,
+			wantErr:   true,
+		},
 		{
 			name:      "Unknown flag",
 			args:      []string{"--unknown"},
@@ -151,6 +179,12 @@ func TestParseUninitFlags(t *testing.T) {
 			if flags.dryRun != tt.wantFlags.dryRun {
 				t.Errorf("dryRun = %v, want %v", flags.dryRun, tt.wantFlags.dryRun)
 			}
+			if flags.removeShellIntegration != tt.wantFlags.removeShellIntegration {
+				t.Errorf("removeShellIntegration = %v, want %v", flags.removeShellIntegration, tt.wantFlags.removeShellIntegration)
+			}
+			if flags.restoreRC != tt.wantFlags.restoreRC {
+				t.Errorf("restoreRC = %v, want %v", flags.restoreRC, tt.wantFlags.restoreRC)
+			}
 		})
 	}
 }
@@ -327,7 +361,7 @@ alias ll='ls -la'
 		noBackup: true, // Skip backup for test
 	}
 
-	err := removeShellIntegrations(plan, flags)
+	err := removeShellIntegrations(plan, flags, clock.Real{})
 	if err != nil {
 		t.Fatalf("removeShellIntegrations() error = %v", err)
 	}
@@ -349,6 +383,50 @@ alias ll='ls -la'
 	}
 }
 
+func TestRemoveShellIntegrations_Backup(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	rcPath := filepath.Join(tmpDir, ".bashrc")
+	originalContent := `export PATH=$PATH:/usr/local/bin
+
+# ZERB - Developer environment manager
+eval "$(zerb activate bash)"
+`
+	if err := os.WriteFile(rcPath, []byte(originalContent), 0644); err != nil {
+		t.Fatalf("Failed to create RC file: %v", err)
+	}
+
+	plan := &RemovalPlan{
+		ShellIntegrations: []ShellIntegration{
+			{Shell: "bash", RCFile: rcPath, Line: 4},
+		},
+	}
+
+	if err := removeShellIntegrations(plan, &UninitFlags{removeShellIntegration: true}, clock.Real{}); err != nil {
+		t.Fatalf("removeShellIntegrations() error = %v", err)
+	}
+
+	result, err := os.ReadFile(rcPath)
+	if err != nil {
+		t.Fatalf("Failed to read result: %v", err)
+	}
+	if strings.Contains(string(result), "zerb activate") {
+		t.Error("RC file still contains activation line")
+	}
+
+	// The backup keeps the original rc file and is recorded in the plan
+	if len(plan.ActualBackupPaths) != 1 {
+		t.Fatalf("ActualBackupPaths = %v, want one backup", plan.ActualBackupPaths)
+	}
+	backup, err := os.ReadFile(plan.ActualBackupPaths[0])
+	if err != nil {
+		t.Fatalf("Failed to read backup: %v", err)
+	}
+	if string(backup) != originalContent {
+		t.Errorf("backup = %q, want %q", backup, originalContent)
+	}
+}
+
 func TestRemoveShellIntegrations_DryRun(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -381,7 +459,7 @@ alias ll='ls -la'
 		dryRun: true,
 	}
 
-	err := removeShellIntegrations(plan, flags)
+	err := removeShellIntegrations(plan, flags, clock.Real{})
 	if err != nil {
 		t.Fatalf("removeShellIntegrations() error = %v", err)
 	}
@@ -397,6 +475,74 @@ alias ll='ls -la'
 	}
 }
 
+func TestRestoreRCFiles(t *testing.T) {
+	const original = "export EDITOR=vim\n"
+	const current = original + "\n# ZERB - Developer environment manager\neval \"$(zerb activate bash)\"\n"
+
+	tests := []struct {
+		name         string
+		backup       string // "" for no backup
+		flags        *UninitFlags
+		answer       string
+		wantRestored bool
+	}{
+		{name: "force restores without asking", backup: original, flags: &UninitFlags{force: true}, wantRestored: true},
+		{name: "confirmed", backup: original, flags: &UninitFlags{}, answer: "yes\n", wantRestored: true},
+		{name: "declined", backup: original, flags: &UninitFlags{}, answer: "no\n"},
+		{name: "backup taken after ZERB", backup: current, flags: &UninitFlags{force: true}},
+		{name: "no backup", flags: &UninitFlags{force: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			rcPath := filepath.Join(tmpDir, ".bashrc")
+			if err := os.WriteFile(rcPath, []byte(current), 0644); err != nil {
+				t.Fatalf("Failed to create RC file: %v", err)
+			}
+			si := ShellIntegration{Shell: "bash", RCFile: rcPath, Line: 4}
+			if tt.backup != "" {
+				si.Backup = shell.BackupPath(rcPath, time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC))
+				if err := os.WriteFile(si.Backup, []byte(tt.backup), 0644); err != nil {
+					t.Fatalf("Failed to create backup: %v", err)
+				}
+			}
+			plan := &RemovalPlan{ShellIntegrations: []ShellIntegration{si}}
+			p := prompt.NewPrompter(strings.NewReader(tt.answer), &bytes.Buffer{})
+
+			if err := restoreRCFiles(p, plan, tt.flags, clock.Real{}); err != nil {
+				t.Fatalf("restoreRCFiles() error = %v", err)
+			}
+
+			if got := plan.ShellIntegrations[0].Restored; got != tt.wantRestored {
+				t.Errorf("Restored = %v, want %v", got, tt.wantRestored)
+			}
+			result, err := os.ReadFile(rcPath)
+			if err != nil {
+				t.Fatalf("Failed to read result: %v", err)
+			}
+			want := current
+			if tt.wantRestored {
+				want = original
+			}
+			if string(result) != want {
+				t.Errorf("RC file = %q, want %q", result, want)
+			}
+
+			// The replaced file is kept as a backup
+			if tt.wantRestored {
+				if len(plan.ActualBackupPaths) != 1 {
+					t.Fatalf("ActualBackupPaths = %v, want one", plan.ActualBackupPaths)
+				}
+				moved, err := os.ReadFile(plan.ActualBackupPaths[0])
+				if err != nil || string(moved) != current {
+					t.Errorf("moved-aside file = %q (err %v), want %q", moved, err, current)
+				}
+			}
+		})
+	}
+}
+
 func TestRemoveZerbDirectory(t *testing.T) {
 	tmpDir := t.TempDir()
 	zerbDir := filepath.Join(tmpDir, "zerb")
@@ -425,7 +571,7 @@ func TestRemoveZerbDirectory(t *testing.T) {
 
 	flags := &UninitFlags{}
 
-	err := removeZerbDirectory(zerbDir, flags)
+	err := removeZerbDirectory(zerbDir, flags, clock.Real{})
 	if err != nil {
 		t.Fatalf("removeZerbDirectory() error = %v", err)
 	}
@@ -463,7 +609,8 @@ func TestRemoveZerbDirectory_KeepConfigs(t *testing.T) {
 		keepConfigs: true,
 	}
 
-	err := removeZerbDirectory(zerbDir, flags)
+	clk := clock.NewFake(time.Date(2025, 1, 2, 3, 4, 5, 0, time.Local))
+	err := removeZerbDirectory(zerbDir, flags, clk)
 	if err != nil {
 		t.Fatalf("removeZerbDirectory() error = %v", err)
 	}
@@ -473,11 +620,11 @@ func TestRemoveZerbDirectory_KeepConfigs(t *testing.T) {
 		t.Error("ZERB directory still exists")
 	}
 
-	// Configs should be backed up
+	// Configs should be backed up, named after the clock's time
 	backupPattern := filepath.Join(tmpDir, ".zerb-configs-backup-*")
 	matches, _ := filepath.Glob(backupPattern)
-	if len(matches) != 1 {
-		t.Errorf("Expected 1 config backup, found %d", len(matches))
+	if want := filepath.Join(tmpDir, ".zerb-configs-backup-20250102-030405"); len(matches) != 1 || matches[0] != want {
+		t.Errorf("config backups = %v, want [%s]", matches, want)
 	}
 
 	// Check config file exists in backup
@@ -506,7 +653,7 @@ func TestRemoveZerbDirectory_DryRun(t *testing.T) {
 		dryRun: true,
 	}
 
-	err := removeZerbDirectory(zerbDir, flags)
+	err := removeZerbDirectory(zerbDir, flags, clock.Real{})
 	if err != nil {
 		t.Fatalf("removeZerbDirectory() error = %v", err)
 	}
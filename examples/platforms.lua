@@ -9,6 +9,10 @@ zerb = {
     description = "Works on Linux, macOS, and Windows with platform-specific tools",
   },
 
+  -- Backends beyond the built-in ones (cargo, npm, ubi, ...) must be
+  -- listed before tools can use them
+  backends = { "scoop", "dnf", "apt" },
+
   tools = {
     -- Universal tools (work everywhere)
     "node@20.11.0",
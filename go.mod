@@ -8,7 +8,9 @@ require (
 	github.com/google/uuid v1.6.0
 	github.com/shirou/gopsutil/v4 v4.25.10
 	github.com/sigstore/sigstore v1.9.6-0.20250729224751-181c5d3339b3
+	github.com/ulikunitz/xz v0.5.15
 	github.com/yuin/gopher-lua v1.1.1
+	golang.org/x/sync v0.15.0
 )
 
 require (
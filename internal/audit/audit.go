@@ -0,0 +1,61 @@
+// Package audit records security-relevant events in the ZERB directory.
+//
+// The audit log is an append-only text file, one event per line:
+//
+//	2025-01-15T10:30:00Z insecure-skip-verify mise 2024.12.7 /path/to/archive
+//
+// Events are only ever appended; ZERB never rewrites or truncates the log.
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
+)
+
+// FileName is the name of the audit log in the ZERB logs directory.
+const FileName = "audit.log"
+
+// EventInsecureSkipVerify records a binary installed without verification.
+const EventInsecureSkipVerify = "insecure-skip-verify"
+
+// Path returns the path of the audit log for zerbDir.
+func Path(zerbDir string) string {
+	return filepath.Join(zerbDir, "logs", FileName)
+}
+
+// Record appends an event to the audit log of zerbDir, creating the log if
+// needed. Newlines in fields are replaced so each event stays on one line.
+func Record(zerbDir string, clk clock.Clock, event string, fields ...string) error {
+	if zerbDir == "" {
+		return fmt.Errorf("zerbDir cannot be empty")
+	}
+
+	path := Path(zerbDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("create logs directory: %w", err)
+	}
+
+	parts := append([]string{clk.Now().UTC().Format(time.RFC3339), event}, fields...)
+	for i, part := range parts {
+		parts[i] = strings.NewReplacer("\n", " ", "\r", " ").Replace(part)
+	}
+
+	// 0600: the log names files and versions on this machine
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	if _, err := fmt.Fprintln(f, strings.Join(parts, " ")); err != nil {
+		f.Close()
+		return fmt.Errorf("write audit log: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close audit log: %w", err)
+	}
+	return nil
+}
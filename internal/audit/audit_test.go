@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
+)
+
+func TestRecord_Appends(t *testing.T) {
+	zerbDir := t.TempDir()
+	clk := clock.NewFake(time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC))
+
+	if err := Record(zerbDir, clk, EventInsecureSkipVerify, "mise", "2024.12.7"); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	clk.Advance(time.Minute)
+	if err := Record(zerbDir, clk, "test-event", "multi\nline"); err != nil {
+		t.Fatalf("second Record() error = %v", err)
+	}
+
+	content, err := os.ReadFile(Path(zerbDir))
+	if err != nil {
+		t.Fatalf("failed to read audit log: %v", err)
+	}
+	want := "2025-01-15T10:30:00Z insecure-skip-verify mise 2024.12.7\n" +
+		"2025-01-15T10:31:00Z test-event multi line\n"
+	if string(content) != want {
+		t.Errorf("audit log =\n%s\nwant:\n%s", content, want)
+	}
+
+	info, err := os.Stat(Path(zerbDir))
+	if err != nil {
+		t.Fatalf("failed to stat audit log: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("audit log mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestRecord_EmptyDir(t *testing.T) {
+	if err := Record("", clock.Real{}, "test-event"); err == nil {
+		t.Error("Record() with empty zerbDir should fail")
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !zerbdev
+
+package binary
+
+// devBuild is false in release builds, which ignore EnvInsecureSkipVerify
+const devBuild = false
@@ -0,0 +1,7 @@
+//go:build zerbdev
+
+package binary
+
+// devBuild is true in development builds (go build -tags zerbdev), which
+// honor EnvInsecureSkipVerify
+const devBuild = true
@@ -7,9 +7,21 @@
 //   - Downloaded only from official GitHub releases
 //   - Verified using GPG signatures (preferred) or SHA256 checksums (fallback)
 //   - Never installed without successful verification
+//   - Quarantined under cache/quarantine when verification fails, with a
+//     .reason file recording the method and error (Manager.ClearQuarantine
+//     removes them)
 //
 // # Verification Strategy
 //
+// 0. Keyless Cosign Bundles (Library Only, Off by Default)
+//   - Used when a release publishes a cosign bundle and Manager.WithSigstore
+//     pins the signer identity for the binary
+//   - Checks the Rekor log entry, the Fulcio certificate chain at log time,
+//     the signer identity and the signature; a bad bundle is never skipped
+//   - The CLI never enables it: the pinned releases publish no bundle
+//     (DownloadInfo.BundleURL is empty), so it is an opt-in for callers
+//     that set a bundle URL and their own trust roots
+//
 // 1. GPG Signature Verification (Preferred)
 //   - Downloads .sig or .asc signature file
 //   - Verifies using embedded GPG public keys
@@ -20,6 +32,46 @@
 //   - Verifies file integrity only (not authenticity)
 //   - Used when GPG verification unavailable or fails
 //
+// # Development Builds
+//
+// ZERB_INSECURE_SKIP_VERIFY=1 skips verification, for hacking on ZERB
+// against local builds. It only takes effect in binaries built with
+// -tags zerbdev; release builds ignore it. Every skipped verification
+// prints a warning and is recorded in logs/audit.log.
+//
+// # Versions
+//
+// DefaultVersions are the tested versions, but any published version can
+// be pinned with DownloadOptions.Version; it drives the download URLs,
+// checksum lookup and verification alike. Versions are validated against
+// each binary's release format (2024.12.7 for mise, 2.46.1 for chezmoi),
+// a version with no release fails with ErrAssetNotFound, and
+// Manager.InstalledVersion asks an installed binary for its version.
+//
+// # Offline Installs
+//
+// DownloadOptions.LocalDir installs from release files already downloaded
+// to a directory (e.g. an airgapped machine's USB bundle), named as in the
+// release. Nothing is fetched, missing files are listed up front, and the
+// copies are verified exactly like downloads.
+//
+// # Integrity
+//
+// Install and Update record the SHA256 of each installed binary in
+// cache/versions/<binary>.integrity.json. QuickVerify recomputes the hash
+// without network access and re-verifies the recorded release only when
+// it differs; a binary still differing from its release is reported as a
+// *TamperedError. Binaries without a record are verified, without running
+// them, against the release of the version set by Manager.WithVersions
+// before they are recorded; a release that cannot be fetched or verified
+// is reported as an *UnverifiedError.
+//
+// # Logging
+//
+// Manager.WithLogger reports key steps (download start and finish, the
+// verification method used, installs, updates and rollbacks) to a Logger
+// with the same methods as config.Logger, e.g. a *slog.Logger.
+//
 // # Usage
 //
 //	// Create a manager
@@ -33,6 +85,11 @@
 //	    return err
 //	}
 //
+//	// Self-test: check the extracted keys still match the embedded ones
+//	if err := mgr.VerifyKeyrings(); err != nil {
+//	    return err // wraps ErrKeyringMismatch for a corrupted key
+//	}
+//
 //	// Download and install mise
 //	err = mgr.Install(ctx, binary.DownloadOptions{
 //	    Binary:  binary.BinaryMise,
@@ -42,9 +99,11 @@
 // # Architecture
 //
 // The package is organized into several components:
-//   - Manager: High-level orchestration of download, verify, install
-//   - Downloader: HTTP download with retry logic and caching
-//   - Verifier: GPG and SHA256 verification
-//   - Extractor: Archive extraction (tar.gz)
+//   - Manager: High-level orchestration of download, verify, install; the
+//     archive and its verification files are fetched concurrently
+//   - Downloader: HTTP download with retry logic, resumable range requests,
+//     progress reporting and caching
+//   - Verifier: Cosign bundle, GPG and SHA256 verification
+//   - Extractor: Archive extraction (tar.gz, tar.xz and zip)
 //   - Platform: Platform-specific URL construction
 package binary
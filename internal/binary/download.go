@@ -2,12 +2,17 @@ package binary
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
 )
 
 const (
@@ -19,12 +24,17 @@ const (
 	DefaultUserAgent = "ZERB/1.0"
 )
 
+// ErrAssetNotFound is returned when a download URL does not exist (HTTP
+// 404), e.g. for a version that was never released. It is not retried.
+var ErrAssetNotFound = errors.New("release asset not found")
+
 // Downloader handles HTTP downloads with retry logic
 type Downloader struct {
 	client    *http.Client
 	cacheDir  string
 	userAgent string
 	retries   int
+	clock     clock.Clock // Time source for retry backoff
 }
 
 // NewDownloader creates a new downloader
@@ -43,11 +53,29 @@ func NewDownloader(cacheDir string) *Downloader {
 		cacheDir:  cacheDir,
 		userAgent: DefaultUserAgent,
 		retries:   DefaultRetries,
+		clock:     clock.Real{},
 	}
 }
 
-// DownloadToFile downloads a URL to a specific file path
-func (d *Downloader) DownloadToFile(ctx context.Context, url, destPath string) error {
+// WithClock sets the clock used for retry backoff and returns the downloader.
+func (d *Downloader) WithClock(clk clock.Clock) *Downloader {
+	if clk == nil {
+		clk = clock.Real{}
+	}
+	d.clock = clk
+	return d
+}
+
+// ProgressFunc receives download progress: the bytes written so far and the
+// total size, or -1 if the server did not report it. It is called from the
+// downloading goroutine as data arrives.
+type ProgressFunc func(downloaded, total int64)
+
+// DownloadToFile downloads a URL to a specific file path. Data is written
+// to destPath+".part" and renamed into place on success; a failed attempt
+// keeps the partial file and the retry resumes it with a range request.
+// progress may be nil.
+func (d *Downloader) DownloadToFile(ctx context.Context, url, destPath string, progress ProgressFunc) error {
 	var lastErr error
 
 	for attempt := 0; attempt <= d.retries; attempt++ {
@@ -60,13 +88,13 @@ func (d *Downloader) DownloadToFile(ctx context.Context, url, destPath string) e
 			// Exponential backoff: 1s, 2s, 4s
 			backoff := time.Duration(1<<uint(attempt-1)) * time.Second
 			select {
-			case <-time.After(backoff):
+			case <-d.clock.After(backoff):
 			case <-ctx.Done():
 				return ctx.Err()
 			}
 		}
 
-		err := d.downloadOnce(ctx, url, destPath)
+		err := d.downloadOnce(ctx, url, destPath, progress)
 		if err == nil {
 			return nil
 		}
@@ -77,13 +105,29 @@ func (d *Downloader) DownloadToFile(ctx context.Context, url, destPath string) e
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
+		if errors.Is(err, ErrAssetNotFound) {
+			return err
+		}
 	}
 
 	return fmt.Errorf("download failed after %d retries: %w", d.retries, lastErr)
 }
 
-// downloadOnce performs a single download attempt
-func (d *Downloader) downloadOnce(ctx context.Context, url, destPath string) error {
+// partSuffix marks a partially downloaded file. It is kept when a download
+// fails so the next attempt can resume it with a range request.
+const partSuffix = ".part"
+
+// downloadOnce performs a single download attempt, resuming a partial
+// download left by an earlier attempt if the server supports range requests
+func (d *Downloader) downloadOnce(ctx context.Context, url, destPath string, progress ProgressFunc) error {
+	partPath := destPath + partSuffix
+
+	// Resume from the end of an earlier partial download
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil && info.Mode().IsRegular() {
+		offset = info.Size()
+	}
+
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
@@ -91,6 +135,9 @@ func (d *Downloader) downloadOnce(ctx context.Context, url, destPath string) err
 	}
 
 	req.Header.Set("User-Agent", d.userAgent)
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
 
 	// Execute request
 	resp, err := d.client.Do(req)
@@ -99,8 +146,27 @@ func (d *Downloader) downloadOnce(ctx context.Context, url, destPath string) err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	// Check status code
-	if resp.StatusCode != http.StatusOK {
+	// Check status code: 206 continues the partial file, 200 replaces it
+	// (the server ignored the range)
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		if start, ok := contentRangeStart(resp.Header.Get("Content-Range")); !ok || start != offset {
+			// Not the range we asked for; start over on the next attempt
+			_ = os.Remove(partPath)
+			return fmt.Errorf("unexpected content range %q for resume at byte %d", resp.Header.Get("Content-Range"), offset)
+		}
+		flags = os.O_WRONLY | os.O_APPEND
+	case resp.StatusCode == http.StatusOK:
+		offset = 0
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// The partial file is not a prefix of this URL; start over
+		_ = os.Remove(partPath)
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	case resp.StatusCode == http.StatusNotFound:
+		_ = os.Remove(partPath)
+		return fmt.Errorf("%w: %s", ErrAssetNotFound, url)
+	default:
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
@@ -110,45 +176,78 @@ func (d *Downloader) downloadOnce(ctx context.Context, url, destPath string) err
 		return fmt.Errorf("create dest dir: %w", err)
 	}
 
-	// Create temporary file
-	tmpPath := destPath + ".tmp"
-	tmpFile, err := os.Create(tmpPath)
+	partFile, err := os.OpenFile(partPath, flags, 0644)
 	if err != nil {
-		return fmt.Errorf("create temp file: %w", err)
+		return fmt.Errorf("open partial file: %w", err)
 	}
 
-	// Track whether we need to clean up the temp file
-	cleanupNeeded := true
-	defer func() {
-		_ = tmpFile.Close()
-		if cleanupNeeded {
-			_ = os.Remove(tmpPath) // Clean up on error
+	// Copy response body to file. On failure the partial file is kept
+	// for the next attempt to resume.
+	var dst io.Writer = partFile
+	if progress != nil {
+		total := int64(-1)
+		if resp.ContentLength >= 0 {
+			total = offset + resp.ContentLength
 		}
-	}()
-
-	// Copy response body to file
-	_, err = io.Copy(tmpFile, resp.Body)
-	if err != nil {
+		progress(offset, total)
+		dst = &progressWriter{w: partFile, written: offset, total: total, progress: progress}
+	}
+	if _, err := io.Copy(dst, resp.Body); err != nil {
+		_ = partFile.Close()
 		return fmt.Errorf("copy response body: %w", err)
 	}
 
-	// Close temp file before rename
-	if err := tmpFile.Close(); err != nil {
-		return fmt.Errorf("close temp file: %w", err)
+	// Close partial file before rename
+	if err := partFile.Close(); err != nil {
+		return fmt.Errorf("close partial file: %w", err)
 	}
 
 	// Atomic rename
-	if err := os.Rename(tmpPath, destPath); err != nil {
-		return fmt.Errorf("rename temp file: %w", err)
+	if err := os.Rename(partPath, destPath); err != nil {
+		return fmt.Errorf("rename partial file: %w", err)
 	}
 
-	// Success - don't clean up the temp file (it's been renamed)
-	cleanupNeeded = false
 	return nil
 }
 
-// DownloadBinary downloads a binary archive to the cache directory
-func (d *Downloader) DownloadBinary(ctx context.Context, info *DownloadInfo) (string, error) {
+// progressWriter reports the running byte count after each write
+type progressWriter struct {
+	w        io.Writer
+	written  int64
+	total    int64
+	progress ProgressFunc
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	if n > 0 {
+		p.written += int64(n)
+		p.progress(p.written, p.total)
+	}
+	return n, err
+}
+
+// contentRangeStart returns the first byte position of a Content-Range
+// header such as "bytes 100-199/200"
+func contentRangeStart(header string) (int64, bool) {
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return 0, false
+	}
+	first, _, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return start, true
+}
+
+// DownloadBinary downloads a binary archive to the cache directory,
+// reporting progress to progress if it is not nil
+func (d *Downloader) DownloadBinary(ctx context.Context, info *DownloadInfo, progress ProgressFunc) (string, error) {
 	if info == nil {
 		return "", fmt.Errorf("download info is nil")
 	}
@@ -163,7 +262,7 @@ func (d *Downloader) DownloadBinary(ctx context.Context, info *DownloadInfo) (st
 	}
 
 	// Download to cache
-	if err := d.DownloadToFile(ctx, info.URL, cachePath); err != nil {
+	if err := d.DownloadToFile(ctx, info.URL, cachePath, progress); err != nil {
 		return "", fmt.Errorf("download binary: %w", err)
 	}
 
@@ -186,7 +285,7 @@ func (d *Downloader) DownloadSignature(ctx context.Context, info *DownloadInfo)
 	}
 
 	// Download signature
-	if err := d.DownloadToFile(ctx, info.SignatureURL, cachePath); err != nil {
+	if err := d.DownloadToFile(ctx, info.SignatureURL, cachePath, nil); err != nil {
 		return "", fmt.Errorf("download signature: %w", err)
 	}
 
@@ -209,7 +308,7 @@ func (d *Downloader) DownloadChecksums(ctx context.Context, info *DownloadInfo)
 	}
 
 	// Download checksums
-	if err := d.DownloadToFile(ctx, info.ChecksumURL, cachePath); err != nil {
+	if err := d.DownloadToFile(ctx, info.ChecksumURL, cachePath, nil); err != nil {
 		return "", fmt.Errorf("download checksums: %w", err)
 	}
 
@@ -232,7 +331,7 @@ func (d *Downloader) DownloadBundle(ctx context.Context, info *DownloadInfo) (st
 	}
 
 	// Download bundle
-	if err := d.DownloadToFile(ctx, info.BundleURL, cachePath); err != nil {
+	if err := d.DownloadToFile(ctx, info.BundleURL, cachePath, nil); err != nil {
 		return "", fmt.Errorf("download bundle: %w", err)
 	}
 
@@ -10,6 +10,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
 )
 
 func TestDownloaderDownloadToFile(t *testing.T) {
@@ -63,7 +65,7 @@ func TestDownloaderDownloadToFile(t *testing.T) {
 
 			// Download to temp file
 			destPath := filepath.Join(tmpDir, "test-file")
-			err := downloader.DownloadToFile(context.Background(), server.URL, destPath)
+			err := downloader.DownloadToFile(context.Background(), server.URL, destPath, nil)
 
 			if tt.wantErr {
 				if err == nil {
@@ -107,11 +109,12 @@ func TestDownloaderRetryLogic(t *testing.T) {
 	defer server.Close()
 
 	tmpDir := t.TempDir()
-	downloader := NewDownloader(tmpDir)
+	clk := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	downloader := NewDownloader(tmpDir).WithClock(clk)
 	downloader.retries = 3
 
 	destPath := filepath.Join(tmpDir, "test-file")
-	err := downloader.DownloadToFile(context.Background(), server.URL, destPath)
+	err := downloader.DownloadToFile(context.Background(), server.URL, destPath, nil)
 
 	if err != nil {
 		t.Fatalf("expected success after retries, got error: %v", err)
@@ -121,12 +124,180 @@ func TestDownloaderRetryLogic(t *testing.T) {
 		t.Errorf("expected 3 attempts, got %d", attempts)
 	}
 
+	// Exponential backoff between attempts, without real sleeping
+	sleeps := clk.Sleeps()
+	if len(sleeps) != 2 || sleeps[0] != time.Second || sleeps[1] != 2*time.Second {
+		t.Errorf("backoff = %v, want [1s 2s]", sleeps)
+	}
+
 	content, _ := os.ReadFile(destPath)
 	if string(content) != "success" {
 		t.Errorf("unexpected content: %s", string(content))
 	}
 }
 
+func TestDownloaderResumesPartialDownload(t *testing.T) {
+	const body = "0123456789abcdefghij"
+
+	tests := []struct {
+		name        string
+		honorRange  bool
+		wantRanges  []string
+		wantContent string
+	}{
+		{
+			name:        "server honors range",
+			honorRange:  true,
+			wantRanges:  []string{"", "bytes=8-"},
+			wantContent: body,
+		},
+		{
+			name:        "server ignores range",
+			honorRange:  false,
+			wantRanges:  []string{"", "bytes=8-"},
+			wantContent: body,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ranges []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ranges = append(ranges, r.Header.Get("Range"))
+				if len(ranges) == 1 {
+					// Promise the whole body but drop the connection after 8 bytes
+					w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write([]byte(body[:8]))
+					return
+				}
+				if tt.honorRange && r.Header.Get("Range") == "bytes=8-" {
+					w.Header().Set("Content-Range", fmt.Sprintf("bytes 8-%d/%d", len(body)-1, len(body)))
+					w.WriteHeader(http.StatusPartialContent)
+					_, _ = w.Write([]byte(body[8:]))
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(body))
+			}))
+			defer server.Close()
+
+			tmpDir := t.TempDir()
+			downloader := NewDownloader(tmpDir).WithClock(clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+			downloader.retries = 2
+
+			destPath := filepath.Join(tmpDir, "test-file")
+			if err := downloader.DownloadToFile(context.Background(), server.URL, destPath, nil); err != nil {
+				t.Fatalf("DownloadToFile() error = %v", err)
+			}
+
+			if fmt.Sprint(ranges) != fmt.Sprint(tt.wantRanges) {
+				t.Errorf("Range headers = %q, want %q", ranges, tt.wantRanges)
+			}
+			content, err := os.ReadFile(destPath)
+			if err != nil {
+				t.Fatalf("failed to read downloaded file: %v", err)
+			}
+			if string(content) != tt.wantContent {
+				t.Errorf("content = %q, want %q", content, tt.wantContent)
+			}
+			if _, err := os.Stat(destPath + partSuffix); !os.IsNotExist(err) {
+				t.Errorf("partial file should be gone after success, stat error = %v", err)
+			}
+		})
+	}
+}
+
+func TestDownloaderReportsProgress(t *testing.T) {
+	chunk := strings.Repeat("x", 1024)
+	const chunks = 8
+
+	tests := []struct {
+		name          string
+		contentLength bool
+		wantTotal     int64
+	}{
+		{name: "known length", contentLength: true, wantTotal: chunks * 1024},
+		{name: "unknown length", contentLength: false, wantTotal: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.contentLength {
+					w.Header().Set("Content-Length", fmt.Sprint(chunks*len(chunk)))
+				}
+				w.WriteHeader(http.StatusOK)
+				for i := 0; i < chunks; i++ {
+					_, _ = w.Write([]byte(chunk))
+					w.(http.Flusher).Flush()
+				}
+			}))
+			defer server.Close()
+
+			tmpDir := t.TempDir()
+			downloader := NewDownloader(tmpDir)
+
+			var downloaded []int64
+			progress := func(n, total int64) {
+				if total != tt.wantTotal {
+					t.Errorf("total = %d, want %d", total, tt.wantTotal)
+				}
+				downloaded = append(downloaded, n)
+			}
+
+			destPath := filepath.Join(tmpDir, "test-file")
+			if err := downloader.DownloadToFile(context.Background(), server.URL, destPath, progress); err != nil {
+				t.Fatalf("DownloadToFile() error = %v", err)
+			}
+
+			if len(downloaded) < 2 {
+				t.Fatalf("progress called %d times, want several", len(downloaded))
+			}
+			for i := 1; i < len(downloaded); i++ {
+				if downloaded[i] <= downloaded[i-1] {
+					t.Errorf("progress not increasing: %v", downloaded)
+					break
+				}
+			}
+			if last := downloaded[len(downloaded)-1]; last != chunks*int64(len(chunk)) {
+				t.Errorf("final progress = %d, want %d", last, chunks*len(chunk))
+			}
+		})
+	}
+}
+
+func TestDownloaderRestartsOnMismatchedRange(t *testing.T) {
+	const body = "0123456789"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			// Partial file is longer than the asset: not a prefix of it
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	destPath := filepath.Join(tmpDir, "test-file")
+	if err := os.WriteFile(destPath+partSuffix, []byte("stale partial content from another asset"), 0644); err != nil {
+		t.Fatalf("failed to write partial file: %v", err)
+	}
+
+	downloader := NewDownloader(tmpDir).WithClock(clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+	downloader.retries = 2
+	if err := downloader.DownloadToFile(context.Background(), server.URL, destPath, nil); err != nil {
+		t.Fatalf("DownloadToFile() error = %v", err)
+	}
+
+	content, _ := os.ReadFile(destPath)
+	if string(content) != body {
+		t.Errorf("content = %q, want %q", content, body)
+	}
+}
+
 func TestDownloaderContextCancellation(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Simulate slow response
@@ -145,7 +316,7 @@ func TestDownloaderContextCancellation(t *testing.T) {
 	defer cancel()
 
 	destPath := filepath.Join(tmpDir, "test-file")
-	err := downloader.DownloadToFile(ctx, server.URL, destPath)
+	err := downloader.DownloadToFile(ctx, server.URL, destPath, nil)
 
 	if err == nil {
 		t.Error("expected context cancellation error")
@@ -177,7 +348,7 @@ func TestDownloaderDownloadBinary(t *testing.T) {
 	}
 
 	// First download
-	cachePath1, err := downloader.DownloadBinary(context.Background(), info)
+	cachePath1, err := downloader.DownloadBinary(context.Background(), info, nil)
 	if err != nil {
 		t.Fatalf("first download failed: %v", err)
 	}
@@ -204,7 +375,7 @@ func TestDownloaderDownloadBinary(t *testing.T) {
 		t.Error("unexpected HTTP request - should use cache")
 	})
 
-	cachePath2, err := downloader.DownloadBinary(context.Background(), info)
+	cachePath2, err := downloader.DownloadBinary(context.Background(), info, nil)
 	if err != nil {
 		t.Fatalf("second download failed: %v", err)
 	}
@@ -312,7 +483,7 @@ func TestDownloaderCreatesNestedDirectories(t *testing.T) {
 
 	// Download to deeply nested path
 	deepPath := filepath.Join(tmpDir, "a", "b", "c", "d", "file.txt")
-	err := downloader.DownloadToFile(context.Background(), server.URL, deepPath)
+	err := downloader.DownloadToFile(context.Background(), server.URL, deepPath, nil)
 
 	if err != nil {
 		t.Fatalf("download failed: %v", err)
@@ -405,7 +576,7 @@ func TestDownloaderRedirectHandling(t *testing.T) {
 	downloader := NewDownloader(tmpDir)
 
 	destPath := filepath.Join(tmpDir, "redirected-file")
-	err := downloader.DownloadToFile(context.Background(), server.URL, destPath)
+	err := downloader.DownloadToFile(context.Background(), server.URL, destPath, nil)
 
 	if err != nil {
 		t.Fatalf("download with redirects failed: %v", err)
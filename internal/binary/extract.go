@@ -2,12 +2,17 @@ package binary
 
 import (
 	"archive/tar"
+	"archive/zip"
+	"bytes"
 	"compress/gzip"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+
+	"github.com/ulikunitz/xz"
 )
 
 // Extractor handles archive extraction
@@ -18,8 +23,88 @@ func NewExtractor() *Extractor {
 	return &Extractor{}
 }
 
+// archiveFormat is a supported archive container
+type archiveFormat int
+
+const (
+	formatTarGz archiveFormat = iota
+	formatTarXz
+	formatZip
+)
+
+// Magic bytes at the start of each supported archive format
+var (
+	magicGzip = []byte{0x1f, 0x8b}
+	magicXz   = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	magicZip  = []byte{'P', 'K', 0x03, 0x04}
+	// An empty zip archive starts with the end of central directory record
+	magicZipEmpty = []byte{'P', 'K', 0x05, 0x06}
+)
+
+// detectArchiveFormat detects the format of an archive from its magic
+// bytes, so a misnamed download is still read correctly. The extension is
+// only used to describe an unsupported file.
+func detectArchiveFormat(archivePath string) (archiveFormat, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return 0, fmt.Errorf("open archive: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	header := make([]byte, len(magicXz))
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return 0, fmt.Errorf("read archive header: %w", err)
+	}
+	header = header[:n]
+
+	switch {
+	case bytes.HasPrefix(header, magicGzip):
+		return formatTarGz, nil
+	case bytes.HasPrefix(header, magicXz):
+		return formatTarXz, nil
+	case bytes.HasPrefix(header, magicZip), bytes.HasPrefix(header, magicZipEmpty):
+		return formatZip, nil
+	}
+
+	return 0, fmt.Errorf("unsupported archive format for %s (supported: .tar.gz, .tar.xz, .zip)", filepath.Base(archivePath))
+}
+
+// Extract extracts a .tar.gz, .tar.xz or .zip archive to a destination
+// directory, detecting the format from the file contents
+func (e *Extractor) Extract(archivePath, destDir string) error {
+	format, err := detectArchiveFormat(archivePath)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case formatTarXz:
+		return e.ExtractTarXz(archivePath, destDir)
+	case formatZip:
+		return e.ExtractZip(archivePath, destDir)
+	default:
+		return e.ExtractTarGz(archivePath, destDir)
+	}
+}
+
 // ExtractTarGz extracts a .tar.gz archive to a destination directory
 func (e *Extractor) ExtractTarGz(archivePath, destDir string) error {
+	return withTarReader(archivePath, formatTarGz, func(tarReader *tar.Reader) error {
+		return extractTar(tarReader, destDir)
+	})
+}
+
+// ExtractTarXz extracts a .tar.xz archive to a destination directory
+func (e *Extractor) ExtractTarXz(archivePath, destDir string) error {
+	return withTarReader(archivePath, formatTarXz, func(tarReader *tar.Reader) error {
+		return extractTar(tarReader, destDir)
+	})
+}
+
+// withTarReader opens a compressed tar archive and passes its tar reader
+// to fn
+func withTarReader(archivePath string, format archiveFormat, fn func(*tar.Reader) error) error {
 	// Open archive file
 	archiveFile, err := os.Open(archivePath)
 	if err != nil {
@@ -27,16 +112,29 @@ func (e *Extractor) ExtractTarGz(archivePath, destDir string) error {
 	}
 	defer func() { _ = archiveFile.Close() }()
 
-	// Create gzip reader
-	gzipReader, err := gzip.NewReader(archiveFile)
-	if err != nil {
-		return fmt.Errorf("create gzip reader: %w", err)
+	// Create decompressing reader
+	var r io.Reader
+	switch format {
+	case formatTarXz:
+		xzReader, err := xz.NewReader(archiveFile)
+		if err != nil {
+			return fmt.Errorf("create xz reader: %w", err)
+		}
+		r = xzReader
+	default:
+		gzipReader, err := gzip.NewReader(archiveFile)
+		if err != nil {
+			return fmt.Errorf("create gzip reader: %w", err)
+		}
+		defer func() { _ = gzipReader.Close() }()
+		r = gzipReader
 	}
-	defer func() { _ = gzipReader.Close() }()
 
-	// Create tar reader
-	tarReader := tar.NewReader(gzipReader)
+	return fn(tar.NewReader(r))
+}
 
+// extractTar extracts every entry of a tar archive to destDir
+func extractTar(tarReader *tar.Reader, destDir string) error {
 	// Create destination directory
 	if err := os.MkdirAll(destDir, 0750); err != nil {
 		return fmt.Errorf("create dest dir: %w", err)
@@ -70,47 +168,72 @@ func (e *Extractor) ExtractTarGz(archivePath, destDir string) error {
 			}
 
 		case tar.TypeReg:
-			// Create parent directory if needed
-			if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
-				return fmt.Errorf("create parent dir for %s: %w", target, err)
+			if err := writeExtractedFile(target, tarReader, header.Size, os.FileMode(header.Mode)); err != nil {
+				return err
 			}
 
-			// Create file
-			outFile, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode))
-			if err != nil {
-				return fmt.Errorf("create file %s: %w", target, err)
+		case tar.TypeSymlink:
+			if err := createExtractedSymlink(target, header.Name, header.Linkname, destDir); err != nil {
+				return err
 			}
 
-			// Copy file contents with size limit to prevent decompression bombs
-			lr := &io.LimitedReader{R: tarReader, N: header.Size}
-			if _, err := io.Copy(outFile, lr); err != nil {
-				_ = outFile.Close()
-				_ = os.Remove(target) // Clean up partial file on error
-				return fmt.Errorf("write file %s: %w", target, err)
-			}
+		default:
+			// Skip other types (char devices, block devices, etc.)
+			continue
+		}
+	}
 
-			if err := outFile.Close(); err != nil {
-				return fmt.Errorf("close file %s: %w", target, err)
-			}
+	return nil
+}
 
-		case tar.TypeSymlink:
-			// Validate symlink target doesn't escape destDir
-			if err := validateSymlinkTarget(target, header.Linkname, destDir); err != nil {
-				return fmt.Errorf("illegal symlink %s -> %s: %w", header.Name, header.Linkname, err)
+// ExtractZip extracts a .zip archive to a destination directory, with the
+// same path traversal (zip-slip) and symlink escape protections as tar
+// archives
+func (e *Extractor) ExtractZip(archivePath, destDir string) error {
+	zipReader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("open zip archive: %w", err)
+	}
+	defer func() { _ = zipReader.Close() }()
+
+	// Create destination directory
+	if err := os.MkdirAll(destDir, 0750); err != nil {
+		return fmt.Errorf("create dest dir: %w", err)
+	}
+
+	for _, file := range zipReader.File {
+		// Construct target path
+		target := filepath.Join(destDir, file.Name)
+
+		// Security check: prevent path traversal
+		if err := validateExtractPath(target, destDir); err != nil {
+			return fmt.Errorf("illegal file path %s: %w", file.Name, err)
+		}
+
+		mode := file.Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.MkdirAll(target, 0750); err != nil {
+				return fmt.Errorf("create directory %s: %w", target, err)
 			}
 
-			// Create parent directory if needed
-			if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
-				return fmt.Errorf("create parent dir for symlink %s: %w", target, err)
+		case mode&os.ModeSymlink != 0:
+			// The entry's contents are the link target
+			linkname, err := readZipSymlink(file)
+			if err != nil {
+				return err
+			}
+			if err := createExtractedSymlink(target, file.Name, linkname, destDir); err != nil {
+				return err
 			}
 
-			// Create symlink
-			if err := os.Symlink(header.Linkname, target); err != nil {
-				return fmt.Errorf("create symlink %s: %w", target, err)
+		case mode.IsRegular():
+			if err := extractZipFile(file, target, mode.Perm()); err != nil {
+				return err
 			}
 
 		default:
-			// Skip other types (char devices, block devices, etc.)
+			// Skip other types (devices, named pipes, etc.)
 			continue
 		}
 	}
@@ -118,26 +241,103 @@ func (e *Extractor) ExtractTarGz(archivePath, destDir string) error {
 	return nil
 }
 
-// ExtractBinary extracts a specific binary file from a tar.gz archive
-// This is optimized for extracting just the binary we need
-func (e *Extractor) ExtractBinary(archivePath, destPath, binaryName string) error {
-	// Open archive file
-	archiveFile, err := os.Open(archivePath)
+// extractZipFile writes one regular zip entry to target
+func extractZipFile(file *zip.File, target string, perm os.FileMode) error {
+	rc, err := file.Open()
 	if err != nil {
-		return fmt.Errorf("open archive: %w", err)
+		return fmt.Errorf("open %s: %w", file.Name, err)
 	}
-	defer func() { _ = archiveFile.Close() }()
+	defer func() { _ = rc.Close() }()
+
+	// The declared size bounds the copy to prevent decompression bombs
+	return writeExtractedFile(target, rc, int64(file.UncompressedSize64), perm)
+}
+
+// maxSymlinkTarget bounds the link target read from a zip entry
+const maxSymlinkTarget = 4096
 
-	// Create gzip reader
-	gzipReader, err := gzip.NewReader(archiveFile)
+// readZipSymlink returns the link target stored in a zip symlink entry
+func readZipSymlink(file *zip.File) (string, error) {
+	rc, err := file.Open()
 	if err != nil {
-		return fmt.Errorf("create gzip reader: %w", err)
+		return "", fmt.Errorf("open %s: %w", file.Name, err)
 	}
-	defer func() { _ = gzipReader.Close() }()
+	defer func() { _ = rc.Close() }()
 
-	// Create tar reader
-	tarReader := tar.NewReader(gzipReader)
+	linkname, err := io.ReadAll(io.LimitReader(rc, maxSymlinkTarget))
+	if err != nil {
+		return "", fmt.Errorf("read symlink %s: %w", file.Name, err)
+	}
+	return string(linkname), nil
+}
+
+// writeExtractedFile writes at most size bytes from r to target, creating
+// its parent directory. A partial file is removed on error.
+func writeExtractedFile(target string, r io.Reader, size int64, perm os.FileMode) error {
+	// Create parent directory if needed
+	if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+		return fmt.Errorf("create parent dir for %s: %w", target, err)
+	}
+
+	// Create file
+	outFile, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("create file %s: %w", target, err)
+	}
+
+	// Copy file contents with size limit to prevent decompression bombs
+	lr := &io.LimitedReader{R: r, N: size}
+	if _, err := io.Copy(outFile, lr); err != nil {
+		_ = outFile.Close()
+		_ = os.Remove(target) // Clean up partial file on error
+		return fmt.Errorf("write file %s: %w", target, err)
+	}
+
+	if err := outFile.Close(); err != nil {
+		return fmt.Errorf("close file %s: %w", target, err)
+	}
+	return nil
+}
 
+// createExtractedSymlink creates the symlink target -> linkname after
+// checking that linkname does not escape destDir
+func createExtractedSymlink(target, name, linkname, destDir string) error {
+	// Validate symlink target doesn't escape destDir
+	if err := validateSymlinkTarget(target, linkname, destDir); err != nil {
+		return fmt.Errorf("illegal symlink %s -> %s: %w", name, linkname, err)
+	}
+
+	// Create parent directory if needed
+	if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+		return fmt.Errorf("create parent dir for symlink %s: %w", target, err)
+	}
+
+	// Create symlink
+	if err := os.Symlink(linkname, target); err != nil {
+		return fmt.Errorf("create symlink %s: %w", target, err)
+	}
+	return nil
+}
+
+// ExtractBinary extracts a specific binary file from a .tar.gz, .tar.xz or
+// .zip archive. This is optimized for extracting just the binary we need
+func (e *Extractor) ExtractBinary(archivePath, destPath, binaryName string) error {
+	format, err := detectArchiveFormat(archivePath)
+	if err != nil {
+		return err
+	}
+
+	if format == formatZip {
+		return extractZipBinary(archivePath, destPath, binaryName)
+	}
+	return withTarReader(archivePath, format, func(tarReader *tar.Reader) error {
+		return extractTarBinary(tarReader, destPath, binaryName)
+	})
+}
+
+// extractTarBinary writes the regular file named binaryName in a tar
+// archive to destPath
+func extractTarBinary(tarReader *tar.Reader, destPath, binaryName string) error {
 	// Search for binary
 	for {
 		header, err := tarReader.Next()
@@ -150,32 +350,29 @@ func (e *Extractor) ExtractBinary(archivePath, destPath, binaryName string) erro
 
 		// Check if this is the binary we want
 		if header.Typeflag == tar.TypeReg && filepath.Base(header.Name) == binaryName {
-			// Create parent directory if needed
-			destDir := filepath.Dir(destPath)
-			if err := os.MkdirAll(destDir, 0750); err != nil {
-				return fmt.Errorf("create dest dir: %w", err)
-			}
-
 			// Create destination file with executable permissions
-			outFile, err := os.OpenFile(destPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
-			if err != nil {
-				return fmt.Errorf("create file: %w", err)
-			}
+			return writeExtractedFile(destPath, tarReader, header.Size, 0755)
+		}
+	}
+}
 
-			// Copy binary contents with size limit
-			lr := &io.LimitedReader{R: tarReader, N: header.Size}
-			if _, err := io.Copy(outFile, lr); err != nil {
-				_ = outFile.Close()
-				_ = os.Remove(destPath) // Clean up partial file on error
-				return fmt.Errorf("write file: %w", err)
-			}
+// extractZipBinary writes the regular file named binaryName in a zip
+// archive to destPath
+func extractZipBinary(archivePath, destPath, binaryName string) error {
+	zipReader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("open zip archive: %w", err)
+	}
+	defer func() { _ = zipReader.Close() }()
 
-			if err := outFile.Close(); err != nil {
-				return fmt.Errorf("close file: %w", err)
-			}
-			return nil
+	for _, file := range zipReader.File {
+		// Zip entry names always use forward slashes
+		if file.Mode().IsRegular() && path.Base(file.Name) == binaryName {
+			// Create destination file with executable permissions
+			return extractZipFile(file, destPath, 0755)
 		}
 	}
+	return fmt.Errorf("binary %s not found in archive", binaryName)
 }
 
 // SetExecutable sets executable permissions on a file
@@ -2,10 +2,14 @@ package binary
 
 import (
 	"archive/tar"
+	"archive/zip"
 	"compress/gzip"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/ulikunitz/xz"
 )
 
 // Helper function to create a test tar.gz archive
@@ -508,3 +512,213 @@ func TestExtractBinary_CreatesNestedDirectories(t *testing.T) {
 		t.Error("binary was not extracted to nested directory")
 	}
 }
+
+// testEntry is a file or symlink in a test archive
+type testEntry struct {
+	name    string
+	content string // file contents, or the link target of a symlink
+	symlink bool
+}
+
+// createTestTarXz creates a .tar.xz archive with the given entries
+func createTestTarXz(t *testing.T, entries []testEntry) string {
+	t.Helper()
+
+	archivePath := filepath.Join(t.TempDir(), "test.tar.xz")
+	archiveFile, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatalf("failed to create archive: %v", err)
+	}
+	defer func() { _ = archiveFile.Close() }()
+
+	xzWriter, err := xz.NewWriter(archiveFile)
+	if err != nil {
+		t.Fatalf("failed to create xz writer: %v", err)
+	}
+	tarWriter := tar.NewWriter(xzWriter)
+
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Mode: 0644, Size: int64(len(entry.content)), Typeflag: tar.TypeReg}
+		if entry.symlink {
+			header = &tar.Header{Name: entry.name, Linkname: entry.content, Typeflag: tar.TypeSymlink}
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatalf("failed to write header for %s: %v", entry.name, err)
+		}
+		if !entry.symlink {
+			if _, err := tarWriter.Write([]byte(entry.content)); err != nil {
+				t.Fatalf("failed to write content for %s: %v", entry.name, err)
+			}
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		t.Fatalf("failed to close tar writer: %v", err)
+	}
+	if err := xzWriter.Close(); err != nil {
+		t.Fatalf("failed to close xz writer: %v", err)
+	}
+	return archivePath
+}
+
+// createTestZip creates a .zip archive with the given entries
+func createTestZip(t *testing.T, name string, entries []testEntry) string {
+	t.Helper()
+
+	archivePath := filepath.Join(t.TempDir(), name)
+	archiveFile, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatalf("failed to create archive: %v", err)
+	}
+	defer func() { _ = archiveFile.Close() }()
+
+	zipWriter := zip.NewWriter(archiveFile)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.name, Method: zip.Deflate}
+		header.SetMode(0644)
+		if entry.symlink {
+			header.SetMode(os.ModeSymlink | 0777)
+		}
+		w, err := zipWriter.CreateHeader(header)
+		if err != nil {
+			t.Fatalf("failed to write header for %s: %v", entry.name, err)
+		}
+		if _, err := w.Write([]byte(entry.content)); err != nil {
+			t.Fatalf("failed to write content for %s: %v", entry.name, err)
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatalf("failed to close zip writer: %v", err)
+	}
+	return archivePath
+}
+
+func TestExtract_Formats(t *testing.T) {
+	entries := []testEntry{
+		{name: "chezmoi_2.46.1/README.md", content: "readme"},
+		{name: "chezmoi_2.46.1/chezmoi", content: "#!/bin/sh\necho chezmoi\n"},
+	}
+
+	tests := []struct {
+		name    string
+		archive func(t *testing.T) string
+	}{
+		{
+			name: "tar.gz",
+			archive: func(t *testing.T) string {
+				return createTestTarGz(t, map[string]string{entries[0].name: entries[0].content, entries[1].name: entries[1].content})
+			},
+		},
+		{
+			name:    "tar.xz",
+			archive: func(t *testing.T) string { return createTestTarXz(t, entries) },
+		},
+		{
+			name:    "zip",
+			archive: func(t *testing.T) string { return createTestZip(t, "test.zip", entries) },
+		},
+		{
+			// The format is detected from the contents, not the name
+			name:    "misnamed zip",
+			archive: func(t *testing.T) string { return createTestZip(t, "test.tar.gz", entries) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archivePath := tt.archive(t)
+			extractor := NewExtractor()
+
+			destDir := filepath.Join(t.TempDir(), "extract")
+			if err := extractor.Extract(archivePath, destDir); err != nil {
+				t.Fatalf("Extract() error = %v", err)
+			}
+			for _, entry := range entries {
+				content, err := os.ReadFile(filepath.Join(destDir, entry.name))
+				if err != nil {
+					t.Fatalf("failed to read %s: %v", entry.name, err)
+				}
+				if string(content) != entry.content {
+					t.Errorf("%s content = %q, want %q", entry.name, content, entry.content)
+				}
+			}
+
+			destPath := filepath.Join(t.TempDir(), "bin", "chezmoi")
+			if err := extractor.ExtractBinary(archivePath, destPath, "chezmoi"); err != nil {
+				t.Fatalf("ExtractBinary() error = %v", err)
+			}
+			content, err := os.ReadFile(destPath)
+			if err != nil {
+				t.Fatalf("failed to read binary: %v", err)
+			}
+			if string(content) != entries[1].content {
+				t.Errorf("binary content = %q, want %q", content, entries[1].content)
+			}
+			info, err := os.Stat(destPath)
+			if err != nil {
+				t.Fatalf("failed to stat binary: %v", err)
+			}
+			if info.Mode().Perm()&0111 == 0 {
+				t.Errorf("binary mode = %v, want executable", info.Mode())
+			}
+
+			if err := extractor.ExtractBinary(archivePath, destPath, "mise"); err == nil || !strings.Contains(err.Error(), "not found") {
+				t.Errorf("ExtractBinary() missing binary error = %v, want not found", err)
+			}
+		})
+	}
+}
+
+func TestExtract_UnsupportedFormat(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "chezmoi.7z")
+	if err := os.WriteFile(archivePath, []byte("not an archive"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	extractor := NewExtractor()
+	if err := extractor.Extract(archivePath, t.TempDir()); err == nil || !strings.Contains(err.Error(), "unsupported archive format") {
+		t.Errorf("Extract() error = %v, want unsupported archive format", err)
+	}
+	if err := extractor.ExtractBinary(archivePath, filepath.Join(t.TempDir(), "chezmoi"), "chezmoi"); err == nil {
+		t.Error("ExtractBinary() should fail for an unsupported format")
+	}
+}
+
+func TestExtract_Traversal(t *testing.T) {
+	tests := []struct {
+		name       string
+		entry      testEntry
+		shouldFail bool
+	}{
+		{name: "file traversal", entry: testEntry{name: "../../../etc/passwd", content: "evil"}, shouldFail: true},
+		{name: "valid file", entry: testEntry{name: "subdir/file.txt", content: "ok"}, shouldFail: false},
+		{name: "symlink escape", entry: testEntry{name: "link", content: "../../../etc/passwd", symlink: true}, shouldFail: true},
+		{name: "absolute symlink", entry: testEntry{name: "link", content: "/etc/passwd", symlink: true}, shouldFail: true},
+		{name: "valid symlink", entry: testEntry{name: "link", content: "subdir/file.txt", symlink: true}, shouldFail: false},
+	}
+
+	formats := []struct {
+		name    string
+		archive func(t *testing.T, entry testEntry) string
+	}{
+		{name: "zip", archive: func(t *testing.T, entry testEntry) string { return createTestZip(t, "test.zip", []testEntry{entry}) }},
+		{name: "tar.xz", archive: func(t *testing.T, entry testEntry) string { return createTestTarXz(t, []testEntry{entry}) }},
+	}
+
+	for _, format := range formats {
+		for _, tt := range tests {
+			t.Run(format.name+"/"+tt.name, func(t *testing.T) {
+				archivePath := format.archive(t, tt.entry)
+				destDir := filepath.Join(t.TempDir(), "extract")
+
+				err := NewExtractor().Extract(archivePath, destDir)
+				if tt.shouldFail && err == nil {
+					t.Error("expected extraction to fail, but it succeeded")
+				}
+				if !tt.shouldFail && err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package binary
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/audit"
+)
+
+// EnvInsecureSkipVerify names the environment variable that skips binary
+// verification when set to 1. It is for developing ZERB against local
+// builds only: it takes effect only in binaries built with -tags zerbdev,
+// and release builds ignore it.
+const EnvInsecureSkipVerify = "ZERB_INSECURE_SKIP_VERIFY"
+
+// insecureSkipWarning is printed in red each time verification is skipped
+const insecureSkipWarning = "\033[1;31m" +
+	"!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!\n" +
+	"!!  WARNING: BINARY VERIFICATION DISABLED (%s=1)\n" +
+	"!!  The %s %s is NOT signature- or checksum-verified.\n" +
+	"!!  Development builds only. Never use this for a real environment.\n" +
+	"!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!\n" +
+	"\033[0m"
+
+// skipVerifyRequested reports whether ZERB_INSECURE_SKIP_VERIFY=1 is set
+func skipVerifyRequested() bool {
+	return os.Getenv(EnvInsecureSkipVerify) == "1"
+}
+
+// skipVerify reports whether verification should be skipped: both the
+// environment variable and a development build are required. A request
+// in a release build is reported and ignored.
+func (m *Manager) skipVerify() bool {
+	if !skipVerifyRequested() {
+		return false
+	}
+	if !m.devBuild {
+		fmt.Fprintf(m.stderr, "Note: %s is ignored in release builds; verifying as usual\n", EnvInsecureSkipVerify)
+		return false
+	}
+	return true
+}
+
+// recordInsecureSkip warns loudly that a binary was not verified and
+// records it in the audit log
+func (m *Manager) recordInsecureSkip(info *DownloadInfo, path string) error {
+	fmt.Fprintf(m.stderr, insecureSkipWarning, EnvInsecureSkipVerify, info.Binary.Label(), info.Version)
+
+	if err := audit.Record(m.zerbDir, m.clock, audit.EventInsecureSkipVerify, info.Binary.String(), info.Version, path); err != nil {
+		return fmt.Errorf("record skipped verification: %w", err)
+	}
+	return nil
+}
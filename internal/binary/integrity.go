@@ -0,0 +1,378 @@
+package binary
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/fsutil"
+)
+
+// integritySuffix names the integrity record of a binary in cache/versions
+const integritySuffix = ".integrity.json"
+
+// IntegrityRecord is the state of an installed binary when it was last
+// verified. It is kept in cache/versions so QuickVerify can check the
+// binary by its hash instead of re-verifying its release signatures.
+type IntegrityRecord struct {
+	// Binary is the binary the record is for (e.g. "mise")
+	Binary string `json:"binary"`
+	// Version is the release the binary was verified as; empty when it
+	// was trusted on first use by an earlier ZERB
+	Version string `json:"version,omitempty"`
+	// SHA256 is the hash of the installed binary
+	SHA256 string `json:"sha256"`
+	// ModTime and Size are those of the installed binary
+	ModTime time.Time `json:"mod_time"`
+	Size    int64     `json:"size"`
+	// Verified is the verification method of the release (see
+	// VerificationMethod.String)
+	Verified string `json:"verified"`
+	// RecordedAt is when the record was written
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// TamperedError reports an installed binary that no longer matches the
+// one verified when it was installed
+type TamperedError struct {
+	Binary Binary
+	// Expected is the recorded hash, empty when the binary had no record,
+	// and Actual the hash of the binary now
+	Expected string
+	Actual   string
+	// Modified is the modification time of the binary now
+	Modified time.Time
+	// Err is why the binary could not be re-verified, if that was tried
+	Err error
+}
+
+func (e *TamperedError) Error() string {
+	if e.Expected == "" {
+		return fmt.Sprintf("the %s has no integrity record and differs from its release: %v", e.Binary.Label(), e.Err) +
+			"\nRefusing to run it. Run 'zerb doctor' to check and repair your installation"
+	}
+	msg := fmt.Sprintf("the %s has changed since it was verified (modified %s)",
+		e.Binary.Label(), e.Modified.Format(time.RFC3339))
+	if e.Err != nil {
+		msg += fmt.Sprintf(" and could not be re-verified: %v", e.Err)
+	}
+	return msg + "\nRefusing to run it. Run 'zerb doctor' to check and repair your installation"
+}
+
+func (e *TamperedError) Unwrap() error { return e.Err }
+
+// UnverifiedError reports an installed binary without an integrity record
+// whose release could not be downloaded or verified to compare it with,
+// e.g. while offline. The binary is not known to be tampered with; it is
+// verified again on the next QuickVerify.
+type UnverifiedError struct {
+	Binary Binary
+	// Version is the release the binary was to be verified against
+	Version string
+	// Err is why the release could not be verified
+	Err error
+}
+
+func (e *UnverifiedError) Error() string {
+	return fmt.Sprintf("the %s has no integrity record and could not be verified against its %s release: %v",
+		e.Binary.Label(), e.Version, e.Err)
+}
+
+func (e *UnverifiedError) Unwrap() error { return e.Err }
+
+// integrityPath returns the path of the integrity record of binary
+func (m *Manager) integrityPath(binary Binary) string {
+	return filepath.Join(m.zerbDir, "cache", "versions", binary.String()+integritySuffix)
+}
+
+// LoadIntegrity returns the integrity record of binary, or (nil, nil) if
+// it has none
+func (m *Manager) LoadIntegrity(binary Binary) (*IntegrityRecord, error) {
+	data, err := os.ReadFile(m.integrityPath(binary))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read integrity record: %w", err)
+	}
+
+	var record IntegrityRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("parse integrity record: %w", err)
+	}
+	if record.Binary != binary.String() || record.SHA256 == "" {
+		return nil, fmt.Errorf("invalid integrity record for %s", binary.Label())
+	}
+	return &record, nil
+}
+
+// recordIntegrity records the installed binary as verified as version by
+// method, replacing any earlier record
+func (m *Manager) recordIntegrity(binary Binary, version string, method VerificationMethod) (*IntegrityRecord, error) {
+	path := m.GetBinaryPath(binary)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("stat binary: %w", err)
+	}
+	sum, err := calculateSHA256(path)
+	if err != nil {
+		return nil, fmt.Errorf("hash binary: %w", err)
+	}
+
+	record := &IntegrityRecord{
+		Binary:     binary.String(),
+		Version:    version,
+		SHA256:     sum,
+		ModTime:    info.ModTime().UTC(),
+		Size:       info.Size(),
+		Verified:   method.String(),
+		RecordedAt: m.clock.Now().UTC(),
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode integrity record: %w", err)
+	}
+
+	recordPath := m.integrityPath(binary)
+	if err := os.MkdirAll(filepath.Dir(recordPath), 0755); err != nil {
+		return nil, fmt.Errorf("create integrity record dir: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(recordPath, append(data, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("write integrity record: %w", err)
+	}
+	return record, nil
+}
+
+// ClearIntegrity removes the integrity record of binary, e.g. before a
+// binary that failed CheckIntegrity is reinstalled
+func (m *Manager) ClearIntegrity(binary Binary) error {
+	if err := os.Remove(m.integrityPath(binary)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove integrity record: %w", err)
+	}
+	return nil
+}
+
+// CheckIntegrity compares the installed binary with its integrity record
+// without any network access. It returns a *TamperedError if the hash
+// differs, and nil if it matches or there is no record to compare with.
+func (m *Manager) CheckIntegrity(binary Binary) error {
+	record, err := m.LoadIntegrity(binary)
+	if err != nil || record == nil {
+		return err
+	}
+	_, err = m.compareIntegrity(binary, record)
+	return err
+}
+
+// compareIntegrity hashes the installed binary and returns the hash, with
+// a *TamperedError if it differs from record
+func (m *Manager) compareIntegrity(binary Binary, record *IntegrityRecord) (string, error) {
+	path := m.GetBinaryPath(binary)
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("stat binary: %w", err)
+	}
+	sum, err := calculateSHA256(path)
+	if err != nil {
+		return "", fmt.Errorf("hash binary: %w", err)
+	}
+	if strings.EqualFold(sum, record.SHA256) {
+		return sum, nil
+	}
+	return sum, &TamperedError{Binary: binary, Expected: record.SHA256, Actual: sum, Modified: info.ModTime()}
+}
+
+// QuickVerify checks that the installed core components are the binaries
+// verified when they were installed. Each binary's SHA256 is recomputed
+// and compared with its integrity record, which is fast enough to run on
+// every startup. Only a binary whose hash differs is fully re-verified:
+// its recorded release is downloaded and verified with its signatures,
+// and the installed binary must be identical to it; it then gets a fresh
+// record. Otherwise it fails with a *TamperedError.
+//
+// A binary without a record (installed before records were kept, or whose
+// record was lost) is never trusted as it is, nor run before it is
+// verified: it is fully verified against the release of the version set
+// by WithVersions, as above, and a note on stderr says so. If that
+// release cannot be downloaded or verified, it fails with an
+// *UnverifiedError instead. Binaries that are not installed are skipped.
+func (m *Manager) QuickVerify(ctx context.Context) error {
+	var errs []error
+	for _, binary := range []Binary{BinaryMise, BinaryChezmoi} {
+		if err := m.quickVerify(ctx, binary); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// quickVerify implements QuickVerify for one binary
+func (m *Manager) quickVerify(ctx context.Context, binary Binary) error {
+	installed, err := m.IsInstalled(binary)
+	if err != nil || !installed {
+		return err
+	}
+
+	record, err := m.LoadIntegrity(binary)
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		return m.verifyUnrecorded(ctx, binary)
+	}
+
+	sum, err := m.compareIntegrity(binary, record)
+	var tampered *TamperedError
+	if !errors.As(err, &tampered) {
+		if err == nil {
+			m.logger.Debug("integrity verified", "component", binary.Label())
+		}
+		return err
+	}
+
+	// A record trusted on first use by an earlier ZERB names no release to
+	// re-verify against
+	if record.Version == "" {
+		m.logger.Error("integrity check failed", "component", binary.Label())
+		return tampered
+	}
+
+	m.logger.Warn("integrity mismatch, re-verifying", "component", binary.Label(), "version", record.Version)
+	result, releaseSum, err := m.verifiedReleaseHash(ctx, binary, record.Version)
+	if err == nil && !strings.EqualFold(releaseSum, sum) {
+		err = fmt.Errorf("it differs from the verified %s release", record.Version)
+	}
+	if err != nil {
+		m.logger.Error("integrity check failed", "component", binary.Label(), "error", err)
+		tampered.Err = err
+		return tampered
+	}
+
+	if _, err := m.recordIntegrity(binary, record.Version, result.Verified); err != nil {
+		return fmt.Errorf("record %s integrity: %w", binary.Label(), err)
+	}
+	m.logger.Info("integrity re-verified", "component", binary.Label(), "version", record.Version)
+	return nil
+}
+
+// verifyUnrecorded implements QuickVerify for a binary without an integrity
+// record, verifying it against the release of its expected version. The
+// binary is not run, as it cannot be trusted until it is verified.
+func (m *Manager) verifyUnrecorded(ctx context.Context, binary Binary) error {
+	m.logger.Warn("no integrity record, verifying against the release", "component", binary.Label())
+	path := m.GetBinaryPath(binary)
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("stat binary: %w", err)
+	}
+	sum, err := calculateSHA256(path)
+	if err != nil {
+		return fmt.Errorf("hash binary: %w", err)
+	}
+	version, err := m.expectedVersion(binary)
+	if err != nil {
+		return err
+	}
+
+	result, releaseSum, err := m.verifiedReleaseHash(ctx, binary, version)
+	if err != nil {
+		m.logger.Warn("integrity not verified", "component", binary.Label(), "version", version, "error", err)
+		return &UnverifiedError{Binary: binary, Version: version, Err: err}
+	}
+	if !strings.EqualFold(releaseSum, sum) {
+		err := fmt.Errorf("it differs from the verified %s release", version)
+		m.logger.Error("integrity check failed", "component", binary.Label(), "error", err)
+		return &TamperedError{Binary: binary, Actual: sum, Modified: info.ModTime(), Err: err}
+	}
+
+	if _, err := m.recordIntegrity(binary, version, result.Verified); err != nil {
+		return fmt.Errorf("record %s integrity: %w", binary.Label(), err)
+	}
+	fmt.Fprintf(m.stderr, "Note: the %s had no integrity record; verified it against its %s release and recorded it\n",
+		binary.Label(), version)
+	m.logger.Info("integrity verified against the release", "component", binary.Label(), "version", version)
+	return nil
+}
+
+// verifiedReleaseHash downloads and verifies a release of binary and
+// returns the SHA256 of the binary it contains
+func (m *Manager) verifiedReleaseHash(ctx context.Context, binary Binary, version string) (*DownloadResult, string, error) {
+	result, err := m.Download(ctx, DownloadOptions{Binary: binary, Version: version})
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := os.MkdirAll(m.cacheDir, 0755); err != nil {
+		return nil, "", fmt.Errorf("create cache dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(m.cacheDir, "."+binary.String()+".verify-")
+	if err != nil {
+		return nil, "", fmt.Errorf("create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	_ = tmp.Close()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if err := m.extractor.ExtractBinary(result.Path, tmpPath, binary.String()); err != nil {
+		return nil, "", fmt.Errorf("extract binary: %w", err)
+	}
+	sum, err := calculateSHA256(tmpPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("hash binary: %w", err)
+	}
+	return result, sum, nil
+}
+
+// replaceIntegrity records binary after Update installed version. With
+// keepPrevious the record of the replaced binary is kept for Rollback.
+// Failures are logged: a missing record only means the next QuickVerify
+// verifies the binary against its release again.
+func (m *Manager) replaceIntegrity(binary Binary, version string, method VerificationMethod, keepPrevious bool) {
+	path := m.integrityPath(binary)
+	if keepPrevious {
+		if err := renameIfExists(path, path+previousSuffix); err != nil {
+			m.logger.Warn("integrity record not kept", "component", binary.Label(), "error", err)
+		}
+	}
+	if _, err := m.recordIntegrity(binary, version, method); err != nil {
+		m.logger.Warn("integrity record failed", "component", binary.Label(), "error", err)
+	}
+}
+
+// swapIntegrity swaps the records of binary and its previous version
+// after Rollback swapped the binaries
+func (m *Manager) swapIntegrity(binary Binary) {
+	path := m.integrityPath(binary)
+	swapPath := path + ".swap"
+	err := renameIfExists(path, swapPath)
+	if err == nil {
+		err = renameIfExists(path+previousSuffix, path)
+	}
+	if err == nil {
+		err = renameIfExists(swapPath, path+previousSuffix)
+	}
+	if err != nil {
+		m.logger.Warn("integrity records not swapped", "component", binary.Label(), "error", err)
+		// A stale record would fail QuickVerify; verify against the release
+		// instead
+		_ = os.Remove(path)
+	}
+}
+
+// renameIfExists renames src to dst, replacing dst. A missing src removes
+// dst, so dst never describes the wrong file.
+func renameIfExists(src, dst string) error {
+	err := os.Rename(src, dst)
+	if os.IsNotExist(err) {
+		err = os.Remove(dst)
+		if os.IsNotExist(err) {
+			return nil
+		}
+	}
+	return err
+}
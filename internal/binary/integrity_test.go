@@ -0,0 +1,235 @@
+package binary
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestManagerQuickVerify(t *testing.T) {
+	tests := []struct {
+		name string
+		// setup installs mise and prepares its integrity record
+		setup        func(t *testing.T, m *Manager)
+		wantTampered bool
+		wantVersion  string // recorded version afterwards
+		wantNoRecord bool
+		wantNote     bool // a note says the binary was verified without a record
+	}{
+		{
+			name: "unchanged since install",
+			setup: func(t *testing.T, m *Manager) {
+				seedMiseRelease(t, m, "2024.12.7", "2024.12.7")
+				if err := m.Install(context.Background(), DownloadOptions{Binary: BinaryMise, Version: "2024.12.7"}); err != nil {
+					t.Fatalf("Install() error = %v", err)
+				}
+			},
+			wantVersion: "2024.12.7",
+		},
+		{
+			name: "no record is verified against the release",
+			setup: func(t *testing.T, m *Manager) {
+				seedMiseRelease(t, m, "2024.12.7", "2024.12.7")
+				installMise(t, m, "2024.12.7")
+			},
+			wantVersion: "2024.12.7",
+			wantNote:    true,
+		},
+		{
+			name: "no record is verified against the pinned release",
+			setup: func(t *testing.T, m *Manager) {
+				m.WithVersions(Version{Mise: "2024.12.8"})
+				seedMiseRelease(t, m, "2024.12.8", "2024.12.8")
+				installMise(t, m, "2024.12.8")
+			},
+			wantVersion: "2024.12.8",
+			wantNote:    true,
+		},
+		{
+			name: "no record and differs from the release",
+			setup: func(t *testing.T, m *Manager) {
+				seedMiseRelease(t, m, "2024.12.7", "2024.12.7")
+				installMise(t, m, "2024.12.7 # swapped")
+			},
+			wantTampered: true,
+			wantNoRecord: true,
+		},
+		{
+			name: "changed after trust on first use",
+			setup: func(t *testing.T, m *Manager) {
+				installMise(t, m, "2024.12.7")
+				if _, err := m.recordIntegrity(BinaryMise, "", VerificationNone); err != nil {
+					t.Fatal(err)
+				}
+				installMise(t, m, "6.6.6")
+			},
+			wantTampered: true,
+		},
+		{
+			name: "stale record re-verified against the release",
+			setup: func(t *testing.T, m *Manager) {
+				seedMiseRelease(t, m, "2024.12.7", "2024.12.7")
+				if err := m.Install(context.Background(), DownloadOptions{Binary: BinaryMise, Version: "2024.12.7"}); err != nil {
+					t.Fatalf("Install() error = %v", err)
+				}
+				writeIntegrity(t, m, IntegrityRecord{Binary: "mise", Version: "2024.12.7", SHA256: "0000"})
+			},
+			wantVersion: "2024.12.7",
+		},
+		{
+			name: "binary differs from the release",
+			setup: func(t *testing.T, m *Manager) {
+				seedMiseRelease(t, m, "2024.12.7", "2024.12.7")
+				if err := m.Install(context.Background(), DownloadOptions{Binary: BinaryMise, Version: "2024.12.7"}); err != nil {
+					t.Fatalf("Install() error = %v", err)
+				}
+				installMise(t, m, "6.6.6")
+			},
+			wantTampered: true,
+			wantVersion:  "2024.12.7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newUpdateTestManager(t)
+			tt.setup(t, m)
+			var stderr bytes.Buffer
+			m.WithStderr(&stderr)
+
+			err := m.QuickVerify(context.Background())
+			var tampered *TamperedError
+			if got := errors.As(err, &tampered); got != tt.wantTampered {
+				t.Fatalf("QuickVerify() error = %v, want tampered %v", err, tt.wantTampered)
+			}
+			if !tt.wantTampered && err != nil {
+				t.Fatalf("QuickVerify() error = %v", err)
+			}
+			if got := strings.Contains(stderr.String(), "had no integrity record"); got != tt.wantNote {
+				t.Errorf("stderr = %q, want note %v", stderr.String(), tt.wantNote)
+			}
+			if tt.wantNoRecord {
+				if !strings.Contains(tampered.Error(), "has no integrity record") {
+					t.Errorf("QuickVerify() error = %v, want it to say there was no record", tampered)
+				}
+				if record, err := m.LoadIntegrity(BinaryMise); record != nil || err != nil {
+					t.Errorf("LoadIntegrity() = %+v, %v; want no record", record, err)
+				}
+				return
+			}
+
+			record, err := m.LoadIntegrity(BinaryMise)
+			if err != nil || record == nil {
+				t.Fatalf("LoadIntegrity() = %v, %v; want a record", record, err)
+			}
+			if record.Version != tt.wantVersion {
+				t.Errorf("record version = %q, want %q", record.Version, tt.wantVersion)
+			}
+			if tt.wantTampered {
+				if m.CheckIntegrity(BinaryMise) == nil {
+					t.Error("CheckIntegrity() = nil for a tampered binary")
+				}
+				return
+			}
+			if err := m.CheckIntegrity(BinaryMise); err != nil {
+				t.Errorf("CheckIntegrity() after QuickVerify = %v", err)
+			}
+		})
+	}
+}
+
+// failingTransport fails every request, as when offline
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("network unreachable")
+}
+
+func TestManagerQuickVerify_Unverified(t *testing.T) {
+	m := newUpdateTestManager(t)
+	m.downloader.client.Transport = failingTransport{}
+	m.downloader.retries = 0
+
+	// A binary without a record that leaves a mark if it is run
+	ran := filepath.Join(t.TempDir(), "ran")
+	if err := os.MkdirAll(m.binDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\ntouch " + ran + "\necho 2024.12.7 linux-x64\n"
+	if err := os.WriteFile(m.GetBinaryPath(BinaryMise), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	err := m.QuickVerify(context.Background())
+	var unverified *UnverifiedError
+	if !errors.As(err, &unverified) {
+		t.Fatalf("QuickVerify() error = %v, want *UnverifiedError", err)
+	}
+	var tampered *TamperedError
+	if errors.As(err, &tampered) {
+		t.Errorf("QuickVerify() error = %v, want no *TamperedError when the release is unreachable", err)
+	}
+	if unverified.Version != DefaultVersions.Mise {
+		t.Errorf("Version = %q, want %q", unverified.Version, DefaultVersions.Mise)
+	}
+	if _, err := os.Stat(ran); err == nil {
+		t.Error("QuickVerify() ran the unverified binary")
+	}
+	if record, err := m.LoadIntegrity(BinaryMise); record != nil || err != nil {
+		t.Errorf("LoadIntegrity() = %+v, %v; want no record", record, err)
+	}
+}
+
+func TestManagerUpdate_Integrity(t *testing.T) {
+	m := newUpdateTestManager(t)
+	seedMiseRelease(t, m, "2024.12.7", "2024.12.7")
+	seedMiseRelease(t, m, "2024.12.8", "2024.12.8")
+	ctx := context.Background()
+	if err := m.Install(ctx, DownloadOptions{Binary: BinaryMise, Version: "2024.12.7"}); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	recordedVersion := func() string {
+		t.Helper()
+		record, err := m.LoadIntegrity(BinaryMise)
+		if err != nil || record == nil {
+			t.Fatalf("LoadIntegrity() = %v, %v; want a record", record, err)
+		}
+		if err := m.CheckIntegrity(BinaryMise); err != nil {
+			t.Errorf("CheckIntegrity() = %v", err)
+		}
+		return record.Version
+	}
+
+	if err := m.Update(ctx, BinaryMise, "2024.12.8"); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got := recordedVersion(); got != "2024.12.8" {
+		t.Errorf("after Update: record version = %q, want 2024.12.8", got)
+	}
+
+	if err := m.Rollback(BinaryMise); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if got := recordedVersion(); got != "2024.12.7" {
+		t.Errorf("after Rollback: record version = %q, want 2024.12.7", got)
+	}
+}
+
+// writeIntegrity replaces the integrity record of mise
+func writeIntegrity(t *testing.T, m *Manager, record IntegrityRecord) {
+	t.Helper()
+	data, err := json.Marshal(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(m.integrityPath(BinaryMise), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,10 +1,20 @@
 package binary
 
 import (
+	"bytes"
+	"crypto/sha256"
 	_ "embed"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp" //nolint:staticcheck // Using ProtonMail's maintained fork
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/fsutil"
 )
 
 // Embedded public keys for binary verification
@@ -16,6 +26,43 @@ var miseKeyring []byte
 //go:embed keyrings/chezmoi.pub
 var chezmoiCosignKey []byte
 
+// Expected fingerprints of the embedded keys. VerifyKeyrings checks the
+// extracted keys against these, so a truncated or replaced key on disk is
+// caught before it causes a confusing verification failure.
+const (
+	// miseKeyFingerprint is the OpenPGP fingerprint of the mise release key
+	miseKeyFingerprint = "24853EC9F655CE80B48E6C3A8B81C9D17413A06D"
+	// chezmoiCosignKeyFingerprint is the SHA-256 of the DER-encoded cosign key
+	chezmoiCosignKeyFingerprint = "766e9989f59d2b3935712cbff161041c1e8d71117215eec88c3d83dee2152aef"
+)
+
+// ErrKeyringMismatch is returned when an extracted keyring does not have
+// the expected fingerprint.
+var ErrKeyringMismatch = errors.New("keyring fingerprint mismatch")
+
+// embeddedKey describes a key embedded at compile time and extracted to the
+// keyring directory
+type embeddedKey struct {
+	binary        Binary
+	filename      string
+	fingerprint   string
+	fingerprintOf func(data []byte) (string, error)
+	extract       func(keyringDir string, binary Binary) error
+}
+
+// embeddedKeys lists every key extracted to the keyring directory
+var embeddedKeys = []embeddedKey{
+	{BinaryMise, "mise.gpg", miseKeyFingerprint, gpgFingerprint, extractKeyring},
+	{BinaryChezmoi, "chezmoi.pub", chezmoiCosignKeyFingerprint, cosignKeyFingerprint, extractCosignKey},
+}
+
+// KeyringInfo describes an embedded verification key and its extracted copy.
+type KeyringInfo struct {
+	Binary  Binary // Binary the key verifies
+	Path    string // Location of the extracted copy
+	Present bool   // Extracted copy exists and is not empty
+}
+
 // getKeyring returns the embedded GPG keyring for a binary
 func getKeyring(binary Binary) ([]byte, error) {
 	switch binary {
@@ -46,7 +93,7 @@ func extractKeyring(keyringDir string, binary Binary) error {
 
 	// Write keyring file
 	keyringPath := filepath.Join(keyringDir, fmt.Sprintf("%s.gpg", binary))
-	if err := os.WriteFile(keyringPath, keyring, 0644); err != nil {
+	if err := fsutil.WriteFileAtomic(keyringPath, keyring, 0644); err != nil {
 		return fmt.Errorf("write keyring file: %w", err)
 	}
 
@@ -75,7 +122,7 @@ func extractCosignKey(keyringDir string, binary Binary) error {
 
 	// Write public key file
 	keyPath := filepath.Join(keyringDir, fmt.Sprintf("%s.pub", binary))
-	if err := os.WriteFile(keyPath, keyData, 0644); err != nil {
+	if err := fsutil.WriteFileAtomic(keyPath, keyData, 0644); err != nil {
 		return fmt.Errorf("write cosign key file: %w", err)
 	}
 
@@ -104,10 +151,57 @@ func getKeyringPath(keyringDir string, binary Binary) string {
 
 // keyringExists checks if a keyring file exists on disk
 func keyringExists(keyringDir string, binary Binary) bool {
-	path := getKeyringPath(keyringDir, binary)
+	return keyFilePresent(getKeyringPath(keyringDir, binary))
+}
+
+// keyFilePresent reports whether path is a non-empty file
+func keyFilePresent(path string) bool {
 	info, err := os.Stat(path)
 	if err != nil {
 		return false
 	}
 	return !info.IsDir() && info.Size() > 0
 }
+
+// gpgFingerprint returns the fingerprint of the first key in an armored or
+// binary OpenPGP keyring
+func gpgFingerprint(data []byte) (string, error) {
+	keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	if err != nil {
+		keyring, err = openpgp.ReadKeyRing(bytes.NewReader(data))
+		if err != nil {
+			return "", fmt.Errorf("read keyring: %w", err)
+		}
+	}
+	if len(keyring) == 0 || keyring[0].PrimaryKey == nil {
+		return "", fmt.Errorf("keyring is empty")
+	}
+	return strings.ToUpper(hex.EncodeToString(keyring[0].PrimaryKey.Fingerprint)), nil
+}
+
+// cosignKeyFingerprint returns the SHA-256 of a PEM-encoded public key
+func cosignKeyFingerprint(data []byte) (string, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return "", fmt.Errorf("failed to decode PEM block")
+	}
+	sum := sha256.Sum256(block.Bytes)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// verifyKeyFile checks that the key file at path has the expected fingerprint
+func verifyKeyFile(path, want string, fingerprint func([]byte) (string, error)) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", filepath.Base(path), err)
+	}
+
+	got, err := fingerprint(data)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrKeyringMismatch, filepath.Base(path), err)
+	}
+	if !strings.EqualFold(got, want) {
+		return fmt.Errorf("%w: %s: got %s, want %s", ErrKeyringMismatch, filepath.Base(path), got, want)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package binary
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/platform"
+)
+
+func TestManagerDownload_LocalDir(t *testing.T) {
+	platformInfo := &platform.Info{OS: "linux", Arch: "amd64"}
+	info, err := constructDownloadInfo(BinaryMise, DefaultVersions.Mise, platformInfo)
+	if err != nil {
+		t.Fatalf("constructDownloadInfo failed: %v", err)
+	}
+	allFiles := []string{info.URL, info.SignatureURL, info.ChecksumURL}
+
+	tests := []struct {
+		name       string
+		files      []string // release files present in the local directory
+		skipVerify bool
+		wantErr    []string
+	}{
+		{
+			name:    "missing files are listed",
+			files:   []string{info.URL},
+			wantErr: []string{"missing 2 file(s)", filepath.Base(info.SignatureURL), info.ChecksumURL},
+		},
+		{
+			name:    "files are verified",
+			files:   allFiles,
+			wantErr: []string{"verify binary", "moved to"},
+		},
+		{
+			name:       "dev build may skip verification",
+			files:      allFiles,
+			skipVerify: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.skipVerify {
+				t.Setenv(EnvInsecureSkipVerify, "1")
+			}
+
+			server := httptest.NewServer(http.NotFoundHandler())
+			defer server.Close()
+			target, _ := url.Parse(server.URL)
+
+			tmpDir := t.TempDir()
+			manager, err := NewManager(Config{ZerbDir: tmpDir, PlatformInfo: platformInfo})
+			if err != nil {
+				t.Fatalf("NewManager failed: %v", err)
+			}
+			manager.WithStderr(&strings.Builder{}).WithClock(clock.NewFake(time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)))
+			manager.devBuild = tt.skipVerify
+			transport := &redirectTransport{target: target}
+			manager.downloader.client.Transport = transport
+			if err := manager.EnsureKeyrings(); err != nil {
+				t.Fatalf("EnsureKeyrings failed: %v", err)
+			}
+
+			localDir := t.TempDir()
+			for _, u := range tt.files {
+				if err := os.WriteFile(filepath.Join(localDir, filepath.Base(u)), []byte("offline copy"), 0644); err != nil {
+					t.Fatalf("failed to write %s: %v", u, err)
+				}
+			}
+
+			result, err := manager.Download(context.Background(), DownloadOptions{Binary: BinaryMise, LocalDir: localDir})
+
+			if len(transport.paths) != 0 {
+				t.Errorf("offline Download() made requests: %v", transport.paths)
+			}
+			// Verification failures never move the originals
+			for _, u := range tt.files {
+				if _, err := os.Stat(filepath.Join(localDir, filepath.Base(u))); err != nil {
+					t.Errorf("original %s was touched: %v", filepath.Base(u), err)
+				}
+			}
+
+			if len(tt.wantErr) > 0 {
+				if err == nil {
+					t.Fatal("Download() succeeded, want an error")
+				}
+				for _, want := range tt.wantErr {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("Download() error = %v, want containing %q", err, want)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Download() error = %v", err)
+			}
+			want := filepath.Join(tmpDir, "cache", "offline", "mise", DefaultVersions.Mise, filepath.Base(info.URL))
+			if result.Path != want {
+				t.Errorf("Path = %q, want staged copy %q", result.Path, want)
+			}
+		})
+	}
+}
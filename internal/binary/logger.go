@@ -0,0 +1,32 @@
+package binary
+
+// Logger provides structured logging for download, verification and install operations.
+// It has the same methods as config.Logger, so one implementation (e.g. a
+// *slog.Logger) can be shared between packages.
+type Logger interface {
+	// Debug logs debug-level messages with optional key-value pairs.
+	Debug(msg string, keysAndValues ...interface{})
+
+	// Info logs info-level messages with optional key-value pairs.
+	Info(msg string, keysAndValues ...interface{})
+
+	// Warn logs warning-level messages with optional key-value pairs.
+	Warn(msg string, keysAndValues ...interface{})
+
+	// Error logs error-level messages with optional key-value pairs.
+	Error(msg string, keysAndValues ...interface{})
+}
+
+// noopLogger is a Logger implementation that does nothing.
+// This is the default logger used when none is provided.
+type noopLogger struct{}
+
+func (n *noopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (n *noopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (n *noopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (n *noopLogger) Error(msg string, keysAndValues ...interface{}) {}
+
+// defaultLogger returns the default no-op logger.
+func defaultLogger() Logger {
+	return &noopLogger{}
+}
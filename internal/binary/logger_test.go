@@ -0,0 +1,57 @@
+package binary
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// recordingLogger records the messages logged at each level
+type recordingLogger struct {
+	messages []string
+}
+
+func (r *recordingLogger) Debug(msg string, keysAndValues ...interface{}) {
+	r.messages = append(r.messages, "debug: "+msg)
+}
+func (r *recordingLogger) Info(msg string, keysAndValues ...interface{}) {
+	r.messages = append(r.messages, "info: "+msg)
+}
+func (r *recordingLogger) Warn(msg string, keysAndValues ...interface{}) {
+	r.messages = append(r.messages, "warn: "+msg)
+}
+func (r *recordingLogger) Error(msg string, keysAndValues ...interface{}) {
+	r.messages = append(r.messages, "error: "+msg)
+}
+
+func TestManagerWithLogger(t *testing.T) {
+	m := newUpdateTestManager(t)
+	seedMiseRelease(t, m, "2024.12.8", "2024.12.8")
+	logger := &recordingLogger{}
+	m.WithLogger(logger)
+	ctx := context.Background()
+
+	opts := DownloadOptions{Binary: BinaryMise, Version: "2024.12.8"}
+	if _, err := m.InstallWithResult(ctx, opts); err != nil {
+		t.Fatalf("InstallWithResult() error = %v", err)
+	}
+	if _, err := m.InstallWithResult(ctx, opts); err != nil {
+		t.Fatalf("second InstallWithResult() error = %v", err)
+	}
+
+	want := []string{
+		"debug: download start",
+		"warn: verification skipped",
+		"info: installed",
+		"debug: already installed",
+	}
+	if !reflect.DeepEqual(logger.messages, want) {
+		t.Errorf("logged %q, want %q", logger.messages, want)
+	}
+
+	// A nil logger turns logging off again
+	m.WithLogger(nil)
+	if _, ok := m.logger.(*noopLogger); !ok {
+		t.Errorf("WithLogger(nil) set %T, want the no-op logger", m.logger)
+	}
+}
@@ -2,23 +2,42 @@ package binary
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"time"
 
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
 	"github.com/ZebulonRouseFrantzich/zerb/internal/platform"
+	"golang.org/x/sync/errgroup"
 )
 
+// downloadWorkers bounds the concurrent requests of one Download: the
+// archive, its signature, its checksums and its cosign bundle
+const downloadWorkers = 4
+
 // Manager orchestrates binary download, verification, and installation
 type Manager struct {
-	binDir       string
-	keyringDir   string
-	cacheDir     string
-	platformInfo *platform.Info
-	downloader   *Downloader
-	verifier     *Verifier
-	extractor    *Extractor
+	zerbDir       string
+	binDir        string
+	keyringDir    string
+	cacheDir      string
+	offlineDir    string
+	quarantineDir string
+	platformInfo  *platform.Info
+	downloader    *Downloader
+	verifier      *Verifier
+	extractor     *Extractor
+	clock         clock.Clock
+	stderr        io.Writer
+	logger        Logger
+	// versions are those installed, for binaries without an integrity
+	// record; zero fields use DefaultVersions
+	versions Version
+	// devBuild enables EnvInsecureSkipVerify (development builds only)
+	devBuild bool
 }
 
 // Config holds configuration for the binary manager
@@ -43,37 +62,148 @@ func NewManager(config Config) (*Manager, error) {
 	binDir := filepath.Join(config.ZerbDir, "bin")
 	keyringDir := filepath.Join(config.ZerbDir, "keyrings")
 	cacheDir := filepath.Join(config.ZerbDir, "cache", "downloads")
+	offlineDir := filepath.Join(config.ZerbDir, "cache", "offline")
+	quarantineDir := filepath.Join(config.ZerbDir, "cache", "quarantine")
 
 	// Create manager
 	manager := &Manager{
-		binDir:       binDir,
-		keyringDir:   keyringDir,
-		cacheDir:     cacheDir,
-		platformInfo: config.PlatformInfo,
-		downloader:   NewDownloader(cacheDir),
-		verifier:     NewVerifier(keyringDir),
-		extractor:    NewExtractor(),
+		zerbDir:       config.ZerbDir,
+		binDir:        binDir,
+		keyringDir:    keyringDir,
+		cacheDir:      cacheDir,
+		offlineDir:    offlineDir,
+		quarantineDir: quarantineDir,
+		platformInfo:  config.PlatformInfo,
+		downloader:    NewDownloader(cacheDir),
+		verifier:      NewVerifier(keyringDir),
+		extractor:     NewExtractor(),
+		clock:         clock.Real{},
+		stderr:        os.Stderr,
+		logger:        defaultLogger(),
+		devBuild:      devBuild,
 	}
 
 	return manager, nil
 }
 
-// EnsureKeyrings extracts embedded GPG keyrings to disk
-// This is idempotent - safe to call multiple times
+// WithClock sets the clock used for download retries and audit log entries.
+// Returns the manager for method chaining.
+func (m *Manager) WithClock(clk clock.Clock) *Manager {
+	m.clock = clk
+	m.downloader.WithClock(clk)
+	return m
+}
+
+// WithSigstore configures keyless cosign bundle verification for binaries
+// whose releases publish a bundle. It is off unless called; the CLI does
+// not, as the pinned releases publish no bundle. Returns the manager for
+// method chaining.
+func (m *Manager) WithSigstore(cfg SigstoreConfig) *Manager {
+	m.verifier.WithSigstore(cfg)
+	return m
+}
+
+// WithVersions sets the versions the core components were installed at
+// (default DefaultVersions; empty fields keep the default). QuickVerify
+// verifies a binary without an integrity record against this release
+// rather than running it to ask. Returns the manager for method chaining.
+func (m *Manager) WithVersions(versions Version) *Manager {
+	m.versions = versions
+	return m
+}
+
+// expectedVersion returns the version binary was installed at, as set by
+// WithVersions
+func (m *Manager) expectedVersion(binary Binary) (string, error) {
+	switch binary {
+	case BinaryMise:
+		if m.versions.Mise != "" {
+			return m.versions.Mise, nil
+		}
+		return DefaultVersions.Mise, nil
+	case BinaryChezmoi:
+		if m.versions.Chezmoi != "" {
+			return m.versions.Chezmoi, nil
+		}
+		return DefaultVersions.Chezmoi, nil
+	default:
+		return "", fmt.Errorf("unknown binary: %s", binary)
+	}
+}
+
+// WithStderr sets where warnings are written (default os.Stderr).
+// Returns the manager for method chaining.
+func (m *Manager) WithStderr(w io.Writer) *Manager {
+	m.stderr = w
+	return m
+}
+
+// WithLogger sets the logger that downloads, verification and installs
+// are reported to (default: no logging). Returns the manager for method
+// chaining.
+func (m *Manager) WithLogger(logger Logger) *Manager {
+	if logger == nil {
+		logger = defaultLogger()
+	}
+	m.logger = logger
+	return m
+}
+
+// ListKeyrings returns the embedded verification keys and whether each
+// has been extracted to the keyring directory.
+func (m *Manager) ListKeyrings() []KeyringInfo {
+	keyrings := make([]KeyringInfo, 0, len(embeddedKeys))
+	for _, key := range embeddedKeys {
+		path := filepath.Join(m.keyringDir, key.filename)
+		keyrings = append(keyrings, KeyringInfo{
+			Binary:  key.binary,
+			Path:    path,
+			Present: keyFilePresent(path),
+		})
+	}
+	return keyrings
+}
+
+// EnsureKeyrings extracts any embedded keyring missing from disk.
+// Keyrings already present are left untouched; use ExtractKeyrings to
+// overwrite them. This is idempotent - safe to call multiple times
 func (m *Manager) EnsureKeyrings() error {
-	// Check if keyrings already exist (idempotent operation)
-	if keyringExists(m.keyringDir, BinaryMise) {
-		return nil // Already extracted
+	for _, key := range embeddedKeys {
+		if keyFilePresent(filepath.Join(m.keyringDir, key.filename)) {
+			continue
+		}
+		if err := key.extract(m.keyringDir, key.binary); err != nil {
+			return fmt.Errorf("extract keyrings: %w", err)
+		}
 	}
 
-	// Extract all embedded keyrings
+	return nil
+}
+
+// ExtractKeyrings extracts all embedded keyrings, overwriting any copies
+// already on disk.
+func (m *Manager) ExtractKeyrings() error {
 	if err := extractAllKeyrings(m.keyringDir); err != nil {
 		return fmt.Errorf("extract keyrings: %w", err)
 	}
-
 	return nil
 }
 
+// VerifyKeyrings re-reads each extracted keyring and checks that its
+// fingerprint matches the embedded key. A missing, truncated or replaced
+// keyring is reported as an error wrapping ErrKeyringMismatch (or the read
+// error for a missing file). All keyrings are checked; errors are joined.
+func (m *Manager) VerifyKeyrings() error {
+	var errs []error
+	for _, key := range embeddedKeys {
+		path := filepath.Join(m.keyringDir, key.filename)
+		if err := verifyKeyFile(path, key.fingerprint, key.fingerprintOf); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // IsInstalled checks if a binary is already installed and executable
 func (m *Manager) IsInstalled(binary Binary) (bool, error) {
 	binaryPath := filepath.Join(m.binDir, binary.String())
@@ -102,6 +232,9 @@ func (m *Manager) IsInstalled(binary Binary) (bool, error) {
 
 // GetInstalledVersion returns the version of an installed binary
 // For now, this returns the hard-coded version since we know what we installed
+//
+// Deprecated: The binary may have been installed with a pinned version; use
+// InstalledVersion, which asks the binary itself.
 func (m *Manager) GetInstalledVersion(binary Binary) (string, error) {
 	installed, err := m.IsInstalled(binary)
 	if err != nil {
@@ -139,71 +272,169 @@ func (m *Manager) Download(ctx context.Context, opts DownloadOptions) (*Download
 		}
 	}
 
+	// The version drives the URLs, checksum lookup and verification, so it
+	// must look like a release of this binary
+	version, err := NormalizeVersion(opts.Binary, opts.Version)
+	if err != nil {
+		return nil, err
+	}
+	opts.Version = version
+
 	// Construct download info
 	downloadInfo, err := constructDownloadInfo(opts.Binary, opts.Version, m.platformInfo)
 	if err != nil {
 		return nil, fmt.Errorf("construct download info: %w", err)
 	}
 
-	// Download binary
-	binaryPath, err := m.downloader.DownloadBinary(ctx, downloadInfo)
-	if err != nil {
-		return nil, fmt.Errorf("download binary: %w", err)
+	// Decide which verification files are needed before fetching anything
+	skipVerify := m.skipVerify()
+	fetchSignature, fetchChecksums := false, false
+	if !skipVerify {
+		switch opts.Binary {
+		case BinaryMise:
+			// mise REQUIRES both the GPG-signed checksums and the checksums file
+			if downloadInfo.SignatureURL == "" && !opts.SkipGPG {
+				return nil, fmt.Errorf("missing GPG signature URL for mise")
+			}
+			if downloadInfo.ChecksumURL == "" {
+				return nil, fmt.Errorf("missing checksums URL for mise")
+			}
+			fetchSignature = !opts.SkipGPG
+			fetchChecksums = true
+
+		case BinaryChezmoi:
+			// chezmoi uses key-based cosign verification, and cosign signs
+			// the checksum file
+			fetchSignature = downloadInfo.SignatureURL != ""
+			fetchChecksums = downloadInfo.ChecksumURL != ""
+
+		default:
+			return nil, fmt.Errorf("unknown binary: %s", opts.Binary)
+		}
 	}
 
-	// Download verification files based on binary type
-	var signaturePath, checksumPath, bundlePath string
+	// A keyless cosign bundle is preferred over the methods above when the
+	// release publishes one and a signer is pinned for the binary
+	_, pinned := m.verifier.sigstore.identity(opts.Binary)
+	fetchBundle := pinned && !skipVerify && downloadInfo.BundleURL != ""
+
+	// An offline install reads the same files from a local directory; check
+	// up front that all of them are there
+	var source artifactSource = m.downloader
+	if opts.LocalDir != "" {
+		local := newLocalSource(opts.LocalDir, m.offlineDir)
+		required := []string{downloadInfo.URL}
+		if fetchSignature {
+			required = append(required, downloadInfo.SignatureURL)
+		}
+		if fetchChecksums {
+			required = append(required, downloadInfo.ChecksumURL)
+		}
+		if fetchBundle {
+			required = append(required, downloadInfo.BundleURL)
+		}
+		if err := local.checkFiles(downloadInfo, required); err != nil {
+			return nil, err
+		}
+		source = local
+	}
 
-	switch opts.Binary {
-	case BinaryMise:
-		// mise REQUIRES both the GPG-signed checksums and the checksums file
-		if downloadInfo.SignatureURL != "" && !opts.SkipGPG {
-			signaturePath, err = m.downloader.DownloadSignature(ctx, downloadInfo)
-			if err != nil {
-				return nil, fmt.Errorf("failed to download required GPG signature for mise: %w", err)
-			}
-		} else if !opts.SkipGPG {
-			return nil, fmt.Errorf("missing GPG signature URL for mise")
+	m.logger.Debug("download start",
+		"component", opts.Binary.Label(), "version", opts.Version,
+		"os", m.platformInfo.OS, "arch", m.platformInfo.Arch,
+		"offline", opts.LocalDir != "",
+		"signature", fetchSignature, "checksums", fetchChecksums, "bundle", fetchBundle)
+
+	// Fetch the archive and verification files concurrently. The first
+	// failure cancels the other in-flight requests.
+	var binaryPath, signaturePath, checksumPath, bundlePath string
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(downloadWorkers)
+
+	g.Go(func() error {
+		path, err := source.DownloadBinary(gctx, downloadInfo, opts.Progress)
+		if err != nil {
+			return fmt.Errorf("download binary: %w", err)
 		}
+		binaryPath = path
+		return nil
+	})
 
-		if downloadInfo.ChecksumURL != "" {
-			checksumPath, err = m.downloader.DownloadChecksums(ctx, downloadInfo)
+	if fetchSignature {
+		g.Go(func() error {
+			path, err := source.DownloadSignature(gctx, downloadInfo)
 			if err != nil {
-				return nil, fmt.Errorf("failed to download required checksums for mise: %w", err)
+				if opts.Binary == BinaryMise {
+					return fmt.Errorf("failed to download required GPG signature for mise: %w", err)
+				}
+				return fmt.Errorf("failed to download required cosign signature for chezmoi: %w", err)
 			}
-		} else {
-			return nil, fmt.Errorf("missing checksums URL for mise")
-		}
+			signaturePath = path
+			return nil
+		})
+	}
 
-	case BinaryChezmoi:
-		// chezmoi uses key-based cosign verification
-		if downloadInfo.SignatureURL != "" {
-			signaturePath, err = m.downloader.DownloadSignature(ctx, downloadInfo)
+	if fetchChecksums {
+		g.Go(func() error {
+			path, err := source.DownloadChecksums(gctx, downloadInfo)
 			if err != nil {
-				return nil, fmt.Errorf("failed to download required cosign signature for chezmoi: %w", err)
+				return fmt.Errorf("failed to download required checksums for %s: %w", opts.Binary, err)
 			}
-		}
+			checksumPath = path
+			return nil
+		})
+	}
 
-		// Also download checksums (cosign signs the checksum file)
-		if downloadInfo.ChecksumURL != "" {
-			checksumPath, err = m.downloader.DownloadChecksums(ctx, downloadInfo)
+	if fetchBundle {
+		g.Go(func() error {
+			path, err := source.DownloadBundle(gctx, downloadInfo)
 			if err != nil {
-				return nil, fmt.Errorf("failed to download required checksums for chezmoi: %w", err)
+				return fmt.Errorf("failed to download cosign bundle for %s: %w", opts.Binary, err)
 			}
+			bundlePath = path
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		m.logger.Error("download failed", "component", opts.Binary.Label(), "version", opts.Version, "error", err)
+		if errors.Is(err, ErrAssetNotFound) {
+			return nil, fmt.Errorf("%s %s has no release for %s/%s (is it a published version?): %w",
+				opts.Binary, opts.Version, m.platformInfo.OS, m.platformInfo.Arch, err)
 		}
+		return nil, err
+	}
 
-	default:
-		return nil, fmt.Errorf("unknown binary: %s", opts.Binary)
+	// Development builds may skip verification entirely
+	if skipVerify {
+		m.logger.Warn("verification skipped", "component", opts.Binary.Label(), "version", opts.Version)
+		if err := m.recordInsecureSkip(downloadInfo, binaryPath); err != nil {
+			return nil, err
+		}
+		return &DownloadResult{
+			Binary:       opts.Binary,
+			Version:      opts.Version,
+			Path:         binaryPath,
+			Verified:     VerificationNone,
+			DownloadTime: time.Since(startTime),
+		}, nil
 	}
 
-	// Verify binary
+	// Verify binary. A download that fails verification is quarantined,
+	// never left in the cache for a later install to pick up.
 	verifyResult, err := m.verifier.VerifyFile(binaryPath, signaturePath, checksumPath, bundlePath, downloadInfo)
-	if err != nil {
-		return nil, fmt.Errorf("verify binary: %w", err)
+	if err == nil && !verifyResult.Success {
+		err = &VerificationError{Method: verifyResult.Method, Err: fmt.Errorf("verification failed: %v", verifyResult.Error)}
 	}
-
-	if !verifyResult.Success {
-		return nil, fmt.Errorf("verification failed: %v", verifyResult.Error)
+	if err != nil {
+		m.logger.Error("verification failed", "component", opts.Binary.Label(), "version", opts.Version, "error", err)
+		dir, qErr := m.quarantine(downloadInfo, err, binaryPath, signaturePath, checksumPath, bundlePath)
+		if qErr != nil {
+			m.logger.Warn("quarantine failed", "error", qErr)
+			fmt.Fprintf(m.stderr, "Warning: could not quarantine unverified download: %v\n", qErr)
+			return nil, fmt.Errorf("verify binary: %w", err)
+		}
+		return nil, fmt.Errorf("verify binary: %w\nThe unverified download was moved to %s for inspection", err, dir)
 	}
 
 	// Return result
@@ -214,46 +445,65 @@ func (m *Manager) Download(ctx context.Context, opts DownloadOptions) (*Download
 		Verified:     verifyResult.Method,
 		DownloadTime: time.Since(startTime),
 	}
+	m.logger.Info("download finished",
+		"component", opts.Binary.Label(), "version", opts.Version,
+		"verification", result.Verified.String(), "duration", result.DownloadTime)
 
 	return result, nil
 }
 
 // Install downloads, verifies, extracts, and installs a binary
 func (m *Manager) Install(ctx context.Context, opts DownloadOptions) error {
+	_, err := m.InstallWithResult(ctx, opts)
+	return err
+}
+
+// InstallWithResult is Install, returning the download result so callers
+// can report how the binary was verified. The result is nil if the binary
+// was already installed and nothing was downloaded.
+func (m *Manager) InstallWithResult(ctx context.Context, opts DownloadOptions) (*DownloadResult, error) {
 	// Check if already installed
 	installed, err := m.IsInstalled(opts.Binary)
 	if err != nil {
-		return fmt.Errorf("check if installed: %w", err)
+		return nil, fmt.Errorf("check if installed: %w", err)
 	}
 
 	if installed {
 		// Already installed, skip
-		return nil
+		m.logger.Debug("already installed", "component", opts.Binary.Label(), "path", m.GetBinaryPath(opts.Binary))
+		return nil, nil
 	}
 
 	// Download and verify
 	result, err := m.Download(ctx, opts)
 	if err != nil {
-		return fmt.Errorf("download: %w", err)
+		return nil, fmt.Errorf("download: %w", err)
 	}
 
 	// Create bin directory
 	if err := os.MkdirAll(m.binDir, 0755); err != nil {
-		return fmt.Errorf("create bin dir: %w", err)
+		return nil, fmt.Errorf("create bin dir: %w", err)
 	}
 
 	// Extract binary to bin directory
 	destPath := filepath.Join(m.binDir, opts.Binary.String())
 	if err := m.extractor.ExtractBinary(result.Path, destPath, opts.Binary.String()); err != nil {
-		return fmt.Errorf("extract binary: %w", err)
+		return nil, fmt.Errorf("extract binary: %w", err)
 	}
 
 	// Ensure it's executable (should already be set by extractor)
 	if err := SetExecutable(destPath); err != nil {
-		return fmt.Errorf("set executable: %w", err)
+		return nil, fmt.Errorf("set executable: %w", err)
 	}
 
-	return nil
+	// Record the verified binary for QuickVerify; without a record it is
+	// verified against its release again
+	if _, err := m.recordIntegrity(opts.Binary, result.Version, result.Verified); err != nil {
+		m.logger.Warn("integrity record failed", "component", opts.Binary.Label(), "error", err)
+	}
+
+	m.logger.Info("installed", "component", opts.Binary.Label(), "version", result.Version, "path", destPath)
+	return result, nil
 }
 
 // InstallAll installs both mise and chezmoi binaries
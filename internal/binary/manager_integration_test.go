@@ -133,7 +133,7 @@ func TestManagerDownload_Complete(t *testing.T) {
 
 	// Download binary
 	ctx := context.Background()
-	binaryPath, err := manager.downloader.DownloadBinary(ctx, downloadInfo)
+	binaryPath, err := manager.downloader.DownloadBinary(ctx, downloadInfo, nil)
 	if err != nil {
 		t.Fatalf("download failed: %v", err)
 	}
@@ -220,7 +220,7 @@ func TestManagerInstall_Complete(t *testing.T) {
 	ctx := context.Background()
 
 	// Download
-	binaryPath, err := manager.downloader.DownloadBinary(ctx, downloadInfo)
+	binaryPath, err := manager.downloader.DownloadBinary(ctx, downloadInfo, nil)
 	if err != nil {
 		t.Fatalf("download failed: %v", err)
 	}
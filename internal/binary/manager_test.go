@@ -1,14 +1,23 @@
 package binary
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/ProtonMail/go-crypto/openpgp" //nolint:staticcheck // Using ProtonMail's maintained fork
+	"github.com/ZebulonRouseFrantzich/zerb/internal/audit"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
 	"github.com/ZebulonRouseFrantzich/zerb/internal/platform"
 )
 
@@ -76,6 +85,198 @@ func TestNewManager(t *testing.T) {
 	}
 }
 
+func TestManagerEnsureKeyrings_RepairsMissing(t *testing.T) {
+	manager, err := NewManager(Config{
+		ZerbDir:      t.TempDir(),
+		PlatformInfo: &platform.Info{OS: "linux", Arch: "amd64"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+
+	// Nothing extracted yet
+	for _, k := range manager.ListKeyrings() {
+		if k.Present {
+			t.Errorf("%s reported present before extraction", k.Path)
+		}
+	}
+
+	if err := manager.EnsureKeyrings(); err != nil {
+		t.Fatalf("EnsureKeyrings failed: %v", err)
+	}
+	keyrings := manager.ListKeyrings()
+	if len(keyrings) != 2 {
+		t.Fatalf("ListKeyrings() returned %d keyrings, want 2", len(keyrings))
+	}
+	for _, k := range keyrings {
+		if !k.Present {
+			t.Errorf("%s missing after EnsureKeyrings", k.Path)
+		}
+	}
+
+	// Delete one keyring and mark the other so changes are visible
+	deleted := filepath.Join(manager.keyringDir, "chezmoi.pub")
+	intact := filepath.Join(manager.keyringDir, "mise.gpg")
+	if err := os.Remove(deleted); err != nil {
+		t.Fatalf("failed to delete keyring: %v", err)
+	}
+	marked := append(append([]byte(nil), miseKeyring...), []byte("\n# local copy\n")...)
+	if err := os.WriteFile(intact, marked, 0644); err != nil {
+		t.Fatalf("failed to mark keyring: %v", err)
+	}
+
+	for _, k := range manager.ListKeyrings() {
+		if want := k.Path != deleted; k.Present != want {
+			t.Errorf("%s Present = %v, want %v", k.Path, k.Present, want)
+		}
+	}
+
+	if err := manager.EnsureKeyrings(); err != nil {
+		t.Fatalf("EnsureKeyrings failed: %v", err)
+	}
+
+	// The deleted keyring is re-extracted from the embedded copy
+	got, err := os.ReadFile(deleted)
+	if err != nil {
+		t.Fatalf("deleted keyring not re-extracted: %v", err)
+	}
+	if !bytes.Equal(got, chezmoiCosignKey) {
+		t.Error("re-extracted keyring does not match the embedded key")
+	}
+
+	// The intact keyring is left as-is
+	got, err = os.ReadFile(intact)
+	if err != nil {
+		t.Fatalf("failed to read intact keyring: %v", err)
+	}
+	if !bytes.Equal(got, marked) {
+		t.Error("EnsureKeyrings overwrote a keyring that was present")
+	}
+
+	// ExtractKeyrings overwrites it
+	if err := manager.ExtractKeyrings(); err != nil {
+		t.Fatalf("ExtractKeyrings failed: %v", err)
+	}
+	got, err = os.ReadFile(intact)
+	if err != nil {
+		t.Fatalf("failed to read keyring: %v", err)
+	}
+	if !bytes.Equal(got, miseKeyring) {
+		t.Error("ExtractKeyrings did not restore the embedded keyring")
+	}
+}
+
+func TestManagerVerifyKeyrings(t *testing.T) {
+	newManager := func(t *testing.T) *Manager {
+		t.Helper()
+		manager, err := NewManager(Config{
+			ZerbDir:      t.TempDir(),
+			PlatformInfo: &platform.Info{OS: "linux", Arch: "amd64"},
+		})
+		if err != nil {
+			t.Fatalf("failed to create manager: %v", err)
+		}
+		if err := manager.EnsureKeyrings(); err != nil {
+			t.Fatalf("EnsureKeyrings failed: %v", err)
+		}
+		return manager
+	}
+
+	t.Run("extracted keyrings match", func(t *testing.T) {
+		manager := newManager(t)
+		if err := manager.VerifyKeyrings(); err != nil {
+			t.Errorf("VerifyKeyrings() error = %v", err)
+		}
+	})
+
+	t.Run("truncated keyring", func(t *testing.T) {
+		manager := newManager(t)
+		path := filepath.Join(manager.keyringDir, "mise.gpg")
+		if err := os.WriteFile(path, miseKeyring[:len(miseKeyring)/2], 0644); err != nil {
+			t.Fatalf("failed to truncate keyring: %v", err)
+		}
+
+		err := manager.VerifyKeyrings()
+		if !errors.Is(err, ErrKeyringMismatch) {
+			t.Fatalf("VerifyKeyrings() error = %v, want ErrKeyringMismatch", err)
+		}
+		if !strings.Contains(err.Error(), "mise.gpg") {
+			t.Errorf("error = %v, want it to name the keyring", err)
+		}
+	})
+
+	t.Run("replaced keyring", func(t *testing.T) {
+		manager := newManager(t)
+
+		// A valid key that is not the embedded one
+		entity, err := openpgp.NewEntity("Someone Else", "", "someone@example.com", nil)
+		if err != nil {
+			t.Fatalf("failed to create key: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := entity.Serialize(&buf); err != nil {
+			t.Fatalf("failed to serialize key: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(manager.keyringDir, "mise.gpg"), buf.Bytes(), 0644); err != nil {
+			t.Fatalf("failed to replace keyring: %v", err)
+		}
+
+		err = manager.VerifyKeyrings()
+		if !errors.Is(err, ErrKeyringMismatch) {
+			t.Fatalf("VerifyKeyrings() error = %v, want ErrKeyringMismatch", err)
+		}
+		if !strings.Contains(err.Error(), "want "+miseKeyFingerprint) {
+			t.Errorf("error = %v, want it to show the expected fingerprint", err)
+		}
+	})
+
+	t.Run("corrupted cosign key", func(t *testing.T) {
+		manager := newManager(t)
+		path := filepath.Join(manager.keyringDir, "chezmoi.pub")
+		corrupted := bytes.Replace(chezmoiCosignKey, []byte("MFkw"), []byte("MFkx"), 1)
+		if err := os.WriteFile(path, corrupted, 0644); err != nil {
+			t.Fatalf("failed to corrupt key: %v", err)
+		}
+
+		err := manager.VerifyKeyrings()
+		if !errors.Is(err, ErrKeyringMismatch) {
+			t.Fatalf("VerifyKeyrings() error = %v, want ErrKeyringMismatch", err)
+		}
+		if !strings.Contains(err.Error(), "chezmoi.pub") {
+			t.Errorf("error = %v, want it to name the key", err)
+		}
+	})
+
+	t.Run("missing keyring", func(t *testing.T) {
+		manager := newManager(t)
+		if err := os.Remove(filepath.Join(manager.keyringDir, "mise.gpg")); err != nil {
+			t.Fatalf("failed to remove keyring: %v", err)
+		}
+		if err := manager.VerifyKeyrings(); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("VerifyKeyrings() error = %v, want not exist", err)
+		}
+	})
+}
+
+func TestEmbeddedKeyFingerprints(t *testing.T) {
+	// The pinned fingerprints must be updated together with the embedded keys
+	got, err := gpgFingerprint(miseKeyring)
+	if err != nil {
+		t.Fatalf("gpgFingerprint() error = %v", err)
+	}
+	if got != miseKeyFingerprint {
+		t.Errorf("embedded GPG key fingerprint = %s, want %s", got, miseKeyFingerprint)
+	}
+
+	got, err = cosignKeyFingerprint(chezmoiCosignKey)
+	if err != nil {
+		t.Fatalf("cosignKeyFingerprint() error = %v", err)
+	}
+	if got != chezmoiCosignKeyFingerprint {
+		t.Errorf("embedded cosign key fingerprint = %s, want %s", got, chezmoiCosignKeyFingerprint)
+	}
+}
+
 func TestManagerEnsureKeyrings(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -281,6 +482,296 @@ func TestManagerDownload(t *testing.T) {
 	}
 }
 
+func TestManagerDownload_FetchesConcurrently(t *testing.T) {
+	// Each request waits until all three files have been requested, so a
+	// sequential download would time out
+	var mu sync.Mutex
+	arrived := 0
+	allArrived := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		arrived++
+		if arrived == 3 {
+			close(allArrived)
+		}
+		mu.Unlock()
+
+		select {
+		case <-allArrived:
+			_, _ = w.Write([]byte("not genuine"))
+		case <-time.After(5 * time.Second):
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+
+	manager, err := NewManager(Config{ZerbDir: t.TempDir(), PlatformInfo: &platform.Info{OS: "linux", Arch: "amd64"}})
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	manager.WithClock(clock.NewFake(time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)))
+	manager.downloader.retries = 0
+	transport := &redirectTransport{target: target}
+	manager.downloader.client.Transport = transport
+	if err := manager.EnsureKeyrings(); err != nil {
+		t.Fatalf("EnsureKeyrings failed: %v", err)
+	}
+
+	// Verification of the bogus files fails, after all three were fetched
+	_, err = manager.Download(context.Background(), DownloadOptions{Binary: BinaryMise})
+	if err == nil || !strings.Contains(err.Error(), "verif") {
+		t.Fatalf("Download() error = %v, want a verification failure", err)
+	}
+	if len(transport.paths) != 3 {
+		t.Fatalf("requested %v, want the archive, signature and checksums", transport.paths)
+	}
+
+	// The unverified files were quarantined, not cached: a second download
+	// fetches them again
+	transport.paths = nil
+	if _, err := manager.Download(context.Background(), DownloadOptions{Binary: BinaryMise}); err == nil {
+		t.Fatal("second Download() should fail verification again")
+	}
+	if len(transport.paths) != 3 {
+		t.Errorf("second Download() requested %v, want all three files again", transport.paths)
+	}
+}
+
+func TestManagerDownload_FailureCancelsOtherRequests(t *testing.T) {
+	archiveCancelled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, ".tar.gz") {
+			// Stall until the client gives up on the request
+			select {
+			case <-r.Context().Done():
+				close(archiveCancelled)
+			case <-time.After(10 * time.Second):
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+
+	manager, err := NewManager(Config{ZerbDir: t.TempDir(), PlatformInfo: &platform.Info{OS: "linux", Arch: "amd64"}})
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	manager.downloader.client.Transport = &redirectTransport{target: target}
+
+	start := time.Now()
+	_, err = manager.Download(context.Background(), DownloadOptions{Binary: BinaryMise})
+	if !errors.Is(err, ErrAssetNotFound) {
+		t.Fatalf("Download() error = %v, want ErrAssetNotFound", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Download() took %v; the stalled archive request was not cancelled", elapsed)
+	}
+	select {
+	case <-archiveCancelled:
+	case <-time.After(5 * time.Second):
+		t.Error("archive request was not cancelled")
+	}
+}
+
+func TestManagerDownload_InsecureSkipVerify(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      string
+		devBuild bool
+		wantSkip bool
+	}{
+		{name: "env and dev build", env: "1", devBuild: true, wantSkip: true},
+		{name: "env in release build", env: "1", devBuild: false, wantSkip: false},
+		{name: "dev build without env", env: "", devBuild: true, wantSkip: false},
+		{name: "env not exactly 1", env: "true", devBuild: true, wantSkip: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvInsecureSkipVerify, tt.env)
+			tmpDir := t.TempDir()
+
+			manager, err := NewManager(Config{
+				ZerbDir:      tmpDir,
+				PlatformInfo: &platform.Info{OS: "linux", Arch: "amd64"},
+			})
+			if err != nil {
+				t.Fatalf("NewManager failed: %v", err)
+			}
+			var stderr bytes.Buffer
+			manager.WithStderr(&stderr).WithClock(clock.NewFake(time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)))
+			manager.devBuild = tt.devBuild
+			if err := manager.EnsureKeyrings(); err != nil {
+				t.Fatalf("EnsureKeyrings failed: %v", err)
+			}
+
+			// Seed the download cache with an archive and bogus signature
+			// and checksums, so no network access is needed
+			info, err := constructDownloadInfo(BinaryMise, DefaultVersions.Mise, manager.platformInfo)
+			if err != nil {
+				t.Fatalf("constructDownloadInfo failed: %v", err)
+			}
+			cacheDir := filepath.Join(tmpDir, "cache", "downloads", "mise", DefaultVersions.Mise)
+			if err := os.MkdirAll(cacheDir, 0755); err != nil {
+				t.Fatalf("failed to create cache dir: %v", err)
+			}
+			for _, url := range []string{info.URL, info.SignatureURL, info.ChecksumURL} {
+				if err := os.WriteFile(filepath.Join(cacheDir, filepath.Base(url)), []byte("not genuine"), 0644); err != nil {
+					t.Fatalf("failed to seed cache: %v", err)
+				}
+			}
+
+			result, err := manager.Download(context.Background(), DownloadOptions{Binary: BinaryMise})
+			logContent, _ := os.ReadFile(audit.Path(tmpDir))
+
+			if !tt.wantSkip {
+				if err == nil {
+					t.Fatal("Download() succeeded for an unverifiable binary; verification was skipped")
+				}
+				if len(logContent) > 0 {
+					t.Errorf("audit log written without skipping verification:\n%s", logContent)
+				}
+				if strings.Contains(stderr.String(), "VERIFICATION DISABLED") {
+					t.Errorf("skip warning printed without skipping verification:\n%s", stderr.String())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Download() error = %v", err)
+			}
+			if result.Verified != VerificationNone {
+				t.Errorf("Verified = %v, want None", result.Verified)
+			}
+			if !strings.Contains(stderr.String(), "BINARY VERIFICATION DISABLED") {
+				t.Errorf("stderr = %q, want a skip warning", stderr.String())
+			}
+			if !strings.Contains(stderr.String(), "The tool manager "+DefaultVersions.Mise+" is NOT") || strings.Contains(stderr.String(), "mise") {
+				t.Errorf("stderr = %q, want the binary named by its label", stderr.String())
+			}
+			wantLog := "2025-01-15T10:30:00Z insecure-skip-verify mise " + DefaultVersions.Mise + " " + result.Path + "\n"
+			if string(logContent) != wantLog {
+				t.Errorf("audit log = %q, want %q", logContent, wantLog)
+			}
+		})
+	}
+}
+
+func TestManagerInstall_QuarantinesUnverified(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager, err := NewManager(Config{ZerbDir: tmpDir, PlatformInfo: &platform.Info{OS: "linux", Arch: "amd64"}})
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	manager.WithClock(clock.NewFake(time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)))
+	if err := manager.EnsureKeyrings(); err != nil {
+		t.Fatalf("EnsureKeyrings failed: %v", err)
+	}
+
+	// Seed the download cache with files that fail GPG verification
+	info, err := constructDownloadInfo(BinaryMise, DefaultVersions.Mise, manager.platformInfo)
+	if err != nil {
+		t.Fatalf("constructDownloadInfo failed: %v", err)
+	}
+	cacheDir := filepath.Join(tmpDir, "cache", "downloads", "mise", DefaultVersions.Mise)
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		t.Fatalf("failed to create cache dir: %v", err)
+	}
+	urls := []string{info.URL, info.SignatureURL, info.ChecksumURL}
+	for _, url := range urls {
+		if err := os.WriteFile(filepath.Join(cacheDir, filepath.Base(url)), []byte("tampered"), 0644); err != nil {
+			t.Fatalf("failed to seed cache: %v", err)
+		}
+	}
+
+	err = manager.Install(context.Background(), DownloadOptions{Binary: BinaryMise})
+	quarantined := filepath.Join(tmpDir, "cache", "quarantine", "mise-"+DefaultVersions.Mise+"-20250115T103000.000Z")
+	if err == nil || !strings.Contains(err.Error(), quarantined) {
+		t.Fatalf("Install() error = %v, want it to point to %s", err, quarantined)
+	}
+
+	// Nothing unverified reaches bin/ or stays in the cache
+	if installed, _ := manager.IsInstalled(BinaryMise); installed {
+		t.Error("unverified binary was installed")
+	}
+	for _, url := range urls {
+		name := filepath.Base(url)
+		if _, err := os.Stat(filepath.Join(cacheDir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s left in the download cache", name)
+		}
+		if _, err := os.Stat(filepath.Join(quarantined, name)); err != nil {
+			t.Errorf("%s not quarantined: %v", name, err)
+		}
+	}
+
+	reason, err := os.ReadFile(filepath.Join(quarantined, filepath.Base(info.URL)+".reason"))
+	if err != nil {
+		t.Fatalf("failed to read reason file: %v", err)
+	}
+	for _, want := range []string{"binary: mise", "method: GPG", "url: " + info.URL, "error: "} {
+		if !strings.Contains(string(reason), want) {
+			t.Errorf("reason file missing %q:\n%s", want, reason)
+		}
+	}
+
+	if err := manager.ClearQuarantine(); err != nil {
+		t.Fatalf("ClearQuarantine() error = %v", err)
+	}
+	if _, err := os.Stat(manager.QuarantineDir()); !os.IsNotExist(err) {
+		t.Errorf("quarantine dir still exists after ClearQuarantine(): %v", err)
+	}
+}
+
+func TestQuarantineReason_ChecksumMismatch(t *testing.T) {
+	info := &DownloadInfo{Binary: BinaryChezmoi, Version: "2.46.1", URL: "https://example.com/chezmoi.tar.gz"}
+	verifyErr := &VerificationError{
+		Method: VerificationSHA256,
+		Err:    fmt.Errorf("SHA256 verification failed for chezmoi: %w", &ChecksumMismatchError{Expected: "aaaa", Actual: "bbbb"}),
+	}
+
+	reason := quarantineReason(info, verifyErr, time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC), []string{"chezmoi.tar.gz"})
+	want := `binary: chezmoi
+version: 2.46.1
+url: https://example.com/chezmoi.tar.gz
+time: 2025-01-15T10:30:00Z
+method: SHA256
+expected sha256: aaaa
+actual sha256: bbbb
+files: chezmoi.tar.gz
+error: SHA256 verification failed for chezmoi: checksum mismatch: got bbbb, want aaaa
+`
+	if reason != want {
+		t.Errorf("quarantineReason() =\n%s\nwant:\n%s", reason, want)
+	}
+}
+
+func TestNewManager_ReleaseBuildIgnoresInsecureSkipVerify(t *testing.T) {
+	if devBuild {
+		t.Skip("development build")
+	}
+	t.Setenv(EnvInsecureSkipVerify, "1")
+
+	manager, err := NewManager(Config{
+		ZerbDir:      t.TempDir(),
+		PlatformInfo: &platform.Info{OS: "linux", Arch: "amd64"},
+	})
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	var stderr bytes.Buffer
+	manager.WithStderr(&stderr)
+
+	if manager.skipVerify() {
+		t.Error("skipVerify() = true in a release build")
+	}
+	if !strings.Contains(stderr.String(), "ignored in release builds") {
+		t.Errorf("stderr = %q, want a note that the variable is ignored", stderr.String())
+	}
+}
+
 func TestManagerGetBinaryPath(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -355,6 +846,26 @@ func TestManagerInstall_SkipIfAlreadyInstalled(t *testing.T) {
 	}
 }
 
+func TestManagerInstallWithResult(t *testing.T) {
+	m := newUpdateTestManager(t)
+	seedMiseRelease(t, m, "2024.12.8", "2024.12.8")
+	ctx := context.Background()
+
+	result, err := m.InstallWithResult(ctx, DownloadOptions{Binary: BinaryMise, Version: "2024.12.8"})
+	if err != nil {
+		t.Fatalf("InstallWithResult() error = %v", err)
+	}
+	if result == nil || result.Version != "2024.12.8" || result.Verified != VerificationNone {
+		t.Errorf("InstallWithResult() = %+v, want version 2024.12.8 verified by None", result)
+	}
+
+	// Nothing is downloaded for an installed binary
+	result, err = m.InstallWithResult(ctx, DownloadOptions{Binary: BinaryMise, Version: "2024.12.8"})
+	if err != nil || result != nil {
+		t.Errorf("second InstallWithResult() = %+v, %v, want nil, nil", result, err)
+	}
+}
+
 func TestManagerConfig_DirectoryStructure(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -0,0 +1,39 @@
+package binary
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrBinaryMissing is returned when a core component ZERB runs is not
+// installed or not executable, e.g. after its binary was deleted from the
+// bin directory.
+var ErrBinaryMissing = errors.New("core component missing")
+
+// Label returns the user-facing name of the binary, which never names the
+// underlying tool.
+func (b Binary) Label() string {
+	switch b {
+	case BinaryMise:
+		return "tool manager"
+	case BinaryChezmoi:
+		return "configuration manager"
+	default:
+		return "core component"
+	}
+}
+
+// CheckExecutable checks that path, the installed location of binary, is an
+// executable regular file. Otherwise it returns an error wrapping
+// ErrBinaryMissing that tells the user how to restore it.
+func CheckExecutable(path string, binary Binary) error {
+	info, err := os.Stat(path)
+	if err == nil && info.Mode().IsRegular() && info.Mode().Perm()&0111 != 0 {
+		return nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("%w: cannot access the %s\nRun 'zerb init --reinstall' to restore it", ErrBinaryMissing, binary.Label())
+	}
+	return fmt.Errorf("%w: the %s is not installed or not executable\nRun 'zerb init --reinstall' to restore it", ErrBinaryMissing, binary.Label())
+}
@@ -0,0 +1,54 @@
+package binary
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckExecutable(t *testing.T) {
+	dir := t.TempDir()
+	executable := filepath.Join(dir, "mise")
+	if err := os.WriteFile(executable, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	notExecutable := filepath.Join(dir, "chezmoi")
+	if err := os.WriteFile(notExecutable, []byte("#!/bin/sh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{"executable", executable, false},
+		{"missing", filepath.Join(dir, "deleted"), true},
+		{"not executable", notExecutable, true},
+		{"directory", dir, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckExecutable(tt.path, BinaryMise)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("CheckExecutable() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrBinaryMissing) {
+				t.Fatalf("CheckExecutable() error = %v, want ErrBinaryMissing", err)
+			}
+			msg := err.Error()
+			if !strings.Contains(msg, "zerb init --reinstall") || !strings.Contains(msg, "tool manager") {
+				t.Errorf("error %q should name the tool manager and suggest zerb init --reinstall", msg)
+			}
+			if strings.Contains(msg, "mise") {
+				t.Errorf("error %q mentions the underlying binary", msg)
+			}
+		})
+	}
+}
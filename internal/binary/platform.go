@@ -17,6 +17,7 @@ func constructDownloadInfo(binary Binary, version string, platformInfo *platform
 		Version: version,
 		OS:      platformInfo.OS,
 		Arch:    platformInfo.Arch,
+		Libc:    detectLibc(platformInfo),
 	}
 
 	switch binary {
@@ -29,8 +30,30 @@ func constructDownloadInfo(binary Binary, version string, platformInfo *platform
 	}
 }
 
+// Libc variants of Linux release assets
+const (
+	libcGNU  = "gnu"
+	libcMusl = "musl"
+)
+
+// detectLibc returns the C library of a Linux platform: musl if detected
+// or on distros built on it, glibc otherwise. Returns "" for other
+// operating systems. glibc builds of the binaries crash on musl systems.
+func detectLibc(platformInfo *platform.Info) string {
+	if platformInfo.OS != "linux" {
+		return ""
+	}
+	if platformInfo.IsMusl() {
+		return libcMusl
+	}
+	if distro := platformInfo.GetDistro(); distro != nil && distro.Family == platform.FamilyAlpine {
+		return libcMusl
+	}
+	return libcGNU
+}
+
 // constructMiseDownloadInfo constructs mise download URLs
-// Pattern: https://github.com/jdx/mise/releases/download/v{version}/mise-v{version}-{os}-{arch}.tar.gz
+// Pattern: https://github.com/jdx/mise/releases/download/v{version}/mise-v{version}-{os}-{arch}[-musl].tar.gz
 func constructMiseDownloadInfo(info *DownloadInfo, version string) (*DownloadInfo, error) {
 	// Map Go arch to mise arch naming
 	archName, err := mapMiseArch(info.Arch)
@@ -46,12 +69,16 @@ func constructMiseDownloadInfo(info *DownloadInfo, version string) (*DownloadInf
 
 	baseURL := fmt.Sprintf("https://github.com/jdx/mise/releases/download/v%s", version)
 	binaryName := fmt.Sprintf("mise-v%s-%s-%s.tar.gz", version, osName, archName)
+	if info.Libc == libcMusl {
+		binaryName = fmt.Sprintf("mise-v%s-%s-%s-musl.tar.gz", version, osName, archName)
+	}
 
 	info.URL = fmt.Sprintf("%s/%s", baseURL, binaryName)
 	// mise provides a GPG-signed checksums file (SHASUMS256.txt + SHASUMS256.asc)
 	// Verify the checksums file with GPG, then verify the binary's SHA256.
 	info.SignatureURL = fmt.Sprintf("%s/SHASUMS256.asc", baseURL)
 	info.ChecksumURL = fmt.Sprintf("%s/SHASUMS256.txt", baseURL)
+	// No keyless cosign bundle is published, so WithSigstore has no effect
 	info.BundleURL = ""
 	info.BinaryFilename = binaryName
 
@@ -60,7 +87,7 @@ func constructMiseDownloadInfo(info *DownloadInfo, version string) (*DownloadInf
 
 // constructChezmoiDownloadInfo constructs chezmoi download URLs
 // Pattern: https://github.com/twpayne/chezmoi/releases/download/v{version}/chezmoi_{version}_{os}_{arch}.tar.gz
-// Note: Linux builds use "-glibc" suffix (e.g., linux-glibc_amd64)
+// Note: Linux builds use a libc suffix (e.g., linux-glibc_amd64, linux-musl_amd64)
 func constructChezmoiDownloadInfo(info *DownloadInfo, version string) (*DownloadInfo, error) {
 	// Map Go arch to chezmoi arch naming
 	archName, err := mapChezmoiArch(info.Arch)
@@ -74,9 +101,12 @@ func constructChezmoiDownloadInfo(info *DownloadInfo, version string) (*Download
 		return nil, err
 	}
 
-	// Linux uses "-glibc" suffix for compatibility (chezmoi also has -musl variant)
+	// Linux assets are built per libc
 	if info.OS == "linux" {
 		osName = "linux-glibc"
+		if info.Libc == libcMusl {
+			osName = "linux-musl"
+		}
 	}
 
 	baseURL := fmt.Sprintf("https://github.com/twpayne/chezmoi/releases/download/v%s", version)
@@ -88,7 +118,10 @@ func constructChezmoiDownloadInfo(info *DownloadInfo, version string) (*Download
 	// The signature signs the checksums file, not the binary directly
 	info.SignatureURL = fmt.Sprintf("%s/chezmoi_%s_checksums.txt.sig", baseURL, version)
 	info.ChecksumURL = fmt.Sprintf("%s/chezmoi_%s_checksums.txt", baseURL, version)
-	info.BundleURL = "" // Not used for key-based cosign
+	// Key-based cosign publishes no keyless bundle, so WithSigstore has no
+	// effect
+	info.BundleURL = ""
+	info.BinaryFilename = binaryName
 
 	return info, nil
 }
@@ -116,8 +149,10 @@ func mapMiseOS(goos string) (string, error) {
 		return "linux", nil
 	case "darwin":
 		return "darwin", nil
+	case "freebsd":
+		return "freebsd", nil
 	default:
-		return "", fmt.Errorf("unsupported OS for mise: %s (MVP supports Linux only)", goos)
+		return "", fmt.Errorf("unsupported OS for mise: %s (supported: linux, darwin, freebsd)", goos)
 	}
 }
 
@@ -144,7 +179,9 @@ func mapChezmoiOS(goos string) (string, error) {
 		return "linux", nil
 	case "darwin":
 		return "darwin", nil
+	case "freebsd":
+		return "freebsd", nil
 	default:
-		return "", fmt.Errorf("unsupported OS for chezmoi: %s (MVP supports Linux only)", goos)
+		return "", fmt.Errorf("unsupported OS for chezmoi: %s (supported: linux, darwin, freebsd)", goos)
 	}
 }
@@ -1,6 +1,7 @@
 package binary
 
 import (
+	"path"
 	"testing"
 
 	"github.com/ZebulonRouseFrantzich/zerb/internal/platform"
@@ -12,6 +13,7 @@ func TestConstructMiseDownloadInfo(t *testing.T) {
 		version     string
 		os          string
 		arch        string
+		libc        string
 		expectedURL string
 		expectedSig string
 		expectedSum string
@@ -47,6 +49,27 @@ func TestConstructMiseDownloadInfo(t *testing.T) {
 			expectedSum: "https://github.com/jdx/mise/releases/download/v2024.12.7/SHASUMS256.txt",
 			wantErr:     false,
 		},
+		{
+			name:        "linux_musl_amd64",
+			version:     "2024.12.7",
+			os:          "linux",
+			arch:        "amd64",
+			libc:        "musl",
+			expectedURL: "https://github.com/jdx/mise/releases/download/v2024.12.7/mise-v2024.12.7-linux-x64-musl.tar.gz",
+			expectedSig: "https://github.com/jdx/mise/releases/download/v2024.12.7/SHASUMS256.asc",
+			expectedSum: "https://github.com/jdx/mise/releases/download/v2024.12.7/SHASUMS256.txt",
+			wantErr:     false,
+		},
+		{
+			name:        "freebsd_amd64",
+			version:     "2024.12.7",
+			os:          "freebsd",
+			arch:        "amd64",
+			expectedURL: "https://github.com/jdx/mise/releases/download/v2024.12.7/mise-v2024.12.7-freebsd-x64.tar.gz",
+			expectedSig: "https://github.com/jdx/mise/releases/download/v2024.12.7/SHASUMS256.asc",
+			expectedSum: "https://github.com/jdx/mise/releases/download/v2024.12.7/SHASUMS256.txt",
+			wantErr:     false,
+		},
 		{
 			name:    "unsupported_arch",
 			version: "2024.12.7",
@@ -70,6 +93,7 @@ func TestConstructMiseDownloadInfo(t *testing.T) {
 				Version: tt.version,
 				OS:      tt.os,
 				Arch:    tt.arch,
+				Libc:    tt.libc,
 			}
 
 			result, err := constructMiseDownloadInfo(info, tt.version)
@@ -95,6 +119,9 @@ func TestConstructMiseDownloadInfo(t *testing.T) {
 			if result.ChecksumURL != tt.expectedSum {
 				t.Errorf("ChecksumURL mismatch:\ngot:  %s\nwant: %s", result.ChecksumURL, tt.expectedSum)
 			}
+			if want := path.Base(tt.expectedURL); result.BinaryFilename != want {
+				t.Errorf("BinaryFilename = %q, want %q", result.BinaryFilename, want)
+			}
 		})
 	}
 }
@@ -105,6 +132,7 @@ func TestConstructChezmoiDownloadInfo(t *testing.T) {
 		version        string
 		os             string
 		arch           string
+		libc           string
 		expectedURL    string
 		expectedSig    string
 		expectedSum    string
@@ -144,6 +172,29 @@ func TestConstructChezmoiDownloadInfo(t *testing.T) {
 			expectedBundle: "",
 			wantErr:        false,
 		},
+		{
+			name:           "linux_musl_amd64",
+			version:        "2.46.1",
+			os:             "linux",
+			arch:           "amd64",
+			libc:           "musl",
+			expectedURL:    "https://github.com/twpayne/chezmoi/releases/download/v2.46.1/chezmoi_2.46.1_linux-musl_amd64.tar.gz",
+			expectedSig:    "https://github.com/twpayne/chezmoi/releases/download/v2.46.1/chezmoi_2.46.1_checksums.txt.sig",
+			expectedSum:    "https://github.com/twpayne/chezmoi/releases/download/v2.46.1/chezmoi_2.46.1_checksums.txt",
+			expectedBundle: "",
+			wantErr:        false,
+		},
+		{
+			name:           "freebsd_amd64",
+			version:        "2.46.1",
+			os:             "freebsd",
+			arch:           "amd64",
+			expectedURL:    "https://github.com/twpayne/chezmoi/releases/download/v2.46.1/chezmoi_2.46.1_freebsd_amd64.tar.gz",
+			expectedSig:    "https://github.com/twpayne/chezmoi/releases/download/v2.46.1/chezmoi_2.46.1_checksums.txt.sig",
+			expectedSum:    "https://github.com/twpayne/chezmoi/releases/download/v2.46.1/chezmoi_2.46.1_checksums.txt",
+			expectedBundle: "",
+			wantErr:        false,
+		},
 		{
 			name:    "unsupported_arch",
 			version: "2.46.1",
@@ -154,7 +205,7 @@ func TestConstructChezmoiDownloadInfo(t *testing.T) {
 		{
 			name:    "unsupported_os",
 			version: "2.46.1",
-			os:      "freebsd",
+			os:      "windows",
 			arch:    "amd64",
 			wantErr: true,
 		},
@@ -167,6 +218,7 @@ func TestConstructChezmoiDownloadInfo(t *testing.T) {
 				Version: tt.version,
 				OS:      tt.os,
 				Arch:    tt.arch,
+				Libc:    tt.libc,
 			}
 
 			result, err := constructChezmoiDownloadInfo(info, tt.version)
@@ -193,6 +245,9 @@ func TestConstructChezmoiDownloadInfo(t *testing.T) {
 			if result.ChecksumURL != tt.expectedSum {
 				t.Errorf("ChecksumURL mismatch:\ngot:  %s\nwant: %s", result.ChecksumURL, tt.expectedSum)
 			}
+			if want := path.Base(tt.expectedURL); result.BinaryFilename != want {
+				t.Errorf("BinaryFilename = %q, want %q", result.BinaryFilename, want)
+			}
 
 			if result.BundleURL != tt.expectedBundle {
 				t.Errorf("BundleURL mismatch:\ngot:  %s\nwant: %s", result.BundleURL, tt.expectedBundle)
@@ -278,6 +333,61 @@ func TestConstructDownloadInfo(t *testing.T) {
 	}
 }
 
+func TestConstructDownloadInfo_Libc(t *testing.T) {
+	tests := []struct {
+		name         string
+		platformInfo *platform.Info
+		wantLibc     string
+		wantAsset    string
+	}{
+		{
+			name:         "alpine uses musl",
+			platformInfo: &platform.Info{OS: "linux", Arch: "amd64", Platform: "alpine", Family: platform.FamilyAlpine},
+			wantLibc:     "musl",
+			wantAsset:    "chezmoi_2.46.1_linux-musl_amd64.tar.gz",
+		},
+		{
+			name:         "debian uses glibc",
+			platformInfo: &platform.Info{OS: "linux", Arch: "amd64", Platform: "ubuntu", Family: "debian"},
+			wantLibc:     "gnu",
+			wantAsset:    "chezmoi_2.46.1_linux-glibc_amd64.tar.gz",
+		},
+		{
+			name:         "detected musl",
+			platformInfo: &platform.Info{OS: "linux", Arch: "amd64", Platform: "void", Family: platform.FamilyUnknown, Libc: platform.LibcMusl},
+			wantLibc:     "musl",
+			wantAsset:    "chezmoi_2.46.1_linux-musl_amd64.tar.gz",
+		},
+		{
+			name:         "unknown distro uses glibc",
+			platformInfo: &platform.Info{OS: "linux", Arch: "amd64"},
+			wantLibc:     "gnu",
+			wantAsset:    "chezmoi_2.46.1_linux-glibc_amd64.tar.gz",
+		},
+		{
+			name:         "freebsd has no libc variant",
+			platformInfo: &platform.Info{OS: "freebsd", Arch: "amd64"},
+			wantLibc:     "",
+			wantAsset:    "chezmoi_2.46.1_freebsd_amd64.tar.gz",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := constructDownloadInfo(BinaryChezmoi, "2.46.1", tt.platformInfo)
+			if err != nil {
+				t.Fatalf("constructDownloadInfo() error = %v", err)
+			}
+			if info.Libc != tt.wantLibc {
+				t.Errorf("Libc = %q, want %q", info.Libc, tt.wantLibc)
+			}
+			if info.BinaryFilename != tt.wantAsset {
+				t.Errorf("BinaryFilename = %q, want %q", info.BinaryFilename, tt.wantAsset)
+			}
+		})
+	}
+}
+
 func TestBinaryString(t *testing.T) {
 	tests := []struct {
 		binary   Binary
@@ -0,0 +1,88 @@
+package binary
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/fsutil"
+)
+
+// reasonSuffix names the file recording why a download was quarantined
+const reasonSuffix = ".reason"
+
+// quarantine moves the downloaded files of a binary that failed
+// verification into their own directory under cache/quarantine, with a
+// .reason file describing the failure. Returns the directory.
+//
+// Moving the files out of the download cache also means the next attempt
+// downloads them again instead of reusing them.
+func (m *Manager) quarantine(info *DownloadInfo, verifyErr error, paths ...string) (string, error) {
+	now := m.clock.Now().UTC()
+	dir := filepath.Join(m.quarantineDir,
+		fmt.Sprintf("%s-%s-%s", info.Binary, info.Version, now.Format("20060102T150405.000Z")))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("create quarantine dir: %w", err)
+	}
+
+	var moved []string
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		dest := filepath.Join(dir, filepath.Base(path))
+		if err := os.Rename(path, dest); err != nil {
+			return "", fmt.Errorf("move %s to quarantine: %w", filepath.Base(path), err)
+		}
+		moved = append(moved, filepath.Base(path))
+	}
+
+	reasonPath := filepath.Join(dir, filepath.Base(info.URL)+reasonSuffix)
+	if err := fsutil.WriteFileAtomic(reasonPath, []byte(quarantineReason(info, verifyErr, now, moved)), 0600); err != nil {
+		return "", fmt.Errorf("write quarantine reason: %w", err)
+	}
+
+	return dir, nil
+}
+
+// quarantineReason describes a verification failure for the .reason file
+func quarantineReason(info *DownloadInfo, verifyErr error, now time.Time, files []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "binary: %s\n", info.Binary)
+	fmt.Fprintf(&b, "version: %s\n", info.Version)
+	fmt.Fprintf(&b, "url: %s\n", info.URL)
+	fmt.Fprintf(&b, "time: %s\n", now.Format(time.RFC3339))
+
+	method := VerificationNone
+	var verr *VerificationError
+	if errors.As(verifyErr, &verr) {
+		method = verr.Method
+	}
+	fmt.Fprintf(&b, "method: %s\n", method)
+
+	var mismatch *ChecksumMismatchError
+	if errors.As(verifyErr, &mismatch) {
+		fmt.Fprintf(&b, "expected sha256: %s\n", mismatch.Expected)
+		fmt.Fprintf(&b, "actual sha256: %s\n", mismatch.Actual)
+	}
+
+	fmt.Fprintf(&b, "files: %s\n", strings.Join(files, ", "))
+	fmt.Fprintf(&b, "error: %v\n", verifyErr)
+	return b.String()
+}
+
+// QuarantineDir returns the directory holding quarantined downloads
+func (m *Manager) QuarantineDir() string {
+	return m.quarantineDir
+}
+
+// ClearQuarantine removes all quarantined downloads.
+func (m *Manager) ClearQuarantine() error {
+	if err := os.RemoveAll(m.quarantineDir); err != nil {
+		return fmt.Errorf("clear quarantine: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package binary
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+// Fulcio certificate extensions carrying the OIDC issuer of the signer.
+// The first holds the raw string (deprecated), the second a DER UTF8String.
+var (
+	oidFulcioIssuer   = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+	oidFulcioIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+)
+
+// CosignIdentity pins the signer of a keyless cosign bundle
+type CosignIdentity struct {
+	// Subject is the certificate identity, e.g. a release workflow URI.
+	// A trailing "*" matches any suffix, e.g. a release tag.
+	Subject string
+	// Issuer is the OIDC issuer that vouched for Subject
+	Issuer string
+}
+
+// matches reports whether a certificate subject and issuer belong to the
+// pinned identity
+func (id CosignIdentity) matches(subject, issuer string) bool {
+	if issuer != id.Issuer {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(id.Subject, "*"); ok {
+		return strings.HasPrefix(subject, prefix)
+	}
+	return subject == id.Subject
+}
+
+// SigstoreConfig configures keyless cosign bundle verification.
+// Verification is only attempted when the roots and the Rekor key are set
+// and an identity is pinned for the binary.
+type SigstoreConfig struct {
+	// Identities pins the expected signer per binary
+	Identities map[Binary]CosignIdentity
+	// FulcioRoots are the CA certificates that issue signing certificates
+	FulcioRoots *x509.CertPool
+	// RekorPublicKey verifies the transparency log's signed entry timestamp
+	RekorPublicKey crypto.PublicKey
+}
+
+// identity returns the pinned identity for binary, if bundles can be
+// verified for it
+func (c SigstoreConfig) identity(binary Binary) (CosignIdentity, bool) {
+	if c.FulcioRoots == nil || c.RekorPublicKey == nil {
+		return CosignIdentity{}, false
+	}
+	id, ok := c.Identities[binary]
+	return id, ok
+}
+
+// cosignBundle is the bundle written by `cosign sign-blob --bundle`
+type cosignBundle struct {
+	Base64Signature string `json:"base64Signature"`
+	Cert            string `json:"cert"` // base64-encoded PEM certificate
+	RekorBundle     *struct {
+		SignedEntryTimestamp string       `json:"SignedEntryTimestamp"`
+		Payload              rekorPayload `json:"Payload"`
+	} `json:"rekorBundle"`
+}
+
+// rekorPayload is the transparency log entry covered by the signed entry
+// timestamp. Fields are in canonical (sorted) JSON order, so marshaling it
+// reproduces the signed bytes.
+type rekorPayload struct {
+	Body           string `json:"body"`
+	IntegratedTime int64  `json:"integratedTime"`
+	LogID          string `json:"logID"`
+	LogIndex       int64  `json:"logIndex"`
+}
+
+// hashedRekord is the part of a Rekor hashedrekord entry body that ties
+// the entry to one signature over one artifact
+type hashedRekord struct {
+	Spec struct {
+		Data struct {
+			Hash struct {
+				Algorithm string `json:"algorithm"`
+				Value     string `json:"value"`
+			} `json:"hash"`
+		} `json:"data"`
+		Signature struct {
+			Content string `json:"content"`
+		} `json:"signature"`
+	} `json:"spec"`
+}
+
+// verifyCosignBundle verifies a keyless cosign bundle for the binary. The
+// bundle signs the checksums file when there is one (the binary's checksum
+// is then checked against it), otherwise the binary itself.
+func (v *Verifier) verifyCosignBundle(binaryPath, checksumPath, bundlePath string, identity CosignIdentity, info *DownloadInfo) (*VerificationResult, error) {
+	artifactPath := binaryPath
+	if checksumPath != "" {
+		artifactPath = checksumPath
+	}
+
+	if err := v.verifyBundleSignature(bundlePath, artifactPath, identity); err != nil {
+		return nil, fmt.Errorf("cosign bundle verification failed: %w", err)
+	}
+
+	if checksumPath != "" {
+		result, err := v.verifySHA256(binaryPath, checksumPath, info.assetName(binaryPath))
+		if err != nil {
+			return nil, fmt.Errorf("checksum verification failed after cosign: %w", err)
+		}
+		if !result.Success {
+			return nil, fmt.Errorf("checksum verification failed after cosign: %v", result.Error)
+		}
+	}
+
+	return &VerificationResult{Method: VerificationCosign, Success: true, Error: nil}, nil
+}
+
+// verifyBundleSignature checks a bundle against the artifact: the log entry
+// is signed by Rekor and records this signature and artifact, the
+// certificate chains to a Fulcio root at the time it was logged and names
+// the pinned identity, and the signature over the artifact is valid.
+func (v *Verifier) verifyBundleSignature(bundlePath, artifactPath string, identity CosignIdentity) error {
+	data, err := os.ReadFile(bundlePath)
+	if err != nil {
+		return fmt.Errorf("read bundle: %w", err)
+	}
+	var bundle cosignBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return fmt.Errorf("parse bundle: %w", err)
+	}
+	if bundle.RekorBundle == nil {
+		return fmt.Errorf("bundle has no transparency log entry")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(bundle.Base64Signature)
+	if err != nil {
+		return fmt.Errorf("decode signature: %w", err)
+	}
+	cert, err := parseBundleCert(bundle.Cert)
+	if err != nil {
+		return err
+	}
+	artifact, err := os.ReadFile(artifactPath)
+	if err != nil {
+		return fmt.Errorf("read artifact: %w", err)
+	}
+
+	// Step 1: The log entry is signed by Rekor
+	payload, err := json.Marshal(bundle.RekorBundle.Payload)
+	if err != nil {
+		return fmt.Errorf("encode log entry: %w", err)
+	}
+	set, err := base64.StdEncoding.DecodeString(bundle.RekorBundle.SignedEntryTimestamp)
+	if err != nil {
+		return fmt.Errorf("decode signed entry timestamp: %w", err)
+	}
+	rekor, err := signature.LoadVerifier(v.sigstore.RekorPublicKey, crypto.SHA256)
+	if err != nil {
+		return fmt.Errorf("load Rekor key: %w", err)
+	}
+	if err := rekor.VerifySignature(bytes.NewReader(set), bytes.NewReader(payload)); err != nil {
+		return fmt.Errorf("verify signed entry timestamp: %w", err)
+	}
+
+	// Step 2: The log entry records this signature over this artifact
+	if err := checkRekordBody(bundle.RekorBundle.Payload.Body, bundle.Base64Signature, artifact); err != nil {
+		return err
+	}
+
+	// Step 3: The short-lived certificate was valid when it was logged
+	integratedTime := time.Unix(bundle.RekorBundle.Payload.IntegratedTime, 0)
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:       v.sigstore.FulcioRoots,
+		CurrentTime: integratedTime,
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}); err != nil {
+		return fmt.Errorf("verify certificate chain: %w", err)
+	}
+
+	// Step 4: The certificate belongs to the pinned identity
+	subject, issuer := certIdentity(cert)
+	if !identity.matches(subject, issuer) {
+		return fmt.Errorf("certificate identity %q (issuer %q) does not match %q (issuer %q)",
+			subject, issuer, identity.Subject, identity.Issuer)
+	}
+
+	// Step 5: The signature over the artifact is valid
+	verifier, err := signature.LoadVerifier(cert.PublicKey, crypto.SHA256)
+	if err != nil {
+		return fmt.Errorf("load certificate key: %w", err)
+	}
+	if err := verifier.VerifySignature(bytes.NewReader(sig), bytes.NewReader(artifact)); err != nil {
+		return fmt.Errorf("verify signature: %w", err)
+	}
+
+	return nil
+}
+
+// parseBundleCert decodes the base64-encoded PEM certificate of a bundle
+func parseBundleCert(encoded string) (*x509.Certificate, error) {
+	pemData, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("decode certificate: %w", err)
+	}
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode certificate PEM block")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse certificate: %w", err)
+	}
+	return cert, nil
+}
+
+// checkRekordBody checks that a base64-encoded hashedrekord entry body
+// records sig and the SHA256 of artifact
+func checkRekordBody(body, sig string, artifact []byte) error {
+	data, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return fmt.Errorf("decode log entry body: %w", err)
+	}
+	var entry hashedRekord
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("parse log entry body: %w", err)
+	}
+
+	hash := entry.Spec.Data.Hash
+	digest := sha256.Sum256(artifact)
+	if hash.Algorithm != "sha256" || !strings.EqualFold(hash.Value, hex.EncodeToString(digest[:])) {
+		return fmt.Errorf("log entry is for a different artifact")
+	}
+	if entry.Spec.Signature.Content != sig {
+		return fmt.Errorf("log entry is for a different signature")
+	}
+	return nil
+}
+
+// certIdentity returns the subject (first URI or email SAN) and the OIDC
+// issuer of a Fulcio certificate
+func certIdentity(cert *x509.Certificate) (subject, issuer string) {
+	switch {
+	case len(cert.URIs) > 0:
+		subject = cert.URIs[0].String()
+	case len(cert.EmailAddresses) > 0:
+		subject = cert.EmailAddresses[0]
+	}
+
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(oidFulcioIssuerV2):
+			var value string
+			if _, err := asn1.UnmarshalWithParams(ext.Value, &value, "utf8"); err == nil {
+				return subject, value
+			}
+		case ext.Id.Equal(oidFulcioIssuer):
+			issuer = string(ext.Value)
+		}
+	}
+	return subject, issuer
+}
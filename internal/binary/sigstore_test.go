@@ -0,0 +1,285 @@
+package binary
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testWorkflow = "https://github.com/example/tool/.github/workflows/release.yml@refs/tags/v1.2.3"
+	testIssuer   = "https://token.actions.githubusercontent.com"
+)
+
+// testSigstore is a throwaway Fulcio CA and Rekor log for signing bundles
+type testSigstore struct {
+	caKey    *ecdsa.PrivateKey
+	caCert   *x509.Certificate
+	rekorKey *ecdsa.PrivateKey
+	config   SigstoreConfig
+}
+
+func newTestSigstore(t *testing.T) *testSigstore {
+	t.Helper()
+
+	caKey := mustECDSAKey(t)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test fulcio"},
+		NotBefore:             time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:              time.Date(2040, 1, 1, 0, 0, 0, 0, time.UTC),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %v", err)
+	}
+	caCert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse CA certificate: %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+	rekorKey := mustECDSAKey(t)
+
+	return &testSigstore{
+		caKey:    caKey,
+		caCert:   caCert,
+		rekorKey: rekorKey,
+		config: SigstoreConfig{
+			Identities: map[Binary]CosignIdentity{
+				BinaryChezmoi: {Subject: "https://github.com/example/tool/.github/workflows/release.yml@refs/tags/*", Issuer: testIssuer},
+			},
+			FulcioRoots:    roots,
+			RekorPublicKey: &rekorKey.PublicKey,
+		},
+	}
+}
+
+// bundleOptions tweaks the bundle signed by testSigstore.sign
+type bundleOptions struct {
+	subject        string
+	issuer         string
+	integratedTime time.Time
+}
+
+// sign returns a cosign bundle over artifact, as `cosign sign-blob --bundle`
+// would write it
+func (s *testSigstore) sign(t *testing.T, artifact []byte, opts bundleOptions) []byte {
+	t.Helper()
+
+	// Short-lived signing certificate for the workflow identity
+	signingKey := mustECDSAKey(t)
+	subject, err := url.Parse(opts.subject)
+	if err != nil {
+		t.Fatalf("invalid subject: %v", err)
+	}
+	issued := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		NotBefore:    issued,
+		NotAfter:     issued.Add(10 * time.Minute),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		URIs:         []*url.URL{subject},
+		ExtraExtensions: []pkix.Extension{
+			{Id: oidFulcioIssuer, Value: []byte(opts.issuer)},
+		},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, s.caCert, &signingKey.PublicKey, s.caKey)
+	if err != nil {
+		t.Fatalf("failed to create signing certificate: %v", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	sig := base64.StdEncoding.EncodeToString(mustSign(t, signingKey, artifact))
+
+	// Log entry recording the signature over the artifact
+	digest := sha256.Sum256(artifact)
+	body := fmt.Sprintf(`{"apiVersion":"0.0.1","kind":"hashedrekord","spec":{"data":{"hash":{"algorithm":"sha256","value":"%s"}},"signature":{"content":"%s"}}}`,
+		hex.EncodeToString(digest[:]), sig)
+	payload := rekorPayload{
+		Body:           base64.StdEncoding.EncodeToString([]byte(body)),
+		IntegratedTime: opts.integratedTime.Unix(),
+		LogID:          "c0d23d6ad406973f9559f3ba2d1ca01f84147d8ffc5b8445c224f98b9591801d",
+		LogIndex:       42,
+	}
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("failed to encode payload: %v", err)
+	}
+
+	bundle := map[string]any{
+		"base64Signature": sig,
+		"cert":            base64.StdEncoding.EncodeToString(certPEM),
+		"rekorBundle": map[string]any{
+			"SignedEntryTimestamp": base64.StdEncoding.EncodeToString(mustSign(t, s.rekorKey, payloadJSON)),
+			"Payload":              payload,
+		},
+	}
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatalf("failed to encode bundle: %v", err)
+	}
+	return data
+}
+
+func mustECDSAKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return key
+}
+
+func mustSign(t *testing.T, key *ecdsa.PrivateKey, data []byte) []byte {
+	t.Helper()
+	digest := sha256.Sum256(data)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	return sig
+}
+
+func TestVerifyFile_CosignBundle(t *testing.T) {
+	sigstore := newTestSigstore(t)
+	valid := bundleOptions{
+		subject:        testWorkflow,
+		issuer:         testIssuer,
+		integratedTime: time.Date(2025, 1, 1, 12, 5, 0, 0, time.UTC),
+	}
+
+	binaryData, err := os.ReadFile("testdata/test-binary")
+	if err != nil {
+		t.Fatalf("failed to read test binary: %v", err)
+	}
+	digest := sha256.Sum256(binaryData)
+	checksums := []byte(hex.EncodeToString(digest[:]) + "  test-binary\n")
+
+	tests := []struct {
+		name       string
+		config     SigstoreConfig
+		bundle     bundleOptions
+		tamper     func(checksums []byte) []byte // changes the checksums after signing
+		noBundle   bool
+		wantMethod VerificationMethod
+		wantErr    string
+	}{
+		{
+			name:       "valid bundle",
+			config:     sigstore.config,
+			bundle:     valid,
+			wantMethod: VerificationCosign,
+		},
+		{
+			name:    "wrong subject",
+			config:  sigstore.config,
+			bundle:  bundleOptions{subject: "https://github.com/attacker/tool/.github/workflows/release.yml@refs/tags/v1.2.3", issuer: testIssuer, integratedTime: valid.integratedTime},
+			wantErr: "does not match",
+		},
+		{
+			name:    "wrong issuer",
+			config:  sigstore.config,
+			bundle:  bundleOptions{subject: testWorkflow, issuer: "https://accounts.example.com", integratedTime: valid.integratedTime},
+			wantErr: "does not match",
+		},
+		{
+			name:    "logged after certificate expired",
+			config:  sigstore.config,
+			bundle:  bundleOptions{subject: testWorkflow, issuer: testIssuer, integratedTime: valid.integratedTime.Add(time.Hour)},
+			wantErr: "verify certificate chain",
+		},
+		{
+			name:   "tampered checksums",
+			config: sigstore.config,
+			bundle: valid,
+			tamper: func(checksums []byte) []byte {
+				return []byte(strings.Repeat("0", 64) + "  test-binary\n")
+			},
+			wantErr: "different artifact",
+		},
+		{
+			name: "untrusted log",
+			config: SigstoreConfig{
+				Identities:     sigstore.config.Identities,
+				FulcioRoots:    sigstore.config.FulcioRoots,
+				RekorPublicKey: &mustECDSAKey(t).PublicKey,
+			},
+			bundle:  valid,
+			wantErr: "signed entry timestamp",
+		},
+		{
+			name:       "no pinned identity falls back to SHA256",
+			config:     SigstoreConfig{FulcioRoots: sigstore.config.FulcioRoots, RekorPublicKey: sigstore.config.RekorPublicKey},
+			bundle:     valid,
+			wantMethod: VerificationSHA256,
+		},
+		{
+			name:       "no bundle falls back to SHA256",
+			config:     sigstore.config,
+			noBundle:   true,
+			wantMethod: VerificationSHA256,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+
+			checksumPath := filepath.Join(tmpDir, "checksums.txt")
+			bundle := sigstore.sign(t, checksums, tt.bundle)
+			written := checksums
+			if tt.tamper != nil {
+				written = tt.tamper(checksums)
+			}
+			if err := os.WriteFile(checksumPath, written, 0644); err != nil {
+				t.Fatalf("failed to write checksums: %v", err)
+			}
+
+			bundlePath := ""
+			if !tt.noBundle {
+				bundlePath = filepath.Join(tmpDir, "checksums.txt.bundle")
+				if err := os.WriteFile(bundlePath, bundle, 0644); err != nil {
+					t.Fatalf("failed to write bundle: %v", err)
+				}
+			}
+
+			verifier := NewVerifier(tmpDir).WithSigstore(tt.config)
+			info := &DownloadInfo{Binary: BinaryChezmoi, BinaryFilename: "test-binary"}
+			result, err := verifier.VerifyFile("testdata/test-binary", "", checksumPath, bundlePath, info)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("VerifyFile() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyFile() error = %v", err)
+			}
+			if !result.Success || result.Method != tt.wantMethod {
+				t.Errorf("VerifyFile() = %+v, want success with %v", result, tt.wantMethod)
+			}
+		})
+	}
+}
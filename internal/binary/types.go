@@ -1,6 +1,7 @@
 package binary
 
 import (
+	"path/filepath"
 	"time"
 )
 
@@ -40,6 +41,11 @@ type DownloadOptions struct {
 	SkipGPG bool
 	// UseMockDownload uses mock HTTP server (for testing only)
 	UseMockDownload bool
+	// Progress, if set, receives the archive download progress
+	Progress ProgressFunc
+	// LocalDir, if set, installs from release files already downloaded to
+	// this directory instead of fetching them. They are verified as usual.
+	LocalDir string
 }
 
 // VerificationMethod indicates how a binary was verified
@@ -85,15 +91,25 @@ type DownloadResult struct {
 type DownloadInfo struct {
 	Binary         Binary
 	Version        string
-	OS             string // "linux", "darwin", etc.
+	OS             string // "linux", "darwin", "freebsd"
 	Arch           string // "amd64", "arm64", etc.
+	Libc           string // "gnu" or "musl" on Linux, empty elsewhere
 	URL            string // Constructed download URL
 	SignatureURL   string // GPG signature URL (may be empty)
 	ChecksumURL    string // SHA256 checksum URL (may be empty)
-	BundleURL      string // Cosign bundle URL (may be empty)
+	BundleURL      string // Keyless cosign bundle URL; empty for the pinned releases
 	BinaryFilename string // Binary filename for checksum lookup (e.g., "mise-v2024.12.7-linux-x64.tar.gz")
 }
 
+// assetName returns the release asset name to look up in the checksums
+// file, falling back to the name of the downloaded file
+func (d *DownloadInfo) assetName(binaryPath string) string {
+	if d != nil && d.BinaryFilename != "" {
+		return d.BinaryFilename
+	}
+	return filepath.Base(binaryPath)
+}
+
 // VerificationResult contains the outcome of a verification attempt
 type VerificationResult struct {
 	Method  VerificationMethod
@@ -0,0 +1,160 @@
+package binary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ErrNoPreviousVersion is returned by Rollback when no binary was kept
+// by an earlier Update.
+var ErrNoPreviousVersion = errors.New("no previous version to roll back to")
+
+// previousSuffix names the copy of a binary that Update replaced
+const previousSuffix = ".prev"
+
+// Update replaces an installed binary with targetVersion (the default
+// version if empty). The new version is downloaded and verified like an
+// install and extracted next to the current binary. The current binary is
+// kept as bin/<name>.prev, the new one is renamed into place, and it must
+// then run and report targetVersion; otherwise the previous binary is
+// put back. A binary that is not installed yet is simply installed.
+func (m *Manager) Update(ctx context.Context, binary Binary, targetVersion string) error {
+	result, err := m.Download(ctx, DownloadOptions{Binary: binary, Version: targetVersion})
+	if err != nil {
+		return fmt.Errorf("download: %w", err)
+	}
+
+	if err := os.MkdirAll(m.binDir, 0755); err != nil {
+		return fmt.Errorf("create bin dir: %w", err)
+	}
+
+	// Extract next to the binary, so that swapping it in is a rename
+	tmp, err := os.CreateTemp(m.binDir, "."+binary.String()+".new-")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	_ = tmp.Close()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if err := m.extractor.ExtractBinary(result.Path, tmpPath, binary.String()); err != nil {
+		return fmt.Errorf("extract binary: %w", err)
+	}
+	if err := SetExecutable(tmpPath); err != nil {
+		return err
+	}
+
+	// Keep the current binary; it stays in place until the rename below
+	destPath := m.GetBinaryPath(binary)
+	prevPath := destPath + previousSuffix
+	installed, err := m.IsInstalled(binary)
+	if err != nil {
+		return fmt.Errorf("check if installed: %w", err)
+	}
+	if installed {
+		if err := linkOrCopy(destPath, prevPath); err != nil {
+			return fmt.Errorf("back up %s: %w", binary, err)
+		}
+	}
+
+	if err := os.Rename(tmpPath, destPath); err != nil {
+		return fmt.Errorf("install %s: %w", binary, err)
+	}
+
+	// The new binary must run and be the version that was downloaded
+	version, err := m.InstalledVersion(binary)
+	if err == nil && version != result.Version {
+		err = fmt.Errorf("%s reports version %s, want %s", binary, version, result.Version)
+	}
+	if err != nil {
+		m.logger.Error("updated binary failed verification", "component", binary.Label(), "version", result.Version, "error", err)
+		if !installed {
+			_ = os.Remove(destPath)
+			return fmt.Errorf("verify updated %s: %w", binary, err)
+		}
+		if restoreErr := os.Rename(prevPath, destPath); restoreErr != nil {
+			return fmt.Errorf("verify updated %s: %w (restoring the previous version failed: %v)", binary, err, restoreErr)
+		}
+		m.logger.Warn("previous version restored", "component", binary.Label())
+		return fmt.Errorf("verify updated %s: %w; the previous version was restored", binary, err)
+	}
+
+	m.replaceIntegrity(binary, result.Version, result.Verified, installed)
+	m.logger.Info("updated", "component", binary.Label(), "version", result.Version, "previous_kept", installed)
+	return nil
+}
+
+// Rollback restores the binary that the last Update replaced. The binary
+// it replaces is kept in turn, so a second Rollback undoes the first.
+func (m *Manager) Rollback(binary Binary) error {
+	destPath := m.GetBinaryPath(binary)
+	prevPath := destPath + previousSuffix
+
+	if _, err := os.Stat(prevPath); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w: %s", ErrNoPreviousVersion, binary)
+		}
+		return fmt.Errorf("stat previous %s: %w", binary, err)
+	}
+
+	installed, err := m.IsInstalled(binary)
+	if err != nil {
+		return fmt.Errorf("check if installed: %w", err)
+	}
+	if !installed {
+		if err := os.Rename(prevPath, destPath); err != nil {
+			return fmt.Errorf("restore %s: %w", binary, err)
+		}
+		m.swapIntegrity(binary)
+		return nil
+	}
+
+	// Swap through a third name so that bin/<name> always exists
+	swapPath := filepath.Join(m.binDir, "."+binary.String()+".swap")
+	if err := linkOrCopy(destPath, swapPath); err != nil {
+		return fmt.Errorf("back up %s: %w", binary, err)
+	}
+	if err := os.Rename(prevPath, destPath); err != nil {
+		_ = os.Remove(swapPath)
+		return fmt.Errorf("restore %s: %w", binary, err)
+	}
+	if err := os.Rename(swapPath, prevPath); err != nil {
+		m.swapIntegrity(binary)
+		return fmt.Errorf("keep replaced %s: %w", binary, err)
+	}
+	m.swapIntegrity(binary)
+	m.logger.Info("rolled back", "component", binary.Label())
+	return nil
+}
+
+// linkOrCopy makes dst a hard link to src, replacing dst, or an
+// executable copy where hard links are not supported
+func linkOrCopy(src, dst string) error {
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		_ = os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
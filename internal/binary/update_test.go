@@ -0,0 +1,148 @@
+package binary
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/platform"
+)
+
+// newUpdateTestManager returns a manager of a development build that
+// skips verification, so archives seeded into its download cache install
+// without network access
+func newUpdateTestManager(t *testing.T) *Manager {
+	t.Helper()
+	t.Setenv(EnvInsecureSkipVerify, "1")
+
+	manager, err := NewManager(Config{ZerbDir: t.TempDir(), PlatformInfo: &platform.Info{OS: "linux", Arch: "amd64"}})
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	manager.WithStderr(io.Discard)
+	manager.devBuild = true
+	return manager
+}
+
+// seedMiseRelease places a mise release archive in the download cache
+// whose binary reports reported as its version
+func seedMiseRelease(t *testing.T, m *Manager, version, reported string) {
+	t.Helper()
+	info, err := constructDownloadInfo(BinaryMise, version, m.platformInfo)
+	if err != nil {
+		t.Fatalf("constructDownloadInfo failed: %v", err)
+	}
+	dir := filepath.Join(m.cacheDir, "mise", version)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("failed to create cache dir: %v", err)
+	}
+	script := "#!/bin/sh\necho " + reported + " linux-x64\n"
+	if err := createTestArchiveWithFile(filepath.Join(dir, filepath.Base(info.URL)), "mise/bin/mise", script); err != nil {
+		t.Fatalf("failed to create archive: %v", err)
+	}
+}
+
+// installMise installs a mise binary reporting version
+func installMise(t *testing.T, m *Manager, version string) {
+	t.Helper()
+	if err := os.MkdirAll(m.binDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\necho " + version + " linux-x64\n"
+	if err := os.WriteFile(m.GetBinaryPath(BinaryMise), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// versionAt runs the binary at path and returns the version it reports
+func versionAt(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return strings.Fields(strings.TrimPrefix(string(data), "#!/bin/sh\necho "))[0]
+}
+
+func TestManagerUpdate(t *testing.T) {
+	m := newUpdateTestManager(t)
+	installMise(t, m, "2024.12.7")
+	seedMiseRelease(t, m, "2024.12.8", "2024.12.8")
+	binPath := m.GetBinaryPath(BinaryMise)
+
+	if err := m.Update(context.Background(), BinaryMise, "v2024.12.8"); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got, err := m.InstalledVersion(BinaryMise); err != nil || got != "2024.12.8" {
+		t.Errorf("InstalledVersion() = %q, %v, want 2024.12.8", got, err)
+	}
+	if got := versionAt(t, binPath+".prev"); got != "2024.12.7" {
+		t.Errorf("kept previous version %s, want 2024.12.7", got)
+	}
+
+	// Rollback swaps the two, so rolling back again undoes it
+	for _, want := range []string{"2024.12.7", "2024.12.8"} {
+		if err := m.Rollback(BinaryMise); err != nil {
+			t.Fatalf("Rollback() error = %v", err)
+		}
+		if got, err := m.InstalledVersion(BinaryMise); err != nil || got != want {
+			t.Errorf("InstalledVersion() after Rollback = %q, %v, want %s", got, err, want)
+		}
+	}
+
+	// No temporary files are left behind
+	entries, _ := os.ReadDir(m.binDir)
+	if len(entries) != 2 {
+		t.Errorf("bin directory has %d entries, want mise and mise.prev", len(entries))
+	}
+}
+
+func TestManagerUpdate_RestoresOnFailure(t *testing.T) {
+	m := newUpdateTestManager(t)
+	installMise(t, m, "2024.12.7")
+	// The archive holds a binary of another version than requested
+	seedMiseRelease(t, m, "2024.12.9", "2024.12.6")
+
+	err := m.Update(context.Background(), BinaryMise, "2024.12.9")
+	if err == nil || !strings.Contains(err.Error(), "previous version was restored") {
+		t.Fatalf("Update() error = %v, want the previous version restored", err)
+	}
+	if got, err := m.InstalledVersion(BinaryMise); err != nil || got != "2024.12.7" {
+		t.Errorf("InstalledVersion() = %q, %v, want 2024.12.7", got, err)
+	}
+	entries, _ := os.ReadDir(m.binDir)
+	if len(entries) != 1 {
+		t.Errorf("bin directory has %d entries after a failed update, want 1", len(entries))
+	}
+}
+
+func TestManagerUpdate_NotInstalled(t *testing.T) {
+	m := newUpdateTestManager(t)
+	seedMiseRelease(t, m, "2024.12.8", "2024.12.8")
+
+	if err := m.Update(context.Background(), BinaryMise, "2024.12.8"); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got, err := m.InstalledVersion(BinaryMise); err != nil || got != "2024.12.8" {
+		t.Errorf("InstalledVersion() = %q, %v, want 2024.12.8", got, err)
+	}
+	if err := m.Rollback(BinaryMise); !errors.Is(err, ErrNoPreviousVersion) {
+		t.Errorf("Rollback() error = %v, want ErrNoPreviousVersion", err)
+	}
+}
+
+func TestManagerUpdate_InvalidVersion(t *testing.T) {
+	m := newUpdateTestManager(t)
+	installMise(t, m, "2024.12.7")
+
+	if err := m.Update(context.Background(), BinaryMise, "latest"); !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("Update() error = %v, want ErrInvalidVersion", err)
+	}
+	if got := versionAt(t, m.GetBinaryPath(BinaryMise)); got != "2024.12.7" {
+		t.Errorf("binary changed to %s", got)
+	}
+}
@@ -25,6 +25,7 @@ import (
 type Verifier struct {
 	keyringDir string
 	skipGPG    bool // For testing only
+	sigstore   SigstoreConfig
 }
 
 // NewVerifier creates a new verifier
@@ -35,18 +36,44 @@ func NewVerifier(keyringDir string) *Verifier {
 	}
 }
 
+// WithSigstore configures keyless cosign bundle verification and returns
+// the verifier.
+func (v *Verifier) WithSigstore(cfg SigstoreConfig) *Verifier {
+	v.sigstore = cfg
+	return v
+}
+
 // VerifyFile verifies a downloaded binary file
-// It uses the appropriate verification method based on the binary type:
+// A keyless cosign bundle is preferred when bundlePath is provided and a
+// signer identity is pinned for the binary; a bundle that fails
+// verification is an error, not a reason to fall back. Otherwise it uses
+// the appropriate verification method based on the binary type:
 // - mise: REQUIRES GPG verification (no fallback)
-// - chezmoi: Uses cosign verification if bundlePath provided, falls back to SHA256
-func (v *Verifier) VerifyFile(binaryPath, signaturePath, checksumPath, bundlePath string, info *DownloadInfo) (*VerificationResult, error) {
+// - chezmoi: Uses key-based cosign verification if signaturePath provided, falls back to SHA256
+// Failures are returned as a *VerificationError naming the method attempted.
+func (v *Verifier) VerifyFile(binaryPath, signaturePath, checksumPath, bundlePath string, info *DownloadInfo) (_ *VerificationResult, err error) {
 	if info == nil {
 		return nil, fmt.Errorf("download info is required")
 	}
 
+	attempted := VerificationNone
+	defer func() {
+		if err != nil {
+			err = &VerificationError{Method: attempted, Err: err}
+		}
+	}()
+
+	if bundlePath != "" {
+		if identity, ok := v.sigstore.identity(info.Binary); ok {
+			attempted = VerificationCosign
+			return v.verifyCosignBundle(binaryPath, checksumPath, bundlePath, identity, info)
+		}
+	}
+
 	// Route verification based on binary type
 	switch info.Binary {
 	case BinaryMise:
+		attempted = VerificationGPG
 		// mise uses a clearsigned SHASUMS256.asc file containing both:
 		// - The checksums data (embedded)
 		// - The GPG signature
@@ -62,10 +89,7 @@ func (v *Verifier) VerifyFile(binaryPath, signaturePath, checksumPath, bundlePat
 		}
 
 		// Step 2: Find the checksum for our specific binary
-		binaryFilename := info.BinaryFilename
-		if binaryFilename == "" {
-			binaryFilename = filepath.Base(binaryPath)
-		}
+		binaryFilename := info.assetName(binaryPath)
 
 		expectedHash, err := findChecksumInData(checksums, binaryFilename)
 		if err != nil {
@@ -79,7 +103,7 @@ func (v *Verifier) VerifyFile(binaryPath, signaturePath, checksumPath, bundlePat
 		}
 
 		if !strings.EqualFold(actualHash, expectedHash) {
-			return nil, fmt.Errorf("checksum mismatch: got %s, want %s", actualHash, expectedHash)
+			return nil, &ChecksumMismatchError{Expected: expectedHash, Actual: actualHash}
 		}
 
 		// Success: GPG-signed checksums verified, binary hash matches
@@ -88,6 +112,7 @@ func (v *Verifier) VerifyFile(binaryPath, signaturePath, checksumPath, bundlePat
 	case BinaryChezmoi:
 		// chezmoi: Use cosign verification (key-based) with embedded public key
 		if signaturePath != "" && checksumPath != "" {
+			attempted = VerificationCosign
 			result, err := v.verifyCosign(binaryPath, signaturePath, checksumPath, info)
 			if err != nil {
 				return nil, fmt.Errorf("cosign verification failed for chezmoi: %w", err)
@@ -96,11 +121,12 @@ func (v *Verifier) VerifyFile(binaryPath, signaturePath, checksumPath, bundlePat
 		}
 
 		// Fallback to SHA256 if no signature (backward compatibility)
+		attempted = VerificationSHA256
 		if checksumPath == "" {
 			return nil, fmt.Errorf("checksum file required for chezmoi but not available")
 		}
 
-		result, err := v.verifySHA256(binaryPath, checksumPath)
+		result, err := v.verifySHA256(binaryPath, checksumPath, info.assetName(binaryPath))
 		if err != nil {
 			return nil, fmt.Errorf("SHA256 verification failed for chezmoi: %w", err)
 		}
@@ -116,6 +142,28 @@ func (v *Verifier) VerifyFile(binaryPath, signaturePath, checksumPath, bundlePat
 	}
 }
 
+// VerificationError is returned by VerifyFile when a binary fails
+// verification
+type VerificationError struct {
+	Method VerificationMethod // Method attempted (None if none applied)
+	Err    error
+}
+
+func (e *VerificationError) Error() string { return e.Err.Error() }
+
+func (e *VerificationError) Unwrap() error { return e.Err }
+
+// ChecksumMismatchError reports a binary whose SHA256 differs from the
+// checksum published for it
+type ChecksumMismatchError struct {
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("checksum mismatch: got %s, want %s", e.Actual, e.Expected)
+}
+
 // verifyGPG verifies a file using GPG signature
 // This handles both detached signatures and clearsigned messages
 func (v *Verifier) verifyGPG(binaryPath, signaturePath string, binary Binary) (*VerificationResult, error) {
@@ -233,7 +281,7 @@ func (v *Verifier) verifyGPG(binaryPath, signaturePath string, binary Binary) (*
 }
 
 // verifySHA256 verifies a file using SHA256 checksum
-func (v *Verifier) verifySHA256(binaryPath, checksumPath string) (*VerificationResult, error) {
+func (v *Verifier) verifySHA256(binaryPath, checksumPath, assetName string) (*VerificationResult, error) {
 	// Calculate SHA256 of binary
 	actualChecksum, err := calculateSHA256(binaryPath)
 	if err != nil {
@@ -245,7 +293,10 @@ func (v *Verifier) verifySHA256(binaryPath, checksumPath string) (*VerificationR
 	}
 
 	// Find expected checksum in checksum file
-	expectedChecksum, err := findChecksum(checksumPath, filepath.Base(binaryPath))
+	if assetName == "" {
+		assetName = filepath.Base(binaryPath)
+	}
+	expectedChecksum, err := findChecksum(checksumPath, assetName)
 	if err != nil {
 		return &VerificationResult{
 			Method:  VerificationSHA256,
@@ -261,7 +312,7 @@ func (v *Verifier) verifySHA256(binaryPath, checksumPath string) (*VerificationR
 			Success: false,
 			Error: fmt.Errorf("checksum mismatch:\nactual:   %s\nexpected: %s",
 				actualChecksum, expectedChecksum),
-		}, fmt.Errorf("checksum mismatch")
+		}, &ChecksumMismatchError{Expected: expectedChecksum, Actual: actualChecksum}
 	}
 
 	return &VerificationResult{
@@ -284,7 +335,7 @@ func (v *Verifier) verifyCosign(binaryPath, signaturePath, checksumPath string,
 	}
 
 	// Step 2: Now that checksums file is verified, check binary checksum
-	result, err := v.verifySHA256(binaryPath, checksumPath)
+	result, err := v.verifySHA256(binaryPath, checksumPath, info.assetName(binaryPath))
 	if err != nil || !result.Success {
 		return &VerificationResult{
 			Method:  VerificationCosign,
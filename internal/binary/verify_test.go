@@ -121,7 +121,7 @@ func TestVerifySHA256(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := verifier.verifySHA256(tt.binaryPath, tt.checksumPath)
+			result, err := verifier.verifySHA256(tt.binaryPath, tt.checksumPath, "")
 
 			if tt.wantSuccess {
 				if err != nil {
@@ -0,0 +1,82 @@
+package binary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrInvalidVersion is returned for a version string that is not in the
+// release format of the binary.
+var ErrInvalidVersion = errors.New("invalid version")
+
+// versionPatterns match the release versions of each binary: mise uses
+// calendar versions (2024.12.7), chezmoi semantic versions (2.46.1)
+var versionPatterns = map[Binary]*regexp.Regexp{
+	BinaryMise:    regexp.MustCompile(`\d{4}\.\d{1,2}\.\d+`),
+	BinaryChezmoi: regexp.MustCompile(`\d+\.\d+\.\d+`),
+}
+
+// installedVersionTimeout bounds running a binary to ask for its version
+const installedVersionTimeout = 5 * time.Second
+
+// NormalizeVersion validates a requested version of binary and returns it
+// without a leading "v" (release tags are v-prefixed, versions in asset
+// names are not). Any published version can be pinned this way; the check
+// only rejects strings that cannot be a release, before they reach a URL.
+func NormalizeVersion(binary Binary, version string) (string, error) {
+	pattern, ok := versionPatterns[binary]
+	if !ok {
+		return "", fmt.Errorf("unknown binary: %s", binary)
+	}
+
+	v := strings.TrimPrefix(version, "v")
+	if loc := pattern.FindStringIndex(v); loc == nil || loc[0] != 0 || loc[1] != len(v) {
+		return "", fmt.Errorf("%w for %s: %q (expected %s)", ErrInvalidVersion, binary, version, versionExample(binary))
+	}
+	return v, nil
+}
+
+// versionExample describes the version format of binary for error messages
+func versionExample(binary Binary) string {
+	if binary == BinaryMise {
+		return "a date version like 2024.12.7"
+	}
+	return "a version like v2.46.1"
+}
+
+// InstalledVersion runs the installed binary and returns the version it
+// reports, so callers can confirm that a pinned version was installed.
+func (m *Manager) InstalledVersion(binary Binary) (string, error) {
+	pattern, ok := versionPatterns[binary]
+	if !ok {
+		return "", fmt.Errorf("unknown binary: %s", binary)
+	}
+
+	installed, err := m.IsInstalled(binary)
+	if err != nil {
+		return "", err
+	}
+	if !installed {
+		return "", fmt.Errorf("binary %s is not installed", binary)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), installedVersionTimeout)
+	defer cancel()
+
+	//nolint:gosec // G204: The path is ZERB's own bin directory and the binary name is a known constant
+	output, err := exec.CommandContext(ctx, m.GetBinaryPath(binary), "--version").Output()
+	if err != nil {
+		return "", fmt.Errorf("run %s --version: %w", binary, err)
+	}
+
+	version := pattern.FindString(string(output))
+	if version == "" {
+		return "", fmt.Errorf("no version in %s --version output: %q", binary, strings.TrimSpace(string(output)))
+	}
+	return version, nil
+}
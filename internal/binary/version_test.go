@@ -0,0 +1,167 @@
+package binary
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/platform"
+)
+
+func TestNormalizeVersion(t *testing.T) {
+	tests := []struct {
+		binary  Binary
+		version string
+		want    string
+		wantErr bool
+	}{
+		{BinaryMise, "2024.12.7", "2024.12.7", false},
+		{BinaryMise, "v2023.1.0", "2023.1.0", false},
+		{BinaryMise, "2.46.1", "", true},
+		{BinaryMise, "2024.12", "", true},
+		{BinaryMise, "2024.12.7/../../evil", "", true},
+		{BinaryChezmoi, "2.46.1", "2.46.1", false},
+		{BinaryChezmoi, "v2.40.0", "2.40.0", false},
+		{BinaryChezmoi, "2.46", "", true},
+		{BinaryChezmoi, "latest", "", true},
+		{BinaryChezmoi, "", "", true},
+		{Binary("other"), "1.0.0", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.binary)+"@"+tt.version, func(t *testing.T) {
+			got, err := NormalizeVersion(tt.binary, tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeVersion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManagerInstalledVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		binary  Binary
+		output  string
+		want    string
+		wantErr bool
+	}{
+		{name: "mise", binary: BinaryMise, output: "2023.1.0 linux-x64 (2023-01-02)", want: "2023.1.0"},
+		{name: "chezmoi", binary: BinaryChezmoi, output: "chezmoi version v2.40.0, commit abc, built at 2023-09-01", want: "2.40.0"},
+		{name: "no version", binary: BinaryMise, output: "usage: mise", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager, err := NewManager(Config{ZerbDir: t.TempDir(), PlatformInfo: &platform.Info{OS: "linux", Arch: "amd64"}})
+			if err != nil {
+				t.Fatalf("failed to create manager: %v", err)
+			}
+
+			if _, err := manager.InstalledVersion(tt.binary); err == nil {
+				t.Error("InstalledVersion() should fail for a binary that is not installed")
+			}
+
+			binPath := manager.GetBinaryPath(tt.binary)
+			if err := os.MkdirAll(filepath.Dir(binPath), 0755); err != nil {
+				t.Fatalf("failed to create bin dir: %v", err)
+			}
+			script := "#!/bin/sh\necho '" + tt.output + "'\n"
+			if err := os.WriteFile(binPath, []byte(script), 0755); err != nil {
+				t.Fatalf("failed to write binary: %v", err)
+			}
+
+			got, err := manager.InstalledVersion(tt.binary)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InstalledVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("InstalledVersion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// redirectTransport sends every request to a test server, keeping the path
+type redirectTransport struct {
+	target *url.URL
+	mu     sync.Mutex
+	paths  []string
+}
+
+func (rt *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.mu.Lock()
+	rt.paths = append(rt.paths, req.URL.Path)
+	rt.mu.Unlock()
+	req = req.Clone(req.Context())
+	req.URL.Scheme = rt.target.Scheme
+	req.URL.Host = rt.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestManagerDownload_PinnedVersion(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+
+	manager, err := NewManager(Config{ZerbDir: t.TempDir(), PlatformInfo: &platform.Info{OS: "linux", Arch: "amd64"}})
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	transport := &redirectTransport{target: target}
+	manager.downloader.client.Transport = transport
+
+	t.Run("missing release fails without retry", func(t *testing.T) {
+		transport.paths = nil
+		err := manager.Install(context.Background(), DownloadOptions{Binary: BinaryMise, Version: "v2023.1.0"})
+		if !errors.Is(err, ErrAssetNotFound) {
+			t.Fatalf("Install() error = %v, want ErrAssetNotFound", err)
+		}
+		if !strings.Contains(err.Error(), "mise 2023.1.0 has no release for linux/amd64") {
+			t.Errorf("Install() error = %v, want the version in the message", err)
+		}
+		// The pinned version drives the URLs; the archive and its
+		// verification files are fetched concurrently, each only once
+		want := []string{
+			"/jdx/mise/releases/download/v2023.1.0/SHASUMS256.asc",
+			"/jdx/mise/releases/download/v2023.1.0/SHASUMS256.txt",
+			"/jdx/mise/releases/download/v2023.1.0/mise-v2023.1.0-linux-x64.tar.gz",
+		}
+		got := append([]string(nil), transport.paths...)
+		sort.Strings(got)
+		for _, path := range got {
+			if !slices.Contains(want, path) {
+				t.Errorf("requested %s, want only %v", path, want)
+			}
+		}
+		if len(slices.Compact(got)) != len(got) {
+			t.Errorf("requested %v, want each file at most once", got)
+		}
+		if installed, _ := manager.IsInstalled(BinaryMise); installed {
+			t.Error("nothing should be installed")
+		}
+	})
+
+	t.Run("invalid version is not requested", func(t *testing.T) {
+		transport.paths = nil
+		err := manager.Install(context.Background(), DownloadOptions{Binary: BinaryChezmoi, Version: "2.46"})
+		if !errors.Is(err, ErrInvalidVersion) {
+			t.Fatalf("Install() error = %v, want ErrInvalidVersion", err)
+		}
+		if len(transport.paths) != 0 {
+			t.Errorf("requested %v, want no requests", transport.paths)
+		}
+	})
+}
@@ -9,12 +9,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
 
+	"github.com/ZebulonRouseFrantzich/zerb/internal/binary"
 	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
 )
 
@@ -23,6 +25,9 @@ var (
 	ErrInvalidPath                = errors.New("invalid path")
 	ErrDirectoryRequiresRecursive = errors.New("directory requires --recursive flag")
 	ErrChezmoiInvocation          = errors.New("failed to add configuration file")
+	ErrForgetFailed               = errors.New("failed to untrack configuration file")
+	ErrDiffFailed                 = errors.New("failed to compare configuration file")
+	ErrApplyFailed                = errors.New("failed to apply configuration files")
 	ErrTransactionExists          = errors.New("another configuration operation is in progress")
 )
 
@@ -67,12 +72,39 @@ type AddOptions struct {
 	Template  bool // Enable template processing
 	Secrets   bool // Encrypt with GPG
 	Private   bool // Set file permissions to 600
+
+	// Target is the absolute path the file is applied to, if not the added
+	// path. It must be within the home directory.
+	Target string
+}
+
+// ApplyOptions configures the behavior of applying tracked configs.
+type ApplyOptions struct {
+	DryRun bool // Report what would change without writing anything
+	Force  bool // Overwrite files changed on disk without prompting
+
+	// Target limits the apply to a single tracked path. Empty applies
+	// every tracked config.
+	Target string
+
+	// Out receives the list of changes made (or, with DryRun, that would
+	// be made). Nil discards it.
+	Out io.Writer
+}
+
+// AddResult describes the entry Add created in the source directory.
+type AddResult struct {
+	// SourcePath is the absolute path of the created source file, or the
+	// source directory for a recursive add.
+	SourcePath string
+	Encrypted  bool // The source file is encrypted (AddOptions.Secrets)
 }
 
 // Chezmoi is the interface for chezmoi operations.
 // Following Go best practices: accept interfaces, return structs.
 type Chezmoi interface {
-	Add(ctx context.Context, path string, opts AddOptions) error
+	Add(ctx context.Context, path string, opts AddOptions) (*AddResult, error)
+	Forget(ctx context.Context, path string) error
 	HasFile(ctx context.Context, path string) (bool, error)
 }
 
@@ -94,11 +126,40 @@ func NewClient(zerbDir string) *Client {
 
 // Add adds a config file to chezmoi's source directory.
 // It uses complete isolation flags to prevent touching the user's chezmoi installation.
-func (c *Client) Add(ctx context.Context, path string, opts AddOptions) error {
+//
+// With opts.Target, the file is added as if it were at the target path: a
+// link to it is placed at the target's location in a scratch destination
+// directory and added with --follow, so the source state is named after
+// the target and applying it writes the target.
+//
+// The returned AddResult names the source entry that was created, so
+// callers can stage exactly that entry.
+func (c *Client) Add(ctx context.Context, path string, opts AddOptions) (*AddResult, error) {
 	args := []string{
 		"--source", c.src,
 		"--config", c.conf,
-		"add",
+	}
+
+	// The entry is named after where it is applied
+	appliedPath := path
+	if opts.Target != "" {
+		appliedPath = opts.Target
+	}
+
+	if opts.Target != "" {
+		destDir, link, err := stageTarget(path, opts.Target)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = os.RemoveAll(destDir) }()
+
+		args = append(args, "--destination", destDir)
+		path = link
+	}
+
+	args = append(args, "add")
+	if opts.Target != "" {
+		args = append(args, "--follow")
 	}
 
 	// Add optional flags
@@ -118,6 +179,142 @@ func (c *Client) Add(ctx context.Context, path string, opts AddOptions) error {
 	// Add the path as the last argument
 	args = append(args, path)
 
+	if out, err := c.run(ctx, args...); err != nil {
+		return nil, translateChezmoiError(err, string(out))
+	}
+
+	sourcePath, err := c.sourcePath(ctx, appliedPath)
+	if err != nil {
+		return nil, err
+	}
+	return &AddResult{SourcePath: sourcePath, Encrypted: opts.Secrets}, nil
+}
+
+// sourcePath returns the source entry of the config applied to target
+func (c *Client) sourcePath(ctx context.Context, target string) (string, error) {
+	out, err := c.output(ctx, nil, "--source", c.src, "--config", c.conf, "source-path", target)
+	if err != nil {
+		return "", translateChezmoiError(err, string(out))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// stageTarget creates a scratch destination directory holding a link to
+// path at target's location relative to the home directory. Returns the
+// directory (for the caller to remove) and the link.
+func stageTarget(path, target string) (destDir, link string, err error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", fmt.Errorf("get home directory: %w", err)
+	}
+
+	relTarget, err := filepath.Rel(home, target)
+	if err != nil || !filepath.IsAbs(target) || relTarget == "." ||
+		relTarget == ".." || strings.HasPrefix(relTarget, ".."+string(filepath.Separator)) {
+		return "", "", fmt.Errorf("target must be within the home directory: %s", target)
+	}
+
+	destDir, err = os.MkdirTemp("", "zerb-add-")
+	if err != nil {
+		return "", "", fmt.Errorf("create staging directory: %w", err)
+	}
+
+	link = filepath.Join(destDir, relTarget)
+	if err := os.MkdirAll(filepath.Dir(link), 0700); err != nil {
+		_ = os.RemoveAll(destDir)
+		return "", "", fmt.Errorf("create staging directory: %w", err)
+	}
+	if err := os.Symlink(path, link); err != nil {
+		_ = os.RemoveAll(destDir)
+		return "", "", fmt.Errorf("stage target: %w", err)
+	}
+
+	return destDir, link, nil
+}
+
+// Forget stops tracking a config file by removing it from chezmoi's source
+// directory. The file in the user's home directory is left untouched.
+func (c *Client) Forget(ctx context.Context, path string) error {
+	args := []string{
+		"--source", c.src,
+		"--config", c.conf,
+		"--force", // Never prompt; ZERB confirms with the user itself
+		"forget",
+		path,
+	}
+
+	if out, err := c.run(ctx, args...); err != nil {
+		return translateChezmoiErrorAs(ErrForgetFailed, err, string(out))
+	}
+
+	return nil
+}
+
+// Diff returns a unified diff from the tracked content of paths to the
+// files on disk, or "" if they are identical. Lines starting with "-" are
+// tracked content and lines starting with "+" are what is on disk. With no
+// paths every tracked config is compared, previewing what Apply would undo.
+func (c *Client) Diff(ctx context.Context, paths ...string) (string, error) {
+	args := []string{
+		"--source", c.src,
+		"--config", c.conf,
+		"--no-pager",
+		"--color=false",
+		"diff",
+		"--reverse", // Tracked -> on disk rather than what applying would change
+	}
+	args = append(args, paths...)
+
+	out, err := c.output(ctx, nil, args...)
+	if err != nil {
+		return "", translateChezmoiErrorAs(ErrDiffFailed, err, string(out))
+	}
+	return string(out), nil
+}
+
+// Apply writes the tracked content of configs to the home directory, the
+// reverse of Add. With opts.DryRun nothing is written and the changes that
+// would be made are reported to opts.Out.
+func (c *Client) Apply(ctx context.Context, opts ApplyOptions) error {
+	args := []string{
+		"--source", c.src,
+		"--config", c.conf,
+		"--no-pager",
+		"--color=false",
+		"--no-tty", // Never prompt; conflicts fail unless opts.Force is set
+	}
+	if opts.Force {
+		args = append(args, "--force")
+	}
+
+	args = append(args, "apply", "--verbose")
+	if opts.DryRun {
+		args = append(args, "--dry-run")
+	}
+	if opts.Target != "" {
+		args = append(args, opts.Target)
+	}
+
+	out, err := c.run(ctx, args...)
+	if err != nil {
+		return translateChezmoiErrorAs(ErrApplyFailed, err, string(out))
+	}
+	if opts.Out != nil && len(out) > 0 {
+		if _, err := opts.Out.Write(out); err != nil {
+			return fmt.Errorf("write apply output: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// run executes the chezmoi binary with a scrubbed environment and returns
+// its combined output.
+func (c *Client) run(ctx context.Context, args ...string) ([]byte, error) {
+	if err := binary.CheckExecutable(c.bin, binary.BinaryChezmoi); err != nil {
+		return nil, err
+	}
+
 	// Create command with context for cancellation/timeout support
 	cmd := exec.CommandContext(ctx, c.bin, args...)
 
@@ -132,12 +329,7 @@ func (c *Client) Add(ctx context.Context, path string, opts AddOptions) error {
 	// Explicitly do NOT pass CHEZMOI_* environment variables
 
 	// Capture combined output for error reporting
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return translateChezmoiError(err, string(out))
-	}
-
-	return nil
+	return cmd.CombinedOutput()
 }
 
 // HasFile checks if a path is managed by ZERB.
@@ -207,6 +399,17 @@ func pathToChezmoiSource(relPath string) string {
 // translateChezmoiError maps chezmoi errors to user-friendly ZERB errors.
 // This ensures we never expose "chezmoi" in user-facing messages.
 func translateChezmoiError(err error, stderr string) error {
+	return translateChezmoiErrorAs(ErrChezmoiInvocation, err, stderr)
+}
+
+// translateChezmoiErrorAs is translateChezmoiError with a caller-chosen
+// sentinel error for failures that are not cancellations or timeouts.
+func translateChezmoiErrorAs(base error, err error, stderr string) error {
+	// A missing binary already says how to fix it
+	if errors.Is(err, binary.ErrBinaryMissing) {
+		return err
+	}
+
 	// Check for context cancellation/timeout first
 	// Use errors.Is for wrapped errors and string check as fallback
 	if errors.Is(err, context.Canceled) {
@@ -220,20 +423,20 @@ func translateChezmoiError(err error, stderr string) error {
 	stderrLower := strings.ToLower(stderr)
 
 	if strings.Contains(stderrLower, "no such file") || strings.Contains(stderrLower, "does not exist") {
-		return fmt.Errorf("%w: file not found", ErrChezmoiInvocation)
+		return fmt.Errorf("%w: file not found", base)
 	}
 
 	if strings.Contains(stderrLower, "permission denied") {
-		return fmt.Errorf("%w: permission denied", ErrChezmoiInvocation)
+		return fmt.Errorf("%w: permission denied", base)
 	}
 
 	if strings.Contains(stderrLower, "is a directory") {
-		return fmt.Errorf("%w: path is a directory (use --recursive)", ErrChezmoiInvocation)
+		return fmt.Errorf("%w: path is a directory (use --recursive)", base)
 	}
 
 	// Generic fallback - redact sensitive info but preserve useful context
 	sanitized := redactSensitiveInfo(stderr)
-	return fmt.Errorf("%w: %s", ErrChezmoiInvocation, sanitized)
+	return fmt.Errorf("%w: %s", base, sanitized)
 }
 
 // redactSensitiveInfo removes potentially sensitive information from error messages.
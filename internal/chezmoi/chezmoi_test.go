@@ -6,9 +6,13 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/binary"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
 )
 
 func TestNewClient(t *testing.T) {
@@ -151,7 +155,7 @@ exit 0
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctx := context.Background()
-			err := client.Add(ctx, tt.path, tt.opts)
+			_, err := client.Add(ctx, tt.path, tt.opts)
 			if err != nil {
 				// For now we expect success since we're using a stub
 				t.Errorf("Add() error = %v", err)
@@ -190,7 +194,7 @@ sleep 10
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel() // Cancel immediately
 
-	err := client.Add(ctx, testFile, AddOptions{})
+	_, err := client.Add(ctx, testFile, AddOptions{})
 	if err == nil {
 		t.Error("Add() with cancelled context should return error")
 	}
@@ -226,7 +230,7 @@ sleep 2
 	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
 	defer cancel()
 
-	err := client.Add(ctx, testFile, AddOptions{})
+	_, err := client.Add(ctx, testFile, AddOptions{})
 	if err == nil {
 		t.Error("Add() with timeout should return error")
 		return
@@ -237,6 +241,149 @@ sleep 2
 	t.Logf("Add() with timeout returned error: %v (expected - test passes)", err)
 }
 
+func TestClient_Forget(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Create a stub chezmoi binary that records its arguments
+	argsFile := filepath.Join(tmpDir, "args")
+	stubBin := filepath.Join(tmpDir, "chezmoi")
+	stubScript := `#!/bin/bash
+echo "$@" > "` + argsFile + `"
+exit 0
+`
+	if err := os.WriteFile(stubBin, []byte(stubScript), 0755); err != nil {
+		t.Fatalf("cannot create stub binary: %v", err)
+	}
+
+	client := &Client{
+		bin:  stubBin,
+		src:  filepath.Join(tmpDir, "source"),
+		conf: filepath.Join(tmpDir, "config.toml"),
+	}
+
+	testFile := filepath.Join(tmpDir, "testfile")
+	if err := client.Forget(context.Background(), testFile); err != nil {
+		t.Fatalf("Forget() error = %v", err)
+	}
+
+	got, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("cannot read recorded args: %v", err)
+	}
+	want := strings.Join([]string{"--source", client.src, "--config", client.conf, "--force", "forget", testFile}, " ")
+	if strings.TrimSpace(string(got)) != want {
+		t.Errorf("Forget() args = %q, want %q", strings.TrimSpace(string(got)), want)
+	}
+}
+
+func TestClient_Add_Target(t *testing.T) {
+	tmpDir := t.TempDir()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// Stub records its arguments and where the staged link points, and
+	// maps the target to its source entry
+	argsFile := filepath.Join(tmpDir, "args")
+	stubBin := filepath.Join(tmpDir, "chezmoi")
+	stubScript := `#!/bin/bash
+if [ "$5" = source-path ]; then
+    [ "$6" = "$HOME/.config/git/config" ] && echo "$2/dot_config/git/private_config"
+    exit 0
+fi
+for last; do :; done
+echo "$@" > "` + argsFile + `"
+readlink "$last" >> "` + argsFile + `"
+exit 0
+`
+	if err := os.WriteFile(stubBin, []byte(stubScript), 0755); err != nil {
+		t.Fatalf("cannot create stub binary: %v", err)
+	}
+
+	client := &Client{
+		bin:  stubBin,
+		src:  filepath.Join(tmpDir, "source"),
+		conf: filepath.Join(tmpDir, "config.toml"),
+	}
+
+	source := filepath.Join(home, "dotfiles", "work.gitconfig")
+	target := filepath.Join(home, ".config", "git", "config")
+	added, err := client.Add(context.Background(), source, AddOptions{Private: true, Target: target})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	wantAdded := &AddResult{SourcePath: filepath.Join(client.src, "dot_config", "git", "private_config")}
+	if !reflect.DeepEqual(added, wantAdded) {
+		t.Errorf("Add() = %+v, want %+v", added, wantAdded)
+	}
+
+	got, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("cannot read recorded args: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(got)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("recorded %q, want args and link target", got)
+	}
+	args := strings.Fields(lines[0])
+
+	// --source S --config C --destination D add --follow --private D/.config/git/config
+	if len(args) != 10 {
+		t.Fatalf("Add() args = %q", lines[0])
+	}
+	destDir := args[5]
+	want := []string{
+		"--source", client.src, "--config", client.conf, "--destination", destDir,
+		"add", "--follow", "--private", filepath.Join(destDir, ".config", "git", "config"),
+	}
+	if strings.Join(args, " ") != strings.Join(want, " ") {
+		t.Errorf("Add() args = %q, want %q", lines[0], strings.Join(want, " "))
+	}
+	if lines[1] != source {
+		t.Errorf("staged link points to %q, want %q", lines[1], source)
+	}
+	if _, err := os.Stat(destDir); !os.IsNotExist(err) {
+		t.Errorf("staging directory %s should be removed, stat error = %v", destDir, err)
+	}
+
+	// Targets outside home are refused before running the binary
+	if _, err := client.Add(context.Background(), source, AddOptions{Target: "/etc/gitconfig"}); err == nil {
+		t.Error("Add() with a target outside home should fail")
+	}
+	if _, err := client.Add(context.Background(), source, AddOptions{Target: home}); err == nil {
+		t.Error("Add() with the home directory as target should fail")
+	}
+}
+
+func TestClient_Forget_Error(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	stubBin := filepath.Join(tmpDir, "chezmoi")
+	stubScript := `#!/bin/bash
+echo "chezmoi: $3: not managed" >&2
+exit 1
+`
+	if err := os.WriteFile(stubBin, []byte(stubScript), 0755); err != nil {
+		t.Fatalf("cannot create stub binary: %v", err)
+	}
+
+	client := &Client{
+		bin:  stubBin,
+		src:  filepath.Join(tmpDir, "source"),
+		conf: filepath.Join(tmpDir, "config.toml"),
+	}
+
+	err := client.Forget(context.Background(), filepath.Join(tmpDir, "testfile"))
+	if err == nil {
+		t.Fatal("Forget() error = nil, want error")
+	}
+	if !errors.Is(err, ErrForgetFailed) {
+		t.Errorf("Forget() error = %v, want ErrForgetFailed", err)
+	}
+	if strings.Contains(err.Error(), "chezmoi") {
+		t.Errorf("Forget() error leaks implementation name: %v", err)
+	}
+}
+
 func TestClient_Add_ErrorHandling(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -300,7 +447,7 @@ exit 1
 			}
 
 			ctx := context.Background()
-			err := client.Add(ctx, tt.path, AddOptions{})
+			_, err := client.Add(ctx, tt.path, AddOptions{})
 			if err == nil {
 				t.Fatal("Add() should return error")
 			}
@@ -550,3 +697,250 @@ func TestRedactedError_NilError(t *testing.T) {
 		t.Errorf("newRedactedError(nil) = %v, want nil", result)
 	}
 }
+
+func TestClient_MissingBinary(t *testing.T) {
+	zerbDir := t.TempDir()
+	client := NewClient(zerbDir)
+	ctx := context.Background()
+
+	file := filepath.Join(t.TempDir(), ".zshrc")
+	if err := os.WriteFile(file, []byte("content"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := map[string]func() error{
+		"Add":     func() error { _, err := client.Add(ctx, file, AddOptions{}); return err },
+		"Forget":  func() error { return client.Forget(ctx, file) },
+		"Decrypt": func() error { _, err := client.Decrypt(ctx, file); return err },
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			err := call()
+			if !errors.Is(err, binary.ErrBinaryMissing) {
+				t.Fatalf("%s() error = %v, want ErrBinaryMissing", name, err)
+			}
+			msg := err.Error()
+			if !strings.Contains(msg, "zerb init --reinstall") {
+				t.Errorf("error %q should suggest zerb init --reinstall", msg)
+			}
+			if strings.Contains(msg, "chezmoi") {
+				t.Errorf("error %q mentions the underlying binary", msg)
+			}
+		})
+	}
+}
+
+func TestClient_Apply(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     ApplyOptions
+		wantArgs []string // after the --source/--config/output flags
+	}{
+		{
+			name:     "everything",
+			opts:     ApplyOptions{},
+			wantArgs: []string{"apply", "--verbose"},
+		},
+		{
+			name:     "dry run of a single target",
+			opts:     ApplyOptions{DryRun: true, Target: "/home/user/.zshrc"},
+			wantArgs: []string{"apply", "--verbose", "--dry-run", "/home/user/.zshrc"},
+		},
+		{
+			name:     "force",
+			opts:     ApplyOptions{Force: true},
+			wantArgs: []string{"--force", "apply", "--verbose"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+
+			// Stub records its arguments and reports a change
+			argsFile := filepath.Join(tmpDir, "args")
+			stubBin := filepath.Join(tmpDir, "chezmoi")
+			stubScript := `#!/bin/bash
+echo "$@" > "` + argsFile + `"
+echo "+export EDITOR=vim"
+exit 0
+`
+			if err := os.WriteFile(stubBin, []byte(stubScript), 0755); err != nil {
+				t.Fatalf("cannot create stub binary: %v", err)
+			}
+
+			client := &Client{
+				bin:  stubBin,
+				src:  filepath.Join(tmpDir, "source"),
+				conf: filepath.Join(tmpDir, "config.toml"),
+			}
+
+			var out strings.Builder
+			tt.opts.Out = &out
+			if err := client.Apply(context.Background(), tt.opts); err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+
+			got, err := os.ReadFile(argsFile)
+			if err != nil {
+				t.Fatalf("cannot read recorded args: %v", err)
+			}
+			want := append([]string{"--source", client.src, "--config", client.conf, "--no-pager", "--color=false", "--no-tty"}, tt.wantArgs...)
+			if strings.TrimSpace(string(got)) != strings.Join(want, " ") {
+				t.Errorf("Apply() args = %q, want %q", strings.TrimSpace(string(got)), strings.Join(want, " "))
+			}
+			if out.String() != "+export EDITOR=vim\n" {
+				t.Errorf("Apply() output = %q", out.String())
+			}
+		})
+	}
+}
+
+func TestClient_Apply_Error(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	stubBin := filepath.Join(tmpDir, "chezmoi")
+	stubScript := `#!/bin/bash
+echo "chezmoi: /home/user/.zshrc: has changed since chezmoi last wrote it" >&2
+exit 1
+`
+	if err := os.WriteFile(stubBin, []byte(stubScript), 0755); err != nil {
+		t.Fatalf("cannot create stub binary: %v", err)
+	}
+
+	client := &Client{
+		bin:  stubBin,
+		src:  filepath.Join(tmpDir, "source"),
+		conf: filepath.Join(tmpDir, "config.toml"),
+	}
+
+	err := client.Apply(context.Background(), ApplyOptions{})
+	if err == nil {
+		t.Fatal("Apply() error = nil, want error")
+	}
+	if !errors.Is(err, ErrApplyFailed) {
+		t.Errorf("Apply() error = %v, want ErrApplyFailed", err)
+	}
+	if strings.Contains(err.Error(), "chezmoi") {
+		t.Errorf("Apply() error leaks implementation name: %v", err)
+	}
+}
+
+func TestClient_Status(t *testing.T) {
+	tmpDir := t.TempDir()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// Stub records its arguments and reports .zshrc modified, .gitconfig
+	// absent and a file below .config/nvim modified
+	argsFile := filepath.Join(tmpDir, "args")
+	stubBin := filepath.Join(tmpDir, "chezmoi")
+	stubScript := `#!/bin/bash
+echo "$@" > "` + argsFile + `"
+echo " M .zshrc"
+echo " A .gitconfig"
+echo "M  .tmux.conf"
+echo " M .config/nvim/init.lua"
+exit 0
+`
+	if err := os.WriteFile(stubBin, []byte(stubScript), 0755); err != nil {
+		t.Fatalf("cannot create stub binary: %v", err)
+	}
+
+	client := &Client{
+		bin:  stubBin,
+		src:  filepath.Join(tmpDir, "source"),
+		conf: filepath.Join(tmpDir, "config.toml"),
+	}
+
+	// Everything but .bashrc is tracked
+	for _, name := range []string{"dot_zshrc", "dot_gitconfig", "dot_tmux.conf", "dot_config/nvim/init.lua"} {
+		path := filepath.Join(client.src, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create source dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("content"), 0644); err != nil {
+			t.Fatalf("failed to create source file: %v", err)
+		}
+	}
+
+	paths := map[string]config.ConfigStatus{
+		filepath.Join(home, ".zshrc"):       config.StatusModified,
+		filepath.Join(home, ".gitconfig"):   config.StatusMissing,
+		filepath.Join(home, ".tmux.conf"):   config.StatusSynced, // Only the last-written state differs
+		filepath.Join(home, ".config/nvim"): config.StatusModified,
+	}
+	var query []string
+	for path := range paths {
+		query = append(query, path)
+	}
+
+	got, err := client.Status(context.Background(), query)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, paths) {
+		t.Errorf("Status() = %v, want %v", got, paths)
+	}
+
+	// The paths are compared in one query
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("cannot read recorded args: %v", err)
+	}
+	for path := range paths {
+		if !strings.Contains(string(args), path) {
+			t.Errorf("Status() did not query %s: %s", path, args)
+		}
+	}
+}
+
+func TestClient_Status_PathFails(t *testing.T) {
+	tmpDir := t.TempDir()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// Stub fails whenever the encrypted .secrets is queried, as without its
+	// key, and reports .zshrc modified
+	stubBin := filepath.Join(tmpDir, "chezmoi")
+	stubScript := `#!/bin/bash
+for arg in "$@"; do
+  case "$arg" in
+    */.secrets) echo "chezmoi: decrypt failed" >&2; exit 1 ;;
+  esac
+done
+for arg in "$@"; do
+  case "$arg" in
+    */.zshrc) echo " M .zshrc" ;;
+  esac
+done
+exit 0
+`
+	if err := os.WriteFile(stubBin, []byte(stubScript), 0755); err != nil {
+		t.Fatalf("cannot create stub binary: %v", err)
+	}
+
+	client := &Client{
+		bin:  stubBin,
+		src:  filepath.Join(tmpDir, "source"),
+		conf: filepath.Join(tmpDir, "config.toml"),
+	}
+
+	want := map[string]config.ConfigStatus{
+		filepath.Join(home, ".zshrc"):     config.StatusModified,
+		filepath.Join(home, ".gitconfig"): config.StatusSynced,
+		filepath.Join(home, ".secrets"):   config.StatusUnknown,
+	}
+	var query []string
+	for path := range want {
+		query = append(query, path)
+	}
+
+	got, err := client.Status(context.Background(), query)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Status() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,285 @@
+package chezmoi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/binary"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/fsutil"
+)
+
+// Error types for re-encryption (never mention "chezmoi")
+var (
+	ErrDecryptFailed = errors.New("failed to decrypt secret configuration file")
+	ErrEncryptFailed = errors.New("failed to encrypt secret configuration file")
+)
+
+// encryptedPrefix marks encrypted files in the source directory
+const encryptedPrefix = "encrypted_"
+
+// Encryption is the interface for re-encrypting secret config files when
+// the encryption key changes.
+type Encryption interface {
+	// SecretFiles returns the encrypted source files of the config applied
+	// to target (all files below it for a directory).
+	SecretFiles(ctx context.Context, target string) ([]string, error)
+	// Decrypt returns the plaintext of an encrypted source file.
+	Decrypt(ctx context.Context, sourceFile string) ([]byte, error)
+	// Encrypt encrypts plaintext to the configured recipient.
+	Encrypt(ctx context.Context, plaintext []byte) ([]byte, error)
+	// Recipient returns the configured recipient, or "" if none is set.
+	Recipient() (string, error)
+	// SetRecipient configures the recipient new encryptions are made for,
+	// and for age recipients the identity (private key file) to decrypt with.
+	SetRecipient(recipient, identity string) error
+	// EncryptionConfig returns the content of the config file the recipient
+	// is stored in, or nil if there is none, to restore it later.
+	EncryptionConfig() ([]byte, error)
+	// RestoreEncryptionConfig puts back content from EncryptionConfig,
+	// removing the config file if content is nil.
+	RestoreEncryptionConfig(content []byte) error
+}
+
+// SecretFiles returns the encrypted source files of the config applied to
+// target, using the configuration manager's own source path mapping.
+func (c *Client) SecretFiles(ctx context.Context, target string) ([]string, error) {
+	sourcePath, err := c.sourcePath(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	err = filepath.WalkDir(sourcePath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasPrefix(d.Name(), encryptedPrefix) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, newRedactedError(err, "list secret files")
+	}
+	return files, nil
+}
+
+// Decrypt returns the plaintext of an encrypted source file.
+func (c *Client) Decrypt(ctx context.Context, sourceFile string) ([]byte, error) {
+	out, err := c.output(ctx, nil, "--source", c.src, "--config", c.conf, "decrypt", sourceFile)
+	if err != nil {
+		return nil, translateChezmoiErrorAs(ErrDecryptFailed, err, string(out))
+	}
+	return out, nil
+}
+
+// Encrypt encrypts plaintext to the recipient in the config file. The
+// plaintext is passed on stdin so it is never written to disk.
+func (c *Client) Encrypt(ctx context.Context, plaintext []byte) ([]byte, error) {
+	out, err := c.output(ctx, plaintext, "--source", c.src, "--config", c.conf, "encrypt")
+	if err != nil {
+		return nil, translateChezmoiErrorAs(ErrEncryptFailed, err, string(out))
+	}
+	return out, nil
+}
+
+// output executes the chezmoi binary like run, with stdin, and returns its
+// stdout. On failure the returned output is stderr, for error translation.
+func (c *Client) output(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+	if err := binary.CheckExecutable(c.bin, binary.BinaryChezmoi); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, c.bin, args...)
+	cmd.Env = []string{
+		"HOME=" + os.Getenv("HOME"),
+		"PATH=" + os.Getenv("PATH"),
+		"USER=" + os.Getenv("USER"),
+		"LANG=" + os.Getenv("LANG"),
+	}
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return stderr.Bytes(), err
+	}
+	return stdout.Bytes(), nil
+}
+
+// Recipient returns the recipient configured for the encryption method in
+// the config file, or "" if none is set.
+func (c *Client) Recipient() (string, error) {
+	content, err := os.ReadFile(c.conf)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", newRedactedError(err, "read encryption config")
+	}
+
+	section := ""
+	encryption := "gpg"
+	recipients := make(map[string]string)
+	for _, line := range strings.Split(string(content), "\n") {
+		if name, ok := tomlSection(line); ok {
+			section = name
+			continue
+		}
+		key, value, ok := tomlKeyValue(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case section == "" && key == "encryption":
+			encryption = value
+		case key == "recipient":
+			recipients[section] = value
+		}
+	}
+	return recipients[encryption], nil
+}
+
+// SetRecipient sets the recipient in the config file, selecting age for
+// age1... recipients and gpg otherwise. A non-empty identity is set as the
+// method's identity file. Other settings are kept.
+func (c *Client) SetRecipient(recipient, identity string) error {
+	content, err := os.ReadFile(c.conf)
+	if err != nil && !os.IsNotExist(err) {
+		return newRedactedError(err, "read encryption config")
+	}
+
+	updated := setRecipient(string(content), recipientEncryption(recipient), recipient, identity)
+	if err := os.MkdirAll(filepath.Dir(c.conf), 0700); err != nil {
+		return newRedactedError(err, "create config directory")
+	}
+	if err := fsutil.WriteFileAtomic(c.conf, []byte(updated), 0600); err != nil {
+		return newRedactedError(err, "write encryption config")
+	}
+	return nil
+}
+
+// EncryptionConfig returns the content of the config file, or nil if it
+// does not exist.
+func (c *Client) EncryptionConfig() ([]byte, error) {
+	content, err := os.ReadFile(c.conf)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, newRedactedError(err, "read encryption config")
+	}
+	return content, nil
+}
+
+// RestoreEncryptionConfig writes content back to the config file, or
+// removes the file if content is nil.
+func (c *Client) RestoreEncryptionConfig(content []byte) error {
+	if content == nil {
+		if err := os.Remove(c.conf); err != nil && !os.IsNotExist(err) {
+			return newRedactedError(err, "remove encryption config")
+		}
+		return nil
+	}
+	if err := fsutil.WriteFileAtomic(c.conf, content, 0600); err != nil {
+		return newRedactedError(err, "write encryption config")
+	}
+	return nil
+}
+
+// recipientEncryption returns the encryption method for a recipient
+func recipientEncryption(recipient string) string {
+	if strings.HasPrefix(recipient, "age1") {
+		return "age"
+	}
+	return "gpg"
+}
+
+// setRecipient rewrites a TOML config so that encryption is the method,
+// recipient its recipient and, if not empty, identity its identity,
+// keeping every other line.
+func setRecipient(content, encryption, recipient, identity string) string {
+	settings := []string{"    recipient = " + strconv.Quote(recipient)}
+	replaced := map[string]bool{"recipient": true}
+	if identity != "" {
+		settings = append(settings, "    identity = "+strconv.Quote(identity))
+		replaced["identity"] = true
+	}
+
+	var lines []string
+	if strings.TrimSpace(content) != "" {
+		lines = strings.Split(strings.TrimRight(content, "\n"), "\n")
+	}
+
+	var out []string
+	section := ""
+	written := false
+	for _, line := range lines {
+		if name, ok := tomlSection(line); ok {
+			if section == encryption && !written {
+				out = append(out, settings...)
+				written = true
+			}
+			section = name
+			out = append(out, line)
+			continue
+		}
+		key, _, ok := tomlKeyValue(line)
+		switch {
+		case ok && section == "" && key == "encryption":
+			continue // Re-added at the top
+		case ok && section == encryption && replaced[key]:
+			if !written {
+				out = append(out, settings...)
+				written = true
+			}
+			continue
+		}
+		out = append(out, line)
+	}
+	if !written {
+		if section == encryption {
+			out = append(out, settings...)
+		} else {
+			out = append(append(out, "", "["+encryption+"]"), settings...)
+		}
+	}
+
+	// Top-level keys must come before the first table
+	out = append([]string{"encryption = " + strconv.Quote(encryption)}, out...)
+	return strings.Join(out, "\n") + "\n"
+}
+
+// tomlSection returns the name of a [section] header line
+func tomlSection(line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "[") || !strings.HasSuffix(trimmed, "]") {
+		return "", false
+	}
+	return strings.TrimSpace(trimmed[1 : len(trimmed)-1]), true
+}
+
+// tomlKeyValue parses a key = "value" line
+func tomlKeyValue(line string) (key, value string, ok bool) {
+	key, value, ok = strings.Cut(strings.TrimSpace(line), "=")
+	if !ok || strings.HasPrefix(strings.TrimSpace(key), "#") {
+		return "", "", false
+	}
+	value = strings.TrimSpace(value)
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		value = unquoted
+	} else {
+		value = strings.Trim(value, `'`)
+	}
+	return strings.TrimSpace(key), value, true
+}
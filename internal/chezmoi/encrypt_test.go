@@ -0,0 +1,199 @@
+package chezmoi
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// stubEncryptScript mimics the configuration manager's source-path, decrypt
+// and encrypt commands. "Encryption" prefixes the recipient from the config
+// file, and only content encrypted to old-key can be decrypted.
+const stubEncryptScript = `#!/bin/bash
+conf="$4"
+case "$5" in
+source-path)
+    echo "$2/private_dot_ssh"
+    ;;
+decrypt)
+    if ! grep -q '^enc(old-key):' "$6"; then
+        echo "decryption failed: no secret key" >&2
+        exit 1
+    fi
+    sed 's/^enc(old-key)://' "$6"
+    ;;
+encrypt)
+    recipient=$(sed -n 's/^ *recipient = "\(.*\)"/\1/p' "$conf")
+    printf 'enc(%s):' "$recipient"
+    cat
+    ;;
+*)
+    exit 2
+    ;;
+esac
+`
+
+func TestClient_Rekey(t *testing.T) {
+	tmpDir := t.TempDir()
+	stubBin := filepath.Join(tmpDir, "chezmoi")
+	if err := os.WriteFile(stubBin, []byte(stubEncryptScript), 0755); err != nil {
+		t.Fatalf("cannot create stub binary: %v", err)
+	}
+	client := &Client{
+		bin:  stubBin,
+		src:  filepath.Join(tmpDir, "source"),
+		conf: filepath.Join(tmpDir, "config.toml"),
+	}
+	ctx := context.Background()
+
+	sshDir := filepath.Join(client.src, "private_dot_ssh")
+	if err := os.MkdirAll(sshDir, 0700); err != nil {
+		t.Fatalf("failed to create source dir: %v", err)
+	}
+	files := map[string]string{
+		"encrypted_private_config.asc": "enc(old-key):Host *",
+		"encrypted_id_ed25519.asc":     "enc(lost-key):private key",
+		"known_hosts":                  "github.com ssh-ed25519 AAAA",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(sshDir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	if err := os.WriteFile(client.conf, []byte("encryption = \"gpg\"\n[gpg]\n    recipient = \"old-key\"\n"), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	// Only encrypted files are secret files
+	secretFiles, err := client.SecretFiles(ctx, "/home/user/.ssh")
+	if err != nil {
+		t.Fatalf("SecretFiles() error = %v", err)
+	}
+	want := []string{filepath.Join(sshDir, "encrypted_id_ed25519.asc"), filepath.Join(sshDir, "encrypted_private_config.asc")}
+	if !reflect.DeepEqual(secretFiles, want) {
+		t.Errorf("SecretFiles() = %v, want %v", secretFiles, want)
+	}
+
+	plaintext, err := client.Decrypt(ctx, want[1])
+	if err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+	if string(plaintext) != "Host *" {
+		t.Errorf("Decrypt() = %q, want %q", plaintext, "Host *")
+	}
+
+	if _, err := client.Decrypt(ctx, want[0]); !errors.Is(err, ErrDecryptFailed) {
+		t.Errorf("Decrypt() of a file for another key error = %v, want ErrDecryptFailed", err)
+	}
+
+	if got, err := client.Recipient(); err != nil || got != "old-key" {
+		t.Errorf("Recipient() = %q, %v, want old-key", got, err)
+	}
+	if err := client.SetRecipient("new-key", ""); err != nil {
+		t.Fatalf("SetRecipient() error = %v", err)
+	}
+	if got, err := client.Recipient(); err != nil || got != "new-key" {
+		t.Errorf("Recipient() after SetRecipient = %q, %v, want new-key", got, err)
+	}
+
+	ciphertext, err := client.Encrypt(ctx, plaintext)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if string(ciphertext) != "enc(new-key):Host *" {
+		t.Errorf("Encrypt() = %q, want it encrypted to new-key", ciphertext)
+	}
+}
+
+func TestClient_RestoreEncryptionConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	client := &Client{conf: filepath.Join(tmpDir, "config.toml")}
+
+	// No config file: restoring removes the one SetRecipient created
+	saved, err := client.EncryptionConfig()
+	if err != nil || saved != nil {
+		t.Fatalf("EncryptionConfig() = %q, %v, want nil", saved, err)
+	}
+	if err := client.SetRecipient("new-key", ""); err != nil {
+		t.Fatalf("SetRecipient() error = %v", err)
+	}
+	if err := client.RestoreEncryptionConfig(saved); err != nil {
+		t.Fatalf("RestoreEncryptionConfig() error = %v", err)
+	}
+	if _, err := os.Stat(client.conf); !os.IsNotExist(err) {
+		t.Errorf("config file exists after restoring none: %v", err)
+	}
+
+	// An existing config file is restored byte for byte
+	original := "# managed by hand\n[gpg]\n    args = [\"--quiet\"]\n"
+	if err := os.WriteFile(client.conf, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if saved, err = client.EncryptionConfig(); err != nil {
+		t.Fatalf("EncryptionConfig() error = %v", err)
+	}
+	if err := client.SetRecipient("age1example", "~/key.txt"); err != nil {
+		t.Fatalf("SetRecipient() error = %v", err)
+	}
+	if err := client.RestoreEncryptionConfig(saved); err != nil {
+		t.Fatalf("RestoreEncryptionConfig() error = %v", err)
+	}
+	if got, _ := os.ReadFile(client.conf); string(got) != original {
+		t.Errorf("config file = %q, want %q", got, original)
+	}
+}
+
+func TestSetRecipient(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		recipient string
+		identity  string
+		want      string
+	}{
+		{
+			name:      "no config",
+			content:   "",
+			recipient: "0x1234ABCD",
+			want:      "encryption = \"gpg\"\n\n[gpg]\n    recipient = \"0x1234ABCD\"\n",
+		},
+		{
+			name:      "replaces recipient and keeps other settings",
+			content:   "encryption = \"gpg\"\n\n[gpg]\n    recipient = \"old\"\n    args = [\"--quiet\"]\n\n[diff]\n    pager = \"less\"\n",
+			recipient: "new@example.com",
+			want:      "encryption = \"gpg\"\n\n[gpg]\n    recipient = \"new@example.com\"\n    args = [\"--quiet\"]\n\n[diff]\n    pager = \"less\"\n",
+		},
+		{
+			name:      "switches to age",
+			content:   "encryption = \"gpg\"\n[gpg]\n    recipient = \"old\"\n[age]\n    identity = \"~/key.txt\"\n",
+			recipient: "age1newkey",
+			want:      "encryption = \"age\"\n[gpg]\n    recipient = \"old\"\n[age]\n    identity = \"~/key.txt\"\n    recipient = \"age1newkey\"\n",
+		},
+		{
+			name:      "sets age identity",
+			content:   "encryption = \"age\"\n[age]\n    identity = \"~/old.txt\"\n    recipient = \"age1old\"\n[diff]\n    pager = \"less\"\n",
+			recipient: "age1newkey",
+			identity:  "~/new.txt",
+			want:      "encryption = \"age\"\n[age]\n    recipient = \"age1newkey\"\n    identity = \"~/new.txt\"\n[diff]\n    pager = \"less\"\n",
+		},
+		{
+			name:      "adds age section with identity",
+			content:   "",
+			recipient: "age1newkey",
+			identity:  "~/key.txt",
+			want:      "encryption = \"age\"\n\n[age]\n    recipient = \"age1newkey\"\n    identity = \"~/key.txt\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := setRecipient(tt.content, recipientEncryption(tt.recipient), tt.recipient, tt.identity)
+			if got != tt.want {
+				t.Errorf("setRecipient() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package chezmoi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+)
+
+// ErrStatusFailed is returned when the status of tracked configs cannot be
+// read (never mentions "chezmoi")
+var ErrStatusFailed = errors.New("failed to check configuration file status")
+
+// Status reports how each path on disk compares to its tracked content:
+// config.StatusSynced if it matches, config.StatusModified if it differs
+// and config.StatusMissing if it is absent. A directory is modified if any
+// file below it differs. Paths must be tracked; callers check HasFile
+// first.
+//
+// The paths are compared in one query. If that fails, e.g. because one
+// file is encrypted without its key or is a template that fails to
+// evaluate, each path is compared on its own and those that still fail
+// are reported as config.StatusUnknown, so one file doesn't hide the
+// status of the others.
+func (c *Client) Status(ctx context.Context, paths []string) (map[string]config.ConfigStatus, error) {
+	statuses := make(map[string]config.ConfigStatus, len(paths))
+	if len(paths) == 0 {
+		return statuses, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("get home directory: %w", err)
+	}
+
+	changes, err := c.statusChanges(ctx, paths, home)
+	if err == nil {
+		return statusesOf(paths, changes, statuses)
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	for _, path := range paths {
+		changes, err := c.statusChanges(ctx, []string{path}, home)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			statuses[path] = config.StatusUnknown
+			continue
+		}
+		if _, err := statusesOf([]string{path}, changes, statuses); err != nil {
+			return nil, err
+		}
+	}
+	return statuses, nil
+}
+
+// statusChanges runs status for paths and returns the changes applying
+// would make, as parseStatus does
+func (c *Client) statusChanges(ctx context.Context, paths []string, home string) (map[string]byte, error) {
+	args := append([]string{"--source", c.src, "--config", c.conf, "status"}, paths...)
+	out, err := c.output(ctx, nil, args...)
+	if err != nil {
+		return nil, translateChezmoiErrorAs(ErrStatusFailed, err, string(out))
+	}
+	return parseStatus(string(out), home), nil
+}
+
+// statusesOf adds the status of each path given changes to statuses
+func statusesOf(paths []string, changes map[string]byte, statuses map[string]config.ConfigStatus) (map[string]config.ConfigStatus, error) {
+	for _, path := range paths {
+		normalized, err := config.NormalizeConfigPath(path)
+		if err != nil {
+			return nil, newRedactedError(err, "normalize path")
+		}
+		statuses[path] = statusOf(normalized, changes)
+	}
+	return statuses, nil
+}
+
+// parseStatus parses status output into a map from absolute path to the
+// change applying would make: 'A' (create), 'M' (modify) or 'D' (delete).
+// Each line is two status columns, a space and a path relative to home;
+// only the second column compares the file on disk to its tracked content.
+func parseStatus(output, home string) map[string]byte {
+	changes := make(map[string]byte)
+	for _, line := range strings.Split(output, "\n") {
+		if len(line) < 4 || line[2] != ' ' {
+			continue
+		}
+		change := line[1]
+		if change == ' ' {
+			continue
+		}
+		changes[filepath.Join(home, line[3:])] = change
+	}
+	return changes
+}
+
+// statusOf returns the status of path given the changes applying would make
+func statusOf(path string, changes map[string]byte) config.ConfigStatus {
+	if change, ok := changes[path]; ok {
+		if change == 'A' {
+			return config.StatusMissing
+		}
+		return config.StatusModified
+	}
+
+	prefix := path + string(filepath.Separator)
+	for changed := range changes {
+		if strings.HasPrefix(changed, prefix) {
+			return config.StatusModified
+		}
+	}
+	return config.StatusSynced
+}
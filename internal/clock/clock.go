@@ -0,0 +1,82 @@
+// Package clock provides an injectable time source.
+//
+// Code that depends on the passage of time (download backoff, cache TTLs,
+// timestamped snapshot names) takes a Clock so tests can control time
+// deterministically instead of sleeping.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock provides time operations. This interface enables deterministic testing.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After waits for the duration to elapse and then sends the current time
+	// on the returned channel.
+	After(d time.Duration) <-chan time.Time
+}
+
+// Real implements Clock using the actual system time.
+type Real struct{}
+
+// Now returns the current time.
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+// After waits for the duration to elapse using time.After.
+func (Real) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Fake implements Clock with manually controlled time for testing.
+// After never blocks: it advances the fake time by the requested duration
+// and fires immediately, recording the wait so tests can assert on it.
+// Fake is safe for concurrent use.
+type Fake struct {
+	mu     sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+// NewFake creates a fake clock set to the given time.
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Now returns the fake current time.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// After advances the fake time by d and returns a channel that has already fired.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = f.now.Add(d)
+	f.sleeps = append(f.sleeps, d)
+
+	ch := make(chan time.Time, 1)
+	ch <- f.now
+	return ch
+}
+
+// Advance moves the fake time forward by d.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+// Sleeps returns the durations passed to After, in call order.
+func (f *Fake) Sleeps() []time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]time.Duration(nil), f.sleeps...)
+}
@@ -0,0 +1,52 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFake_NowAndAdvance(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	f := NewFake(start)
+
+	if got := f.Now(); !got.Equal(start) {
+		t.Errorf("Now() = %v, want %v", got, start)
+	}
+
+	f.Advance(90 * time.Second)
+	if got := f.Now(); !got.Equal(start.Add(90 * time.Second)) {
+		t.Errorf("Now() after Advance = %v, want %v", got, start.Add(90*time.Second))
+	}
+}
+
+func TestFake_After(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	f := NewFake(start)
+
+	select {
+	case fired := <-f.After(2 * time.Second):
+		if !fired.Equal(start.Add(2 * time.Second)) {
+			t.Errorf("After() fired at %v, want %v", fired, start.Add(2*time.Second))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("After() blocked, want immediate fire")
+	}
+
+	<-f.After(time.Second)
+
+	sleeps := f.Sleeps()
+	if len(sleeps) != 2 || sleeps[0] != 2*time.Second || sleeps[1] != time.Second {
+		t.Errorf("Sleeps() = %v, want [2s 1s]", sleeps)
+	}
+	if got := f.Now(); !got.Equal(start.Add(3 * time.Second)) {
+		t.Errorf("Now() = %v, want %v", got, start.Add(3*time.Second))
+	}
+}
+
+func TestReal_Now(t *testing.T) {
+	before := time.Now()
+	got := Real{}.Now()
+	if got.Before(before) {
+		t.Errorf("Real.Now() = %v, want >= %v", got, before)
+	}
+}
@@ -3,11 +3,15 @@ package config
 // Lua schema field names and globals
 const (
 	luaGlobalZerb           = "zerb"
+	luaFieldSchemaVersion   = "schema_version"
 	luaFieldMeta            = "meta"
 	luaFieldTools           = "tools"
 	luaFieldConfigs         = "configs"
 	luaFieldGit             = "git"
 	luaFieldConfig          = "config"
+	luaFieldProfiles        = "profiles"
+	luaFieldVersionProbe    = "version_probe"
+	luaFieldBackends        = "backends"
 	luaFieldName            = "name"
 	luaFieldDesc            = "description"
 	luaFieldPath            = "path"
@@ -15,7 +19,15 @@ const (
 	luaFieldTemplate        = "template"
 	luaFieldSecrets         = "secrets"
 	luaFieldPrivate         = "private"
+	luaFieldTarget          = "target"
 	luaFieldRemote          = "remote"
 	luaFieldBranch          = "branch"
+	luaFieldPerHostBranches = "per_host_branches"
 	luaFieldBackupRetention = "backup_retention"
+	luaFieldDefaults        = "defaults"
+	luaFieldFlag            = "flag"
+	luaFieldRegex           = "regex"
+	luaFieldEncryption      = "encryption"
+	luaFieldRecipient       = "recipient"
+	luaFieldIdentity        = "identity"
 )
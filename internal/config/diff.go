@@ -0,0 +1,337 @@
+package config
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Diff describes the differences between two configurations.
+// All slices are sorted so the output is stable.
+type Diff struct {
+	Tools         ToolsDiff     `json:"tools"`
+	Profiles      []ProfileDiff `json:"profiles,omitempty"`
+	Backends      ListDiff      `json:"backends"`
+	Configs       ConfigsDiff   `json:"configs"`
+	VersionProbes []FieldChange `json:"version_probe,omitempty"`
+	Encryption    []FieldChange `json:"encryption,omitempty"`
+	Git           []FieldChange `json:"git,omitempty"`
+	Options       []FieldChange `json:"options,omitempty"`
+}
+
+// ToolsDiff describes tool changes between two configurations.
+// Added and Removed hold full tool strings (e.g. "node@20.11.0").
+type ToolsDiff struct {
+	Added   []string     `json:"added,omitempty"`
+	Removed []string     `json:"removed,omitempty"`
+	Changed []ToolChange `json:"changed,omitempty"`
+}
+
+// ToolChange describes a tool whose version changed.
+type ToolChange struct {
+	Name string `json:"name"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ProfileDiff describes the tool changes of one profile. A profile only in
+// one of the configurations has all its tools added or removed.
+type ProfileDiff struct {
+	Name  string    `json:"name"`
+	Tools ToolsDiff `json:"tools"`
+}
+
+// ListDiff describes the entries added to and removed from a list.
+type ListDiff struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// ConfigsDiff describes tracked config file changes between two configurations.
+type ConfigsDiff struct {
+	Added   []string       `json:"added,omitempty"`
+	Removed []string       `json:"removed,omitempty"`
+	Changed []ConfigChange `json:"changed,omitempty"`
+}
+
+// ConfigChange describes a config file tracked by both configurations whose
+// flags or target changed.
+type ConfigChange struct {
+	Path   string        `json:"path"`
+	Fields []FieldChange `json:"fields"`
+}
+
+// FieldChange describes a scalar field whose value changed.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// IsEmpty reports whether the diff contains no changes.
+func (d *Diff) IsEmpty() bool {
+	return len(d.Tools.Added) == 0 &&
+		len(d.Tools.Removed) == 0 &&
+		len(d.Tools.Changed) == 0 &&
+		len(d.Profiles) == 0 &&
+		len(d.Backends.Added) == 0 &&
+		len(d.Backends.Removed) == 0 &&
+		len(d.Configs.Added) == 0 &&
+		len(d.Configs.Removed) == 0 &&
+		len(d.Configs.Changed) == 0 &&
+		len(d.VersionProbes) == 0 &&
+		len(d.Encryption) == 0 &&
+		len(d.Git) == 0 &&
+		len(d.Options) == 0
+}
+
+// DiffConfigs compares two configurations and returns what changed going
+// from a to b. Tools are matched by name so a version bump is reported as a
+// change rather than a removal plus an addition, within profiles too. Config
+// files are matched by path, and version probes by tool name. Either
+// argument may be nil, which is treated as an empty config. Meta and the
+// schema version are not compared.
+func DiffConfigs(a, b *Config) *Diff {
+	if a == nil {
+		a = &Config{}
+	}
+	if b == nil {
+		b = &Config{}
+	}
+
+	return &Diff{
+		Tools:         diffTools(a.Tools, b.Tools),
+		Profiles:      diffProfiles(a.Profiles, b.Profiles),
+		Backends:      diffLists(a.Backends, b.Backends),
+		Configs:       diffConfigFiles(a.Configs, b.Configs),
+		VersionProbes: diffVersionProbes(a.VersionProbes, b.VersionProbes),
+		Encryption: diffFields([]FieldChange{
+			{Field: "recipient", From: a.Encryption.Recipient, To: b.Encryption.Recipient},
+			{Field: "identity", From: a.Encryption.Identity, To: b.Encryption.Identity},
+		}),
+		Git: diffFields([]FieldChange{
+			{Field: "remote", From: a.Git.Remote, To: b.Git.Remote},
+			{Field: "branch", From: a.Git.Branch, To: b.Git.Branch},
+			{Field: "per_host_branches", From: formatBool(a.Git.PerHostBranches), To: formatBool(b.Git.PerHostBranches)},
+		}),
+		Options: diffFields([]FieldChange{
+			{Field: "backup_retention", From: formatInt(a.Options.BackupRetention), To: formatInt(b.Options.BackupRetention)},
+			{Field: "defaults.template", From: formatBool(a.Options.Defaults.Template), To: formatBool(b.Options.Defaults.Template)},
+			{Field: "defaults.secrets", From: formatBool(a.Options.Defaults.Secrets), To: formatBool(b.Options.Defaults.Secrets)},
+			{Field: "defaults.private", From: formatBool(a.Options.Defaults.Private), To: formatBool(b.Options.Defaults.Private)},
+		}),
+	}
+}
+
+// diffTools compares tool lists by tool name.
+func diffTools(a, b []string) ToolsDiff {
+	before := toolsByName(a)
+	after := toolsByName(b)
+
+	var d ToolsDiff
+	for name, tool := range after {
+		old, ok := before[name]
+		switch {
+		case !ok:
+			d.Added = append(d.Added, tool)
+		case CanonicalTool(old) != CanonicalTool(tool):
+			d.Changed = append(d.Changed, ToolChange{
+				Name: name,
+				From: toolVersion(old),
+				To:   toolVersion(tool),
+			})
+		}
+	}
+	for name, tool := range before {
+		if _, ok := after[name]; !ok {
+			d.Removed = append(d.Removed, tool)
+		}
+	}
+
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	sort.Slice(d.Changed, func(i, j int) bool {
+		return d.Changed[i].Name < d.Changed[j].Name
+	})
+
+	return d
+}
+
+// diffProfiles compares profiles by name, and their tools as diffTools does.
+func diffProfiles(a, b map[string][]string) []ProfileDiff {
+	var d []ProfileDiff
+	for name := range a {
+		if _, ok := b[name]; !ok {
+			d = append(d, ProfileDiff{Name: name, Tools: diffTools(a[name], nil)})
+		}
+	}
+	for name, tools := range b {
+		profile := ProfileDiff{Name: name, Tools: diffTools(a[name], tools)}
+		if len(profile.Tools.Added)+len(profile.Tools.Removed)+len(profile.Tools.Changed) > 0 {
+			d = append(d, profile)
+		}
+	}
+
+	sort.Slice(d, func(i, j int) bool {
+		return d[i].Name < d[j].Name
+	})
+
+	return d
+}
+
+// diffLists compares two lists of strings as sets.
+func diffLists(a, b []string) ListDiff {
+	before := make(map[string]bool, len(a))
+	for _, entry := range a {
+		before[entry] = true
+	}
+	after := make(map[string]bool, len(b))
+	for _, entry := range b {
+		after[entry] = true
+	}
+
+	var d ListDiff
+	for entry := range after {
+		if !before[entry] {
+			d.Added = append(d.Added, entry)
+		}
+	}
+	for entry := range before {
+		if !after[entry] {
+			d.Removed = append(d.Removed, entry)
+		}
+	}
+
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+
+	return d
+}
+
+// diffConfigFiles compares tracked config files by path, and the flags and
+// target of the files both track.
+func diffConfigFiles(a, b []ConfigFile) ConfigsDiff {
+	before := make(map[string]ConfigFile, len(a))
+	paths := make([]string, 0, len(a))
+	for _, cf := range a {
+		before[cf.Path] = cf
+		paths = append(paths, cf.Path)
+	}
+	afterPaths := make([]string, 0, len(b))
+	for _, cf := range b {
+		afterPaths = append(afterPaths, cf.Path)
+	}
+
+	list := diffLists(paths, afterPaths)
+	d := ConfigsDiff{Added: list.Added, Removed: list.Removed}
+	for _, cf := range b {
+		old, ok := before[cf.Path]
+		if !ok {
+			continue
+		}
+		fields := diffFields([]FieldChange{
+			{Field: "recursive", From: formatBool(old.Recursive), To: formatBool(cf.Recursive)},
+			{Field: "template", From: formatBool(old.Template), To: formatBool(cf.Template)},
+			{Field: "secrets", From: formatBool(old.Secrets), To: formatBool(cf.Secrets)},
+			{Field: "private", From: formatBool(old.Private), To: formatBool(cf.Private)},
+			{Field: "target", From: old.Target, To: cf.Target},
+		})
+		if len(fields) > 0 {
+			d.Changed = append(d.Changed, ConfigChange{Path: cf.Path, Fields: fields})
+		}
+	}
+
+	sort.Slice(d.Changed, func(i, j int) bool {
+		return d.Changed[i].Path < d.Changed[j].Path
+	})
+
+	return d
+}
+
+// diffVersionProbes compares version probes by tool name, reporting each
+// added, removed or changed probe as a field named after its tool.
+func diffVersionProbes(a, b map[string]VersionProbe) []FieldChange {
+	var fields []FieldChange
+	for name, probe := range a {
+		if _, ok := b[name]; !ok {
+			fields = append(fields, FieldChange{Field: name, From: formatProbe(probe)})
+		}
+	}
+	for name, probe := range b {
+		old, ok := a[name]
+		if !ok {
+			fields = append(fields, FieldChange{Field: name, To: formatProbe(probe)})
+		} else if old != probe {
+			fields = append(fields, FieldChange{Field: name, From: formatProbe(old), To: formatProbe(probe)})
+		}
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Field < fields[j].Field
+	})
+
+	return fields
+}
+
+// diffFields keeps only the fields whose values differ.
+func diffFields(fields []FieldChange) []FieldChange {
+	var changed []FieldChange
+	for _, f := range fields {
+		if f.From != f.To {
+			changed = append(changed, f)
+		}
+	}
+	return changed
+}
+
+// toolsByName indexes tool strings by tool name.
+func toolsByName(tools []string) map[string]string {
+	m := make(map[string]string, len(tools))
+	for _, tool := range tools {
+		m[toolName(tool)] = tool
+	}
+	return m
+}
+
+// toolName returns the canonical tool string without its version suffix.
+// "cargo:ripgrep@14.1.0" becomes "cargo:ripgrep", "core:node@20" becomes "node".
+func toolName(tool string) string {
+	tool = CanonicalTool(tool)
+	if idx := strings.LastIndex(tool, "@"); idx > 0 {
+		return tool[:idx]
+	}
+	return tool
+}
+
+// toolVersion returns the version suffix of a tool string, or "" if unversioned.
+func toolVersion(tool string) string {
+	if idx := strings.LastIndex(tool, "@"); idx > 0 {
+		return tool[idx+1:]
+	}
+	return ""
+}
+
+// formatInt formats an integer option, treating zero as unset.
+func formatInt(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// formatProbe formats a version probe for a FieldChange: its flag, then
+// its regex if it has one.
+func formatProbe(p VersionProbe) string {
+	if p.Regex == "" {
+		return strconv.Quote(p.Flag)
+	}
+	return strconv.Quote(p.Flag) + " regex " + strconv.Quote(p.Regex)
+}
+
+// formatBool formats a flag for a FieldChange, using "" for false.
+func formatBool(b bool) string {
+	if !b {
+		return ""
+	}
+	return "true"
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffConfigs(t *testing.T) {
+	tests := []struct {
+		name string
+		a    *Config
+		b    *Config
+		want *Diff
+	}{
+		{
+			name: "identical",
+			a:    &Config{Tools: []string{"node@20.11.0"}, Configs: []ConfigFile{{Path: "~/.zshrc"}}},
+			b:    &Config{Tools: []string{"node@20.11.0"}, Configs: []ConfigFile{{Path: "~/.zshrc"}}},
+			want: &Diff{},
+		},
+		{
+			name: "tools added removed and changed",
+			a:    &Config{Tools: []string{"node@20.11.0", "python@3.12.1", "go@1.22.0"}},
+			b:    &Config{Tools: []string{"node@22.1.0", "cargo:ripgrep@14.1.0", "go@1.22.0"}},
+			want: &Diff{Tools: ToolsDiff{
+				Added:   []string{"cargo:ripgrep@14.1.0"},
+				Removed: []string{"python@3.12.1"},
+				Changed: []ToolChange{{Name: "node", From: "20.11.0", To: "22.1.0"}},
+			}},
+		},
+		{
+			name: "configs added and removed",
+			a:    &Config{Configs: []ConfigFile{{Path: "~/.zshrc"}, {Path: "~/.vimrc"}}},
+			b:    &Config{Configs: []ConfigFile{{Path: "~/.zshrc"}, {Path: "~/.gitconfig"}, {Path: "~/.bashrc"}}},
+			want: &Diff{Configs: ConfigsDiff{
+				Added:   []string{"~/.bashrc", "~/.gitconfig"},
+				Removed: []string{"~/.vimrc"},
+			}},
+		},
+		{
+			name: "git and options",
+			a:    &Config{Git: GitConfig{Branch: "main"}, Options: Options{BackupRetention: 5}},
+			b:    &Config{Git: GitConfig{Remote: "https://github.com/user/dotfiles", Branch: "main"}},
+			want: &Diff{
+				Git:     []FieldChange{{Field: "remote", From: "", To: "https://github.com/user/dotfiles"}},
+				Options: []FieldChange{{Field: "backup_retention", From: "5", To: ""}},
+			},
+		},
+		{
+			name: "profiles and backends",
+			a: &Config{
+				Profiles: map[string][]string{"work": {"node@20.11.0"}, "old": {"python@3.12.1"}, "same": {"go@1.22.0"}},
+				Backends: []string{"cargo"},
+			},
+			b: &Config{
+				Profiles: map[string][]string{"work": {"node@22.1.0", "kubectl@1.29.0"}, "new": {"ruby@3.3.0"}, "same": {"go@1.22.0"}},
+				Backends: []string{"cargo", "npm"},
+			},
+			want: &Diff{
+				Profiles: []ProfileDiff{
+					{Name: "new", Tools: ToolsDiff{Added: []string{"ruby@3.3.0"}}},
+					{Name: "old", Tools: ToolsDiff{Removed: []string{"python@3.12.1"}}},
+					{Name: "work", Tools: ToolsDiff{
+						Added:   []string{"kubectl@1.29.0"},
+						Changed: []ToolChange{{Name: "node", From: "20.11.0", To: "22.1.0"}},
+					}},
+				},
+				Backends: ListDiff{Added: []string{"npm"}},
+			},
+		},
+		{
+			name: "config flags and target",
+			a:    &Config{Configs: []ConfigFile{{Path: "~/.zshrc", Template: true}, {Path: "~/.ssh/config"}}},
+			b:    &Config{Configs: []ConfigFile{{Path: "~/.zshrc", Private: true}, {Path: "~/.ssh/config", Target: "~/.ssh/config.d/zerb"}}},
+			want: &Diff{Configs: ConfigsDiff{Changed: []ConfigChange{
+				{Path: "~/.ssh/config", Fields: []FieldChange{{Field: "target", To: "~/.ssh/config.d/zerb"}}},
+				{Path: "~/.zshrc", Fields: []FieldChange{{Field: "template", From: "true"}, {Field: "private", To: "true"}}},
+			}}},
+		},
+		{
+			name: "version probes and encryption",
+			a: &Config{
+				VersionProbes: map[string]VersionProbe{"java": {Flag: "-version"}, "dart": {Flag: "--version"}},
+				Encryption:    EncryptionConfig{Recipient: "user@example.com"},
+			},
+			b: &Config{
+				VersionProbes: map[string]VersionProbe{"java": {Flag: "-version", Regex: `version "([^"]+)"`}, "kubectl": {Flag: "version --client"}},
+				Encryption:    EncryptionConfig{Recipient: "age1example", Identity: "~/.config/age/keys.txt"},
+			},
+			want: &Diff{
+				VersionProbes: []FieldChange{
+					{Field: "dart", From: `"--version"`},
+					{Field: "java", From: `"-version"`, To: `"-version" regex "version \"([^\"]+)\""`},
+					{Field: "kubectl", To: `"version --client"`},
+				},
+				Encryption: []FieldChange{
+					{Field: "recipient", From: "user@example.com", To: "age1example"},
+					{Field: "identity", To: "~/.config/age/keys.txt"},
+				},
+			},
+		},
+		{
+			name: "nil treated as empty",
+			a:    nil,
+			b:    &Config{Tools: []string{"node@20.11.0"}},
+			want: &Diff{Tools: ToolsDiff{Added: []string{"node@20.11.0"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffConfigs(tt.a, tt.b)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffConfigs() = %+v, want %+v", got, tt.want)
+			}
+			if got.IsEmpty() != tt.want.IsEmpty() {
+				t.Errorf("IsEmpty() = %v, want %v", got.IsEmpty(), tt.want.IsEmpty())
+			}
+		})
+	}
+}
@@ -39,6 +39,30 @@
 //   - Math operations (math library)
 //   - Basic utilities (type, tostring, tonumber, pairs, ipairs)
 //
+// Locals and string concatenation are plain Lua and stay available, so a
+// config can name a version once and reuse it:
+//
+//	local node_ver = "20.11.0"
+//
+//	zerb = {
+//	  tools = { "node@" .. node_ver },
+//	}
+//
+// Computed values are validated like literal ones.
+//
+// ## Environment Variables
+//
+// os.getenv stays blocked. Callers can instead expose a whitelist of variables
+// through a read-only env table; unlisted or unset variables are nil:
+//
+//	parser := config.NewParser(detector).WithEnv([]string{"USER", "PROJECT_ROOT"})
+//
+//	zerb = {
+//	  meta = { name = env.USER and (env.USER .. "'s environment") or "default" },
+//	}
+//
+// Values longer than 256 characters are rejected, and assigning to env fails.
+//
 // ## Resource Limits
 //
 // To prevent denial-of-service attacks:
@@ -53,7 +77,10 @@
 //
 // All user inputs are validated:
 //   - Tool strings: Must match pattern ^([a-z0-9_-]+:)?[a-z0-9_/-]+(@[a-z0-9._-]+)?$
-//   - Config paths: No path traversal (..); restricted to home directory
+//     (also applied to profile entries)
+//   - Config paths: No path traversal (..); restricted to home directory,
+//     or to the base directory a path starts with ($XDG_CONFIG_HOME,
+//     $XDG_DATA_HOME, or a base added with PathRoots.WithBase)
 //   - Git URLs: Must use https:// or http:// (or SSH format git@host:repo)
 //   - String lengths: Maximum 256 characters for tool strings
 //
@@ -76,6 +103,15 @@
 //	    log.Fatalf("Parse error: %v", err)
 //	}
 //
+// Keys the parser does not recognize (e.g. a misspelled "toools") are
+// ignored. Use ParseStringWithWarnings to have them reported with their
+// line numbers:
+//
+//	cfg, warnings, err := parser.ParseStringWithWarnings(ctx, luaCode)
+//	for _, w := range warnings {
+//	    fmt.Println(w) // line 3: unknown key "toools" in zerb table is ignored (did you mean "tools"?)
+//	}
+//
 // ## Generating Configs
 //
 // Generate Lua code from a Go struct:
@@ -98,6 +134,51 @@
 //	  },
 //	}
 //
+// ## Tool Profiles
+//
+// Tools needed only on some machines can be grouped into named profiles:
+//
+//	zerb = {
+//	  tools = { "node@20.11.0" },
+//	  profiles = {
+//	    work = { "go@1.22.0" },
+//	    home = { "python@3.12.1" },
+//	  },
+//	}
+//
+// Each machine selects its profiles with ZERB_PROFILES (e.g. "work"), and
+// ResolveTools merges them with the base tools. A profile entry replaces
+// a base entry for the same tool. Configs without profiles are unaffected.
+//
+// ## Version Probes
+//
+// Drift detection reads a tool's version by running it with --version or
+// -v. Tools that need other arguments, or whose output is misread, can be
+// given a probe: the arguments, and a regex whose first group (or whole
+// match) is the version:
+//
+//	zerb = {
+//	  version_probe = {
+//	    mytool = { flag = "version --short", regex = "mytool v(\\S+)" },
+//	  },
+//	}
+//
+// Probes replace the built-in rules for the same tool (e.g. java -version).
+//
+// ## Tool Backends
+//
+// A tool's backend prefix (e.g. "cargo:" in "cargo:ripgrep") must be one of
+// DefaultBackends, so a typo is reported with its line when the config is
+// parsed. Other backends, such as a custom plugin, are listed in backends:
+//
+//	zerb = {
+//	  backends = { "mybackend" },
+//	  tools = { "mybackend:mytool@1.0.0" },
+//	}
+//
+// Callers can replace the defaults with Parser.WithBackends, or validate a
+// Config directly with a Validator.
+//
 // ## Structured Logging
 //
 // Add logging to track config operations:
@@ -136,6 +217,7 @@
 // Lua configuration structure:
 //
 //	zerb = {
+//	  schema_version = 1,            -- written by the generator
 //	  meta = {
 //	    name = "My Environment",
 //	    description = "Development setup",
@@ -147,22 +229,38 @@
 //	  },
 //	  configs = {
 //	    "~/.zshrc",                  -- simple path
+//	    "$XDG_CONFIG_HOME/nvim",     -- relative to an XDG base directory
 //	    {
 //	      path = "~/.ssh/config",
 //	      template = true,           -- process as template
 //	      secrets = true,            -- encrypt with GPG
 //	      private = true,            -- chmod 600
 //	    },
+//	    {
+//	      path = "~/dotfiles/work.gitconfig",
+//	      target = "~/.gitconfig",   -- apply to a different location
+//	    },
 //	  },
 //	  git = {
 //	    remote = "https://github.com/user/dotfiles",
 //	    branch = "main",
+//	    per_host_branches = true,  -- commit snapshots to a branch per machine
 //	  },
 //	  config = {
 //	    backup_retention = 5,        -- keep last 5 snapshots
+//	    defaults = { private = true }, -- flags `zerb config add` applies unless given
 //	  },
 //	}
 //
+// ## Schema Versions
+//
+// schema_version records the schema a config was written for; configs
+// without it are version 1. The parser runs Migrate to bring older configs
+// up to CurrentSchemaVersion, and refuses configs with a newer version
+// (ErrSchemaTooNew) rather than silently dropping keys it does not know.
+// When the schema changes, bump CurrentSchemaVersion and register a
+// Migration for the previous version.
+//
 // # Context and Timeouts
 //
 // All parsing operations respect context cancellation and deadlines:
@@ -201,6 +299,7 @@
 //	type ValidationError struct {
 //	    Field   string  // Field that failed validation
 //	    Message string  // Error description
+//	    Line    int     // Source line, when known
 //	}
 //
 // # Design Decisions
@@ -250,7 +349,6 @@
 //   - Config diffing and merging
 //   - Schema versioning for migrations
 //   - Interactive config builder
-//   - Config linting and validation CLI
 //   - IDE integration (LSP for zerb.lua)
 //
 // # Related Packages
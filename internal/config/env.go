@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// luaGlobalEnv is the name of the read-only environment table in Lua configs.
+const luaGlobalEnv = "env"
+
+// envNamePattern matches valid environment variable names.
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// WithEnv returns a new Parser that exposes the named environment variables to
+// configs through a read-only `env` table, e.g. env.USER. Variables that are
+// not listed, or listed but unset, are nil inside Lua. os.getenv stays blocked,
+// so this curated table is the only way for a config to read the environment.
+func (p *Parser) WithEnv(names []string) *Parser {
+	return &Parser{
+		detector: p.detector,
+		logger:   p.logger,
+		envNames: append([]string(nil), names...),
+		roots:    p.roots,
+		backends: p.backends,
+	}
+}
+
+// injectEnvTable sets the read-only `env` global from the allowed variables.
+// Values longer than MaxToolStringLength are rejected.
+func injectEnvTable(L *lua.LState, names []string) error {
+	values := L.NewTable()
+	for _, name := range names {
+		if !envNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid environment variable name: %q", name)
+		}
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if len(value) > MaxToolStringLength {
+			return fmt.Errorf("environment variable %s too long (%d chars, max %d)", name, len(value), MaxToolStringLength)
+		}
+		L.SetField(values, name, lua.LString(value))
+	}
+
+	// Expose the values through an empty proxy whose metatable rejects writes.
+	// Configs can't reach the metatable since getmetatable/rawset are removed.
+	proxy := L.NewTable()
+	meta := L.NewTable()
+	L.SetField(meta, "__index", values)
+	L.SetField(meta, "__newindex", L.NewFunction(func(L *lua.LState) int {
+		L.RaiseError("env is read-only")
+		return 0
+	}))
+	L.SetField(meta, "__metatable", lua.LFalse)
+	L.SetMetatable(proxy, meta)
+
+	L.SetGlobal(luaGlobalEnv, proxy)
+	return nil
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParser_WithEnv(t *testing.T) {
+	t.Setenv("ZERB_TEST_USER", "alice")
+	t.Setenv("ZERB_TEST_SECRET", "hunter2")
+
+	parser := NewParser(nil).WithEnv([]string{"ZERB_TEST_USER", "ZERB_TEST_UNSET"})
+
+	tests := []struct {
+		name     string
+		code     string
+		wantName string
+		wantErr  string
+	}{
+		{
+			name:     "listed variable is visible",
+			code:     `zerb = { meta = { name = env.ZERB_TEST_USER } }`,
+			wantName: "alice",
+		},
+		{
+			name:     "unlisted variable is nil",
+			code:     `zerb = { meta = { name = env.ZERB_TEST_SECRET == nil and "hidden" or "leaked" } }`,
+			wantName: "hidden",
+		},
+		{
+			name:     "listed but unset variable is nil",
+			code:     `zerb = { meta = { name = env.ZERB_TEST_UNSET == nil and "unset" or "set" } }`,
+			wantName: "unset",
+		},
+		{
+			name:    "env is read-only",
+			code:    `env.ZERB_TEST_USER = "mallory"; zerb = {}`,
+			wantErr: "read-only",
+		},
+		{
+			name:    "new keys cannot be added",
+			code:    `env.HOME = "/tmp"; zerb = {}`,
+			wantErr: "read-only",
+		},
+		{
+			name:    "os.getenv stays blocked",
+			code:    `zerb = { meta = { name = os.getenv("ZERB_TEST_SECRET") } }`,
+			wantErr: "Lua syntax error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := parser.ParseString(context.Background(), tt.code)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseString() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseString() error = %v", err)
+			}
+			if cfg.Meta.Name != tt.wantName {
+				t.Errorf("Meta.Name = %q, want %q", cfg.Meta.Name, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestParser_WithEnv_Defaults(t *testing.T) {
+	t.Setenv("ZERB_TEST_USER", "alice")
+
+	// Without WithEnv, env exists but exposes nothing
+	cfg, err := NewParser(nil).ParseString(context.Background(), `zerb = { meta = { name = env.ZERB_TEST_USER or "none" } }`)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+	if cfg.Meta.Name != "none" {
+		t.Errorf("Meta.Name = %q, want %q", cfg.Meta.Name, "none")
+	}
+
+	// WithLogger keeps the allowed variables
+	parser := NewParser(nil).WithEnv([]string{"ZERB_TEST_USER"}).WithLogger(nil)
+	cfg, err = parser.ParseString(context.Background(), `zerb = { meta = { name = env.ZERB_TEST_USER } }`)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+	if cfg.Meta.Name != "alice" {
+		t.Errorf("Meta.Name = %q, want %q", cfg.Meta.Name, "alice")
+	}
+}
+
+func TestParser_WithEnv_Limits(t *testing.T) {
+	t.Setenv("ZERB_TEST_LONG", strings.Repeat("x", MaxToolStringLength+1))
+
+	if _, err := NewParser(nil).WithEnv([]string{"ZERB_TEST_LONG"}).ParseString(context.Background(), `zerb = {}`); err == nil {
+		t.Error("ParseString() should reject an over-long environment value")
+	}
+
+	if _, err := NewParser(nil).WithEnv([]string{"BAD-NAME"}).ParseString(context.Background(), `zerb = {}`); err == nil {
+		t.Error("ParseString() should reject an invalid variable name")
+	}
+}
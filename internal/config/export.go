@@ -0,0 +1,44 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ExportJSON encodes cfg as JSON for sharing with other machines, e.g. to
+// compare setups with `zerb diff-env`. The export is stamped with
+// CurrentSchemaVersion.
+func ExportJSON(cfg *Config) ([]byte, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	export := *cfg
+	export.SchemaVersion = CurrentSchemaVersion
+
+	data, err := json.MarshalIndent(&export, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode config: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// ParseJSON decodes a config exported by ExportJSON, migrating it to
+// CurrentSchemaVersion and validating it. Returns ErrSchemaTooNew for an
+// export from a newer ZERB.
+func ParseJSON(data []byte) (*Config, error) {
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("decode config: %w", err)
+	}
+
+	migrated, _, err := Migrate(&cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrated.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return migrated, nil
+}
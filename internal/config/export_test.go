@@ -0,0 +1,68 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExportJSON_RoundTrip(t *testing.T) {
+	cfg := &Config{
+		Meta:     Meta{Name: "Laptop"},
+		Tools:    []string{"node@20.11.0", "cargo:ripgrep@14.0.0"},
+		Profiles: map[string][]string{"work": {"go@1.22.0"}},
+		Configs:  []ConfigFile{{Path: "~/.gitconfig"}, {Path: "~/.config/nvim/", Recursive: true}},
+		Git:      GitConfig{Remote: "https://github.com/user/dotfiles", Branch: "main"},
+		Options:  Options{BackupRetention: 5},
+	}
+
+	data, err := ExportJSON(cfg)
+	if err != nil {
+		t.Fatalf("ExportJSON() error = %v", err)
+	}
+	if !strings.Contains(string(data), `"schema_version": 1`) {
+		t.Errorf("export is missing schema_version:\n%s", data)
+	}
+
+	got, err := ParseJSON(data)
+	if err != nil {
+		t.Fatalf("ParseJSON() error = %v", err)
+	}
+	want := *cfg
+	want.SchemaVersion = CurrentSchemaVersion
+	if !reflect.DeepEqual(got, &want) {
+		t.Errorf("ParseJSON(ExportJSON()) = %+v, want %+v", got, &want)
+	}
+	if cfg.SchemaVersion != 0 {
+		t.Error("ExportJSON() modified its argument")
+	}
+}
+
+func TestParseJSON_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+		wantIs  error
+	}{
+		{name: "not JSON", data: "zerb = {}", wantErr: "decode config"},
+		{name: "invalid tool", data: `{"tools": ["bad tool!"]}`, wantErr: "invalid config"},
+		{name: "newer schema", data: `{"schema_version": 99, "tools": ["node@20"]}`, wantIs: ErrSchemaTooNew},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseJSON([]byte(tt.data))
+			if err == nil {
+				t.Fatal("ParseJSON() expected error")
+			}
+			if tt.wantIs != nil && !errors.Is(err, tt.wantIs) {
+				t.Errorf("error = %v, want %v", err, tt.wantIs)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -6,12 +6,30 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
 )
 
 // Generator generates Lua configuration code from Go structs.
+// Sections are written in a fixed order and empty ones are omitted, so
+// successive snapshots diff minimally.
 type Generator struct {
 	indent string // Indentation string (default: two spaces)
 	logger Logger
+	clock  clock.Clock // Time source for header and snapshot timestamps
+	opts   GenerateOptions
+}
+
+// GenerateOptions customizes generated output.
+type GenerateOptions struct {
+	// Header is a comment block written at the top of the file, before the
+	// generated header. Lines not already starting with "--" are commented out.
+	Header string
+
+	// ToolComments maps a tool string ("node@20.11.0") or tool name ("node")
+	// to a trailing comment written after that tool, e.g. why it was pinned.
+	// An entry for the full tool string takes precedence over one for its name.
+	ToolComments map[string]string
 }
 
 // NewGenerator creates a new Lua config generator.
@@ -19,6 +37,7 @@ func NewGenerator() *Generator {
 	return &Generator{
 		indent: "  ", // Two spaces
 		logger: defaultLogger(),
+		clock:  clock.Real{},
 	}
 }
 
@@ -30,9 +49,43 @@ func (g *Generator) WithLogger(logger Logger) *Generator {
 	return &Generator{
 		indent: g.indent,
 		logger: logger,
+		clock:  g.clock,
+		opts:   g.opts,
+	}
+}
+
+// WithClock returns a new Generator that uses the given clock for timestamps.
+func (g *Generator) WithClock(clk clock.Clock) *Generator {
+	if clk == nil {
+		clk = clock.Real{}
+	}
+	return &Generator{
+		indent: g.indent,
+		logger: g.logger,
+		clock:  clk,
+		opts:   g.opts,
+	}
+}
+
+// WithOptions returns a new Generator that applies opts to everything it generates.
+func (g *Generator) WithOptions(opts GenerateOptions) *Generator {
+	return &Generator{
+		indent: g.indent,
+		logger: g.logger,
+		clock:  g.clock,
+		opts:   opts,
 	}
 }
 
+// GenerateWithComments generates Lua code like Generate, annotating tools with
+// the given trailing comments (keyed by tool string or tool name). It
+// overrides any ToolComments set with WithOptions.
+func (g *Generator) GenerateWithComments(ctx context.Context, config *Config, toolComments map[string]string) (string, error) {
+	opts := g.opts
+	opts.ToolComments = toolComments
+	return g.WithOptions(opts).Generate(ctx, config)
+}
+
 // Generate generates Lua code from a Config struct.
 // The output is formatted and human-readable.
 func (g *Generator) Generate(ctx context.Context, config *Config) (string, error) {
@@ -48,43 +101,72 @@ func (g *Generator) Generate(ctx context.Context, config *Config) (string, error
 	}
 	var buf bytes.Buffer
 
+	g.writeHeader(&buf)
+	g.writeConfig(&buf, config)
+
+	return buf.String(), nil
+}
+
+// writeConfig writes the generated header comment and the zerb table.
+func (g *Generator) writeConfig(buf *bytes.Buffer, config *Config) {
 	// Write header comment
 	buf.WriteString("-- ZERB Configuration\n")
 	buf.WriteString("-- Generated: ")
-	buf.WriteString(time.Now().Format(time.RFC3339))
+	buf.WriteString(g.clock.Now().Format(time.RFC3339))
 	buf.WriteString("\n\n")
 
 	// Write zerb table
 	buf.WriteString("zerb = {\n")
 
+	// Stamp the schema version so older configs can be migrated later
+	fmt.Fprintf(buf, "%s%s = %d,\n\n", g.indent, luaFieldSchemaVersion, CurrentSchemaVersion)
+
 	// Write meta section
 	if config.Meta.Name != "" || config.Meta.Description != "" {
-		g.writeMeta(&buf, config.Meta)
+		g.writeMeta(buf, config.Meta)
 	}
 
 	// Write tools section
 	if len(config.Tools) > 0 {
-		g.writeTools(&buf, config.Tools)
+		g.writeTools(buf, config.Tools)
+	}
+
+	// Write profiles section
+	if len(config.Profiles) > 0 {
+		g.writeProfiles(buf, config)
+	}
+
+	// Write backends section
+	if len(config.Backends) > 0 {
+		g.writeBackends(buf, config.Backends)
 	}
 
 	// Write configs section
 	if len(config.Configs) > 0 {
-		g.writeConfigFiles(&buf, config.Configs)
+		g.writeConfigFiles(buf, config.Configs)
+	}
+
+	// Write version probe section
+	if len(config.VersionProbes) > 0 {
+		g.writeVersionProbes(buf, config)
+	}
+
+	// Write encryption section
+	if !config.Encryption.IsZero() {
+		g.writeEncryption(buf, config.Encryption)
 	}
 
 	// Write git section
-	if config.Git.Remote != "" || config.Git.Branch != "" {
-		g.writeGitConfig(&buf, config.Git)
+	if config.Git.Remote != "" || config.Git.Branch != "" || config.Git.PerHostBranches {
+		g.writeGitConfig(buf, config.Git)
 	}
 
 	// Write options section
-	if config.Options.BackupRetention > 0 {
-		g.writeOptions(&buf, config.Options)
+	if config.Options.BackupRetention > 0 || !config.Options.Defaults.IsZero() {
+		g.writeOptions(buf, config.Options)
 	}
 
 	buf.WriteString("}\n")
-
-	return buf.String(), nil
 }
 
 // GenerateTimestamped generates a timestamped config with metadata.
@@ -97,10 +179,13 @@ func (g *Generator) GenerateTimestamped(ctx context.Context, config *Config, git
 
 	// Generate timestamp
 	// Format: zerb.TIMESTAMP.lua (ending in .lua for editor syntax highlighting)
-	timestamp := time.Now().UTC()
+	timestamp := g.clock.Now().UTC()
 	timestampStr := timestamp.Format("20060102T150405Z")
 	filename = fmt.Sprintf("zerb.%s.lua", timestampStr)
 
+	// Write caller-supplied header first so it stays at the top of the file
+	g.writeHeader(&buf)
+
 	// Write header with metadata
 	buf.WriteString("-- ZERB CONFIG - Timestamped Snapshot\n")
 	buf.WriteString(fmt.Sprintf("-- Created: %s\n", timestamp.Format(time.RFC3339)))
@@ -121,13 +206,8 @@ func (g *Generator) GenerateTimestamped(ctx context.Context, config *Config, git
 	buf.WriteString("}\n\n")
 
 	// Generate main config
-	configCode, err := g.Generate(ctx, config)
-	if err != nil {
-		return "", "", err
-	}
-
 	buf.WriteString("-- ACTUAL CONFIG\n")
-	buf.WriteString(configCode)
+	g.writeConfig(&buf, config)
 	buf.WriteString("\nreturn zerb\n")
 
 	return filename, buf.String(), nil
@@ -167,6 +247,57 @@ func (g *Generator) writeTools(buf *bytes.Buffer, tools []string) {
 		buf.WriteString(g.indent)
 		buf.WriteString(g.indent)
 		buf.WriteString(g.quoteLuaString(tool))
+		buf.WriteString(",")
+		if comment := g.toolComment(tool); comment != "" {
+			buf.WriteString(" -- ")
+			buf.WriteString(comment)
+		}
+		buf.WriteString("\n")
+	}
+
+	buf.WriteString(g.indent)
+	buf.WriteString("},\n\n")
+}
+
+// writeProfiles writes the profiles section to the buffer, sorted by name.
+func (g *Generator) writeProfiles(buf *bytes.Buffer, config *Config) {
+	buf.WriteString(g.indent)
+	buf.WriteString("profiles = {\n")
+
+	for _, name := range config.ProfileNames() {
+		buf.WriteString(g.indent)
+		buf.WriteString(g.indent)
+		// Profile names may contain "-", which is not valid in a bare key
+		buf.WriteString("[")
+		buf.WriteString(g.quoteLuaString(name))
+		buf.WriteString("] = {\n")
+
+		for _, tool := range config.Profiles[name] {
+			buf.WriteString(g.indent)
+			buf.WriteString(g.indent)
+			buf.WriteString(g.indent)
+			buf.WriteString(g.quoteLuaString(tool))
+			buf.WriteString(",\n")
+		}
+
+		buf.WriteString(g.indent)
+		buf.WriteString(g.indent)
+		buf.WriteString("},\n")
+	}
+
+	buf.WriteString(g.indent)
+	buf.WriteString("},\n\n")
+}
+
+// writeBackends writes the backends section to the buffer.
+func (g *Generator) writeBackends(buf *bytes.Buffer, backends []string) {
+	buf.WriteString(g.indent)
+	buf.WriteString("backends = {\n")
+
+	for _, backend := range backends {
+		buf.WriteString(g.indent)
+		buf.WriteString(g.indent)
+		buf.WriteString(g.quoteLuaString(backend))
 		buf.WriteString(",\n")
 	}
 
@@ -174,6 +305,39 @@ func (g *Generator) writeTools(buf *bytes.Buffer, tools []string) {
 	buf.WriteString("},\n\n")
 }
 
+// toolComment returns the trailing comment for a tool, flattened to one line.
+func (g *Generator) toolComment(tool string) string {
+	comment, ok := g.opts.ToolComments[tool]
+	if !ok {
+		comment = g.opts.ToolComments[toolName(tool)]
+	}
+	return strings.Join(strings.Fields(comment), " ")
+}
+
+// writeHeader writes the caller-supplied header comment block, if any.
+func (g *Generator) writeHeader(buf *bytes.Buffer) {
+	header := strings.TrimRight(g.opts.Header, "\n")
+	if strings.TrimSpace(header) == "" {
+		return
+	}
+
+	for _, line := range strings.Split(header, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		switch {
+		case strings.HasPrefix(line, "--") && !strings.HasPrefix(line, "--["):
+			// Already a line comment ("--[" could open a block comment)
+			buf.WriteString(line)
+		case line == "":
+			buf.WriteString("--")
+		default:
+			buf.WriteString("-- ")
+			buf.WriteString(line)
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("\n")
+}
+
 // writeConfigFiles writes the configs section to the buffer.
 func (g *Generator) writeConfigFiles(buf *bytes.Buffer, configs []ConfigFile) {
 	buf.WriteString(g.indent)
@@ -184,7 +348,7 @@ func (g *Generator) writeConfigFiles(buf *bytes.Buffer, configs []ConfigFile) {
 		buf.WriteString(g.indent)
 
 		// If it's just a path with no options, write as a string
-		if !cf.Recursive && !cf.Template && !cf.Secrets && !cf.Private {
+		if !cf.Recursive && !cf.Template && !cf.Secrets && !cf.Private && cf.Target == "" {
 			buf.WriteString(g.quoteLuaString(cf.Path))
 			buf.WriteString(",\n")
 			continue
@@ -226,6 +390,14 @@ func (g *Generator) writeConfigFiles(buf *bytes.Buffer, configs []ConfigFile) {
 			buf.WriteString(g.indent)
 			buf.WriteString("private = true,\n")
 		}
+		if cf.Target != "" {
+			buf.WriteString(g.indent)
+			buf.WriteString(g.indent)
+			buf.WriteString(g.indent)
+			buf.WriteString("target = ")
+			buf.WriteString(g.quoteLuaString(cf.Target))
+			buf.WriteString(",\n")
+		}
 
 		buf.WriteString(g.indent)
 		buf.WriteString(g.indent)
@@ -236,6 +408,56 @@ func (g *Generator) writeConfigFiles(buf *bytes.Buffer, configs []ConfigFile) {
 	buf.WriteString("},\n\n")
 }
 
+// writeVersionProbes writes the version_probe section to the buffer,
+// sorted by tool name.
+func (g *Generator) writeVersionProbes(buf *bytes.Buffer, config *Config) {
+	buf.WriteString(g.indent)
+	buf.WriteString("version_probe = {\n")
+
+	for _, name := range config.VersionProbeNames() {
+		probe := config.VersionProbes[name]
+		buf.WriteString(g.indent)
+		buf.WriteString(g.indent)
+		buf.WriteString("[")
+		buf.WriteString(g.quoteLuaString(name))
+		buf.WriteString("] = { flag = ")
+		buf.WriteString(g.quoteLuaString(probe.Flag))
+		if probe.Regex != "" {
+			buf.WriteString(", regex = ")
+			buf.WriteString(g.quoteLuaString(probe.Regex))
+		}
+		buf.WriteString(" },\n")
+	}
+
+	buf.WriteString(g.indent)
+	buf.WriteString("},\n\n")
+}
+
+// writeEncryption writes the encryption section to the buffer.
+func (g *Generator) writeEncryption(buf *bytes.Buffer, encryption EncryptionConfig) {
+	buf.WriteString(g.indent)
+	buf.WriteString("encryption = {\n")
+
+	if encryption.Recipient != "" {
+		buf.WriteString(g.indent)
+		buf.WriteString(g.indent)
+		buf.WriteString("recipient = ")
+		buf.WriteString(g.quoteLuaString(encryption.Recipient))
+		buf.WriteString(",\n")
+	}
+
+	if encryption.Identity != "" {
+		buf.WriteString(g.indent)
+		buf.WriteString(g.indent)
+		buf.WriteString("identity = ")
+		buf.WriteString(g.quoteLuaString(encryption.Identity))
+		buf.WriteString(",\n")
+	}
+
+	buf.WriteString(g.indent)
+	buf.WriteString("},\n\n")
+}
+
 // writeGitConfig writes the git section to the buffer.
 func (g *Generator) writeGitConfig(buf *bytes.Buffer, git GitConfig) {
 	buf.WriteString(g.indent)
@@ -257,6 +479,12 @@ func (g *Generator) writeGitConfig(buf *bytes.Buffer, git GitConfig) {
 		buf.WriteString(",\n")
 	}
 
+	if git.PerHostBranches {
+		buf.WriteString(g.indent)
+		buf.WriteString(g.indent)
+		buf.WriteString("per_host_branches = true,\n")
+	}
+
 	buf.WriteString(g.indent)
 	buf.WriteString("},\n\n")
 }
@@ -272,6 +500,24 @@ func (g *Generator) writeOptions(buf *bytes.Buffer, options Options) {
 		fmt.Fprintf(buf, "backup_retention = %d,\n", options.BackupRetention)
 	}
 
+	if defaults := options.Defaults; !defaults.IsZero() {
+		buf.WriteString(g.indent)
+		buf.WriteString(g.indent)
+		buf.WriteString("defaults = {")
+		var flags []string
+		if defaults.Template {
+			flags = append(flags, " template = true")
+		}
+		if defaults.Secrets {
+			flags = append(flags, " secrets = true")
+		}
+		if defaults.Private {
+			flags = append(flags, " private = true")
+		}
+		buf.WriteString(strings.Join(flags, ","))
+		buf.WriteString(" },\n")
+	}
+
 	buf.WriteString(g.indent)
 	buf.WriteString("},\n")
 }
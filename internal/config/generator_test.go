@@ -2,8 +2,12 @@ package config
 
 import (
 	"context"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
 )
 
 func TestGenerator_Generate_Minimal(t *testing.T) {
@@ -142,6 +146,143 @@ func TestGenerator_GenerateTimestamped(t *testing.T) {
 	}
 }
 
+func TestGenerator_WithClock(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 1, 15, 14, 30, 22, 0, time.UTC))
+	gen := NewGenerator().WithClock(clk)
+
+	filename, content, err := gen.GenerateTimestamped(context.Background(), &Config{}, "")
+	if err != nil {
+		t.Fatalf("GenerateTimestamped() error = %v", err)
+	}
+
+	if filename != "zerb.20250115T143022Z.lua" {
+		t.Errorf("filename = %s, want zerb.20250115T143022Z.lua", filename)
+	}
+	if !strings.Contains(content, `timestamp = "2025-01-15T14:30:22Z"`) {
+		t.Errorf("content missing clock timestamp:\n%s", content)
+	}
+
+	// Advancing the clock yields a distinct snapshot name
+	clk.Advance(time.Second)
+	next, _, err := gen.GenerateTimestamped(context.Background(), &Config{}, "")
+	if err != nil {
+		t.Fatalf("GenerateTimestamped() error = %v", err)
+	}
+	if next != "zerb.20250115T143023Z.lua" {
+		t.Errorf("filename after Advance = %s, want zerb.20250115T143023Z.lua", next)
+	}
+}
+
+func TestGenerator_SectionOrder(t *testing.T) {
+	gen := NewGenerator()
+	cfg := &Config{
+		Options: Options{BackupRetention: 3},
+		Git:     GitConfig{Branch: "main"},
+		Configs: []ConfigFile{{Path: "~/.zshrc"}},
+		Tools:   []string{"python@3.12.1", "node@20.11.0", "cargo:ripgrep@14.1.0"},
+		Meta:    Meta{Name: "Ordered"},
+	}
+
+	output, err := gen.Generate(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	// Sections appear in the documented order
+	last := -1
+	for _, section := range []string{"meta = {", "tools = {", "configs = {", "git = {", "config = {"} {
+		idx := strings.Index(output, section)
+		if idx == -1 {
+			t.Fatalf("output missing section %q:\n%s", section, output)
+		}
+		if idx < last {
+			t.Errorf("section %q out of order:\n%s", section, output)
+		}
+		last = idx
+	}
+
+	// Tools keep their input order
+	last = -1
+	for _, tool := range cfg.Tools {
+		idx := strings.Index(output, `"`+tool+`"`)
+		if idx < last {
+			t.Errorf("tool %q reordered:\n%s", tool, output)
+		}
+		last = idx
+	}
+}
+
+func TestGenerator_WithOptions_Header(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 1, 15, 14, 30, 22, 0, time.UTC))
+	gen := NewGenerator().WithClock(clk).WithOptions(GenerateOptions{
+		Header: "My workstation config\n\n-- Keep tools sorted by team\n--[[ not a block comment\n",
+	})
+	wantHeader := "-- My workstation config\n--\n-- Keep tools sorted by team\n-- --[[ not a block comment\n\n"
+
+	output, err := gen.Generate(context.Background(), &Config{Tools: []string{"node@20.11.0"}})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !strings.HasPrefix(output, wantHeader+"-- ZERB Configuration\n") {
+		t.Errorf("Generate() output does not start with header:\n%s", output)
+	}
+
+	_, content, err := gen.GenerateTimestamped(context.Background(), &Config{Tools: []string{"node@20.11.0"}}, "")
+	if err != nil {
+		t.Fatalf("GenerateTimestamped() error = %v", err)
+	}
+	if !strings.HasPrefix(content, wantHeader+"-- ZERB CONFIG - Timestamped Snapshot\n") {
+		t.Errorf("GenerateTimestamped() output does not start with header:\n%s", content)
+	}
+	if strings.Count(content, "My workstation config") != 1 {
+		t.Errorf("header written more than once:\n%s", content)
+	}
+
+	// Header survives parsing and generation is stable
+	parsed, err := NewParser(nil).ParseString(context.Background(), content)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+	_, again, err := gen.GenerateTimestamped(context.Background(), parsed, "")
+	if err != nil {
+		t.Fatalf("GenerateTimestamped() error = %v", err)
+	}
+	if again != content {
+		t.Errorf("regenerated output differs:\n--- first\n%s\n--- second\n%s", content, again)
+	}
+}
+
+func TestGenerator_GenerateWithComments(t *testing.T) {
+	gen := NewGenerator()
+	cfg := &Config{Tools: []string{"node@20.11.0", "python@3.12.1", "go@1.22.0"}}
+
+	output, err := gen.GenerateWithComments(context.Background(), cfg, map[string]string{
+		"node@20.11.0": "pinned for legacy app",
+		"python":       "matches CI\nimage",
+	})
+	if err != nil {
+		t.Fatalf("GenerateWithComments() error = %v", err)
+	}
+
+	for _, want := range []string{
+		`"node@20.11.0", -- pinned for legacy app`,
+		`"python@3.12.1", -- matches CI image`,
+		"\"go@1.22.0\",\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+
+	parsed, err := NewParser(nil).ParseString(context.Background(), output)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+	if strings.Join(parsed.Tools, ",") != strings.Join(cfg.Tools, ",") {
+		t.Errorf("round-tripped tools = %v, want %v", parsed.Tools, cfg.Tools)
+	}
+}
+
 func TestGenerator_QuoteLuaString(t *testing.T) {
 	gen := NewGenerator()
 
@@ -207,14 +348,22 @@ func TestGenerator_RoundTrip(t *testing.T) {
 		Tools: []string{
 			"node@20.11.0",
 			"python@3.12.1",
+			"mybackend:mytool@1.0.0",
 		},
+		Backends: []string{"mybackend"},
 		Configs: []ConfigFile{
 			{Path: "~/.zshrc"},
 			{Path: "~/.config/nvim/", Recursive: true},
+			{Path: "~/dotfiles/work.gitconfig", Target: "~/.gitconfig"},
+		},
+		Encryption: EncryptionConfig{
+			Recipient: "age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p",
+			Identity:  "~/.config/age/work.txt",
 		},
 		Git: GitConfig{
-			Remote: "https://github.com/test/repo",
-			Branch: "main",
+			Remote:          "https://github.com/test/repo",
+			Branch:          "main",
+			PerHostBranches: true,
 		},
 		Options: Options{
 			BackupRetention: 3,
@@ -255,9 +404,25 @@ func TestGenerator_RoundTrip(t *testing.T) {
 		}
 	}
 
+	if !reflect.DeepEqual(parsed.Backends, original.Backends) {
+		t.Errorf("Backends = %v, want %v", parsed.Backends, original.Backends)
+	}
+
 	if len(parsed.Configs) != len(original.Configs) {
 		t.Errorf("Configs length = %d, want %d", len(parsed.Configs), len(original.Configs))
 	}
+	for i := range original.Configs {
+		if i >= len(parsed.Configs) {
+			break
+		}
+		if parsed.Configs[i] != original.Configs[i] {
+			t.Errorf("Configs[%d] = %+v, want %+v", i, parsed.Configs[i], original.Configs[i])
+		}
+	}
+
+	if parsed.Encryption != original.Encryption {
+		t.Errorf("Encryption = %+v, want %+v", parsed.Encryption, original.Encryption)
+	}
 
 	if parsed.Git.Remote != original.Git.Remote {
 		t.Errorf("Git.Remote = %s, want %s", parsed.Git.Remote, original.Git.Remote)
@@ -265,6 +430,9 @@ func TestGenerator_RoundTrip(t *testing.T) {
 	if parsed.Git.Branch != original.Git.Branch {
 		t.Errorf("Git.Branch = %s, want %s", parsed.Git.Branch, original.Git.Branch)
 	}
+	if parsed.Git.PerHostBranches != original.Git.PerHostBranches {
+		t.Errorf("Git.PerHostBranches = %v, want %v", parsed.Git.PerHostBranches, original.Git.PerHostBranches)
+	}
 
 	if parsed.Options.BackupRetention != original.Options.BackupRetention {
 		t.Errorf("Options.BackupRetention = %d, want %d", parsed.Options.BackupRetention, original.Options.BackupRetention)
@@ -317,6 +485,11 @@ func TestGenerator_ConfigFileFormatting(t *testing.T) {
 			config: ConfigFile{Path: "~/.ssh/config", Template: true, Secrets: true, Private: true},
 			want:   "template = true",
 		},
+		{
+			name:   "with target only",
+			config: ConfigFile{Path: "~/dotfiles/work.gitconfig", Target: "~/.gitconfig"},
+			want:   `target = "~/.gitconfig"`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,310 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Severity ranks how serious a lint finding is.
+type Severity int
+
+const (
+	// SeverityInfo is a suggestion that needs no action.
+	SeverityInfo Severity = iota
+	// SeverityWarning is likely a mistake but does not stop the config from loading.
+	SeverityWarning
+	// SeverityError makes the config fail to load.
+	SeverityError
+)
+
+// String returns the string representation of the severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// LintFinding is a single problem reported by Lint.
+type LintFinding struct {
+	Severity Severity
+	Line     int    // 1-based source line, 0 when unknown
+	Field    string // e.g. "tools[2]" or "git.remote"; empty for syntax errors
+	Message  string
+}
+
+// String returns the finding as "line N: severity: field: message".
+func (f LintFinding) String() string {
+	var sb strings.Builder
+	if f.Line > 0 {
+		fmt.Fprintf(&sb, "line %d: ", f.Line)
+	}
+	sb.WriteString(f.Severity.String())
+	sb.WriteString(": ")
+	if f.Field != "" {
+		sb.WriteString(f.Field)
+		sb.WriteString(": ")
+	}
+	sb.WriteString(f.Message)
+	return sb.String()
+}
+
+// versionAliases are non-numeric versions accepted without a warning
+var versionAliases = map[string]bool{
+	"latest":  true,
+	"lts":     true,
+	"stable":  true,
+	"system":  true,
+	"nightly": true,
+}
+
+// luaErrorLinePattern extracts the line number from a sanitized Lua error
+var luaErrorLinePattern = regexp.MustCompile(`config(?::| line:)(\d+)`)
+
+// Lint parses a config and reports every problem it finds, each with a
+// severity and the source line it was found on. Unlike ParseString, Lint
+// does not stop at the first validation error: every tool, config path and
+// git setting is checked. It also warns about unknown keys, duplicate tools
+// and config paths, implausible versions, config paths missing on disk and
+// plain-http git remotes, and notes tools not pinned to a version. A config
+// path listed twice with different flags is an error.
+//
+// The returned config is nil when the Lua code cannot be evaluated or was
+// written for a newer schema_version.
+func (p *Parser) Lint(ctx context.Context, content string) (*Config, []LintFinding) {
+	cfg, warnings, err := p.evaluate(ctx, content)
+	if err != nil {
+		finding := LintFinding{Severity: SeverityError, Message: FormatError(err, false)}
+		var parseErr *ParseError
+		if errors.As(err, &parseErr) {
+			if m := luaErrorLinePattern.FindStringSubmatch(parseErr.Detail); m != nil {
+				finding.Line, _ = strconv.Atoi(m[1])
+			}
+		}
+		return nil, []LintFinding{finding}
+	}
+
+	lines := newLineIndex(content)
+	var findings []LintFinding
+	add := func(severity Severity, line int, field, format string, a ...interface{}) {
+		findings = append(findings, LintFinding{
+			Severity: severity,
+			Line:     line,
+			Field:    field,
+			Message:  fmt.Sprintf(format, a...),
+		})
+	}
+
+	// Schema version: a newer schema may use keys this build does not know
+	migrated, _, err := Migrate(cfg)
+	if err != nil {
+		add(SeverityError, findKeyLine(content, luaFieldSchemaVersion), luaFieldSchemaVersion,
+			"schema_version %d is newer than this zerb supports (%d)", cfg.SchemaVersion, CurrentSchemaVersion)
+		return nil, findings
+	}
+	cfg = migrated
+
+	// Unknown keys
+	for _, w := range warnings {
+		add(SeverityWarning, w.Line, w.Key, "%s", w.Message)
+	}
+
+	// Backends
+	for i, backend := range cfg.Backends {
+		if !backendNamePattern.MatchString(backend) {
+			add(SeverityError, lines.find(backend), fmt.Sprintf("backends[%d]", i), "invalid backend name %q (use lowercase letters, digits, - and _)", backend)
+		}
+	}
+	backends := p.backends
+	if backends == nil {
+		backends = DefaultBackends
+	}
+	allowed := cfg.allowedBackends(backends)
+
+	// Tools
+	if len(cfg.Tools) > MaxToolCount {
+		add(SeverityError, 0, "tools", "too many tools (%d), maximum is %d", len(cfg.Tools), MaxToolCount)
+	}
+	seen := make(map[string]int, len(cfg.Tools))
+	for i, tool := range cfg.Tools {
+		field := fmt.Sprintf("tools[%d]", i)
+		line := lines.find(tool)
+
+		if err := validateTool(tool, allowed); err != nil {
+			add(SeverityError, line, field, "%s", err)
+			continue
+		}
+
+		name := toolName(tool)
+		if first, ok := seen[name]; ok {
+			add(SeverityWarning, line, field, "duplicate tool %q (already declared at tools[%d])", name, first)
+		} else {
+			seen[name] = i
+		}
+
+		if version := toolVersion(tool); version == "" {
+			add(SeverityInfo, line, field, "%s is not pinned to a version", name)
+		} else if reason := implausibleVersion(version); reason != "" {
+			add(SeverityWarning, line, field, "version %q %s", version, reason)
+		}
+	}
+
+	// Profiles
+	for _, name := range cfg.ProfileNames() {
+		if !profileNamePattern.MatchString(name) {
+			add(SeverityError, findKeyLine(content, name), "profiles", "invalid profile name %q (use lowercase letters, digits, - and _)", name)
+			continue
+		}
+		profileTools := cfg.Profiles[name]
+		if len(profileTools) > MaxToolCount {
+			add(SeverityError, 0, "profiles."+name, "too many tools (%d), maximum is %d", len(profileTools), MaxToolCount)
+		}
+		for i, tool := range profileTools {
+			if err := validateTool(tool, allowed); err != nil {
+				add(SeverityError, lines.find(tool), fmt.Sprintf("profiles.%s[%d]", name, i), "%s", err)
+			}
+		}
+	}
+
+	// Config files
+	if len(cfg.Configs) > MaxConfigFileCount {
+		add(SeverityError, 0, "configs", "too many config files (%d), maximum is %d", len(cfg.Configs), MaxConfigFileCount)
+	}
+	configLines := make([]int, len(cfg.Configs))
+	for i, cf := range cfg.Configs {
+		field := fmt.Sprintf("configs[%d]", i)
+		if cf.Path == "" {
+			add(SeverityError, 0, field, "path cannot be empty")
+			continue
+		}
+
+		line := lines.find(cf.Path)
+		configLines[i] = line
+		if err := validateConfigPath(cf.Path); err != nil {
+			add(SeverityError, line, field+".path", "%s", err)
+			continue
+		}
+
+		if normalized, err := NormalizeConfigPath(cf.Path); err == nil {
+			if _, err := os.Lstat(normalized); os.IsNotExist(err) {
+				add(SeverityWarning, line, field+".path", "%s does not exist on this machine", cf.Path)
+			}
+		}
+	}
+
+	for _, dup := range findDuplicateConfigs(cfg.Configs, NormalizeConfigPath) {
+		severity := SeverityWarning
+		if dup.Conflict {
+			severity = SeverityError
+		}
+		add(severity, configLines[dup.Index], fmt.Sprintf("configs[%d]", dup.Index), "%s", dup.message(cfg.Configs))
+	}
+
+	// Git
+	if cfg.Git.Remote != "" {
+		line := lines.find(cfg.Git.Remote)
+		if err := validateGitRemote(cfg.Git.Remote); err != nil {
+			add(SeverityError, line, "git.remote", "%s", err)
+		} else if u, err := url.Parse(cfg.Git.Remote); err == nil && u.Scheme == "http" {
+			add(SeverityWarning, line, "git.remote", "remote uses unencrypted http://; prefer https://")
+		}
+	}
+
+	// Report in source order; findings without a line go last
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i].Line, findings[j].Line
+		if a == 0 || b == 0 {
+			return a != 0 && b == 0
+		}
+		return a < b
+	})
+
+	return cfg, findings
+}
+
+// HasLintErrors reports whether any finding has error severity.
+func HasLintErrors(findings []LintFinding) bool {
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// DedupeTools removes later declarations of a tool already in the list,
+// keeping the first one. Returns the remaining tools and the removed entries.
+func DedupeTools(tools []string) (kept, removed []string) {
+	seen := make(map[string]bool, len(tools))
+	kept = make([]string, 0, len(tools))
+	for _, tool := range tools {
+		name := toolName(tool)
+		if seen[name] {
+			removed = append(removed, tool)
+			continue
+		}
+		seen[name] = true
+		kept = append(kept, tool)
+	}
+	return kept, removed
+}
+
+// implausibleVersion explains why a version string is almost certainly
+// wrong, or returns "" if it looks like a real version or a known alias.
+func implausibleVersion(version string) string {
+	switch {
+	case strings.Contains(version, ".."):
+		return "has an empty component"
+	case strings.ContainsAny(version[:1], ".-_") || strings.ContainsAny(version[len(version)-1:], ".-_"):
+		return "starts or ends with a separator"
+	case strings.ContainsAny(version, "0123456789"):
+		return ""
+	case versionAliases[version] || strings.HasPrefix(version, "lts-"):
+		return ""
+	default:
+		return "is neither a version number nor a known alias (latest, lts, stable, system, nightly)"
+	}
+}
+
+// lineIndex finds the source lines of quoted string values. Repeated
+// lookups of the same value return successive occurrences, so duplicate
+// entries map to their own lines.
+type lineIndex struct {
+	lines []string
+	next  map[string]int // value -> index of the line to search from
+}
+
+// newLineIndex creates a lineIndex for Lua source content
+func newLineIndex(content string) *lineIndex {
+	return &lineIndex{
+		lines: strings.Split(content, "\n"),
+		next:  make(map[string]int),
+	}
+}
+
+// find returns the 1-based line of the next occurrence of value as a quoted
+// Lua string, or 0 if it does not appear literally (e.g. it was computed).
+func (x *lineIndex) find(value string) int {
+	double := strconv.Quote(value)
+	single := "'" + value + "'"
+	for i := x.next[value]; i < len(x.lines); i++ {
+		if strings.Contains(x.lines[i], double) || strings.Contains(x.lines[i], single) {
+			x.next[value] = i + 1
+			return i + 1
+		}
+	}
+	return 0
+}
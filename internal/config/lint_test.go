@@ -0,0 +1,211 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParser_Lint(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.WriteFile(filepath.Join(home, ".zshrc"), []byte(""), 0644); err != nil {
+		t.Fatalf("failed to create .zshrc: %v", err)
+	}
+
+	content := `zerb = {
+  tools = {
+    "node@20.11.0",
+    "python@3..12",
+    "node@18.0.0",
+    "ripgrep",
+    "go@banana",
+  },
+  configs = {
+    "~/.zshrc",
+    "~/.missingrc",
+  },
+  git = {
+    remote = "http://example.com/dotfiles.git",
+  },
+}`
+
+	cfg, findings := NewParser(nil).Lint(context.Background(), content)
+	if cfg == nil {
+		t.Fatal("Lint() returned nil config for a config that evaluates")
+	}
+
+	want := []LintFinding{
+		{Severity: SeverityWarning, Line: 4, Field: "tools[1]", Message: `version "3..12" has an empty component`},
+		{Severity: SeverityWarning, Line: 5, Field: "tools[2]", Message: `duplicate tool "node" (already declared at tools[0])`},
+		{Severity: SeverityInfo, Line: 6, Field: "tools[3]", Message: "ripgrep is not pinned to a version"},
+		{Severity: SeverityWarning, Line: 7, Field: "tools[4]", Message: `version "banana" is neither a version number nor a known alias (latest, lts, stable, system, nightly)`},
+		{Severity: SeverityWarning, Line: 11, Field: "configs[1].path", Message: "~/.missingrc does not exist on this machine"},
+		{Severity: SeverityWarning, Line: 14, Field: "git.remote", Message: "remote uses unencrypted http://; prefer https://"},
+	}
+	if !reflect.DeepEqual(findings, want) {
+		t.Errorf("Lint() findings:\n%s\nwant:\n%s", formatFindings(findings), formatFindings(want))
+	}
+	if HasLintErrors(findings) {
+		t.Error("HasLintErrors() = true, want false for warnings only")
+	}
+}
+
+func TestParser_Lint_ReportsAllValidationErrors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	content := `zerb = {
+  tools = {
+    "Node@20",
+    "python@3.12.1",
+    "bad tool",
+    "carg:ripgrep@14.1.0",
+  },
+  git = {
+    remote = "ftp://example.com/repo",
+  },
+}`
+
+	cfg, findings := NewParser(nil).Lint(context.Background(), content)
+	if cfg == nil {
+		t.Fatal("Lint() returned nil config")
+	}
+
+	var got []string
+	for _, f := range findings {
+		if f.Severity != SeverityError {
+			t.Errorf("unexpected non-error finding: %s", f)
+			continue
+		}
+		got = append(got, f.Field)
+		if f.Line == 0 {
+			t.Errorf("finding for %s has no line number", f.Field)
+		}
+	}
+	if want := []string{"tools[0]", "tools[2]", "tools[3]", "git.remote"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("error fields = %v, want %v", got, want)
+	}
+	if !HasLintErrors(findings) {
+		t.Error("HasLintErrors() = false, want true")
+	}
+}
+
+func TestParser_Lint_DuplicateConfigs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, name := range []string{".zshrc", ".gitconfig"} {
+		if err := os.WriteFile(filepath.Join(home, name), []byte(""), 0644); err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+	}
+
+	content := `zerb = {
+  configs = {
+    "~/.zshrc",
+    { path = "~/.gitconfig", template = true },
+    "~/.zshrc",
+    { path = "~/.gitconfig" },
+  },
+}`
+
+	_, findings := NewParser(nil).Lint(context.Background(), content)
+
+	want := []LintFinding{
+		{Severity: SeverityWarning, Line: 5, Field: "configs[2]", Message: `duplicate config "~/.zshrc" (already declared at configs[0])`},
+		{Severity: SeverityError, Line: 6, Field: "configs[3]", Message: `duplicate config "~/.gitconfig" conflicts with configs[1]: flags {none} vs {template}`},
+	}
+	if !reflect.DeepEqual(findings, want) {
+		t.Errorf("Lint() findings:\n%s\nwant:\n%s", formatFindings(findings), formatFindings(want))
+	}
+}
+
+func TestParser_Lint_UnknownKeys(t *testing.T) {
+	content := `zerb = {
+  tools = { "node@20" },
+  gti = { branch = "main" },
+}`
+
+	_, findings := NewParser(nil).Lint(context.Background(), content)
+	want := []LintFinding{
+		{Severity: SeverityWarning, Line: 3, Field: "gti", Message: `unknown key "gti" in zerb table is ignored (did you mean "git"?)`},
+	}
+	if !reflect.DeepEqual(findings, want) {
+		t.Errorf("Lint() findings:\n%s\nwant:\n%s", formatFindings(findings), formatFindings(want))
+	}
+}
+
+func TestParser_Lint_SyntaxError(t *testing.T) {
+	content := "zerb = {\n  tools = {},\n}\nerror('boom')\n"
+
+	cfg, findings := NewParser(nil).Lint(context.Background(), content)
+	if cfg != nil {
+		t.Error("Lint() returned a config for code that fails to evaluate")
+	}
+	if len(findings) != 1 {
+		t.Fatalf("Lint() returned %d findings, want 1", len(findings))
+	}
+	if f := findings[0]; f.Severity != SeverityError || f.Line != 4 || !strings.Contains(f.Message, "boom") {
+		t.Errorf("finding = %+v, want error on line 4 mentioning boom", f)
+	}
+}
+
+func TestImplausibleVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"20.11.0", false},
+		{"3.12", false},
+		{"1.0.0-rc1", false},
+		{"latest", false},
+		{"lts", false},
+		{"lts-hydrogen", false},
+		{"1..2", true},
+		{".1", true},
+		{"1.", true},
+		{"abc", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if got := implausibleVersion(tt.version) != ""; got != tt.want {
+				t.Errorf("implausibleVersion(%q) flagged = %v, want %v", tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDedupeTools(t *testing.T) {
+	kept, removed := DedupeTools([]string{"node@20", "python@3.12", "node@18", "aqua:cli/cli@2.0", "python@3.11"})
+
+	if want := []string{"node@20", "python@3.12", "aqua:cli/cli@2.0"}; !reflect.DeepEqual(kept, want) {
+		t.Errorf("kept = %v, want %v", kept, want)
+	}
+	if want := []string{"node@18", "python@3.11"}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("removed = %v, want %v", removed, want)
+	}
+}
+
+func TestLintFinding_String(t *testing.T) {
+	f := LintFinding{Severity: SeverityWarning, Line: 3, Field: "tools[1]", Message: "duplicate"}
+	if got, want := f.String(), "line 3: warning: tools[1]: duplicate"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	f = LintFinding{Severity: SeverityError, Message: "syntax error"}
+	if got, want := f.String(), "error: syntax error"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+// formatFindings renders findings one per line for test failure output
+func formatFindings(findings []LintFinding) string {
+	lines := make([]string, len(findings))
+	for i, f := range findings {
+		lines[i] = "  " + f.String()
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,339 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MergePolicy controls how Merge resolves values set differently in both configs.
+type MergePolicy int
+
+const (
+	// PolicyOverlayWins resolves every conflict in favor of the overlay.
+	PolicyOverlayWins MergePolicy = iota
+	// PolicyStrict fails the merge on any conflict.
+	PolicyStrict
+)
+
+// String returns the string representation of the policy.
+func (p MergePolicy) String() string {
+	switch p {
+	case PolicyOverlayWins:
+		return "overlay-wins"
+	case PolicyStrict:
+		return "strict"
+	default:
+		return fmt.Sprintf("MergePolicy(%d)", int(p))
+	}
+}
+
+// ErrMergeConflict is returned by Merge under PolicyStrict when the configs conflict.
+var ErrMergeConflict = errors.New("config merge conflict")
+
+// MergeConflict describes a value set differently in the base and overlay configs.
+type MergeConflict struct {
+	// Field identifies the clashing value, e.g. "tools.node" or "git.remote"
+	Field   string
+	Base    string
+	Overlay string
+}
+
+// String returns a human-readable description of the conflict.
+func (c MergeConflict) String() string {
+	return fmt.Sprintf("%s: base %q, overlay %q", c.Field, c.Base, c.Overlay)
+}
+
+// Merge combines a base config with an overlay and returns the merged config
+// along with every conflict encountered. Neither input is modified.
+//
+// Tools are merged by tool name and config files by normalized path; entries
+// keep the base order, with overlay-only entries appended in overlay order.
+// Scalar fields set only in one config are taken from it. When both configs
+// set a value differently, the policy decides: PolicyOverlayWins takes the
+// overlay value, PolicyStrict returns an error wrapping ErrMergeConflict.
+//
+// The merged config keeps the schema version of the base config.
+//
+// Duplicate tools within either input are reported as a *ValidationError.
+// Merge performs no file I/O.
+func Merge(base, overlay *Config, policy MergePolicy) (*Config, []MergeConflict, error) {
+	if policy != PolicyOverlayWins && policy != PolicyStrict {
+		return nil, nil, fmt.Errorf("unknown merge policy: %s", policy)
+	}
+	if base == nil {
+		base = &Config{}
+	}
+	if overlay == nil {
+		overlay = &Config{}
+	}
+
+	if err := checkDuplicateTools("base", base.Tools); err != nil {
+		return nil, nil, err
+	}
+	if err := checkDuplicateTools("overlay", overlay.Tools); err != nil {
+		return nil, nil, err
+	}
+
+	var conflicts []MergeConflict
+	scalar := func(field, baseValue, overlayValue string) string {
+		if overlayValue == "" || overlayValue == baseValue {
+			return baseValue
+		}
+		if baseValue == "" {
+			return overlayValue
+		}
+		conflicts = append(conflicts, MergeConflict{Field: field, Base: baseValue, Overlay: overlayValue})
+		return overlayValue
+	}
+
+	merged := &Config{
+		SchemaVersion: base.SchemaVersion,
+		Meta: Meta{
+			Name:        scalar("meta.name", base.Meta.Name, overlay.Meta.Name),
+			Description: scalar("meta.description", base.Meta.Description, overlay.Meta.Description),
+		},
+		Encryption: EncryptionConfig{
+			Recipient: scalar("encryption.recipient", base.Encryption.Recipient, overlay.Encryption.Recipient),
+			Identity:  scalar("encryption.identity", base.Encryption.Identity, overlay.Encryption.Identity),
+		},
+		Git: GitConfig{
+			Remote: scalar("git.remote", base.Git.Remote, overlay.Git.Remote),
+			Branch: scalar("git.branch", base.Git.Branch, overlay.Git.Branch),
+			// Enabling is not a conflict; either config can opt in
+			PerHostBranches: base.Git.PerHostBranches || overlay.Git.PerHostBranches,
+		},
+	}
+
+	retention := scalar("options.backup_retention", formatInt(base.Options.BackupRetention), formatInt(overlay.Options.BackupRetention))
+	if retention != "" {
+		// Both inputs came from formatInt, so this cannot fail
+		merged.Options.BackupRetention, _ = strconv.Atoi(retention)
+	}
+	// As with per_host_branches, either config can turn a default on
+	merged.Options.Defaults = ConfigDefaults{
+		Template: base.Options.Defaults.Template || overlay.Options.Defaults.Template,
+		Secrets:  base.Options.Defaults.Secrets || overlay.Options.Defaults.Secrets,
+		Private:  base.Options.Defaults.Private || overlay.Options.Defaults.Private,
+	}
+
+	tools, toolConflicts := mergeTools(base.Tools, overlay.Tools)
+	merged.Tools = tools
+	conflicts = append(conflicts, toolConflicts...)
+
+	profiles, profileConflicts := mergeProfiles(base.Profiles, overlay.Profiles)
+	merged.Profiles = profiles
+	conflicts = append(conflicts, profileConflicts...)
+
+	// Allowing a backend is not a conflict either
+	merged.Backends = mergeBackends(base.Backends, overlay.Backends)
+
+	configs, configConflicts := mergeConfigFiles(base.Configs, overlay.Configs)
+	merged.Configs = configs
+	conflicts = append(conflicts, configConflicts...)
+
+	probes, probeConflicts := mergeVersionProbes(base.VersionProbes, overlay.VersionProbes)
+	merged.VersionProbes = probes
+	conflicts = append(conflicts, probeConflicts...)
+
+	if policy == PolicyStrict && len(conflicts) > 0 {
+		fields := make([]string, len(conflicts))
+		for i, c := range conflicts {
+			fields[i] = c.Field
+		}
+		return nil, conflicts, fmt.Errorf("%w: %s", ErrMergeConflict, strings.Join(fields, ", "))
+	}
+
+	return merged, conflicts, nil
+}
+
+// mergeTools merges tool lists by tool name.
+func mergeTools(base, overlay []string) ([]string, []MergeConflict) {
+	merged := make([]string, 0, len(base)+len(overlay))
+	index := make(map[string]int, len(base))
+	for _, tool := range base {
+		index[toolName(tool)] = len(merged)
+		merged = append(merged, tool)
+	}
+
+	var conflicts []MergeConflict
+	for _, tool := range overlay {
+		name := toolName(tool)
+		i, ok := index[name]
+		if !ok {
+			index[name] = len(merged)
+			merged = append(merged, tool)
+			continue
+		}
+		if CanonicalTool(merged[i]) != CanonicalTool(tool) {
+			conflicts = append(conflicts, MergeConflict{
+				Field:   "tools." + name,
+				Base:    merged[i],
+				Overlay: tool,
+			})
+			merged[i] = tool
+		}
+	}
+
+	return merged, conflicts
+}
+
+// mergeProfiles merges profiles by name, and the tools of a profile
+// defined in both inputs by tool name.
+func mergeProfiles(base, overlay map[string][]string) (map[string][]string, []MergeConflict) {
+	if len(base) == 0 && len(overlay) == 0 {
+		return nil, nil
+	}
+
+	merged := make(map[string][]string, len(base)+len(overlay))
+	for name, tools := range base {
+		merged[name] = append([]string(nil), tools...)
+	}
+
+	var conflicts []MergeConflict
+	names := make([]string, 0, len(overlay))
+	for name := range overlay {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		tools, toolConflicts := mergeTools(merged[name], overlay[name])
+		merged[name] = tools
+		for _, c := range toolConflicts {
+			c.Field = "profiles." + name + "." + c.Field
+			conflicts = append(conflicts, c)
+		}
+	}
+
+	return merged, conflicts
+}
+
+// mergeBackends returns the backends of both inputs, without duplicates.
+func mergeBackends(base, overlay []string) []string {
+	var merged []string
+	for _, backend := range append(slices.Clone(base), overlay...) {
+		if !slices.Contains(merged, backend) {
+			merged = append(merged, backend)
+		}
+	}
+	return merged
+}
+
+// mergeVersionProbes merges version probes by tool name.
+func mergeVersionProbes(base, overlay map[string]VersionProbe) (map[string]VersionProbe, []MergeConflict) {
+	if len(base) == 0 && len(overlay) == 0 {
+		return nil, nil
+	}
+
+	merged := make(map[string]VersionProbe, len(base)+len(overlay))
+	for name, probe := range base {
+		merged[name] = probe
+	}
+
+	var conflicts []MergeConflict
+	names := make([]string, 0, len(overlay))
+	for name := range overlay {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		probe := overlay[name]
+		if existing, ok := merged[name]; ok && existing != probe {
+			conflicts = append(conflicts, MergeConflict{
+				Field:   "version_probe." + name,
+				Base:    fmt.Sprintf("flag=%s regex=%s", existing.Flag, existing.Regex),
+				Overlay: fmt.Sprintf("flag=%s regex=%s", probe.Flag, probe.Regex),
+			})
+		}
+		merged[name] = probe
+	}
+
+	return merged, conflicts
+}
+
+// mergeConfigFiles merges config file lists by normalized path.
+func mergeConfigFiles(base, overlay []ConfigFile) ([]ConfigFile, []MergeConflict) {
+	merged := make([]ConfigFile, 0, len(base)+len(overlay))
+	index := make(map[string]int, len(base))
+	for _, cf := range base {
+		key := mergePathKey(cf.Path)
+		if _, ok := index[key]; !ok {
+			index[key] = len(merged)
+		}
+		merged = append(merged, cf)
+	}
+
+	var conflicts []MergeConflict
+	for _, cf := range overlay {
+		key := mergePathKey(cf.Path)
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(merged)
+			merged = append(merged, cf)
+			continue
+		}
+		if configFileFlags(merged[i]) != configFileFlags(cf) {
+			conflicts = append(conflicts, MergeConflict{
+				Field:   "configs." + key,
+				Base:    configFileFlags(merged[i]),
+				Overlay: configFileFlags(cf),
+			})
+		}
+		merged[i] = cf
+	}
+
+	return merged, conflicts
+}
+
+// checkDuplicateTools reports a tool listed more than once in one input.
+func checkDuplicateTools(source string, tools []string) error {
+	seen := make(map[string]int, len(tools))
+	for i, tool := range tools {
+		name := toolName(tool)
+		if first, ok := seen[name]; ok {
+			return &ValidationError{
+				Field:   fmt.Sprintf("%s tools[%d]", source, i),
+				Message: fmt.Sprintf("duplicate tool %q (already declared at tools[%d])", name, first),
+			}
+		}
+		seen[name] = i
+	}
+	return nil
+}
+
+// mergePathKey returns a lexical normalization of a config path used to match
+// entries across configs. Unlike NormalizeConfigPath it does not touch the
+// filesystem, so "$HOME" and symlinks are not resolved.
+func mergePathKey(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		return "~/" + strings.TrimPrefix(filepath.Clean(path[2:]), "/")
+	}
+	return filepath.Clean(path)
+}
+
+// configFileFlags describes a config file's options for conflict reporting.
+func configFileFlags(cf ConfigFile) string {
+	var flags []string
+	if cf.Recursive {
+		flags = append(flags, "recursive")
+	}
+	if cf.Template {
+		flags = append(flags, "template")
+	}
+	if cf.Secrets {
+		flags = append(flags, "secrets")
+	}
+	if cf.Private {
+		flags = append(flags, "private")
+	}
+	if cf.Target != "" {
+		flags = append(flags, "target="+cf.Target)
+	}
+	return strings.Join(flags, ",")
+}
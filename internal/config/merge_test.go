@@ -0,0 +1,136 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	base := &Config{
+		SchemaVersion: CurrentSchemaVersion,
+		Meta:          Meta{Name: "shared"},
+		Tools:         []string{"node@20.11.0", "python@3.12.1"},
+		Backends:      []string{"mybackend"},
+		Configs:       []ConfigFile{{Path: "~/.zshrc"}, {Path: "~/.config/nvim", Recursive: true}},
+		Git:           GitConfig{Remote: "https://github.com/team/dotfiles", Branch: "main"},
+		Options:       Options{BackupRetention: 5},
+	}
+	overlay := &Config{
+		Tools:    []string{"node@22.1.0", "cargo:ripgrep@14.1.0"},
+		Backends: []string{"other", "mybackend"},
+		Configs:  []ConfigFile{{Path: "~/.config//nvim/", Recursive: true, Template: true}, {Path: "~/.gitconfig"}},
+		Git:      GitConfig{Remote: "https://github.com/me/dotfiles"},
+	}
+
+	merged, conflicts, err := Merge(base, overlay, PolicyOverlayWins)
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+
+	want := &Config{
+		SchemaVersion: CurrentSchemaVersion,
+		Meta:          Meta{Name: "shared"},
+		Tools:         []string{"node@22.1.0", "python@3.12.1", "cargo:ripgrep@14.1.0"},
+		Backends:      []string{"mybackend", "other"},
+		Configs: []ConfigFile{
+			{Path: "~/.zshrc"},
+			{Path: "~/.config//nvim/", Recursive: true, Template: true},
+			{Path: "~/.gitconfig"},
+		},
+		Git:     GitConfig{Remote: "https://github.com/me/dotfiles", Branch: "main"},
+		Options: Options{BackupRetention: 5},
+	}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("Merge() =\n%+v\nwant\n%+v", merged, want)
+	}
+
+	wantConflicts := []MergeConflict{
+		{Field: "git.remote", Base: "https://github.com/team/dotfiles", Overlay: "https://github.com/me/dotfiles"},
+		{Field: "tools.node", Base: "node@20.11.0", Overlay: "node@22.1.0"},
+		{Field: "configs.~/.config/nvim", Base: "recursive", Overlay: "recursive,template"},
+	}
+	if !reflect.DeepEqual(conflicts, wantConflicts) {
+		t.Errorf("conflicts =\n%+v\nwant\n%+v", conflicts, wantConflicts)
+	}
+
+	// Inputs must not be modified
+	if base.Tools[0] != "node@20.11.0" || base.Git.Remote != "https://github.com/team/dotfiles" {
+		t.Error("Merge() modified the base config")
+	}
+}
+
+func TestMerge_Strict(t *testing.T) {
+	base := &Config{Tools: []string{"node@20.11.0"}, Git: GitConfig{Branch: "main"}}
+
+	t.Run("no conflicts", func(t *testing.T) {
+		overlay := &Config{Tools: []string{"node@20.11.0", "go@1.22.0"}, Git: GitConfig{Branch: "main"}}
+		merged, conflicts, err := Merge(base, overlay, PolicyStrict)
+		if err != nil {
+			t.Fatalf("Merge() error = %v", err)
+		}
+		if len(conflicts) != 0 {
+			t.Errorf("conflicts = %+v, want none", conflicts)
+		}
+		if want := []string{"node@20.11.0", "go@1.22.0"}; !reflect.DeepEqual(merged.Tools, want) {
+			t.Errorf("Tools = %v, want %v", merged.Tools, want)
+		}
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		overlay := &Config{Tools: []string{"node@22.1.0"}, Git: GitConfig{Branch: "dev"}}
+		merged, conflicts, err := Merge(base, overlay, PolicyStrict)
+		if !errors.Is(err, ErrMergeConflict) {
+			t.Fatalf("Merge() error = %v, want ErrMergeConflict", err)
+		}
+		if merged != nil {
+			t.Errorf("Merge() returned config %+v, want nil", merged)
+		}
+		if len(conflicts) != 2 {
+			t.Errorf("conflicts = %+v, want 2", conflicts)
+		}
+	})
+}
+
+func TestMerge_DuplicateTools(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    *Config
+		overlay *Config
+	}{
+		{
+			name:    "duplicate in base",
+			base:    &Config{Tools: []string{"node@20.11.0", "node@22.1.0"}},
+			overlay: &Config{},
+		},
+		{
+			name:    "duplicate in overlay",
+			base:    &Config{},
+			overlay: &Config{Tools: []string{"go@1.22.0", "go"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Merge(tt.base, tt.overlay, PolicyOverlayWins)
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Merge() error = %v, want *ValidationError", err)
+			}
+		})
+	}
+}
+
+func TestMerge_NilAndUnknownPolicy(t *testing.T) {
+	merged, conflicts, err := Merge(nil, &Config{Tools: []string{"node@20.11.0"}}, PolicyOverlayWins)
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if len(conflicts) != 0 || !reflect.DeepEqual(merged.Tools, []string{"node@20.11.0"}) {
+		t.Errorf("Merge(nil, overlay) = %+v, %+v", merged, conflicts)
+	}
+
+	if _, _, err := Merge(&Config{}, &Config{}, MergePolicy(42)); err == nil {
+		t.Error("Merge() with unknown policy should fail")
+	}
+}
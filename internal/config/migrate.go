@@ -0,0 +1,100 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// CurrentSchemaVersion is the config schema version this build of ZERB
+// reads and writes. The generator stamps it into every config.
+const CurrentSchemaVersion = 1
+
+// ErrSchemaTooNew is returned by Migrate for a config written for a newer
+// schema than CurrentSchemaVersion.
+var ErrSchemaTooNew = errors.New("config is newer than this zerb")
+
+// Migration upgrades a config from one schema version to the next.
+type Migration struct {
+	// Description summarizes the migration for changelogs
+	Description string
+
+	// Apply modifies cfg in place and returns a description of each change
+	// it made. cfg is a copy owned by Migrate.
+	Apply func(cfg *Config) ([]string, error)
+}
+
+// migrations[v] upgrades schema version v to v+1. Register a migration here
+// whenever CurrentSchemaVersion is bumped.
+var migrations = map[int]Migration{}
+
+// SchemaVersionOf returns the schema version of cfg. Configs written before
+// schema_version existed are version 1.
+func SchemaVersionOf(cfg *Config) int {
+	if cfg.SchemaVersion == 0 {
+		return 1
+	}
+	return cfg.SchemaVersion
+}
+
+// Migrate applies the registered migrations to bring cfg up to
+// CurrentSchemaVersion. It returns the migrated copy and a changelog of what
+// each migration did; cfg itself is not modified. A config that is already
+// current is returned unchanged with an empty changelog. Returns
+// ErrSchemaTooNew if cfg has a newer schema version than this build supports.
+func Migrate(cfg *Config) (*Config, []string, error) {
+	return migrate(cfg, CurrentSchemaVersion, migrations)
+}
+
+// migrate applies registry to bring cfg up to target
+func migrate(cfg *Config, target int, registry map[int]Migration) (*Config, []string, error) {
+	if cfg == nil {
+		return nil, nil, fmt.Errorf("config cannot be nil")
+	}
+
+	version := SchemaVersionOf(cfg)
+	if version > target {
+		return nil, nil, fmt.Errorf("%w: schema_version %d, this zerb supports up to %d\nUpgrade zerb to use this config",
+			ErrSchemaTooNew, version, target)
+	}
+
+	migrated := cloneConfig(cfg)
+	var changelog []string
+	for v := version; v < target; v++ {
+		migration, ok := registry[v]
+		if !ok {
+			return nil, nil, fmt.Errorf("no migration registered from schema_version %d", v)
+		}
+
+		changes, err := migration.Apply(migrated)
+		if err != nil {
+			return nil, nil, fmt.Errorf("migrate schema_version %d to %d (%s): %w", v, v+1, migration.Description, err)
+		}
+		changelog = append(changelog, fmt.Sprintf("schema_version %d → %d: %s", v, v+1, migration.Description))
+		for _, change := range changes {
+			changelog = append(changelog, "  "+change)
+		}
+	}
+	migrated.SchemaVersion = target
+
+	return migrated, changelog, nil
+}
+
+// cloneConfig returns a copy of cfg that shares no slices or maps with it
+func cloneConfig(cfg *Config) *Config {
+	clone := *cfg
+	clone.Tools = slices.Clone(cfg.Tools)
+	clone.Configs = slices.Clone(cfg.Configs)
+	clone.Backends = slices.Clone(cfg.Backends)
+	if cfg.Profiles != nil {
+		clone.Profiles = make(map[string][]string, len(cfg.Profiles))
+		for name, tools := range cfg.Profiles {
+			clone.Profiles[name] = slices.Clone(tools)
+		}
+	}
+	if cfg.VersionProbes != nil {
+		clone.VersionProbes = maps.Clone(cfg.VersionProbes)
+	}
+	return &clone
+}
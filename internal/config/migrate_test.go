@@ -0,0 +1,208 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// renameRetention stands in for a v1→v2 migration that moves
+// config.backup_retention to options.backup_retention
+var renameRetention = Migration{
+	Description: "move config.backup_retention to options.backup_retention",
+	Apply: func(cfg *Config) ([]string, error) {
+		if cfg.Options.BackupRetention == 0 {
+			return nil, nil
+		}
+		return []string{"renamed config.backup_retention to options.backup_retention"}, nil
+	},
+}
+
+func TestMigrate_Current(t *testing.T) {
+	cfg := &Config{
+		SchemaVersion: CurrentSchemaVersion,
+		Tools:         []string{"node@20.11.0"},
+		Profiles:      map[string][]string{"work": {"go@1.22.0"}},
+		Configs:       []ConfigFile{{Path: "~/.zshrc"}},
+		Options:       Options{BackupRetention: 5},
+	}
+
+	migrated, changelog, err := Migrate(cfg)
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if len(changelog) != 0 {
+		t.Errorf("changelog = %q, want empty", changelog)
+	}
+	if !reflect.DeepEqual(migrated, cfg) {
+		t.Errorf("Migrate() = %+v, want %+v", migrated, cfg)
+	}
+
+	// The result is a copy
+	migrated.Tools[0] = "node@18.0.0"
+	migrated.Profiles["work"][0] = "go@1.21.0"
+	if cfg.Tools[0] != "node@20.11.0" || cfg.Profiles["work"][0] != "go@1.22.0" {
+		t.Error("Migrate() result shares data with its input")
+	}
+}
+
+func TestMigrate_Unversioned(t *testing.T) {
+	migrated, changelog, err := Migrate(&Config{Tools: []string{"node@20.11.0"}})
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if migrated.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", migrated.SchemaVersion, CurrentSchemaVersion)
+	}
+	if len(changelog) != 0 {
+		t.Errorf("changelog = %q, want empty", changelog)
+	}
+}
+
+func TestMigrate_TooNew(t *testing.T) {
+	_, _, err := Migrate(&Config{SchemaVersion: CurrentSchemaVersion + 1})
+	if !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("Migrate() error = %v, want ErrSchemaTooNew", err)
+	}
+	if !strings.Contains(err.Error(), "config is newer than this zerb") {
+		t.Errorf("error = %q, want a clear message", err)
+	}
+}
+
+func TestMigrate_AppliesRegistered(t *testing.T) {
+	registry := map[int]Migration{1: renameRetention}
+
+	t.Run("old config is upgraded", func(t *testing.T) {
+		cfg := &Config{SchemaVersion: 1, Options: Options{BackupRetention: 5}}
+
+		migrated, changelog, err := migrate(cfg, 2, registry)
+		if err != nil {
+			t.Fatalf("migrate() error = %v", err)
+		}
+		if migrated.SchemaVersion != 2 {
+			t.Errorf("SchemaVersion = %d, want 2", migrated.SchemaVersion)
+		}
+		want := []string{
+			"schema_version 1 → 2: move config.backup_retention to options.backup_retention",
+			"  renamed config.backup_retention to options.backup_retention",
+		}
+		if !reflect.DeepEqual(changelog, want) {
+			t.Errorf("changelog = %q, want %q", changelog, want)
+		}
+		if cfg.SchemaVersion != 1 {
+			t.Error("migrate() modified its input")
+		}
+	})
+
+	t.Run("current config is a no-op", func(t *testing.T) {
+		cfg := &Config{SchemaVersion: 2, Options: Options{BackupRetention: 5}}
+
+		migrated, changelog, err := migrate(cfg, 2, registry)
+		if err != nil {
+			t.Fatalf("migrate() error = %v", err)
+		}
+		if len(changelog) != 0 {
+			t.Errorf("changelog = %q, want empty", changelog)
+		}
+		if !reflect.DeepEqual(migrated, cfg) {
+			t.Errorf("migrate() = %+v, want %+v", migrated, cfg)
+		}
+	})
+
+	t.Run("missing migration", func(t *testing.T) {
+		if _, _, err := migrate(&Config{SchemaVersion: 1}, 3, registry); err == nil || !strings.Contains(err.Error(), "from schema_version 2") {
+			t.Errorf("migrate() error = %v, want missing migration error", err)
+		}
+	})
+
+	t.Run("failing migration", func(t *testing.T) {
+		failing := map[int]Migration{1: {
+			Description: "broken",
+			Apply:       func(*Config) ([]string, error) { return nil, errors.New("boom") },
+		}}
+		if _, _, err := migrate(&Config{SchemaVersion: 1}, 2, failing); err == nil || !strings.Contains(err.Error(), "boom") {
+			t.Errorf("migrate() error = %v, want boom", err)
+		}
+	})
+}
+
+func TestParser_SchemaVersion(t *testing.T) {
+	ctx := context.Background()
+	parser := NewParser(nil)
+
+	tests := []struct {
+		name    string
+		code    string
+		want    int
+		wantErr string
+	}{
+		{name: "unversioned", code: `zerb = { tools = {} }`, want: CurrentSchemaVersion},
+		{name: "current", code: `zerb = { schema_version = 1 }`, want: 1},
+		{name: "newer", code: `zerb = { schema_version = 99 }`, wantErr: "config is newer than this zerb"},
+		{name: "string", code: `zerb = { schema_version = "1" }`, wantErr: "invalid schema_version"},
+		{name: "fraction", code: `zerb = { schema_version = 1.5 }`, wantErr: "invalid schema_version"},
+		{name: "zero", code: `zerb = { schema_version = 0 }`, wantErr: "invalid schema_version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, warnings, err := parser.ParseStringWithWarnings(ctx, tt.code)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseString() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseString() error = %v", err)
+			}
+			if cfg.SchemaVersion != tt.want {
+				t.Errorf("SchemaVersion = %d, want %d", cfg.SchemaVersion, tt.want)
+			}
+			if len(warnings) != 0 {
+				t.Errorf("warnings = %v, want none", warnings)
+			}
+		})
+	}
+}
+
+func TestGenerator_StampsSchemaVersion(t *testing.T) {
+	ctx := context.Background()
+
+	// Even a config parsed from an unversioned file is written as current
+	code, err := NewGenerator().Generate(ctx, &Config{Tools: []string{"node@20.11.0"}})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !strings.Contains(code, "schema_version = 1,") {
+		t.Errorf("generated config missing schema_version:\n%s", code)
+	}
+
+	cfg, err := NewParser(nil).ParseString(ctx, code)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+	if cfg.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", cfg.SchemaVersion, CurrentSchemaVersion)
+	}
+}
+
+func TestParser_Lint_SchemaTooNew(t *testing.T) {
+	content := `zerb = {
+  schema_version = 7,
+  tools = { "node@20.11.0" },
+}`
+
+	cfg, findings := NewParser(nil).Lint(context.Background(), content)
+	if cfg != nil {
+		t.Error("Lint() returned a config written for a newer schema")
+	}
+	if len(findings) != 1 || findings[0].Severity != SeverityError || findings[0].Line != 2 {
+		t.Fatalf("findings = %v, want one error on line 2", findings)
+	}
+	if !strings.Contains(findings[0].Message, "newer than this zerb") {
+		t.Errorf("message = %q", findings[0].Message)
+	}
+}
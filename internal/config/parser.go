@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
@@ -22,6 +23,10 @@ const (
 	// MaxConfigFileCount is the maximum number of config files allowed.
 	MaxConfigFileCount = 500
 
+	// MaxToolStringLength is the maximum length of a tool string, also applied
+	// to environment variable values exposed to configs.
+	MaxToolStringLength = 256
+
 	// DefaultParseTimeout is the default timeout for parsing a config (5 seconds).
 	DefaultParseTimeout = 5 * time.Second
 )
@@ -30,6 +35,9 @@ const (
 type Parser struct {
 	detector platform.Detector
 	logger   Logger
+	envNames []string   // Environment variables exposed through the env table
+	roots    *PathRoots // Allowed config path roots; nil uses DefaultPathRoots
+	backends []string   // Allowed tool backends; nil uses DefaultBackends
 }
 
 // NewParser creates a new config parser with the given platform detector.
@@ -49,6 +57,35 @@ func (p *Parser) WithLogger(logger Logger) *Parser {
 	return &Parser{
 		detector: p.detector,
 		logger:   logger,
+		envNames: p.envNames,
+		roots:    p.roots,
+		backends: p.backends,
+	}
+}
+
+// WithPathRoots returns a new Parser that validates config paths against
+// roots instead of the default home and XDG directories, e.g. to accept
+// paths under a caller-supplied base directory.
+func (p *Parser) WithPathRoots(roots PathRoots) *Parser {
+	return &Parser{
+		detector: p.detector,
+		logger:   p.logger,
+		envNames: p.envNames,
+		roots:    &roots,
+		backends: p.backends,
+	}
+}
+
+// WithBackends returns a new Parser that allows the given tool backends
+// instead of DefaultBackends. A config's own backends list is still
+// allowed on top of them.
+func (p *Parser) WithBackends(backends []string) *Parser {
+	return &Parser{
+		detector: p.detector,
+		logger:   p.logger,
+		envNames: p.envNames,
+		roots:    p.roots,
+		backends: append([]string{}, backends...),
 	}
 }
 
@@ -60,16 +97,75 @@ func (p *Parser) WithLogger(logger Logger) *Parser {
 //   - Parse timeout: 5 seconds (configurable via context)
 //   - Resource limits: Call stack depth, memory usage
 func (p *Parser) ParseString(ctx context.Context, luaCode string) (*Config, error) {
+	config, _, err := p.ParseStringWithWarnings(ctx, luaCode)
+	return config, err
+}
+
+// ParseStringWithWarnings parses a Lua config like ParseString and also
+// returns warnings for problems that do not stop the config from loading,
+// such as unknown keys in the zerb table (e.g. a misspelled "toools").
+// Warnings are in source order and sanitized for user display.
+func (p *Parser) ParseStringWithWarnings(ctx context.Context, luaCode string) (*Config, []ParseWarning, error) {
 	p.logger.Debug("parsing config", "size", len(luaCode))
 	start := time.Now()
 	defer func() {
 		p.logger.Debug("parse complete", "duration", time.Since(start))
 	}()
 
+	config, warnings, err := p.evaluate(ctx, luaCode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Bring configs written for an older schema up to date
+	config, changelog, err := Migrate(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, change := range changelog {
+		p.logger.Debug("config migrated", "change", change)
+	}
+
+	// Validate the extracted config
+	validator := Validator{Roots: p.roots, Backends: p.backends}
+	normalizePath := NormalizeConfigPath
+	if p.roots != nil {
+		normalizePath = p.roots.Normalize
+	}
+	if err := validator.Validate(config); err != nil {
+		// Point at the offending value when it was written literally
+		var verr *ValidationError
+		if errors.As(err, &verr) && verr.value != "" {
+			verr.Line = newLineIndex(luaCode).find(verr.value)
+		}
+		return nil, nil, &ParseError{
+			Message: "config validation failed",
+			Detail:  err.Error(),
+		}
+	}
+
+	// A config listed twice with the same flags is unambiguous: keep one
+	configs, dupWarnings := mergeDuplicateConfigs(config.Configs, normalizePath, luaCode)
+	if len(dupWarnings) > 0 {
+		config.Configs = configs
+		warnings = append(warnings, dupWarnings...)
+		sortWarnings(warnings)
+	}
+
+	for _, w := range warnings {
+		p.logger.Warn("config warning", "line", w.Line, "key", w.Key)
+	}
+
+	return config, warnings, nil
+}
+
+// evaluate runs the Lua config in a sandboxed VM and extracts the config
+// without validating it, along with any parse warnings.
+func (p *Parser) evaluate(ctx context.Context, luaCode string) (*Config, []ParseWarning, error) {
 	// Validate input size before parsing
 	if len(luaCode) > MaxConfigSize {
 		p.logger.Error("config file too large", "size", len(luaCode), "max", MaxConfigSize)
-		return nil, &ParseError{
+		return nil, nil, &ParseError{
 			Message: "config file too large",
 			Detail:  fmt.Sprintf("size %d bytes exceeds maximum %d bytes", len(luaCode), MaxConfigSize),
 		}
@@ -77,7 +173,7 @@ func (p *Parser) ParseString(ctx context.Context, luaCode string) (*Config, erro
 
 	// Check if context is already cancelled
 	if err := ctx.Err(); err != nil {
-		return nil, fmt.Errorf("context cancelled before parsing: %w", err)
+		return nil, nil, fmt.Errorf("context cancelled before parsing: %w", err)
 	}
 
 	// Create timeout context if the provided context doesn't have a deadline
@@ -97,30 +193,41 @@ func (p *Parser) ParseString(ctx context.Context, luaCode string) (*Config, erro
 	if p.detector != nil {
 		platformInfo, err := p.detector.Detect(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("platform detection failed: %w", err)
+			return nil, nil, fmt.Errorf("platform detection failed: %w", err)
 		}
 		if err := platform.InjectPlatformTable(L, platformInfo); err != nil {
-			return nil, fmt.Errorf("inject platform table: %w", err)
+			return nil, nil, fmt.Errorf("inject platform table: %w", err)
 		}
 	}
 
+	// Inject the read-only env table (only allowed variables are visible)
+	if err := injectEnvTable(L, p.envNames); err != nil {
+		return nil, nil, fmt.Errorf("inject env table: %w", err)
+	}
+
 	// Execute Lua code with timeout protection
 	if err := L.DoString(luaCode); err != nil {
 		// Check if timeout occurred
 		if ctx.Err() == context.DeadlineExceeded {
-			return nil, &ParseError{
+			return nil, nil, &ParseError{
 				Message: "config parsing timeout",
 				Detail:  "parsing took longer than allowed time limit (possible infinite loop)",
 			}
 		}
-		return nil, &ParseError{
+		return nil, nil, &ParseError{
 			Message: "Lua syntax error",
 			Detail:  sanitizeLuaError(err),
 		}
 	}
 
 	// Extract config from the Lua state
-	return extractConfig(L)
+	config, err := extractConfig(L)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	warnings := unknownKeyWarnings(L.GetGlobal(luaGlobalZerb).(*lua.LTable), luaCode)
+	return config, warnings, nil
 }
 
 // ParseError represents a config parsing error with friendly message.
@@ -133,7 +240,7 @@ func (e *ParseError) Error() string {
 	return fmt.Sprintf("%s: %s", e.Message, e.Detail)
 }
 
-// extractConfig extracts the config from a Lua state.
+// extractConfig extracts the config from a Lua state without validating it.
 // It expects a global "zerb" table with the config structure.
 func extractConfig(L *lua.LState) (*Config, error) {
 	zerbTable := L.GetGlobal(luaGlobalZerb)
@@ -147,6 +254,15 @@ func extractConfig(L *lua.LState) (*Config, error) {
 	config := &Config{}
 	table := zerbTable.(*lua.LTable)
 
+	// Extract schema version
+	if versionVal := table.RawGetString(luaFieldSchemaVersion); versionVal != lua.LNil {
+		version, err := extractSchemaVersion(versionVal)
+		if err != nil {
+			return nil, err
+		}
+		config.SchemaVersion = version
+	}
+
 	// Extract meta
 	if metaVal := table.RawGetString(luaFieldMeta); metaVal.Type() == lua.LTTable {
 		meta, err := extractMeta(metaVal.(*lua.LTable))
@@ -165,6 +281,24 @@ func extractConfig(L *lua.LState) (*Config, error) {
 		config.Tools = tools
 	}
 
+	// Extract profiles
+	if profilesVal := table.RawGetString(luaFieldProfiles); profilesVal.Type() == lua.LTTable {
+		profiles, err := extractProfiles(profilesVal.(*lua.LTable))
+		if err != nil {
+			return nil, err
+		}
+		config.Profiles = profiles
+	}
+
+	// Extract backends
+	if backendsVal := table.RawGetString(luaFieldBackends); backendsVal.Type() == lua.LTTable {
+		backends, err := extractTools(backendsVal.(*lua.LTable))
+		if err != nil {
+			return nil, err
+		}
+		config.Backends = backends
+	}
+
 	// Extract configs
 	if configsVal := table.RawGetString(luaFieldConfigs); configsVal.Type() == lua.LTTable {
 		configs, err := extractConfigFiles(configsVal.(*lua.LTable))
@@ -174,6 +308,16 @@ func extractConfig(L *lua.LState) (*Config, error) {
 		config.Configs = configs
 	}
 
+	// Extract version probes
+	if probesVal := table.RawGetString(luaFieldVersionProbe); probesVal.Type() == lua.LTTable {
+		config.VersionProbes = extractVersionProbes(probesVal.(*lua.LTable))
+	}
+
+	// Extract encryption
+	if encryptionVal := table.RawGetString(luaFieldEncryption); encryptionVal.Type() == lua.LTTable {
+		config.Encryption = extractEncryptionConfig(encryptionVal.(*lua.LTable))
+	}
+
 	// Extract git
 	if gitVal := table.RawGetString(luaFieldGit); gitVal.Type() == lua.LTTable {
 		git, err := extractGitConfig(gitVal.(*lua.LTable))
@@ -192,15 +336,19 @@ func extractConfig(L *lua.LState) (*Config, error) {
 		config.Options = options
 	}
 
-	// Validate the extracted config
-	if err := config.Validate(); err != nil {
-		return nil, &ParseError{
-			Message: "config validation failed",
-			Detail:  err.Error(),
+	return config, nil
+}
+
+// extractSchemaVersion extracts schema_version, which must be a positive integer.
+func extractSchemaVersion(value lua.LValue) (int, error) {
+	num, ok := value.(lua.LNumber)
+	if !ok || float64(num) != math.Trunc(float64(num)) || num < 1 || num > math.MaxInt32 {
+		return 0, &ParseError{
+			Message: "invalid schema_version",
+			Detail:  fmt.Sprintf("expected a positive integer, got %s", value.Type()),
 		}
 	}
-
-	return config, nil
+	return int(num), nil
 }
 
 // extractMeta extracts metadata from a Lua table.
@@ -243,6 +391,54 @@ func extractTools(table *lua.LTable) ([]string, error) {
 	return tools, nil
 }
 
+// extractProfiles extracts named tool lists from a Lua table.
+// Entries that are not a name mapped to a table are skipped, so a profile
+// can be left out with a platform conditional.
+func extractProfiles(table *lua.LTable) (map[string][]string, error) {
+	profiles := make(map[string][]string)
+
+	var err error
+	table.ForEach(func(key, value lua.LValue) {
+		if err != nil || key.Type() != lua.LTString || value.Type() != lua.LTTable {
+			return
+		}
+
+		var tools []string
+		tools, err = extractTools(value.(*lua.LTable))
+		profiles[key.String()] = tools
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+// extractVersionProbes extracts version probes keyed by tool name from a
+// Lua table. As with profiles, entries that are not a name mapped to a
+// table are skipped.
+func extractVersionProbes(table *lua.LTable) map[string]VersionProbe {
+	probes := make(map[string]VersionProbe)
+
+	table.ForEach(func(key, value lua.LValue) {
+		if key.Type() != lua.LTString || value.Type() != lua.LTTable {
+			return
+		}
+
+		entry := value.(*lua.LTable)
+		probe := VersionProbe{}
+		if flagVal := entry.RawGetString(luaFieldFlag); flagVal.Type() == lua.LTString {
+			probe.Flag = flagVal.String()
+		}
+		if regexVal := entry.RawGetString(luaFieldRegex); regexVal.Type() == lua.LTString {
+			probe.Regex = regexVal.String()
+		}
+		probes[key.String()] = probe
+	})
+
+	return probes
+}
+
 // extractConfigFiles extracts config files array from a Lua table.
 func extractConfigFiles(table *lua.LTable) ([]ConfigFile, error) {
 	var configs []ConfigFile
@@ -286,6 +482,11 @@ func extractConfigFiles(table *lua.LTable) ([]ConfigFile, error) {
 				cf.Private = bool(privVal.(lua.LBool))
 			}
 
+			// Optional: target
+			if targetVal := cfTable.RawGetString(luaFieldTarget); targetVal.Type() == lua.LTString {
+				cf.Target = targetVal.String()
+			}
+
 			configs = append(configs, cf)
 		}
 	})
@@ -293,6 +494,21 @@ func extractConfigFiles(table *lua.LTable) ([]ConfigFile, error) {
 	return configs, nil
 }
 
+// extractEncryptionConfig extracts encryption settings from a Lua table.
+func extractEncryptionConfig(table *lua.LTable) EncryptionConfig {
+	encryption := EncryptionConfig{}
+
+	if recipientVal := table.RawGetString(luaFieldRecipient); recipientVal.Type() == lua.LTString {
+		encryption.Recipient = recipientVal.String()
+	}
+
+	if identityVal := table.RawGetString(luaFieldIdentity); identityVal.Type() == lua.LTString {
+		encryption.Identity = identityVal.String()
+	}
+
+	return encryption
+}
+
 // extractGitConfig extracts git configuration from a Lua table.
 func extractGitConfig(table *lua.LTable) (GitConfig, error) {
 	git := GitConfig{}
@@ -305,6 +521,10 @@ func extractGitConfig(table *lua.LTable) (GitConfig, error) {
 		git.Branch = branchVal.String()
 	}
 
+	if perHostVal := table.RawGetString(luaFieldPerHostBranches); perHostVal.Type() == lua.LTBool {
+		git.PerHostBranches = bool(perHostVal.(lua.LBool))
+	}
+
 	return git, nil
 }
 
@@ -316,9 +536,47 @@ func extractOptions(table *lua.LTable) (Options, error) {
 		options.BackupRetention = int(lua.LVAsNumber(retentionVal))
 	}
 
+	if defaultsVal := table.RawGetString(luaFieldDefaults); defaultsVal.Type() == lua.LTTable {
+		defaults, err := extractConfigDefaults(defaultsVal.(*lua.LTable))
+		if err != nil {
+			return Options{}, err
+		}
+		options.Defaults = defaults
+	}
+
 	return options, nil
 }
 
+// extractConfigDefaults extracts the config.defaults table. Each flag, if
+// set, must be a boolean.
+func extractConfigDefaults(table *lua.LTable) (ConfigDefaults, error) {
+	defaults := ConfigDefaults{}
+	flags := []struct {
+		name  string
+		value *bool
+	}{
+		{luaFieldTemplate, &defaults.Template},
+		{luaFieldSecrets, &defaults.Secrets},
+		{luaFieldPrivate, &defaults.Private},
+	}
+
+	for _, flag := range flags {
+		val := table.RawGetString(flag.name)
+		switch val.Type() {
+		case lua.LTNil:
+		case lua.LTBool:
+			*flag.value = bool(val.(lua.LBool))
+		default:
+			return ConfigDefaults{}, &ParseError{
+				Message: "invalid config.defaults",
+				Detail:  fmt.Sprintf("%s must be true or false, got %s", flag.name, val.Type()),
+			}
+		}
+	}
+
+	return defaults, nil
+}
+
 // sanitizeLuaError sanitizes Lua VM error messages for user display.
 // It removes stack traces and internal implementation details.
 func sanitizeLuaError(err error) string {
@@ -67,6 +67,10 @@ func TestParser_ParseString_Full(t *testing.T) {
 					secrets = true,
 					private = true,
 				},
+				{
+					path = "~/dotfiles/work.gitconfig",
+					target = "~/.gitconfig-work",
+				},
 			},
 			git = {
 				remote = "https://github.com/user/dotfiles",
@@ -107,8 +111,8 @@ func TestParser_ParseString_Full(t *testing.T) {
 	}
 
 	// Check configs
-	if len(config.Configs) != 4 {
-		t.Errorf("Configs length = %d, want 4", len(config.Configs))
+	if len(config.Configs) != 5 {
+		t.Fatalf("Configs length = %d, want 5", len(config.Configs))
 	}
 
 	// First config (simple string)
@@ -137,6 +141,17 @@ func TestParser_ParseString_Full(t *testing.T) {
 	if !config.Configs[3].Private {
 		t.Error("Configs[3].Private = false, want true")
 	}
+	if config.Configs[3].Target != "" || config.Configs[3].TargetPath() != "~/.ssh/config" {
+		t.Errorf("Configs[3] target = %q (TargetPath %q), want none", config.Configs[3].Target, config.Configs[3].TargetPath())
+	}
+
+	// Fifth config (with a target override)
+	if config.Configs[4].Target != "~/.gitconfig-work" {
+		t.Errorf("Configs[4].Target = %s, want ~/.gitconfig-work", config.Configs[4].Target)
+	}
+	if config.Configs[4].TargetPath() != "~/.gitconfig-work" {
+		t.Errorf("Configs[4].TargetPath() = %s, want ~/.gitconfig-work", config.Configs[4].TargetPath())
+	}
 
 	// Check git
 	if config.Git.Remote != "https://github.com/user/dotfiles" {
@@ -250,6 +265,103 @@ func TestParser_ParseString_Errors(t *testing.T) {
 	}
 }
 
+func TestParser_ParseString_Backends(t *testing.T) {
+	code := `zerb = {
+  tools = {
+    "node@20.11.0",
+    "carg:ripgrep",
+  },
+}`
+	_, err := NewParser(nil).ParseString(context.Background(), code)
+	if err == nil {
+		t.Fatal("ParseString() accepted an unknown backend")
+	}
+	if want := `tools[1] (line 4): unknown backend "carg"`; !strings.Contains(err.Error(), want) {
+		t.Errorf("ParseString() error = %v, want it to contain %q", err, want)
+	}
+
+	// The config can allow more backends
+	code = `zerb = {
+  backends = { "carg" },
+  tools = { "carg:ripgrep", "cargo:bat" },
+}`
+	cfg, err := NewParser(nil).ParseString(context.Background(), code)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+	if len(cfg.Backends) != 1 || cfg.Backends[0] != "carg" {
+		t.Errorf("Backends = %v, want [carg]", cfg.Backends)
+	}
+
+	// WithBackends replaces the defaults
+	_, err = NewParser(nil).WithBackends([]string{"carg"}).ParseString(context.Background(), code)
+	if err == nil || !strings.Contains(err.Error(), `unknown backend "cargo"`) {
+		t.Errorf("ParseString() error = %v, want cargo rejected", err)
+	}
+}
+
+func TestParser_ParseString_ConfigDefaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		block   string
+		want    ConfigDefaults
+		wantErr string
+	}{
+		{
+			name:  "no defaults",
+			block: `backup_retention = 5`,
+			want:  ConfigDefaults{},
+		},
+		{
+			name:  "private and not template",
+			block: `defaults = { private = true, template = false }`,
+			want:  ConfigDefaults{Private: true},
+		},
+		{
+			name:  "all flags",
+			block: `defaults = { template = true, secrets = true, private = true }`,
+			want:  ConfigDefaults{Template: true, Secrets: true, Private: true},
+		},
+		{
+			name:    "not a boolean",
+			block:   `defaults = { private = "yes" }`,
+			wantErr: "private must be true or false",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			luaCode := "zerb = { config = { " + tt.block + " } }"
+			cfg, err := NewParser(nil).ParseString(context.Background(), luaCode)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseString() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseString() error = %v", err)
+			}
+			if cfg.Options.Defaults != tt.want {
+				t.Errorf("Options.Defaults = %+v, want %+v", cfg.Options.Defaults, tt.want)
+			}
+
+			// Generated configs keep the defaults
+			generated, err := NewGenerator().Generate(context.Background(), cfg)
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			reparsed, err := NewParser(nil).ParseString(context.Background(), generated)
+			if err != nil {
+				t.Fatalf("ParseString() of generated config error = %v\n%s", err, generated)
+			}
+			if reparsed.Options.Defaults != tt.want {
+				t.Errorf("generated Options.Defaults = %+v, want %+v", reparsed.Options.Defaults, tt.want)
+			}
+		})
+	}
+}
+
 func TestParser_ParseString_EmptyConfig(t *testing.T) {
 	luaCode := `
 		zerb = {
@@ -314,6 +426,73 @@ func TestParser_ParseString_HelperFunction(t *testing.T) {
 	}
 }
 
+func TestParser_ParseString_LocalVars(t *testing.T) {
+	tests := []struct {
+		name      string
+		luaCode   string
+		wantTools []string
+		wantErr   string
+	}{
+		{
+			name: "top-level local concatenated",
+			luaCode: `
+				local node_ver = "20.11.0"
+				zerb = { tools = { "node@" .. node_ver } }
+			`,
+			wantTools: []string{"node@20.11.0"},
+		},
+		{
+			name: "vars table shared by tools",
+			luaCode: `
+				local vars = { node = "20.11.0", backend = "cargo" }
+				zerb = {
+					tools = {
+						"node@" .. vars.node,
+						vars.backend .. ":ripgrep",
+						string.format("%s:bat", vars.backend),
+					},
+				}
+			`,
+			wantTools: []string{"node@20.11.0", "cargo:ripgrep", "cargo:bat"},
+		},
+		{
+			name: "computed tool is validated",
+			luaCode: `
+				local node_ver = "20.11.0"
+				zerb = { tools = { "node@" .. node_ver .. "@extra" } }
+			`,
+			wantErr: "config validation failed",
+		},
+		{
+			name: "local alias of require stays blocked",
+			luaCode: `
+				local load_module = require
+				zerb = { tools = { load_module("os") } }
+			`,
+			wantErr: "Lua syntax error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewParser(nil)
+			config, err := parser.ParseString(context.Background(), tt.luaCode)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseString() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseString() error = %v", err)
+			}
+			if strings.Join(config.Tools, ",") != strings.Join(tt.wantTools, ",") {
+				t.Errorf("Tools = %v, want %v", config.Tools, tt.wantTools)
+			}
+		})
+	}
+}
+
 func TestFormatError(t *testing.T) {
 	tests := []struct {
 		name    string
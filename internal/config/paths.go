@@ -0,0 +1,260 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Base directory variables expanded at the start of config paths
+const (
+	BaseXDGConfigHome = "XDG_CONFIG_HOME"
+	BaseXDGDataHome   = "XDG_DATA_HOME"
+)
+
+// basePathPattern matches a leading $NAME or ${NAME} followed by / or the end
+var basePathPattern = regexp.MustCompile(`^\$(?:\{([A-Za-z_][A-Za-z0-9_]*)\}|([A-Za-z_][A-Za-z0-9_]*))(/|$)`)
+
+// PathRoots is the set of directories config paths may refer to: the home
+// directory (~/) and named base directories ($XDG_CONFIG_HOME/). A config
+// path must stay within home, or within the base directory it starts with.
+type PathRoots struct {
+	Home  string            // User's home directory
+	Bases map[string]string // Variable name (without $) -> absolute directory
+}
+
+// DefaultPathRoots returns the home directory plus $XDG_CONFIG_HOME and
+// $XDG_DATA_HOME. Per the XDG spec, unset or relative values fall back to
+// ~/.config and ~/.local/share.
+func DefaultPathRoots() (PathRoots, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return PathRoots{}, fmt.Errorf("cannot determine home directory: %w", err)
+	}
+
+	return PathRoots{
+		Home: home,
+		Bases: map[string]string{
+			BaseXDGConfigHome: xdgDir(BaseXDGConfigHome, filepath.Join(home, ".config")),
+			BaseXDGDataHome:   xdgDir(BaseXDGDataHome, filepath.Join(home, ".local", "share")),
+		},
+	}, nil
+}
+
+// xdgDir returns the XDG directory in the named variable, or fallback if it
+// is unset or not absolute
+func xdgDir(name, fallback string) string {
+	if dir := os.Getenv(name); filepath.IsAbs(dir) {
+		return filepath.Clean(dir)
+	}
+	return fallback
+}
+
+// WithBase returns a copy of the roots with name mapped to dir, so paths
+// starting with $name resolve under dir. Existing bases are kept.
+func (r PathRoots) WithBase(name, dir string) PathRoots {
+	bases := make(map[string]string, len(r.Bases)+1)
+	for k, v := range r.Bases {
+		bases[k] = v
+	}
+	bases[name] = dir
+
+	return PathRoots{Home: r.Home, Bases: bases}
+}
+
+// Expand expands a leading ~ or base variable and returns the cleaned
+// absolute path. It does not resolve symlinks or check containment.
+func (r PathRoots) Expand(path string) (string, error) {
+	absPath, _, err := r.expand(path)
+	return absPath, err
+}
+
+// expand expands path and also returns the base directory it started with,
+// or "" if it did not start with a base variable
+func (r PathRoots) expand(path string) (absPath, base string, err error) {
+	if path == "" {
+		return "", "", fmt.Errorf("path cannot be empty")
+	}
+
+	switch {
+	case strings.HasPrefix(path, "~/"):
+		absPath = filepath.Join(r.Home, path[2:])
+	case path == "~":
+		absPath = r.Home
+	case strings.HasPrefix(path, "$"):
+		m := basePathPattern.FindStringSubmatch(path)
+		if m == nil {
+			return "", "", fmt.Errorf("invalid base directory reference in %q", path)
+		}
+		name := m[1] + m[2]
+		dir, ok := r.Bases[name]
+		if !ok {
+			return "", "", fmt.Errorf("unknown base directory $%s", name)
+		}
+		if !filepath.IsAbs(dir) || filepath.Clean(dir) == string(filepath.Separator) {
+			return "", "", fmt.Errorf("base directory $%s must be an absolute path other than /", name)
+		}
+		base = filepath.Clean(dir)
+		absPath = filepath.Join(base, path[len(m[0]):])
+	case filepath.IsAbs(path):
+		absPath = path
+	default:
+		return "", "", fmt.Errorf("must be absolute or start with ~/ (or a base directory such as $%s/)", BaseXDGConfigHome)
+	}
+
+	return filepath.Clean(absPath), base, nil
+}
+
+// Contract returns absPath as ~/... if it is inside the home directory, or
+// inside the directory home resolves to, and unchanged otherwise. It is the
+// inverse of Expand for paths under home, so a path found on disk is stored
+// the way a user would write it.
+func (r PathRoots) Contract(absPath string) string {
+	absPath = filepath.Clean(absPath)
+	homes := []string{filepath.Clean(r.Home)}
+	if evalHome, err := filepath.EvalSymlinks(r.Home); err == nil && evalHome != homes[0] {
+		homes = append(homes, evalHome)
+	}
+
+	for _, home := range homes {
+		if absPath == home {
+			return "~"
+		}
+		if withinDir(absPath, home) {
+			rel, err := filepath.Rel(home, absPath)
+			if err == nil {
+				return "~/" + filepath.ToSlash(rel)
+			}
+		}
+	}
+	return absPath
+}
+
+// Normalize normalizes a config path to a canonical form for duplicate
+// detection. It expands ~ and base variables, resolves symlinks, and cleans
+// the path. Returns the normalized absolute path or an error if the path is
+// invalid.
+func (r PathRoots) Normalize(path string) (string, error) {
+	absPath, _, err := r.expand(path)
+	if err != nil {
+		return "", err
+	}
+
+	// Resolve symlinks
+	evalPath, err := filepath.EvalSymlinks(absPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Path doesn't exist - validate parent directory instead
+			parentDir := filepath.Dir(absPath)
+			parentEval, parentErr := filepath.EvalSymlinks(parentDir)
+			if parentErr != nil {
+				// Parent doesn't exist or has resolution issues - use cleaned path
+				// This is acceptable for Normalize as it's used for duplicate detection
+				// and the path will be validated separately by Validate
+				return absPath, nil
+			}
+			// Use the resolved parent with the original filename
+			return filepath.Join(parentEval, filepath.Base(absPath)), nil
+		}
+		// Other error (permission denied, symlink loop, etc.) - use cleaned path
+		// Similar reasoning as above
+		return absPath, nil
+	}
+
+	// Path exists, use resolved canonical path
+	return evalPath, nil
+}
+
+// Validate validates a config file path for security. It prevents path
+// traversal and symlink escapes: after expansion and symlink resolution
+// the path must be within the home directory, or within the base directory
+// the path starts with.
+func (r PathRoots) Validate(path string) error {
+	absPath, base, err := r.expand(path)
+	if err != nil {
+		return err
+	}
+
+	// Try to resolve symlinks for canonical path (allow non-existent paths)
+	evalPath, err := filepath.EvalSymlinks(absPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Path doesn't exist - try to validate parent directory instead
+			parentDir := filepath.Dir(absPath)
+			parentEval, parentErr := filepath.EvalSymlinks(parentDir)
+			if parentErr != nil {
+				if !os.IsNotExist(parentErr) {
+					// Other error (permission denied, symlink loop, etc.)
+					return fmt.Errorf("cannot evaluate parent directory: %w", parentErr)
+				}
+				// Parent also doesn't exist - this is OK for validation
+				// The path will be checked for existence at add time
+			} else {
+				// Use the resolved parent with the original filename
+				absPath = filepath.Join(parentEval, filepath.Base(absPath))
+			}
+		} else {
+			// Other error (permission denied, symlink loop, etc.)
+			return fmt.Errorf("cannot evaluate path: %w", err)
+		}
+	} else {
+		// Path exists, use resolved canonical path
+		absPath = evalPath
+	}
+
+	if withinDir(absPath, r.Home) {
+		return nil
+	}
+	if base != "" {
+		if withinDir(absPath, base) {
+			return nil
+		}
+		// The base itself may be reached through a symlink
+		if baseEval, err := filepath.EvalSymlinks(base); err == nil && withinDir(absPath, baseEval) {
+			return nil
+		}
+		return fmt.Errorf("path traversal not allowed: %s escapes its base directory", path)
+	}
+
+	if strings.HasPrefix(path, "~") {
+		return fmt.Errorf("path traversal not allowed: %s", path)
+	}
+	return fmt.Errorf("absolute paths outside home directory not allowed: %s", path)
+}
+
+// ValidateTarget validates a target override for a config. Like Validate,
+// but the path must be inside the home directory even when it starts with a
+// base directory, and cannot be the home directory itself.
+func (r PathRoots) ValidateTarget(path string) error {
+	if err := r.Validate(path); err != nil {
+		return err
+	}
+	absPath, err := r.Normalize(path)
+	if err != nil {
+		return err
+	}
+	home := filepath.Clean(r.Home)
+	if evalHome, err := filepath.EvalSymlinks(home); err == nil {
+		home = evalHome
+	}
+	if absPath == home || !withinDir(absPath, home) {
+		return fmt.Errorf("target must be within the home directory: %s", path)
+	}
+	return nil
+}
+
+// withinDir reports whether path is dir or inside it. Uses filepath.Rel so
+// prefixes like /home/user2 do not match /home/user.
+func withinDir(path, dir string) bool {
+	dir = filepath.Clean(dir)
+	if path == dir {
+		return true
+	}
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
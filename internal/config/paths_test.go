@@ -0,0 +1,324 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultPathRoots(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	tests := []struct {
+		name       string
+		configHome string
+		dataHome   string
+		wantConfig string
+		wantData   string
+	}{
+		{
+			name:       "unset falls back to XDG defaults",
+			wantConfig: filepath.Join(home, ".config"),
+			wantData:   filepath.Join(home, ".local", "share"),
+		},
+		{
+			name:       "absolute values are used",
+			configHome: "/srv/cfg/",
+			dataHome:   "/srv/data",
+			wantConfig: "/srv/cfg",
+			wantData:   "/srv/data",
+		},
+		{
+			name:       "relative values are ignored",
+			configHome: "cfg",
+			dataHome:   "./data",
+			wantConfig: filepath.Join(home, ".config"),
+			wantData:   filepath.Join(home, ".local", "share"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(BaseXDGConfigHome, tt.configHome)
+			t.Setenv(BaseXDGDataHome, tt.dataHome)
+
+			roots, err := DefaultPathRoots()
+			if err != nil {
+				t.Fatalf("DefaultPathRoots() error = %v", err)
+			}
+			if roots.Home != home {
+				t.Errorf("Home = %q, want %q", roots.Home, home)
+			}
+			if got := roots.Bases[BaseXDGConfigHome]; got != tt.wantConfig {
+				t.Errorf("XDG_CONFIG_HOME = %q, want %q", got, tt.wantConfig)
+			}
+			if got := roots.Bases[BaseXDGDataHome]; got != tt.wantData {
+				t.Errorf("XDG_DATA_HOME = %q, want %q", got, tt.wantData)
+			}
+		})
+	}
+}
+
+func TestPathRoots_Expand(t *testing.T) {
+	roots := PathRoots{
+		Home: "/home/user",
+		Bases: map[string]string{
+			BaseXDGConfigHome: "/home/user/cfg",
+			"DOTFILES":        "/srv/dotfiles",
+			"ROOT":            "/",
+			"RELATIVE":        "dotfiles",
+		},
+	}
+
+	tests := []struct {
+		path    string
+		want    string
+		wantErr string
+	}{
+		{path: "~/.zshrc", want: "/home/user/.zshrc"},
+		{path: "~", want: "/home/user"},
+		{path: "/home/user/.bashrc", want: "/home/user/.bashrc"},
+		{path: "$XDG_CONFIG_HOME/nvim/init.lua", want: "/home/user/cfg/nvim/init.lua"},
+		{path: "${XDG_CONFIG_HOME}/nvim", want: "/home/user/cfg/nvim"},
+		{path: "$XDG_CONFIG_HOME", want: "/home/user/cfg"},
+		{path: "$DOTFILES/git/config", want: "/srv/dotfiles/git/config"},
+		{path: "$XDG_CONFIG_HOMEX/nvim", wantErr: "unknown base directory $XDG_CONFIG_HOMEX"},
+		{path: "$NOPE/file", wantErr: "unknown base directory $NOPE"},
+		{path: "${XDG_CONFIG_HOME/nvim", wantErr: "invalid base directory reference"},
+		{path: "$ROOT/etc/passwd", wantErr: "must be an absolute path other than /"},
+		{path: "$RELATIVE/file", wantErr: "must be an absolute path other than /"},
+		{path: ".zshrc", wantErr: "must be absolute or start with ~/"},
+		{path: "", wantErr: "path cannot be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := roots.Expand(tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expand(%q) error = %v, want %q", tt.path, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expand(%q) error = %v", tt.path, err)
+			}
+			if got != tt.want {
+				t.Errorf("Expand(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathRoots_Contract(t *testing.T) {
+	// home is reached through a symlink, as /home can be on some systems
+	realHome := t.TempDir()
+	home := filepath.Join(t.TempDir(), "home")
+	if err := os.Symlink(realHome, home); err != nil {
+		t.Fatal(err)
+	}
+	roots := PathRoots{Home: home}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: filepath.Join(home, ".zshrc"), want: "~/.zshrc"},
+		{path: filepath.Join(home, ".config", "nvim", "init.lua"), want: "~/.config/nvim/init.lua"},
+		{path: filepath.Join(realHome, "dotfiles", "zshrc"), want: "~/dotfiles/zshrc"},
+		{path: home, want: "~"},
+		{path: home + "2/.zshrc", want: home + "2/.zshrc"},
+		{path: "/etc/hosts", want: "/etc/hosts"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := roots.Contract(tt.path); got != tt.want {
+				t.Errorf("Contract(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathRoots_Validate(t *testing.T) {
+	home := t.TempDir()
+	outside := t.TempDir() // A base directory outside home
+	t.Setenv("HOME", home)
+	t.Setenv(BaseXDGConfigHome, outside)
+	t.Setenv(BaseXDGDataHome, "")
+
+	// Symlink inside the base pointing out of it
+	if err := os.Symlink("/etc", filepath.Join(outside, "escape")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	roots, err := DefaultPathRoots()
+	if err != nil {
+		t.Fatalf("DefaultPathRoots() error = %v", err)
+	}
+	sibling := t.TempDir()
+	roots = roots.WithBase("DOTFILES", sibling)
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{name: "XDG config path", path: "$XDG_CONFIG_HOME/nvim/init.lua"},
+		{name: "XDG data default under home", path: "$XDG_DATA_HOME/fonts"},
+		{name: "custom base", path: "$DOTFILES/gitconfig"},
+		{name: "tilde path", path: "~/.zshrc"},
+		{name: "base itself", path: "$XDG_CONFIG_HOME"},
+		{
+			name:    "traversal out of base",
+			path:    "$XDG_CONFIG_HOME/../../etc/passwd",
+			wantErr: "path traversal not allowed",
+		},
+		{
+			name:    "traversal into another base",
+			path:    "$DOTFILES/../" + filepath.Base(outside),
+			wantErr: "path traversal not allowed",
+		},
+		{
+			name:    "symlink escaping base",
+			path:    "$XDG_CONFIG_HOME/escape/passwd",
+			wantErr: "path traversal not allowed",
+		},
+		{
+			// Literal absolute paths must still be within home
+			name:    "absolute path inside base",
+			path:    filepath.Join(outside, "nvim"),
+			wantErr: "absolute paths outside home directory not allowed",
+		},
+		{
+			name:    "tilde traversal",
+			path:    "~/../etc/passwd",
+			wantErr: "path traversal not allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := roots.Validate(tt.path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate(%q) error = %v", tt.path, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate(%q) error = %v, want %q", tt.path, err, tt.wantErr)
+			}
+		})
+	}
+
+	// The package-level helpers use the default roots
+	if err := validateConfigPath("$XDG_CONFIG_HOME/nvim"); err != nil {
+		t.Errorf("validateConfigPath() error = %v", err)
+	}
+	if err := validateConfigPath("$DOTFILES/gitconfig"); err == nil {
+		t.Error("validateConfigPath() accepted a base only known to custom roots")
+	}
+}
+
+func TestPathRoots_ValidateTarget(t *testing.T) {
+	home := t.TempDir()
+	outside := t.TempDir()
+	roots := PathRoots{Home: home, Bases: map[string]string{BaseXDGConfigHome: outside}}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{name: "file in home", path: "~/.gitconfig"},
+		{name: "nested in home", path: "~/.config/git/config"},
+		{name: "absolute in home", path: filepath.Join(home, ".gitconfig")},
+		{name: "home itself", path: "~", wantErr: "within the home directory"},
+		{name: "traversal", path: "~/../etc/passwd", wantErr: "path traversal not allowed"},
+		{name: "outside home", path: "/etc/passwd", wantErr: "outside home directory"},
+		{
+			// Valid as a config path, but targets must stay within home
+			name:    "base outside home",
+			path:    "$XDG_CONFIG_HOME/git/config",
+			wantErr: "within the home directory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := roots.ValidateTarget(tt.path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateTarget(%q) error = %v", tt.path, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateTarget(%q) error = %v, want %q", tt.path, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalizeConfigPath_XDG(t *testing.T) {
+	home := t.TempDir()
+	configHome := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(BaseXDGConfigHome, configHome)
+
+	file := filepath.Join(configHome, "nvim", "init.lua")
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(file, []byte(""), 0644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	got, err := NormalizeConfigPath("$XDG_CONFIG_HOME/nvim/init.lua")
+	if err != nil {
+		t.Fatalf("NormalizeConfigPath() error = %v", err)
+	}
+	want, _ := filepath.EvalSymlinks(file)
+	if got != want {
+		t.Errorf("NormalizeConfigPath() = %q, want %q", got, want)
+	}
+
+	// Without XDG_CONFIG_HOME the base falls back to ~/.config
+	t.Setenv(BaseXDGConfigHome, "")
+	got, err = NormalizeConfigPath("$XDG_CONFIG_HOME/nvim/init.lua")
+	if err != nil {
+		t.Fatalf("NormalizeConfigPath() error = %v", err)
+	}
+	if want := filepath.Join(home, ".config", "nvim", "init.lua"); got != want {
+		t.Errorf("NormalizeConfigPath() = %q, want %q", got, want)
+	}
+}
+
+func TestParser_WithPathRoots(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dotfiles := t.TempDir()
+
+	code := `zerb = { configs = { "$DOTFILES/gitconfig" } }`
+
+	if _, err := NewParser(nil).ParseString(context.Background(), code); err == nil {
+		t.Fatal("ParseString() accepted an unknown base directory")
+	}
+
+	roots, err := DefaultPathRoots()
+	if err != nil {
+		t.Fatalf("DefaultPathRoots() error = %v", err)
+	}
+	parser := NewParser(nil).WithPathRoots(roots.WithBase("DOTFILES", dotfiles))
+	cfg, err := parser.ParseString(context.Background(), code)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+	if cfg.Configs[0].Path != "$DOTFILES/gitconfig" {
+		t.Errorf("Path = %q, want the unexpanded path", cfg.Configs[0].Path)
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// EnvProfiles names the environment variable listing the active profiles,
+// comma-separated (e.g. ZERB_PROFILES=work,gui).
+const EnvProfiles = "ZERB_PROFILES"
+
+// ErrUnknownProfile is returned by ResolveTools for a profile the config
+// does not define.
+var ErrUnknownProfile = errors.New("unknown profile")
+
+// ProfileNames returns the names of the defined profiles, sorted.
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveTools returns the base tools merged with the tools of the active
+// profiles, in the order given. A profile entry replaces an earlier entry
+// for the same tool, so a profile can pin a different version than the
+// base list. With no active profiles the base tools are returned as is.
+func (c *Config) ResolveTools(active []string) ([]string, error) {
+	tools := append([]string(nil), c.Tools...)
+
+	seen := make(map[string]bool, len(active))
+	for _, name := range active {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		profile, ok := c.Profiles[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownProfile, name)
+		}
+		tools, _ = mergeTools(tools, profile)
+	}
+
+	return tools, nil
+}
+
+// ProfilesFromEnv returns the active profiles listed in ZERB_PROFILES.
+// Blank entries are ignored; returns nil if the variable is unset.
+func ProfilesFromEnv() []string {
+	return parseProfileList(os.Getenv(EnvProfiles))
+}
+
+// parseProfileList splits a comma-separated profile list
+func parseProfileList(list string) []string {
+	var profiles []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			profiles = append(profiles, name)
+		}
+	}
+	return profiles
+}
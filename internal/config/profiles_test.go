@@ -0,0 +1,259 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParser_ParseString_Profiles(t *testing.T) {
+	parser := NewParser(nil)
+	cfg, err := parser.ParseString(context.Background(), `zerb = {
+  tools = { "node@20.11.0" },
+  profiles = {
+    work = { "node@18.19.0", "go@1.22.0" },
+    home = { "python@3.12.1", nil },
+    ["ci-runner"] = {},
+  },
+}`)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+
+	want := map[string][]string{
+		"work":      {"node@18.19.0", "go@1.22.0"},
+		"home":      {"python@3.12.1"},
+		"ci-runner": nil,
+	}
+	if !reflect.DeepEqual(cfg.Profiles, want) {
+		t.Errorf("Profiles = %v, want %v", cfg.Profiles, want)
+	}
+	if got := cfg.ProfileNames(); !reflect.DeepEqual(got, []string{"ci-runner", "home", "work"}) {
+		t.Errorf("ProfileNames() = %v", got)
+	}
+}
+
+func TestParser_ParseString_NoProfiles(t *testing.T) {
+	parser := NewParser(nil)
+	cfg, err := parser.ParseString(context.Background(), `zerb = { tools = { "node@20.11.0" } }`)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+	if cfg.Profiles != nil {
+		t.Errorf("Profiles = %v, want nil", cfg.Profiles)
+	}
+
+	tools, err := cfg.ResolveTools(nil)
+	if err != nil {
+		t.Fatalf("ResolveTools() error = %v", err)
+	}
+	if !reflect.DeepEqual(tools, []string{"node@20.11.0"}) {
+		t.Errorf("ResolveTools() = %v", tools)
+	}
+}
+
+func TestConfig_Validate_Profiles(t *testing.T) {
+	tests := []struct {
+		name      string
+		profiles  map[string][]string
+		wantField string
+	}{
+		{
+			name:     "valid",
+			profiles: map[string][]string{"work": {"node@20", "cargo:ripgrep"}},
+		},
+		{
+			name:      "invalid tool",
+			profiles:  map[string][]string{"work": {"node@20", "bad tool!"}},
+			wantField: "profiles.work[1]",
+		},
+		{
+			name:      "empty tool",
+			profiles:  map[string][]string{"home": {""}},
+			wantField: "profiles.home[0]",
+		},
+		{
+			name:      "invalid name",
+			profiles:  map[string][]string{"Work Laptop": {"node@20"}},
+			wantField: "profiles",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Profiles: tt.profiles}
+			err := cfg.Validate()
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate() error = %v, want ValidationError", err)
+			}
+			if verr.Field != tt.wantField {
+				t.Errorf("Field = %q, want %q", verr.Field, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestConfig_ResolveTools(t *testing.T) {
+	cfg := &Config{
+		Tools: []string{"node@20.11.0", "ripgrep"},
+		Profiles: map[string][]string{
+			"work": {"go@1.22.0", "node@18.19.0"},
+			"home": {"python@3.12.1", "go@1.21.0"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		active  []string
+		want    []string
+		wantErr error
+	}{
+		{
+			name:   "no profiles",
+			active: nil,
+			want:   []string{"node@20.11.0", "ripgrep"},
+		},
+		{
+			name:   "one profile",
+			active: []string{"work"},
+			want:   []string{"node@18.19.0", "ripgrep", "go@1.22.0"},
+		},
+		{
+			name:   "later profile wins",
+			active: []string{"work", "home"},
+			want:   []string{"node@18.19.0", "ripgrep", "go@1.21.0", "python@3.12.1"},
+		},
+		{
+			name:   "repeated profile",
+			active: []string{"home", "home"},
+			want:   []string{"node@20.11.0", "ripgrep", "python@3.12.1", "go@1.21.0"},
+		},
+		{
+			name:    "unknown profile",
+			active:  []string{"gaming"},
+			wantErr: ErrUnknownProfile,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cfg.ResolveTools(tt.active)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ResolveTools() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveTools() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResolveTools() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// The base list must not be modified
+	if !reflect.DeepEqual(cfg.Tools, []string{"node@20.11.0", "ripgrep"}) {
+		t.Errorf("ResolveTools() modified Tools: %v", cfg.Tools)
+	}
+}
+
+func TestProfilesFromEnv(t *testing.T) {
+	t.Setenv(EnvProfiles, " work, ,gui ")
+	if got := ProfilesFromEnv(); !reflect.DeepEqual(got, []string{"work", "gui"}) {
+		t.Errorf("ProfilesFromEnv() = %v", got)
+	}
+
+	t.Setenv(EnvProfiles, "")
+	if got := ProfilesFromEnv(); got != nil {
+		t.Errorf("ProfilesFromEnv() = %v, want nil", got)
+	}
+}
+
+func TestGenerator_Profiles_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	original := &Config{
+		Tools: []string{"node@20.11.0"},
+		Profiles: map[string][]string{
+			"work":        {"go@1.22.0"},
+			"home":        {"python@3.12.1", "cargo:ripgrep"},
+			"work-laptop": {"jq@1.7.1"},
+		},
+	}
+
+	lua, err := NewGenerator().Generate(ctx, original)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if strings.Index(lua, `["home"] = {`) > strings.Index(lua, `["work"] = {`) {
+		t.Errorf("profiles not sorted by name:\n%s", lua)
+	}
+
+	parsed, err := NewParser(nil).ParseString(ctx, lua)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v\n%s", err, lua)
+	}
+	if !reflect.DeepEqual(parsed.Profiles, original.Profiles) {
+		t.Errorf("Profiles = %v, want %v", parsed.Profiles, original.Profiles)
+	}
+}
+
+func TestMerge_Profiles(t *testing.T) {
+	base := &Config{Profiles: map[string][]string{
+		"work": {"node@20", "go@1.22"},
+	}}
+	overlay := &Config{Profiles: map[string][]string{
+		"work": {"node@18"},
+		"home": {"python@3.12"},
+	}}
+
+	merged, conflicts, err := Merge(base, overlay, PolicyOverlayWins)
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+
+	want := map[string][]string{
+		"work": {"node@18", "go@1.22"},
+		"home": {"python@3.12"},
+	}
+	if !reflect.DeepEqual(merged.Profiles, want) {
+		t.Errorf("Profiles = %v, want %v", merged.Profiles, want)
+	}
+
+	wantConflicts := []MergeConflict{{Field: "profiles.work.tools.node", Base: "node@20", Overlay: "node@18"}}
+	if !reflect.DeepEqual(conflicts, wantConflicts) {
+		t.Errorf("conflicts = %v, want %v", conflicts, wantConflicts)
+	}
+}
+
+func TestParser_Lint_Profiles(t *testing.T) {
+	content := `zerb = {
+  profiles = {
+    work = {
+      "bad tool!",
+    },
+  },
+}`
+
+	_, findings := NewParser(nil).Lint(context.Background(), content)
+	want := []LintFinding{{
+		Severity: SeverityError,
+		Line:     4,
+		Field:    "profiles.work[0]",
+		Message:  `invalid tool string format: "bad tool!" (expected: name@version or backend:name)`,
+	}}
+	if !reflect.DeepEqual(findings, want) {
+		t.Errorf("Lint() findings = %v, want %v", findings, want)
+	}
+}
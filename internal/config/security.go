@@ -3,7 +3,9 @@ package config
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 )
 
 // SensitivePattern represents a pattern that might indicate sensitive data
@@ -54,16 +56,74 @@ type SensitiveDataFinding struct {
 	Preview     string // Redacted preview of the match
 }
 
+var (
+	customPatternsMu sync.RWMutex
+	customPatterns   []SensitivePattern
+)
+
+// RegisterSensitivePattern adds a detection rule used by DetectSensitiveData
+// in addition to the built-in rules, e.g. for an organization's internal
+// token format. Rules can only be added: the built-in rules always run, and
+// a name already in use (built-in or registered) is rejected.
+func RegisterSensitivePattern(name string, re *regexp.Regexp, description string) error {
+	if name == "" {
+		return fmt.Errorf("sensitive pattern name cannot be empty")
+	}
+	if re == nil {
+		return fmt.Errorf("sensitive pattern %q has no regular expression", name)
+	}
+	if description == "" {
+		description = "Potential " + name + " detected"
+	}
+
+	customPatternsMu.Lock()
+	defer customPatternsMu.Unlock()
+
+	for _, p := range sensitivePatterns {
+		if p.Name == name {
+			return fmt.Errorf("sensitive pattern %q is built in", name)
+		}
+	}
+	for _, p := range customPatterns {
+		if p.Name == name {
+			return fmt.Errorf("sensitive pattern %q is already registered", name)
+		}
+	}
+
+	customPatterns = append(customPatterns, SensitivePattern{Name: name, Pattern: re, Description: description})
+	return nil
+}
+
 // DetectSensitiveData scans configuration content for potential sensitive data
+// using the built-in and registered rules
 func DetectSensitiveData(content string) []SensitiveDataFinding {
+	return DetectSensitiveDataWith(content, nil)
+}
+
+// DetectSensitiveDataWith scans content using the built-in and registered
+// rules plus extra. Extra rules never replace the others, so a rule with a
+// duplicate name is simply checked as well.
+func DetectSensitiveDataWith(content string, extra []SensitivePattern) []SensitiveDataFinding {
+	customPatternsMu.RLock()
+	patterns := make([]SensitivePattern, 0, len(sensitivePatterns)+len(customPatterns)+len(extra))
+	patterns = append(patterns, sensitivePatterns...)
+	patterns = append(patterns, customPatterns...)
+	customPatternsMu.RUnlock()
+
+	for _, p := range extra {
+		if p.Pattern != nil {
+			patterns = append(patterns, p)
+		}
+	}
+
 	var findings []SensitiveDataFinding
 	lines := strings.Split(content, "\n")
 
 	for lineNum, line := range lines {
-		for _, pattern := range sensitivePatterns {
+		for _, pattern := range patterns {
 			if pattern.Pattern.MatchString(line) {
 				// Create redacted preview
-				preview := redactSensitiveValue(line)
+				preview := redactSensitiveValue(line, pattern.Pattern)
 
 				findings = append(findings, SensitiveDataFinding{
 					PatternName: pattern.Name,
@@ -78,20 +138,21 @@ func DetectSensitiveData(content string) []SensitiveDataFinding {
 	return findings
 }
 
-// redactSensitiveValue creates a redacted preview of a line with sensitive data
-func redactSensitiveValue(line string) string {
+// redactSensitiveValue creates a redacted preview of a line with sensitive data.
+// Only the key of an assignment is kept; nothing the pattern matches is shown.
+func redactSensitiveValue(line string, pattern *regexp.Regexp) string {
 	// Find the assignment operator
 	eqIdx := strings.Index(line, "=")
 	if eqIdx == -1 {
-		// If no '=', just show first 30 chars
-		if len(line) > 30 {
-			return line[:30] + "... [REDACTED]"
-		}
-		return line + " [REDACTED]"
+		// No key to show, e.g. a bare token in a list
+		return "[REDACTED]"
 	}
 
 	// Show the key part, redact the value
 	keyPart := strings.TrimSpace(line[:eqIdx])
+	if pattern.MatchString(keyPart) {
+		return "[REDACTED]"
+	}
 	return keyPart + " = [REDACTED]"
 }
 
@@ -105,9 +166,32 @@ func FormatSensitiveDataWarning(findings []SensitiveDataFinding) string {
 	sb.WriteString("\n⚠️  WARNING: Potential sensitive data detected in configuration\n\n")
 	sb.WriteString("The following patterns were found that may indicate hardcoded secrets:\n\n")
 
-	for i, finding := range findings {
-		sb.WriteString(fmt.Sprintf("%d. %s (line %d)\n", i+1, finding.Description, finding.Line))
-		sb.WriteString(fmt.Sprintf("   Preview: %s\n\n", finding.Preview))
+	// Group findings by rule, keeping the order rules were first seen
+	type group struct {
+		description string
+		lines       []string
+		preview     string
+	}
+	var order []string
+	groups := make(map[string]*group)
+	for _, finding := range findings {
+		g, ok := groups[finding.PatternName]
+		if !ok {
+			g = &group{description: finding.Description, preview: finding.Preview}
+			groups[finding.PatternName] = g
+			order = append(order, finding.PatternName)
+		}
+		g.lines = append(g.lines, strconv.Itoa(finding.Line))
+	}
+
+	for i, name := range order {
+		g := groups[name]
+		label := "line"
+		if len(g.lines) > 1 {
+			label = "lines"
+		}
+		sb.WriteString(fmt.Sprintf("%d. %s [%s] (%s %s)\n", i+1, g.description, name, label, strings.Join(g.lines, ", ")))
+		sb.WriteString(fmt.Sprintf("   Preview: %s\n\n", g.preview))
 	}
 
 	sb.WriteString("SECURITY RECOMMENDATION:\n")
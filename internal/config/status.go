@@ -21,8 +21,14 @@ const (
 	// was incomplete or failed.
 	StatusPartial
 
-	// TODO: Future enhancement - StatusDrift for when file exists and managed but content differs
-	// This will require file hash comparison and integration with drift detection component.
+	// StatusModified indicates the config is managed by ZERB but the file on
+	// disk differs from its tracked content, e.g. after an external edit.
+	StatusModified
+
+	// StatusUnknown indicates the config exists but could not be compared to
+	// its tracked content, e.g. an encrypted file without its key or a
+	// template that fails to evaluate.
+	StatusUnknown
 )
 
 // String returns the string representation of a ConfigStatus.
@@ -34,11 +40,25 @@ func (s ConfigStatus) String() string {
 		return "missing"
 	case StatusPartial:
 		return "partial"
+	case StatusModified:
+		return "modified"
+	case StatusUnknown:
+		return "unknown"
 	default:
 		return "unknown"
 	}
 }
 
+// ParseConfigStatus parses a status name as returned by String.
+func ParseConfigStatus(name string) (ConfigStatus, error) {
+	for _, s := range []ConfigStatus{StatusSynced, StatusMissing, StatusPartial, StatusModified, StatusUnknown} {
+		if s.String() == name {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown config status %q (valid: synced, modified, missing, partial, unknown)", name)
+}
+
 // Symbol returns the visual symbol for a ConfigStatus.
 func (s ConfigStatus) Symbol() string {
 	switch s {
@@ -48,6 +68,10 @@ func (s ConfigStatus) Symbol() string {
 		return "✗"
 	case StatusPartial:
 		return "?"
+	case StatusModified:
+		return "~"
+	case StatusUnknown:
+		return "!"
 	default:
 		return "?"
 	}
@@ -68,6 +92,11 @@ type StatusDetector interface {
 // This is a subset of the full chezmoi.Chezmoi interface needed for status detection.
 type Chezmoi interface {
 	HasFile(ctx context.Context, path string) (bool, error)
+	// Status compares managed paths on disk to their tracked content,
+	// returning StatusSynced, StatusModified or StatusMissing for each, or
+	// StatusUnknown for a path that cannot be compared. Callers pass only
+	// paths HasFile reported as managed.
+	Status(ctx context.Context, paths []string) (map[string]ConfigStatus, error)
 }
 
 // DefaultStatusDetector implements StatusDetector using filesystem checks and chezmoi queries.
@@ -89,13 +118,18 @@ func NewDefaultStatusDetector(cm Chezmoi) *DefaultStatusDetector {
 // using NormalizeConfigPath before calling this method.
 //
 // Status detection logic:
-// - StatusSynced: File exists on disk AND managed by ZERB
+// - StatusSynced: File exists on disk, managed by ZERB and unchanged
+// - StatusModified: File exists on disk and managed by ZERB, but differs
 // - StatusMissing: File does NOT exist on disk
 // - StatusPartial: File exists on disk but NOT managed by ZERB
+// - StatusUnknown: File exists on disk but could not be checked or compared
 //
-// The method respects context cancellation and will stop processing if context is cancelled.
+// Detection is best-effort: a file that cannot be checked is reported as
+// StatusUnknown and the others are still detected. The method respects
+// context cancellation and will stop processing if context is cancelled.
 func (d *DefaultStatusDetector) DetectStatus(ctx context.Context, configs []ConfigFile) ([]ConfigWithStatus, error) {
 	results := make([]ConfigWithStatus, 0, len(configs))
+	var managedTargets []string
 
 	for _, cfg := range configs {
 		// Check for context cancellation
@@ -116,14 +150,17 @@ func (d *DefaultStatusDetector) DetectStatus(ctx context.Context, configs []Conf
 			result.Status = StatusMissing
 		} else {
 			// File exists -> check if managed by ZERB
-			managed, err := d.chezmoi.HasFile(ctx, cfg.Path)
-			if err != nil {
-				return nil, fmt.Errorf("check if file %q is managed: %w", cfg.Path, err)
-			}
-
-			if managed {
+			// The source state is named after where the file is applied
+			managed, err := d.chezmoi.HasFile(ctx, cfg.TargetPath())
+			switch {
+			case err != nil && ctx.Err() != nil:
+				return nil, ctx.Err()
+			case err != nil:
+				result.Status = StatusUnknown
+			case managed:
 				result.Status = StatusSynced
-			} else {
+				managedTargets = append(managedTargets, cfg.TargetPath())
+			default:
 				result.Status = StatusPartial
 			}
 		}
@@ -131,5 +168,26 @@ func (d *DefaultStatusDetector) DetectStatus(ctx context.Context, configs []Conf
 		results = append(results, result)
 	}
 
+	if len(managedTargets) == 0 {
+		return results, nil
+	}
+
+	// Compare managed files to their tracked content in one query. If it
+	// fails as a whole, the managed files are listed without a status.
+	statuses, err := d.chezmoi.Status(ctx, managedTargets)
+	if err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	for i, result := range results {
+		if result.Status != StatusSynced {
+			continue
+		}
+		status, ok := statuses[result.ConfigFile.TargetPath()]
+		if err != nil || !ok {
+			status = StatusUnknown
+		}
+		results[i].Status = status
+	}
+
 	return results, nil
 }
@@ -30,8 +30,18 @@ func TestConfigStatus(t *testing.T) {
 			status: StatusPartial,
 			want:   "partial",
 		},
+		{
+			name:   "modified status",
+			status: StatusModified,
+			want:   "modified",
+		},
 		{
 			name:   "unknown status",
+			status: StatusUnknown,
+			want:   "unknown",
+		},
+		{
+			name:   "invalid status",
 			status: ConfigStatus(999),
 			want:   "unknown",
 		},
@@ -69,8 +79,18 @@ func TestConfigStatus_Symbol(t *testing.T) {
 			status: StatusPartial,
 			want:   "?",
 		},
+		{
+			name:   "modified symbol",
+			status: StatusModified,
+			want:   "~",
+		},
 		{
 			name:   "unknown symbol",
+			status: StatusUnknown,
+			want:   "!",
+		},
+		{
+			name:   "invalid symbol",
 			status: ConfigStatus(999),
 			want:   "?",
 		},
@@ -86,9 +106,26 @@ func TestConfigStatus_Symbol(t *testing.T) {
 	}
 }
 
+func TestParseConfigStatus(t *testing.T) {
+	for _, want := range []ConfigStatus{StatusSynced, StatusMissing, StatusPartial, StatusModified, StatusUnknown} {
+		got, err := ParseConfigStatus(want.String())
+		if err != nil || got != want {
+			t.Errorf("ParseConfigStatus(%q) = %v, %v, want %v", want.String(), got, err, want)
+		}
+	}
+	for _, name := range []string{"", "invalid", "Synced"} {
+		if _, err := ParseConfigStatus(name); err == nil {
+			t.Errorf("ParseConfigStatus(%q) expected error, got nil", name)
+		}
+	}
+}
+
 // mockChezmoi implements the Chezmoi interface for testing.
 type mockChezmoi struct {
 	hasFileFunc func(ctx context.Context, path string) (bool, error)
+	statuses    map[string]ConfigStatus // Status of each path; synced if absent
+	statusErr   error
+	statusCalls int
 }
 
 func (m *mockChezmoi) HasFile(ctx context.Context, path string) (bool, error) {
@@ -98,6 +135,22 @@ func (m *mockChezmoi) HasFile(ctx context.Context, path string) (bool, error) {
 	return false, nil
 }
 
+func (m *mockChezmoi) Status(ctx context.Context, paths []string) (map[string]ConfigStatus, error) {
+	m.statusCalls++
+	if m.statusErr != nil {
+		return nil, m.statusErr
+	}
+	statuses := make(map[string]ConfigStatus, len(paths))
+	for _, path := range paths {
+		if status, ok := m.statuses[path]; ok {
+			statuses[path] = status
+		} else {
+			statuses[path] = StatusSynced
+		}
+	}
+	return statuses, nil
+}
+
 // TestDefaultStatusDetector_Synced tests detection of synced configs.
 func TestDefaultStatusDetector_Synced(t *testing.T) {
 	// Create temp directory for testing
@@ -206,7 +259,8 @@ func TestDefaultStatusDetector_Partial(t *testing.T) {
 	}
 }
 
-// TestDefaultStatusDetector_ChezmoiError tests error handling.
+// TestDefaultStatusDetector_ChezmoiError tests that a file that cannot be
+// checked is reported as unknown.
 func TestDefaultStatusDetector_ChezmoiError(t *testing.T) {
 	tmpDir := t.TempDir()
 	testFile := filepath.Join(tmpDir, "test.conf")
@@ -230,13 +284,12 @@ func TestDefaultStatusDetector_ChezmoiError(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	_, err := detector.DetectStatus(ctx, configs)
-	if err == nil {
-		t.Fatal("expected error, got nil")
+	results, err := detector.DetectStatus(ctx, configs)
+	if err != nil {
+		t.Fatalf("DetectStatus() error = %v", err)
 	}
-
-	if !errors.Is(err, expectedErr) {
-		t.Errorf("expected error to wrap %v, got %v", expectedErr, err)
+	if results[0].Status != StatusUnknown {
+		t.Errorf("expected status %q, got %q", StatusUnknown, results[0].Status)
 	}
 }
 
@@ -331,3 +384,78 @@ func TestDefaultStatusDetector_MultipleConfigs(t *testing.T) {
 		t.Errorf("expected missing status for %s, got %q", missingFile, results[2].Status)
 	}
 }
+
+// TestDefaultStatusDetector_Modified tests that managed files that differ
+// from their tracked content are reported as modified.
+func TestDefaultStatusDetector_Modified(t *testing.T) {
+	tmpDir := t.TempDir()
+	syncedFile := filepath.Join(tmpDir, "synced.conf")
+	editedFile := filepath.Join(tmpDir, "edited.conf")
+	for _, file := range []string{syncedFile, editedFile} {
+		if err := os.WriteFile(file, []byte("content"), 0644); err != nil {
+			t.Fatalf("failed to create %s: %v", file, err)
+		}
+	}
+
+	mockCm := &mockChezmoi{
+		hasFileFunc: func(ctx context.Context, path string) (bool, error) {
+			return true, nil
+		},
+		statuses: map[string]ConfigStatus{editedFile: StatusModified},
+	}
+
+	detector := NewDefaultStatusDetector(mockCm)
+	results, err := detector.DetectStatus(context.Background(), []ConfigFile{{Path: syncedFile}, {Path: editedFile}})
+	if err != nil {
+		t.Fatalf("DetectStatus() error = %v", err)
+	}
+	if results[0].Status != StatusSynced {
+		t.Errorf("expected synced status for %s, got %q", syncedFile, results[0].Status)
+	}
+	if results[1].Status != StatusModified {
+		t.Errorf("expected modified status for %s, got %q", editedFile, results[1].Status)
+	}
+
+	// A failed status query still lists the managed files, without a status
+	mockCm.statusErr = errors.New("status failed")
+	results, err = detector.DetectStatus(context.Background(), []ConfigFile{{Path: syncedFile}})
+	if err != nil {
+		t.Fatalf("DetectStatus() error = %v", err)
+	}
+	if results[0].Status != StatusUnknown {
+		t.Errorf("expected unknown status for %s, got %q", syncedFile, results[0].Status)
+	}
+}
+
+// TestDefaultStatusDetector_SingleStatusCall tests that the managed files
+// are checked once each and compared in one query.
+func TestDefaultStatusDetector_SingleStatusCall(t *testing.T) {
+	tmpDir := t.TempDir()
+	var files []ConfigFile
+	for _, name := range []string{"a.conf", "b.conf"} {
+		path := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(path, []byte("content"), 0644); err != nil {
+			t.Fatalf("failed to create %s: %v", path, err)
+		}
+		files = append(files, ConfigFile{Path: path})
+	}
+
+	hasFileCalls := 0
+	mockCm := &mockChezmoi{
+		hasFileFunc: func(ctx context.Context, path string) (bool, error) {
+			hasFileCalls++
+			return true, nil
+		},
+	}
+
+	detector := NewDefaultStatusDetector(mockCm)
+	if _, err := detector.DetectStatus(context.Background(), files); err != nil {
+		t.Fatalf("DetectStatus() error = %v", err)
+	}
+	if hasFileCalls != len(files) {
+		t.Errorf("HasFile called %d times, want %d", hasFileCalls, len(files))
+	}
+	if mockCm.statusCalls != 1 {
+		t.Errorf("Status called %d times, want 1", mockCm.statusCalls)
+	}
+}
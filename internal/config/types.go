@@ -8,9 +8,9 @@ package config
 import (
 	"fmt"
 	"net/url"
-	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 )
@@ -18,15 +18,35 @@ import (
 // Config represents the complete ZERB configuration.
 // This matches the Lua schema defined in the design document.
 type Config struct {
+	// SchemaVersion is the schema version the config was written for.
+	// 0 means the config predates schema_version (treated as version 1).
+	SchemaVersion int `json:"schema_version,omitempty"`
+
 	// Metadata about the configuration
 	Meta Meta `json:"meta,omitempty"`
 
 	// Tools to install via mise (with exact versions)
 	Tools []string `json:"tools,omitempty"`
 
+	// Profiles are named groups of extra tools, selected per machine and
+	// merged with Tools by ResolveTools
+	Profiles map[string][]string `json:"profiles,omitempty"`
+
+	// Backends are tool backends allowed in addition to DefaultBackends,
+	// e.g. for a custom mise plugin
+	Backends []string `json:"backends,omitempty"`
+
 	// Configuration files to manage via chezmoi
 	Configs []ConfigFile `json:"configs,omitempty"`
 
+	// VersionProbes teach drift detection how to read the version of tools
+	// the built-in rules get wrong, by tool name
+	VersionProbes map[string]VersionProbe `json:"version_probe,omitempty"`
+
+	// Encryption is the key secret configs are encrypted to, shared by
+	// every machine that uses the repository
+	Encryption EncryptionConfig `json:"encryption,omitempty"`
+
 	// Git repository settings
 	Git GitConfig `json:"git,omitempty"`
 
@@ -56,18 +76,111 @@ type ConfigFile struct {
 
 	// Private file (chmod 600)
 	Private bool `json:"private,omitempty"`
+
+	// Target is where the file is applied, if not at Path (supports ~).
+	// It must stay within the home directory.
+	Target string `json:"target,omitempty"`
+}
+
+// TargetPath returns the path the config is applied to: Target if set,
+// otherwise Path.
+func (cf ConfigFile) TargetPath() string {
+	if cf.Target != "" {
+		return cf.Target
+	}
+	return cf.Path
+}
+
+// VersionProbe is how to read a tool's version: the arguments to run it
+// with and a regular expression that extracts the version from its output.
+type VersionProbe struct {
+	// Flag holds the arguments, separated by spaces (e.g. "version --client")
+	Flag string `json:"flag"`
+
+	// Regex matches the version in the output (stdout and stderr); its first
+	// capture group is the version if it has one, else the whole match.
+	// Empty uses the default semantic version match.
+	Regex string `json:"regex,omitempty"`
+}
+
+// DefaultAgeIdentity is the age private key file used when an age
+// recipient is configured without an identity.
+const DefaultAgeIdentity = "~/.config/age/keys.txt"
+
+// EncryptionConfig is the key secret configs are encrypted to.
+type EncryptionConfig struct {
+	// Recipient is a GPG key ID or email, or an age public key (age1...)
+	Recipient string `json:"recipient,omitempty"`
+
+	// Identity is the age private key file secrets are decrypted with
+	// (supports ~). It only applies to age recipients.
+	Identity string `json:"identity,omitempty"`
+}
+
+// IsAge reports whether the recipient is an age public key.
+func (e EncryptionConfig) IsAge() bool {
+	return strings.HasPrefix(e.Recipient, "age1")
+}
+
+// IdentityFile returns the identity to decrypt with: Identity, or
+// DefaultAgeIdentity for an age recipient without one.
+func (e EncryptionConfig) IdentityFile() string {
+	if e.Identity == "" && e.IsAge() {
+		return DefaultAgeIdentity
+	}
+	return e.Identity
+}
+
+// Validate checks that an identity is only set for an age recipient and
+// is an absolute or ~/ path.
+func (e EncryptionConfig) Validate() error {
+	if e.Identity == "" {
+		return nil
+	}
+	if !e.IsAge() {
+		return fmt.Errorf("identity only applies to age recipients (age1...)")
+	}
+	if !strings.HasPrefix(e.Identity, "~/") && !filepath.IsAbs(e.Identity) {
+		return fmt.Errorf("identity must be an absolute or ~/ path")
+	}
+	return nil
+}
+
+// IsZero reports whether no encryption key is configured.
+func (e EncryptionConfig) IsZero() bool {
+	return e == EncryptionConfig{}
 }
 
 // GitConfig contains Git repository settings for config versioning.
 type GitConfig struct {
 	Remote string `json:"remote,omitempty"`
 	Branch string `json:"branch,omitempty"`
+
+	// PerHostBranches commits snapshots to a branch named after this machine
+	// so several machines can push without conflicting
+	PerHostBranches bool `json:"per_host_branches,omitempty"`
 }
 
 // Options contains ZERB configuration options.
 type Options struct {
 	// Number of timestamped config backups to retain
 	BackupRetention int `json:"backup_retention,omitempty"`
+
+	// Defaults are the flags new config entries get unless the flag is
+	// given when adding them
+	Defaults ConfigDefaults `json:"defaults,omitempty"`
+}
+
+// ConfigDefaults contains the default flags for new config entries.
+type ConfigDefaults struct {
+	Template bool `json:"template,omitempty"`
+	Secrets  bool `json:"secrets,omitempty"`
+	Private  bool `json:"private,omitempty"`
+}
+
+// IsZero reports whether no default is set.
+func (d ConfigDefaults) IsZero() bool {
+	return d == ConfigDefaults{}
 }
 
 // Metadata contains internal metadata for timestamped configs.
@@ -109,6 +222,55 @@ type Override struct {
 
 // Validate performs basic validation on a Config.
 func (c *Config) Validate() error {
+	return Validator{}.Validate(c)
+}
+
+// ValidateWithRoots is like Validate, but checks config paths against roots
+// instead of the default home and XDG directories.
+func (c *Config) ValidateWithRoots(roots PathRoots) error {
+	return Validator{Roots: &roots}.Validate(c)
+}
+
+// Validator validates configs. The zero value checks config paths against
+// the default home and XDG directories and allows the DefaultBackends.
+type Validator struct {
+	// Roots are the allowed config path roots; nil uses DefaultPathRoots
+	Roots *PathRoots
+
+	// Backends are the allowed tool backends; nil uses DefaultBackends.
+	// A config's own backends list is allowed on top of these.
+	Backends []string
+}
+
+// Validate validates c.
+func (v Validator) Validate(c *Config) error {
+	validatePath, normalizePath := validateConfigPath, NormalizeConfigPath
+	if v.Roots != nil {
+		validatePath, normalizePath = v.Roots.Validate, v.Roots.Normalize
+	}
+	backends := v.Backends
+	if backends == nil {
+		backends = DefaultBackends
+	}
+	return c.validate(validatePath, normalizePath, backends)
+}
+
+// validate validates the config, checking config paths with validatePath
+// and comparing them for duplicates after normalizePath. Tool backends must
+// be in backends or the config's own backends list.
+func (c *Config) validate(validatePath func(string) error, normalizePath func(string) (string, error), backends []string) error {
+	// Backend validation
+	for i, backend := range c.Backends {
+		if !backendNamePattern.MatchString(backend) {
+			return &ValidationError{
+				Field:   fmt.Sprintf("backends[%d]", i),
+				Message: fmt.Sprintf("invalid backend name %q (use lowercase letters, digits, - and _)", backend),
+				value:   backend,
+			}
+		}
+	}
+	allowed := c.allowedBackends(backends)
+
 	// Tool count validation
 	if len(c.Tools) > MaxToolCount {
 		return &ValidationError{
@@ -119,10 +281,38 @@ func (c *Config) Validate() error {
 
 	// Tool validation
 	for i, tool := range c.Tools {
-		if err := validateToolString(tool); err != nil {
+		if err := validateTool(tool, allowed); err != nil {
 			return &ValidationError{
 				Field:   fmt.Sprintf("tools[%d]", i),
 				Message: err.Error(),
+				value:   tool,
+			}
+		}
+	}
+
+	// Profile validation (sorted for deterministic errors)
+	for _, name := range c.ProfileNames() {
+		if !profileNamePattern.MatchString(name) {
+			return &ValidationError{
+				Field:   "profiles",
+				Message: fmt.Sprintf("invalid profile name %q (use lowercase letters, digits, - and _)", name),
+			}
+		}
+
+		tools := c.Profiles[name]
+		if len(tools) > MaxToolCount {
+			return &ValidationError{
+				Field:   "profiles." + name,
+				Message: fmt.Sprintf("too many tools (%d), maximum is %d", len(tools), MaxToolCount),
+			}
+		}
+		for i, tool := range tools {
+			if err := validateTool(tool, allowed); err != nil {
+				return &ValidationError{
+					Field:   fmt.Sprintf("profiles.%s[%d]", name, i),
+					Message: err.Error(),
+					value:   tool,
+				}
 			}
 		}
 	}
@@ -140,12 +330,42 @@ func (c *Config) Validate() error {
 		if cf.Path == "" {
 			return &ValidationError{Field: fmt.Sprintf("configs[%d]", i), Message: "path cannot be empty"}
 		}
-		if err := validateConfigPath(cf.Path); err != nil {
+		if err := validatePath(cf.Path); err != nil {
 			return &ValidationError{
 				Field:   fmt.Sprintf("configs[%d].path", i),
 				Message: err.Error(),
 			}
 		}
+		if cf.Target != "" {
+			if err := validatePath(cf.Target); err != nil {
+				return &ValidationError{
+					Field:   fmt.Sprintf("configs[%d].target", i),
+					Message: err.Error(),
+				}
+			}
+		}
+	}
+
+	// The same config listed twice with different flags is ambiguous
+	for _, dup := range findDuplicateConfigs(c.Configs, normalizePath) {
+		if dup.Conflict {
+			return &ValidationError{
+				Field:   fmt.Sprintf("configs[%d]", dup.Index),
+				Message: dup.message(c.Configs),
+			}
+		}
+	}
+
+	// Version probe validation (sorted for deterministic errors)
+	for _, name := range c.VersionProbeNames() {
+		if err := validateVersionProbe(name, c.VersionProbes[name]); err != nil {
+			return &ValidationError{Field: "version_probe." + name, Message: err.Error()}
+		}
+	}
+
+	// Encryption validation
+	if err := c.Encryption.Validate(); err != nil {
+		return &ValidationError{Field: "encryption.identity", Message: err.Error()}
 	}
 
 	// Git config validation
@@ -158,19 +378,136 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// configDuplicate is a config entry whose path was already declared by an
+// earlier entry.
+type configDuplicate struct {
+	Index    int  // the repeated entry
+	First    int  // the entry that first declared the path
+	Conflict bool // the two entries have different flags
+}
+
+// message describes the duplicate for the user
+func (d configDuplicate) message(configs []ConfigFile) string {
+	path := configs[d.Index].Path
+	if !d.Conflict {
+		return fmt.Sprintf("duplicate config %q (already declared at configs[%d])", path, d.First)
+	}
+	return fmt.Sprintf("duplicate config %q conflicts with configs[%d]: flags {%s} vs {%s}",
+		path, d.First, configFlags(configs[d.Index]), configFlags(configs[d.First]))
+}
+
+// findDuplicateConfigs reports config entries whose path, after
+// normalizePath, was already declared. Paths that cannot be normalized are
+// compared as written.
+func findDuplicateConfigs(configs []ConfigFile, normalizePath func(string) (string, error)) []configDuplicate {
+	var dups []configDuplicate
+	seen := make(map[string]int, len(configs))
+	for i, cf := range configs {
+		if cf.Path == "" {
+			continue
+		}
+		key := cf.Path
+		if normalized, err := normalizePath(cf.Path); err == nil {
+			key = normalized
+		}
+		first, ok := seen[key]
+		if !ok {
+			seen[key] = i
+			continue
+		}
+		dups = append(dups, configDuplicate{
+			Index:    i,
+			First:    first,
+			Conflict: configFlags(cf) != configFlags(configs[first]),
+		})
+	}
+	return dups
+}
+
+// configFlags describes the flags of a config entry, e.g.
+// "recursive, secrets", or "none"
+func configFlags(cf ConfigFile) string {
+	var flags []string
+	for _, flag := range []struct {
+		name string
+		set  bool
+	}{
+		{"recursive", cf.Recursive},
+		{"template", cf.Template},
+		{"secrets", cf.Secrets},
+		{"private", cf.Private},
+	} {
+		if flag.set {
+			flags = append(flags, flag.name)
+		}
+	}
+	if cf.Target != "" {
+		flags = append(flags, "target="+cf.Target)
+	}
+	if len(flags) == 0 {
+		return "none"
+	}
+	return strings.Join(flags, ", ")
+}
+
 // ValidationError represents a config validation error.
 type ValidationError struct {
 	Field   string
 	Message string
+	Line    int // 1-based source line, 0 when unknown
+
+	// value is the offending string value, used to find Line in the source
+	value string
 }
 
 func (e *ValidationError) Error() string {
+	field := e.Field
+	if e.Line > 0 {
+		field = fmt.Sprintf("%s (line %d)", e.Field, e.Line)
+	}
 	if e.Field != "" {
-		return "config validation failed for " + e.Field + ": " + e.Message
+		return "config validation failed for " + field + ": " + e.Message
 	}
 	return "config validation failed: " + e.Message
 }
 
+// profileNamePattern matches valid profile names
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,64}$`)
+
+// versionProbeNamePattern matches the tool names version probes are keyed by
+var versionProbeNamePattern = regexp.MustCompile(`^[a-z0-9_.-]{1,64}$`)
+
+// maxVersionProbeRegexLength bounds version probe regular expressions
+const maxVersionProbeRegexLength = 256
+
+// VersionProbeNames returns the names of the tools with a version probe,
+// sorted.
+func (c *Config) VersionProbeNames() []string {
+	names := make([]string, 0, len(c.VersionProbes))
+	for name := range c.VersionProbes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateVersionProbe checks a version probe for the named tool
+func validateVersionProbe(name string, probe VersionProbe) error {
+	if !versionProbeNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid tool name %q (use lowercase letters, digits, ., - and _)", name)
+	}
+	if strings.TrimSpace(probe.Flag) == "" {
+		return fmt.Errorf("flag cannot be empty")
+	}
+	if len(probe.Regex) > maxVersionProbeRegexLength {
+		return fmt.Errorf("regex too long (%d characters), maximum is %d", len(probe.Regex), maxVersionProbeRegexLength)
+	}
+	if _, err := regexp.Compile(probe.Regex); err != nil {
+		return fmt.Errorf("invalid regex: %w", err)
+	}
+	return nil
+}
+
 // toolStringPattern matches valid tool strings: name@version, backend:name, backend:name@version
 var toolStringPattern = regexp.MustCompile(`^([a-z0-9_-]+:)?[a-z0-9_/-]+(@[a-z0-9._-]+)?$`)
 
@@ -181,8 +518,8 @@ func validateToolString(tool string) error {
 	}
 
 	// Check length
-	if len(tool) > 256 {
-		return fmt.Errorf("tool string too long (%d chars, max 256)", len(tool))
+	if len(tool) > MaxToolStringLength {
+		return fmt.Errorf("tool string too long (%d chars, max %d)", len(tool), MaxToolStringLength)
 	}
 
 	// Validate format
@@ -193,136 +530,106 @@ func validateToolString(tool string) error {
 	return nil
 }
 
-// NormalizeConfigPath normalizes a config path to a canonical form for duplicate detection.
-// It expands tilde, resolves symlinks, and cleans the path.
-// Returns the normalized absolute path or an error if the path is invalid.
-func NormalizeConfigPath(path string) (string, error) {
-	if path == "" {
-		return "", fmt.Errorf("path cannot be empty")
-	}
+// CoreBackend is the tool manager's default backend. "core:node" and "node"
+// name the same tool.
+const CoreBackend = "core"
 
-	// Get home directory
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("cannot determine home directory: %w", err)
-	}
+// DefaultBackends are the tool backends the tool manager supports. Configs
+// can allow more with a backends list.
+var DefaultBackends = []string{
+	"aqua", "asdf", "cargo", "conda", CoreBackend, "dotnet", "gem", "github",
+	"gitlab", "go", "http", "npm", "pipx", "spm", "ubi", "vfox",
+}
 
-	// Expand tilde
-	var absPath string
-	if strings.HasPrefix(path, "~/") {
-		absPath = filepath.Join(home, path[2:])
-	} else if path == "~" {
-		absPath = home
-	} else if filepath.IsAbs(path) {
-		absPath = path
-	} else {
-		return "", fmt.Errorf("must be absolute or start with ~/")
-	}
+// backendNamePattern matches valid backend names
+var backendNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,64}$`)
 
-	// Clean and resolve symlinks
-	absPath = filepath.Clean(absPath)
-	evalPath, err := filepath.EvalSymlinks(absPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			// Path doesn't exist - validate parent directory instead
-			parentDir := filepath.Dir(absPath)
-			parentEval, parentErr := filepath.EvalSymlinks(parentDir)
-			if parentErr != nil {
-				// Parent doesn't exist or has resolution issues - use cleaned path
-				// This is acceptable for NormalizeConfigPath as it's used for duplicate detection
-				// and the path will be validated separately by validateConfigPath
-				return absPath, nil
-			}
-			// Use the resolved parent with the original filename
-			absPath = filepath.Join(parentEval, filepath.Base(absPath))
-		} else {
-			// Other error (permission denied, symlink loop, etc.) - use cleaned path
-			// Similar reasoning as above
-			return absPath, nil
-		}
-	} else {
-		// Path exists, use resolved canonical path
-		absPath = evalPath
+// allowedBackends returns the set of backends, plus the config's own
+func (c *Config) allowedBackends(backends []string) map[string]bool {
+	allowed := make(map[string]bool, len(backends)+len(c.Backends))
+	for _, backend := range backends {
+		allowed[backend] = true
+	}
+	for _, backend := range c.Backends {
+		allowed[backend] = true
 	}
+	return allowed
+}
 
-	return absPath, nil
+// validateTool validates a tool string and checks that its backend, if
+// any, is allowed.
+func validateTool(tool string, allowed map[string]bool) error {
+	if err := validateToolString(tool); err != nil {
+		return err
+	}
+	backend, _, hasBackend := strings.Cut(tool, ":")
+	if hasBackend && !allowed[backend] {
+		return fmt.Errorf("unknown backend %q in %q (add it to backends to allow it)", backend, tool)
+	}
+	return nil
 }
 
-// validateConfigPath validates a config file path for security.
-// It prevents path traversal attacks and restricts to home directory.
-// Uses canonical path checking with symlink resolution to prevent escapes.
-func validateConfigPath(path string) error {
-	if path == "" {
-		return fmt.Errorf("path cannot be empty")
+// CanonicalTool returns the canonical form of a tool string, so equivalent
+// strings compare equal:
+//   - an empty version is dropped ("node@" becomes "node")
+//   - the redundant core backend is dropped ("core:node" becomes "node")
+//   - backend and name are lowercased, as the tool grammar only allows
+//     lowercase; the version is kept as written
+//
+// The string is not validated; use it for comparisons, not for output.
+func CanonicalTool(tool string) string {
+	tool = strings.TrimSpace(tool)
+
+	nameVersion, version, hasVersion := strings.Cut(tool, "@")
+	backend, name, hasBackend := strings.Cut(nameVersion, ":")
+	if !hasBackend {
+		backend, name = "", nameVersion
 	}
 
-	// Get home directory
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("cannot determine home directory: %w", err)
+	backend = strings.ToLower(backend)
+	if backend == CoreBackend {
+		backend = ""
 	}
-	homeAbs := filepath.Clean(home)
 
-	// Expand and normalize the path
-	var absPath string
-	if strings.HasPrefix(path, "~/") {
-		absPath = filepath.Join(home, path[2:])
-	} else if path == "~" {
-		absPath = home
-	} else if filepath.IsAbs(path) {
-		absPath = path
-	} else {
-		return fmt.Errorf("must be absolute or start with ~/")
+	canonical := strings.ToLower(name)
+	if backend != "" {
+		canonical = backend + ":" + canonical
+	}
+	if hasVersion && version != "" {
+		canonical += "@" + version
 	}
+	return canonical
+}
 
-	// Clean the path
-	absPath = filepath.Clean(absPath)
+// NormalizeConfigPath normalizes a config path to a canonical form for duplicate detection.
+// It expands tilde, $XDG_CONFIG_HOME and $XDG_DATA_HOME, resolves symlinks, and cleans the path.
+// Returns the normalized absolute path or an error if the path is invalid.
+func NormalizeConfigPath(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("path cannot be empty")
+	}
 
-	// Try to resolve symlinks for canonical path (allow non-existent paths)
-	evalPath, err := filepath.EvalSymlinks(absPath)
+	roots, err := DefaultPathRoots()
 	if err != nil {
-		if os.IsNotExist(err) {
-			// Path doesn't exist - try to validate parent directory instead
-			parentDir := filepath.Dir(absPath)
-			parentEval, parentErr := filepath.EvalSymlinks(parentDir)
-			if parentErr != nil {
-				if os.IsNotExist(parentErr) {
-					// Parent also doesn't exist - this is OK for validation
-					// The path will be checked for existence at add time
-					// For now, just use the cleaned path
-					absPath = filepath.Clean(absPath)
-				} else {
-					// Other error (permission denied, symlink loop, etc.)
-					return fmt.Errorf("cannot evaluate parent directory: %w", parentErr)
-				}
-			} else {
-				// Use the resolved parent with the original filename
-				absPath = filepath.Join(parentEval, filepath.Base(absPath))
-			}
-		} else {
-			// Other error (permission denied, symlink loop, etc.)
-			return fmt.Errorf("cannot evaluate path: %w", err)
-		}
-	} else {
-		// Path exists, use resolved canonical path
-		absPath = evalPath
+		return "", err
 	}
+	return roots.Normalize(path)
+}
 
-	// Verify the path is within home directory
-	// Check prefix carefully - must have separator or be exact match
-	if !strings.HasPrefix(absPath, homeAbs+string(filepath.Separator)) && absPath != homeAbs {
-		return fmt.Errorf("absolute paths outside home directory not allowed: %s", path)
+// validateConfigPath validates a config file path for security.
+// It prevents path traversal attacks and restricts to home directory
+// (or the XDG base directory the path starts with).
+// Uses canonical path checking with symlink resolution to prevent escapes.
+func validateConfigPath(path string) error {
+	if path == "" {
+		return fmt.Errorf("path cannot be empty")
 	}
 
-	// Use filepath.Rel to verify no directory traversal
-	// This is the secure way to check - it computes the relative path
-	// and if it starts with "..", then there's traversal happening
-	rel, err := filepath.Rel(homeAbs, absPath)
-	if err != nil || strings.HasPrefix(rel, "..") {
-		return fmt.Errorf("path traversal not allowed: %s", path)
+	roots, err := DefaultPathRoots()
+	if err != nil {
+		return err
 	}
-
-	return nil
+	return roots.Validate(path)
 }
 
 // validateGitRemote validates a Git remote URL.
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -71,6 +72,18 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:    "identity with gpg recipient",
+			config:  &Config{Encryption: EncryptionConfig{Recipient: "0x1234ABCD", Identity: "~/key.txt"}},
+			wantErr: true,
+			errMsg:  "identity only applies to age recipients",
+		},
+		{
+			name:    "relative identity",
+			config:  &Config{Encryption: EncryptionConfig{Recipient: "age1example", Identity: "key.txt"}},
+			wantErr: true,
+			errMsg:  "identity must be an absolute or ~/ path",
+		},
 	}
 
 	for _, tt := range tests {
@@ -137,6 +150,108 @@ func TestValidateToolString(t *testing.T) {
 	}
 }
 
+func TestValidator_Backends(t *testing.T) {
+	tests := []struct {
+		name      string
+		validator Validator
+		config    *Config
+		errMsg    string
+	}{
+		{
+			name:   "default backend",
+			config: &Config{Tools: []string{"cargo:ripgrep", "core:node@20.11.0", "ubi:sharkdp/bat"}},
+		},
+		{
+			name:   "unknown backend",
+			config: &Config{Tools: []string{"node@20.11.0", "carg:ripgrep"}},
+			errMsg: `tools[1]: unknown backend "carg" in "carg:ripgrep"`,
+		},
+		{
+			name:   "unknown backend in profile",
+			config: &Config{Profiles: map[string][]string{"work": {"nmp:prettier"}}},
+			errMsg: `profiles.work[0]: unknown backend "nmp"`,
+		},
+		{
+			name:   "backend allowed by config",
+			config: &Config{Backends: []string{"mybackend"}, Tools: []string{"mybackend:mytool"}},
+		},
+		{
+			name:   "invalid backend name",
+			config: &Config{Backends: []string{"My Backend"}},
+			errMsg: `backends[0]: invalid backend name "My Backend"`,
+		},
+		{
+			name:      "validator backends replace the defaults",
+			validator: Validator{Backends: []string{"mybackend"}},
+			config:    &Config{Tools: []string{"cargo:ripgrep"}},
+			errMsg:    `unknown backend "cargo"`,
+		},
+		{
+			name:      "config backends extend the validator's",
+			validator: Validator{Backends: []string{"mybackend"}},
+			config:    &Config{Backends: []string{"cargo"}, Tools: []string{"cargo:ripgrep", "mybackend:mytool"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.validator.Validate(tt.config)
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Validate() error = %v, want it to contain %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestCanonicalTool(t *testing.T) {
+	equivalent := [][]string{
+		{"node", "node@", "core:node", "core:node@", "Node", "CORE:NODE", " node "},
+		{"node@20.11.0", "core:node@20.11.0", "NODE@20.11.0"},
+		{"cargo:ripgrep@14.1.0", "Cargo:RipGrep@14.1.0"},
+		{"ubi:sharkdp/bat", "UBI:sharkdp/bat@"},
+	}
+	for _, group := range equivalent {
+		want := CanonicalTool(group[0])
+		for _, tool := range group[1:] {
+			if got := CanonicalTool(tool); got != want {
+				t.Errorf("CanonicalTool(%q) = %q, want %q (same as %q)", tool, got, want, group[0])
+			}
+		}
+	}
+
+	different := [][2]string{
+		{"node", "node@20"},
+		{"node@20", "node@22"},
+		{"node", "nodejs"},
+		{"cargo:ripgrep", "ripgrep"},
+		{"cargo:ripgrep", "npm:ripgrep"},
+		{"node@rc", "node@RC"},
+	}
+	for _, pair := range different {
+		if CanonicalTool(pair[0]) == CanonicalTool(pair[1]) {
+			t.Errorf("CanonicalTool(%q) and CanonicalTool(%q) should differ, both %q", pair[0], pair[1], CanonicalTool(pair[0]))
+		}
+	}
+
+	if got := CanonicalTool("core:node@20.11.0"); got != "node@20.11.0" {
+		t.Errorf("CanonicalTool(core:node@20.11.0) = %q, want node@20.11.0", got)
+	}
+
+	// Comparisons use the canonical form
+	if d := DiffConfigs(&Config{Tools: []string{"node@20.11.0"}}, &Config{Tools: []string{"core:node@20.11.0"}}); !d.IsEmpty() {
+		t.Errorf("DiffConfigs() of equivalent tools = %+v, want empty", d.Tools)
+	}
+	if kept, removed := DedupeTools([]string{"node@20.11.0", "core:node@22.0.0"}); len(kept) != 1 || len(removed) != 1 {
+		t.Errorf("DedupeTools() = %v, %v; want the core:node entry removed", kept, removed)
+	}
+}
+
 func TestMetadata(t *testing.T) {
 	now := time.Now()
 	metadata := Metadata{
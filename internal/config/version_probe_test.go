@@ -0,0 +1,86 @@
+package config
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestParser_ParseString_VersionProbes(t *testing.T) {
+	parser := NewParser(nil)
+	cfg, err := parser.ParseString(context.Background(), `zerb = {
+  tools = { "java@21.0.2", "kubectl@1.29.0" },
+  version_probe = {
+    java = { flag = "-version", regex = 'version "([^"]+)"' },
+    ["my-tool"] = { flag = "version --short" },
+  },
+}`)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+
+	want := map[string]VersionProbe{
+		"java":    {Flag: "-version", Regex: `version "([^"]+)"`},
+		"my-tool": {Flag: "version --short"},
+	}
+	if !reflect.DeepEqual(cfg.VersionProbes, want) {
+		t.Errorf("VersionProbes = %v, want %v", cfg.VersionProbes, want)
+	}
+
+	// The generated config reads back the same
+	content, err := NewGenerator().Generate(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	reparsed, err := parser.ParseString(context.Background(), content)
+	if err != nil {
+		t.Fatalf("ParseString(generated) error = %v\n%s", err, content)
+	}
+	if !reflect.DeepEqual(reparsed.VersionProbes, want) {
+		t.Errorf("round-tripped VersionProbes = %v, want %v", reparsed.VersionProbes, want)
+	}
+}
+
+func TestConfig_Validate_VersionProbes(t *testing.T) {
+	tests := []struct {
+		name      string
+		probes    map[string]VersionProbe
+		wantField string
+	}{
+		{
+			name:   "valid",
+			probes: map[string]VersionProbe{"java": {Flag: "-version", Regex: `version "(.+)"`}},
+		},
+		{
+			name:      "empty flag",
+			probes:    map[string]VersionProbe{"java": {Flag: "  "}},
+			wantField: "version_probe.java",
+		},
+		{
+			name:      "invalid regex",
+			probes:    map[string]VersionProbe{"java": {Flag: "-version", Regex: "(unclosed"}},
+			wantField: "version_probe.java",
+		},
+		{
+			name:      "invalid tool name",
+			probes:    map[string]VersionProbe{"Java Tool": {Flag: "-version"}},
+			wantField: "version_probe.Java Tool",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Config{VersionProbes: tt.probes}).Validate()
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			verr, ok := err.(*ValidationError)
+			if !ok || verr.Field != tt.wantField {
+				t.Errorf("Validate() error = %v, want validation error for %s", err, tt.wantField)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// maxWarningKeyLength bounds how much of an unknown key is echoed back
+const maxWarningKeyLength = 64
+
+// knownTopLevelKeys are the keys the parser reads from the zerb table
+var knownTopLevelKeys = []string{
+	luaFieldSchemaVersion,
+	luaFieldMeta,
+	luaFieldTools,
+	luaFieldConfigs,
+	luaFieldGit,
+	luaFieldConfig,
+	luaFieldProfiles,
+	luaFieldVersionProbe,
+	luaFieldBackends,
+	luaFieldEncryption,
+}
+
+// ParseWarning is a problem that does not stop a config from loading,
+// such as a misspelled key that is silently ignored.
+type ParseWarning struct {
+	Key     string // Sanitized key name
+	Line    int    // 1-based source line, 0 when unknown
+	Message string // Sanitized, user-friendly message
+}
+
+// String returns the warning as "line N: message".
+func (w ParseWarning) String() string {
+	if w.Line > 0 {
+		return fmt.Sprintf("line %d: %s", w.Line, w.Message)
+	}
+	return w.Message
+}
+
+// unknownKeyWarnings reports keys of the zerb table the parser does not
+// read, in source order
+func unknownKeyWarnings(table *lua.LTable, source string) []ParseWarning {
+	known := make(map[string]bool, len(knownTopLevelKeys))
+	for _, key := range knownTopLevelKeys {
+		known[key] = true
+	}
+
+	var warnings []ParseWarning
+	table.ForEach(func(k, _ lua.LValue) {
+		name, isString := k.(lua.LString)
+		if isString && known[string(name)] {
+			return
+		}
+
+		key := sanitizeWarningKey(k.String())
+		warning := ParseWarning{Key: key}
+		if isString {
+			warning.Line = findKeyLine(source, string(name))
+			warning.Message = fmt.Sprintf("unknown key %q in zerb table is ignored", key)
+			if suggestion := suggestKey(string(name), knownTopLevelKeys); suggestion != "" {
+				warning.Message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+			}
+		} else {
+			// Positional entries such as zerb = { "node@20" }
+			warning.Message = fmt.Sprintf("unexpected %s entry [%s] in zerb table is ignored", k.Type(), key)
+		}
+		warnings = append(warnings, warning)
+	})
+
+	// Table iteration order is random; report in source order
+	sortWarnings(warnings)
+	return warnings
+}
+
+// sortWarnings sorts warnings in source order; warnings without a line go
+// last
+func sortWarnings(warnings []ParseWarning) {
+	sort.SliceStable(warnings, func(i, j int) bool {
+		a, b := warnings[i], warnings[j]
+		if a.Line != b.Line {
+			if a.Line == 0 || b.Line == 0 {
+				return b.Line == 0
+			}
+			return a.Line < b.Line
+		}
+		return a.Key < b.Key
+	})
+}
+
+// mergeDuplicateConfigs drops config entries that repeat an earlier entry
+// with the same flags, returning the remaining configs and a warning for
+// each dropped entry. Conflicting duplicates are left for validation to
+// reject.
+func mergeDuplicateConfigs(configs []ConfigFile, normalizePath func(string) (string, error), source string) ([]ConfigFile, []ParseWarning) {
+	dups := findDuplicateConfigs(configs, normalizePath)
+	if len(dups) == 0 {
+		return configs, nil
+	}
+
+	lines := newLineIndex(source)
+	configLines := make([]int, len(configs))
+	for i, cf := range configs {
+		configLines[i] = lines.find(cf.Path)
+	}
+
+	drop := make(map[int]bool, len(dups))
+	var warnings []ParseWarning
+	for _, dup := range dups {
+		if dup.Conflict {
+			continue
+		}
+		drop[dup.Index] = true
+		warnings = append(warnings, ParseWarning{
+			Key:     fmt.Sprintf("configs[%d]", dup.Index),
+			Line:    configLines[dup.Index],
+			Message: dup.message(configs) + "; the repeat is ignored",
+		})
+	}
+
+	kept := make([]ConfigFile, 0, len(configs)-len(drop))
+	for i, cf := range configs {
+		if !drop[i] {
+			kept = append(kept, cf)
+		}
+	}
+	return kept, warnings
+}
+
+// sanitizeWarningKey makes a key safe to print: control characters are
+// replaced and overly long keys are truncated
+func sanitizeWarningKey(key string) string {
+	key = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || !unicode.IsPrint(r) {
+			return '?'
+		}
+		return r
+	}, key)
+
+	if runes := []rune(key); len(runes) > maxWarningKeyLength {
+		key = string(runes[:maxWarningKeyLength]) + "..."
+	}
+	return key
+}
+
+// findKeyLine returns the 1-based line where key is first assigned in a
+// table constructor (key = or ["key"] =), or 0 if it is not found
+func findKeyLine(source, key string) int {
+	quoted := regexp.QuoteMeta(key)
+	pattern, err := regexp.Compile(`(?:^|[^\w.])` + quoted + `\s*=|\[\s*["']` + quoted + `["']\s*\]\s*=`)
+	if err != nil {
+		return 0
+	}
+
+	for i, line := range strings.Split(source, "\n") {
+		if pattern.MatchString(line) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// suggestKey returns the known key closest to key if it is a likely typo
+func suggestKey(key string, known []string) string {
+	lower := strings.ToLower(key)
+	best, bestDist := "", 3
+	for _, candidate := range known {
+		if dist := editDistance(lower, candidate); dist < bestDist {
+			best, bestDist = candidate, dist
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
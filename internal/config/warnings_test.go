@@ -0,0 +1,208 @@
+package config
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParser_ParseStringWithWarnings(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      string
+		wantKeys  []string
+		wantLines []int
+		wantMsg   []string // substrings expected in each warning message
+	}{
+		{
+			name: "known keys only",
+			code: `zerb = {
+  meta = { name = "test" },
+  tools = { "node@20" },
+  configs = {},
+  git = { branch = "main" },
+  config = { backup_retention = 3 },
+}`,
+		},
+		{
+			name: "misspelled tools",
+			code: `zerb = {
+  meta = { name = "test" },
+  toools = { "node@20" },
+}`,
+			wantKeys:  []string{"toools"},
+			wantLines: []int{3},
+			wantMsg:   []string{`unknown key "toools" in zerb table is ignored (did you mean "tools"?)`},
+		},
+		{
+			name: "several unknown keys in source order",
+			code: `zerb = {
+  zzz = true,
+  tools = {},
+  ["aaa"] = 1,
+}`,
+			wantKeys:  []string{"zzz", "aaa"},
+			wantLines: []int{2, 4},
+			wantMsg:   []string{`unknown key "zzz"`, `unknown key "aaa"`},
+		},
+		{
+			name: "unrelated key has no suggestion",
+			code: `zerb = {
+  packages = {},
+}`,
+			wantKeys:  []string{"packages"},
+			wantLines: []int{2},
+			wantMsg:   []string{`unknown key "packages" in zerb table is ignored`},
+		},
+		{
+			name:      "positional entry",
+			code:      `zerb = { "node@20" }`,
+			wantKeys:  []string{"1"},
+			wantLines: []int{0},
+			wantMsg:   []string{"unexpected number entry [1]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewParser(nil)
+			cfg, warnings, err := parser.ParseStringWithWarnings(context.Background(), tt.code)
+			if err != nil {
+				t.Fatalf("ParseStringWithWarnings() error = %v", err)
+			}
+			if cfg == nil {
+				t.Fatal("ParseStringWithWarnings() returned nil config")
+			}
+
+			if len(warnings) != len(tt.wantKeys) {
+				t.Fatalf("got %d warnings, want %d: %v", len(warnings), len(tt.wantKeys), warnings)
+			}
+			for i, w := range warnings {
+				if w.Key != tt.wantKeys[i] {
+					t.Errorf("warnings[%d].Key = %q, want %q", i, w.Key, tt.wantKeys[i])
+				}
+				if w.Line != tt.wantLines[i] {
+					t.Errorf("warnings[%d].Line = %d, want %d", i, w.Line, tt.wantLines[i])
+				}
+				if !strings.Contains(w.Message, tt.wantMsg[i]) {
+					t.Errorf("warnings[%d].Message = %q, want it to contain %q", i, w.Message, tt.wantMsg[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParser_ParseString_IgnoresUnknownKeys(t *testing.T) {
+	parser := NewParser(nil)
+	cfg, err := parser.ParseString(context.Background(), `zerb = { toools = { "node@20" } }`)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+	if len(cfg.Tools) != 0 {
+		t.Errorf("Tools = %v, want none", cfg.Tools)
+	}
+}
+
+func TestParser_ParseString_DuplicateConfigs(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	tests := []struct {
+		name        string
+		code        string
+		wantPaths   []string
+		wantWarning string
+		wantErr     string
+	}{
+		{
+			name: "identical flags are merged",
+			code: `zerb = {
+  configs = {
+    { path = "~/.ssh/config", private = true },
+    "~/.zshrc",
+    { path = "~/.ssh/../.ssh/config", private = true },
+  },
+}`,
+			wantPaths:   []string{"~/.ssh/config", "~/.zshrc"},
+			wantWarning: `line 5: duplicate config "~/.ssh/../.ssh/config" (already declared at configs[0]); the repeat is ignored`,
+		},
+		{
+			name: "conflicting flags are rejected",
+			code: `zerb = {
+  configs = {
+    { path = "~/.ssh/config", secrets = true, private = true },
+    "~/.ssh/config",
+  },
+}`,
+			wantErr: `configs[1]: duplicate config "~/.ssh/config" conflicts with configs[0]: flags {none} vs {secrets, private}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, warnings, err := NewParser(nil).ParseStringWithWarnings(context.Background(), tt.code)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseStringWithWarnings() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseStringWithWarnings() error = %v", err)
+			}
+
+			var paths []string
+			for _, cf := range cfg.Configs {
+				paths = append(paths, cf.Path)
+			}
+			if strings.Join(paths, ",") != strings.Join(tt.wantPaths, ",") {
+				t.Errorf("Configs = %v, want %v", paths, tt.wantPaths)
+			}
+			if len(warnings) != 1 || warnings[0].String() != tt.wantWarning {
+				t.Errorf("warnings = %v, want [%s]", warnings, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestParser_ParseStringWithWarnings_Error(t *testing.T) {
+	parser := NewParser(nil)
+	_, warnings, err := parser.ParseStringWithWarnings(context.Background(), `zerb = { toools = {}, tools = { "bad tool!" } }`)
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	if warnings != nil {
+		t.Errorf("warnings = %v, want nil on error", warnings)
+	}
+}
+
+func TestSanitizeWarningKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{"plain", "toools", "toools"},
+		{"control characters", "bad\x1b[31mkey\n", "bad?[31mkey?"},
+		{"long key", strings.Repeat("k", 100), strings.Repeat("k", maxWarningKeyLength) + "..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeWarningKey(tt.key); got != tt.want {
+				t.Errorf("sanitizeWarningKey(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWarning_String(t *testing.T) {
+	w := ParseWarning{Key: "toools", Line: 3, Message: "unknown key"}
+	if got := w.String(); got != "line 3: unknown key" {
+		t.Errorf("String() = %q", got)
+	}
+
+	w.Line = 0
+	if got := w.String(); got != "unknown key" {
+		t.Errorf("String() = %q", got)
+	}
+}
@@ -10,6 +10,9 @@ import (
 	"strconv"
 	"sync"
 	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
+	"golang.org/x/sync/errgroup"
 )
 
 // VersionCache provides caching for version detection results.
@@ -33,6 +36,7 @@ type InMemoryVersionCache struct {
 	entries    map[string]versionCacheEntry
 	ttl        time.Duration
 	maxEntries int
+	clock      clock.Clock
 }
 
 // NewVersionCache creates a new in-memory version cache with default settings.
@@ -46,7 +50,19 @@ func NewVersionCacheWithOptions(ttl time.Duration, maxEntries int) *InMemoryVers
 		entries:    make(map[string]versionCacheEntry),
 		ttl:        ttl,
 		maxEntries: maxEntries,
+		clock:      clock.Real{},
+	}
+}
+
+// WithClock sets the clock used for TTL expiry and returns the cache.
+func (c *InMemoryVersionCache) WithClock(clk clock.Clock) *InMemoryVersionCache {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if clk == nil {
+		clk = clock.Real{}
 	}
+	c.clock = clk
+	return c
 }
 
 // Get returns the cached version for a binary path, or ("", false) if not cached or expired.
@@ -55,7 +71,7 @@ func (c *InMemoryVersionCache) Get(binaryPath string) (string, bool) {
 	defer c.mu.RUnlock()
 
 	if entry, exists := c.entries[binaryPath]; exists {
-		if time.Since(entry.timestamp) < c.ttl {
+		if c.clock.Now().Sub(entry.timestamp) < c.ttl {
 			return entry.version, true
 		}
 	}
@@ -69,7 +85,7 @@ func (c *InMemoryVersionCache) Set(binaryPath string, version string) {
 
 	c.entries[binaryPath] = versionCacheEntry{
 		version:   version,
-		timestamp: time.Now(),
+		timestamp: c.clock.Now(),
 	}
 
 	// Prune if needed
@@ -81,7 +97,7 @@ func (c *InMemoryVersionCache) Set(binaryPath string, version string) {
 // pruneExpiredEntries removes expired entries from the cache.
 // Must be called with c.mu.Lock() held.
 func (c *InMemoryVersionCache) pruneExpiredEntries() {
-	now := time.Now()
+	now := c.clock.Now()
 	for path, entry := range c.entries {
 		if now.Sub(entry.timestamp) >= c.ttl {
 			delete(c.entries, path)
@@ -123,7 +139,38 @@ const (
 
 // defaultVersionCache is the package-level default cache for backwards compatibility.
 // New code should prefer passing a VersionCache explicitly.
-var defaultVersionCache = NewVersionCache()
+var defaultVersionCache VersionCache = NewVersionCache()
+
+// VersionOptions configures how tool versions are detected. The zero value
+// uses the built-in version probes and the default package-level cache.
+type VersionOptions struct {
+	// Probes are the version probes by tool name, e.g. from
+	// LoadVersionProbes; nil uses DefaultVersionProbes
+	Probes VersionProbes
+	// Records, if set, keeps detected versions on disk across runs, in a
+	// PersistentVersionCache
+	Records *VersionRecordStore
+	// Cache caches detected versions, overriding Records; nil uses Records
+	// or else the default package-level cache
+	Cache VersionCache
+}
+
+// resolve returns the cache and probes version detection uses
+func (o VersionOptions) resolve() (VersionCache, VersionProbes) {
+	cache := o.Cache
+	if cache == nil {
+		if o.Records != nil {
+			cache = NewPersistentVersionCache(o.Records)
+		} else {
+			cache = defaultVersionCache
+		}
+	}
+	probes := o.Probes
+	if probes == nil {
+		probes = DefaultVersionProbes()
+	}
+	return cache, probes
+}
 
 // getVersionTimeout returns the version detection timeout from env or default
 func getVersionTimeout() time.Duration {
@@ -137,44 +184,108 @@ func getVersionTimeout() time.Duration {
 
 // QueryActive queries the active environment for tools in PATH.
 // Uses the default package-level cache for version detection.
-func QueryActive(ctx context.Context, toolNames []string, forceRefresh bool) ([]Tool, error) {
-	return QueryActiveWithCache(ctx, toolNames, forceRefresh, defaultVersionCache)
+// searchPath optionally lists the directories to search instead of $PATH.
+func QueryActive(ctx context.Context, toolNames []string, forceRefresh bool, searchPath ...string) ([]Tool, error) {
+	return QueryActiveWithOptions(ctx, toolNames, forceRefresh, VersionOptions{}, searchPath...)
 }
 
 // QueryActiveWithCache queries the active environment for tools in PATH using the provided cache.
-func QueryActiveWithCache(ctx context.Context, toolNames []string, forceRefresh bool, cache VersionCache) ([]Tool, error) {
-	var tools []Tool
-
-	for _, name := range toolNames {
-		// Find tool in PATH
-		path, err := exec.LookPath(name)
-		if err != nil {
-			// Tool not found in PATH, skip
-			continue
-		}
+// searchPath optionally lists the directories to search instead of $PATH.
+func QueryActiveWithCache(ctx context.Context, toolNames []string, forceRefresh bool, cache VersionCache, searchPath ...string) ([]Tool, error) {
+	if cache == nil {
+		// A nil cache disables caching
+		return queryActive(ctx, toolNames, forceRefresh, nil, DefaultVersionProbes(), searchPath)
+	}
+	return QueryActiveWithOptions(ctx, toolNames, forceRefresh, VersionOptions{Cache: cache}, searchPath...)
+}
 
-		// Resolve symlinks to get actual binary path
-		resolvedPath, err := filepath.EvalSymlinks(path)
-		if err != nil {
-			// If symlink resolution fails, use original path
-			resolvedPath = path
-		}
+// QueryActiveWithOptions queries the active environment for tools in PATH,
+// detecting versions as opts configures.
+// searchPath optionally lists the directories to search instead of $PATH.
+func QueryActiveWithOptions(ctx context.Context, toolNames []string, forceRefresh bool, opts VersionOptions, searchPath ...string) ([]Tool, error) {
+	cache, probes := opts.resolve()
+	return queryActive(ctx, toolNames, forceRefresh, cache, probes, searchPath)
+}
 
-		// Detect version (with caching)
-		version, err := DetectVersionWithCache(ctx, resolvedPath, forceRefresh, cache)
-		if err != nil {
-			// Mark as unknown if version detection fails
-			version = "unknown"
-		}
+// queryActive implements QueryActiveWithOptions; a nil cache disables caching
+func queryActive(ctx context.Context, toolNames []string, forceRefresh bool, cache VersionCache, probes VersionProbes, searchPath []string) ([]Tool, error) {
+	if len(searchPath) == 0 {
+		searchPath = filepath.SplitList(os.Getenv("PATH"))
+	}
 
-		tools = append(tools, Tool{
-			Name:    name,
-			Version: version,
-			Path:    resolvedPath,
+	// Detect versions concurrently, up to Parallelism() at a time; found[i]
+	// is toolNames[i], nil when not in the search path
+	found := make([]*Tool, len(toolNames))
+	var g errgroup.Group
+	g.SetLimit(Parallelism())
+
+	for i, name := range toolNames {
+		g.Go(func() error {
+			// Find tool in the search path, keeping the entries it shadows
+			allPaths := ExplainPath(name, searchPath...)
+			if len(allPaths) == 0 {
+				// Tool not found in PATH, skip
+				return nil
+			}
+			path := allPaths[0]
+
+			// Resolve symlinks to get actual binary path
+			resolvedPath, err := filepath.EvalSymlinks(path)
+			if err != nil {
+				// If symlink resolution fails, use original path
+				resolvedPath = path
+			}
+
+			// Detect version (with caching), by the name the tool was looked up
+			// by: the resolved binary may be named differently (python3.12)
+			version, err := detectToolVersion(ctx, name, resolvedPath, nil, forceRefresh, cache, probes)
+			if err != nil {
+				// Mark as unknown if version detection fails
+				version = "unknown"
+			}
+
+			found[i] = &Tool{
+				Name:     name,
+				Version:  version,
+				Path:     resolvedPath,
+				AllPaths: allPaths,
+			}
+			return nil
 		})
 	}
+	_ = g.Wait() // Workers never fail
+
+	return collectTools(found), nil
+}
+
+// collectTools returns the found tools, in order
+func collectTools(found []*Tool) []Tool {
+	var tools []Tool
+	for _, tool := range found {
+		if tool != nil {
+			tools = append(tools, *tool)
+		}
+	}
+	return tools
+}
 
-	return tools, nil
+// lookPath finds an executable named name in the directories of searchPath,
+// in order, as exec.LookPath does for $PATH. Empty and relative directories
+// are skipped, as exec.LookPath refuses results relative to the current
+// directory.
+func lookPath(name string, searchPath []string) (string, error) {
+	for _, dir := range searchPath {
+		if dir == "" || !filepath.IsAbs(dir) {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+			continue
+		}
+		return path, nil
+	}
+	return "", fmt.Errorf("%s: %w", name, exec.ErrNotFound)
 }
 
 // DetectVersionCached detects the version of a binary with caching.
@@ -187,38 +298,80 @@ func DetectVersionCached(ctx context.Context, binaryPath string, forceRefresh bo
 // Uses a TTL cache to avoid repeated subprocess calls.
 // Set forceRefresh to true to bypass the cache.
 func DetectVersionWithCache(ctx context.Context, binaryPath string, forceRefresh bool, cache VersionCache) (string, error) {
+	return detectVersionCached(ctx, filepath.Base(binaryPath), binaryPath, nil, forceRefresh, cache, DefaultVersionProbes())
+}
+
+// DetectVersionWithOptions detects the version of a binary as opts
+// configures. Set forceRefresh to true to bypass the cache.
+func DetectVersionWithOptions(ctx context.Context, binaryPath string, forceRefresh bool, opts VersionOptions) (string, error) {
+	cache, probes := opts.resolve()
+	return detectVersionCached(ctx, filepath.Base(binaryPath), binaryPath, nil, forceRefresh, cache, probes)
+}
+
+// detectToolVersion is detectVersionCached. Tests replace it to observe the
+// version detection workers.
+var detectToolVersion = detectVersionCached
+
+// detectVersionCached implements DetectVersionWithCache for the tool name
+// at binaryPath with the probe probes registers for it, running it with env
+// (nil inherits the current environment)
+func detectVersionCached(ctx context.Context, name, binaryPath string, env []string, forceRefresh bool, cache VersionCache, probes VersionProbes) (string, error) {
+	probe := probes.lookup(name)
+	key := versionCacheKey(binaryPath, probe)
+
 	// Check cache unless force refresh is requested
 	if !forceRefresh && cache != nil {
-		if version, ok := cache.Get(binaryPath); ok {
+		if version, ok := cache.Get(key); ok {
 			return version, nil
 		}
 	}
 
 	// Cache miss or expired - detect version
-	version, err := DetectVersion(ctx, binaryPath)
+	version, err := detectVersion(ctx, binaryPath, probe, env)
 	if err != nil {
 		return "", err
 	}
 
 	// Update cache
 	if cache != nil {
-		cache.Set(binaryPath, version)
+		cache.Set(key, version)
 	}
 
 	return version, nil
 }
 
 // DetectVersion detects the version of a binary by executing it
-// Tries --version flag first, then -v as fallback
+// Uses the built-in version probe for the binary's name if there is one,
+// then tries --version flag, then -v as fallback
 // This function does NOT use caching - use DetectVersionCached for cached lookups
 // Uses context with timeout to prevent hanging on misbehaving tools
 func DetectVersion(ctx context.Context, binaryPath string) (string, error) {
+	return detectVersion(ctx, binaryPath, DefaultVersionProbes().lookup(filepath.Base(binaryPath)), nil)
+}
+
+// detectVersion implements DetectVersion with the given probe (nil for
+// none), running the binary with env (nil inherits the current environment)
+func detectVersion(ctx context.Context, binaryPath string, probe *VersionProbe, env []string) (string, error) {
 	timeout := getVersionTimeout()
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	// Try --version first (most common)
+	// Try the tool's own rule first
+	if probe != nil {
+		cmd := exec.CommandContext(ctx, binaryPath, probe.Args...)
+		cmd.Env = env
+		output, err := cmd.CombinedOutput() // java prints its version on stderr
+		if err == nil {
+			version, err := probe.Extract(string(output))
+			if err == nil {
+				return version, nil
+			}
+		}
+	}
+
+	// Try --version (most common)
 	cmd := exec.CommandContext(ctx, binaryPath, "--version")
+	cmd.Env = env
 	output, err := cmd.CombinedOutput() // Capture both stdout and stderr
 	if err == nil {
 		version, err := ExtractVersion(string(output))
@@ -229,6 +382,7 @@ func DetectVersion(ctx context.Context, binaryPath string) (string, error) {
 
 	// Try -v as fallback
 	cmd = exec.CommandContext(ctx, binaryPath, "-v")
+	cmd.Env = env
 	output, err = cmd.CombinedOutput() // Capture both stdout and stderr
 	if err == nil {
 		version, err := ExtractVersion(string(output))
@@ -7,27 +7,24 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
 )
 
 func TestQueryActive(t *testing.T) {
-	// Setup mock binaries in test PATH
-	testPATH := SetupTestPATH(t, map[string]string{
+	t.Parallel()
+
+	// Setup mock binaries in the search path
+	binDir := SetupTestPATH(t, map[string]string{
 		"node":   "20.11.0",
 		"python": "3.12.1",
 		"go":     "1.22.0",
 	})
 
-	// Save original PATH
-	origPATH := os.Getenv("PATH")
-	defer os.Setenv("PATH", origPATH)
-
-	// Set test PATH
-	os.Setenv("PATH", testPATH)
-
 	// Test with tool names (including one that doesn't exist)
 	toolNames := []string{"node", "python", "go", "nonexistent"}
 
-	tools, err := QueryActive(context.Background(), toolNames, false)
+	tools, err := QueryActive(context.Background(), toolNames, false, binDir)
 	if err != nil {
 		t.Fatalf("QueryActive() error = %v", err)
 	}
@@ -72,6 +69,38 @@ func TestQueryActive_EmptyList(t *testing.T) {
 	}
 }
 
+// TestQueryActive_SearchPath tests that an explicit search path is used
+// instead of $PATH, in order
+func TestQueryActive_SearchPath(t *testing.T) {
+	t.Parallel()
+
+	first := SetupTestPATH(t, map[string]string{"node": "20.11.0"})
+	second := SetupTestPATH(t, map[string]string{"node": "18.19.0", "python": "3.12.1"})
+	notExecutable := t.TempDir()
+	if err := os.WriteFile(filepath.Join(notExecutable, "node"), []byte("#!/bin/sh\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	// $PATH is not consulted: "sh" is on every PATH but not in the search path
+	searchPath := []string{"", "relative/bin", notExecutable, first, second}
+	tools, err := QueryActiveWithCache(context.Background(), []string{"node", "python", "sh"}, false, NewVersionCache(), searchPath...)
+	if err != nil {
+		t.Fatalf("QueryActiveWithCache() error = %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, tool := range tools {
+		got[tool.Name] = tool.Version + " " + filepath.Dir(tool.Path)
+	}
+	want := map[string]string{
+		"node":   "20.11.0 " + first,
+		"python": "3.12.1 " + second,
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("QueryActiveWithCache() = %v, want %v", got, want)
+	}
+}
+
 func TestDetectVersion(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -177,6 +206,8 @@ exit 1
 }
 
 func TestQueryActive_SymlinkResolution(t *testing.T) {
+	t.Parallel()
+
 	tmpDir := t.TempDir()
 
 	// Create actual binary
@@ -193,13 +224,8 @@ func TestQueryActive_SymlinkResolution(t *testing.T) {
 		t.Fatalf("failed to create symlink: %v", err)
 	}
 
-	// Set PATH to symlink directory
-	origPATH := os.Getenv("PATH")
-	defer os.Setenv("PATH", origPATH)
-	os.Setenv("PATH", symlinkDir+":"+origPATH)
-
-	// Query active tools
-	tools, err := QueryActive(context.Background(), []string{"node"}, false)
+	// Query active tools in the symlink directory
+	tools, err := QueryActive(context.Background(), []string{"node"}, false, symlinkDir)
 	if err != nil {
 		t.Fatalf("QueryActive() error = %v", err)
 	}
@@ -318,8 +344,9 @@ func TestDetectVersionCached(t *testing.T) {
 }
 
 func TestDetectVersionCached_Expiry(t *testing.T) {
-	// Create a cache with very short TTL for testing expiry
-	cache := NewVersionCacheWithOptions(1*time.Millisecond, 100)
+	// Create a cache driven by a fake clock for deterministic expiry
+	clk := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	cache := NewVersionCacheWithOptions(time.Minute, 100).WithClock(clk)
 
 	tmpDir := t.TempDir()
 	mockPath := CreateMockBinary(t, tmpDir, "test-tool", "2.0.0")
@@ -327,8 +354,14 @@ func TestDetectVersionCached_Expiry(t *testing.T) {
 	// Populate cache
 	cache.Set(mockPath, "1.0.0") // Old version
 
-	// Wait for cache to expire
-	time.Sleep(5 * time.Millisecond)
+	// Still cached just before the TTL
+	clk.Advance(59 * time.Second)
+	if version, ok := cache.Get(mockPath); !ok || version != "1.0.0" {
+		t.Fatalf("cache.Get() = (%q, %v), want (\"1.0.0\", true) before TTL", version, ok)
+	}
+
+	// Advance past the TTL
+	clk.Advance(time.Second)
 
 	// Call should detect new version because cache is expired
 	version, err := DetectVersionWithCache(context.Background(), mockPath, false, cache)
@@ -341,23 +374,18 @@ func TestDetectVersionCached_Expiry(t *testing.T) {
 }
 
 func TestQueryActive_ForceRefresh(t *testing.T) {
-	// Setup mock binaries in test PATH
-	testPATH := SetupTestPATH(t, map[string]string{
+	t.Parallel()
+
+	// Setup mock binaries in the search path
+	binDir := SetupTestPATH(t, map[string]string{
 		"node": "20.11.0",
 	})
 
-	// Save original PATH
-	origPATH := os.Getenv("PATH")
-	defer os.Setenv("PATH", origPATH)
-
-	// Set test PATH
-	os.Setenv("PATH", testPATH)
-
 	// Create fresh cache for this test
 	cache := NewVersionCache()
 
 	// First call without force refresh
-	tools1, err := QueryActiveWithCache(context.Background(), []string{"node"}, false, cache)
+	tools1, err := QueryActiveWithCache(context.Background(), []string{"node"}, false, cache, binDir)
 	if err != nil {
 		t.Fatalf("QueryActiveWithCache() first call error = %v", err)
 	}
@@ -366,7 +394,7 @@ func TestQueryActive_ForceRefresh(t *testing.T) {
 	}
 
 	// Second call with force refresh
-	tools2, err := QueryActiveWithCache(context.Background(), []string{"node"}, true, cache)
+	tools2, err := QueryActiveWithCache(context.Background(), []string{"node"}, true, cache, binDir)
 	if err != nil {
 		t.Fatalf("QueryActiveWithCache() second call error = %v", err)
 	}
@@ -417,8 +445,9 @@ func TestCachePruning(t *testing.T) {
 }
 
 func TestCachePruning_ExpiredEntries(t *testing.T) {
-	// Create cache with very short TTL
-	cache := NewVersionCacheWithOptions(1*time.Millisecond, 100)
+	// Create cache driven by a fake clock
+	clk := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	cache := NewVersionCacheWithOptions(time.Minute, 100).WithClock(clk)
 
 	tmpDir := t.TempDir()
 
@@ -428,8 +457,8 @@ func TestCachePruning_ExpiredEntries(t *testing.T) {
 		cache.Set(fakePath, "1.0.0")
 	}
 
-	// Wait for entries to expire
-	time.Sleep(5 * time.Millisecond)
+	// Expire the entries
+	clk.Advance(2 * time.Minute)
 
 	// Add a new tool to potentially trigger pruning
 	newPath := filepath.Join(tmpDir, "new-tool")
@@ -2,14 +2,18 @@ package drift
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
-	"time"
 
+	"github.com/ZebulonRouseFrantzich/zerb/internal/binary"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
 	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/fsutil"
 )
 
 var (
@@ -41,13 +45,31 @@ func validateVersion(version string) error {
 	return nil
 }
 
+// ActionOptions controls how ApplyDriftAction applies an action.
+type ActionOptions struct {
+	// DryRun validates the action and writes what it would do to Out,
+	// without changing the config or running tool commands
+	DryRun bool
+	// Out receives the dry-run output; nil discards it
+	Out io.Writer
+}
+
 // ApplyDriftAction applies a drift resolution action
-func ApplyDriftAction(ctx context.Context, result DriftResult, action DriftAction, configPath, zerbDir, miseBinary string) error {
+func ApplyDriftAction(ctx context.Context, result DriftResult, action DriftAction, configPath, zerbDir, miseBinary string, opts ActionOptions) error {
+	out := opts.Out
+	if out == nil {
+		out = io.Discard
+	}
+
 	switch action {
 	case ActionAdopt:
+		if opts.DryRun {
+			fmt.Fprintf(out, "Would adopt %s into the config\n", result.Tool)
+			return nil
+		}
 		return applyAdopt(result, configPath, zerbDir)
 	case ActionRevert:
-		return applyRevert(ctx, result, miseBinary, zerbDir)
+		return applyRevert(ctx, result, miseBinary, zerbDir, opts.DryRun, out)
 	case ActionSkip:
 		return nil // No action
 	default:
@@ -57,127 +79,175 @@ func ApplyDriftAction(ctx context.Context, result DriftResult, action DriftActio
 
 // applyAdopt updates baseline to match environment
 func applyAdopt(result DriftResult, configPath string, zerbDir string) error {
+	_, err := adoptResults([]DriftResult{result}, configPath, zerbDir, clock.Real{})
+	return err
+}
+
+// adoptResults applies the adopt action for every result to the baseline and
+// writes the outcome as a single new timestamped config. It returns the new
+// config filename. The clock determines the snapshot timestamp.
+func adoptResults(results []DriftResult, configPath string, zerbDir string, clk clock.Clock) (string, error) {
 	// Read current config
 	content, err := os.ReadFile(configPath)
 	if err != nil {
-		return fmt.Errorf("read config: %w", err)
+		return "", fmt.Errorf("read config: %w", err)
 	}
 
 	// Parse config
 	parser := config.NewParser(nil)
 	cfg, err := parser.ParseString(context.Background(), string(content))
 	if err != nil {
-		return fmt.Errorf("parse config: %w", err)
+		return "", fmt.Errorf("parse config: %w", err)
 	}
 
-	// Update tools array based on drift type
-	cfg.Tools = updateToolsArray(cfg.Tools, result, ActionAdopt)
+	// Update the tools list each tool is declared by, based on drift type
+	active := config.ProfilesFromEnv()
+	for _, result := range results {
+		adoptIntoConfig(cfg, active, result)
+	}
 
 	// Generate new config
-	generator := config.NewGenerator()
+	generator := config.NewGenerator().WithClock(clk)
 	luaCode, err := generator.Generate(context.Background(), cfg)
 	if err != nil {
-		return fmt.Errorf("generate config: %w", err)
+		return "", fmt.Errorf("generate config: %w", err)
 	}
 
 	// Create timestamped config
 	// Format: zerb.TIMESTAMP.lua (ending in .lua for editor syntax highlighting)
-	timestamp := time.Now().UTC().Format("20060102T150405.000Z")
+	timestamp := clk.Now().UTC().Format("20060102T150405.000Z")
 	configsDir := filepath.Join(zerbDir, "configs")
 	newConfigFilename := fmt.Sprintf("zerb.%s.lua", timestamp)
 	newConfigPath := filepath.Join(configsDir, newConfigFilename)
 
 	// Write new config (0600 for security - may contain sensitive data)
-	if err := os.WriteFile(newConfigPath, []byte(luaCode), 0600); err != nil {
-		return fmt.Errorf("write config: %w", err)
+	if err := fsutil.WriteFileAtomic(newConfigPath, []byte(luaCode), 0600); err != nil {
+		return "", fmt.Errorf("write config: %w", err)
 	}
 
 	// Update .zerb-active marker (0600 for consistency)
 	markerPath := filepath.Join(zerbDir, ".zerb-active")
-	if err := os.WriteFile(markerPath, []byte(newConfigFilename), 0600); err != nil {
-		return fmt.Errorf("update marker: %w", err)
+	if err := fsutil.WriteFileAtomic(markerPath, []byte(newConfigFilename), 0600); err != nil {
+		return "", fmt.Errorf("update marker: %w", err)
 	}
 
 	// Update symlink
 	symlinkPath := filepath.Join(zerbDir, "zerb.active.lua")
 	if err := os.Remove(symlinkPath); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("remove old symlink: %w", err)
+		return "", fmt.Errorf("remove old symlink: %w", err)
 	}
 	symlinkTarget := filepath.Join("configs", newConfigFilename)
 	if err := os.Symlink(symlinkTarget, symlinkPath); err != nil {
-		return fmt.Errorf("update symlink: %w", err)
+		return "", fmt.Errorf("update symlink: %w", err)
+	}
+
+	return newConfigFilename, nil
+}
+
+// applyRevert restores environment to match baseline. With dryRun, the
+// drift is validated and the tool command is written to out instead of run.
+func applyRevert(ctx context.Context, result DriftResult, miseBinary string, zerbDir string, dryRun bool, out io.Writer) error {
+	cmd, ok, err := revertCommand(result)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	if dryRun {
+		fmt.Fprintf(out, "Would run tool manager command: %s\n", cmd)
+		return nil
+	}
+
+	if err := executeMiseInstallOrUninstall(ctx, miseBinary, zerbDir, cmd.Operation, cmd.ToolSpec); err != nil {
+		return fmt.Errorf("%s: %w", cmd, err)
 	}
 
 	return nil
 }
 
-// applyRevert restores environment to match baseline
-func applyRevert(ctx context.Context, result DriftResult, miseBinary string, zerbDir string) error {
+// revertCommand returns the tool command that reverts a drift. ok is false
+// when the drift type needs no command.
+func revertCommand(result DriftResult) (cmd ToolCommand, ok bool, err error) {
 	// Validate tool name before any operations
 	if err := validateToolName(result.Tool); err != nil {
-		return fmt.Errorf("invalid tool name: %w", err)
+		return ToolCommand{}, false, fmt.Errorf("invalid tool name: %w", err)
 	}
 
 	switch result.DriftType {
-	case DriftExternalOverride, DriftVersionMismatch:
+	case DriftExternalOverride, DriftVersionMismatch, DriftMissing:
 		// Validate version
 		if err := validateVersion(result.BaselineVersion); err != nil {
-			return fmt.Errorf("invalid baseline version: %w", err)
-		}
-		// Reinstall correct version via mise
-		toolSpec := fmt.Sprintf("%s@%s", result.Tool, result.BaselineVersion)
-		if err := executeMiseInstallOrUninstall(ctx, miseBinary, zerbDir, "install", toolSpec); err != nil {
-			return fmt.Errorf("install %s: %w", toolSpec, err)
-		}
-
-	case DriftMissing:
-		// Validate version
-		if err := validateVersion(result.BaselineVersion); err != nil {
-			return fmt.Errorf("invalid baseline version: %w", err)
-		}
-		// Install missing tool
-		toolSpec := fmt.Sprintf("%s@%s", result.Tool, result.BaselineVersion)
-		if err := executeMiseInstallOrUninstall(ctx, miseBinary, zerbDir, "install", toolSpec); err != nil {
-			return fmt.Errorf("install %s: %w", toolSpec, err)
+			return ToolCommand{}, false, fmt.Errorf("invalid baseline version: %w", err)
 		}
+		// Install the baseline version (reinstalling over an override or mismatch)
+		return ToolCommand{Operation: "install", ToolSpec: fmt.Sprintf("%s@%s", result.Tool, result.BaselineVersion)}, true, nil
 
 	case DriftExtra:
 		// Validate managed version before uninstall
 		if result.ManagedVersion == "" {
-			return fmt.Errorf("cannot uninstall tool %s: managed version unknown", result.Tool)
+			return ToolCommand{}, false, fmt.Errorf("cannot uninstall tool %s: managed version unknown", result.Tool)
 		}
 		// Uninstall extra tool with version spec (important when multiple versions installed)
-		toolSpec := fmt.Sprintf("%s@%s", result.Tool, result.ManagedVersion)
-		if err := executeMiseInstallOrUninstall(ctx, miseBinary, zerbDir, "uninstall", toolSpec); err != nil {
-			return fmt.Errorf("uninstall %s: %w", toolSpec, err)
-		}
+		return ToolCommand{Operation: "uninstall", ToolSpec: fmt.Sprintf("%s@%s", result.Tool, result.ManagedVersion)}, true, nil
+
+	case DriftInactiveProfile:
+		// Uninstalling would break the profile on the next machine (or day)
+		// it is active; the profile has to be edited instead
+		return ToolCommand{}, false, fmt.Errorf("cannot uninstall tool %s: it is declared by a profile not active on this machine; remove it from the profile instead", result.Tool)
 
 	case DriftManagedButNotActive:
 		// This is typically a PATH issue, not something we can fix with mise
 		// But we can try re-activating the shell or do nothing
 		// For now, do nothing (this should be handled by the user)
-		return fmt.Errorf("drift type %s requires manual PATH investigation", result.DriftType)
+		return ToolCommand{}, false, fmt.Errorf("drift type %s requires manual PATH investigation", result.DriftType)
 
 	case DriftVersionUnknown:
 		// Reinstall to hopefully fix version detection
-		toolSpec := fmt.Sprintf("%s@%s", result.Tool, result.BaselineVersion)
-		if err := executeMiseInstallOrUninstall(ctx, miseBinary, zerbDir, "install", toolSpec); err != nil {
-			return fmt.Errorf("install %s: %w", toolSpec, err)
-		}
+		return ToolCommand{Operation: "install", ToolSpec: fmt.Sprintf("%s@%s", result.Tool, result.BaselineVersion)}, true, nil
 	}
 
-	return nil
+	return ToolCommand{}, false, nil
 }
 
 // executeMiseInstallOrUninstall is a wrapper around executeMiseCommand that discards output
 func executeMiseInstallOrUninstall(ctx context.Context, miseBinary string, zerbDir string, args ...string) error {
 	_, err := executeMiseCommand(ctx, miseBinary, zerbDir, args...)
+	if errors.Is(err, binary.ErrBinaryMissing) {
+		return err
+	}
 	if err != nil {
 		return fmt.Errorf("mise command failed: %w", err)
 	}
 	return nil
 }
 
+// adoptIntoConfig applies the adopt action for result to the tools list
+// ResolveTools takes the tool's entry from with the active profiles: the
+// last active profile declaring the tool, else the base tools. Editing the
+// base entry of a tool a profile overrides would change other machines
+// and not this one. Tools declared nowhere are added to the base tools.
+func adoptIntoConfig(cfg *config.Config, active []string, result DriftResult) {
+	var profiles []string
+	seen := make(map[string]bool, len(active))
+	for _, name := range active {
+		if !seen[name] {
+			seen[name] = true
+			profiles = append(profiles, name)
+		}
+	}
+
+	for i := len(profiles) - 1; i >= 0; i-- {
+		tools, ok := cfg.Profiles[profiles[i]]
+		if ok && containsTool(tools, result.Tool) {
+			cfg.Profiles[profiles[i]] = updateToolsArray(tools, result, ActionAdopt)
+			return
+		}
+	}
+	cfg.Tools = updateToolsArray(cfg.Tools, result, ActionAdopt)
+}
+
 // updateToolsArray updates the tools array based on drift type and action
 func updateToolsArray(tools []string, result DriftResult, action DriftAction) []string {
 	if action != ActionAdopt {
@@ -195,7 +265,10 @@ func updateToolsArray(tools []string, result DriftResult, action DriftAction) []
 		return updateToolVersion(tools, result.Tool, result.ActiveVersion)
 
 	case DriftExtra:
-		// Add tool to baseline
+		// Add tool to baseline, unless an earlier adoption already did
+		if containsTool(tools, result.Tool) {
+			return tools
+		}
 		toolSpec := fmt.Sprintf("%s@%s", result.Tool, result.ManagedVersion)
 		return append(tools, toolSpec)
 
@@ -203,6 +276,11 @@ func updateToolsArray(tools []string, result DriftResult, action DriftAction) []
 		// Remove from baseline (user decided not to install)
 		return removeToolFromList(tools, result.Tool)
 
+	case DriftInactiveProfile:
+		// Already declared by its profile; adding it to the base tools
+		// would install it on every machine
+		return tools
+
 	case DriftManagedButNotActive:
 		// PATH issue - optionally remove from baseline
 		return removeToolFromList(tools, result.Tool)
@@ -215,6 +293,17 @@ func updateToolsArray(tools []string, result DriftResult, action DriftAction) []
 	return tools
 }
 
+// containsTool reports whether the tools list declares toolName
+func containsTool(tools []string, toolName string) bool {
+	for _, t := range tools {
+		spec, err := ParseToolSpec(t)
+		if err == nil && spec.Name == toolKey(toolName) {
+			return true
+		}
+	}
+	return false
+}
+
 // removeToolFromList removes a tool from the tools list
 func removeToolFromList(tools []string, toolName string) []string {
 	var result []string
@@ -225,7 +314,7 @@ func removeToolFromList(tools []string, toolName string) []string {
 			result = append(result, t)
 			continue
 		}
-		if spec.Name != toolName {
+		if spec.Name != toolKey(toolName) {
 			result = append(result, t)
 		}
 	}
@@ -244,7 +333,7 @@ func updateToolVersion(tools []string, toolName string, newVersion string) []str
 			continue
 		}
 
-		if spec.Name == toolName {
+		if spec.Name == toolKey(toolName) {
 			// Reconstruct tool spec with new version
 			var newSpec string
 			if spec.Backend != "" {
@@ -1,10 +1,13 @@
 package drift
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -163,6 +166,17 @@ func TestUpdateToolsArray(t *testing.T) {
 			action: ActionAdopt,
 			want:   []string{"node@20.15.0"},
 		},
+		{
+			name:  "Inactive profile tool - adopt (unchanged)",
+			tools: []string{"node@20.11.0"},
+			result: DriftResult{
+				Tool:           "go",
+				DriftType:      DriftInactiveProfile,
+				ManagedVersion: "1.22.0",
+			},
+			action: ActionAdopt,
+			want:   []string{"node@20.11.0"},
+		},
 		{
 			name:  "Extra tool - adopt (add)",
 			tools: []string{"node@20.11.0"},
@@ -174,6 +188,17 @@ func TestUpdateToolsArray(t *testing.T) {
 			action: ActionAdopt,
 			want:   []string{"node@20.11.0", "rust@1.75.0"},
 		},
+		{
+			name:  "Extra tool already adopted - no duplicate",
+			tools: []string{"node@20.11.0", "rust@1.75.0"},
+			result: DriftResult{
+				Tool:           "rust",
+				DriftType:      DriftExtra,
+				ManagedVersion: "1.75.0",
+			},
+			action: ActionAdopt,
+			want:   []string{"node@20.11.0", "rust@1.75.0"},
+		},
 		{
 			name:  "Missing tool - adopt (remove)",
 			tools: []string{"python@3.12.1", "node@20.11.0"},
@@ -352,6 +377,143 @@ func TestApplyAdopt_Integration(t *testing.T) {
 	}
 }
 
+func TestAdoptIntoConfig(t *testing.T) {
+	newConfig := func() *config.Config {
+		return &config.Config{
+			Tools: []string{"node@20.11.0", "python@3.12.1"},
+			Profiles: map[string][]string{
+				"work": {"node@18.19.0", "go@1.22.0"},
+				"home": {"node@22.1.0"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		active       []string
+		result       DriftResult
+		wantTools    []string
+		wantProfiles map[string][]string
+	}{
+		{
+			name:      "base tool",
+			active:    []string{"work"},
+			result:    DriftResult{Tool: "python", DriftType: DriftVersionMismatch, ActiveVersion: "3.12.2"},
+			wantTools: []string{"node@20.11.0", "python@3.12.2"},
+			wantProfiles: map[string][]string{
+				"work": {"node@18.19.0", "go@1.22.0"},
+				"home": {"node@22.1.0"},
+			},
+		},
+		{
+			name:      "tool from the active profile",
+			active:    []string{"work"},
+			result:    DriftResult{Tool: "go", DriftType: DriftVersionMismatch, ActiveVersion: "1.22.5"},
+			wantTools: []string{"node@20.11.0", "python@3.12.1"},
+			wantProfiles: map[string][]string{
+				"work": {"node@18.19.0", "go@1.22.5"},
+				"home": {"node@22.1.0"},
+			},
+		},
+		{
+			name:      "profile overriding a base tool",
+			active:    []string{"work"},
+			result:    DriftResult{Tool: "node", DriftType: DriftVersionMismatch, ActiveVersion: "18.20.0"},
+			wantTools: []string{"node@20.11.0", "python@3.12.1"},
+			wantProfiles: map[string][]string{
+				"work": {"node@18.20.0", "go@1.22.0"},
+				"home": {"node@22.1.0"},
+			},
+		},
+		{
+			name:      "last active profile wins",
+			active:    []string{"work", "home", "work"},
+			result:    DriftResult{Tool: "node", DriftType: DriftMissing},
+			wantTools: []string{"node@20.11.0", "python@3.12.1"},
+			wantProfiles: map[string][]string{
+				"work": {"node@18.19.0", "go@1.22.0"},
+				"home": nil,
+			},
+		},
+		{
+			name:      "inactive profile is left alone",
+			active:    nil,
+			result:    DriftResult{Tool: "node", DriftType: DriftVersionMismatch, ActiveVersion: "20.15.0"},
+			wantTools: []string{"node@20.15.0", "python@3.12.1"},
+			wantProfiles: map[string][]string{
+				"work": {"node@18.19.0", "go@1.22.0"},
+				"home": {"node@22.1.0"},
+			},
+		},
+		{
+			name:      "extra goes to the base tools",
+			active:    []string{"work"},
+			result:    DriftResult{Tool: "ripgrep", DriftType: DriftExtra, ManagedVersion: "14.1.0"},
+			wantTools: []string{"node@20.11.0", "python@3.12.1", "ripgrep@14.1.0"},
+			wantProfiles: map[string][]string{
+				"work": {"node@18.19.0", "go@1.22.0"},
+				"home": {"node@22.1.0"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newConfig()
+			adoptIntoConfig(cfg, tt.active, tt.result)
+			if !reflect.DeepEqual(cfg.Tools, tt.wantTools) {
+				t.Errorf("Tools = %v, want %v", cfg.Tools, tt.wantTools)
+			}
+			if !reflect.DeepEqual(cfg.Profiles, tt.wantProfiles) {
+				t.Errorf("Profiles = %v, want %v", cfg.Profiles, tt.wantProfiles)
+			}
+		})
+	}
+}
+
+func TestApplyAdopt_ProfileTool(t *testing.T) {
+	t.Setenv(config.EnvProfiles, "work")
+	tmpDir := t.TempDir()
+	configsDir := filepath.Join(tmpDir, "configs")
+	if err := os.MkdirAll(configsDir, 0755); err != nil {
+		t.Fatalf("failed to create configs dir: %v", err)
+	}
+
+	initialConfig := `zerb = {
+  tools = { "node@20.11.0" },
+  profiles = {
+    work = { "node@18.19.0", "go@1.22.0" },
+  },
+}`
+	initialConfigPath := filepath.Join(configsDir, "zerb.20250113T120000.000Z.lua")
+	if err := os.WriteFile(initialConfigPath, []byte(initialConfig), 0644); err != nil {
+		t.Fatalf("failed to write initial config: %v", err)
+	}
+
+	result := DriftResult{Tool: "node", DriftType: DriftVersionMismatch, ActiveVersion: "18.20.0"}
+	if err := applyAdopt(result, initialConfigPath, tmpDir); err != nil {
+		t.Fatalf("applyAdopt() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "zerb.active.lua"))
+	if err != nil {
+		t.Fatalf("failed to read new config: %v", err)
+	}
+	cfg, err := config.NewParser(nil).ParseString(context.Background(), string(content))
+	if err != nil {
+		t.Fatalf("failed to parse new config: %v", err)
+	}
+
+	// The profile's entry is the one this machine uses; other machines
+	// keep the base version
+	if want := []string{"node@20.11.0"}; !reflect.DeepEqual(cfg.Tools, want) {
+		t.Errorf("Tools = %v, want %v", cfg.Tools, want)
+	}
+	if want := []string{"node@18.20.0", "go@1.22.0"}; !reflect.DeepEqual(cfg.Profiles["work"], want) {
+		t.Errorf("work profile = %v, want %v", cfg.Profiles["work"], want)
+	}
+}
+
 func TestApplyDriftAction(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -434,7 +596,7 @@ exit 0
 			misePath := filepath.Join(binDir, "mise")
 			os.WriteFile(misePath, []byte(miseScript), 0755)
 
-			err := ApplyDriftAction(context.Background(), tt.result, tt.action, configPath, tmpDir, misePath)
+			err := ApplyDriftAction(context.Background(), tt.result, tt.action, configPath, tmpDir, misePath, ActionOptions{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ApplyDriftAction() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -485,6 +647,17 @@ func TestApplyRevert(t *testing.T) {
 			wantErr:    true,
 			errPattern: "manual PATH investigation",
 		},
+		{
+			name: "DriftInactiveProfile - never uninstalled",
+			result: DriftResult{
+				Tool:            "go",
+				DriftType:       DriftInactiveProfile,
+				BaselineVersion: "1.22.0",
+				ManagedVersion:  "1.22.0",
+			},
+			wantErr:    true,
+			errPattern: "declared by a profile not active",
+		},
 		{
 			name: "DriftExternalOverride - install baseline version",
 			result: DriftResult{
@@ -549,7 +722,7 @@ exit 0
 			misePath := filepath.Join(binDir, "mise")
 			os.WriteFile(misePath, []byte(miseScript), 0755)
 
-			err := applyRevert(context.Background(), tt.result, misePath, tmpDir)
+			err := applyRevert(context.Background(), tt.result, misePath, tmpDir, false, io.Discard)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("applyRevert() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -563,6 +736,75 @@ exit 0
 	}
 }
 
+func TestApplyDriftAction_DryRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		action  DriftAction
+		result  DriftResult
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "install baseline version",
+			action: ActionRevert,
+			result: DriftResult{Tool: "node", DriftType: DriftVersionMismatch, BaselineVersion: "20.11.0", ActiveVersion: "20.15.0"},
+			want:   "Would run tool manager command: install node@20.11.0\n",
+		},
+		{
+			name:   "uninstall extra tool",
+			action: ActionRevert,
+			result: DriftResult{Tool: "ripgrep", DriftType: DriftExtra, ManagedVersion: "14.1.0"},
+			want:   "Would run tool manager command: uninstall ripgrep@14.1.0\n",
+		},
+		{
+			name:   "adopt",
+			action: ActionAdopt,
+			result: DriftResult{Tool: "node", DriftType: DriftVersionMismatch, ActiveVersion: "20.15.0"},
+			want:   "Would adopt node into the config\n",
+		},
+		{
+			name:    "shell metacharacters",
+			action:  ActionRevert,
+			result:  DriftResult{Tool: "node", DriftType: DriftMissing, BaselineVersion: "20.11.0; rm -rf /"},
+			wantErr: "invalid baseline version",
+		},
+		{
+			name:    "managed but not active",
+			action:  ActionRevert,
+			result:  DriftResult{Tool: "node", DriftType: DriftManagedButNotActive, BaselineVersion: "20.11.0"},
+			wantErr: "manual PATH investigation",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Neither the tool manager nor the config exist, so anything
+			// but a dry run fails
+			tmpDir := t.TempDir()
+			misePath := filepath.Join(tmpDir, "bin", "mise")
+			configPath := filepath.Join(tmpDir, "zerb.active.lua")
+
+			var out bytes.Buffer
+			err := ApplyDriftAction(context.Background(), tt.result, tt.action, configPath, tmpDir, misePath, ActionOptions{DryRun: true, Out: &out})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ApplyDriftAction() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyDriftAction() error = %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("output = %q, want %q", out.String(), tt.want)
+			}
+			if entries, _ := os.ReadDir(tmpDir); len(entries) != 0 {
+				t.Errorf("dry run created %d files", len(entries))
+			}
+		})
+	}
+}
+
 func TestExecuteMiseInstallOrUninstall(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -8,8 +8,28 @@ import (
 	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
 )
 
-// QueryBaseline parses the active config and returns declared tools
+// QueryBaseline parses the active config and returns declared tools,
+// including those of the profiles listed in ZERB_PROFILES
 func QueryBaseline(ctx context.Context, configPath string) ([]ToolSpec, error) {
+	return queryTools(ctx, configPath, func(cfg *config.Config) ([]string, error) {
+		// Merge in the tools of the profiles active on this machine
+		return cfg.ResolveTools(config.ProfilesFromEnv())
+	})
+}
+
+// QueryDeclared parses the active config and returns every tool it could
+// declare: the base tools and those of all profiles, active or not. Tools
+// only in this list are expected on machines using another profile, so
+// DetectDrift does not report them as extras.
+func QueryDeclared(ctx context.Context, configPath string) ([]ToolSpec, error) {
+	return queryTools(ctx, configPath, func(cfg *config.Config) ([]string, error) {
+		return cfg.ResolveTools(cfg.ProfileNames())
+	})
+}
+
+// queryTools parses the config at configPath and returns the tools resolve
+// selects from it as ToolSpecs
+func queryTools(ctx context.Context, configPath string, resolve func(*config.Config) ([]string, error)) ([]ToolSpec, error) {
 	// Check context before reading file
 	if err := ctx.Err(); err != nil {
 		return nil, fmt.Errorf("operation cancelled: %w", err)
@@ -28,9 +48,14 @@ func QueryBaseline(ctx context.Context, configPath string) ([]ToolSpec, error) {
 		return nil, fmt.Errorf("parse config: %w", err)
 	}
 
+	tools, err := resolve(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("resolve profiles: %w", err)
+	}
+
 	// Convert tool strings to ToolSpecs
-	specs := make([]ToolSpec, 0, len(cfg.Tools))
-	for _, toolStr := range cfg.Tools {
+	specs := make([]ToolSpec, 0, len(tools))
+	for _, toolStr := range tools {
 		spec, err := ParseToolSpec(toolStr)
 		if err != nil {
 			return nil, fmt.Errorf("parse tool spec %q: %w", toolStr, err)
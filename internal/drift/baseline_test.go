@@ -4,7 +4,10 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
 )
 
 func TestQueryBaseline(t *testing.T) {
@@ -107,6 +110,97 @@ func TestQueryBaseline(t *testing.T) {
 	}
 }
 
+func TestQueryBaseline_Profiles(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "zerb.lua")
+	content := `zerb = {
+		tools = { "node@20.11.0" },
+		profiles = {
+			work = { "node@18.19.0", "go@1.22.0" },
+			home = { "python@3.12.1" },
+		},
+	}`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		profiles string
+		want     []ToolSpec
+		wantErr  bool
+	}{
+		{
+			name: "No active profiles",
+			want: []ToolSpec{{Name: "node", Version: "20.11.0"}},
+		},
+		{
+			name:     "Work profile overrides base version",
+			profiles: "work",
+			want: []ToolSpec{
+				{Name: "node", Version: "18.19.0"},
+				{Name: "go", Version: "1.22.0"},
+			},
+		},
+		{
+			name:     "Unknown profile",
+			profiles: "gaming",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(config.EnvProfiles, tt.profiles)
+
+			got, err := QueryBaseline(context.Background(), configPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("QueryBaseline() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("QueryBaseline() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("QueryBaseline()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestQueryDeclared(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "zerb.lua")
+	content := `zerb = {
+		tools = { "node@20.11.0" },
+		profiles = {
+			work = { "go@1.22.0" },
+			home = { "python@3.12.1" },
+		},
+	}`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+	// Active profiles do not matter
+	t.Setenv(config.EnvProfiles, "work")
+
+	got, err := QueryDeclared(context.Background(), configPath)
+	if err != nil {
+		t.Fatalf("QueryDeclared() error = %v", err)
+	}
+	want := []ToolSpec{
+		{Name: "node", Version: "20.11.0"},
+		{Name: "python", Version: "3.12.1"},
+		{Name: "go", Version: "1.22.0"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("QueryDeclared() = %+v, want %+v", got, want)
+	}
+}
+
 func TestQueryBaseline_FileErrors(t *testing.T) {
 	tests := []struct {
 		name    string
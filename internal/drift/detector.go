@@ -4,30 +4,54 @@ package drift
 // and returns drift results for each tool.
 //
 // The algorithm:
-//  1. Build lookup maps for managed and active tools (O(1) access)
+//  1. Match managed tools to baseline tools by backend and name, and
+//     build a lookup map for active tools (O(1) access)
 //  2. Iterate through baseline tools, classify each drift, remove from maps
-//  3. Iterate through remaining managed tools (extras not in baseline)
+//  3. Iterate through unmatched managed tools: those declared by an
+//     inactive profile, then extras not declared at all
 //  4. Return all drift results
 //
+// A managed tool whose ID is known ("cargo:ripgrep") matches the baseline
+// tool with that backend and name even when the tool manager reports a
+// different binary name ("rg"); see specIndex.lookup.
+//
 // Parameters:
-//   - baseline: Tools declared in zerb.lua configuration
+//   - baseline: Tools declared in zerb.lua configuration for this machine
+//     (base tools and active profiles, from QueryBaseline)
+//   - declared: Every tool the configuration could declare, including
+//     inactive profiles (from QueryDeclared); nil if there are no profiles
 //   - managed: Tools installed by ZERB (from QueryManaged)
 //   - active: Tools found in PATH (from QueryActive)
 //   - zerbDir: ZERB directory path (e.g., ~/.config/zerb) for path detection
 //
 // Returns: Slice of DriftResult, one per tool (baseline tools + extras)
-func DetectDrift(baseline []ToolSpec, managed []Tool, active []Tool, zerbDir string) []DriftResult {
+func DetectDrift(baseline []ToolSpec, declared []ToolSpec, managed []Tool, active []Tool, zerbDir string) []DriftResult {
 	var results []DriftResult
 
-	// Build lookup maps for O(1) access
+	// Match managed tools to baseline tools; the rest are extras
+	baselineIndex := newSpecIndex(baseline)
 	managedMap := make(map[string]Tool)
+	var unmatched []Tool
+	seen := make(map[string]bool)
 	for _, t := range managed {
-		managedMap[t.Name] = t
+		if spec, ok := baselineIndex.lookup(t); ok {
+			managedMap[specKey(spec)] = t
+			continue
+		}
+		// Report each extra tool once
+		key := t.ID
+		if key == "" {
+			key = toolKey(t.Name)
+		}
+		if !seen[key] {
+			seen[key] = true
+			unmatched = append(unmatched, t)
+		}
 	}
 
 	activeMap := make(map[string]Tool)
 	for _, t := range active {
-		activeMap[t.Name] = t
+		activeMap[toolKey(t.Name)] = t
 	}
 
 	// Process each baseline tool
@@ -38,7 +62,7 @@ func DetectDrift(baseline []ToolSpec, managed []Tool, active []Tool, zerbDir str
 		}
 
 		// Look up tool in managed and active maps
-		managedTool, hasManaged := managedMap[spec.Name]
+		managedTool, hasManaged := managedMap[specKey(spec)]
 		activeTool, hasActive := activeMap[spec.Name]
 
 		// Populate version info
@@ -49,30 +73,44 @@ func DetectDrift(baseline []ToolSpec, managed []Tool, active []Tool, zerbDir str
 		if hasActive {
 			result.ActiveVersion = activeTool.Version
 			result.ActivePath = activeTool.Path
+			result.AllPaths = activeTool.AllPaths
 		}
 
 		// Classify drift type
 		result.DriftType = classifyDrift(spec, managedTool, hasManaged, activeTool, hasActive, zerbDir)
 
+		// Explain an override by where ZERB's copy is in PATH
+		if result.DriftType == DriftExternalOverride {
+			result.PathOrder = classifyPathOrder(activeTool, zerbDir)
+		}
+
 		results = append(results, result)
 
-		// Remove from maps to detect extras later
-		delete(managedMap, spec.Name)
+		// Remove from active map to detect extras later
 		delete(activeMap, spec.Name)
 	}
 
+	// Tools of inactive profiles are expected to be installed; never
+	// report them as extras, which invites uninstalling them
+	declaredIndex := newSpecIndex(declared)
+
 	// Process extra tools (in managed but not in baseline)
-	for name, tool := range managedMap {
+	for _, tool := range unmatched {
 		result := DriftResult{
-			Tool:           name,
+			Tool:           tool.Name,
 			DriftType:      DriftExtra,
 			ManagedVersion: tool.Version,
 		}
+		if spec, ok := declaredIndex.lookup(tool); ok {
+			result.DriftType = DriftInactiveProfile
+			result.BaselineVersion = spec.Version
+		}
 
 		// Check if also in active (extra might not be in PATH)
-		if activeTool, exists := activeMap[name]; exists {
+		if activeTool, exists := activeMap[toolKey(tool.Name)]; exists {
 			result.ActiveVersion = activeTool.Version
 			result.ActivePath = activeTool.Path
+			result.AllPaths = activeTool.AllPaths
 		}
 
 		results = append(results, result)
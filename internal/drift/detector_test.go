@@ -18,6 +18,7 @@ func TestDetectDrift(t *testing.T) {
 	tests := []struct {
 		name     string
 		baseline []ToolSpec
+		declared []ToolSpec
 		managed  []Tool
 		active   []Tool
 		zerbDir  string
@@ -296,11 +297,96 @@ func TestDetectDrift(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:     "Tool of an inactive profile",
+			baseline: []ToolSpec{},
+			declared: []ToolSpec{
+				{Name: "go", Version: "1.22.0"},
+			},
+			managed: []Tool{
+				{Name: "go", Version: "1.22.0", Path: "/home/.config/zerb/installs/go/1.22.0/bin/go"},
+			},
+			active:  []Tool{},
+			zerbDir: "/home/.config/zerb",
+			want: []DriftResult{
+				{
+					Tool:            "go",
+					DriftType:       DriftInactiveProfile,
+					BaselineVersion: "1.22.0",
+					ManagedVersion:  "1.22.0",
+				},
+			},
+		},
+		{
+			name: "Backend tool reported by binary name",
+			baseline: []ToolSpec{
+				{Backend: "cargo", Name: "ripgrep", Version: "14.1.0"},
+			},
+			managed: []Tool{
+				{Name: "rg", Version: "14.1.0", Path: "/home/.config/zerb/installs/cargo-ripgrep/14.1.0", ID: "cargo:ripgrep", Backend: "cargo"},
+			},
+			active: []Tool{
+				{Name: "ripgrep", Version: "14.1.0", Path: "/home/.config/zerb/installs/cargo-ripgrep/14.1.0/bin/rg"},
+			},
+			zerbDir: "/home/.config/zerb",
+			want: []DriftResult{
+				{
+					Tool:            "ripgrep",
+					DriftType:       DriftOK,
+					BaselineVersion: "14.1.0",
+					ManagedVersion:  "14.1.0",
+					ActiveVersion:   "14.1.0",
+					ActivePath:      "/home/.config/zerb/installs/cargo-ripgrep/14.1.0/bin/rg",
+				},
+			},
+		},
+		{
+			name: "Backend-less baseline tool matches any backend",
+			baseline: []ToolSpec{
+				{Name: "ripgrep", Version: "14.1.0"},
+			},
+			managed: []Tool{
+				{Name: "cargo:ripgrep", Version: "14.1.0", ID: "cargo:ripgrep", Backend: "cargo"},
+			},
+			active:  []Tool{},
+			zerbDir: "/home/.config/zerb",
+			want: []DriftResult{
+				{
+					Tool:            "ripgrep",
+					DriftType:       DriftManagedButNotActive,
+					BaselineVersion: "14.1.0",
+					ManagedVersion:  "14.1.0",
+				},
+			},
+		},
+		{
+			name: "Same name from another backend",
+			baseline: []ToolSpec{
+				{Backend: "cargo", Name: "ripgrep", Version: "14.1.0"},
+			},
+			managed: []Tool{
+				{Name: "aqua:BurntSushi/ripgrep", Version: "14.1.0", ID: "aqua:BurntSushi/ripgrep", Backend: "aqua"},
+			},
+			active:  []Tool{},
+			zerbDir: "/home/.config/zerb",
+			want: []DriftResult{
+				{
+					Tool:            "ripgrep",
+					DriftType:       DriftMissing,
+					BaselineVersion: "14.1.0",
+				},
+				{
+					Tool:           "aqua:BurntSushi/ripgrep",
+					DriftType:      DriftExtra,
+					ManagedVersion: "14.1.0",
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := DetectDrift(tt.baseline, tt.managed, tt.active, tt.zerbDir)
+			got := DetectDrift(tt.baseline, tt.declared, tt.managed, tt.active, tt.zerbDir)
 
 			if len(got) != len(tt.want) {
 				t.Errorf("DetectDrift() returned %d results, want %d", len(got), len(tt.want))
@@ -513,7 +599,7 @@ func TestDetectDrift_Integration(t *testing.T) {
 		{Name: "ripgrep", Version: "13.0.0", Path: zerbDir + "/installs/ripgrep/13.0.0/bin/rg"},
 	}
 
-	results := DetectDrift(baseline, managed, active, zerbDir)
+	results := DetectDrift(baseline, nil, managed, active, zerbDir)
 
 	// Verify we got 5 results (4 baseline + 1 extra)
 	if len(results) != 5 {
@@ -0,0 +1,99 @@
+package drift
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
+)
+
+// PersistentVersionCache is a VersionCache backed by version records on
+// disk, with an in-memory cache in front of it, so versions detected by one
+// run are reused by the next instead of executing every tool again.
+//
+// A record is only used while the binary's modification time and size
+// match the ones it was detected for, so upgrading a tool in place
+// invalidates it. Write failures are ignored: the cache is an optimization
+// and detection still works without it.
+type PersistentVersionCache struct {
+	memory *InMemoryVersionCache
+	store  *VersionRecordStore
+	clock  clock.Clock
+}
+
+// NewPersistentVersionCache creates a version cache storing its records in
+// store, with the default in-memory TTL as the fast layer.
+func NewPersistentVersionCache(store *VersionRecordStore) *PersistentVersionCache {
+	return &PersistentVersionCache{
+		memory: NewVersionCache(),
+		store:  store,
+		clock:  clock.Real{},
+	}
+}
+
+// WithClock sets the clock used for the in-memory TTL and the detection
+// time of new records, and returns the cache.
+func (c *PersistentVersionCache) WithClock(clk clock.Clock) *PersistentVersionCache {
+	if clk == nil {
+		clk = clock.Real{}
+	}
+	c.clock = clk
+	c.memory.WithClock(clk)
+	return c
+}
+
+// Get returns the cached version for key, from memory or else from a disk
+// record that still matches the binary.
+func (c *PersistentVersionCache) Get(key string) (string, bool) {
+	if version, ok := c.memory.Get(key); ok {
+		return version, true
+	}
+
+	binaryPath, probe := splitVersionCacheKey(key)
+	record, err := c.store.Load(binaryPath)
+	if err != nil || record == nil || record.Probe != probe {
+		return "", false
+	}
+	info, err := os.Stat(binaryPath)
+	if err != nil || !info.ModTime().Equal(record.ModTime) || info.Size() != record.Size {
+		// The binary changed since the version was detected
+		return "", false
+	}
+
+	c.memory.Set(key, record.Version)
+	return record.Version, true
+}
+
+// Set caches version for key in memory and records it on disk along with
+// the binary's current modification time and size.
+func (c *PersistentVersionCache) Set(key string, version string) {
+	c.memory.Set(key, version)
+
+	binaryPath, probe := splitVersionCacheKey(key)
+	info, err := os.Stat(binaryPath)
+	if err != nil {
+		return
+	}
+	_ = c.store.Save(VersionRecord{
+		BinaryPath: binaryPath,
+		Version:    version,
+		Probe:      probe,
+		ModTime:    info.ModTime(),
+		Size:       info.Size(),
+		DetectedAt: c.clock.Now(),
+	})
+}
+
+// splitVersionCacheKey splits a key made by versionCacheKey into the
+// binary path and the probe key
+func splitVersionCacheKey(key string) (binaryPath, probe string) {
+	binaryPath, probe, _ = strings.Cut(key, "\x00")
+	return binaryPath, probe
+}
+
+// VersionRecordsDir returns the directory version records of zerbDir are
+// kept in, for NewVersionRecordStore.
+func VersionRecordsDir(zerbDir string) string {
+	return filepath.Join(zerbDir, "cache", "versions")
+}
@@ -0,0 +1,138 @@
+package drift
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPersistentVersionCache(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := NewVersionRecordStore(filepath.Join(dir, "cache", "versions"))
+	binaryPath := CreateMockBinary(t, dir, "node", "1.0.0")
+	modTime := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(binaryPath, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	version, err := DetectVersionWithCache(ctx, binaryPath, false, NewPersistentVersionCache(store))
+	if err != nil || version != "1.0.0" {
+		t.Fatalf("DetectVersionWithCache() = %q, %v, want 1.0.0", version, err)
+	}
+
+	// Upgrade the binary without changing its size or modification time:
+	// a new run (empty in-memory cache) still trusts the record
+	CreateMockBinary(t, dir, "node", "2.0.0")
+	if err := os.Chtimes(binaryPath, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	if version, _ := DetectVersionWithCache(ctx, binaryPath, false, NewPersistentVersionCache(store)); version != "1.0.0" {
+		t.Errorf("second run = %q, want the recorded 1.0.0", version)
+	}
+
+	// forceRefresh bypasses both layers
+	if version, _ := DetectVersionWithCache(ctx, binaryPath, true, NewPersistentVersionCache(store)); version != "2.0.0" {
+		t.Errorf("forced refresh = %q, want 2.0.0", version)
+	}
+
+	// A binary changed on disk invalidates its record
+	CreateMockBinary(t, dir, "node", "3.0.0")
+	later := modTime.Add(time.Hour)
+	if err := os.Chtimes(binaryPath, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if version, _ := DetectVersionWithCache(ctx, binaryPath, false, NewPersistentVersionCache(store)); version != "3.0.0" {
+		t.Errorf("after the binary changed = %q, want 3.0.0", version)
+	}
+}
+
+func TestPersistentVersionCache_Get(t *testing.T) {
+	dir := t.TempDir()
+	binaryPath := CreateMockBinary(t, dir, "java", "21.0.1")
+	probe := DefaultVersionProbes().lookup("java")
+
+	cache := NewPersistentVersionCache(NewVersionRecordStore(filepath.Join(dir, "versions")))
+	cache.Set(versionCacheKey(binaryPath, probe), "21.0.1")
+
+	fresh := NewPersistentVersionCache(NewVersionRecordStore(filepath.Join(dir, "versions")))
+	tests := []struct {
+		name   string
+		key    string
+		want   string
+		wantOK bool
+	}{
+		{"same probe", versionCacheKey(binaryPath, probe), "21.0.1", true},
+		{"without probe", versionCacheKey(binaryPath, nil), "", false},
+		{"other probe", versionCacheKey(binaryPath, &VersionProbe{Args: []string{"--version"}, Pattern: probe.Pattern}), "", false},
+		{"no record", versionCacheKey(filepath.Join(dir, "go"), nil), "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := fresh.Get(tt.key)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Get() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestPersistentVersionCache_ShimsNotRecorded(t *testing.T) {
+	ctx := context.Background()
+	zerbDir := t.TempDir()
+	shimDir := ShimDir(zerbDir)
+	if err := os.MkdirAll(shimDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// The shim stays the same while the version it selects changes, as
+	// when the tool manager's config moves to another version
+	selected := filepath.Join(zerbDir, "selected")
+	shim := fmt.Sprintf("#!/bin/sh\necho \"node version $(cat %s)\"\n", selected)
+	if err := os.WriteFile(filepath.Join(shimDir, "node"), []byte(shim), 0755); err != nil {
+		t.Fatal(err)
+	}
+	store := NewVersionRecordStore(filepath.Join(zerbDir, "cache", "versions"))
+
+	for _, version := range []string{"20.11.0", "22.1.0"} {
+		if err := os.WriteFile(selected, []byte(version), 0644); err != nil {
+			t.Fatal(err)
+		}
+		tools, err := QueryShimsWithCache(ctx, zerbDir, []string{"node"}, false, NewPersistentVersionCache(store))
+		if err != nil {
+			t.Fatalf("QueryShimsWithCache() error = %v", err)
+		}
+		if len(tools) != 1 || tools[0].Version != version {
+			t.Errorf("QueryShimsWithCache() = %+v, want node %s", tools, version)
+		}
+	}
+}
+
+func TestQueryActiveWithOptions_Records(t *testing.T) {
+	ctx := context.Background()
+	zerbDir := t.TempDir()
+	binDir := t.TempDir()
+	binaryPath := CreateMockBinary(t, binDir, "node", "1.0.0")
+	modTime := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(binaryPath, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	opts := VersionOptions{Records: NewVersionRecordStore(VersionRecordsDir(zerbDir))}
+
+	if tools, err := QueryActiveWithOptions(ctx, []string{"node"}, false, opts, binDir); err != nil || len(tools) != 1 || tools[0].Version != "1.0.0" {
+		t.Fatalf("QueryActiveWithOptions() = %+v, %v, want node 1.0.0", tools, err)
+	}
+
+	// The next run reads the record instead of running the binary
+	CreateMockBinary(t, binDir, "node", "2.0.0")
+	if err := os.Chtimes(binaryPath, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	if tools, _ := QueryActiveWithOptions(ctx, []string{"node"}, false, opts, binDir); len(tools) != 1 || tools[0].Version != "1.0.0" {
+		t.Errorf("second run = %+v, want the recorded node 1.0.0", tools)
+	}
+}
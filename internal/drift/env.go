@@ -0,0 +1,30 @@
+package drift
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+)
+
+// QueryEnv returns the environment variables the tool manager sets for the
+// tools of the active config (PATH entries for each tool, plus any variables
+// the tools define), as an activated shell would see them.
+func QueryEnv(ctx context.Context, zerbDir string) (map[string]string, error) {
+	// Validate zerbDir to prevent path traversal attacks
+	if err := validateZerbDir(zerbDir); err != nil {
+		return nil, err
+	}
+
+	misePath := filepath.Join(zerbDir, "bin", "mise")
+	output, err := executeMiseCommand(ctx, misePath, zerbDir, "env", "--json")
+	if err != nil {
+		return nil, fmt.Errorf("query tool environment: %w", err)
+	}
+
+	env := make(map[string]string)
+	if err := json.Unmarshal([]byte(output), &env); err != nil {
+		return nil, fmt.Errorf("parse tool environment: %w", err)
+	}
+	return env, nil
+}
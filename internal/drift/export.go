@@ -0,0 +1,219 @@
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// sarifSchema is the JSON schema of the SARIF version WriteDriftSARIF emits
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// driftRecord is the machine-readable form of a DriftResult
+type driftRecord struct {
+	Tool            string `json:"tool"`
+	DriftType       string `json:"drift_type"`
+	Severity        string `json:"severity"`
+	BaselineVersion string `json:"baseline_version,omitempty"`
+	ManagedVersion  string `json:"managed_version,omitempty"`
+	ActiveVersion   string `json:"active_version,omitempty"`
+	ActivePath      string `json:"active_path,omitempty"`
+	// AllPaths and ManagedPathOrder explain an external override
+	AllPaths         []string `json:"all_paths,omitempty"`
+	ManagedPathOrder string   `json:"managed_path_order,omitempty"`
+}
+
+// WriteDriftJSON writes results as an indented JSON array, one object per
+// tool, with the drift type and severity as their string names.
+func WriteDriftJSON(w io.Writer, results []DriftResult) error {
+	records := make([]driftRecord, len(results))
+	for i, r := range results {
+		records[i] = driftRecord{
+			Tool:            r.Tool,
+			DriftType:       r.DriftType.String(),
+			Severity:        r.DriftType.Severity().String(),
+			BaselineVersion: r.BaselineVersion,
+			ManagedVersion:  r.ManagedVersion,
+			ActiveVersion:   r.ActiveVersion,
+			ActivePath:      r.ActivePath,
+			AllPaths:        r.AllPaths,
+		}
+		if r.PathOrder != PathOrderUnknown {
+			records[i].ManagedPathOrder = r.PathOrder.String()
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(records); err != nil {
+		return fmt.Errorf("encode drift results: %w", err)
+	}
+	return nil
+}
+
+// driftRuleTypes are the drift types reported as SARIF rules, in rule order
+var driftRuleTypes = []DriftType{
+	DriftExternalOverride,
+	DriftVersionMismatch,
+	DriftMissing,
+	DriftExtra,
+	DriftManagedButNotActive,
+	DriftVersionUnknown,
+	DriftInactiveProfile,
+}
+
+// Description returns a one-line explanation of the drift type
+func (d DriftType) Description() string {
+	switch d {
+	case DriftOK:
+		return "Tool matches baseline"
+	case DriftVersionMismatch:
+		return "Installed version differs from baseline"
+	case DriftMissing:
+		return "Tool declared in baseline but not found"
+	case DriftExtra:
+		return "Tool installed but not in baseline"
+	case DriftExternalOverride:
+		return "External installation taking precedence over ZERB"
+	case DriftManagedButNotActive:
+		return "Tool installed by ZERB but not in PATH"
+	case DriftVersionUnknown:
+		return "Version could not be detected"
+	case DriftInactiveProfile:
+		return "Tool installed for a profile not active on this machine"
+	default:
+		return "Unknown drift"
+	}
+}
+
+// sarifLevel maps a drift severity to a SARIF result level
+func sarifLevel(s Severity) string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "note"
+	default:
+		return "none"
+	}
+}
+
+// SARIF 2.1.0 types, limited to the properties drift reports use
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		Version        string      `json:"version,omitempty"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		ID                   string             `json:"id"`
+		ShortDescription     sarifMessage       `json:"shortDescription"`
+		DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	}
+	sarifConfiguration struct {
+		Level string `json:"level"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		RuleIndex int             `json:"ruleIndex"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations"`
+	}
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	}
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+)
+
+// WriteDriftSARIF writes the drifted results as a SARIF 2.1.0 log for
+// code-scanning dashboards. Each drift type is a rule; each drift is a result
+// located at configURI, the config that declares the baseline. OK results
+// are left out. version is ZERB's version, reported as the tool version.
+func WriteDriftSARIF(w io.Writer, results []DriftResult, version, configURI string) error {
+	driver := sarifDriver{
+		Name:           "zerb",
+		Version:        version,
+		InformationURI: "https://github.com/ZebulonRouseFrantzich/zerb",
+	}
+	ruleIndex := make(map[DriftType]int, len(driftRuleTypes))
+	for i, d := range driftRuleTypes {
+		ruleIndex[d] = i
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   d.String(),
+			ShortDescription:     sarifMessage{Text: d.Description()},
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(d.Severity())},
+		})
+	}
+
+	run := sarifRun{Tool: sarifTool{Driver: driver}, Results: []sarifResult{}}
+	for _, r := range results {
+		index, ok := ruleIndex[r.DriftType]
+		if !ok {
+			continue
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    r.DriftType.String(),
+			RuleIndex: index,
+			Level:     sarifLevel(r.DriftType.Severity()),
+			Message:   sarifMessage{Text: sarifResultMessage(r)},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: configURI}},
+			}},
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(sarifLog{Schema: sarifSchema, Version: "2.1.0", Runs: []sarifRun{run}}); err != nil {
+		return fmt.Errorf("encode SARIF log: %w", err)
+	}
+	return nil
+}
+
+// sarifResultMessage describes a single drift for a SARIF result
+func sarifResultMessage(r DriftResult) string {
+	msg := fmt.Sprintf("%s: %s", r.Tool, r.DriftType.Description())
+
+	var details []string
+	if r.BaselineVersion != "" {
+		details = append(details, "baseline "+r.BaselineVersion)
+	}
+	if r.ManagedVersion != "" {
+		details = append(details, "managed "+r.ManagedVersion)
+	}
+	if r.ActiveVersion != "" {
+		details = append(details, "active "+r.ActiveVersion)
+	}
+	if r.ActivePath != "" {
+		details = append(details, "at "+r.ActivePath)
+	}
+	if len(details) > 0 {
+		msg += " (" + strings.Join(details, ", ") + ")"
+	}
+	return msg
+}
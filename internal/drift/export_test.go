@@ -0,0 +1,88 @@
+package drift
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func exportTestResults() []DriftResult {
+	return []DriftResult{
+		{Tool: "node", DriftType: DriftOK, BaselineVersion: "20.11.0", ManagedVersion: "20.11.0", ActiveVersion: "20.11.0", ActivePath: "/home/user/.config/zerb/installs/node/20.11.0/bin/node"},
+		{Tool: "python", DriftType: DriftExternalOverride, BaselineVersion: "3.12.1", ManagedVersion: "3.12.1", ActiveVersion: "3.11.0", ActivePath: "/usr/bin/python"},
+		{Tool: "ripgrep", DriftType: DriftMissing, BaselineVersion: "14.1.0"},
+	}
+}
+
+func TestWriteDriftJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteDriftJSON(&buf, exportTestResults()); err != nil {
+		t.Fatalf("WriteDriftJSON() error = %v", err)
+	}
+
+	var got []map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not a JSON array: %v\n%s", err, buf.String())
+	}
+	want := []map[string]string{
+		{"tool": "node", "drift_type": "OK", "severity": "none", "baseline_version": "20.11.0", "managed_version": "20.11.0", "active_version": "20.11.0", "active_path": "/home/user/.config/zerb/installs/node/20.11.0/bin/node"},
+		{"tool": "python", "drift_type": "EXTERNAL_OVERRIDE", "severity": "error", "baseline_version": "3.12.1", "managed_version": "3.12.1", "active_version": "3.11.0", "active_path": "/usr/bin/python"},
+		{"tool": "ripgrep", "drift_type": "MISSING", "severity": "error", "baseline_version": "14.1.0"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WriteDriftJSON() =\n%v\nwant:\n%v", got, want)
+	}
+
+	buf.Reset()
+	if err := WriteDriftJSON(&buf, []DriftResult{}); err != nil {
+		t.Fatalf("WriteDriftJSON() error = %v", err)
+	}
+	if buf.String() != "[]\n" {
+		t.Errorf("WriteDriftJSON() with no results = %q, want %q", buf.String(), "[]\n")
+	}
+}
+
+func TestWriteDriftSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteDriftSARIF(&buf, exportTestResults(), "v1.2.3", "zerb.active.lua"); err != nil {
+		t.Fatalf("WriteDriftSARIF() error = %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("version = %q with %d runs, want 2.1.0 with 1 run", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "zerb" || run.Tool.Driver.Version != "v1.2.3" {
+		t.Errorf("driver = %+v, want zerb v1.2.3", run.Tool.Driver)
+	}
+
+	// OK results are left out
+	if len(run.Results) != 2 {
+		t.Fatalf("got %d results, want 2", len(run.Results))
+	}
+	tests := []struct {
+		ruleID  string
+		level   string
+		message string
+	}{
+		{"EXTERNAL_OVERRIDE", "error", "python: External installation taking precedence over ZERB (baseline 3.12.1, managed 3.12.1, active 3.11.0, at /usr/bin/python)"},
+		{"MISSING", "error", "ripgrep: Tool declared in baseline but not found (baseline 14.1.0)"},
+	}
+	for i, tt := range tests {
+		r := run.Results[i]
+		if r.RuleID != tt.ruleID || r.Level != tt.level || r.Message.Text != tt.message {
+			t.Errorf("result %d = {%s %s %q}, want {%s %s %q}", i, r.RuleID, r.Level, r.Message.Text, tt.ruleID, tt.level, tt.message)
+		}
+		if run.Tool.Driver.Rules[r.RuleIndex].ID != r.RuleID {
+			t.Errorf("result %d ruleIndex %d points at %s", i, r.RuleIndex, run.Tool.Driver.Rules[r.RuleIndex].ID)
+		}
+		if len(r.Locations) != 1 || r.Locations[0].PhysicalLocation.ArtifactLocation.URI != "zerb.active.lua" {
+			t.Errorf("result %d locations = %+v, want zerb.active.lua", i, r.Locations)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package drift
+
+import (
+	"sort"
+	"strings"
+)
+
+// ToolFilter restricts drift detection to a subset of tools by name.
+// Names are matched case-insensitively against the normalized tool name,
+// so "Node" selects "node" and "cargo:ripgrep" selects "ripgrep".
+type ToolFilter struct {
+	only   map[string]string // lowercased name -> name as given
+	ignore map[string]string
+}
+
+// NewToolFilter creates a filter selecting only the named tools (all tools
+// if only is empty) minus the ignored ones. Empty names are dropped.
+func NewToolFilter(only, ignore []string) ToolFilter {
+	return ToolFilter{only: filterNames(only), ignore: filterNames(ignore)}
+}
+
+// ParseToolList splits a comma-separated list of tool names, as given to
+// --only and --ignore
+func ParseToolList(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// filterNames indexes names by their lowercased form
+func filterNames(names []string) map[string]string {
+	index := make(map[string]string, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			index[strings.ToLower(toolKey(name))] = name
+		}
+	}
+	return index
+}
+
+// IsEmpty reports whether the filter selects every tool
+func (f ToolFilter) IsEmpty() bool {
+	return len(f.only) == 0 && len(f.ignore) == 0
+}
+
+// Match reports whether the tool named name is selected
+func (f ToolFilter) Match(name string) bool {
+	key := strings.ToLower(toolKey(name))
+	if _, ignored := f.ignore[key]; ignored {
+		return false
+	}
+	if len(f.only) == 0 {
+		return true
+	}
+	_, ok := f.only[key]
+	return ok
+}
+
+// FilterBaseline returns the selected baseline tools, and the sorted names
+// given to the filter that are not in the baseline, so callers can warn
+// about typos.
+func (f ToolFilter) FilterBaseline(baseline []ToolSpec) (selected []ToolSpec, unknown []string) {
+	declared := make(map[string]bool, len(baseline))
+	for _, spec := range baseline {
+		declared[strings.ToLower(spec.Name)] = true
+		if f.Match(spec.Name) {
+			selected = append(selected, spec)
+		}
+	}
+
+	for _, names := range []map[string]string{f.only, f.ignore} {
+		for key, name := range names {
+			if !declared[key] {
+				unknown = append(unknown, name)
+			}
+		}
+	}
+	sort.Strings(unknown)
+	return selected, unknown
+}
+
+// FilterTools returns the selected tools, e.g. managed tools, so that extras
+// outside the filter are not reported
+func (f ToolFilter) FilterTools(tools []Tool) []Tool {
+	var selected []Tool
+	for _, t := range tools {
+		if f.Match(t.Name) {
+			selected = append(selected, t)
+		}
+	}
+	return selected
+}
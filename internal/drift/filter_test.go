@@ -0,0 +1,91 @@
+package drift
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestToolFilter_FilterBaseline(t *testing.T) {
+	baseline := []ToolSpec{
+		{Name: "node", Version: "20.11.0"},
+		{Name: "go", Version: "1.22.0"},
+		{Name: "python", Version: "3.12.1"},
+		{Backend: "cargo", Name: "ripgrep", Version: "14.1.0"},
+	}
+
+	tests := []struct {
+		name        string
+		only        []string
+		ignore      []string
+		wantTools   []string
+		wantUnknown []string
+	}{
+		{
+			name:      "no filter",
+			wantTools: []string{"node", "go", "python", "ripgrep"},
+		},
+		{
+			name:      "only, case-insensitive",
+			only:      []string{"Node", "GO"},
+			wantTools: []string{"node", "go"},
+		},
+		{
+			name:      "ignore",
+			ignore:    []string{"python"},
+			wantTools: []string{"node", "go", "ripgrep"},
+		},
+		{
+			name:      "only with backend prefix",
+			only:      []string{"cargo:ripgrep"},
+			wantTools: []string{"ripgrep"},
+		},
+		{
+			name:      "ignore wins over only",
+			only:      []string{"node", "go"},
+			ignore:    []string{"go"},
+			wantTools: []string{"node"},
+		},
+		{
+			name:        "unknown names",
+			only:        []string{"node", "rust"},
+			ignore:      []string{"java"},
+			wantTools:   []string{"node"},
+			wantUnknown: []string{"java", "rust"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, unknown := NewToolFilter(tt.only, tt.ignore).FilterBaseline(baseline)
+
+			var names []string
+			for _, spec := range selected {
+				names = append(names, spec.Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantTools) {
+				t.Errorf("FilterBaseline() selected %v, want %v", names, tt.wantTools)
+			}
+			if !reflect.DeepEqual(unknown, tt.wantUnknown) {
+				t.Errorf("FilterBaseline() unknown = %v, want %v", unknown, tt.wantUnknown)
+			}
+		})
+	}
+}
+
+func TestToolFilter_FilterTools(t *testing.T) {
+	managed := []Tool{{Name: "node", Version: "20.11.0"}, {Name: "cargo:ripgrep", Version: "14.1.0"}, {Name: "jq", Version: "1.7"}}
+
+	got := NewToolFilter([]string{"node", "RIPGREP"}, nil).FilterTools(managed)
+	want := []Tool{managed[0], managed[1]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FilterTools() = %v, want %v", got, want)
+	}
+}
+
+func TestParseToolList(t *testing.T) {
+	got := ParseToolList(" node, go,,python ")
+	want := []string{"node", "go", "python"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseToolList() = %v, want %v", got, want)
+	}
+}
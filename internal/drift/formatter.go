@@ -16,10 +16,8 @@ func FormatDriftReport(results []DriftResult) string {
 	sb.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n\n")
 
 	// Count drift types
-	counts := make(map[DriftType]int)
-	for _, r := range results {
-		counts[r.DriftType]++
-	}
+	summary := SummarizeDrift(results)
+	counts := summary.Counts
 
 	// Display each drift (skip OK entries in detailed view)
 	for _, r := range results {
@@ -40,7 +38,7 @@ func FormatDriftReport(results []DriftResult) string {
 	// Summary
 	sb.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
 
-	totalDrifts := len(results) - okCount
+	totalDrifts := summary.Drifted()
 	if totalDrifts == 0 {
 		sb.WriteString("SUMMARY: No drifts detected ✓\n")
 	} else {
@@ -65,6 +63,9 @@ func FormatDriftReport(results []DriftResult) string {
 		if counts[DriftVersionUnknown] > 0 {
 			parts = append(parts, fmt.Sprintf("%d version unknown", counts[DriftVersionUnknown]))
 		}
+		if counts[DriftInactiveProfile] > 0 {
+			parts = append(parts, fmt.Sprintf("%d inactive profile", counts[DriftInactiveProfile]))
+		}
 
 		sb.WriteString("  " + strings.Join(parts, ", ") + "\n")
 	}
@@ -86,8 +87,24 @@ func formatDriftEntry(r DriftResult) string {
 		sb.WriteString(fmt.Sprintf("  %s\n", r.Tool))
 		sb.WriteString(fmt.Sprintf("    Baseline:  %s (managed by ZERB)\n", r.BaselineVersion))
 		sb.WriteString(fmt.Sprintf("    Active:    %s at %s\n", r.ActiveVersion, r.ActivePath))
+		if len(r.AllPaths) > 1 {
+			sb.WriteString("    PATH order:\n")
+			for i, path := range r.AllPaths {
+				sb.WriteString(fmt.Sprintf("      %d. %s\n", i+1, path))
+			}
+		}
 		sb.WriteString("    \n")
 		sb.WriteString("    → An external installation has taken precedence over ZERB\n")
+		switch r.PathOrder {
+		case PathOrderManagedLater:
+			sb.WriteString("    → ZERB's copy is shadowed by an earlier PATH entry; move ZERB's\n")
+			sb.WriteString("      directories ahead of it in PATH\n")
+		case PathOrderManagedEarlier:
+			sb.WriteString("    → ZERB's copy comes first in PATH; check for an alias or a stale\n")
+			sb.WriteString("      command hash (run 'hash -r')\n")
+		case PathOrderManagedAbsent:
+			sb.WriteString("    → ZERB's copy is not in PATH; is this shell activated?\n")
+		}
 
 	case DriftVersionMismatch:
 		sb.WriteString("[VERSION MISMATCH]\n")
@@ -113,6 +130,15 @@ func formatDriftEntry(r DriftResult) string {
 		sb.WriteString("    \n")
 		sb.WriteString("    → Tool is installed but not in baseline\n")
 
+	case DriftInactiveProfile:
+		sb.WriteString("[INACTIVE PROFILE]\n")
+		sb.WriteString(fmt.Sprintf("  %s\n", r.Tool))
+		sb.WriteString(fmt.Sprintf("    Declared:  %s (in an inactive profile)\n", r.BaselineVersion))
+		sb.WriteString(fmt.Sprintf("    Managed:   %s (managed by ZERB)\n", r.ManagedVersion))
+		sb.WriteString("    \n")
+		sb.WriteString("    → Declared by a profile that is not active on this machine\n")
+		sb.WriteString("      (see ZERB_PROFILES); it is kept installed\n")
+
 	case DriftManagedButNotActive:
 		sb.WriteString("[MANAGED BUT NOT ACTIVE]\n")
 		sb.WriteString(fmt.Sprintf("  %s\n", r.Tool))
@@ -58,14 +58,10 @@ func TestDataCollection_Integration(t *testing.T) {
 	CreateMockBinary(t, mockDir, "node", "20.11.0")
 	CreateMockBinary(t, mockDir, "python", "3.12.1")
 
-	origPATH := os.Getenv("PATH")
-	defer os.Setenv("PATH", origPATH)
-	// Use ONLY mock directory as PATH to avoid finding system tools
-	os.Setenv("PATH", mockDir)
-
-	// 4. Test active collection
+	// 4. Test active collection, searching ONLY the mock directory to
+	// avoid finding system tools
 	toolNames := []string{"node", "python", "go"}
-	active, err := QueryActive(context.Background(), toolNames, false)
+	active, err := QueryActive(context.Background(), toolNames, false, mockDir)
 	if err != nil {
 		t.Fatalf("QueryActive() error = %v", err)
 	}
@@ -163,13 +159,8 @@ exit 1
 		t.Fatalf("failed to create test binary: %v", err)
 	}
 
-	// Set PATH
-	origPATH := os.Getenv("PATH")
-	defer os.Setenv("PATH", origPATH)
-	os.Setenv("PATH", tmpDir+":"+origPATH)
-
 	// Query active
-	active, err := QueryActive(context.Background(), []string{"mystery-tool"}, false)
+	active, err := QueryActive(context.Background(), []string{"mystery-tool"}, false, tmpDir)
 	if err != nil {
 		t.Fatalf("QueryActive() error = %v", err)
 	}
@@ -251,21 +242,15 @@ func TestDriftDetection_EndToEnd(t *testing.T) {
 	}
 	CreateMockBinary(t, systemDir, "rust", "1.76.0") // External override (different from baseline)
 
-	// Set up PATH with both ZERB and system directories
-	origPATH := os.Getenv("PATH")
-	defer os.Setenv("PATH", origPATH)
-	testPATH := systemDir + ":" + nodeInstallDir + ":" + pythonInstallDir + ":" + ripgrepInstallDir
-	os.Setenv("PATH", testPATH)
-
-	// Query active environment
+	// Query active environment with both ZERB and system directories in the search path
 	toolNames := []string{"node", "python", "go", "rust", "rg"}
-	active, err := QueryActive(context.Background(), toolNames, false)
+	active, err := QueryActive(context.Background(), toolNames, false, systemDir, nodeInstallDir, pythonInstallDir, ripgrepInstallDir)
 	if err != nil {
 		t.Fatalf("QueryActive() error = %v", err)
 	}
 
 	// Detect drift
-	results := DetectDrift(baseline, managed, active, zerbDir)
+	results := DetectDrift(baseline, nil, managed, active, zerbDir)
 
 	// Verify results
 	if len(results) != 5 { // 4 baseline + 1 extra (rg)
@@ -378,20 +363,17 @@ func TestDriftDetection_ManagedButNotActive(t *testing.T) {
 		{Name: "node", Version: "20.11.0", Path: filepath.Join(nodeInstallDir, "node")},
 	}
 
-	// Set PATH to empty directory (tool not in PATH)
+	// Search an empty directory (tool not in PATH)
 	emptyDir := t.TempDir()
-	origPATH := os.Getenv("PATH")
-	defer os.Setenv("PATH", origPATH)
-	os.Setenv("PATH", emptyDir)
 
 	// Query active (should find nothing)
-	active, err := QueryActive(context.Background(), []string{"node"}, false)
+	active, err := QueryActive(context.Background(), []string{"node"}, false, emptyDir)
 	if err != nil {
 		t.Fatalf("QueryActive() error = %v", err)
 	}
 
 	// Detect drift
-	results := DetectDrift(baseline, managed, active, zerbDir)
+	results := DetectDrift(baseline, nil, managed, active, zerbDir)
 
 	if len(results) != 1 {
 		t.Fatalf("DetectDrift() returned %d results, want 1", len(results))
@@ -445,19 +427,14 @@ exit 1
 		{Name: "mystery", Version: "1.0.0", Path: toolPath},
 	}
 
-	// Set PATH
-	origPATH := os.Getenv("PATH")
-	defer os.Setenv("PATH", origPATH)
-	os.Setenv("PATH", mysteryInstallDir)
-
 	// Query active
-	active, err := QueryActive(context.Background(), []string{"mystery"}, false)
+	active, err := QueryActive(context.Background(), []string{"mystery"}, false, mysteryInstallDir)
 	if err != nil {
 		t.Fatalf("QueryActive() error = %v", err)
 	}
 
 	// Detect drift
-	results := DetectDrift(baseline, managed, active, zerbDir)
+	results := DetectDrift(baseline, nil, managed, active, zerbDir)
 
 	if len(results) != 1 {
 		t.Fatalf("DetectDrift() returned %d results, want 1", len(results))
@@ -10,6 +10,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/binary"
 )
 
 // validateZerbDir checks if zerbDir contains path traversal sequences
@@ -59,7 +61,10 @@ func getMiseTimeout() time.Duration {
 	return defaultMiseTimeout
 }
 
-// QueryManaged queries mise for ZERB-installed tools
+// QueryManaged queries mise for ZERB-installed tools. Each tool carries
+// its full identity as mise reports it (e.g. "cargo:ripgrep") in both
+// Name and ID, with Backend split out, so DetectDrift can match it to the
+// baseline by backend and name.
 func QueryManaged(ctx context.Context, zerbDir string) ([]Tool, error) {
 	// Validate zerbDir to prevent path traversal attacks
 	if err := validateZerbDir(zerbDir); err != nil {
@@ -67,6 +72,9 @@ func QueryManaged(ctx context.Context, zerbDir string) ([]Tool, error) {
 	}
 
 	misePath := filepath.Join(zerbDir, "bin", "mise")
+	if err := binary.CheckExecutable(misePath, binary.BinaryMise); err != nil {
+		return nil, err
+	}
 
 	// Execute mise ls --json to get all installed tools
 	jsonOutput, err := executeMiseCommand(ctx, misePath, zerbDir, "ls", "--json")
@@ -110,27 +118,49 @@ func QueryManaged(ctx context.Context, zerbDir string) ([]Tool, error) {
 			}
 		}
 
-		tools = append(tools, Tool{
+		tool := Tool{
 			Name:    toolName,
 			Version: version,
 			Path:    installPath,
-		})
+			ID:      toolName,
+		}
+		if spec, err := ParseToolSpec(toolName); err == nil {
+			tool.Backend = spec.Backend
+		}
+		tools = append(tools, tool)
 	}
 
 	return tools, nil
 }
 
 // executeMiseCommand executes a mise command with proper isolation
+// A missing binary is reported as binary.ErrBinaryMissing.
 func executeMiseCommand(ctx context.Context, misePath, zerbDir string, args ...string) (string, error) {
+	if err := binary.CheckExecutable(misePath, binary.BinaryMise); err != nil {
+		return "", err
+	}
+
 	timeout := getMiseTimeout()
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, misePath, args...)
 
-	// Build minimal clean environment for mise (instead of inheriting all env vars)
-	// Only include variables that mise actually needs
-	cmd.Env = []string{
+	cmd.Env = miseEnv(zerbDir)
+
+	output, err := cmd.CombinedOutput() // Capture both stdout and stderr
+	if err != nil {
+		return "", fmt.Errorf("%w (output: %s)", err, string(output))
+	}
+
+	return string(output), nil
+}
+
+// miseEnv builds a minimal clean environment for mise (instead of
+// inheriting all env vars), isolated to the ZERB directory. Only includes
+// variables that mise actually needs.
+func miseEnv(zerbDir string) []string {
+	return []string{
 		"MISE_CONFIG_FILE=" + filepath.Join(zerbDir, "mise/config.toml"),
 		"MISE_DATA_DIR=" + filepath.Join(zerbDir, "mise"),
 		"MISE_CACHE_DIR=" + filepath.Join(zerbDir, "cache/mise"),
@@ -139,14 +169,8 @@ func executeMiseCommand(ctx context.Context, misePath, zerbDir string, args ...s
 		"USER=" + os.Getenv("USER"),
 		"TMPDIR=" + os.Getenv("TMPDIR"),
 		"TERM=" + os.Getenv("TERM"), // For better output formatting
+		"MISE_JOBS=" + strconv.Itoa(Parallelism()),
 	}
-
-	output, err := cmd.CombinedOutput() // Capture both stdout and stderr
-	if err != nil {
-		return "", fmt.Errorf("%w (output: %s)", err, string(output))
-	}
-
-	return string(output), nil
 }
 
 // parseMiseJSON parses mise ls --json output
@@ -161,7 +185,9 @@ func parseMiseJSON(jsonOutput string) (map[string][]MiseTool, error) {
 }
 
 // parseMiseCurrent parses mise ls --current output
-// Format: "tool_name    version\n..."
+// Format: "tool_name    version  [source  requested]\n..."
+// Tool names keep their backend ("cargo:ripgrep"). Versions mise reports
+// as "(missing)" are not installed and are skipped.
 func parseMiseCurrent(output string) (map[string]string, error) {
 	result := make(map[string]string)
 
@@ -174,18 +200,32 @@ func parseMiseCurrent(output string) (map[string]string, error) {
 
 		// Split on whitespace (can be tabs or spaces)
 		fields := strings.Fields(line)
-		if len(fields) >= 2 {
-			toolName := fields[0]
-			version := fields[1]
-			result[toolName] = version
+		if len(fields) < 2 || isMiseMissing(fields[1:]) {
+			continue
 		}
+		result[fields[0]] = fields[1]
 	}
 
 	return result, nil
 }
 
-// IsZERBManaged checks if a binary path is managed by ZERB
+// isMiseMissing reports whether the fields after a tool name mark the
+// version as not installed
+func isMiseMissing(fields []string) bool {
+	for _, field := range fields {
+		if field == "(missing)" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsZERBManaged checks if a binary path is managed by ZERB: either an
+// installed binary or one of ZERB's shims
 func IsZERBManaged(binaryPath, zerbDir string) bool {
 	installsDir := filepath.Join(zerbDir, "installs")
-	return strings.HasPrefix(binaryPath, installsDir)
+	if strings.HasPrefix(binaryPath, installsDir) {
+		return true
+	}
+	return strings.HasPrefix(binaryPath, ShimDir(zerbDir)+string(filepath.Separator))
 }
@@ -2,10 +2,14 @@ package drift
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/binary"
 )
 
 func TestQueryManaged(t *testing.T) {
@@ -63,8 +67,8 @@ fi
 
 	// Verify results
 	want := []Tool{
-		{Name: "node", Version: "20.11.0", Path: "/home/user/.config/zerb/installs/node/20.11.0"},
-		{Name: "python", Version: "3.12.1", Path: "/home/user/.config/zerb/installs/python/3.12.1"},
+		{Name: "node", Version: "20.11.0", Path: "/home/user/.config/zerb/installs/node/20.11.0", ID: "node"},
+		{Name: "python", Version: "3.12.1", Path: "/home/user/.config/zerb/installs/python/3.12.1", ID: "python"},
 	}
 
 	if len(tools) != len(want) {
@@ -86,7 +90,7 @@ fi
 			t.Errorf("QueryManaged(context.Background(), ) missing tool %s", wantTool.Name)
 			continue
 		}
-		if gotTool != wantTool {
+		if !reflect.DeepEqual(gotTool, wantTool) {
 			t.Errorf("QueryManaged(context.Background(), ) tool %s = %+v, want %+v", wantTool.Name, gotTool, wantTool)
 		}
 	}
@@ -232,6 +236,21 @@ go       1.22.0`,
 				"python": "3.12.1",
 			},
 		},
+		{
+			name:   "Backend tools and source columns",
+			output: "cargo:ripgrep  14.1.0  ~/.config/zerb/mise/config.toml  14.1.0\nubi:sharkdp/bat  0.24.0  ~/.config/zerb/mise/config.toml  latest",
+			want: map[string]string{
+				"cargo:ripgrep":   "14.1.0",
+				"ubi:sharkdp/bat": "0.24.0",
+			},
+		},
+		{
+			name:   "Missing versions skipped",
+			output: "node  20.11.0\npython  3.12.1 (missing)  ~/.config/zerb/mise/config.toml  3.12.1\nrust",
+			want: map[string]string{
+				"node": "20.11.0",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -262,6 +281,18 @@ func TestIsZERBManaged(t *testing.T) {
 			zerbDir: "/home/user/.config/zerb",
 			want:    true,
 		},
+		{
+			name:    "ZERB shim path",
+			path:    "/home/user/.config/zerb/mise/shims/node",
+			zerbDir: "/home/user/.config/zerb",
+			want:    true,
+		},
+		{
+			name:    "ZERB tool manager data path",
+			path:    "/home/user/.config/zerb/mise/shims-old/node",
+			zerbDir: "/home/user/.config/zerb",
+			want:    false,
+		},
 		{
 			name:    "System path",
 			path:    "/usr/bin/node",
@@ -355,6 +386,74 @@ fi
 	}
 }
 
+func TestQueryManaged_BackendTools(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	miseScript := `#!/bin/sh
+if [ "$1" = "ls" ] && [ "$2" = "--json" ]; then
+    cat << 'EOF'
+{
+  "cargo:ripgrep": [
+    {"version": "14.1.0", "install_path": "/zerb/installs/cargo-ripgrep/14.1.0"}
+  ],
+  "ubi:sharkdp/bat": [
+    {"version": "0.24.0", "install_path": "/zerb/installs/ubi-sharkdp-bat/0.24.0"}
+  ]
+}
+EOF
+elif [ "$1" = "ls" ] && [ "$2" = "--current" ]; then
+    cat << 'EOF'
+cargo:ripgrep    14.1.0  ~/.config/zerb/mise/config.toml  14.1.0
+ubi:sharkdp/bat  0.24.0  ~/.config/zerb/mise/config.toml  0.24.0
+EOF
+fi
+`
+	misePath := filepath.Join(tmpDir, "bin", "mise")
+	if err := os.MkdirAll(filepath.Dir(misePath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(misePath, []byte(miseScript), 0755); err != nil {
+		t.Fatalf("failed to create mock mise: %v", err)
+	}
+
+	tools, err := QueryManaged(context.Background(), tmpDir)
+	if err != nil {
+		t.Fatalf("QueryManaged() error = %v", err)
+	}
+
+	got := make(map[string]Tool)
+	for _, tool := range tools {
+		got[tool.ID] = tool
+	}
+	want := map[string]Tool{
+		"cargo:ripgrep":   {Name: "cargo:ripgrep", Version: "14.1.0", Path: "/zerb/installs/cargo-ripgrep/14.1.0", ID: "cargo:ripgrep", Backend: "cargo"},
+		"ubi:sharkdp/bat": {Name: "ubi:sharkdp/bat", Version: "0.24.0", Path: "/zerb/installs/ubi-sharkdp-bat/0.24.0", ID: "ubi:sharkdp/bat", Backend: "ubi"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("QueryManaged() = %+v, want %+v", got, want)
+	}
+
+	// The baseline names the tools by backend and name; none is missing
+	// or extra even where the tool manager uses a repository path
+	var baseline []ToolSpec
+	for _, s := range []string{"cargo:ripgrep@14.1.0", "ubi:sharkdp/bat@0.24.0"} {
+		spec, err := ParseToolSpec(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		baseline = append(baseline, spec)
+	}
+	results := DetectDrift(baseline, nil, tools, nil, tmpDir)
+	if len(results) != len(baseline) {
+		t.Fatalf("DetectDrift() returned %d results, want %d: %+v", len(results), len(baseline), results)
+	}
+	for _, result := range results {
+		if result.DriftType != DriftManagedButNotActive {
+			t.Errorf("DetectDrift() %s = %v, want %v", result.Tool, result.DriftType, DriftManagedButNotActive)
+		}
+	}
+}
+
 func TestValidateZerbDir(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -460,3 +559,27 @@ func TestGetMiseTimeout(t *testing.T) {
 		})
 	}
 }
+
+func TestMiseCommands_MissingBinary(t *testing.T) {
+	zerbDir := t.TempDir()
+	ctx := context.Background()
+
+	calls := map[string]func() error{
+		"QueryManaged": func() error { _, err := QueryManaged(ctx, zerbDir); return err },
+		"QueryEnv":     func() error { _, err := QueryEnv(ctx, zerbDir); return err },
+		"install": func() error {
+			return executeMiseInstallOrUninstall(ctx, filepath.Join(zerbDir, "bin", "mise"), zerbDir, "install", "node@20.11.0")
+		},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			err := call()
+			if !errors.Is(err, binary.ErrBinaryMissing) {
+				t.Fatalf("%s error = %v, want ErrBinaryMissing", name, err)
+			}
+			if !strings.Contains(err.Error(), "zerb init --reinstall") {
+				t.Errorf("error %q should suggest zerb init --reinstall", err)
+			}
+		})
+	}
+}
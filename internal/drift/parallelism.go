@@ -0,0 +1,43 @@
+package drift
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// EnvParallelism names the environment variable setting how many tool
+// operations run at once, like the global --parallelism flag
+const EnvParallelism = "ZERB_PARALLELISM"
+
+// parallelism bounds concurrent version detection and tool installs
+var parallelism = runtime.NumCPU()
+
+// Parallelism returns how many tool operations run at once.
+func Parallelism() int {
+	return parallelism
+}
+
+// SetParallelism sets how many tool operations run at once: version
+// detection workers in QueryActive and QueryShims, and the jobs the tool
+// manager uses for each install. n must be at least 1.
+func SetParallelism(n int) error {
+	if n < 1 {
+		return fmt.Errorf("parallelism must be at least 1, got %d", n)
+	}
+	parallelism = n
+	return nil
+}
+
+// ParseParallelism parses a --parallelism or ZERB_PARALLELISM value.
+func ParseParallelism(value string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid parallelism %q: must be a whole number", value)
+	}
+	if n < 1 {
+		return 0, fmt.Errorf("invalid parallelism %d: must be at least 1", n)
+	}
+	return n, nil
+}
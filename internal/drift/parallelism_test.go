@@ -0,0 +1,99 @@
+package drift
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// setParallelism sets the parallelism for a test, restoring it afterwards
+func setParallelism(t *testing.T, n int) {
+	t.Helper()
+	old := Parallelism()
+	if err := SetParallelism(n); err != nil {
+		t.Fatalf("SetParallelism(%d) error = %v", n, err)
+	}
+	t.Cleanup(func() { _ = SetParallelism(old) })
+}
+
+func TestQueryActive_Parallelism(t *testing.T) {
+	binaries := map[string]string{}
+	names := []string{"node", "python", "go", "ruby", "rust", "deno", "bun", "java"}
+	for _, name := range names {
+		binaries[name] = "1.0.0"
+	}
+	searchPath := SetupTestPATH(t, binaries)
+
+	for _, workers := range []int{1, 2, 5} {
+		setParallelism(t, workers)
+
+		// Record how many detections run at once
+		var mu sync.Mutex
+		running, maxRunning := 0, 0
+		old := detectToolVersion
+		detectToolVersion = func(ctx context.Context, name, binaryPath string, env []string, forceRefresh bool, cache VersionCache, probes VersionProbes) (string, error) {
+			mu.Lock()
+			running++
+			maxRunning = max(maxRunning, running)
+			mu.Unlock()
+
+			time.Sleep(20 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return "1.0.0", nil
+		}
+		t.Cleanup(func() { detectToolVersion = old })
+
+		tools, err := QueryActiveWithCache(context.Background(), names, true, nil, searchPath)
+		if err != nil {
+			t.Fatalf("QueryActive() error = %v", err)
+		}
+		detectToolVersion = old
+
+		if maxRunning != workers {
+			t.Errorf("parallelism %d: %d detections ran at once", workers, maxRunning)
+		}
+		var got []string
+		for _, tool := range tools {
+			got = append(got, tool.Name)
+		}
+		if !reflect.DeepEqual(got, names) {
+			t.Errorf("parallelism %d: tools = %v, want %v in order", workers, got, names)
+		}
+	}
+}
+
+func TestParseParallelism(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{"1", 1, false},
+		{" 16 ", 16, false},
+		{"0", 0, true},
+		{"-2", 0, true},
+		{"four", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseParallelism(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseParallelism(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseParallelism(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+
+	if err := SetParallelism(0); err == nil {
+		t.Error("SetParallelism(0) succeeded, want error")
+	}
+}
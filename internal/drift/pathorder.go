@@ -0,0 +1,92 @@
+package drift
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// PathOrder describes where ZERB's copy of a tool is in PATH relative to
+// the copy that runs, to explain an external override.
+type PathOrder int
+
+const (
+	// PathOrderUnknown means the order was not determined
+	PathOrderUnknown PathOrder = iota
+	// PathOrderManagedAbsent means no ZERB-managed copy is in PATH, e.g.
+	// the shell is not activated
+	PathOrderManagedAbsent
+	// PathOrderManagedEarlier means ZERB's copy comes before the copy that
+	// runs in PATH, so something else (an alias, a hash) picked the other
+	PathOrderManagedEarlier
+	// PathOrderManagedLater means ZERB's copy is in PATH but shadowed by an
+	// earlier entry
+	PathOrderManagedLater
+)
+
+// String returns a human-readable description of the order
+func (o PathOrder) String() string {
+	switch o {
+	case PathOrderManagedAbsent:
+		return "not in PATH"
+	case PathOrderManagedEarlier:
+		return "earlier in PATH"
+	case PathOrderManagedLater:
+		return "later in PATH"
+	default:
+		return "unknown"
+	}
+}
+
+// ExplainPath returns every executable named toolName in the directories
+// of searchPath (default $PATH), in the order the shell would consider
+// them: the first one runs, the others are shadowed by it.
+func ExplainPath(toolName string, searchPath ...string) []string {
+	if len(searchPath) == 0 {
+		searchPath = filepath.SplitList(os.Getenv("PATH"))
+	}
+
+	var paths []string
+	seen := make(map[string]bool)
+	for _, dir := range searchPath {
+		// A directory listed twice holds the same executable
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		if path, err := lookPath(toolName, []string{dir}); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// classifyPathOrder compares the position of the first ZERB-managed entry
+// in active.AllPaths with the entry that runs (active.Path, resolved).
+func classifyPathOrder(active Tool, zerbDir string) PathOrder {
+	if len(active.AllPaths) == 0 {
+		return PathOrderUnknown
+	}
+
+	activeIndex, managedIndex := -1, -1
+	for i, path := range active.AllPaths {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			resolved = path
+		}
+		if activeIndex < 0 && (path == active.Path || resolved == active.Path) {
+			activeIndex = i
+		}
+		if managedIndex < 0 && (IsZERBManaged(path, zerbDir) || IsZERBManaged(resolved, zerbDir)) {
+			managedIndex = i
+		}
+	}
+
+	switch {
+	case managedIndex < 0:
+		return PathOrderManagedAbsent
+	case activeIndex >= 0 && managedIndex < activeIndex:
+		return PathOrderManagedEarlier
+	default:
+		return PathOrderManagedLater
+	}
+}
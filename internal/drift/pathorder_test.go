@@ -0,0 +1,98 @@
+package drift
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExplainPath(t *testing.T) {
+	systemDir := SetupTestPATH(t, map[string]string{"node": "18.19.0"})
+	zerbDir := t.TempDir()
+	managedDir := filepath.Join(zerbDir, "installs", "node", "20.11.0", "bin")
+	if err := os.MkdirAll(managedDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	CreateMockBinary(t, managedDir, "node", "20.11.0")
+	emptyDir := t.TempDir()
+
+	got := ExplainPath("node", systemDir, emptyDir, managedDir, systemDir)
+	want := []string{filepath.Join(systemDir, "node"), filepath.Join(managedDir, "node")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExplainPath() = %v, want %v", got, want)
+	}
+
+	if got := ExplainPath("python", systemDir, managedDir); got != nil {
+		t.Errorf("ExplainPath() for a tool not in PATH = %v, want nil", got)
+	}
+
+	// QueryActive runs the first and records the whole chain
+	tools, err := QueryActiveWithCache(context.Background(), []string{"node"}, true, nil, systemDir, managedDir)
+	if err != nil {
+		t.Fatalf("QueryActive() error = %v", err)
+	}
+	if len(tools) != 1 || tools[0].Version != "18.19.0" || !reflect.DeepEqual(tools[0].AllPaths, want) {
+		t.Fatalf("QueryActive() = %+v, want system node shadowing %v", tools, want)
+	}
+
+	// The override is explained by ZERB's copy coming later in PATH
+	baseline := []ToolSpec{{Name: "node", Version: "20.11.0"}}
+	managed := []Tool{{Name: "node", Version: "20.11.0", Path: filepath.Join(managedDir, "node")}}
+	results := DetectDrift(baseline, nil, managed, tools, zerbDir)
+	if len(results) != 1 || results[0].DriftType != DriftExternalOverride {
+		t.Fatalf("DetectDrift() = %+v, want one external override", results)
+	}
+	if results[0].PathOrder != PathOrderManagedLater {
+		t.Errorf("PathOrder = %v, want %v", results[0].PathOrder, PathOrderManagedLater)
+	}
+
+	report := formatDriftEntry(results[0])
+	for _, s := range []string{"PATH order:", "1. " + want[0], "2. " + want[1], "shadowed by an earlier PATH entry"} {
+		if !strings.Contains(report, s) {
+			t.Errorf("report missing %q:\n%s", s, report)
+		}
+	}
+}
+
+func TestClassifyPathOrder(t *testing.T) {
+	zerbDir := "/home/user/.config/zerb"
+	managed := zerbDir + "/installs/node/20.11.0/bin/node"
+
+	tests := []struct {
+		name   string
+		active Tool
+		want   PathOrder
+	}{
+		{
+			name:   "no paths recorded",
+			active: Tool{Path: "/usr/bin/node"},
+			want:   PathOrderUnknown,
+		},
+		{
+			name:   "managed copy not in PATH",
+			active: Tool{Path: "/usr/bin/node", AllPaths: []string{"/usr/bin/node", "/usr/local/bin/node"}},
+			want:   PathOrderManagedAbsent,
+		},
+		{
+			name:   "managed copy shadowed",
+			active: Tool{Path: "/usr/bin/node", AllPaths: []string{"/usr/bin/node", managed}},
+			want:   PathOrderManagedLater,
+		},
+		{
+			name:   "managed copy first but another runs",
+			active: Tool{Path: "/usr/bin/node", AllPaths: []string{managed, "/usr/bin/node"}},
+			want:   PathOrderManagedEarlier,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyPathOrder(tt.active, zerbDir); got != tt.want {
+				t.Errorf("classifyPathOrder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package drift
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+)
+
+// ToolCommand is a tool install or uninstall that reverting a drift runs.
+type ToolCommand struct {
+	Operation string // "install" or "uninstall"
+	ToolSpec  string // name@version
+}
+
+// String returns the command as "<operation> <tool>@<version>".
+func (c ToolCommand) String() string {
+	return c.Operation + " " + c.ToolSpec
+}
+
+// ApplyFailure records a tool command that failed while applying a batch.
+type ApplyFailure struct {
+	Tool    string
+	Command ToolCommand
+	Err     error
+}
+
+// ApplyOptions controls how ApplyDriftActions runs a batch.
+type ApplyOptions struct {
+	// DryRun only returns the plan, without writing files or running tool commands
+	DryRun bool
+	// FailFast stops at the first failed tool command instead of continuing
+	// with the rest of the batch
+	FailFast bool
+}
+
+// ApplyPlan describes what applying a batch of drift actions does: the
+// baseline changes from adopted drifts and the tool commands from reverted ones.
+type ApplyPlan struct {
+	// Adopted holds the drifts whose resolution updates the baseline
+	Adopted []DriftResult
+	// Commands holds the tool commands that revert drifts, in order
+	Commands []ToolCommand
+	// Diff is the change from the current config to the config that adopting writes
+	Diff *config.Diff
+	// ConfigVersion is the new config filename (empty for a dry run or when nothing is adopted)
+	ConfigVersion string
+	// Applied holds the tool commands that succeeded
+	Applied []ToolCommand
+	// Failures holds the tool commands that failed, with the reason
+	Failures []ApplyFailure
+}
+
+// Resolved returns the number of drifts the batch resolved: every adopted
+// drift once the baseline is written, plus every successful tool command.
+func (p *ApplyPlan) Resolved() int {
+	resolved := len(p.Applied)
+	if p.ConfigVersion != "" {
+		resolved += len(p.Adopted)
+	}
+	return resolved
+}
+
+// PlanDriftActions computes the outcome of applying actions[i] to results[i]
+// without writing files or running any tool commands.
+func PlanDriftActions(ctx context.Context, results []DriftResult, actions []DriftAction, configPath string) (*ApplyPlan, error) {
+	if len(results) != len(actions) {
+		return nil, fmt.Errorf("got %d drift results but %d actions", len(results), len(actions))
+	}
+
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+
+	parser := config.NewParser(nil)
+	current, err := parser.ParseString(ctx, string(content))
+	if err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+
+	plan := &ApplyPlan{}
+	proposed := *current
+	proposed.Tools = append([]string(nil), current.Tools...)
+	if current.Profiles != nil {
+		proposed.Profiles = make(map[string][]string, len(current.Profiles))
+		for name, tools := range current.Profiles {
+			proposed.Profiles[name] = append([]string(nil), tools...)
+		}
+	}
+	active := config.ProfilesFromEnv()
+
+	for i, result := range results {
+		switch actions[i] {
+		case ActionAdopt:
+			if result.DriftType == DriftExtra {
+				if err := validateToolName(result.Tool); err != nil {
+					return nil, fmt.Errorf("invalid tool name: %w", err)
+				}
+				if err := validateVersion(result.ManagedVersion); err != nil {
+					return nil, fmt.Errorf("invalid version for %s: %w", result.Tool, err)
+				}
+			}
+			adoptIntoConfig(&proposed, active, result)
+			plan.Adopted = append(plan.Adopted, result)
+		case ActionRevert:
+			cmd, ok, err := revertCommand(result)
+			if err != nil {
+				return nil, fmt.Errorf("plan revert for %s: %w", result.Tool, err)
+			}
+			if ok {
+				plan.Commands = append(plan.Commands, cmd)
+			}
+		case ActionSkip:
+			// No action
+		default:
+			return nil, fmt.Errorf("unknown action: %v", actions[i])
+		}
+	}
+
+	plan.Diff = config.DiffConfigs(current, &proposed)
+	return plan, nil
+}
+
+// ApplyDriftActions applies actions[i] to results[i] as one batch: the
+// revert commands run in order, then every adopted drift goes into a single
+// new timestamped config. The commands share the tool manager's data
+// directory, so they run one at a time; each install uses up to
+// Parallelism() jobs. A failed command doesn't stop the batch; it is
+// recorded in the plan's Failures and the rest still apply. With
+// opts.FailFast, the first failed command aborts the batch before the new
+// config is written. With opts.DryRun, it only returns the plan, without
+// writing files or running tool commands.
+func ApplyDriftActions(ctx context.Context, results []DriftResult, actions []DriftAction, configPath, zerbDir, miseBinary string, opts ApplyOptions) (*ApplyPlan, error) {
+	plan, err := PlanDriftActions(ctx, results, actions, configPath)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return plan, nil
+	}
+
+	// errs[i] is the outcome of plan.Commands[i]
+	errs := make([]error, len(plan.Commands))
+	for i, cmd := range plan.Commands {
+		errs[i] = executeMiseInstallOrUninstall(ctx, miseBinary, zerbDir, cmd.Operation, cmd.ToolSpec)
+		if errs[i] != nil && opts.FailFast {
+			return plan, fmt.Errorf("%s: %w", cmd, errs[i])
+		}
+	}
+
+	for i, cmd := range plan.Commands {
+		if errs[i] != nil {
+			plan.Failures = append(plan.Failures, ApplyFailure{Tool: specTool(cmd.ToolSpec), Command: cmd, Err: errs[i]})
+			continue
+		}
+		plan.Applied = append(plan.Applied, cmd)
+	}
+
+	if len(plan.Adopted) > 0 {
+		plan.ConfigVersion, err = adoptResults(plan.Adopted, configPath, zerbDir, clock.Real{})
+		if err != nil {
+			return plan, fmt.Errorf("adopt: %w", err)
+		}
+	}
+
+	return plan, nil
+}
+
+// specTool returns the tool of a [backend:]name[@version] spec. The version
+// follows the last "@" after the backend, as names may contain one
+// themselves: "npm:@scope/pkg@1.0" is the tool "npm:@scope/pkg".
+func specTool(spec string) string {
+	prefix, name := "", spec
+	if backend, rest, ok := strings.Cut(spec, ":"); ok {
+		prefix, name = backend+":", rest
+	}
+	if idx := strings.LastIndex(name, "@"); idx > 0 {
+		name = name[:idx]
+	}
+	return prefix + name
+}
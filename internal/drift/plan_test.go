@@ -0,0 +1,272 @@
+package drift
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+)
+
+// setupPlanTest creates a ZERB directory with an active config declaring
+// node and python, and a mock mise that records every invocation.
+// Returns the zerb dir, the active config symlink and the invocation log path.
+func setupPlanTest(t *testing.T) (zerbDir, configPath, logPath string) {
+	t.Helper()
+
+	zerbDir = t.TempDir()
+	configsDir := filepath.Join(zerbDir, "configs")
+	binDir := filepath.Join(zerbDir, "bin")
+	for _, dir := range []string{configsDir, binDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+	}
+
+	initialFilename := "zerb.20250113T120000.000Z.lua"
+	initialConfig := `zerb = {
+  tools = {
+    "node@20.11.0",
+    "python@3.12.1",
+  }
+}`
+	if err := os.WriteFile(filepath.Join(configsDir, initialFilename), []byte(initialConfig), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(zerbDir, ".zerb-active"), []byte(initialFilename), 0600); err != nil {
+		t.Fatalf("failed to write marker: %v", err)
+	}
+	configPath = filepath.Join(zerbDir, "zerb.active.lua")
+	if err := os.Symlink(filepath.Join("configs", initialFilename), configPath); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	logPath = filepath.Join(t.TempDir(), "mise.log")
+	script := "#!/bin/sh\necho \"$@\" >> " + logPath + "\n"
+	if err := os.WriteFile(filepath.Join(binDir, "mise"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to create mock mise: %v", err)
+	}
+
+	return zerbDir, configPath, logPath
+}
+
+// planTestDrifts returns a version mismatch and an extra to adopt, and a
+// missing tool to revert.
+func planTestDrifts() ([]DriftResult, []DriftAction) {
+	results := []DriftResult{
+		{Tool: "node", DriftType: DriftVersionMismatch, BaselineVersion: "20.11.0", ManagedVersion: "20.11.0", ActiveVersion: "22.1.0"},
+		{Tool: "python", DriftType: DriftMissing, BaselineVersion: "3.12.1"},
+		{Tool: "ripgrep", DriftType: DriftExtra, ManagedVersion: "14.1.0"},
+	}
+	actions := []DriftAction{ActionAdopt, ActionRevert, ActionAdopt}
+	return results, actions
+}
+
+func TestApplyDriftActions_DryRun(t *testing.T) {
+	zerbDir, configPath, logPath := setupPlanTest(t)
+	results, actions := planTestDrifts()
+
+	entriesBefore, _ := os.ReadDir(filepath.Join(zerbDir, "configs"))
+	markerBefore, _ := os.ReadFile(filepath.Join(zerbDir, ".zerb-active"))
+
+	plan, err := ApplyDriftActions(context.Background(), results, actions, configPath, zerbDir, filepath.Join(zerbDir, "bin", "mise"), ApplyOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("ApplyDriftActions() error = %v", err)
+	}
+
+	wantDiff := &config.Diff{Tools: config.ToolsDiff{
+		Added:   []string{"ripgrep@14.1.0"},
+		Changed: []config.ToolChange{{Name: "node", From: "20.11.0", To: "22.1.0"}},
+	}}
+	if !reflect.DeepEqual(plan.Diff, wantDiff) {
+		t.Errorf("Diff = %+v, want %+v", plan.Diff, wantDiff)
+	}
+
+	wantCommands := []ToolCommand{{Operation: "install", ToolSpec: "python@3.12.1"}}
+	if !reflect.DeepEqual(plan.Commands, wantCommands) {
+		t.Errorf("Commands = %+v, want %+v", plan.Commands, wantCommands)
+	}
+	if plan.ConfigVersion != "" {
+		t.Errorf("ConfigVersion = %q, want empty for dry run", plan.ConfigVersion)
+	}
+
+	// No tool commands ran
+	if _, err := os.Stat(logPath); !os.IsNotExist(err) {
+		t.Error("dry run invoked the tool manager")
+	}
+
+	// No files written
+	entriesAfter, _ := os.ReadDir(filepath.Join(zerbDir, "configs"))
+	if len(entriesAfter) != len(entriesBefore) {
+		t.Errorf("configs dir has %d entries after dry run, want %d", len(entriesAfter), len(entriesBefore))
+	}
+	markerAfter, _ := os.ReadFile(filepath.Join(zerbDir, ".zerb-active"))
+	if string(markerAfter) != string(markerBefore) {
+		t.Errorf("marker changed during dry run: %q -> %q", markerBefore, markerAfter)
+	}
+}
+
+func TestApplyDriftActions_Apply(t *testing.T) {
+	zerbDir, configPath, logPath := setupPlanTest(t)
+	results, actions := planTestDrifts()
+
+	plan, err := ApplyDriftActions(context.Background(), results, actions, configPath, zerbDir, filepath.Join(zerbDir, "bin", "mise"), ApplyOptions{})
+	if err != nil {
+		t.Fatalf("ApplyDriftActions() error = %v", err)
+	}
+	if plan.ConfigVersion == "" {
+		t.Fatal("ConfigVersion is empty, want new snapshot")
+	}
+
+	log, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read invocation log: %v", err)
+	}
+	if strings.TrimSpace(string(log)) != "install python@3.12.1" {
+		t.Errorf("tool manager invocations = %q, want %q", log, "install python@3.12.1")
+	}
+
+	content, err := os.ReadFile(filepath.Join(zerbDir, "configs", plan.ConfigVersion))
+	if err != nil {
+		t.Fatalf("failed to read new config: %v", err)
+	}
+	cfg, err := config.NewParser(nil).ParseString(context.Background(), string(content))
+	if err != nil {
+		t.Fatalf("failed to parse new config: %v", err)
+	}
+	want := []string{"node@22.1.0", "python@3.12.1", "ripgrep@14.1.0"}
+	if !reflect.DeepEqual(cfg.Tools, want) {
+		t.Errorf("Tools = %v, want %v", cfg.Tools, want)
+	}
+}
+
+func TestPlanDriftActions_Errors(t *testing.T) {
+	_, configPath, _ := setupPlanTest(t)
+	ctx := context.Background()
+
+	results := []DriftResult{{Tool: "node", DriftType: DriftManagedButNotActive, BaselineVersion: "20.11.0"}}
+	if _, err := PlanDriftActions(ctx, results, []DriftAction{ActionRevert}, configPath); err == nil {
+		t.Error("PlanDriftActions() should fail to revert a PATH issue")
+	}
+
+	if _, err := PlanDriftActions(ctx, results, nil, configPath); err == nil {
+		t.Error("PlanDriftActions() should fail when actions don't match results")
+	}
+}
+
+func TestApplyDriftActions_PartialFailure(t *testing.T) {
+	tests := []struct {
+		name         string
+		failFast     bool
+		wantErr      bool
+		wantLog      string
+		wantApplied  []ToolCommand
+		wantFailures []string
+		wantSnapshot bool
+	}{
+		{
+			name:         "continues past failure",
+			wantLog:      "install node@20.11.0\ninstall python@3.12.1",
+			wantApplied:  []ToolCommand{{Operation: "install", ToolSpec: "python@3.12.1"}},
+			wantFailures: []string{"node"},
+			wantSnapshot: true,
+		},
+		{
+			name:         "fail fast",
+			failFast:     true,
+			wantErr:      true,
+			wantLog:      "install node@20.11.0",
+			wantSnapshot: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zerbDir, configPath, logPath := setupPlanTest(t)
+
+			// Installing node fails; everything else succeeds
+			script := "#!/bin/sh\necho \"$@\" >> " + logPath + "\ncase \"$2\" in node@*) echo 'download failed' >&2; exit 1;; esac\n"
+			if err := os.WriteFile(filepath.Join(zerbDir, "bin", "mise"), []byte(script), 0755); err != nil {
+				t.Fatalf("failed to create mock mise: %v", err)
+			}
+
+			results := []DriftResult{
+				{Tool: "node", DriftType: DriftMissing, BaselineVersion: "20.11.0"},
+				{Tool: "python", DriftType: DriftMissing, BaselineVersion: "3.12.1"},
+				{Tool: "ripgrep", DriftType: DriftExtra, ManagedVersion: "14.1.0"},
+			}
+			actions := []DriftAction{ActionRevert, ActionRevert, ActionAdopt}
+
+			plan, err := ApplyDriftActions(context.Background(), results, actions, configPath, zerbDir, filepath.Join(zerbDir, "bin", "mise"), ApplyOptions{FailFast: tt.failFast})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ApplyDriftActions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			log, _ := os.ReadFile(logPath)
+			if got := strings.TrimSpace(string(log)); got != tt.wantLog {
+				t.Errorf("tool manager invocations = %q, want %q", got, tt.wantLog)
+			}
+			if !reflect.DeepEqual(plan.Applied, tt.wantApplied) {
+				t.Errorf("Applied = %+v, want %+v", plan.Applied, tt.wantApplied)
+			}
+
+			var failed []string
+			for _, f := range plan.Failures {
+				failed = append(failed, f.Tool)
+				if f.Err == nil || !strings.Contains(f.Err.Error(), "download failed") {
+					t.Errorf("failure for %s has reason %v, want the tool manager output", f.Tool, f.Err)
+				}
+			}
+			if !reflect.DeepEqual(failed, tt.wantFailures) {
+				t.Errorf("failed tools = %v, want %v", failed, tt.wantFailures)
+			}
+
+			if (plan.ConfigVersion != "") != tt.wantSnapshot {
+				t.Fatalf("ConfigVersion = %q, want snapshot %v", plan.ConfigVersion, tt.wantSnapshot)
+			}
+			if !tt.wantSnapshot {
+				return
+			}
+			if plan.Resolved() != 2 {
+				t.Errorf("Resolved() = %d, want 2", plan.Resolved())
+			}
+			content, err := os.ReadFile(filepath.Join(zerbDir, "configs", plan.ConfigVersion))
+			if err != nil {
+				t.Fatalf("failed to read new config: %v", err)
+			}
+			cfg, err := config.NewParser(nil).ParseString(context.Background(), string(content))
+			if err != nil {
+				t.Fatalf("failed to parse new config: %v", err)
+			}
+			want := []string{"node@20.11.0", "python@3.12.1", "ripgrep@14.1.0"}
+			if !reflect.DeepEqual(cfg.Tools, want) {
+				t.Errorf("Tools = %v, want %v", cfg.Tools, want)
+			}
+		})
+	}
+}
+
+func TestSpecTool(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"node@20.11.0", "node"},
+		{"node", "node"},
+		{"cargo:ripgrep@14.1.0", "cargo:ripgrep"},
+		{"npm:@scope/pkg@1.0", "npm:@scope/pkg"},
+		{"npm:@scope/pkg", "npm:@scope/pkg"},
+		{"ubi:sharkdp/bat@0.24.0", "ubi:sharkdp/bat"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			if got := specTool(tt.spec); got != tt.want {
+				t.Errorf("specTool(%q) = %q, want %q", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
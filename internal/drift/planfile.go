@@ -0,0 +1,165 @@
+package drift
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// PlanFileVersion is the format version of drift plan files
+const PlanFileVersion = 1
+
+// PlanFile is a reviewable list of drift resolutions, written by
+// `zerb drift --plan-out` and applied in order by `zerb drift --apply`.
+type PlanFile struct {
+	Version int         `json:"version"`
+	Entries []PlanEntry `json:"entries"`
+}
+
+// PlanEntry is a drift and the action that resolves it. DriftType and
+// Action hold the names printed by DriftType.String and
+// DriftAction.PlanName, e.g. "MISSING" and "revert".
+type PlanEntry struct {
+	Tool            string `json:"tool"`
+	DriftType       string `json:"drift_type"`
+	BaselineVersion string `json:"baseline_version,omitempty"`
+	ManagedVersion  string `json:"managed_version,omitempty"`
+	ActiveVersion   string `json:"active_version,omitempty"`
+	ActivePath      string `json:"active_path,omitempty"`
+	Action          string `json:"action"`
+}
+
+// PlanName returns the action's name in plan files
+func (a DriftAction) PlanName() string {
+	return strings.ToLower(a.String())
+}
+
+// parsePlanAction returns the action named name in a plan file
+func parsePlanAction(name string) (DriftAction, error) {
+	for _, a := range []DriftAction{ActionAdopt, ActionRevert, ActionSkip} {
+		if name == a.PlanName() {
+			return a, nil
+		}
+	}
+	return ActionSkip, fmt.Errorf("unknown action %q (expected adopt, revert or skip)", name)
+}
+
+// parseDriftType returns the drift type named name, as printed by String
+func parseDriftType(name string) (DriftType, error) {
+	for d := DriftOK; d <= DriftInactiveProfile; d++ {
+		if name == d.String() {
+			return d, nil
+		}
+	}
+	return DriftOK, fmt.Errorf("unknown drift type %q", name)
+}
+
+// NewPlanFile returns a plan resolving every drift in results with its
+// DefaultDriftAction. OK results are left out.
+func NewPlanFile(results []DriftResult) *PlanFile {
+	plan := &PlanFile{Version: PlanFileVersion, Entries: []PlanEntry{}}
+	for _, r := range results {
+		if r.DriftType == DriftOK {
+			continue
+		}
+		plan.Entries = append(plan.Entries, PlanEntry{
+			Tool:            r.Tool,
+			DriftType:       r.DriftType.String(),
+			BaselineVersion: r.BaselineVersion,
+			ManagedVersion:  r.ManagedVersion,
+			ActiveVersion:   r.ActiveVersion,
+			ActivePath:      r.ActivePath,
+			Action:          DefaultDriftAction(r.DriftType).PlanName(),
+		})
+	}
+	return plan
+}
+
+// WritePlanFile writes plan as indented JSON
+func WritePlanFile(w io.Writer, plan *PlanFile) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(plan); err != nil {
+		return fmt.Errorf("encode plan: %w", err)
+	}
+	return nil
+}
+
+// ReadPlanFile reads and validates a plan. Unknown fields, versions, drift
+// types and actions are rejected, as are actions that cannot apply to their
+// drift (e.g. reverting a PATH issue), so a bad edit fails before anything
+// is changed.
+func ReadPlanFile(r io.Reader) (*PlanFile, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	var plan PlanFile
+	if err := dec.Decode(&plan); err != nil {
+		return nil, fmt.Errorf("parse plan: %w", err)
+	}
+	if plan.Version != PlanFileVersion {
+		return nil, fmt.Errorf("unsupported plan version %d (expected %d)", plan.Version, PlanFileVersion)
+	}
+
+	for i, entry := range plan.Entries {
+		if _, _, err := entry.resolve(); err != nil {
+			return nil, fmt.Errorf("entry %d (%s): %w", i+1, entry.Tool, err)
+		}
+	}
+	return &plan, nil
+}
+
+// resolve converts the entry to the drift and action it describes
+func (e PlanEntry) resolve() (DriftResult, DriftAction, error) {
+	if err := validateToolName(e.Tool); err != nil {
+		return DriftResult{}, ActionSkip, err
+	}
+	driftType, err := parseDriftType(e.DriftType)
+	if err != nil {
+		return DriftResult{}, ActionSkip, err
+	}
+	if driftType == DriftOK {
+		return DriftResult{}, ActionSkip, fmt.Errorf("drift type %s has nothing to resolve", e.DriftType)
+	}
+	action, err := parsePlanAction(e.Action)
+	if err != nil {
+		return DriftResult{}, ActionSkip, err
+	}
+
+	result := DriftResult{
+		Tool:            e.Tool,
+		DriftType:       driftType,
+		BaselineVersion: e.BaselineVersion,
+		ManagedVersion:  e.ManagedVersion,
+		ActiveVersion:   e.ActiveVersion,
+		ActivePath:      e.ActivePath,
+	}
+	if action == ActionRevert {
+		if _, _, err := revertCommand(result); err != nil {
+			return DriftResult{}, ActionSkip, fmt.Errorf("cannot revert: %w", err)
+		}
+	}
+	return result, action, nil
+}
+
+// ApplyPlanFile applies each entry of plan in order with ApplyDriftAction,
+// stopping at the first error. It returns the entries applied before the
+// error (skipped entries included), so callers can report exactly where
+// the plan stopped. With opts.DryRun, each entry is only validated and
+// described.
+func ApplyPlanFile(ctx context.Context, plan *PlanFile, configPath, zerbDir, miseBinary string, opts ActionOptions) ([]PlanEntry, error) {
+	var applied []PlanEntry
+	for i, entry := range plan.Entries {
+		result, action, err := entry.resolve()
+		if err != nil {
+			return applied, fmt.Errorf("entry %d (%s): %w", i+1, entry.Tool, err)
+		}
+		if err := ApplyDriftAction(ctx, result, action, configPath, zerbDir, miseBinary, opts); err != nil {
+			return applied, fmt.Errorf("entry %d (%s %s): %w", i+1, entry.Action, entry.Tool, err)
+		}
+		applied = append(applied, entry)
+	}
+	return applied, nil
+}
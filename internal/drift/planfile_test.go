@@ -0,0 +1,132 @@
+package drift
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPlanFile_RoundTrip(t *testing.T) {
+	results := []DriftResult{
+		{Tool: "node", DriftType: DriftOK, BaselineVersion: "20.11.0"},
+		{Tool: "python", DriftType: DriftMissing, BaselineVersion: "3.12.1"},
+		{Tool: "go", DriftType: DriftVersionMismatch, BaselineVersion: "1.22.0", ManagedVersion: "1.22.0", ActiveVersion: "1.23.0"},
+		{Tool: "jq", DriftType: DriftManagedButNotActive, BaselineVersion: "1.7", ManagedVersion: "1.7"},
+	}
+
+	plan := NewPlanFile(results)
+	var actions []string
+	for _, entry := range plan.Entries {
+		actions = append(actions, entry.Tool+"="+entry.Action)
+	}
+	// OK results are left out; each drift gets its default action
+	want := []string{"python=revert", "go=adopt", "jq=skip"}
+	if !reflect.DeepEqual(actions, want) {
+		t.Errorf("NewPlanFile() actions = %v, want %v", actions, want)
+	}
+
+	var buf bytes.Buffer
+	if err := WritePlanFile(&buf, plan); err != nil {
+		t.Fatalf("WritePlanFile() error = %v", err)
+	}
+	got, err := ReadPlanFile(&buf)
+	if err != nil {
+		t.Fatalf("ReadPlanFile() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, plan) {
+		t.Errorf("ReadPlanFile() = %+v, want %+v", got, plan)
+	}
+}
+
+func TestReadPlanFile_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		plan    string
+		wantErr string
+	}{
+		{
+			name:    "unknown action",
+			plan:    `{"version": 1, "entries": [{"tool": "node", "drift_type": "MISSING", "baseline_version": "20.11.0", "action": "upgrade"}]}`,
+			wantErr: `entry 1 (node): unknown action "upgrade"`,
+		},
+		{
+			name:    "unknown drift type",
+			plan:    `{"version": 1, "entries": [{"tool": "node", "drift_type": "BROKEN", "action": "skip"}]}`,
+			wantErr: `unknown drift type "BROKEN"`,
+		},
+		{
+			name:    "no drift",
+			plan:    `{"version": 1, "entries": [{"tool": "node", "drift_type": "OK", "action": "adopt"}]}`,
+			wantErr: "has nothing to resolve",
+		},
+		{
+			name:    "revert a PATH issue",
+			plan:    `{"version": 1, "entries": [{"tool": "jq", "drift_type": "MANAGED_BUT_NOT_ACTIVE", "baseline_version": "1.7", "action": "revert"}]}`,
+			wantErr: "cannot revert",
+		},
+		{
+			name:    "unsafe tool name",
+			plan:    `{"version": 1, "entries": [{"tool": "node; rm -rf ~", "drift_type": "MISSING", "action": "skip"}]}`,
+			wantErr: "invalid tool name",
+		},
+		{
+			name:    "unsupported version",
+			plan:    `{"version": 2, "entries": []}`,
+			wantErr: "unsupported plan version 2",
+		},
+		{
+			name:    "unknown field",
+			plan:    `{"version": 1, "entries": [{"tool": "node", "drift_type": "MISSING", "action": "skip", "force": true}]}`,
+			wantErr: "unknown field",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadPlanFile(strings.NewReader(tt.plan))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ReadPlanFile() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyPlanFile_StopsOnFirstError(t *testing.T) {
+	zerbDir, configPath, logPath := setupPlanTest(t)
+
+	// Installing node fails
+	script := "#!/bin/sh\necho \"$@\" >> " + logPath + "\ncase \"$2\" in node@*) exit 1;; esac\n"
+	if err := os.WriteFile(filepath.Join(zerbDir, "bin", "mise"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to create mock mise: %v", err)
+	}
+
+	plan := &PlanFile{Version: PlanFileVersion, Entries: []PlanEntry{
+		{Tool: "python", DriftType: "MISSING", BaselineVersion: "3.12.1", Action: "revert"},
+		{Tool: "ripgrep", DriftType: "EXTRA", ManagedVersion: "14.1.0", Action: "skip"},
+		{Tool: "node", DriftType: "MISSING", BaselineVersion: "20.11.0", Action: "revert"},
+		{Tool: "jq", DriftType: "EXTRA", ManagedVersion: "1.7", Action: "adopt"},
+	}}
+
+	applied, err := ApplyPlanFile(context.Background(), plan, configPath, zerbDir, filepath.Join(zerbDir, "bin", "mise"), ActionOptions{})
+	if err == nil || !strings.Contains(err.Error(), "entry 3 (revert node)") {
+		t.Fatalf("ApplyPlanFile() error = %v, want failure at entry 3", err)
+	}
+	if !reflect.DeepEqual(applied, plan.Entries[:2]) {
+		t.Errorf("applied = %+v, want the first two entries", applied)
+	}
+
+	log, _ := os.ReadFile(logPath)
+	if got := strings.TrimSpace(string(log)); got != "install python@3.12.1\ninstall node@20.11.0" {
+		t.Errorf("tool manager invocations = %q", got)
+	}
+
+	// The entry after the failure was not attempted
+	entries, _ := os.ReadDir(filepath.Join(zerbDir, "configs"))
+	if len(entries) != 1 {
+		t.Errorf("configs dir has %d entries, want only the initial config", len(entries))
+	}
+}
@@ -0,0 +1,111 @@
+package drift
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+)
+
+// VersionProbe is a tool-specific rule for reading a tool's version, for
+// tools that don't answer --version or -v, or whose output the default
+// semantic version match misreads.
+type VersionProbe struct {
+	// Args make the tool print its version, e.g. ["-version"]
+	Args []string
+	// Pattern extracts the version from the combined stdout and stderr: its
+	// first capture group if it has one, else the whole match
+	Pattern *regexp.Regexp
+}
+
+// Extract returns the version in output.
+func (p VersionProbe) Extract(output string) (string, error) {
+	match := p.Pattern.FindStringSubmatch(output)
+	if match == nil {
+		return "", fmt.Errorf("no version found in output")
+	}
+	if len(match) > 1 {
+		return match[1], nil
+	}
+	return match[0], nil
+}
+
+// key identifies the rule, so cached versions are only reused by the rule
+// that detected them
+func (p VersionProbe) key() string {
+	return strings.Join(p.Args, " ") + "\x00" + p.Pattern.String()
+}
+
+// VersionProbes maps canonical tool names to their version probes.
+type VersionProbes map[string]VersionProbe
+
+// DefaultVersionProbes returns the built-in version probes.
+func DefaultVersionProbes() VersionProbes {
+	return VersionProbes{
+		// java -version prints `openjdk version "21.0.2" 2024-01-16` to stderr
+		"java": {Args: []string{"-version"}, Pattern: regexp.MustCompile(`version "([^"]+)"`)},
+		// go version prints `go version go1.22.0 linux/amd64`
+		"go": {Args: []string{"version"}, Pattern: regexp.MustCompile(`go(\d+\.\d+(?:\.\d+)?(?:[a-z]+\d+)?)`)},
+		// kubectl has no --version; the client version is `Client Version: v1.29.0`
+		"kubectl": {Args: []string{"version", "--client"}, Pattern: regexp.MustCompile(`Client Version: v?(\d+\.\d+\.\d+)`)},
+	}
+}
+
+// NewVersionProbes returns the built-in version probes with the probes of a
+// config added, replacing built-in probes for the same tool. A config probe
+// without a regex uses the default semantic version match.
+func NewVersionProbes(configured map[string]config.VersionProbe) (VersionProbes, error) {
+	probes := DefaultVersionProbes()
+	for name, cp := range configured {
+		args := strings.Fields(cp.Flag)
+		if len(args) == 0 {
+			return nil, fmt.Errorf("version probe for %s: flag cannot be empty", name)
+		}
+		pattern := versionRegex
+		if cp.Regex != "" {
+			var err error
+			pattern, err = regexp.Compile(cp.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("version probe for %s: invalid regex: %w", name, err)
+			}
+		}
+		probes[toolKey(name)] = VersionProbe{Args: args, Pattern: pattern}
+	}
+	return probes, nil
+}
+
+// LoadVersionProbes returns the version probes for the config at configPath:
+// the built-in probes plus those of its version_probe table.
+func LoadVersionProbes(ctx context.Context, configPath string) (VersionProbes, error) {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+	cfg, err := config.NewParser(nil).ParseString(ctx, string(content))
+	if err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+	return NewVersionProbes(cfg.VersionProbes)
+}
+
+// lookup returns the probe for the named tool, or nil if none is registered
+func (p VersionProbes) lookup(name string) *VersionProbe {
+	probe, ok := p[toolKey(name)]
+	if !ok {
+		return nil
+	}
+	return &probe
+}
+
+// versionCacheKey returns the key a version detected for binaryPath is
+// cached under. Versions detected by a probe are keyed by the probe as
+// well, so changing the rule invalidates them.
+func versionCacheKey(binaryPath string, probe *VersionProbe) string {
+	if probe == nil {
+		return binaryPath
+	}
+	return binaryPath + "\x00" + probe.key()
+}
@@ -0,0 +1,125 @@
+package drift
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+)
+
+func TestDefaultVersionProbes_Extract(t *testing.T) {
+	tests := []struct {
+		tool   string
+		output string
+		want   string
+	}{
+		{"java", "openjdk version \"21.0.2\" 2024-01-16\nOpenJDK Runtime Environment (build 21.0.2+13-58)", "21.0.2"},
+		{"java", "java version \"1.8.0_392\"\nJava(TM) SE Runtime Environment", "1.8.0_392"},
+		{"go", "go version go1.22.0 linux/amd64", "1.22.0"},
+		{"go", "go version go1.21 darwin/arm64", "1.21"},
+		{"kubectl", "Client Version: v1.29.0\nKustomize Version: v5.0.4-0.20230601165947-6ce0bf390ce3", "1.29.0"},
+	}
+
+	probes := DefaultVersionProbes()
+	for _, tt := range tests {
+		t.Run(tt.tool+" "+tt.want, func(t *testing.T) {
+			got, err := probes[tt.tool].Extract(tt.output)
+			if err != nil {
+				t.Fatalf("Extract() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Extract() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewVersionProbes(t *testing.T) {
+	probes, err := NewVersionProbes(map[string]config.VersionProbe{
+		"java":   {Flag: "--version", Regex: `openjdk (\S+)`},
+		"mytool": {Flag: "version --short"},
+	})
+	if err != nil {
+		t.Fatalf("NewVersionProbes() error = %v", err)
+	}
+
+	// A configured probe replaces the built-in one
+	if got := probes["java"].Args; len(got) != 1 || got[0] != "--version" {
+		t.Errorf("java Args = %v, want [--version]", got)
+	}
+	// Flags are split into arguments; no regex uses the default match
+	mytool := probes["mytool"]
+	if len(mytool.Args) != 2 || mytool.Args[1] != "--short" {
+		t.Errorf("mytool Args = %v, want [version --short]", mytool.Args)
+	}
+	if got, _ := mytool.Extract("mytool 3.4.5 (abc)"); got != "3.4.5" {
+		t.Errorf("mytool Extract() = %q, want 3.4.5", got)
+	}
+	// Other built-in probes are kept
+	if _, ok := probes["go"]; !ok {
+		t.Error("built-in go probe missing")
+	}
+
+	if _, err := NewVersionProbes(map[string]config.VersionProbe{"bad": {Flag: "-v", Regex: "("}}); err == nil {
+		t.Error("NewVersionProbes() with invalid regex succeeded, want error")
+	}
+}
+
+func TestDetectVersion_Probe(t *testing.T) {
+	// Like java: only -version works, and it prints to stderr
+	tmpDir := t.TempDir()
+	script := `#!/bin/sh
+if [ "$1" = "-version" ]; then
+    echo 'openjdk version "17.0.10" 2024-01-16' >&2
+    echo 'OpenJDK 64-Bit Server VM (build 17.0.10+7, mixed mode)' >&2
+    exit 0
+fi
+echo "Unrecognized option: $1" >&2
+exit 1
+`
+	javaPath := filepath.Join(tmpDir, "java")
+	if err := os.WriteFile(javaPath, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to create test binary: %v", err)
+	}
+
+	version, err := DetectVersion(context.Background(), javaPath)
+	if err != nil {
+		t.Fatalf("DetectVersion() error = %v", err)
+	}
+	if version != "17.0.10" {
+		t.Errorf("DetectVersion() = %q, want 17.0.10", version)
+	}
+
+	// Without a probe the tool is not understood
+	opts := VersionOptions{Probes: VersionProbes{}, Cache: NewVersionCache()}
+	if _, err := DetectVersionWithOptions(context.Background(), javaPath, true, opts); err == nil {
+		t.Error("DetectVersionWithOptions() without a probe succeeded, want error")
+	}
+}
+
+func TestDetectVersionWithOptions_ProbeChange(t *testing.T) {
+	tmpDir := t.TempDir()
+	script := "#!/bin/sh\necho 'tool 1.0.0 (api 2.0.0)'\n"
+	toolPath := filepath.Join(tmpDir, "tool")
+	if err := os.WriteFile(toolPath, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to create test binary: %v", err)
+	}
+	cache := NewVersionCache()
+
+	opts := VersionOptions{
+		Probes: VersionProbes{"tool": {Args: []string{"info"}, Pattern: regexp.MustCompile(`tool (\S+)`)}},
+		Cache:  cache,
+	}
+	if version, err := DetectVersionWithOptions(context.Background(), toolPath, false, opts); err != nil || version != "1.0.0" {
+		t.Fatalf("DetectVersionWithOptions() = %q, %v, want 1.0.0", version, err)
+	}
+
+	// A changed rule must not reuse the version cached by the old one
+	opts.Probes = VersionProbes{"tool": {Args: []string{"info"}, Pattern: regexp.MustCompile(`api (\S+)\)`)}}
+	if version, err := DetectVersionWithOptions(context.Background(), toolPath, false, opts); err != nil || version != "2.0.0" {
+		t.Errorf("DetectVersionWithOptions() after rule change = %q, %v, want 2.0.0", version, err)
+	}
+}
@@ -0,0 +1,55 @@
+package drift
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
+)
+
+// FindManagedExtras compares the tools installed in ZERB's isolated tool
+// environment against the baseline and returns a DriftExtra result for each
+// installed tool that the configuration does not declare.
+//
+// This is the inverse direction of DetectDrift: instead of checking that the
+// environment matches the config, it finds tools that were installed outside
+// of ZERB's config (e.g. by running the bundled tool manager directly) so they
+// can be adopted. The extras are those DetectDrift reports, so both agree on
+// which tools match the baseline. Results are sorted by tool name.
+func FindManagedExtras(baseline []ToolSpec, managed []Tool) []DriftResult {
+	var extras []DriftResult
+	for _, result := range DetectDrift(baseline, nil, managed, nil, "") {
+		if result.DriftType == DriftExtra {
+			extras = append(extras, result)
+		}
+	}
+
+	sort.Slice(extras, func(i, j int) bool {
+		return extras[i].Tool < extras[j].Tool
+	})
+
+	return extras
+}
+
+// AdoptManagedExtras adds every extra tool to the baseline in one pass,
+// producing a single new timestamped config. It returns the new config
+// filename, or an empty string if there was nothing to adopt.
+func AdoptManagedExtras(extras []DriftResult, configPath, zerbDir string) (string, error) {
+	if len(extras) == 0 {
+		return "", nil
+	}
+
+	for _, extra := range extras {
+		if extra.DriftType != DriftExtra {
+			return "", fmt.Errorf("cannot adopt %s: drift type %s is not %s", extra.Tool, extra.DriftType, DriftExtra)
+		}
+		if err := validateToolName(extra.Tool); err != nil {
+			return "", fmt.Errorf("invalid tool name: %w", err)
+		}
+		if err := validateVersion(extra.ManagedVersion); err != nil {
+			return "", fmt.Errorf("invalid version for %s: %w", extra.Tool, err)
+		}
+	}
+
+	return adoptResults(extras, configPath, zerbDir, clock.Real{})
+}
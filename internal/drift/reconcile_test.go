@@ -0,0 +1,210 @@
+package drift
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+)
+
+func TestFindManagedExtras(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseline []ToolSpec
+		managed  []Tool
+		want     []string
+	}{
+		{
+			name:     "no extras",
+			baseline: []ToolSpec{{Name: "node", Version: "20.11.0"}},
+			managed:  []Tool{{Name: "node", Version: "20.11.0"}},
+			want:     nil,
+		},
+		{
+			name:     "extras sorted by name",
+			baseline: []ToolSpec{{Name: "node", Version: "20.11.0"}},
+			managed: []Tool{
+				{Name: "python", Version: "3.12.1"},
+				{Name: "node", Version: "20.11.0"},
+				{Name: "go", Version: "1.22.0"},
+			},
+			want: []string{"go", "python"},
+		},
+		{
+			name:     "empty baseline",
+			baseline: nil,
+			managed:  []Tool{{Name: "node", Version: "20.11.0"}},
+			want:     []string{"node"},
+		},
+		{
+			name:     "duplicate managed entries",
+			baseline: nil,
+			managed:  []Tool{{Name: "node", Version: "20.11.0"}, {Name: "node", Version: "20.11.0"}},
+			want:     []string{"node"},
+		},
+		{
+			name:     "backend tool reported by binary name",
+			baseline: []ToolSpec{{Backend: "cargo", Name: "ripgrep", Version: "14.1.0"}},
+			managed:  []Tool{{Name: "rg", Version: "14.1.0", ID: "cargo:ripgrep", Backend: "cargo"}},
+			want:     nil,
+		},
+		{
+			name:     "same name from another backend",
+			baseline: []ToolSpec{{Backend: "cargo", Name: "ripgrep", Version: "14.1.0"}},
+			managed:  []Tool{{Name: "npm:ripgrep", Version: "1.0.0", ID: "npm:ripgrep", Backend: "npm"}},
+			want:     []string{"npm:ripgrep"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindManagedExtras(tt.baseline, tt.managed)
+			if len(got) != len(tt.want) {
+				t.Fatalf("FindManagedExtras() returned %d results, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i, r := range got {
+				if r.Tool != tt.want[i] {
+					t.Errorf("result[%d].Tool = %q, want %q", i, r.Tool, tt.want[i])
+				}
+				if r.DriftType != DriftExtra {
+					t.Errorf("result[%d].DriftType = %v, want %v", i, r.DriftType, DriftExtra)
+				}
+			}
+		})
+	}
+}
+
+func TestAdoptManagedExtras_Integration(t *testing.T) {
+	tmpDir := t.TempDir()
+	configsDir := filepath.Join(tmpDir, "configs")
+	if err := os.MkdirAll(configsDir, 0755); err != nil {
+		t.Fatalf("failed to create configs dir: %v", err)
+	}
+
+	// Baseline declares only node
+	initialFilename := "zerb.20250113T120000.000Z.lua"
+	initialConfig := `zerb = {
+  tools = {
+    "node@20.11.0",
+  }
+}`
+	initialConfigPath := filepath.Join(configsDir, initialFilename)
+	if err := os.WriteFile(initialConfigPath, []byte(initialConfig), 0644); err != nil {
+		t.Fatalf("failed to write initial config: %v", err)
+	}
+	markerPath := filepath.Join(tmpDir, ".zerb-active")
+	if err := os.WriteFile(markerPath, []byte(initialFilename), 0644); err != nil {
+		t.Fatalf("failed to write marker: %v", err)
+	}
+	symlinkPath := filepath.Join(tmpDir, "zerb.active.lua")
+	if err := os.Symlink(filepath.Join("configs", initialFilename), symlinkPath); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	// Mock mise reports node plus an extra ripgrep installed outside the config
+	miseScript := `#!/bin/sh
+if [ "$1" = "ls" ] && [ "$2" = "--json" ]; then
+    cat << 'EOF'
+{
+  "node": [{"version": "20.11.0", "install_path": "/zerb/installs/node/20.11.0"}],
+  "ripgrep": [{"version": "14.1.0", "install_path": "/zerb/installs/ripgrep/14.1.0"}]
+}
+EOF
+elif [ "$1" = "ls" ] && [ "$2" = "--current" ]; then
+    cat << 'EOF'
+node     20.11.0
+ripgrep  14.1.0
+EOF
+fi
+`
+	misePath := filepath.Join(tmpDir, "bin", "mise")
+	if err := os.MkdirAll(filepath.Dir(misePath), 0755); err != nil {
+		t.Fatalf("failed to create bin dir: %v", err)
+	}
+	if err := os.WriteFile(misePath, []byte(miseScript), 0755); err != nil {
+		t.Fatalf("failed to create mock mise: %v", err)
+	}
+
+	ctx := context.Background()
+	baseline, err := QueryBaseline(ctx, symlinkPath)
+	if err != nil {
+		t.Fatalf("QueryBaseline() error = %v", err)
+	}
+	managed, err := QueryManaged(ctx, tmpDir)
+	if err != nil {
+		t.Fatalf("QueryManaged() error = %v", err)
+	}
+
+	extras := FindManagedExtras(baseline, managed)
+	if len(extras) != 1 || extras[0].Tool != "ripgrep" {
+		t.Fatalf("FindManagedExtras() = %+v, want single ripgrep extra", extras)
+	}
+
+	newFilename, err := AdoptManagedExtras(extras, symlinkPath, tmpDir)
+	if err != nil {
+		t.Fatalf("AdoptManagedExtras() error = %v", err)
+	}
+	if newFilename == "" || newFilename == initialFilename {
+		t.Fatalf("AdoptManagedExtras() filename = %q, want new snapshot", newFilename)
+	}
+
+	// Marker should point at the new snapshot
+	markerContent, err := os.ReadFile(markerPath)
+	if err != nil {
+		t.Fatalf("failed to read marker: %v", err)
+	}
+	if strings.TrimSpace(string(markerContent)) != newFilename {
+		t.Errorf("marker = %q, want %q", markerContent, newFilename)
+	}
+
+	// New snapshot should declare both tools
+	content, err := os.ReadFile(filepath.Join(configsDir, newFilename))
+	if err != nil {
+		t.Fatalf("failed to read new config: %v", err)
+	}
+	cfg, err := config.NewParser(nil).ParseString(ctx, string(content))
+	if err != nil {
+		t.Fatalf("failed to parse new config: %v", err)
+	}
+
+	want := map[string]bool{"node@20.11.0": false, "ripgrep@14.1.0": false}
+	for _, tool := range cfg.Tools {
+		if _, ok := want[tool]; ok {
+			want[tool] = true
+		}
+	}
+	for tool, found := range want {
+		if !found {
+			t.Errorf("tool %s not found in new config, got %v", tool, cfg.Tools)
+		}
+	}
+
+	// Original snapshot must be left untouched
+	original, err := os.ReadFile(initialConfigPath)
+	if err != nil {
+		t.Fatalf("failed to read initial config: %v", err)
+	}
+	if string(original) != initialConfig {
+		t.Error("initial config snapshot was modified")
+	}
+}
+
+func TestAdoptManagedExtras_Empty(t *testing.T) {
+	filename, err := AdoptManagedExtras(nil, "/nonexistent/zerb.active.lua", t.TempDir())
+	if err != nil {
+		t.Fatalf("AdoptManagedExtras() error = %v, want nil", err)
+	}
+	if filename != "" {
+		t.Errorf("AdoptManagedExtras() filename = %q, want empty", filename)
+	}
+}
+
+func TestAdoptManagedExtras_RejectsNonExtra(t *testing.T) {
+	results := []DriftResult{{Tool: "node", DriftType: DriftMissing, BaselineVersion: "20.11.0"}}
+	if _, err := AdoptManagedExtras(results, "/nonexistent/zerb.active.lua", t.TempDir()); err == nil {
+		t.Error("AdoptManagedExtras() error = nil, want error for non-extra drift")
+	}
+}
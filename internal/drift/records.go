@@ -0,0 +1,120 @@
+package drift
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/fsutil"
+)
+
+// VersionRecord is a detected tool version persisted to disk, so later runs
+// and other ZERB processes can reuse it.
+type VersionRecord struct {
+	// BinaryPath is the resolved path of the tool binary
+	BinaryPath string `json:"binary_path"`
+	// Version is the version detected for the binary
+	Version string `json:"version"`
+	// Probe identifies the version probe the version was detected with,
+	// empty for the generic --version/-v detection
+	Probe string `json:"probe,omitempty"`
+	// ModTime and Size identify the binary the version was detected for
+	ModTime time.Time `json:"mod_time"`
+	Size    int64     `json:"size"`
+	// DetectedAt is when the version was detected
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// VersionRecordStore reads and writes version records, one JSON file per
+// binary, in a directory (normally cache/versions in the ZERB directory).
+//
+// The store is safe for concurrent use. Within a process a mutex
+// serializes access; across processes records are replaced atomically, so
+// a reader sees either the old or the new record, and a read that still
+// fails (e.g. a filesystem without atomic rename) is retried once.
+type VersionRecordStore struct {
+	dir string
+	mu  sync.RWMutex
+}
+
+// readRecordFile reads a record file. Tests replace it to simulate a
+// transient read failure.
+var readRecordFile = os.ReadFile
+
+// NewVersionRecordStore creates a store for the records in dir.
+func NewVersionRecordStore(dir string) *VersionRecordStore {
+	return &VersionRecordStore{dir: dir}
+}
+
+// Load returns the record for binaryPath. Returns (nil, nil) if there is
+// no record.
+func (s *VersionRecordStore) Load(binaryPath string) (*VersionRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	path := s.recordPath(binaryPath)
+	record, err := readRecord(path)
+	if err != nil && !os.IsNotExist(err) {
+		// Another process may have been mid-write; try once more
+		record, err = readRecord(path)
+	}
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read version record for %s: %w", binaryPath, err)
+	}
+	if record.BinaryPath != binaryPath {
+		// Hash collision or a hand-edited file; treat as no record
+		return nil, nil
+	}
+	return record, nil
+}
+
+// Save writes the record for record.BinaryPath, replacing any existing one.
+func (s *VersionRecordStore) Save(record VersionRecord) error {
+	if record.BinaryPath == "" {
+		return fmt.Errorf("version record has no binary path")
+	}
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode version record: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("create version record directory: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(s.recordPath(record.BinaryPath), append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("write version record for %s: %w", record.BinaryPath, err)
+	}
+	return nil
+}
+
+// recordPath returns the record file for a binary, named by a hash of its
+// path so any path maps to a safe filename
+func (s *VersionRecordStore) recordPath(binaryPath string) string {
+	sum := sha256.Sum256([]byte(binaryPath))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:16])+".json")
+}
+
+// readRecord reads and decodes one record file
+func readRecord(path string) (*VersionRecord, error) {
+	data, err := readRecordFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var record VersionRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", filepath.Base(path), err)
+	}
+	return &record, nil
+}
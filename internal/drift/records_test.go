@@ -0,0 +1,162 @@
+package drift
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestVersionRecordStore_SaveLoad(t *testing.T) {
+	store := NewVersionRecordStore(filepath.Join(t.TempDir(), "cache", "versions"))
+
+	got, err := store.Load("/usr/bin/node")
+	if err != nil || got != nil {
+		t.Fatalf("Load() before Save = %v, %v; want nil, nil", got, err)
+	}
+
+	record := VersionRecord{
+		BinaryPath: "/usr/bin/node",
+		Version:    "20.11.0",
+		ModTime:    time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+		Size:       1234,
+		DetectedAt: time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC),
+	}
+	if err := store.Save(record); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err = store.Load("/usr/bin/node")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got == nil || *got != record {
+		t.Errorf("Load() = %+v, want %+v", got, record)
+	}
+
+	if other, err := store.Load("/usr/bin/python"); err != nil || other != nil {
+		t.Errorf("Load() for another binary = %v, %v; want nil, nil", other, err)
+	}
+
+	if err := store.Save(VersionRecord{Version: "1.0.0"}); err == nil {
+		t.Error("Save() without a binary path should fail")
+	}
+}
+
+func TestVersionRecordStore_RetriesTransientReadFailure(t *testing.T) {
+	store := NewVersionRecordStore(t.TempDir())
+	if err := store.Save(VersionRecord{BinaryPath: "/usr/bin/node", Version: "20.11.0"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		failures int
+		wantErr  bool
+	}{
+		{name: "one failure is retried", failures: 1},
+		{name: "two failures are reported", failures: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			readRecordFile = func(path string) ([]byte, error) {
+				calls++
+				if calls <= tt.failures {
+					return []byte(`{"binary_path": "/usr/bi`), nil
+				}
+				return os.ReadFile(path)
+			}
+			t.Cleanup(func() { readRecordFile = os.ReadFile })
+
+			got, err := store.Load("/usr/bin/node")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Load() = %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if got == nil || got.Version != "20.11.0" {
+				t.Errorf("Load() = %+v, want version 20.11.0", got)
+			}
+		})
+	}
+
+	readRecordFile = func(string) ([]byte, error) { return nil, errors.New("boom") }
+	t.Cleanup(func() { readRecordFile = os.ReadFile })
+	if _, err := store.Load("/usr/bin/node"); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Load() error = %v, want boom", err)
+	}
+}
+
+func TestVersionRecordStore_Concurrent(t *testing.T) {
+	dir := t.TempDir()
+	binaryPath := "/usr/bin/node"
+
+	// Records differ in size so a torn read would show up as invalid JSON
+	// or a mismatched version/size pair
+	versions := []string{"20.11.0", "21.0.0-" + strings.Repeat("x", 4096)}
+	if err := NewVersionRecordStore(dir).Save(VersionRecord{BinaryPath: binaryPath, Version: versions[0], Size: 0}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// Separate stores model separate processes sharing the directory; the
+	// shared store covers goroutines within one process
+	shared := NewVersionRecordStore(dir)
+	stores := []*VersionRecordStore{shared, shared, NewVersionRecordStore(dir), NewVersionRecordStore(dir)}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	done := make(chan struct{})
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(done)
+		writer := NewVersionRecordStore(dir)
+		for i := 0; i < 200; i++ {
+			n := i % 2
+			if err := writer.Save(VersionRecord{BinaryPath: binaryPath, Version: versions[n], Size: int64(n)}); err != nil {
+				errs <- fmt.Errorf("Save() error = %v", err)
+				return
+			}
+		}
+	}()
+
+	for _, store := range stores {
+		wg.Add(1)
+		go func(store *VersionRecordStore) {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				record, err := store.Load(binaryPath)
+				if err != nil {
+					errs <- fmt.Errorf("Load() error = %v", err)
+					return
+				}
+				if record == nil || record.Size < 0 || record.Size > 1 || record.Version != versions[record.Size] {
+					errs <- fmt.Errorf("torn read: %+v", record)
+					return
+				}
+			}
+		}(store)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
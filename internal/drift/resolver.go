@@ -1,10 +1,9 @@
 package drift
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-	"strings"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/prompt"
 )
 
 // ResolutionMode represents how to resolve drifts
@@ -35,25 +34,21 @@ func (r ResolutionMode) String() string {
 	}
 }
 
-// PromptResolutionMode prompts user for resolution mode
-func PromptResolutionMode() (ResolutionMode, error) {
+// PromptResolutionMode prompts user for resolution mode. With assume-yes,
+// drifts are resolved individually with their suggested actions.
+func PromptResolutionMode(p *prompt.Prompter) (ResolutionMode, error) {
 	fmt.Println("\nHow would you like to resolve these drifts?")
 	fmt.Println("  1. Resolve individually (choose action for each drift)")
 	fmt.Println("  2. Adopt all changes (update baseline to match environment)")
 	fmt.Println("  3. Revert all changes (restore environment to match baseline)")
 	fmt.Println("  4. Show details only (no changes)")
 	fmt.Println("  5. Exit")
-	fmt.Print("\nChoice [1]: ")
+	fmt.Println()
 
-	reader := bufio.NewReader(os.Stdin)
-	input, err := reader.ReadString('\n')
+	// Default to individual
+	input, err := p.Choose("Choice [1]: ", "1")
 	if err != nil {
-		return ResolutionExit, fmt.Errorf("read input: %w", err)
-	}
-
-	input = strings.TrimSpace(input)
-	if input == "" {
-		input = "1" // Default to individual
+		return ResolutionExit, err
 	}
 
 	switch input {
@@ -94,8 +89,24 @@ func (a DriftAction) String() string {
 	}
 }
 
-// PromptDriftAction prompts user for action on a single drift
-func PromptDriftAction(result DriftResult) (DriftAction, error) {
+// DefaultDriftAction returns the suggested action for a drift type: the
+// first choice PromptDriftAction offers.
+func DefaultDriftAction(d DriftType) DriftAction {
+	switch d {
+	case DriftMissing:
+		return ActionRevert // Install missing tool
+	case DriftManagedButNotActive, DriftVersionUnknown:
+		return ActionSkip // Needs manual investigation
+	case DriftInactiveProfile:
+		return ActionSkip // Already declared; removing it would break the profile
+	default:
+		return ActionAdopt
+	}
+}
+
+// PromptDriftAction prompts user for action on a single drift. With
+// assume-yes, the suggested action (DefaultDriftAction) is taken.
+func PromptDriftAction(p *prompt.Prompter, result DriftResult) (DriftAction, error) {
 	fmt.Printf("\n%s\n", formatDriftEntry(result))
 	fmt.Println("\nWhat would you like to do?")
 
@@ -125,35 +136,28 @@ func PromptDriftAction(result DriftResult) (DriftAction, error) {
 		fmt.Println("  2. Adopt (remove from baseline)")
 		fmt.Println("  3. Revert (not applicable)")
 
+	case DriftInactiveProfile:
+		fmt.Println("  1. Skip (keep it for when the profile is active)")
+		fmt.Println("  2. Adopt (already declared by the profile, no change)")
+		fmt.Println("  3. Revert (not applicable, the profile still declares it)")
+
 	case DriftVersionUnknown:
 		fmt.Println("  1. Skip (version detection failed, needs manual investigation)")
 		fmt.Println("  2. Adopt (remove from baseline)")
 		fmt.Println("  3. Revert (reinstall to fix version detection)")
 	}
 
-	fmt.Print("\nChoice [1]: ")
+	fmt.Println()
 
-	reader := bufio.NewReader(os.Stdin)
-	input, err := reader.ReadString('\n')
+	// Default to first option
+	input, err := p.Choose("Choice [1]: ", "1")
 	if err != nil {
-		return ActionSkip, fmt.Errorf("read input: %w", err)
-	}
-
-	input = strings.TrimSpace(input)
-	if input == "" {
-		input = "1" // Default to first option
+		return ActionSkip, err
 	}
 
 	switch input {
 	case "1":
-		// Default action depends on drift type
-		if result.DriftType == DriftMissing {
-			return ActionRevert, nil // Install missing tool
-		}
-		if result.DriftType == DriftManagedButNotActive || result.DriftType == DriftVersionUnknown {
-			return ActionSkip, nil // Skip by default for these types
-		}
-		return ActionAdopt, nil // Default for others
+		return DefaultDriftAction(result.DriftType), nil
 	case "2":
 		if result.DriftType == DriftMissing {
 			return ActionAdopt, nil // Remove from baseline
@@ -164,6 +168,9 @@ func PromptDriftAction(result DriftResult) (DriftAction, error) {
 		if result.DriftType == DriftVersionUnknown {
 			return ActionAdopt, nil // Remove from baseline
 		}
+		if result.DriftType == DriftInactiveProfile {
+			return ActionAdopt, nil // No change
+		}
 		return ActionRevert, nil // Revert for others
 	case "3":
 		if result.DriftType == DriftManagedButNotActive {
@@ -172,8 +179,23 @@ func PromptDriftAction(result DriftResult) (DriftAction, error) {
 		if result.DriftType == DriftVersionUnknown {
 			return ActionRevert, nil // Reinstall
 		}
+		if result.DriftType == DriftInactiveProfile {
+			return ActionSkip, nil // Not applicable, treat as skip
+		}
 		return ActionSkip, nil
 	default:
 		return ActionSkip, fmt.Errorf("invalid choice: %s", input)
 	}
 }
+
+// PromptAdoptExtras asks whether tools installed outside the configuration
+// should be added to the baseline in a single pass
+func PromptAdoptExtras(p *prompt.Prompter, extras []DriftResult) (bool, error) {
+	fmt.Printf("\nFound %d tool(s) installed outside your configuration:\n", len(extras))
+	for _, extra := range extras {
+		fmt.Printf("  + %s@%s\n", extra.Tool, extra.ManagedVersion)
+	}
+	fmt.Println()
+
+	return p.Confirm("Adopt these tools into your configuration? [y/N]: ")
+}
@@ -1,7 +1,12 @@
 package drift
 
 import (
+	"errors"
+	"io"
+	"strings"
 	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/prompt"
 )
 
 func TestResolutionMode_String(t *testing.T) {
@@ -52,6 +57,61 @@ func TestDriftAction_String(t *testing.T) {
 	}
 }
 
-// Note: PromptResolutionMode and PromptDriftAction require manual testing
-// or stdin mocking, which is beyond the scope of unit tests.
-// These functions are tested manually and in integration tests.
+func TestPromptResolutionMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    ResolutionMode
+		wantErr bool
+	}{
+		{"default", "\n", ResolutionIndividual, false},
+		{"adopt all", "2\n", ResolutionAdoptAll, false},
+		{"revert all", "3\n", ResolutionRevertAll, false},
+		{"invalid", "9\n", ResolutionExit, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := prompt.NewPrompter(strings.NewReader(tt.input), io.Discard)
+			got, err := PromptResolutionMode(p)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PromptResolutionMode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("PromptResolutionMode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPromptDriftAction(t *testing.T) {
+	missing := DriftResult{Tool: "node", DriftType: DriftMissing, BaselineVersion: "20.11.0"}
+
+	tests := []struct {
+		name string
+		p    *prompt.Prompter
+		want DriftAction
+	}{
+		{"default", prompt.NewPrompter(strings.NewReader("\n"), io.Discard), ActionRevert},
+		{"second choice", prompt.NewPrompter(strings.NewReader("2\n"), io.Discard), ActionAdopt},
+		{"assume yes takes the suggested action", prompt.NewPrompter(strings.NewReader("3\n"), io.Discard).WithAssumeYes(true), ActionRevert},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PromptDriftAction(tt.p, missing)
+			if err != nil {
+				t.Fatalf("PromptDriftAction() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("PromptDriftAction() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Non-interactive runs fail instead of waiting for input
+	p := prompt.NewPrompter(strings.NewReader("1\n"), io.Discard).WithNonInteractive(true)
+	if _, err := PromptDriftAction(p, missing); !errors.Is(err, prompt.ErrNonInteractive) {
+		t.Errorf("PromptDriftAction() error = %v, want ErrNonInteractive", err)
+	}
+}
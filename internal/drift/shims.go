@@ -0,0 +1,86 @@
+package drift
+
+import (
+	"context"
+	"path/filepath"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ShimDir returns the directory holding the tool manager's shims for
+// zerbDir. An activated shell has it on PATH.
+func ShimDir(zerbDir string) string {
+	return filepath.Join(zerbDir, "mise", "shims")
+}
+
+// QueryShims queries the tools in ZERB's shim directory instead of PATH, so
+// the result is the same whether or not the invoking shell is activated.
+// Uses the default package-level cache for version detection.
+func QueryShims(ctx context.Context, zerbDir string, toolNames []string, forceRefresh bool) ([]Tool, error) {
+	return QueryShimsWithOptions(ctx, zerbDir, toolNames, forceRefresh, VersionOptions{})
+}
+
+// QueryShimsWithCache queries the tools in ZERB's shim directory using the
+// provided cache.
+//
+// Unlike QueryActive, shim paths are not resolved: a shim is a link to the
+// tool manager, which picks the tool by the name it was run as. Shims are
+// run with ZERB's isolated tool manager environment, as an activated shell
+// would run them.
+//
+// Shim versions are not recorded on disk: every shim runs the tool
+// manager, whose binary stays the same when the version it picks changes,
+// so a record could never be invalidated. A PersistentVersionCache only
+// caches them in memory.
+func QueryShimsWithCache(ctx context.Context, zerbDir string, toolNames []string, forceRefresh bool, cache VersionCache) ([]Tool, error) {
+	return queryShims(ctx, zerbDir, toolNames, forceRefresh, cache, DefaultVersionProbes())
+}
+
+// QueryShimsWithOptions queries the tools in ZERB's shim directory,
+// detecting versions as opts configures. opts.Records is not used, as
+// QueryShimsWithCache explains.
+func QueryShimsWithOptions(ctx context.Context, zerbDir string, toolNames []string, forceRefresh bool, opts VersionOptions) ([]Tool, error) {
+	opts.Records = nil
+	cache, probes := opts.resolve()
+	return queryShims(ctx, zerbDir, toolNames, forceRefresh, cache, probes)
+}
+
+// queryShims implements QueryShimsWithOptions
+func queryShims(ctx context.Context, zerbDir string, toolNames []string, forceRefresh bool, cache VersionCache, probes VersionProbes) ([]Tool, error) {
+	shimDir := ShimDir(zerbDir)
+	env := miseEnv(zerbDir)
+	if persistent, ok := cache.(*PersistentVersionCache); ok {
+		cache = persistent.memory
+	}
+
+	// As in QueryActive, versions are detected by Parallelism() workers
+	found := make([]*Tool, len(toolNames))
+	var g errgroup.Group
+	g.SetLimit(Parallelism())
+
+	for i, name := range toolNames {
+		g.Go(func() error {
+			path, err := lookPath(name, []string{shimDir})
+			if err != nil {
+				// No shim for this tool, skip
+				return nil
+			}
+
+			version, err := detectToolVersion(ctx, name, path, env, forceRefresh, cache, probes)
+			if err != nil {
+				// Mark as unknown if version detection fails
+				version = "unknown"
+			}
+
+			found[i] = &Tool{
+				Name:    name,
+				Version: version,
+				Path:    path,
+			}
+			return nil
+		})
+	}
+	_ = g.Wait() // Workers never fail
+
+	return collectTools(found), nil
+}
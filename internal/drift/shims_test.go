@@ -0,0 +1,117 @@
+package drift
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestQueryShims_MatchesActivatedShell(t *testing.T) {
+	t.Parallel()
+
+	zerbDir := t.TempDir()
+	shimDir := ShimDir(zerbDir)
+	if err := os.MkdirAll(shimDir, 0755); err != nil {
+		t.Fatalf("failed to create shim dir: %v", err)
+	}
+
+	// The shim only reports the managed version when run with ZERB's tool
+	// manager environment, as a real shim would
+	shim := fmt.Sprintf(`#!/bin/sh
+if [ "$MISE_DATA_DIR" = "%s" ]; then
+    echo "node version 20.11.0"
+else
+    echo "no version configured" >&2
+    exit 1
+fi
+`, filepath.Join(zerbDir, "mise"))
+	if err := os.WriteFile(filepath.Join(shimDir, "node"), []byte(shim), 0755); err != nil {
+		t.Fatalf("failed to write shim: %v", err)
+	}
+
+	system := SetupTestPATH(t, map[string]string{"node": "18.19.0", "python": "3.12.1"})
+
+	baseline := []ToolSpec{{Name: "node", Version: "20.11.0"}, {Name: "python", Version: "3.12.1"}}
+	managed := []Tool{{Name: "node", Version: "20.11.0", Path: filepath.Join(zerbDir, "installs", "node", "20.11.0", "bin", "node")}}
+	toolNames := []string{"node", "python"}
+
+	tests := []struct {
+		name  string
+		query func() ([]Tool, error)
+		want  map[string]DriftType
+	}{
+		{
+			name: "non-activated shell finds system tools",
+			query: func() ([]Tool, error) {
+				return QueryActiveWithCache(context.Background(), toolNames, false, NewVersionCache(), system)
+			},
+			want: map[string]DriftType{"node": DriftExternalOverride, "python": DriftExternalOverride},
+		},
+		{
+			name: "ambient PATH with shims first",
+			query: func() ([]Tool, error) {
+				return QueryActiveWithCache(context.Background(), toolNames, false, NewVersionCache(), shimDir, system)
+			},
+			// Resolving the shim and running it outside ZERB's environment
+			// loses the version
+			want: map[string]DriftType{"node": DriftVersionUnknown, "python": DriftExternalOverride},
+		},
+		{
+			name: "shim-scoped scan",
+			query: func() ([]Tool, error) {
+				return QueryShimsWithCache(context.Background(), zerbDir, toolNames, false, NewVersionCache())
+			},
+			want: map[string]DriftType{"node": DriftOK, "python": DriftMissing},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			active, err := tt.query()
+			if err != nil {
+				t.Fatalf("query error = %v", err)
+			}
+
+			got := make(map[string]DriftType)
+			for _, r := range DetectDrift(baseline, nil, managed, active, zerbDir) {
+				got[r.Tool] = r.DriftType
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("drift = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryShims_KeepsShimPath(t *testing.T) {
+	t.Parallel()
+
+	zerbDir := t.TempDir()
+	shimDir := ShimDir(zerbDir)
+	if err := os.MkdirAll(shimDir, 0755); err != nil {
+		t.Fatalf("failed to create shim dir: %v", err)
+	}
+
+	// Shims are links to the tool manager; resolving them would detect the
+	// tool manager's version instead of the tool's
+	target := CreateMockBinary(t, t.TempDir(), "dispatcher", "2024.12.7")
+	if err := os.Symlink(target, filepath.Join(shimDir, "node")); err != nil {
+		t.Fatalf("failed to create shim: %v", err)
+	}
+
+	tools, err := QueryShimsWithCache(context.Background(), zerbDir, []string{"node"}, false, NewVersionCache())
+	if err != nil {
+		t.Fatalf("QueryShimsWithCache() error = %v", err)
+	}
+	if len(tools) != 1 {
+		t.Fatalf("QueryShimsWithCache() = %v, want one tool", tools)
+	}
+	if want := filepath.Join(shimDir, "node"); tools[0].Path != want {
+		t.Errorf("Path = %q, want %q", tools[0].Path, want)
+	}
+	if !IsZERBManaged(tools[0].Path, zerbDir) {
+		t.Errorf("shim path %q should be ZERB-managed", tools[0].Path)
+	}
+}
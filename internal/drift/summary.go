@@ -0,0 +1,93 @@
+package drift
+
+// Severity ranks how serious a drift is
+type Severity int
+
+const (
+	// SeverityNone means no drift
+	SeverityNone Severity = iota
+	// SeverityInfo is a drift worth knowing about that needs no action,
+	// e.g. a version that could not be detected
+	SeverityInfo
+	// SeverityWarning is a drift where the right tool is present but not in
+	// the expected version or not active
+	SeverityWarning
+	// SeverityError is a drift where a declared tool is missing or another
+	// installation is used instead of ZERB's
+	SeverityError
+)
+
+// String returns the lowercase severity name
+func (s Severity) String() string {
+	switch s {
+	case SeverityNone:
+		return "none"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// Severity returns how serious a drift of this type is
+func (d DriftType) Severity() Severity {
+	switch d {
+	case DriftOK:
+		return SeverityNone
+	case DriftVersionUnknown, DriftExtra, DriftInactiveProfile:
+		return SeverityInfo
+	case DriftVersionMismatch, DriftManagedButNotActive:
+		return SeverityWarning
+	default:
+		return SeverityError
+	}
+}
+
+// DriftSummary counts drift results by type
+type DriftSummary struct {
+	// Counts is the number of results of each drift type (types with no
+	// results are absent)
+	Counts map[DriftType]int
+	// Total is the number of results, including OK ones
+	Total int
+}
+
+// SummarizeDrift counts results by drift type
+func SummarizeDrift(results []DriftResult) DriftSummary {
+	summary := DriftSummary{Counts: make(map[DriftType]int), Total: len(results)}
+	for _, r := range results {
+		summary.Counts[r.DriftType]++
+	}
+	return summary
+}
+
+// Count returns the number of results of drift type d
+func (s DriftSummary) Count(d DriftType) int {
+	return s.Counts[d]
+}
+
+// Drifted returns the number of results that are not OK
+func (s DriftSummary) Drifted() int {
+	return s.Total - s.Counts[DriftOK]
+}
+
+// HasDrift reports whether any result is not OK
+func (s DriftSummary) HasDrift() bool {
+	return s.Drifted() > 0
+}
+
+// WorstSeverity returns the highest severity among the results
+// (SeverityNone if there is no drift)
+func (s DriftSummary) WorstSeverity() Severity {
+	worst := SeverityNone
+	for d, n := range s.Counts {
+		if n > 0 && d.Severity() > worst {
+			worst = d.Severity()
+		}
+	}
+	return worst
+}
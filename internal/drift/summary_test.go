@@ -0,0 +1,75 @@
+package drift
+
+import "testing"
+
+func TestSummarizeDrift(t *testing.T) {
+	results := []DriftResult{
+		{Tool: "node", DriftType: DriftOK},
+		{Tool: "python", DriftType: DriftOK},
+		{Tool: "go", DriftType: DriftVersionMismatch},
+		{Tool: "rust", DriftType: DriftVersionMismatch},
+		{Tool: "rg", DriftType: DriftVersionUnknown},
+		{Tool: "bat", DriftType: DriftMissing},
+	}
+
+	summary := SummarizeDrift(results)
+
+	wantCounts := map[DriftType]int{
+		DriftOK:                  2,
+		DriftVersionMismatch:     2,
+		DriftVersionUnknown:      1,
+		DriftMissing:             1,
+		DriftExtra:               0,
+		DriftExternalOverride:    0,
+		DriftManagedButNotActive: 0,
+	}
+	for d, want := range wantCounts {
+		if got := summary.Count(d); got != want {
+			t.Errorf("Count(%s) = %d, want %d", d, got, want)
+		}
+	}
+	if summary.Total != 6 {
+		t.Errorf("Total = %d, want 6", summary.Total)
+	}
+	if summary.Drifted() != 4 {
+		t.Errorf("Drifted() = %d, want 4", summary.Drifted())
+	}
+	if !summary.HasDrift() {
+		t.Error("HasDrift() = false, want true")
+	}
+	if summary.WorstSeverity() != SeverityError {
+		t.Errorf("WorstSeverity() = %s, want error", summary.WorstSeverity())
+	}
+}
+
+func TestDriftSummary_WorstSeverity(t *testing.T) {
+	tests := []struct {
+		name    string
+		types   []DriftType
+		want    Severity
+		wantHas bool
+	}{
+		{name: "no results", want: SeverityNone},
+		{name: "all OK", types: []DriftType{DriftOK, DriftOK}, want: SeverityNone},
+		{name: "unknown version", types: []DriftType{DriftOK, DriftVersionUnknown}, want: SeverityInfo, wantHas: true},
+		{name: "extra and mismatch", types: []DriftType{DriftExtra, DriftVersionMismatch}, want: SeverityWarning, wantHas: true},
+		{name: "not active", types: []DriftType{DriftManagedButNotActive}, want: SeverityWarning, wantHas: true},
+		{name: "external override", types: []DriftType{DriftVersionUnknown, DriftExternalOverride}, want: SeverityError, wantHas: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var results []DriftResult
+			for _, d := range tt.types {
+				results = append(results, DriftResult{DriftType: d})
+			}
+			summary := SummarizeDrift(results)
+			if got := summary.WorstSeverity(); got != tt.want {
+				t.Errorf("WorstSeverity() = %s, want %s", got, tt.want)
+			}
+			if got := summary.HasDrift(); got != tt.wantHas {
+				t.Errorf("HasDrift() = %v, want %v", got, tt.wantHas)
+			}
+		})
+	}
+}
@@ -29,7 +29,8 @@ fi
 	return path
 }
 
-// SetupTestPATH creates a test PATH with mock binaries
+// SetupTestPATH creates a directory of mock binaries and returns it, to
+// pass to QueryActive as the search path
 func SetupTestPATH(t *testing.T, binaries map[string]string) string {
 	t.Helper()
 
@@ -39,8 +40,7 @@ func SetupTestPATH(t *testing.T, binaries map[string]string) string {
 		CreateMockBinary(t, tmpDir, name, version)
 	}
 
-	// Return new PATH prefix
-	return tmpDir + ":" + os.Getenv("PATH")
+	return tmpDir
 }
 
 // MockMiseOutput returns mock JSON output for mise ls
@@ -13,6 +13,9 @@ const (
 	DriftExternalOverride
 	DriftManagedButNotActive
 	DriftVersionUnknown
+	// DriftInactiveProfile is a managed tool that is not in the active
+	// baseline but is declared by a profile not active on this machine
+	DriftInactiveProfile
 )
 
 // String returns human-readable drift type name
@@ -32,6 +35,8 @@ func (d DriftType) String() string {
 		return "MANAGED_BUT_NOT_ACTIVE"
 	case DriftVersionUnknown:
 		return "VERSION_UNKNOWN"
+	case DriftInactiveProfile:
+		return "INACTIVE_PROFILE"
 	default:
 		return "UNKNOWN"
 	}
@@ -41,7 +46,16 @@ func (d DriftType) String() string {
 type Tool struct {
 	Name    string
 	Version string
-	Path    string
+	Path    string // install path for managed tools, executable for active ones
+	// ID is the tool manager's full identity of a managed tool, e.g.
+	// "cargo:ripgrep" or "ubi:sharkdp/bat", which Name (e.g. "rg") may
+	// not match; empty when unknown, as for tools found in PATH
+	ID string
+	// Backend is the backend of ID ("" for core tools)
+	Backend string
+	// AllPaths lists every executable found for the tool, in PATH order;
+	// the first is the one that runs
+	AllPaths []string
 }
 
 // ToolSpec represents a parsed tool specification
@@ -59,4 +73,9 @@ type DriftResult struct {
 	ManagedVersion  string
 	ActiveVersion   string
 	ActivePath      string
+	// AllPaths lists every executable found for the tool, in PATH order
+	AllPaths []string
+	// PathOrder says where ZERB's copy of the tool is in PATH, for
+	// DriftExternalOverride (PathOrderUnknown otherwise)
+	PathOrder PathOrder
 }
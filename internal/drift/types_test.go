@@ -15,6 +15,7 @@ func TestDriftType_String(t *testing.T) {
 		{"External Override", DriftExternalOverride, "EXTERNAL_OVERRIDE"},
 		{"Managed But Not Active", DriftManagedButNotActive, "MANAGED_BUT_NOT_ACTIVE"},
 		{"Version Unknown", DriftVersionUnknown, "VERSION_UNKNOWN"},
+		{"Inactive Profile", DriftInactiveProfile, "INACTIVE_PROFILE"},
 	}
 
 	for _, tt := range tests {
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
 )
 
 // versionRegex matches semantic versions including pre-release and build metadata
@@ -60,5 +62,80 @@ func ParseToolSpec(spec string) (ToolSpec, error) {
 		Backend: backend,
 		Name:    name,
 		Version: version,
-	}, nil
+	}.Canonical(), nil
+}
+
+// Canonical returns the spec in canonical form, following the rules of
+// config.CanonicalTool, so "node", "node@" and "core:node" all give the
+// same spec: the core backend is dropped and backend and name are
+// lowercased. The version is kept as written.
+func (s ToolSpec) Canonical() ToolSpec {
+	s.Backend = strings.ToLower(strings.TrimSpace(s.Backend))
+	if s.Backend == config.CoreBackend {
+		s.Backend = ""
+	}
+	s.Name = strings.ToLower(strings.TrimSpace(s.Name))
+	s.Version = strings.TrimSpace(s.Version)
+	return s
+}
+
+// toolKey returns the canonical name a tool is compared by. Tool names
+// reported by the tool manager may carry a backend ("cargo:ripgrep") or
+// repository path, which baseline specs have already stripped.
+func toolKey(name string) string {
+	spec, err := ParseToolSpec(name)
+	if err != nil {
+		return name
+	}
+	return spec.Name
+}
+
+// specKey returns the backend-qualified key a spec is matched by, e.g.
+// "cargo:ripgrep", or just the name for core tools
+func specKey(spec ToolSpec) string {
+	if spec.Backend == "" {
+		return spec.Name
+	}
+	return spec.Backend + ":" + spec.Name
+}
+
+// specIndex finds the spec a managed tool was installed for
+type specIndex struct {
+	byKey  map[string]ToolSpec // by backend and name
+	byName map[string]ToolSpec // by name alone
+}
+
+func newSpecIndex(specs []ToolSpec) specIndex {
+	index := specIndex{
+		byKey:  make(map[string]ToolSpec, len(specs)),
+		byName: make(map[string]ToolSpec, len(specs)),
+	}
+	for _, spec := range specs {
+		index.byKey[specKey(spec)] = spec
+		if _, ok := index.byName[spec.Name]; !ok {
+			index.byName[spec.Name] = spec
+		}
+	}
+	return index
+}
+
+// lookup returns the spec matching tool. A tool whose identity (ID) is
+// known matches a spec with the same backend and name, or a spec naming
+// no backend; its reported Name, which may be a binary name like "rg" for
+// "cargo:ripgrep", is not used. A tool without an ID matches by name.
+func (x specIndex) lookup(tool Tool) (ToolSpec, bool) {
+	if tool.ID == "" {
+		spec, ok := x.byName[toolKey(tool.Name)]
+		return spec, ok
+	}
+
+	id, err := ParseToolSpec(tool.ID)
+	if err != nil {
+		return ToolSpec{}, false
+	}
+	if spec, ok := x.byKey[specKey(id)]; ok {
+		return spec, true
+	}
+	spec, ok := x.byKey[id.Name]
+	return spec, ok
 }
@@ -90,3 +90,72 @@ func TestParseToolSpec(t *testing.T) {
 		})
 	}
 }
+
+func TestToolSpec_Canonical(t *testing.T) {
+	equivalent := [][]string{
+		{"node", "node@", "core:node", "core:node@", "Node", "CORE:node"},
+		{"node@20.11.0", "core:node@20.11.0", "NODE@20.11.0"},
+		{"cargo:ripgrep@14.1.0", "Cargo:RipGrep@14.1.0"},
+	}
+	for _, group := range equivalent {
+		want, err := ParseToolSpec(group[0])
+		if err != nil {
+			t.Fatalf("ParseToolSpec(%q) error = %v", group[0], err)
+		}
+		for _, spec := range group[1:] {
+			got, err := ParseToolSpec(spec)
+			if err != nil {
+				t.Fatalf("ParseToolSpec(%q) error = %v", spec, err)
+			}
+			if got != want {
+				t.Errorf("ParseToolSpec(%q) = %+v, want %+v (same as %q)", spec, got, want, group[0])
+			}
+		}
+	}
+
+	different := [][2]string{
+		{"node", "node@20"},
+		{"node@20", "node@22"},
+		{"cargo:ripgrep", "ripgrep"},
+		{"cargo:ripgrep", "npm:ripgrep"},
+	}
+	for _, pair := range different {
+		a, _ := ParseToolSpec(pair[0])
+		b, _ := ParseToolSpec(pair[1])
+		if a == b {
+			t.Errorf("ParseToolSpec(%q) and ParseToolSpec(%q) should differ, both %+v", pair[0], pair[1], a)
+		}
+	}
+
+	raw := ToolSpec{Backend: "Core", Name: " Node ", Version: "20.11.0"}
+	if got, want := raw.Canonical(), (ToolSpec{Name: "node", Version: "20.11.0"}); got != want {
+		t.Errorf("Canonical() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDetectDrift_CanonicalNames(t *testing.T) {
+	zerbDir := "/home/user/.config/zerb"
+	baseline := []ToolSpec{{Name: "node", Version: "20.11.0"}, {Backend: "cargo", Name: "ripgrep", Version: "14.1.0"}}
+	managed := []Tool{
+		{Name: "core:node", Version: "20.11.0", Path: zerbDir + "/installs/node/20.11.0/bin/node"},
+		{Name: "cargo:ripgrep", Version: "14.1.0", Path: zerbDir + "/installs/ripgrep/14.1.0/bin/rg"},
+	}
+	active := []Tool{
+		{Name: "node", Version: "20.11.0", Path: zerbDir + "/installs/node/20.11.0/bin/node"},
+		{Name: "ripgrep", Version: "14.1.0", Path: zerbDir + "/installs/ripgrep/14.1.0/bin/rg"},
+	}
+
+	results := DetectDrift(baseline, nil, managed, active, zerbDir)
+	if len(results) != 2 {
+		t.Fatalf("DetectDrift() = %+v, want 2 results (no extras)", results)
+	}
+	for _, r := range results {
+		if r.DriftType != DriftOK {
+			t.Errorf("%s drift = %v, want OK", r.Tool, r.DriftType)
+		}
+	}
+
+	if extras := FindManagedExtras(baseline, managed); len(extras) != 0 {
+		t.Errorf("FindManagedExtras() = %+v, want none", extras)
+	}
+}
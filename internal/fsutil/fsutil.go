@@ -0,0 +1,96 @@
+// Package fsutil provides filesystem helpers shared across ZERB.
+//
+// WriteFileAtomic replaces a file so readers see either the old content or
+// the new content in full, never a partially written file, even if ZERB is
+// interrupted or the machine loses power mid-write.
+package fsutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// tempPattern names temporary files created next to their destination
+const tempPattern = ".zerb-tmp-*"
+
+// writeData writes data to the temporary file. Tests replace it to
+// simulate a failure part way through a write.
+var writeData = func(f *os.File, data []byte) (int, error) {
+	return f.Write(data)
+}
+
+// syncDir syncs the destination directory. Tests replace it to simulate
+// a platform that cannot sync directories.
+var syncDir = SyncDir
+
+// WriteFileAtomic writes data to path atomically with the given permissions.
+//
+// The data is written to a temporary file in the same directory, which is
+// then set to mode, synced to disk and renamed over path. Finally the
+// directory is synced so the rename itself survives a crash; as path is
+// already written by then, a failed directory sync (e.g. on Windows, which
+// cannot sync directories) is not an error. If any earlier step fails,
+// path is left untouched and the temporary file is removed.
+//
+// mode is applied exactly; the process umask does not affect it.
+func WriteFileAtomic(path string, data []byte, mode os.FileMode) error {
+	dir := filepath.Dir(path)
+
+	tmpFile, err := os.CreateTemp(dir, tempPattern)
+	if err != nil {
+		return fmt.Errorf("create temporary file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+
+	// Clean up the temporary file unless it was renamed into place
+	renamed := false
+	defer func() {
+		if !renamed {
+			tmpFile.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := writeData(tmpFile, data); err != nil {
+		return fmt.Errorf("write temporary file: %w", err)
+	}
+
+	if err := tmpFile.Chmod(mode.Perm()); err != nil {
+		return fmt.Errorf("set permissions: %w", err)
+	}
+
+	if err := tmpFile.Sync(); err != nil {
+		return fmt.Errorf("sync temporary file: %w", err)
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("close temporary file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("rename temporary file: %w", err)
+	}
+	renamed = true
+
+	// Best effort: the file is in place whether or not this succeeds
+	_ = syncDir(dir)
+
+	return nil
+}
+
+// SyncDir flushes a directory's entries to disk, making recent renames and
+// file creations in it durable.
+func SyncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("open directory: %w", err)
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("sync directory: %w", err)
+	}
+
+	return nil
+}
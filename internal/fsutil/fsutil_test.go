@@ -0,0 +1,130 @@
+package fsutil
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []byte // nil means the file does not exist
+		data     []byte
+	}{
+		{name: "new file", data: []byte("hello\n")},
+		{name: "replace existing", existing: []byte("old content\n"), data: []byte("new\n")},
+		{name: "empty data", existing: []byte("old\n"), data: []byte{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "file")
+			if tt.existing != nil {
+				if err := os.WriteFile(path, tt.existing, 0644); err != nil {
+					t.Fatalf("failed to write existing file: %v", err)
+				}
+			}
+
+			if err := WriteFileAtomic(path, tt.data, 0644); err != nil {
+				t.Fatalf("WriteFileAtomic() error = %v", err)
+			}
+
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read file: %v", err)
+			}
+			if string(got) != string(tt.data) {
+				t.Errorf("content = %q, want %q", got, tt.data)
+			}
+			assertNoTempFiles(t, dir)
+		})
+	}
+}
+
+func TestWriteFileAtomic_FailedWriteLeavesOriginal(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+	original := []byte("original content\n")
+	if err := os.WriteFile(path, original, 0644); err != nil {
+		t.Fatalf("failed to write original file: %v", err)
+	}
+
+	// Simulate running out of disk space half way through the write
+	errDiskFull := errors.New("no space left on device")
+	oldWriteData := writeData
+	writeData = func(f *os.File, data []byte) (int, error) {
+		n, _ := f.Write(data[:len(data)/2])
+		return n, errDiskFull
+	}
+	defer func() { writeData = oldWriteData }()
+
+	err := WriteFileAtomic(path, []byte("replacement content that never fully lands\n"), 0644)
+	if !errors.Is(err, errDiskFull) {
+		t.Fatalf("WriteFileAtomic() error = %v, want %v", err, errDiskFull)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if string(got) != string(original) {
+		t.Errorf("content = %q, want original %q", got, original)
+	}
+	assertNoTempFiles(t, dir)
+}
+
+func TestWriteFileAtomic_FailedDirSyncKeepsFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+
+	// Directories cannot be synced on some platforms, e.g. Windows
+	oldSyncDir := syncDir
+	syncDir = func(dir string) error {
+		return errors.New("sync directory: not supported")
+	}
+	defer func() { syncDir = oldSyncDir }()
+
+	if err := WriteFileAtomic(path, []byte("data"), 0644); err != nil {
+		t.Fatalf("WriteFileAtomic() error = %v, want nil once the file is renamed into place", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if string(got) != "data" {
+		t.Errorf("content = %q, want %q", got, "data")
+	}
+	assertNoTempFiles(t, dir)
+}
+
+func TestWriteFileAtomic_MissingDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "file")
+	if err := WriteFileAtomic(path, []byte("data"), 0644); err == nil {
+		t.Fatal("WriteFileAtomic() expected error for missing directory")
+	}
+}
+
+func TestSyncDir(t *testing.T) {
+	if err := SyncDir(t.TempDir()); err != nil {
+		t.Errorf("SyncDir() error = %v", err)
+	}
+	if err := SyncDir(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("SyncDir() expected error for missing directory")
+	}
+}
+
+// assertNoTempFiles fails if a temporary file was left behind in dir
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, tempPattern))
+	if err != nil {
+		t.Fatalf("glob failed: %v", err)
+	}
+	if len(matches) > 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
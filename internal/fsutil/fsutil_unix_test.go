@@ -0,0 +1,39 @@
+//go:build unix
+
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestWriteFileAtomic_Mode(t *testing.T) {
+	// A restrictive umask must not change the requested mode
+	oldMask := syscall.Umask(0077)
+	defer syscall.Umask(oldMask)
+
+	for _, mode := range []os.FileMode{0600, 0644, 0755} {
+		t.Run(mode.String(), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "file")
+
+			// Existing file with a different mode is replaced with the requested one
+			if err := os.WriteFile(path, []byte("old"), 0640); err != nil {
+				t.Fatalf("failed to write existing file: %v", err)
+			}
+
+			if err := WriteFileAtomic(path, []byte("data"), mode); err != nil {
+				t.Fatalf("WriteFileAtomic() error = %v", err)
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatalf("failed to stat file: %v", err)
+			}
+			if info.Mode().Perm() != mode {
+				t.Errorf("mode = %v, want %v", info.Mode().Perm(), mode)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// HostBranchPrefix is the namespace for per-host snapshot branches
+const HostBranchPrefix = "hosts/"
+
+// ErrDetachedHead is returned by CurrentBranch when HEAD is not on a branch
+var ErrDetachedHead = errors.New("HEAD is not on a branch")
+
+// machineIDPaths lists the files holding a stable per-machine identifier
+var machineIDPaths = []string{"/etc/machine-id", "/var/lib/dbus/machine-id"}
+
+// invalidBranchChars matches runs of characters not allowed in host branch names
+var invalidBranchChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// CurrentBranch returns the short name of the branch HEAD points to.
+// Returns ErrDetachedHead if HEAD is detached.
+func (c *Client) CurrentBranch(ctx context.Context) (string, error) {
+	// Check context cancellation
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("context cancelled: %w", err)
+	}
+
+	repo, err := gogit.PlainOpen(c.repoPath)
+	if err != nil {
+		return "", fmt.Errorf("open repository: %w", err)
+	}
+
+	// Read HEAD without resolving it so an unborn branch still has a name
+	head, err := repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return "", fmt.Errorf("get HEAD: %w", err)
+	}
+	if head.Type() != plumbing.SymbolicReference {
+		return "", ErrDetachedHead
+	}
+
+	return head.Target().Short(), nil
+}
+
+// CheckoutBranch switches HEAD to the named branch, creating it at the
+// current HEAD commit if it does not exist. The working tree is left
+// untouched. A new branch keeps the index, so pending changes are committed
+// to it; switching to an existing branch resets the index to that branch,
+// so a commit there does not carry over the other branch's staged files.
+func (c *Client) CheckoutBranch(ctx context.Context, name string) error {
+	// Check context cancellation
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+
+	ref := plumbing.NewBranchReferenceName(name)
+	if err := ref.Validate(); err != nil {
+		return fmt.Errorf("invalid branch name %q: %w", name, err)
+	}
+
+	repo, err := gogit.PlainOpen(c.repoPath)
+	if err != nil {
+		return fmt.Errorf("open repository: %w", err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("get worktree: %w", err)
+	}
+
+	existing, err := repo.Storer.Reference(ref)
+	switch {
+	case err == nil:
+		// Branch exists; switch to it
+	case errors.Is(err, plumbing.ErrReferenceNotFound):
+		head, err := repo.Head()
+		if err != nil {
+			return fmt.Errorf("get HEAD: %w", err)
+		}
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(ref, head.Hash())); err != nil {
+			return fmt.Errorf("create branch %s: %w", name, err)
+		}
+	default:
+		return fmt.Errorf("look up branch %s: %w", name, err)
+	}
+
+	if err := worktree.Checkout(&gogit.CheckoutOptions{Branch: ref, Keep: true}); err != nil {
+		return fmt.Errorf("checkout branch %s: %w", name, err)
+	}
+
+	if existing != nil {
+		if err := worktree.Reset(&gogit.ResetOptions{Commit: existing.Hash(), Mode: gogit.MixedReset}); err != nil {
+			return fmt.Errorf("reset index to branch %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// HostBranchName returns the snapshot branch for this machine, e.g.
+// "hosts/laptop-4c1d9e0a". The name combines the hostname with the start of
+// the machine id, so it stays the same across reboots and ZERB reinstalls.
+// Without a machine id the hostname alone is used.
+func HostBranchName() (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", fmt.Errorf("get hostname: %w", err)
+	}
+	return hostBranchName(hostname, readMachineID(machineIDPaths)), nil
+}
+
+// hostBranchName builds a branch name from a hostname and machine id
+func hostBranchName(hostname, machineID string) string {
+	// Drop the domain and reduce the rest to a safe branch component
+	if i := strings.Index(hostname, "."); i > 0 {
+		hostname = hostname[:i]
+	}
+	name := strings.Trim(invalidBranchChars.ReplaceAllString(strings.ToLower(hostname), "-"), "-")
+	if name == "" {
+		name = "host"
+	}
+
+	if len(machineID) >= 8 {
+		name += "-" + machineID[:8]
+	}
+
+	return HostBranchPrefix + name
+}
+
+// readMachineID returns the first valid machine id found in paths, or ""
+func readMachineID(paths []string) string {
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		id := strings.ToLower(strings.TrimSpace(string(data)))
+		if id != "" && invalidBranchChars.FindStringIndex(id) == nil {
+			return id
+		}
+	}
+	return ""
+}
@@ -0,0 +1,240 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupBranchTestRepo creates a repository with a single commit
+func setupBranchTestRepo(t *testing.T) (*Client, string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	ctx := context.Background()
+	client := NewClient(tmpDir)
+
+	if err := client.InitRepo(ctx); err != nil {
+		t.Fatalf("InitRepo() error = %v", err)
+	}
+	if err := client.ConfigureUser(ctx, GitUserInfo{Name: "Test User", Email: "test@example.com"}); err != nil {
+		t.Fatalf("ConfigureUser() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatalf("cannot create test file: %v", err)
+	}
+	if err := client.CreateInitialCommit(ctx, "Initial commit", []string{"a.txt"}); err != nil {
+		t.Fatalf("CreateInitialCommit() error = %v", err)
+	}
+
+	return client, tmpDir
+}
+
+// revParse resolves a revision with the git CLI
+func revParse(t *testing.T, dir string, rev ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"rev-parse"}, rev...)...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git rev-parse %v failed: %v", rev, err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestClient_CurrentBranch(t *testing.T) {
+	client, dir := setupBranchTestRepo(t)
+
+	got, err := client.CurrentBranch(context.Background())
+	if err != nil {
+		t.Fatalf("CurrentBranch() error = %v", err)
+	}
+	if want := revParse(t, dir, "--abbrev-ref", "HEAD"); got != want {
+		t.Errorf("CurrentBranch() = %q, want %q", got, want)
+	}
+}
+
+func TestClient_CurrentBranch_Detached(t *testing.T) {
+	client, dir := setupBranchTestRepo(t)
+
+	cmd := exec.Command("git", "checkout", "-q", "--detach")
+	cmd.Dir = dir
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("git checkout --detach failed: %v", err)
+	}
+
+	_, err := client.CurrentBranch(context.Background())
+	if !errors.Is(err, ErrDetachedHead) {
+		t.Errorf("CurrentBranch() error = %v, want ErrDetachedHead", err)
+	}
+}
+
+func TestClient_CheckoutBranch_CommitsLandOnBranch(t *testing.T) {
+	client, dir := setupBranchTestRepo(t)
+	ctx := context.Background()
+	baseBranch := revParse(t, dir, "--abbrev-ref", "HEAD")
+	baseCommit := revParse(t, dir, "HEAD")
+
+	// Pending changes are carried onto the new branch
+	if err := os.WriteFile(filepath.Join(dir, "b.txt"), []byte("b"), 0644); err != nil {
+		t.Fatalf("cannot create test file: %v", err)
+	}
+	if err := client.Stage(ctx, "b.txt"); err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+
+	if err := client.CheckoutBranch(ctx, "hosts/laptop"); err != nil {
+		t.Fatalf("CheckoutBranch() error = %v", err)
+	}
+	if err := client.Commit(ctx, "Add b", ""); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	if got, _ := client.CurrentBranch(ctx); got != "hosts/laptop" {
+		t.Errorf("CurrentBranch() = %q, want %q", got, "hosts/laptop")
+	}
+	if got := revParse(t, dir, "hosts/laptop~1"); got != baseCommit {
+		t.Errorf("host branch parent = %s, want %s", got, baseCommit)
+	}
+	if got := revParse(t, dir, baseBranch); got != baseCommit {
+		t.Errorf("%s moved to %s, want %s", baseBranch, got, baseCommit)
+	}
+
+	// Switching to the existing branch again is a no-op for its history
+	if err := client.CheckoutBranch(ctx, baseBranch); err != nil {
+		t.Fatalf("CheckoutBranch(%s) error = %v", baseBranch, err)
+	}
+	if err := client.CheckoutBranch(ctx, "hosts/laptop"); err != nil {
+		t.Fatalf("CheckoutBranch(existing) error = %v", err)
+	}
+	head, _ := client.GetHeadCommit(ctx)
+	if want := revParse(t, dir, "hosts/laptop"); head != want {
+		t.Errorf("HEAD = %s, want %s", head, want)
+	}
+}
+
+func TestClient_CheckoutBranch_ExistingBranchDirtyIndex(t *testing.T) {
+	client, dir := setupBranchTestRepo(t)
+	ctx := context.Background()
+	baseBranch := revParse(t, dir, "--abbrev-ref", "HEAD")
+
+	writeAndStage := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("cannot write %s: %v", name, err)
+		}
+		if err := client.Stage(ctx, name); err != nil {
+			t.Fatalf("Stage(%s) error = %v", name, err)
+		}
+	}
+
+	// The host branch has a file the base branch does not
+	if err := client.CheckoutBranch(ctx, "hosts/laptop"); err != nil {
+		t.Fatalf("CheckoutBranch() error = %v", err)
+	}
+	writeAndStage("host.txt", "host")
+	if err := client.Commit(ctx, "Add host file", ""); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	// Back on the base branch, leave a staged change behind
+	if err := client.CheckoutBranch(ctx, baseBranch); err != nil {
+		t.Fatalf("CheckoutBranch(%s) error = %v", baseBranch, err)
+	}
+	writeAndStage("a.txt", "staged on base")
+
+	if err := client.CheckoutBranch(ctx, "hosts/laptop"); err != nil {
+		t.Fatalf("CheckoutBranch(existing) error = %v", err)
+	}
+	writeAndStage("b.txt", "b")
+	if err := client.Commit(ctx, "Add b", ""); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	show := func(path string) string {
+		t.Helper()
+		cmd := exec.Command("git", "show", "hosts/laptop:"+path)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git show hosts/laptop:%s failed: %v", path, err)
+		}
+		return string(out)
+	}
+	if got := show("host.txt"); got != "host" {
+		t.Errorf("host.txt = %q, want the host branch's file kept", got)
+	}
+	if got := show("a.txt"); got != "a" {
+		t.Errorf("a.txt = %q, want %q (the base branch's staged change must not be committed)", got, "a")
+	}
+	if got := show("b.txt"); got != "b" {
+		t.Errorf("b.txt = %q, want %q", got, "b")
+	}
+	// The working tree keeps the uncommitted change
+	if data, _ := os.ReadFile(filepath.Join(dir, "a.txt")); string(data) != "staged on base" {
+		t.Errorf("a.txt on disk = %q, want the change kept", data)
+	}
+}
+
+func TestClient_CheckoutBranch_InvalidName(t *testing.T) {
+	client, _ := setupBranchTestRepo(t)
+
+	for _, name := range []string{"", "bad..name", "trailing/", "has space"} {
+		if err := client.CheckoutBranch(context.Background(), name); err == nil {
+			t.Errorf("CheckoutBranch(%q) expected error, got nil", name)
+		}
+	}
+}
+
+func TestClient_CheckoutBranch_ContextCancellation(t *testing.T) {
+	client, _ := setupBranchTestRepo(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := client.CheckoutBranch(ctx, "hosts/laptop"); err == nil {
+		t.Error("CheckoutBranch() expected error with cancelled context, got nil")
+	}
+}
+
+func TestHostBranchName(t *testing.T) {
+	tests := []struct {
+		name      string
+		hostname  string
+		machineID string
+		want      string
+	}{
+		{"hostname and machine id", "laptop", "4c1d9e0a2b3c4d5e", "hosts/laptop-4c1d9e0a"},
+		{"fully qualified hostname", "laptop.example.com", "4c1d9e0a2b3c4d5e", "hosts/laptop-4c1d9e0a"},
+		{"mixed case and symbols", "My_Work PC", "", "hosts/my-work-pc"},
+		{"no usable hostname", "___", "4c1d9e0a2b3c4d5e", "hosts/host-4c1d9e0a"},
+		{"short machine id ignored", "laptop", "abc", "hosts/laptop"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hostBranchName(tt.hostname, tt.machineID); got != tt.want {
+				t.Errorf("hostBranchName(%q, %q) = %q, want %q", tt.hostname, tt.machineID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadMachineID(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty")
+	valid := filepath.Join(dir, "machine-id")
+	os.WriteFile(empty, []byte("\n"), 0644)
+	os.WriteFile(valid, []byte("4C1D9E0A2B3C4D5E\n"), 0644)
+
+	if got := readMachineID([]string{filepath.Join(dir, "missing"), empty, valid}); got != "4c1d9e0a2b3c4d5e" {
+		t.Errorf("readMachineID() = %q, want %q", got, "4c1d9e0a2b3c4d5e")
+	}
+	if got := readMachineID([]string{filepath.Join(dir, "missing")}); got != "" {
+		t.Errorf("readMachineID() = %q, want empty", got)
+	}
+}
@@ -0,0 +1,90 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/fsutil"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// ErrFileNotInCommit means the requested file does not exist in the commit
+var ErrFileNotInCommit = errors.New("file not found in commit")
+
+// CheckoutFile writes path as it was in commit to the working tree, leaving
+// the index and HEAD untouched. commit may be a full or abbreviated hash or
+// any revision git understands. path is relative to the repository root.
+// Restored files are readable only by the user, since the ZERB directory
+// may hold secrets.
+func (c *Client) CheckoutFile(ctx context.Context, commit, path string) error {
+	// Check context cancellation
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+
+	name, err := repoRelativePath(path)
+	if err != nil {
+		return err
+	}
+
+	repo, err := gogit.PlainOpen(c.repoPath)
+	if err != nil {
+		return fmt.Errorf("open repository: %w", err)
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(commit))
+	if err != nil {
+		return fmt.Errorf("resolve commit %s: %w", commit, err)
+	}
+
+	obj, err := repo.CommitObject(*hash)
+	if err != nil {
+		return fmt.Errorf("read commit %s: %w", commit, err)
+	}
+
+	file, err := obj.File(name)
+	if err != nil {
+		if errors.Is(err, object.ErrFileNotFound) {
+			return fmt.Errorf("%s in %s: %w", name, commit, ErrFileNotInCommit)
+		}
+		return fmt.Errorf("read %s in %s: %w", name, commit, err)
+	}
+
+	content, err := file.Contents()
+	if err != nil {
+		return fmt.Errorf("read %s in %s: %w", name, commit, err)
+	}
+
+	perm := os.FileMode(0600)
+	if file.Mode == filemode.Executable {
+		perm = 0700
+	}
+
+	dest := filepath.Join(c.repoPath, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(dest), 0700); err != nil {
+		return fmt.Errorf("create directory for %s: %w", name, err)
+	}
+	if err := fsutil.WriteFileAtomic(dest, []byte(content), perm); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// repoRelativePath cleans a repository-relative path to the slash-separated
+// form git stores, rejecting paths that leave the repository
+func repoRelativePath(p string) (string, error) {
+	name := path.Clean(filepath.ToSlash(p))
+	if name == "." || path.IsAbs(name) || filepath.IsAbs(p) || name == ".." || strings.HasPrefix(name, "../") {
+		return "", fmt.Errorf("invalid repository path %q", p)
+	}
+	return name, nil
+}
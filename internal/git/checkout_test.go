@@ -0,0 +1,79 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClient_CheckoutFile(t *testing.T) {
+	dir := t.TempDir()
+	client := NewClient(dir)
+	ctx := context.Background()
+	if err := client.InitRepo(ctx); err != nil {
+		t.Fatalf("InitRepo() error = %v", err)
+	}
+	if err := client.ConfigureUser(ctx, GitUserInfo{Name: "Test User", Email: "test@example.com"}); err != nil {
+		t.Fatalf("ConfigureUser() error = %v", err)
+	}
+
+	commitFile(t, client, dir, "configs/zerb.1.lua", "Add first version", "")
+	first := revParse(t, dir, "HEAD")
+
+	// Remove the file in a later commit, as pruning a snapshot would
+	snapshot := filepath.Join(dir, "configs", "zerb.1.lua")
+	if err := os.Remove(snapshot); err != nil {
+		t.Fatalf("cannot remove snapshot: %v", err)
+	}
+	commitFile(t, client, dir, "configs/zerb.2.lua", "Add second version", "")
+	if err := client.Stage(ctx, "configs/zerb.1.lua"); err != nil {
+		t.Fatalf("Stage() of removal error = %v", err)
+	}
+	if err := client.Commit(ctx, "Prune first version", ""); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	head := revParse(t, dir, "HEAD")
+
+	// An abbreviated hash restores the file as it was, without committing
+	if err := client.CheckoutFile(ctx, first[:8], "configs/zerb.1.lua"); err != nil {
+		t.Fatalf("CheckoutFile() error = %v", err)
+	}
+	data, err := os.ReadFile(snapshot)
+	if err != nil {
+		t.Fatalf("restored file not readable: %v", err)
+	}
+	if string(data) != "configs/zerb.1.lua" {
+		t.Errorf("restored content = %q", data)
+	}
+	if info, err := os.Stat(snapshot); err == nil && info.Mode().Perm() != 0600 {
+		t.Errorf("restored file mode = %v, want 0600", info.Mode().Perm())
+	}
+	if got := revParse(t, dir, "HEAD"); got != head {
+		t.Errorf("HEAD moved to %s, want %s", got, head)
+	}
+
+	tests := []struct {
+		name    string
+		commit  string
+		path    string
+		wantErr error
+	}{
+		{name: "file missing from commit", commit: "HEAD", path: "configs/zerb.1.lua", wantErr: ErrFileNotInCommit},
+		{name: "unknown commit", commit: "0123456789abcdef", path: "configs/zerb.1.lua"},
+		{name: "path outside repository", commit: first, path: "../zerb.1.lua"},
+		{name: "absolute path", commit: first, path: "/etc/passwd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := client.CheckoutFile(ctx, tt.commit, tt.path)
+			if err == nil {
+				t.Fatal("CheckoutFile() error = nil, want error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckoutFile() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
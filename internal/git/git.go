@@ -6,9 +6,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"path"
+	"strings"
 	"time"
 
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
 	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
@@ -20,8 +24,17 @@ var (
 	ErrNoFiles         = errors.New("no files specified to stage")
 	ErrGitInitFailed   = errors.New("git initialization failed")
 	ErrInvalidRepo     = errors.New("invalid git repository")
+	ErrForeignRepo     = errors.New("git repository was not created by ZERB")
 )
 
+// zerbMarkerDir is tracked by every ZERB repository from its initial commit
+const zerbMarkerDir = "configs"
+
+// zerbSnapshotPattern matches the config snapshots in zerbMarkerDir, e.g.
+// zerb.20250101T000000.000Z.lua. A configs directory alone is too common
+// to tell a ZERB repository apart.
+const zerbSnapshotPattern = "zerb.*.lua"
+
 // GitUserInfo contains git user configuration information
 type GitUserInfo struct {
 	Name       string
@@ -38,24 +51,47 @@ type Git interface {
 	Stage(ctx context.Context, files ...string) error
 	Commit(ctx context.Context, msg, body string) error
 	GetHeadCommit(ctx context.Context) (string, error)
+	FileCommit(ctx context.Context, path string) (string, error)
+	Log(ctx context.Context, limit int) ([]Commit, error)
+	CheckoutFile(ctx context.Context, commit, path string) error
 
 	// New initialization methods
 	InitRepo(ctx context.Context) error
 	ConfigureUser(ctx context.Context, userInfo GitUserInfo) error
 	CreateInitialCommit(ctx context.Context, message string, files []string) error
 	IsGitRepo(ctx context.Context) (bool, error)
+
+	// Branch methods
+	CurrentBranch(ctx context.Context) (string, error)
+	CheckoutBranch(ctx context.Context, name string) error
+
+	// Remote methods
+	Push(ctx context.Context, remote, branch string) error
+	Pull(ctx context.Context, remote, branch string) error
+	SetRemote(ctx context.Context, url string) error
 }
 
 // Client implements the Git interface.
 type Client struct {
-	repoPath string // Path to the git repository
+	repoPath string      // Path to the git repository
+	clock    clock.Clock // Clock for retry backoff
 }
 
 // NewClient creates a new Git client for the given repository path.
 func NewClient(repoPath string) *Client {
 	return &Client{
 		repoPath: repoPath,
+		clock:    clock.Real{},
+	}
+}
+
+// WithClock sets the clock used for retry backoff and returns the client.
+func (c *Client) WithClock(clk clock.Clock) *Client {
+	if clk == nil {
+		clk = clock.Real{}
 	}
+	c.clock = clk
+	return c
 }
 
 // Stage adds files to the git staging area using go-git.
@@ -134,6 +170,9 @@ func (c *Client) Commit(ctx context.Context, msg, body string) error {
 		},
 	})
 	if err != nil {
+		if errors.Is(err, gogit.ErrEmptyCommit) {
+			return ErrNothingToCommit
+		}
 		return fmt.Errorf("create commit: %w", err)
 	}
 
@@ -193,6 +232,55 @@ func (c *Client) IsGitRepo(ctx context.Context) (bool, error) {
 	return true, nil
 }
 
+// CheckOwnership checks that an existing repository can be used by ZERB.
+// A repository without commits, or whose HEAD tracks a config snapshot
+// under ZERB's configs directory, is accepted. Any other repository holds someone else's history
+// and an error wrapping ErrForeignRepo is returned, naming its HEAD commit
+// and origin remote.
+func (c *Client) CheckOwnership(ctx context.Context) error {
+	// Check context cancellation
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+
+	repo, err := gogit.PlainOpen(c.repoPath)
+	if err != nil {
+		return fmt.Errorf("open repository: %w", err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			// Nothing committed yet, nothing to take over
+			return nil
+		}
+		return fmt.Errorf("get HEAD: %w", err)
+	}
+
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return fmt.Errorf("get HEAD commit: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return fmt.Errorf("get HEAD tree: %w", err)
+	}
+	if configs, err := tree.Tree(zerbMarkerDir); err == nil {
+		for _, entry := range configs.Entries {
+			if ok, _ := path.Match(zerbSnapshotPattern, entry.Name); ok && entry.Mode.IsFile() {
+				return nil
+			}
+		}
+	}
+
+	subject, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+	desc := fmt.Sprintf("HEAD is %s %q", head.Hash().String()[:7], subject)
+	if remote, err := repo.Remote("origin"); err == nil && len(remote.Config().URLs) > 0 {
+		desc += fmt.Sprintf(", origin is %s", remote.Config().URLs[0])
+	}
+	return fmt.Errorf("%w: %s has history without ZERB files (%s)", ErrForeignRepo, c.repoPath, desc)
+}
+
 // ConfigureUser sets the git user name and email in repository-local config.
 // This never touches global git config to maintain ZERB isolation.
 func (c *Client) ConfigureUser(ctx context.Context, userInfo GitUserInfo) error {
@@ -273,3 +361,47 @@ func (c *Client) CreateInitialCommit(ctx context.Context, message string, files
 
 	return nil
 }
+
+// FileCommit returns the hash of the commit that added the file at path
+// (relative to the repository root), following history from HEAD.
+// Returns "" if the file has never been committed.
+func (c *Client) FileCommit(ctx context.Context, path string) (string, error) {
+	// Check context cancellation
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("context cancelled: %w", err)
+	}
+
+	repo, err := gogit.PlainOpen(c.repoPath)
+	if err != nil {
+		return "", fmt.Errorf("open repository: %w", err)
+	}
+
+	// A repository without commits has no history to search
+	if _, err := repo.Head(); err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return "", nil
+		}
+		return "", fmt.Errorf("get HEAD: %w", err)
+	}
+
+	iter, err := repo.Log(&gogit.LogOptions{FileName: &path})
+	if err != nil {
+		return "", fmt.Errorf("read history of %s: %w", path, err)
+	}
+	defer iter.Close()
+
+	// Commits come newest first; the last one touching the file added it
+	var oldest string
+	err = iter.ForEach(func(commit *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("context cancelled: %w", err)
+		}
+		oldest = commit.Hash.String()
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("read history of %s: %w", path, err)
+	}
+
+	return oldest, nil
+}
@@ -2,6 +2,7 @@ package git
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -218,8 +219,8 @@ func TestClient_Commit_NothingToCommit(t *testing.T) {
 	ctx := context.Background()
 
 	err := client.Commit(ctx, "Empty commit", "")
-	if err == nil {
-		t.Error("Commit() with nothing staged should return error")
+	if !errors.Is(err, ErrNothingToCommit) {
+		t.Errorf("Commit() with nothing staged error = %v, want ErrNothingToCommit", err)
 	}
 }
 
@@ -429,3 +430,52 @@ func TestClient_GetHeadCommit_ContextCancellation(t *testing.T) {
 		t.Error("GetHeadCommit() with cancelled context should return error")
 	}
 }
+
+func TestClient_FileCommit(t *testing.T) {
+	client, dir := setupBranchTestRepo(t)
+	ctx := context.Background()
+	addedAt := revParse(t, dir, "HEAD")
+
+	// A later commit touching another file doesn't change the answer
+	if err := os.WriteFile(filepath.Join(dir, "b.txt"), []byte("b"), 0644); err != nil {
+		t.Fatalf("cannot create test file: %v", err)
+	}
+	if err := client.Stage(ctx, "b.txt"); err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+	if err := client.Commit(ctx, "Add b", ""); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	got, err := client.FileCommit(ctx, "a.txt")
+	if err != nil {
+		t.Fatalf("FileCommit() error = %v", err)
+	}
+	if got != addedAt {
+		t.Errorf("FileCommit(a.txt) = %s, want %s", got, addedAt)
+	}
+
+	got, err = client.FileCommit(ctx, "never-committed.txt")
+	if err != nil {
+		t.Fatalf("FileCommit() error = %v", err)
+	}
+	if got != "" {
+		t.Errorf("FileCommit(never-committed.txt) = %q, want empty", got)
+	}
+}
+
+func TestClient_FileCommit_NoCommits(t *testing.T) {
+	tmpDir := t.TempDir()
+	client := NewClient(tmpDir)
+	if err := client.InitRepo(context.Background()); err != nil {
+		t.Fatalf("InitRepo() error = %v", err)
+	}
+
+	got, err := client.FileCommit(context.Background(), "a.txt")
+	if err != nil {
+		t.Fatalf("FileCommit() error = %v", err)
+	}
+	if got != "" {
+		t.Errorf("FileCommit() = %q, want empty", got)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/fsutil"
 )
 
 // gitignoreTemplate is the .gitignore template for ZERB repositories.
@@ -24,6 +26,7 @@ logs/
 
 # Tool state (not tracked)
 mise/
+installs/
 
 # Transaction state (ephemeral)
 .txn/
@@ -46,6 +49,9 @@ keyrings/
 
 # Git unavailable marker
 .zerb-no-git
+
+# Directory layout version (per machine)
+.zerb-layout
 `
 
 // WriteGitignore writes the .gitignore template to the specified path.
@@ -58,7 +64,7 @@ func WriteGitignore(path string) error {
 	}
 
 	// Write .gitignore file
-	if err := os.WriteFile(path, []byte(gitignoreTemplate), 0644); err != nil {
+	if err := fsutil.WriteFileAtomic(path, []byte(gitignoreTemplate), 0644); err != nil {
 		return fmt.Errorf("write .gitignore: %w", err)
 	}
 
@@ -2,6 +2,7 @@ package git
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -142,6 +143,58 @@ func TestConfigureUser_NoRepo(t *testing.T) {
 	}
 }
 
+// TestCheckOwnership tests telling ZERB repositories from foreign ones
+func TestCheckOwnership(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       []string // committed files, none for an empty repo
+		wantForeign bool
+	}{
+		{name: "no commits", files: nil, wantForeign: false},
+		{name: "zerb repo", files: []string{".gitignore", "configs/zerb.20250101T000000.000Z.lua"}, wantForeign: false},
+		{name: "foreign repo", files: []string{"README.md", "main.go"}, wantForeign: true},
+		{name: "foreign repo with configs dir", files: []string{"README.md", "configs/app.yaml"}, wantForeign: true},
+		{name: "foreign repo with zerb-like dir", files: []string{"configs/zerb.lua", "configs/zerb/settings.lua"}, wantForeign: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			client := NewClient(tmpDir)
+			ctx := context.Background()
+
+			if err := client.InitRepo(ctx); err != nil {
+				t.Fatalf("InitRepo() error = %v", err)
+			}
+			if err := client.ConfigureUser(ctx, GitUserInfo{Name: "Test User", Email: "test@example.com"}); err != nil {
+				t.Fatalf("ConfigureUser() error = %v", err)
+			}
+			for _, file := range tt.files {
+				path := filepath.Join(tmpDir, file)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("failed to create dir: %v", err)
+				}
+				if err := os.WriteFile(path, []byte("content"), 0644); err != nil {
+					t.Fatalf("failed to write %s: %v", file, err)
+				}
+			}
+			if len(tt.files) > 0 {
+				if err := client.CreateInitialCommit(ctx, "Initial commit", tt.files); err != nil {
+					t.Fatalf("CreateInitialCommit() error = %v", err)
+				}
+			}
+
+			err := client.CheckOwnership(ctx)
+			if got := errors.Is(err, ErrForeignRepo); got != tt.wantForeign {
+				t.Errorf("CheckOwnership() error = %v, want foreign %v", err, tt.wantForeign)
+			}
+			if !tt.wantForeign && err != nil {
+				t.Errorf("CheckOwnership() error = %v, want nil", err)
+			}
+		})
+	}
+}
+
 // TestCreateInitialCommit tests creating initial commit
 func TestCreateInitialCommit(t *testing.T) {
 	tmpDir := t.TempDir()
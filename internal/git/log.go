@@ -0,0 +1,93 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// Commit is a commit in the ZERB repository's history
+type Commit struct {
+	Hash    string
+	Subject string    // First line of the message
+	Body    string    // Rest of the message, without the separating blank line
+	Time    time.Time // Author time
+}
+
+// errLogLimit stops a log walk once enough commits are collected
+var errLogLimit = errors.New("log limit reached")
+
+// Log returns the commits that changed configs/, newest first. A limit of
+// zero or less returns all of them. A repository without commits has an
+// empty history; in a shallow clone the history ends at the oldest commit
+// present.
+func (c *Client) Log(ctx context.Context, limit int) ([]Commit, error) {
+	// Check context cancellation
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("context cancelled: %w", err)
+	}
+
+	repo, err := gogit.PlainOpen(c.repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("open repository: %w", err)
+	}
+
+	commits := []Commit{}
+
+	// A repository without commits has no history
+	if _, err := repo.Head(); err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return commits, nil
+		}
+		return nil, fmt.Errorf("get HEAD: %w", err)
+	}
+
+	iter, err := repo.Log(&gogit.LogOptions{
+		PathFilter: func(path string) bool {
+			return strings.HasPrefix(path, zerbMarkerDir+"/")
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+	defer iter.Close()
+
+	err = iter.ForEach(func(commit *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("context cancelled: %w", err)
+		}
+		commits = append(commits, newCommit(commit))
+		if limit > 0 && len(commits) >= limit {
+			return errLogLimit
+		}
+		return nil
+	})
+	switch {
+	case err == nil, errors.Is(err, errLogLimit), errors.Is(err, storer.ErrStop):
+	case errors.Is(err, plumbing.ErrObjectNotFound):
+		// A shallow clone lacks the parents of its oldest commit
+	default:
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+
+	return commits, nil
+}
+
+// newCommit converts a go-git commit, splitting its message into subject
+// and body
+func newCommit(commit *object.Commit) Commit {
+	subject, body, _ := strings.Cut(strings.TrimRight(commit.Message, "\n"), "\n")
+	return Commit{
+		Hash:    commit.Hash.String(),
+		Subject: strings.TrimSpace(subject),
+		Body:    strings.TrimLeft(body, "\n"),
+		Time:    commit.Author.When,
+	}
+}
@@ -0,0 +1,121 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// commitFile writes name in dir and commits it with msg and body
+func commitFile(t *testing.T, client *Client, dir, name, msg, body string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+		t.Fatalf("cannot create directory for %s: %v", name, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+		t.Fatalf("cannot create %s: %v", name, err)
+	}
+	if err := client.Stage(context.Background(), name); err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+	if err := client.Commit(context.Background(), msg, body); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+}
+
+// subjects returns the subjects of commits
+func subjects(commits []Commit) []string {
+	var out []string
+	for _, c := range commits {
+		out = append(out, c.Subject)
+	}
+	return out
+}
+
+func TestClient_Log(t *testing.T) {
+	dir := t.TempDir()
+	client := NewClient(dir)
+	ctx := context.Background()
+	if err := client.InitRepo(ctx); err != nil {
+		t.Fatalf("InitRepo() error = %v", err)
+	}
+	if err := client.ConfigureUser(ctx, GitUserInfo{Name: "Test User", Email: "test@example.com"}); err != nil {
+		t.Fatalf("ConfigureUser() error = %v", err)
+	}
+
+	// A repository without commits has an empty history
+	commits, err := client.Log(ctx, 0)
+	if err != nil {
+		t.Fatalf("Log() without commits error = %v", err)
+	}
+	if commits == nil || len(commits) != 0 {
+		t.Errorf("Log() without commits = %#v, want empty slice", commits)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "configs"), 0755); err != nil {
+		t.Fatalf("cannot create configs: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "configs", "zerb.1.lua"), []byte("1"), 0644); err != nil {
+		t.Fatalf("cannot create snapshot: %v", err)
+	}
+	if err := client.CreateInitialCommit(ctx, "Initialize ZERB environment", []string{"configs/zerb.1.lua"}); err != nil {
+		t.Fatalf("CreateInitialCommit() error = %v", err)
+	}
+
+	// A single commit is the whole history
+	commits, err = client.Log(ctx, 0)
+	if err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	if got := subjects(commits); !reflect.DeepEqual(got, []string{"Initialize ZERB environment"}) {
+		t.Errorf("Log() subjects = %v", got)
+	}
+
+	commitFile(t, client, dir, "configs/zerb.2.lua", "Add ~/.zshrc to tracked configs", "Source files:\n- chezmoi/source/dot_zshrc\n")
+	commitFile(t, client, dir, "notes.txt", "Unrelated change", "")
+	commitFile(t, client, dir, "configs/zerb.3.lua", "Remove ~/.zshrc from tracked configs", "")
+
+	// Only commits touching configs/ are listed, newest first
+	commits, err = client.Log(ctx, 0)
+	if err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	want := []string{"Remove ~/.zshrc from tracked configs", "Add ~/.zshrc to tracked configs", "Initialize ZERB environment"}
+	if got := subjects(commits); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Log() subjects = %v, want %v", got, want)
+	}
+	if commits[0].Hash != revParse(t, dir, "HEAD") {
+		t.Errorf("newest commit = %s, want HEAD", commits[0].Hash)
+	}
+	if commits[1].Body != "Source files:\n- chezmoi/source/dot_zshrc" {
+		t.Errorf("Body = %q", commits[1].Body)
+	}
+	if commits[0].Body != "" {
+		t.Errorf("Body of a commit without one = %q, want empty", commits[0].Body)
+	}
+	if commits[0].Time.IsZero() || commits[0].Time.Before(commits[2].Time) {
+		t.Errorf("commit times out of order: %v, %v", commits[0].Time, commits[2].Time)
+	}
+
+	// The limit keeps the newest commits
+	commits, err = client.Log(ctx, 2)
+	if err != nil {
+		t.Fatalf("Log(2) error = %v", err)
+	}
+	if got := subjects(commits); !reflect.DeepEqual(got, want[:2]) {
+		t.Errorf("Log(2) subjects = %v, want %v", got, want[:2])
+	}
+
+	// A shallow clone lists the commits it has
+	clone := t.TempDir()
+	gitRun(t, clone, "clone", "-q", "--depth", "2", "file://"+dir, ".")
+	commits, err = NewClient(clone).Log(ctx, 0)
+	if err != nil {
+		t.Fatalf("Log() in shallow clone error = %v", err)
+	}
+	if len(commits) == 0 || commits[0].Subject != want[0] {
+		t.Errorf("Log() in shallow clone subjects = %v, want starting with %q", subjects(commits), want[0])
+	}
+}
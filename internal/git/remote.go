@@ -0,0 +1,274 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// DefaultRemote is the remote pushed to when none is given
+const DefaultRemote = "origin"
+
+// pushRetries is how many times a push is retried after a transient error
+const pushRetries = 3
+
+// Remote errors. Push and the other remote operations wrap these so
+// callers can tell failures that need user action from network hiccups.
+var (
+	// ErrNonFastForward means the remote branch has commits the local
+	// branch lacks; pull before pushing
+	ErrNonFastForward = errors.New("remote has changes not in this repository (non-fast-forward)")
+	// ErrAuthFailed means the remote rejected the credentials, or none were available
+	ErrAuthFailed = errors.New("authentication with the remote failed")
+	// ErrRemoteNotFound means the remote repository does not exist
+	ErrRemoteNotFound = errors.New("remote repository not found")
+	// ErrNoRemote means the repository has no remote of the given name;
+	// set git.remote in the config
+	ErrNoRemote = errors.New("no remote configured")
+)
+
+// pushRepository pushes to a remote. Tests replace it to simulate remote
+// failures.
+var pushRepository = func(ctx context.Context, repo *gogit.Repository, opts *gogit.PushOptions) error {
+	return repo.PushContext(ctx, opts)
+}
+
+// pullWorktree pulls into a worktree. Tests replace it to simulate remote
+// failures.
+var pullWorktree = func(ctx context.Context, worktree *gogit.Worktree, opts *gogit.PullOptions) error {
+	return worktree.PullContext(ctx, opts)
+}
+
+// SetRemote points DefaultRemote at url, creating it if needed. It only
+// changes the ZERB repository's own config, never the user's git config.
+func (c *Client) SetRemote(ctx context.Context, url string) error {
+	// Check context cancellation
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+
+	if strings.TrimSpace(url) == "" {
+		return fmt.Errorf("set remote: %w", ErrNoRemote)
+	}
+
+	repo, err := gogit.PlainOpen(c.repoPath)
+	if err != nil {
+		return fmt.Errorf("open repository: %w", err)
+	}
+
+	existing, err := repo.Remote(DefaultRemote)
+	switch {
+	case err == nil:
+		urls := existing.Config().URLs
+		if len(urls) == 1 && urls[0] == url {
+			return nil
+		}
+		if err := repo.DeleteRemote(DefaultRemote); err != nil {
+			return fmt.Errorf("replace remote %s: %w", DefaultRemote, err)
+		}
+	case !errors.Is(err, gogit.ErrRemoteNotFound):
+		return fmt.Errorf("read remote %s: %w", DefaultRemote, err)
+	}
+
+	if _, err := repo.CreateRemote(&config.RemoteConfig{Name: DefaultRemote, URLs: []string{url}}); err != nil {
+		return fmt.Errorf("set remote %s: %s", DefaultRemote, redactCredentials(err.Error()))
+	}
+	return nil
+}
+
+// Pull fast-forwards branch from remote (DefaultRemote if empty). An empty
+// branch pulls the current branch. Transient network errors are retried
+// like Push; if the histories have diverged ErrNonFastForward is returned.
+// A branch that is already up to date is not an error.
+func (c *Client) Pull(ctx context.Context, remote, branch string) error {
+	// Check context cancellation
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+
+	remote, branch, ref, err := c.resolveRemoteBranch(ctx, remote, branch)
+	if err != nil {
+		return err
+	}
+
+	repo, err := gogit.PlainOpen(c.repoPath)
+	if err != nil {
+		return fmt.Errorf("open repository: %w", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("get worktree: %w", err)
+	}
+
+	opts := &gogit.PullOptions{
+		RemoteName:    remote,
+		ReferenceName: ref,
+		SingleBranch:  true,
+	}
+	return c.retryRemote(ctx, fmt.Sprintf("pull %s from %s", branch, remote), func() error {
+		return pullWorktree(ctx, worktree, opts)
+	})
+}
+
+// Push pushes branch to remote (DefaultRemote if empty). An empty branch
+// pushes the current branch. Transient network errors (connection reset,
+// timeouts, dropped connections) are retried with exponential backoff;
+// rejections such as ErrNonFastForward and ErrAuthFailed are returned
+// immediately. A remote that is already up to date is not an error.
+func (c *Client) Push(ctx context.Context, remote, branch string) error {
+	// Check context cancellation
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+
+	remote, branch, ref, err := c.resolveRemoteBranch(ctx, remote, branch)
+	if err != nil {
+		return err
+	}
+
+	repo, err := gogit.PlainOpen(c.repoPath)
+	if err != nil {
+		return fmt.Errorf("open repository: %w", err)
+	}
+
+	opts := &gogit.PushOptions{
+		RemoteName: remote,
+		RefSpecs:   []config.RefSpec{config.RefSpec(ref.String() + ":" + ref.String())},
+	}
+	return c.retryRemote(ctx, fmt.Sprintf("push %s to %s", branch, remote), func() error {
+		return pushRepository(ctx, repo, opts)
+	})
+}
+
+// resolveRemoteBranch applies the defaults for an empty remote (DefaultRemote)
+// and branch (the current branch) and validates the branch name.
+func (c *Client) resolveRemoteBranch(ctx context.Context, remote, branch string) (string, string, plumbing.ReferenceName, error) {
+	if remote == "" {
+		remote = DefaultRemote
+	}
+	if branch == "" {
+		current, err := c.CurrentBranch(ctx)
+		if err != nil {
+			return "", "", "", fmt.Errorf("get current branch: %w", err)
+		}
+		branch = current
+	}
+
+	ref := plumbing.NewBranchReferenceName(branch)
+	if err := ref.Validate(); err != nil {
+		return "", "", "", fmt.Errorf("invalid branch name %q: %w", branch, err)
+	}
+	return remote, branch, ref, nil
+}
+
+// retryRemote runs a remote operation, retrying transient network errors
+// with exponential backoff. action describes the operation in errors.
+func (c *Client) retryRemote(ctx context.Context, action string, op func() error) error {
+	var lastErr error
+	for attempt := 0; attempt <= pushRetries; attempt++ {
+		if attempt > 0 {
+			// Exponential backoff: 1s, 2s, 4s
+			backoff := time.Duration(1<<uint(attempt-1)) * time.Second
+			select {
+			case <-c.clock.After(backoff):
+			case <-ctx.Done():
+				return fmt.Errorf("%s: %w", action, ctx.Err())
+			}
+		}
+
+		err := classifyRemoteError(op())
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		if ctx.Err() != nil {
+			return fmt.Errorf("%s: %w", action, ctx.Err())
+		}
+		if !IsTransient(err) {
+			return fmt.Errorf("%s: %w", action, err)
+		}
+	}
+
+	return fmt.Errorf("%s failed after %d retries: %w", action, pushRetries, lastErr)
+}
+
+// classifyRemoteError wraps an error from a remote operation with the
+// matching remote error, so callers can use errors.Is. A remote that is
+// already up to date is not an error. Credentials embedded in remote URLs
+// are removed from the message.
+func classifyRemoteError(err error) error {
+	switch {
+	case err == nil, errors.Is(err, gogit.NoErrAlreadyUpToDate):
+		return nil
+	case errors.Is(err, gogit.ErrRemoteNotFound):
+		return fmt.Errorf("%w: set git.remote in your config, then run 'zerb sync' again", ErrNoRemote)
+	case errors.Is(err, gogit.ErrNonFastForwardUpdate),
+		strings.Contains(err.Error(), "non-fast-forward"):
+		return fmt.Errorf("%w: %s", ErrNonFastForward, redactCredentials(err.Error()))
+	case errors.Is(err, transport.ErrAuthenticationRequired),
+		errors.Is(err, transport.ErrAuthorizationFailed),
+		errors.Is(err, transport.ErrInvalidAuthMethod):
+		return fmt.Errorf("%w: %s", ErrAuthFailed, redactCredentials(err.Error()))
+	case errors.Is(err, transport.ErrRepositoryNotFound):
+		return fmt.Errorf("%w: %s", ErrRemoteNotFound, redactCredentials(err.Error()))
+	default:
+		return &remoteError{message: redactCredentials(err.Error()), wrapped: err}
+	}
+}
+
+// remoteError is a remote failure with credentials removed from its
+// message. It unwraps to the original error so IsTransient still works.
+type remoteError struct {
+	message string
+	wrapped error
+}
+
+func (e *remoteError) Error() string { return e.message }
+
+func (e *remoteError) Unwrap() error { return e.wrapped }
+
+// credentialsPattern matches the user information of a URL
+var credentialsPattern = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://)[^/@\s]+@`)
+
+// redactCredentials removes user names and tokens embedded in URLs
+func redactCredentials(s string) string {
+	return credentialsPattern.ReplaceAllString(s, "${1}***@")
+}
+
+// IsTransient reports whether err from a remote operation is a network
+// failure worth retrying. Rejections by the remote (non-fast-forward,
+// authentication, missing repository) and cancellation are not transient.
+func IsTransient(err error) bool {
+	switch {
+	case err == nil,
+		errors.Is(err, ErrNonFastForward),
+		errors.Is(err, ErrAuthFailed),
+		errors.Is(err, ErrRemoteNotFound),
+		errors.Is(err, context.Canceled),
+		errors.Is(err, context.DeadlineExceeded):
+		return false
+	case errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNABORTED),
+		errors.Is(err, syscall.EPIPE),
+		errors.Is(err, syscall.ETIMEDOUT):
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,232 @@
+// Package layout tracks the on-disk layout of the ZERB directory.
+//
+// init records the layout version in a marker file. Commands check it
+// before touching the directory and migrate older installs to the current
+// layout (creating missing directories, moving files) instead of failing
+// later with "not found" errors.
+//
+// Layout versions:
+//
+//	1  Installs created before the marker existed. The active config may be
+//	   a regular zerb.active.lua file instead of a symlink into configs/,
+//	   and installs/ is missing.
+//	2  Current layout: installs/ exists, zerb.active.lua is a symlink to a
+//	   snapshot in configs/ named by .zerb-active.
+package layout
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/fsutil"
+)
+
+const (
+	// MarkerFile is the file in the ZERB directory recording its layout version.
+	MarkerFile = ".zerb-layout"
+
+	// Version is the current layout version.
+	Version = 2
+
+	// LegacyVersion is the layout of installs without a marker file.
+	LegacyVersion = 1
+)
+
+// ErrNewerLayout is returned when the ZERB directory was written by a newer
+// version of ZERB.
+var ErrNewerLayout = errors.New("ZERB directory layout is newer than this version of ZERB supports")
+
+// dirs lists the directories of the current layout, relative to the ZERB directory
+var dirs = []string{
+	"bin",
+	"keyrings",
+	filepath.Join("cache", "downloads"),
+	filepath.Join("cache", "versions"),
+	"configs",
+	"installs",
+	"tmp",
+	"logs",
+	"mise",
+	filepath.Join("chezmoi", "source"),
+}
+
+// Dirs returns the directories of the current layout, relative to the ZERB
+// directory.
+func Dirs() []string {
+	return append([]string(nil), dirs...)
+}
+
+// Create creates the directories of the current layout and writes the
+// layout marker. It is idempotent.
+func Create(zerbDir string) error {
+	if zerbDir == "" {
+		return fmt.Errorf("zerbDir cannot be empty")
+	}
+
+	// 0700 (user-only access) protects git history and config files on
+	// multi-user systems
+	for _, dir := range append([]string{""}, dirs...) {
+		path := filepath.Join(zerbDir, dir)
+		if err := os.MkdirAll(path, 0700); err != nil {
+			return fmt.Errorf("create directory %s: %w", path, err)
+		}
+	}
+
+	return WriteVersion(zerbDir)
+}
+
+// ReadVersion returns the layout version recorded in zerbDir. A missing
+// marker means LegacyVersion.
+func ReadVersion(zerbDir string) (int, error) {
+	data, err := os.ReadFile(filepath.Join(zerbDir, MarkerFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return LegacyVersion, nil
+		}
+		return 0, fmt.Errorf("read layout marker: %w", err)
+	}
+
+	version, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || version < LegacyVersion {
+		return 0, fmt.Errorf("invalid layout marker %s: %q", MarkerFile, strings.TrimSpace(string(data)))
+	}
+	return version, nil
+}
+
+// WriteVersion records the current layout version in zerbDir.
+func WriteVersion(zerbDir string) error {
+	marker := filepath.Join(zerbDir, MarkerFile)
+	if err := fsutil.WriteFileAtomic(marker, []byte(strconv.Itoa(Version)+"\n"), 0600); err != nil {
+		return fmt.Errorf("write layout marker: %w", err)
+	}
+	return nil
+}
+
+// migration upgrades a ZERB directory from one layout version to the next
+// and returns a description of each change it made
+type migration func(zerbDir string, clk clock.Clock) ([]string, error)
+
+// migrations[v] upgrades layout version v to v+1
+var migrations = map[int]migration{
+	1: migrateV1,
+}
+
+// Migrate brings zerbDir up to the current layout and records the new
+// version. Returns a description of each change made; nil if the layout was
+// already current. Existing files are moved, never overwritten or deleted.
+// Returns ErrNewerLayout if the directory has a newer layout than Version.
+func Migrate(zerbDir string, clk clock.Clock) ([]string, error) {
+	version, err := ReadVersion(zerbDir)
+	if err != nil {
+		return nil, err
+	}
+	if version > Version {
+		return nil, fmt.Errorf("%w (directory is version %d, supported up to %d)\nUpgrade ZERB to use %s",
+			ErrNewerLayout, version, Version, zerbDir)
+	}
+	if version == Version {
+		return nil, nil
+	}
+
+	var changes []string
+	for v := version; v < Version; v++ {
+		migrate, ok := migrations[v]
+		if !ok {
+			return nil, fmt.Errorf("no layout migration from version %d", v)
+		}
+		applied, err := migrate(zerbDir, clk)
+		if err != nil {
+			return changes, fmt.Errorf("migrate layout from version %d: %w", v, err)
+		}
+		changes = append(changes, applied...)
+	}
+
+	if err := WriteVersion(zerbDir); err != nil {
+		return changes, err
+	}
+	return changes, nil
+}
+
+// migrateV1 creates the directories added since version 1 and converts a
+// regular zerb.active.lua file into a snapshot with a marker and symlink
+func migrateV1(zerbDir string, clk clock.Clock) ([]string, error) {
+	var changes []string
+
+	created := 0
+	for _, dir := range dirs {
+		path := filepath.Join(zerbDir, dir)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		if err := os.MkdirAll(path, 0700); err != nil {
+			return changes, fmt.Errorf("create directory %s: %w", path, err)
+		}
+		created++
+	}
+	if created > 0 {
+		changes = append(changes, fmt.Sprintf("created %d missing director%s", created, plural(created, "y", "ies")))
+	}
+
+	activePath := filepath.Join(zerbDir, "zerb.active.lua")
+	markerPath := filepath.Join(zerbDir, ".zerb-active")
+
+	info, err := os.Lstat(activePath)
+	switch {
+	case os.IsNotExist(err):
+		// Nothing to convert
+	case err != nil:
+		return changes, fmt.Errorf("check active config: %w", err)
+	case info.Mode().IsRegular():
+		// Move the config into configs/ as the active snapshot
+		filename := fmt.Sprintf("zerb.%s.lua", clk.Now().UTC().Format("20060102T150405.000Z"))
+		snapshotPath := filepath.Join(zerbDir, "configs", filename)
+		if _, err := os.Lstat(snapshotPath); err == nil {
+			return changes, fmt.Errorf("cannot move active config: %s already exists", snapshotPath)
+		}
+		if err := os.Rename(activePath, snapshotPath); err != nil {
+			return changes, fmt.Errorf("move active config: %w", err)
+		}
+		if err := os.Chmod(snapshotPath, 0600); err != nil {
+			return changes, fmt.Errorf("set snapshot permissions: %w", err)
+		}
+		if err := fsutil.WriteFileAtomic(markerPath, []byte(filename+"\n"), 0600); err != nil {
+			return changes, fmt.Errorf("write active marker: %w", err)
+		}
+		if err := os.Symlink(filepath.Join("configs", filename), activePath); err != nil {
+			return changes, fmt.Errorf("create active symlink: %w", err)
+		}
+		changes = append(changes, fmt.Sprintf("moved zerb.active.lua to configs/%s", filename))
+	case info.Mode()&os.ModeSymlink != 0:
+		// Recreate a missing marker from the symlink target
+		if _, err := os.Stat(markerPath); err == nil {
+			break
+		}
+		target, err := os.Readlink(activePath)
+		if err != nil {
+			return changes, fmt.Errorf("read active symlink: %w", err)
+		}
+		if filepath.Dir(target) != "configs" {
+			break
+		}
+		filename := filepath.Base(target)
+		if err := fsutil.WriteFileAtomic(markerPath, []byte(filename+"\n"), 0600); err != nil {
+			return changes, fmt.Errorf("write active marker: %w", err)
+		}
+		changes = append(changes, fmt.Sprintf("recreated .zerb-active for %s", filename))
+	}
+
+	return changes, nil
+}
+
+// plural returns one if n is 1, else many
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
@@ -0,0 +1,290 @@
+package layout
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
+)
+
+var testClock = clock.NewFake(time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC))
+
+// setupLegacyDir creates a version 1 ZERB directory: no layout marker, no
+// installs/ directory and the active config as a regular file
+func setupLegacyDir(t *testing.T) string {
+	t.Helper()
+
+	zerbDir := t.TempDir()
+	for _, dir := range []string{"bin", "configs", "mise", filepath.Join("chezmoi", "source")} {
+		if err := os.MkdirAll(filepath.Join(zerbDir, dir), 0700); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+	}
+	files := map[string]string{
+		"zerb.active.lua":                       `zerb = { tools = { "node@20.11.0" } }`,
+		"configs/zerb.20240101T000000.000Z.lua": `zerb = { tools = {} }`,
+		"chezmoi/source/dot_zshrc":              "export EDITOR=vim\n",
+		"bin/tool":                              "#!/bin/sh\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(zerbDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	return zerbDir
+}
+
+func TestCreate(t *testing.T) {
+	zerbDir := filepath.Join(t.TempDir(), "zerb")
+
+	if err := Create(zerbDir); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	// Idempotent
+	if err := Create(zerbDir); err != nil {
+		t.Fatalf("second Create() error = %v", err)
+	}
+
+	for _, dir := range append([]string{""}, Dirs()...) {
+		info, err := os.Stat(filepath.Join(zerbDir, dir))
+		if err != nil {
+			t.Errorf("directory %q missing: %v", dir, err)
+			continue
+		}
+		if info.Mode().Perm() != 0700 {
+			t.Errorf("directory %q mode = %o, want 0700", dir, info.Mode().Perm())
+		}
+	}
+
+	version, err := ReadVersion(zerbDir)
+	if err != nil {
+		t.Fatalf("ReadVersion() error = %v", err)
+	}
+	if version != Version {
+		t.Errorf("ReadVersion() = %d, want %d", version, Version)
+	}
+
+	if err := Create(""); err == nil {
+		t.Error("Create(\"\") expected error")
+	}
+}
+
+func TestCreate_PermissionDenied(t *testing.T) {
+	// Root can write to read-only directories
+	if os.Getuid() == 0 {
+		t.Skip("skipping permission test when running as root")
+	}
+
+	readOnlyDir := filepath.Join(t.TempDir(), "readonly")
+	if err := os.Mkdir(readOnlyDir, 0555); err != nil {
+		t.Fatalf("failed to create read-only dir: %v", err)
+	}
+
+	if err := Create(readOnlyDir); err == nil {
+		t.Error("Create() in a read-only directory succeeded, want error")
+	}
+}
+
+func TestReadVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		marker  *string
+		want    int
+		wantErr bool
+	}{
+		{name: "missing marker is legacy", marker: nil, want: LegacyVersion},
+		{name: "current", marker: ptr("2\n"), want: 2},
+		{name: "newer", marker: ptr("7"), want: 7},
+		{name: "garbage", marker: ptr("two"), wantErr: true},
+		{name: "zero", marker: ptr("0"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zerbDir := t.TempDir()
+			if tt.marker != nil {
+				if err := os.WriteFile(filepath.Join(zerbDir, MarkerFile), []byte(*tt.marker), 0600); err != nil {
+					t.Fatalf("failed to write marker: %v", err)
+				}
+			}
+
+			got, err := ReadVersion(zerbDir)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ReadVersion() = %d, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadVersion() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ReadVersion() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrate_FromLegacy(t *testing.T) {
+	zerbDir := setupLegacyDir(t)
+
+	changes, err := Migrate(zerbDir, testClock)
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	wantChanges := []string{
+		"created 6 missing directories",
+		"moved zerb.active.lua to configs/zerb.20250304T050607.000Z.lua",
+	}
+	if strings.Join(changes, "\n") != strings.Join(wantChanges, "\n") {
+		t.Errorf("changes = %q, want %q", changes, wantChanges)
+	}
+
+	// Every directory of the current layout exists
+	for _, dir := range Dirs() {
+		if info, err := os.Stat(filepath.Join(zerbDir, dir)); err != nil || !info.IsDir() {
+			t.Errorf("directory %s missing after migration", dir)
+		}
+	}
+
+	// The old active config is now the active snapshot, unchanged
+	snapshot := "zerb.20250304T050607.000Z.lua"
+	content, err := os.ReadFile(filepath.Join(zerbDir, "zerb.active.lua"))
+	if err != nil {
+		t.Fatalf("failed to read active config: %v", err)
+	}
+	if string(content) != `zerb = { tools = { "node@20.11.0" } }` {
+		t.Errorf("active config = %q, want original content", content)
+	}
+	target, err := os.Readlink(filepath.Join(zerbDir, "zerb.active.lua"))
+	if err != nil {
+		t.Fatalf("zerb.active.lua is not a symlink: %v", err)
+	}
+	if target != filepath.Join("configs", snapshot) {
+		t.Errorf("symlink target = %q, want configs/%s", target, snapshot)
+	}
+	marker, err := os.ReadFile(filepath.Join(zerbDir, ".zerb-active"))
+	if err != nil {
+		t.Fatalf("failed to read marker: %v", err)
+	}
+	if strings.TrimSpace(string(marker)) != snapshot {
+		t.Errorf("marker = %q, want %q", marker, snapshot)
+	}
+	info, err := os.Stat(filepath.Join(zerbDir, "configs", snapshot))
+	if err != nil {
+		t.Fatalf("snapshot missing: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("snapshot mode = %o, want 0600", info.Mode().Perm())
+	}
+
+	// Existing files are untouched
+	for name, want := range map[string]string{
+		"configs/zerb.20240101T000000.000Z.lua": `zerb = { tools = {} }`,
+		"chezmoi/source/dot_zshrc":              "export EDITOR=vim\n",
+		"bin/tool":                              "#!/bin/sh\n",
+	} {
+		got, err := os.ReadFile(filepath.Join(zerbDir, name))
+		if err != nil {
+			t.Errorf("%s lost: %v", name, err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	// The layout is now current, and migrating again is a no-op
+	version, err := ReadVersion(zerbDir)
+	if err != nil || version != Version {
+		t.Errorf("ReadVersion() = %d, %v; want %d", version, err, Version)
+	}
+	changes, err = Migrate(zerbDir, testClock)
+	if err != nil {
+		t.Fatalf("second Migrate() error = %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("second Migrate() changes = %q, want none", changes)
+	}
+}
+
+func TestMigrate_RecreatesMissingMarker(t *testing.T) {
+	zerbDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(zerbDir, "configs"), 0700); err != nil {
+		t.Fatalf("failed to create configs: %v", err)
+	}
+	snapshot := "zerb.20240101T000000.000Z.lua"
+	if err := os.WriteFile(filepath.Join(zerbDir, "configs", snapshot), []byte("zerb = {}"), 0600); err != nil {
+		t.Fatalf("failed to write snapshot: %v", err)
+	}
+	if err := os.Symlink(filepath.Join("configs", snapshot), filepath.Join(zerbDir, "zerb.active.lua")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	changes, err := Migrate(zerbDir, testClock)
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if len(changes) != 2 || changes[1] != "recreated .zerb-active for "+snapshot {
+		t.Errorf("changes = %q", changes)
+	}
+
+	marker, err := os.ReadFile(filepath.Join(zerbDir, ".zerb-active"))
+	if err != nil {
+		t.Fatalf("failed to read marker: %v", err)
+	}
+	if strings.TrimSpace(string(marker)) != snapshot {
+		t.Errorf("marker = %q, want %q", marker, snapshot)
+	}
+}
+
+func TestMigrate_RefusesToOverwriteSnapshot(t *testing.T) {
+	zerbDir := setupLegacyDir(t)
+
+	// A snapshot already has the name the migration would use
+	existing := filepath.Join(zerbDir, "configs", "zerb.20250304T050607.000Z.lua")
+	if err := os.WriteFile(existing, []byte("keep me"), 0600); err != nil {
+		t.Fatalf("failed to write snapshot: %v", err)
+	}
+
+	if _, err := Migrate(zerbDir, testClock); err == nil {
+		t.Fatal("Migrate() expected error")
+	}
+
+	got, err := os.ReadFile(existing)
+	if err != nil || string(got) != "keep me" {
+		t.Errorf("existing snapshot = %q, %v; want it untouched", got, err)
+	}
+	if _, err := os.ReadFile(filepath.Join(zerbDir, "zerb.active.lua")); err != nil {
+		t.Errorf("active config lost: %v", err)
+	}
+	// The marker is not advanced past a failed migration
+	if version, _ := ReadVersion(zerbDir); version != LegacyVersion {
+		t.Errorf("ReadVersion() = %d, want %d", version, LegacyVersion)
+	}
+}
+
+func TestMigrate_NewerLayout(t *testing.T) {
+	zerbDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(zerbDir, MarkerFile), []byte("99\n"), 0600); err != nil {
+		t.Fatalf("failed to write marker: %v", err)
+	}
+
+	_, err := Migrate(zerbDir, testClock)
+	if !errors.Is(err, ErrNewerLayout) {
+		t.Fatalf("Migrate() error = %v, want ErrNewerLayout", err)
+	}
+	if !strings.Contains(err.Error(), "version 99") {
+		t.Errorf("error = %v, want it to name the version", err)
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
@@ -3,28 +3,59 @@ package platform
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
+	"sync"
 
 	"github.com/shirou/gopsutil/v4/host"
 )
 
 // RealDetector implements Detector using actual platform detection.
-type RealDetector struct{}
+// The first successful detection is cached, so repeated calls do not probe
+// the filesystem again.
+type RealDetector struct {
+	root string // filesystem root probed for libc, container and WSL hints
+
+	mu     sync.Mutex
+	cached *Info
+}
 
 // NewDetector creates a new platform detector.
 func NewDetector() Detector {
-	return &RealDetector{}
+	return &RealDetector{root: "/"}
 }
 
 // Detect performs platform detection and returns platform information.
 // It uses runtime.GOOS and runtime.GOARCH for OS and architecture,
-// and gopsutil for Linux distribution details.
+// and gopsutil for Linux distribution details. On Linux it also probes
+// the filesystem for the C library, containers and WSL.
 //
 // On Linux, if gopsutil fails to detect the distribution, it sets
 // distro fields to empty strings and continues (graceful fallback).
 // This allows basic OS/arch detection to work even when distro
 // detection fails.
+//
+// Each call returns a copy of the cached result, so callers may modify it.
 func (d *RealDetector) Detect(ctx context.Context) (*Info, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.cached == nil {
+		info, err := d.detect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		d.cached = info
+	}
+
+	info := *d.cached
+	return &info, nil
+}
+
+// detect performs the detection that Detect caches
+func (d *RealDetector) detect(ctx context.Context) (*Info, error) {
 	info := &Info{
 		OS:      runtime.GOOS,
 		ArchRaw: runtime.GOARCH,
@@ -37,19 +68,20 @@ func (d *RealDetector) Detect(ctx context.Context) (*Info, error) {
 	}
 	info.Arch = arch
 
-	// Detect Linux distribution details using gopsutil (Linux only)
-	if runtime.GOOS == "linux" {
-		platform, family, version, err := host.PlatformInformationWithContext(ctx)
-		if err != nil {
-			// Check if context was cancelled - this is a hard failure
-			if ctx.Err() != nil {
-				return nil, fmt.Errorf("platform detection cancelled: %w", ctx.Err())
-			}
-			// Graceful fallback for detection failures only
-			// Continue with OS/arch only - most configs won't need distro-specific logic
-			return info, nil
-		}
+	if runtime.GOOS != "linux" {
+		return info, nil
+	}
 
+	// Detect Linux distribution details using gopsutil
+	platform, family, version, err := host.PlatformInformationWithContext(ctx)
+	if err != nil {
+		// Check if context was cancelled - this is a hard failure
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("platform detection cancelled: %w", ctx.Err())
+		}
+		// Graceful fallback for detection failures only
+		// Continue without distro details - most configs won't need distro-specific logic
+	} else {
 		// Normalize and validate platform information
 		platform = normalizePlatform(platform)
 		family = mapFamily(family)
@@ -63,5 +95,75 @@ func (d *RealDetector) Detect(ctx context.Context) (*Info, error) {
 		}
 	}
 
+	root := d.root
+	if root == "" {
+		root = "/"
+	}
+	info.Libc = detectLibc(root, info.Family)
+	info.InContainer = detectContainer(root)
+	info.WSL = detectWSL(root)
+
 	return info, nil
 }
+
+// detectLibc returns the C library of a Linux system under root, judged by
+// the dynamic loaders installed, or "" if there is none (e.g. a distroless
+// image). Alpine is always musl: its gcompat package installs a glibc
+// loader name as a shim.
+func detectLibc(root, family string) string {
+	if family == FamilyAlpine {
+		return LibcMusl
+	}
+	for _, pattern := range []string{"lib/ld-linux*.so*", "lib64/ld-linux*.so*"} {
+		if matches, _ := filepath.Glob(filepath.Join(root, pattern)); len(matches) > 0 {
+			return LibcGlibc
+		}
+	}
+	if matches, _ := filepath.Glob(filepath.Join(root, "lib", "ld-musl-*.so*")); len(matches) > 0 {
+		return LibcMusl
+	}
+	return ""
+}
+
+// containerCgroupHints are cgroup path fragments of container runtimes
+var containerCgroupHints = []string{"docker", "kubepods", "containerd", "libpod", "lxc"}
+
+// detectContainer reports whether the system under root is a container:
+// the marker files Docker and Podman create, or the cgroup of PID 1 naming
+// a container runtime
+func detectContainer(root string) bool {
+	for _, marker := range []string{".dockerenv", filepath.Join("run", ".containerenv")} {
+		if _, err := os.Stat(filepath.Join(root, marker)); err == nil {
+			return true
+		}
+	}
+
+	cgroup, err := os.ReadFile(filepath.Join(root, "proc", "1", "cgroup"))
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(cgroup), "\n") {
+		// Format: hierarchy-ID:controllers:path
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		for _, hint := range containerCgroupHints {
+			if strings.Contains(parts[2], hint) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// detectWSL reports whether the system under root runs on the WSL kernel,
+// whose release names Microsoft
+func detectWSL(root string) bool {
+	release, err := os.ReadFile(filepath.Join(root, "proc", "sys", "kernel", "osrelease"))
+	if err != nil {
+		return false
+	}
+	kernel := strings.ToLower(string(release))
+	return strings.Contains(kernel, "microsoft") || strings.Contains(kernel, "wsl")
+}
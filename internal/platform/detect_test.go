@@ -2,6 +2,8 @@ package platform
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 )
@@ -350,3 +352,116 @@ func TestMockDetector(t *testing.T) {
 		t.Errorf("MockDetector.Detect() = %+v, want %+v", info, expectedInfo)
 	}
 }
+
+func TestRealDetector_Detect_Cached(t *testing.T) {
+	detector := NewDetector()
+	ctx := context.Background()
+
+	first, err := detector.Detect(ctx)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	first.OS = "changed"
+
+	// The cached result is returned again, unaffected by the caller's change
+	second, err := detector.Detect(ctx)
+	if err != nil {
+		t.Fatalf("second Detect() error = %v", err)
+	}
+	if second.OS != runtime.GOOS {
+		t.Errorf("second Detect() OS = %q, want %q", second.OS, runtime.GOOS)
+	}
+	if d := detector.(*RealDetector); d.cached == nil || d.cached.OS != runtime.GOOS {
+		t.Errorf("cached = %+v, want the first detection", d.cached)
+	}
+}
+
+// writeRootFiles creates files under a fake filesystem root
+func writeRootFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return root
+}
+
+func TestDetectLibc(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		family string
+		want   string
+	}{
+		{"glibc amd64", map[string]string{"lib64/ld-linux-x86-64.so.2": ""}, FamilyDebian, LibcGlibc},
+		{"glibc arm64", map[string]string{"lib/ld-linux-aarch64.so.1": ""}, FamilyRHEL, LibcGlibc},
+		{"musl", map[string]string{"lib/ld-musl-x86_64.so.1": ""}, FamilyUnknown, LibcMusl},
+		{"musl loader next to glibc", map[string]string{"lib/ld-musl-x86_64.so.1": "", "lib64/ld-linux-x86-64.so.2": ""}, FamilyDebian, LibcGlibc},
+		{"alpine with gcompat", map[string]string{"lib/ld-linux-x86-64.so.2": ""}, FamilyAlpine, LibcMusl},
+		{"no loader", nil, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := writeRootFiles(t, tt.files)
+			if got := detectLibc(root, tt.family); got != tt.want {
+				t.Errorf("detectLibc() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectContainer(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  bool
+	}{
+		{"docker marker", map[string]string{".dockerenv": ""}, true},
+		{"podman marker", map[string]string{"run/.containerenv": ""}, true},
+		{"docker cgroup", map[string]string{"proc/1/cgroup": "12:cpuset:/docker/0123abcd\n"}, true},
+		{"kubernetes cgroup", map[string]string{"proc/1/cgroup": "0::/kubepods/besteffort/pod1234\n"}, true},
+		{"host cgroup v2", map[string]string{"proc/1/cgroup": "0::/init.scope\n"}, false},
+		{"nothing", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := writeRootFiles(t, tt.files)
+			if got := detectContainer(root); got != tt.want {
+				t.Errorf("detectContainer() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectWSL(t *testing.T) {
+	tests := []struct {
+		name    string
+		release string
+		want    bool
+	}{
+		{"WSL2", "5.15.153.1-microsoft-standard-WSL2\n", true},
+		{"WSL1", "4.4.0-19041-Microsoft\n", true},
+		{"native", "6.8.0-45-generic\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := writeRootFiles(t, map[string]string{"proc/sys/kernel/osrelease": tt.release})
+			if got := detectWSL(root); got != tt.want {
+				t.Errorf("detectWSL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if detectWSL(t.TempDir()) {
+		t.Error("detectWSL() = true without /proc")
+	}
+}
@@ -53,6 +53,16 @@ func InjectPlatformTable(L *lua.LState, info *Info) error {
 	L.SetField(platformTable, "is_alpine", lua.LBool(info.IsAlpine()))
 	L.SetField(platformTable, "is_gentoo", lua.LBool(info.IsGentoo()))
 
+	// C library (nil on non-Linux or if unknown) and environment booleans
+	if info.IsLinux() && info.Libc != "" {
+		L.SetField(platformTable, "libc", lua.LString(info.Libc))
+	} else {
+		L.SetField(platformTable, "libc", lua.LNil)
+	}
+	L.SetField(platformTable, "is_musl", lua.LBool(info.IsMusl()))
+	L.SetField(platformTable, "is_container", lua.LBool(info.IsContainer()))
+	L.SetField(platformTable, "is_wsl", lua.LBool(info.IsWSL()))
+
 	// Helper function: when(condition, value)
 	// Returns value if condition is true, nil otherwise
 	whenFunc := L.NewFunction(func(L *lua.LState) int {
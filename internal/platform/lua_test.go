@@ -59,6 +59,10 @@ func TestInjectPlatformTable_Linux(t *testing.T) {
 		{"is_arch_family", `return platform.is_arch_family`, lua.LFalse},
 		{"is_alpine", `return platform.is_alpine`, lua.LFalse},
 		{"is_gentoo", `return platform.is_gentoo`, lua.LFalse},
+		{"libc", `return platform.libc`, lua.LNil},
+		{"is_musl", `return platform.is_musl`, lua.LFalse},
+		{"is_container", `return platform.is_container`, lua.LFalse},
+		{"is_wsl", `return platform.is_wsl`, lua.LFalse},
 	}
 
 	for _, tt := range tests {
@@ -338,3 +342,37 @@ func TestPlatformTable_UsageExample(t *testing.T) {
 		t.Errorf("expected 2 tools, got %d", count)
 	}
 }
+
+func TestInjectPlatformTable_LibcAndEnvironment(t *testing.T) {
+	tests := []struct {
+		name string
+		info *Info
+		code string
+		want lua.LValue
+	}{
+		{"musl libc", &Info{OS: "linux", Arch: "amd64", Libc: LibcMusl}, `return platform.libc`, lua.LString("musl")},
+		{"is_musl", &Info{OS: "linux", Arch: "amd64", Libc: LibcMusl}, `return platform.is_musl`, lua.LTrue},
+		{"glibc", &Info{OS: "linux", Arch: "amd64", Libc: LibcGlibc}, `return platform.is_musl`, lua.LFalse},
+		{"no libc on macOS", &Info{OS: "darwin", Arch: "arm64", Libc: LibcMusl}, `return platform.libc`, lua.LNil},
+		{"is_container", &Info{OS: "linux", Arch: "amd64", InContainer: true}, `return platform.is_container`, lua.LTrue},
+		{"is_wsl", &Info{OS: "linux", Arch: "amd64", WSL: true}, `return platform.is_wsl`, lua.LTrue},
+		{"conditional", &Info{OS: "linux", Arch: "amd64", Libc: LibcMusl}, `return platform.is_musl and "static" or "dynamic"`, lua.LString("static")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			L := lua.NewState()
+			defer L.Close()
+
+			if err := InjectPlatformTable(L, tt.info); err != nil {
+				t.Fatalf("InjectPlatformTable() error = %v", err)
+			}
+			if err := L.DoString(tt.code); err != nil {
+				t.Fatalf("failed to execute code: %v", err)
+			}
+			if got := L.Get(-1); got.Type() != tt.want.Type() || got.String() != tt.want.String() {
+				t.Errorf("%s = %v, want %v", tt.code, got, tt.want)
+			}
+		})
+	}
+}
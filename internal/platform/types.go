@@ -22,14 +22,23 @@ const (
 	FamilyUnknown = "unknown" // Unrecognized distributions
 )
 
+// C library constants for Info.Libc.
+const (
+	LibcGlibc = "glibc" // GNU C library
+	LibcMusl  = "musl"  // musl, e.g. on Alpine
+)
+
 // Info contains platform detection information.
 type Info struct {
-	OS       string // "linux", "darwin", "windows"
-	Arch     string // "amd64", "arm64" (normalized)
-	ArchRaw  string // original GOARCH (e.g., "x86_64", "aarch64")
-	Platform string // distro ID (Linux only, e.g., "ubuntu", "arch")
-	Family   string // canonical family (e.g., "debian", "rhel", "arch")
-	Version  string // distro version (Linux only, e.g., "22.04")
+	OS          string // "linux", "darwin", "windows"
+	Arch        string // "amd64", "arm64" (normalized)
+	ArchRaw     string // original GOARCH (e.g., "x86_64", "aarch64")
+	Platform    string // distro ID (Linux only, e.g., "ubuntu", "arch")
+	Family      string // canonical family (e.g., "debian", "rhel", "arch")
+	Version     string // distro version (Linux only, e.g., "22.04")
+	Libc        string // LibcGlibc or LibcMusl (Linux only, empty if unknown)
+	InContainer bool   // running inside a container (Docker, Podman, LXC, Kubernetes)
+	WSL         bool   // running under Windows Subsystem for Linux
 }
 
 // Distro contains Linux distribution information.
@@ -118,6 +127,21 @@ func (i *Info) IsGentoo() bool {
 	return i.OS == "linux" && i.Family == FamilyGentoo
 }
 
+// IsMusl returns true if the platform is Linux with the musl C library.
+func (i *Info) IsMusl() bool {
+	return i.OS == "linux" && i.Libc == LibcMusl
+}
+
+// IsContainer returns true if running inside a container.
+func (i *Info) IsContainer() bool {
+	return i.InContainer
+}
+
+// IsWSL returns true if running under Windows Subsystem for Linux.
+func (i *Info) IsWSL() bool {
+	return i.OS == "linux" && i.WSL
+}
+
 // Detector is the interface for platform detection.
 type Detector interface {
 	Detect(ctx context.Context) (*Info, error)
@@ -0,0 +1,132 @@
+// Package prompt provides yes/no confirmation prompts.
+//
+// Commands that ask before doing something (uninit, untrack-all, adopting
+// drift) share a Prompter so a single global --yes flag, or the
+// ZERB_ASSUME_YES environment variable, can answer every confirmation for
+// scripted use. A Prompter only answers questions; safety checks that refuse
+// an operation outright are not confirmations and must not consult it.
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// EnvAssumeYes is the environment variable that auto-confirms all prompts
+const EnvAssumeYes = "ZERB_ASSUME_YES"
+
+// ErrNonInteractive is returned by Confirm when an answer is needed but the
+// Prompter may not read input.
+var ErrNonInteractive = errors.New("confirmation required but running non-interactively (use --yes to confirm)")
+
+// Prompter asks yes/no questions on an input and output stream.
+type Prompter struct {
+	in             *bufio.Reader
+	out            io.Writer
+	assumeYes      bool
+	nonInteractive bool
+}
+
+// NewPrompter creates a Prompter that reads answers from in and writes
+// questions to out.
+func NewPrompter(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{
+		in:  bufio.NewReader(in),
+		out: out,
+	}
+}
+
+// WithAssumeYes returns a new Prompter that answers every confirmation
+// affirmatively without reading input.
+func (p *Prompter) WithAssumeYes(assumeYes bool) *Prompter {
+	return &Prompter{
+		in:             p.in,
+		out:            p.out,
+		assumeYes:      assumeYes,
+		nonInteractive: p.nonInteractive,
+	}
+}
+
+// WithNonInteractive returns a new Prompter that never reads input, for
+// runs without a terminal: confirmations are answered by assume-yes or
+// fail with ErrNonInteractive instead of waiting for an answer.
+func (p *Prompter) WithNonInteractive(nonInteractive bool) *Prompter {
+	return &Prompter{
+		in:             p.in,
+		out:            p.out,
+		assumeYes:      p.assumeYes,
+		nonInteractive: nonInteractive,
+	}
+}
+
+// AssumeYes reports whether confirmations are answered automatically.
+func (p *Prompter) AssumeYes() bool {
+	return p.assumeYes
+}
+
+// Confirm writes question and reads an answer; "y" or "yes" (in any case)
+// confirms, anything else declines. The question should include its own
+// answer hint, e.g. "Continue? (yes/no): ". When assuming yes, the automatic
+// answer is echoed so the output still shows what was decided.
+func (p *Prompter) Confirm(question string) (bool, error) {
+	fmt.Fprint(p.out, question)
+
+	if p.assumeYes {
+		fmt.Fprintln(p.out, "yes")
+		return true, nil
+	}
+	if p.nonInteractive {
+		fmt.Fprintln(p.out)
+		return false, ErrNonInteractive
+	}
+
+	response, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || response == "") {
+		return false, fmt.Errorf("read input: %w", err)
+	}
+
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response == "yes" || response == "y", nil
+}
+
+// Choose writes question and reads a menu choice; an empty answer selects
+// def. When assuming yes, def is chosen and echoed without reading input,
+// so the suggested choice is what scripted runs get.
+func (p *Prompter) Choose(question, def string) (string, error) {
+	fmt.Fprint(p.out, question)
+
+	if p.assumeYes {
+		fmt.Fprintln(p.out, def)
+		return def, nil
+	}
+	if p.nonInteractive {
+		fmt.Fprintln(p.out)
+		return "", ErrNonInteractive
+	}
+
+	response, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || response == "") {
+		return "", fmt.Errorf("read input: %w", err)
+	}
+
+	response = strings.TrimSpace(response)
+	if response == "" {
+		return def, nil
+	}
+	return response, nil
+}
+
+// AssumeYesFromEnv reports whether ZERB_ASSUME_YES is set to a true value
+// ("1", "true" or "yes", in any case).
+func AssumeYesFromEnv() bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(EnvAssumeYes))) {
+	case "1", "true", "yes":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,155 @@
+package prompt
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPrompter_Confirm(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{"yes", "yes\n", true},
+		{"y", "y\n", true},
+		{"uppercase", "YES\n", true},
+		{"surrounding whitespace", "  y  \n", true},
+		{"no", "no\n", false},
+		{"empty", "\n", false},
+		{"other", "maybe\n", false},
+		{"no trailing newline", "y", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			p := NewPrompter(strings.NewReader(tt.input), &out)
+
+			got, err := p.Confirm("Continue? (yes/no): ")
+			if err != nil {
+				t.Fatalf("Confirm() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Confirm() = %v, want %v", got, tt.want)
+			}
+			if out.String() != "Continue? (yes/no): " {
+				t.Errorf("output = %q, want the question", out.String())
+			}
+		})
+	}
+}
+
+func TestPrompter_Confirm_EOF(t *testing.T) {
+	p := NewPrompter(strings.NewReader(""), &bytes.Buffer{})
+
+	if _, err := p.Confirm("Continue? "); err == nil {
+		t.Error("Confirm() expected error on closed input, got nil")
+	}
+}
+
+func TestPrompter_WithAssumeYes(t *testing.T) {
+	var out bytes.Buffer
+	// Input that would decline if it were read
+	p := NewPrompter(strings.NewReader("no\n"), &out).WithAssumeYes(true)
+
+	got, err := p.Confirm("Continue? (yes/no): ")
+	if err != nil {
+		t.Fatalf("Confirm() error = %v", err)
+	}
+	if !got {
+		t.Error("Confirm() = false, want true when assuming yes")
+	}
+	if !p.AssumeYes() {
+		t.Error("AssumeYes() = false, want true")
+	}
+	if out.String() != "Continue? (yes/no): yes\n" {
+		t.Errorf("output = %q, want the question followed by the automatic answer", out.String())
+	}
+}
+
+func TestPrompter_WithNonInteractive(t *testing.T) {
+	in := strings.NewReader("yes\n")
+
+	// Declines without reading input
+	p := NewPrompter(in, &bytes.Buffer{}).WithNonInteractive(true)
+	got, err := p.Confirm("Continue? (yes/no): ")
+	if !errors.Is(err, ErrNonInteractive) || got {
+		t.Errorf("Confirm() = %v, %v, want false, ErrNonInteractive", got, err)
+	}
+	if in.Len() != len("yes\n") {
+		t.Error("Confirm() read input while non-interactive")
+	}
+
+	// Assume-yes still answers
+	got, err = p.WithAssumeYes(true).Confirm("Continue? (yes/no): ")
+	if err != nil || !got {
+		t.Errorf("Confirm() with assume-yes = %v, %v, want true, nil", got, err)
+	}
+}
+
+func TestPrompter_Choose(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		assumeYes bool
+		want      string
+	}{
+		{"choice", "3\n", false, "3"},
+		{"surrounding whitespace", "  2  \n", false, "2"},
+		{"empty selects default", "\n", false, "1"},
+		{"no trailing newline", "4", false, "4"},
+		{"assume yes selects default", "3\n", true, "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPrompter(strings.NewReader(tt.input), &bytes.Buffer{}).WithAssumeYes(tt.assumeYes)
+
+			got, err := p.Choose("Choice [1]: ", "1")
+			if err != nil {
+				t.Fatalf("Choose() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Choose() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Non-interactive runs never wait for a choice
+	in := strings.NewReader("2\n")
+	p := NewPrompter(in, &bytes.Buffer{}).WithNonInteractive(true)
+	if _, err := p.Choose("Choice [1]: ", "1"); !errors.Is(err, ErrNonInteractive) {
+		t.Errorf("Choose() error = %v, want ErrNonInteractive", err)
+	}
+	if in.Len() != len("2\n") {
+		t.Error("Choose() read input while non-interactive")
+	}
+}
+
+func TestAssumeYesFromEnv(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"", false},
+		{"1", true},
+		{"true", true},
+		{"TRUE", true},
+		{"yes", true},
+		{"0", false},
+		{"false", false},
+		{"no", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv(EnvAssumeYes, tt.value)
+			if got := AssumeYesFromEnv(); got != tt.want {
+				t.Errorf("AssumeYesFromEnv() with %q = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
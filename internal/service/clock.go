@@ -1,19 +1,18 @@
 package service
 
-import "time"
+import (
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
+)
 
 // Clock provides time operations. This interface enables deterministic testing.
-type Clock interface {
-	Now() time.Time
-}
+// It is the shared clock.Clock so services and lower-level packages can be
+// driven by the same time source.
+type Clock = clock.Clock
 
 // RealClock implements Clock using the actual system time.
-type RealClock struct{}
-
-// Now returns the current time.
-func (RealClock) Now() time.Time {
-	return time.Now()
-}
+type RealClock = clock.Real
 
 // TestClock implements Clock with a fixed time for testing.
 type TestClock struct {
@@ -24,3 +23,10 @@ type TestClock struct {
 func (t TestClock) Now() time.Time {
 	return t.FixedTime
 }
+
+// After returns a channel that fires immediately with the fixed time.
+func (t TestClock) After(time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	ch <- t.FixedTime
+	return ch
+}
@@ -3,10 +3,13 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/ZebulonRouseFrantzich/zerb/internal/chezmoi"
 	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
@@ -21,6 +24,11 @@ const (
 	ConfigFilePermissions = 0644
 	// TmpDirPermissions sets the permission mode for temporary directories.
 	TmpDirPermissions = 0700
+
+	// DefaultPathAddTimeout bounds how long adding a single file may take.
+	DefaultPathAddTimeout = 30 * time.Second
+	// DefaultRecursiveAddTimeout bounds how long adding a directory tree may take.
+	DefaultRecursiveAddTimeout = 90 * time.Second
 )
 
 // ConfigParser provides config parsing functionality.
@@ -41,6 +49,11 @@ type ConfigAddService struct {
 	generator ConfigGenerator
 	clock     Clock
 	zerbDir   string
+
+	pathTimeout      time.Duration
+	recursiveTimeout time.Duration
+	hostBranch       string
+	roots            *config.PathRoots
 }
 
 // NewConfigAddService creates a new config add service with dependency injection.
@@ -59,7 +72,126 @@ func NewConfigAddService(
 		generator: generator,
 		clock:     clock,
 		zerbDir:   zerbDir,
+
+		pathTimeout:      DefaultPathAddTimeout,
+		recursiveTimeout: DefaultRecursiveAddTimeout,
+	}
+}
+
+// WithAddTimeouts sets the per-path timeouts used when adding files (single)
+// and directories (recursive). Non-positive values keep the current setting.
+func (s *ConfigAddService) WithAddTimeouts(single, recursive time.Duration) *ConfigAddService {
+	if single > 0 {
+		s.pathTimeout = single
+	}
+	if recursive > 0 {
+		s.recursiveTimeout = recursive
+	}
+	return s
+}
+
+// WithHostBranch sets the branch snapshot commits go to when the config
+// enables per-host branches, instead of deriving it from the machine.
+func (s *ConfigAddService) WithHostBranch(name string) *ConfigAddService {
+	s.hostBranch = name
+	return s
+}
+
+// WithPathRoots sets the directories config paths may refer to, including
+// caller-supplied base directories (e.g. "$DOTFILES/..."). Pass the same
+// roots to the parser so the resulting config validates. By default the
+// home directory, $XDG_CONFIG_HOME and $XDG_DATA_HOME are allowed.
+func (s *ConfigAddService) WithPathRoots(roots config.PathRoots) *ConfigAddService {
+	s.roots = &roots
+	return s
+}
+
+// pathRoots returns the configured path roots or the defaults.
+func (s *ConfigAddService) pathRoots() (config.PathRoots, error) {
+	if s.roots != nil {
+		return *s.roots, nil
+	}
+	return config.DefaultPathRoots()
+}
+
+// addTimeout returns the timeout for adding a path with the given options.
+func (s *ConfigAddService) addTimeout(opts ConfigOptions) time.Duration {
+	if opts.Recursive {
+		return s.recursiveTimeout
+	}
+	return s.pathTimeout
+}
+
+// addPath adds a single path to chezmoi, bounded by the per-path timeout,
+// and returns the source entry it created.
+// target is the expanded target override, or "" to apply the file at path.
+// A per-path timeout is reported with the path so a pathological directory
+// is easy to identify.
+func (s *ConfigAddService) addPath(ctx context.Context, path, target string, opts ConfigOptions) (*chezmoi.AddResult, error) {
+	timeout := s.addTimeout(opts)
+	pathCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	added, err := s.chezmoi.Add(pathCtx, path, chezmoi.AddOptions{
+		Recursive: opts.Recursive,
+		Template:  opts.Template,
+		Secrets:   opts.Secrets,
+		Private:   opts.Private,
+		Target:    target,
+	})
+	if err != nil && ctx.Err() == nil && errors.Is(pathCtx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("adding %q timed out after %s: %w", path, timeout, context.DeadlineExceeded)
+	}
+	return added, err
+}
+
+// followSymlinks returns req with each symlinked path whose options set
+// FollowSymlinks replaced by the real file it points to, which must be
+// within the home directory and is given relative to it (~/...). Other
+// paths are kept as given, so a link is tracked as a link by default.
+func followSymlinks(roots config.PathRoots, req AddRequest) (AddRequest, error) {
+	paths := make([]string, 0, len(req.Paths))
+	options := make(map[string]ConfigOptions, len(req.Options))
+	for path, opts := range req.Options {
+		options[path] = opts
+	}
+
+	for _, path := range req.Paths {
+		opts, ok := req.Options[path]
+		if !ok || !opts.FollowSymlinks {
+			paths = append(paths, path)
+			continue
+		}
+
+		absPath, err := roots.Expand(path)
+		if err != nil {
+			return req, fmt.Errorf("invalid path %q: %w", path, err)
+		}
+		info, err := os.Lstat(absPath)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			// Not a link; missing paths are reported by validation
+			paths = append(paths, path)
+			continue
+		}
+
+		resolved, err := filepath.EvalSymlinks(absPath)
+		if err != nil {
+			return req, fmt.Errorf("resolve symlink %q: %w", path, err)
+		}
+		if err := roots.ValidateTarget(resolved); err != nil {
+			return req, fmt.Errorf("symlink %q points outside the home directory: %w", path, err)
+		}
+		// Store the real path as ~/..., the way a user would write it
+		resolved = roots.Contract(resolved)
+
+		delete(options, path)
+		options[resolved] = opts
+		paths = append(paths, resolved)
 	}
+
+	req.Paths = paths
+	req.Options = options
+	return req, nil
 }
 
 // AddRequest contains the parameters for adding config files.
@@ -67,7 +199,12 @@ type AddRequest struct {
 	Paths     []string
 	Options   map[string]ConfigOptions
 	DryRun    bool
+	NoCommit  bool // Stage changes but leave the git commit to the user
 	SkipCheck bool // Skip file existence check (for testing)
+
+	// ForceUnlock removes a held transaction lock first, for a lock whose
+	// holder's PID was reused by another process
+	ForceUnlock bool
 }
 
 // ConfigOptions contains options for a single config file.
@@ -76,14 +213,61 @@ type ConfigOptions struct {
 	Template  bool
 	Secrets   bool
 	Private   bool
+
+	// Target applies the file at this path instead of its own (supports ~).
+	// It must stay within the home directory.
+	Target string
+
+	// FollowSymlinks tracks the file a symlinked path points to, recorded
+	// under its real path, instead of the link itself.
+	FollowSymlinks bool
+
+	// Explicit names the flags given for this path (FlagTemplate,
+	// FlagSecrets, FlagPrivate), set or not. The config's defaults only
+	// turn on the others.
+	Explicit []string
+}
+
+// Flags that can have a default in the config, as named in
+// ConfigOptions.Explicit
+const (
+	FlagTemplate = "template"
+	FlagSecrets  = "secrets"
+	FlagPrivate  = "private"
+)
+
+// applyDefaults returns the options for each path with the config's
+// defaults turned on, except for flags given explicitly.
+func applyDefaults(paths []string, options map[string]ConfigOptions, defaults config.ConfigDefaults) map[string]ConfigOptions {
+	applied := make(map[string]ConfigOptions, len(options))
+	for path, opts := range options {
+		applied[path] = opts
+	}
+
+	for _, path := range paths {
+		opts := applied[path]
+		if defaults.Template && !slices.Contains(opts.Explicit, FlagTemplate) {
+			opts.Template = true
+		}
+		if defaults.Secrets && !slices.Contains(opts.Explicit, FlagSecrets) {
+			opts.Secrets = true
+		}
+		if defaults.Private && !slices.Contains(opts.Explicit, FlagPrivate) {
+			opts.Private = true
+		}
+		applied[path] = opts
+	}
+	return applied
 }
 
 // AddResult contains the results of the add operation.
 type AddResult struct {
 	AddedPaths    []string
 	SkippedPaths  []string // Already tracked
+	SourcePaths   []string // Created source entries, relative to the ZERB directory
 	CommitHash    string
 	ConfigVersion string
+	Uncommitted   bool // Changes are staged but not committed (NoCommit)
 }
 
 // Execute performs the config add operation.
@@ -95,6 +279,11 @@ func (s *ConfigAddService) Execute(ctx context.Context, req AddRequest) (*AddRes
 
 	// 1. Acquire transaction lock
 	txnDir := filepath.Join(s.zerbDir, ".txn")
+	if req.ForceUnlock {
+		if err := transaction.ForceUnlock(txnDir); err != nil {
+			return nil, err
+		}
+	}
 	lock, err := transaction.AcquireLock(txnDir)
 	if err != nil {
 		return nil, fmt.Errorf("acquire transaction lock: %w", err)
@@ -102,10 +291,20 @@ func (s *ConfigAddService) Execute(ctx context.Context, req AddRequest) (*AddRes
 	defer func() { _ = lock.Release() }()
 
 	// 2. Validate and normalize all paths
+	roots, err := s.pathRoots()
+	if err != nil {
+		return nil, err
+	}
+	if req, err = followSymlinks(roots, req); err != nil {
+		return nil, err
+	}
 	normalizedPaths := make(map[string]string) // original -> normalized
 	for _, path := range req.Paths {
 		// Validate and normalize path
-		normalized, err := config.NormalizeConfigPath(path)
+		if err := roots.Validate(path); err != nil {
+			return nil, fmt.Errorf("invalid path %q: %w", path, err)
+		}
+		normalized, err := roots.Normalize(path)
 		if err != nil {
 			return nil, fmt.Errorf("invalid path %q: %w", path, err)
 		}
@@ -138,6 +337,17 @@ Example:
 			}
 		}
 
+		// A target override decides where the file is applied, so it is
+		// also what duplicates are detected by
+		if target := req.Options[path].Target; target != "" {
+			if err := roots.ValidateTarget(target); err != nil {
+				return nil, fmt.Errorf("invalid target %q for %q: %w", target, path, err)
+			}
+			if normalized, err = roots.Normalize(target); err != nil {
+				return nil, fmt.Errorf("invalid target %q for %q: %w", target, path, err)
+			}
+		}
+
 		normalizedPaths[path] = normalized
 	}
 
@@ -157,15 +367,16 @@ Example:
 	if err != nil {
 		return nil, fmt.Errorf("parse current config: %w", err)
 	}
+	req.Options = applyDefaults(req.Paths, req.Options, currentConfig.Options.Defaults)
 
-	// 4. Check for duplicates
+	// 4. Check for duplicates (by where each config is applied)
 	for origPath, normalized := range normalizedPaths {
 		isDuplicate := false
 		for _, existing := range currentConfig.Configs {
-			existingNorm, err := config.NormalizeConfigPath(existing.Path)
+			existingNorm, err := roots.Normalize(existing.TargetPath())
 			if err != nil {
 				// Malformed existing entry - log warning and skip comparison
-				fmt.Fprintf(os.Stderr, "Warning: cannot normalize existing config path %q: %v\n", existing.Path, err)
+				fmt.Fprintf(os.Stderr, "Warning: cannot normalize existing config path %q: %v\n", existing.TargetPath(), err)
 				continue
 			}
 			if existingNorm == normalized {
@@ -198,6 +409,7 @@ Example:
 			Template:  opts.Template,
 			Secrets:   opts.Secrets,
 			Private:   opts.Private,
+			Target:    opts.Target,
 		}
 	}
 	txn := transaction.New(result.AddedPaths, txnOpts)
@@ -209,22 +421,26 @@ Example:
 
 	// 7. Add files to chezmoi (track state per path)
 	for _, path := range result.AddedPaths {
-		opts := req.Options[path]
-		chezmoiOpts := chezmoi.AddOptions{
-			Recursive: opts.Recursive,
-			Template:  opts.Template,
-			Secrets:   opts.Secrets,
-			Private:   opts.Private,
-		}
-
 		// Update transaction state to in_progress
 		txn.UpdatePathState(path, transaction.StateInProgress, nil, nil)
 		if err := txn.Save(txnDir); err != nil {
 			return nil, fmt.Errorf("save transaction: %w", err)
 		}
 
-		// Perform chezmoi add
-		if err := s.chezmoi.Add(ctx, path, chezmoiOpts); err != nil {
+		// Perform chezmoi add (bounded by the per-path timeout)
+		// Base directory variables are expanded; the config keeps the original path
+		source, err := roots.Expand(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %q: %w", path, err)
+		}
+		var target string
+		if opts := req.Options[path]; opts.Target != "" {
+			if target, err = roots.Expand(opts.Target); err != nil {
+				return nil, fmt.Errorf("invalid target %q: %w", opts.Target, err)
+			}
+		}
+		added, err := s.addPath(ctx, source, target, req.Options[path])
+		if err != nil {
 			// Mark as failed and save transaction
 			txn.UpdatePathState(path, transaction.StateFailed, nil, err)
 			if saveErr := txn.Save(txnDir); saveErr != nil {
@@ -233,12 +449,18 @@ Example:
 			}
 
 			// Provide recovery instructions
-			txnFile := filepath.Join(txnDir, fmt.Sprintf("txn-config-add-%s.json", txn.ID))
-			return nil, fmt.Errorf("failed to add %q to config manager: %w (transaction state saved to %s)", path, err, txnFile)
+			return nil, fmt.Errorf("failed to add %q to config manager: %w (transaction state saved to %s; run 'zerb config recover' to undo the partial add)", path, err, txn.File(txnDir))
 		}
 
-		// Mark as completed
-		txn.UpdatePathState(path, transaction.StateCompleted, nil, nil)
+		sourcePath := s.relativeSourcePath(added)
+		result.SourcePaths = append(result.SourcePaths, sourcePath)
+
+		// Mark as completed, recording the source entry for recovery
+		var created []string
+		if sourcePath != "" {
+			created = []string{sourcePath}
+		}
+		txn.UpdatePathState(path, transaction.StateCompleted, created, nil)
 		if err := txn.Save(txnDir); err != nil {
 			return nil, fmt.Errorf("save transaction: %w", err)
 		}
@@ -258,6 +480,7 @@ Example:
 			Template:  opts.Template,
 			Secrets:   opts.Secrets,
 			Private:   opts.Private,
+			Target:    opts.Target,
 		})
 	}
 
@@ -268,15 +491,14 @@ Example:
 		return nil, fmt.Errorf("generate config: %w", err)
 	}
 
-	configsDir := filepath.Join(s.zerbDir, "configs")
-	if err := os.MkdirAll(configsDir, ConfigDirPermissions); err != nil {
-		return nil, fmt.Errorf("create configs directory: %w", err)
+	// Record the snapshot first, so recovery can discard a partial write
+	txn.ConfigFile = newConfigFilename
+	if err := txn.Save(txnDir); err != nil {
+		return nil, fmt.Errorf("save transaction: %w", err)
 	}
 
-	newConfigPath := filepath.Join(configsDir, newConfigFilename)
-
-	if err := os.WriteFile(newConfigPath, []byte(newConfigContent), ConfigFilePermissions); err != nil {
-		return nil, fmt.Errorf("write new config: %w", err)
+	if err := writeConfigSnapshot(s.zerbDir, newConfigFilename, newConfigContent); err != nil {
+		return nil, err
 	}
 
 	result.ConfigVersion = newConfigFilename
@@ -287,55 +509,32 @@ Example:
 		return nil, fmt.Errorf("save transaction: %w", err)
 	}
 
-	// 10. Update .zerb-active marker
-	activeMarkerPath := filepath.Join(s.zerbDir, ".zerb-active")
-	if err := os.WriteFile(activeMarkerPath, []byte(newConfigFilename+"\n"), ConfigFilePermissions); err != nil {
-		return nil, fmt.Errorf("update active marker: %w", err)
-	}
-
-	// 11. Update zerb.lua.active symlink atomically (or copy on Windows)
-	tmpLink := activeConfigPath + ".tmp"
-	target := filepath.Join("configs", newConfigFilename)
-
-	// Try to create symlink to temp location first
-	err = os.Symlink(target, tmpLink)
-	if err != nil {
-		// Check if symlinks are unsupported (Windows without dev mode)
-		errStr := err.Error()
-		if strings.Contains(errStr, "not supported") || strings.Contains(errStr, "not implemented") {
-			// Fallback to copy on systems without symlink support
-			if err := os.WriteFile(activeConfigPath, []byte(newConfigContent), ConfigFilePermissions); err != nil {
-				return nil, fmt.Errorf("update active config: %w", err)
-			}
-		} else {
-			return nil, fmt.Errorf("create symlink: %w", err)
-		}
-	} else {
-		// Atomic rename (overwrites existing)
-		if err := os.Rename(tmpLink, activeConfigPath); err != nil {
-			os.Remove(tmpLink) // Clean up temp
-			return nil, fmt.Errorf("update active config link: %w", err)
-		}
+	// 10-11. Update .zerb-active marker and zerb.active.lua symlink
+	if err := activateConfigSnapshot(s.zerbDir, newConfigFilename, newConfigContent); err != nil {
+		return nil, err
 	}
 
-	// 12. Stage files in git
-	filesToStage := []string{
-		filepath.Join("configs", newConfigFilename),
-		".zerb-active",
-		"zerb.active.lua",
+	// 12. Stage files in git (snapshot, marker, symlink and chezmoi source files)
+	if err := checkoutSnapshotBranch(ctx, s.git, currentConfig, s.hostBranch); err != nil {
+		return nil, err
 	}
-
-	// Also stage chezmoi source files
-	chezmoiSourceDir := filepath.Join("chezmoi", "source")
-	filesToStage = append(filesToStage, chezmoiSourceDir)
+	filesToStage := addStagePaths(newConfigFilename, result.SourcePaths)
 
 	if err := s.git.Stage(ctx, filesToStage...); err != nil {
 		return nil, fmt.Errorf("stage files: %w", err)
 	}
 
-	// 13. Create git commit
+	// 13. Create git commit (unless the caller wants to commit manually)
+	if req.NoCommit {
+		result.Uncommitted = true
+		if err := txn.Finish(txnDir); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
 	commitMsg := s.generateCommitMessage(result.AddedPaths)
-	commitBody := s.generateCommitBody(result.AddedPaths)
+	commitBody := s.generateCommitBody(result.AddedPaths, result.SourcePaths)
 
 	if err := s.git.Commit(ctx, commitMsg, commitBody); err != nil {
 		return nil, fmt.Errorf("create commit: %w", err)
@@ -350,9 +549,9 @@ Example:
 		result.CommitHash = commitHash
 	}
 
-	// Save final transaction state
-	if err := txn.Save(txnDir); err != nil {
-		return nil, fmt.Errorf("save transaction: %w", err)
+	// The transaction is complete; nothing is left to recover
+	if err := txn.Finish(txnDir); err != nil {
+		return nil, err
 	}
 
 	return result, nil
@@ -366,18 +565,63 @@ func (s *ConfigAddService) generateCommitMessage(paths []string) string {
 	return fmt.Sprintf("Add %d configs to tracked configs", len(paths))
 }
 
-// generateCommitBody creates the commit body with details.
-func (s *ConfigAddService) generateCommitBody(paths []string) string {
-	if len(paths) == 1 {
-		return ""
+// generateCommitBody creates the commit body with details: the added
+// configurations (when more than one) and the source files created.
+func (s *ConfigAddService) generateCommitBody(paths, sourcePaths []string) string {
+	var sb strings.Builder
+	if len(paths) > 1 {
+		sb.WriteString("Added configurations:\n")
+		for _, path := range paths {
+			sb.WriteString("- ")
+			sb.WriteString(path)
+			sb.WriteString("\n")
+		}
 	}
 
-	var sb strings.Builder
-	sb.WriteString("Added configurations:\n")
-	for _, path := range paths {
-		sb.WriteString("- ")
-		sb.WriteString(path)
-		sb.WriteString("\n")
+	var known []string
+	for _, sourcePath := range sourcePaths {
+		if sourcePath != "" {
+			known = append(known, sourcePath)
+		}
+	}
+	if len(known) > 0 {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("Source files:\n")
+		for _, sourcePath := range known {
+			sb.WriteString("- ")
+			sb.WriteString(sourcePath)
+			sb.WriteString("\n")
+		}
 	}
 	return sb.String()
 }
+
+// relativeSourcePath returns the source entry of an add relative to the
+// ZERB directory, or "" if it is unknown or outside the source directory.
+func (s *ConfigAddService) relativeSourcePath(added *chezmoi.AddResult) string {
+	if added == nil || added.SourcePath == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(s.zerbDir, added.SourcePath)
+	sourceDir := filepath.Join("chezmoi", "source")
+	if err != nil || !strings.HasPrefix(rel, sourceDir+string(filepath.Separator)) {
+		return ""
+	}
+	return rel
+}
+
+// addStagePaths returns the paths to stage after an add: the snapshot and
+// the created source entries. If any entry is unknown the whole source
+// directory is staged instead.
+func addStagePaths(filename string, sourcePaths []string) []string {
+	paths := snapshotStagePaths(filename)
+	if len(sourcePaths) == 0 || slices.Contains(sourcePaths, "") {
+		return paths
+	}
+
+	sourceDir := filepath.Join("chezmoi", "source")
+	paths = slices.DeleteFunc(paths, func(path string) bool { return path == sourceDir })
+	return append(paths, sourcePaths...)
+}
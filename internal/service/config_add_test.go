@@ -0,0 +1,519 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/chezmoi"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+)
+
+// mockChezmoi implements chezmoi.Chezmoi for testing.
+type mockChezmoi struct {
+	addFunc    func(ctx context.Context, path string, opts chezmoi.AddOptions) error
+	forgetFunc func(ctx context.Context, path string) error
+	sources    map[string]string // Source entry reported for each added path
+	added      []string
+	forgotten  []string
+}
+
+func (m *mockChezmoi) Add(ctx context.Context, path string, opts chezmoi.AddOptions) (*chezmoi.AddResult, error) {
+	m.added = append(m.added, path)
+	if m.addFunc != nil {
+		if err := m.addFunc(ctx, path, opts); err != nil {
+			return nil, err
+		}
+	}
+	return &chezmoi.AddResult{SourcePath: m.sources[path], Encrypted: opts.Secrets}, nil
+}
+
+func (m *mockChezmoi) Forget(ctx context.Context, path string) error {
+	m.forgotten = append(m.forgotten, path)
+	if m.forgetFunc != nil {
+		return m.forgetFunc(ctx, path)
+	}
+	return nil
+}
+
+// HasFile reports paths added and not forgotten since
+func (m *mockChezmoi) HasFile(ctx context.Context, path string) (bool, error) {
+	return slices.Contains(m.added, path) && !slices.Contains(m.forgotten, path), nil
+}
+
+// setupAddTestRepo creates an initialized ZERB directory with a git repo,
+// an initial config snapshot and an initial commit.
+func setupAddTestRepo(t *testing.T) string {
+	t.Helper()
+
+	zerbDir := t.TempDir()
+	ctx := context.Background()
+
+	for _, dir := range []string{"configs", filepath.Join("chezmoi", "source")} {
+		if err := os.MkdirAll(filepath.Join(zerbDir, dir), 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+	}
+
+	gitClient := git.NewClient(zerbDir)
+	if err := gitClient.InitRepo(ctx); err != nil {
+		t.Fatalf("InitRepo() error = %v", err)
+	}
+	if err := gitClient.ConfigureUser(ctx, git.GitUserInfo{Name: "Test User", Email: "test@example.com"}); err != nil {
+		t.Fatalf("ConfigureUser() error = %v", err)
+	}
+
+	initialFilename := "zerb.20250101T000000.000Z.lua"
+	content, err := config.NewGenerator().Generate(ctx, &config.Config{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(zerbDir, "configs", initialFilename), []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write initial config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(zerbDir, ".zerb-active"), []byte(initialFilename+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write active marker: %v", err)
+	}
+	if err := os.Symlink(filepath.Join("configs", initialFilename), filepath.Join(zerbDir, "zerb.active.lua")); err != nil {
+		t.Fatalf("failed to create active symlink: %v", err)
+	}
+
+	files := []string{filepath.Join("configs", initialFilename), ".zerb-active", "zerb.active.lua"}
+	if err := gitClient.CreateInitialCommit(ctx, "Initialize ZERB environment", files); err != nil {
+		t.Fatalf("CreateInitialCommit() error = %v", err)
+	}
+
+	return zerbDir
+}
+
+// newTestAddService builds a ConfigAddService backed by a real git client
+// and parser, with chezmoi replaced by a stub that writes into the source dir.
+func newTestAddService(zerbDir string, cm *mockChezmoi) *ConfigAddService {
+	if cm.addFunc == nil {
+		cm.addFunc = func(ctx context.Context, path string, opts chezmoi.AddOptions) error {
+			name := "dot_" + strings.TrimPrefix(filepath.Base(path), ".")
+			return os.WriteFile(filepath.Join(zerbDir, "chezmoi", "source", name), []byte("content"), 0644)
+		}
+	}
+	return NewConfigAddService(
+		cm,
+		git.NewClient(zerbDir),
+		config.NewParser(nil),
+		config.NewGenerator(),
+		TestClock{FixedTime: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)},
+		zerbDir,
+	)
+}
+
+// gitOutput runs a git command in dir and returns its trimmed output.
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestConfigAddService_Execute_Commits(t *testing.T) {
+	zerbDir := setupAddTestRepo(t)
+	svc := newTestAddService(zerbDir, &mockChezmoi{})
+
+	result, err := svc.Execute(context.Background(), AddRequest{
+		Paths:     []string{"~/.zshrc"},
+		SkipCheck: true,
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if result.Uncommitted {
+		t.Error("Uncommitted = true, want false")
+	}
+	if result.CommitHash == "" {
+		t.Error("CommitHash is empty, want commit hash")
+	}
+	if got := gitOutput(t, zerbDir, "rev-list", "--count", "HEAD"); got != "2" {
+		t.Errorf("commit count = %s, want 2", got)
+	}
+}
+
+func TestConfigAddService_Execute_SourcePaths(t *testing.T) {
+	zerbDir := setupAddTestRepo(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	sourceFile := filepath.Join(zerbDir, "chezmoi", "source", "dot_zshrc")
+	cm := &mockChezmoi{sources: map[string]string{filepath.Join(home, ".zshrc"): sourceFile}}
+	svc := newTestAddService(zerbDir, cm)
+
+	// An unrelated change in the source directory is left alone
+	if err := os.WriteFile(filepath.Join(zerbDir, "chezmoi", "source", "dot_stray"), []byte("stray"), 0644); err != nil {
+		t.Fatalf("failed to write stray file: %v", err)
+	}
+
+	result, err := svc.Execute(context.Background(), AddRequest{
+		Paths:     []string{"~/.zshrc"},
+		SkipCheck: true,
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	wantSource := filepath.Join("chezmoi", "source", "dot_zshrc")
+	if len(result.SourcePaths) != 1 || result.SourcePaths[0] != wantSource {
+		t.Errorf("SourcePaths = %v, want [%s]", result.SourcePaths, wantSource)
+	}
+	committed := gitOutput(t, zerbDir, "show", "--name-only", "--format=", "HEAD")
+	if !strings.Contains(committed, wantSource) {
+		t.Errorf("%s not committed, committed files:\n%s", wantSource, committed)
+	}
+	if strings.Contains(committed, "dot_stray") {
+		t.Errorf("unrelated source file committed:\n%s", committed)
+	}
+	if body := gitOutput(t, zerbDir, "log", "-1", "--format=%b"); body != "Source files:\n- "+wantSource {
+		t.Errorf("commit body = %q, want the source file listed", body)
+	}
+}
+
+func TestConfigAddService_Execute_NoCommit(t *testing.T) {
+	zerbDir := setupAddTestRepo(t)
+	svc := newTestAddService(zerbDir, &mockChezmoi{})
+
+	headBefore := gitOutput(t, zerbDir, "rev-parse", "HEAD")
+
+	result, err := svc.Execute(context.Background(), AddRequest{
+		Paths:     []string{"~/.zshrc"},
+		NoCommit:  true,
+		SkipCheck: true,
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if !result.Uncommitted {
+		t.Error("Uncommitted = false, want true")
+	}
+	if result.CommitHash != "" {
+		t.Errorf("CommitHash = %q, want empty", result.CommitHash)
+	}
+	if result.ConfigVersion == "" {
+		t.Error("ConfigVersion is empty, want new snapshot filename")
+	}
+
+	// No new commit should exist
+	if headAfter := gitOutput(t, zerbDir, "rev-parse", "HEAD"); headAfter != headBefore {
+		t.Errorf("HEAD moved from %s to %s, want no new commit", headBefore, headAfter)
+	}
+
+	// Snapshot, marker and chezmoi source should be staged
+	staged := gitOutput(t, zerbDir, "diff", "--cached", "--name-only")
+	wantStaged := []string{
+		"configs/" + result.ConfigVersion,
+		".zerb-active",
+		"zerb.active.lua",
+		"chezmoi/source/dot_zshrc",
+	}
+	for _, want := range wantStaged {
+		if !strings.Contains(staged, want) {
+			t.Errorf("%s not staged, staged files:\n%s", want, staged)
+		}
+	}
+
+	// Marker should point at the new snapshot
+	marker, err := os.ReadFile(filepath.Join(zerbDir, ".zerb-active"))
+	if err != nil {
+		t.Fatalf("failed to read marker: %v", err)
+	}
+	if strings.TrimSpace(string(marker)) != result.ConfigVersion {
+		t.Errorf("marker = %q, want %q", strings.TrimSpace(string(marker)), result.ConfigVersion)
+	}
+}
+
+func TestConfigAddService_Execute_PathTimeout(t *testing.T) {
+	zerbDir := setupAddTestRepo(t)
+
+	// Stub chezmoi that sleeps much longer than the per-path timeout
+	cm := &mockChezmoi{
+		addFunc: func(ctx context.Context, path string, opts chezmoi.AddOptions) error {
+			select {
+			case <-time.After(5 * time.Second):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+	svc := newTestAddService(zerbDir, cm).WithAddTimeouts(time.Second, 50*time.Millisecond)
+
+	start := time.Now()
+	_, err := svc.Execute(context.Background(), AddRequest{
+		Paths:     []string{"~/.config/nvim"},
+		Options:   map[string]ConfigOptions{"~/.config/nvim": {Recursive: true}},
+		SkipCheck: true,
+	})
+	if err == nil {
+		t.Fatal("Execute() error = nil, want timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Execute() took %s, want recursive timeout to apply", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Execute() error = %v, want context.DeadlineExceeded", err)
+	}
+	if !strings.Contains(err.Error(), "~/.config/nvim") || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Execute() error = %q, want timeout naming the path", err.Error())
+	}
+}
+
+func TestConfigAddService_AddTimeout(t *testing.T) {
+	svc := NewConfigAddService(&mockChezmoi{}, nil, nil, nil, TestClock{}, t.TempDir())
+
+	if got := svc.addTimeout(ConfigOptions{}); got != DefaultPathAddTimeout {
+		t.Errorf("addTimeout(file) = %s, want %s", got, DefaultPathAddTimeout)
+	}
+	if got := svc.addTimeout(ConfigOptions{Recursive: true}); got != DefaultRecursiveAddTimeout {
+		t.Errorf("addTimeout(recursive) = %s, want %s", got, DefaultRecursiveAddTimeout)
+	}
+
+	// Non-positive values keep defaults
+	svc.WithAddTimeouts(0, -1)
+	if got := svc.addTimeout(ConfigOptions{Recursive: true}); got != DefaultRecursiveAddTimeout {
+		t.Errorf("addTimeout(recursive) after zero override = %s, want %s", got, DefaultRecursiveAddTimeout)
+	}
+}
+
+func TestConfigAddService_Execute_PerHostBranch(t *testing.T) {
+	zerbDir := setupAddTestRepo(t)
+	ctx := context.Background()
+
+	// Enable per-host branches in the active config
+	content, err := config.NewGenerator().Generate(ctx, &config.Config{Git: config.GitConfig{PerHostBranches: true}})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(zerbDir, "zerb.active.lua"), []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	gitOutput(t, zerbDir, "commit", "-qam", "Enable per-host branches")
+	baseBranch := gitOutput(t, zerbDir, "rev-parse", "--abbrev-ref", "HEAD")
+
+	svc := newTestAddService(zerbDir, &mockChezmoi{}).WithHostBranch("hosts/test-machine")
+	result, err := svc.Execute(ctx, AddRequest{
+		Paths:     []string{"~/.zshrc"},
+		SkipCheck: true,
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if got := gitOutput(t, zerbDir, "rev-parse", "--abbrev-ref", "HEAD"); got != "hosts/test-machine" {
+		t.Errorf("current branch = %q, want %q", got, "hosts/test-machine")
+	}
+	if got := gitOutput(t, zerbDir, "rev-parse", "hosts/test-machine"); got != result.CommitHash {
+		t.Errorf("host branch = %s, want snapshot commit %s", got, result.CommitHash)
+	}
+	if got := gitOutput(t, zerbDir, "rev-list", "--count", baseBranch); got != "2" {
+		t.Errorf("%s has %s commits, want 2 (snapshot must not land there)", baseBranch, got)
+	}
+
+	// A second snapshot stays on the same branch
+	if _, err := svc.Execute(ctx, AddRequest{Paths: []string{"~/.bashrc"}, SkipCheck: true}); err != nil {
+		t.Fatalf("second Execute() error = %v", err)
+	}
+	if got := gitOutput(t, zerbDir, "rev-list", "--count", "hosts/test-machine"); got != "4" {
+		t.Errorf("host branch has %s commits, want 4", got)
+	}
+}
+
+func TestConfigAddService_Execute_PathRoots(t *testing.T) {
+	zerbDir := setupAddTestRepo(t)
+	dotfiles := t.TempDir()
+
+	roots, err := config.DefaultPathRoots()
+	if err != nil {
+		t.Fatalf("DefaultPathRoots() error = %v", err)
+	}
+	roots = roots.WithBase("DOTFILES", dotfiles)
+
+	cm := &mockChezmoi{
+		addFunc: func(ctx context.Context, path string, opts chezmoi.AddOptions) error {
+			return os.WriteFile(filepath.Join(zerbDir, "chezmoi", "source", "dot_gitconfig"), []byte("content"), 0644)
+		},
+	}
+	// The parser needs the same roots to accept the resulting config
+	svc := NewConfigAddService(
+		cm,
+		git.NewClient(zerbDir),
+		config.NewParser(nil).WithPathRoots(roots),
+		config.NewGenerator(),
+		TestClock{FixedTime: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)},
+		zerbDir,
+	).WithPathRoots(roots)
+
+	result, err := svc.Execute(context.Background(), AddRequest{
+		Paths:     []string{"$DOTFILES/.gitconfig"},
+		SkipCheck: true,
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	// chezmoi receives the expanded path
+	want := filepath.Join(dotfiles, ".gitconfig")
+	if len(cm.added) != 1 || cm.added[0] != want {
+		t.Errorf("added = %v, want [%s]", cm.added, want)
+	}
+
+	// The snapshot keeps the unexpanded path
+	content, err := os.ReadFile(filepath.Join(zerbDir, "configs", result.ConfigVersion))
+	if err != nil {
+		t.Fatalf("failed to read snapshot: %v", err)
+	}
+	if !strings.Contains(string(content), `"$DOTFILES/.gitconfig"`) {
+		t.Errorf("snapshot does not contain the unexpanded path:\n%s", content)
+	}
+
+	// Without the custom base the path is rejected
+	_, err = newTestAddService(zerbDir, &mockChezmoi{}).Execute(context.Background(), AddRequest{
+		Paths:     []string{"$DOTFILES/.vimrc"},
+		SkipCheck: true,
+	})
+	if err == nil || !strings.Contains(err.Error(), "unknown base directory") {
+		t.Errorf("Execute() error = %v, want unknown base directory", err)
+	}
+}
+
+func TestConfigAddService_Execute_FollowSymlinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		follow   bool
+		outside  bool // the link points outside home
+		wantReal bool // the real path is tracked instead of the link
+		wantErr  string
+	}{
+		{name: "link tracked by default", follow: false, wantReal: false},
+		{name: "follow tracks real file", follow: true, wantReal: true},
+		{name: "follow outside home", follow: true, outside: true, wantErr: "outside the home directory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zerbDir := setupAddTestRepo(t)
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+
+			storeDir := filepath.Join(home, "store")
+			if tt.outside {
+				storeDir = t.TempDir()
+			}
+			if err := os.MkdirAll(storeDir, 0755); err != nil {
+				t.Fatalf("failed to create store: %v", err)
+			}
+			realPath := filepath.Join(storeDir, "zshrc")
+			if err := os.WriteFile(realPath, []byte("user content"), 0644); err != nil {
+				t.Fatalf("failed to create file: %v", err)
+			}
+			linkPath := filepath.Join(home, ".zshrc")
+			if err := os.Symlink(realPath, linkPath); err != nil {
+				t.Fatalf("failed to create symlink: %v", err)
+			}
+
+			cm := &mockChezmoi{}
+			result, err := newTestAddService(zerbDir, cm).Execute(context.Background(), AddRequest{
+				Paths:   []string{linkPath},
+				Options: map[string]ConfigOptions{linkPath: {FollowSymlinks: tt.follow}},
+			})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Execute() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			// The config manager is given the real file; the config records
+			// it under ~/ like any other path in the home directory
+			want, wantAdded := linkPath, linkPath
+			if tt.wantReal {
+				want, wantAdded = "~/store/zshrc", realPath
+			}
+			if len(cm.added) != 1 || cm.added[0] != wantAdded {
+				t.Errorf("added = %v, want [%s]", cm.added, wantAdded)
+			}
+			if len(result.AddedPaths) != 1 || result.AddedPaths[0] != want {
+				t.Errorf("AddedPaths = %v, want [%s]", result.AddedPaths, want)
+			}
+			cfg := readActiveConfig(t, zerbDir)
+			if len(cfg.Configs) != 1 || cfg.Configs[0].Path != want {
+				t.Errorf("active config = %+v, want path %s", cfg.Configs, want)
+			}
+		})
+	}
+}
+
+func TestConfigAddService_Execute_ConfigDefaults(t *testing.T) {
+	zerbDir := setupAddTestRepo(t)
+	ctx := context.Background()
+
+	// Replace the initial config with one defaulting new entries to private
+	content, err := config.NewGenerator().Generate(ctx, &config.Config{
+		Options: config.Options{Defaults: config.ConfigDefaults{Private: true, Template: true}},
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(zerbDir, "zerb.active.lua"), []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cm := &mockChezmoi{}
+	added := make(map[string]chezmoi.AddOptions)
+	cm.addFunc = func(ctx context.Context, path string, opts chezmoi.AddOptions) error {
+		added[filepath.Base(path)] = opts
+		return nil
+	}
+	_, err = newTestAddService(zerbDir, cm).Execute(ctx, AddRequest{
+		Paths: []string{"~/.zshrc", "~/.gitconfig", "~/.env"},
+		Options: map[string]ConfigOptions{
+			// --no-private
+			"~/.gitconfig": {Explicit: []string{FlagPrivate}},
+			// --secrets --no-template
+			"~/.env": {Secrets: true, Explicit: []string{FlagSecrets, FlagTemplate}},
+		},
+		SkipCheck: true,
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	want := map[string]config.ConfigFile{
+		"~/.zshrc":     {Path: "~/.zshrc", Template: true, Private: true},
+		"~/.gitconfig": {Path: "~/.gitconfig", Template: true},
+		"~/.env":       {Path: "~/.env", Secrets: true, Private: true},
+	}
+	cfg := readActiveConfig(t, zerbDir)
+	if len(cfg.Configs) != len(want) {
+		t.Fatalf("active config = %+v, want %d entries", cfg.Configs, len(want))
+	}
+	for _, cf := range cfg.Configs {
+		if cf != want[cf.Path] {
+			t.Errorf("config entry = %+v, want %+v", cf, want[cf.Path])
+		}
+		opts := added[filepath.Base(cf.Path)]
+		if opts.Template != cf.Template || opts.Secrets != cf.Secrets || opts.Private != cf.Private {
+			t.Errorf("%s added with %+v, want the flags of %+v", cf.Path, opts, cf)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+)
+
+// ErrNotTracked is returned when a path is not tracked by the active config.
+var ErrNotTracked = errors.New("not tracked")
+
+// FileDiffer compares tracked config files with the files on disk.
+type FileDiffer interface {
+	// Diff returns a unified diff from the tracked content of paths to the
+	// files on disk, or "" if they are identical.
+	Diff(ctx context.Context, paths ...string) (string, error)
+}
+
+// ConfigDiffFileService shows how a tracked file on disk differs from its
+// tracked content.
+type ConfigDiffFileService struct {
+	differ  FileDiffer
+	parser  ConfigParser
+	zerbDir string
+}
+
+// NewConfigDiffFileService creates a new config diff-file service with dependency injection.
+func NewConfigDiffFileService(differ FileDiffer, parser ConfigParser, zerbDir string) *ConfigDiffFileService {
+	return &ConfigDiffFileService{
+		differ:  differ,
+		parser:  parser,
+		zerbDir: zerbDir,
+	}
+}
+
+// DiffFileResult contains the difference between a tracked file and the file on disk.
+type DiffFileResult struct {
+	Path   string // Path as requested
+	Config string // Tracking config entry, as written in the config
+	Target string // Absolute path the tracked content is applied to
+	Diff   string // Unified diff from tracked content to the file on disk
+}
+
+// Identical reports whether the file on disk matches its tracked content.
+func (r *DiffFileResult) Identical() bool {
+	return r.Diff == ""
+}
+
+// DiffFile resolves path, checks that the active config tracks it (directly,
+// as a target, or inside a tracked directory), and returns the diff between
+// its tracked content and the file on disk. Returns ErrNotTracked for
+// untracked paths.
+func (s *ConfigDiffFileService) DiffFile(ctx context.Context, path string) (*DiffFileResult, error) {
+	// Check context first
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	want, err := config.NormalizeConfigPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %q: %w", path, err)
+	}
+
+	activeConfigPath := filepath.Join(s.zerbDir, "zerb.active.lua")
+	cfgData, err := os.ReadFile(activeConfigPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotInitialized
+		}
+		return nil, fmt.Errorf("read active config: %w", err)
+	}
+	cfg, err := s.parser.ParseString(ctx, string(cfgData))
+	if err != nil {
+		return nil, fmt.Errorf("parse active config: %w", err)
+	}
+
+	entry, target, ok := findTrackingConfig(cfg.Configs, want)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", path, ErrNotTracked)
+	}
+
+	diff, err := s.differ.Diff(ctx, target)
+	if err != nil {
+		return nil, fmt.Errorf("compare %s: %w", path, err)
+	}
+
+	return &DiffFileResult{Path: path, Config: entry.Path, Target: target, Diff: diff}, nil
+}
+
+// findTrackingConfig returns the config entry tracking the normalized path
+// and the absolute path its content is applied to. A path inside a
+// recursive config's directory is tracked by that config.
+func findTrackingConfig(configs []config.ConfigFile, normalized string) (config.ConfigFile, string, bool) {
+	for _, cf := range configs {
+		target, err := config.NormalizeConfigPath(cf.TargetPath())
+		if err != nil {
+			continue
+		}
+		candidates := []string{target}
+		if cf.Target != "" {
+			if path, err := config.NormalizeConfigPath(cf.Path); err == nil {
+				candidates = append(candidates, path)
+			}
+		}
+
+		for _, candidate := range candidates {
+			if normalized == candidate {
+				return cf, target, true
+			}
+			if !cf.Recursive {
+				continue
+			}
+			if rel, ok := strings.CutPrefix(normalized, candidate+string(filepath.Separator)); ok {
+				return cf, filepath.Join(target, rel), true
+			}
+		}
+	}
+	return config.ConfigFile{}, "", false
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/chezmoi"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+)
+
+// stubDiffScript mimics the configuration manager's diff command: it
+// compares the file on disk with source/dot_<name> and prints nothing when
+// they match.
+const stubDiffScript = `#!/bin/bash
+target="${@: -1}"
+name=$(basename "$target")
+diff -u --label "a/$name" --label "b/$name" "$2/dot_${name#.}" "$target"
+[ $? -le 1 ]
+`
+
+func TestConfigDiffFileService_DiffFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		live     string
+		wantDiff []string // Lines the diff must contain; nil for identical
+		wantErr  error
+	}{
+		{
+			name:     "modified file",
+			path:     "~/.zshrc",
+			live:     "export EDITOR=vim\nalias ll='ls -l'\n",
+			wantDiff: []string{"-export EDITOR=nano", "+export EDITOR=vim", "+alias ll='ls -l'"},
+		},
+		{
+			name: "identical file",
+			path: "~/.zshrc",
+			live: "export EDITOR=nano\n",
+		},
+		{
+			name:    "untracked path",
+			path:    "~/.bashrc",
+			live:    "export EDITOR=nano\n",
+			wantErr: ErrNotTracked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zerbDir := setupAddTestRepo(t)
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+
+			zshrc := filepath.Join(home, ".zshrc")
+			if err := os.WriteFile(zshrc, []byte("export EDITOR=nano\n"), 0644); err != nil {
+				t.Fatalf("failed to write .zshrc: %v", err)
+			}
+			addSvc := newTestAddService(zerbDir, &mockChezmoi{})
+			if _, err := addSvc.Execute(context.Background(), AddRequest{Paths: []string{zshrc}}); err != nil {
+				t.Fatalf("failed to track .zshrc: %v", err)
+			}
+
+			// Tracked content, then the live edit
+			if err := os.WriteFile(filepath.Join(zerbDir, "chezmoi", "source", "dot_zshrc"), []byte("export EDITOR=nano\n"), 0644); err != nil {
+				t.Fatalf("failed to write source file: %v", err)
+			}
+			if err := os.MkdirAll(filepath.Join(zerbDir, "bin"), 0755); err != nil {
+				t.Fatalf("failed to create bin dir: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(zerbDir, "bin", "chezmoi"), []byte(stubDiffScript), 0755); err != nil {
+				t.Fatalf("failed to create stub binary: %v", err)
+			}
+			live := filepath.Join(home, strings.TrimPrefix(tt.path, "~/"))
+			if err := os.WriteFile(live, []byte(tt.live), 0644); err != nil {
+				t.Fatalf("failed to write %s: %v", tt.path, err)
+			}
+
+			svc := NewConfigDiffFileService(chezmoi.NewClient(zerbDir), config.NewParser(nil), zerbDir)
+			result, err := svc.DiffFile(context.Background(), tt.path)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("DiffFile() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DiffFile() error = %v", err)
+			}
+
+			if result.Target != zshrc {
+				t.Errorf("Target = %q, want %q", result.Target, zshrc)
+			}
+			if result.Identical() != (tt.wantDiff == nil) {
+				t.Fatalf("Identical() = %v, diff:\n%s", result.Identical(), result.Diff)
+			}
+			for _, line := range tt.wantDiff {
+				if !strings.Contains(result.Diff, line+"\n") {
+					t.Errorf("diff missing %q:\n%s", line, result.Diff)
+				}
+			}
+		})
+	}
+}
+
+func TestFindTrackingConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	configs := []config.ConfigFile{
+		{Path: "~/.zshrc"},
+		{Path: "~/.config/nvim", Recursive: true},
+		{Path: "~/dotfiles/gitconfig", Target: "~/.gitconfig"},
+	}
+
+	tests := []struct {
+		path       string
+		wantConfig string
+		wantTarget string
+	}{
+		{path: filepath.Join(home, ".zshrc"), wantConfig: "~/.zshrc", wantTarget: filepath.Join(home, ".zshrc")},
+		{path: filepath.Join(home, ".config/nvim/init.lua"), wantConfig: "~/.config/nvim", wantTarget: filepath.Join(home, ".config/nvim/init.lua")},
+		{path: filepath.Join(home, ".gitconfig"), wantConfig: "~/dotfiles/gitconfig", wantTarget: filepath.Join(home, ".gitconfig")},
+		{path: filepath.Join(home, "dotfiles/gitconfig"), wantConfig: "~/dotfiles/gitconfig", wantTarget: filepath.Join(home, ".gitconfig")},
+		{path: filepath.Join(home, ".zshrc.local")},
+		{path: filepath.Join(home, ".config/nvim-old/init.lua")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			cf, target, ok := findTrackingConfig(configs, tt.path)
+			if ok != (tt.wantConfig != "") {
+				t.Fatalf("findTrackingConfig() ok = %v, want %v", ok, tt.wantConfig != "")
+			}
+			if cf.Path != tt.wantConfig || target != tt.wantTarget {
+				t.Errorf("findTrackingConfig() = %q, %q, want %q, %q", cf.Path, target, tt.wantConfig, tt.wantTarget)
+			}
+		})
+	}
+}
@@ -0,0 +1,385 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/transaction"
+)
+
+// Restore errors
+var (
+	// ErrSnapshotNotFound means a config version is neither in configs/ nor
+	// in the git history
+	ErrSnapshotNotFound = errors.New("config version not found")
+	// ErrSnapshotActive means the config version to restore is already active
+	ErrSnapshotActive = errors.New("config version is already active")
+)
+
+// ListSnapshots returns the config snapshot filenames in configs/, oldest
+// first. Snapshot names embed a UTC timestamp, so name order is time order.
+func ListSnapshots(zerbDir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(zerbDir, "configs"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotInitialized
+		}
+		return nil, fmt.Errorf("read configs directory: %w", err)
+	}
+
+	var snapshots []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "zerb.") || !strings.HasSuffix(name, ".lua") {
+			continue
+		}
+		snapshots = append(snapshots, name)
+	}
+	sort.Strings(snapshots)
+
+	return snapshots, nil
+}
+
+// HistoryEventType describes how a snapshot changed a path's tracking.
+type HistoryEventType string
+
+const (
+	// HistoryAdded means the path is tracked in this snapshot but not the previous one.
+	HistoryAdded HistoryEventType = "added"
+	// HistoryRemoved means the path was tracked in the previous snapshot but not this one.
+	HistoryRemoved HistoryEventType = "removed"
+)
+
+// HistoryEvent is a snapshot where a path started or stopped being tracked.
+type HistoryEvent struct {
+	Type     HistoryEventType
+	Snapshot string // Snapshot filename in configs/
+	Commit   string // Commit that added the snapshot; empty if uncommitted
+}
+
+// HistoryResult contains the tracking timeline of a path.
+type HistoryResult struct {
+	Path    string // Path as requested
+	Events  []HistoryEvent
+	Tracked bool // Tracked in the newest snapshot
+
+	// Skipped lists snapshots that could not be parsed and were ignored
+	Skipped []string
+}
+
+// ConfigHistoryService reports when a config file was tracked and untracked.
+type ConfigHistoryService struct {
+	git     git.Git
+	parser  ConfigParser
+	zerbDir string
+
+	hostBranch string
+}
+
+// NewConfigHistoryService creates a new config history service with dependency injection.
+func NewConfigHistoryService(gitClient git.Git, parser ConfigParser, zerbDir string) *ConfigHistoryService {
+	return &ConfigHistoryService{
+		git:     gitClient,
+		parser:  parser,
+		zerbDir: zerbDir,
+	}
+}
+
+// WithHostBranch sets the branch rollback commits go to when the config
+// enables per-host branches, instead of deriving it from the machine.
+func (s *ConfigHistoryService) WithHostBranch(name string) *ConfigHistoryService {
+	s.hostBranch = name
+	return s
+}
+
+// History walks the snapshots from oldest to newest and returns each one in
+// which path was added to or removed from tracking, with the commit that
+// recorded it. Paths are compared after normalization, so "~/.zshrc" and
+// "$HOME/.zshrc" match the same entry.
+func (s *ConfigHistoryService) History(ctx context.Context, path string) (*HistoryResult, error) {
+	// Check context first
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	want, err := config.NormalizeConfigPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %q: %w", path, err)
+	}
+
+	snapshots, err := ListSnapshots(s.zerbDir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &HistoryResult{Path: path}
+	tracked := false
+	for _, snapshot := range snapshots {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		data, err := os.ReadFile(filepath.Join(s.zerbDir, "configs", snapshot))
+		if err != nil {
+			return nil, fmt.Errorf("read snapshot %s: %w", snapshot, err)
+		}
+
+		cfg, err := s.parser.ParseString(ctx, string(data))
+		if err != nil {
+			result.Skipped = append(result.Skipped, snapshot)
+			continue
+		}
+
+		present := snapshotTracks(cfg, want)
+		if present == tracked {
+			continue
+		}
+		tracked = present
+
+		event := HistoryEvent{Type: HistoryRemoved, Snapshot: snapshot}
+		if present {
+			event.Type = HistoryAdded
+		}
+		event.Commit, err = s.git.FileCommit(ctx, filepath.ToSlash(filepath.Join("configs", snapshot)))
+		if err != nil {
+			return nil, fmt.Errorf("find commit for %s: %w", snapshot, err)
+		}
+		result.Events = append(result.Events, event)
+	}
+
+	result.Tracked = tracked
+	return result, nil
+}
+
+// SnapshotCommit is a commit that changed configs/, with the snapshot it
+// introduced.
+type SnapshotCommit struct {
+	git.Commit
+	Snapshot string // Snapshot filename in configs/; empty if the commit added none
+}
+
+// Snapshots returns the newest limit commits that changed configs/ (all of
+// them if limit is zero or less), newest first, each correlated with the
+// snapshot it introduced. Snapshots pruned since are not listed.
+func (s *ConfigHistoryService) Snapshots(ctx context.Context, limit int) ([]SnapshotCommit, error) {
+	// Check context first
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	snapshots, err := ListSnapshots(s.zerbDir)
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := s.git.Log(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("read config history: %w", err)
+	}
+
+	introduced := make(map[string]string, len(snapshots)) // commit -> snapshot
+	for _, snapshot := range snapshots {
+		commit, err := s.git.FileCommit(ctx, filepath.ToSlash(filepath.Join("configs", snapshot)))
+		if err != nil {
+			return nil, fmt.Errorf("find commit for %s: %w", snapshot, err)
+		}
+		if commit != "" {
+			introduced[commit] = snapshot
+		}
+	}
+
+	result := make([]SnapshotCommit, len(commits))
+	for i, commit := range commits {
+		result[i] = SnapshotCommit{Commit: commit, Snapshot: introduced[commit.Hash]}
+	}
+	return result, nil
+}
+
+// RestoreResult contains the results of restoring a config snapshot.
+type RestoreResult struct {
+	Snapshot    string // Snapshot filename now active
+	Previous    string // Snapshot filename active before the restore
+	FromHistory bool   // The snapshot was missing from configs/ and recovered from git
+	CommitHash  string
+}
+
+// RestoreSnapshot makes an earlier config snapshot the active config and
+// commits the rollback. version is a snapshot filename, optionally prefixed
+// with "configs/". A snapshot missing from configs/ is recovered from the
+// commit that added it. The snapshot must parse before anything changes.
+//
+// The rollback is all or nothing: if activating, staging or committing
+// fails, the .zerb-active marker, the zerb.active.lua symlink and the index
+// are put back and a recovered snapshot is removed again. Tracked files in
+// the source directory are not changed.
+func (s *ConfigHistoryService) RestoreSnapshot(ctx context.Context, version string) (*RestoreResult, error) {
+	// Check context first
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	filename := strings.TrimPrefix(filepath.ToSlash(version), "configs/")
+	if filename != filepath.Base(filename) || !strings.HasPrefix(filename, "zerb.") || !strings.HasSuffix(filename, ".lua") {
+		return nil, fmt.Errorf("invalid config version %q", version)
+	}
+
+	// 1. Acquire transaction lock
+	txnDir := filepath.Join(s.zerbDir, ".txn")
+	lock, err := transaction.AcquireLock(txnDir)
+	if err != nil {
+		return nil, fmt.Errorf("acquire transaction lock: %w", err)
+	}
+	defer func() { _ = lock.Release() }()
+
+	// 2. Read the active version
+	markerData, err := os.ReadFile(filepath.Join(s.zerbDir, ".zerb-active"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotInitialized
+		}
+		return nil, fmt.Errorf("read active marker: %w", err)
+	}
+	previous := strings.TrimSpace(string(markerData))
+	if previous == filename {
+		return nil, fmt.Errorf("%s: %w", filename, ErrSnapshotActive)
+	}
+
+	// Kept for systems where the active config is a copy, not a symlink
+	previousContent, err := os.ReadFile(filepath.Join(s.zerbDir, "zerb.active.lua"))
+	if err != nil {
+		return nil, fmt.Errorf("read active config: %w", err)
+	}
+
+	result := &RestoreResult{Snapshot: filename, Previous: previous}
+	snapshotRel := filepath.ToSlash(filepath.Join("configs", filename))
+	snapshotPath := filepath.Join(s.zerbDir, "configs", filename)
+
+	// Undo every completed step unless the rollback commit is made
+	var activated, staged, committed bool
+	var originalBranch string
+	defer func() {
+		if committed {
+			return
+		}
+		s.undoRestore(context.WithoutCancel(ctx), result, string(previousContent), originalBranch, activated, staged)
+	}()
+
+	// 3. Recover a pruned snapshot from the commit that added it
+	if _, err := os.Stat(snapshotPath); os.IsNotExist(err) {
+		commit, err := s.git.FileCommit(ctx, snapshotRel)
+		if err != nil {
+			return nil, fmt.Errorf("find commit for %s: %w", filename, err)
+		}
+		if commit == "" {
+			return nil, fmt.Errorf("%s: %w", filename, ErrSnapshotNotFound)
+		}
+		if err := s.git.CheckoutFile(ctx, commit, snapshotRel); err != nil {
+			return nil, fmt.Errorf("recover %s from history: %w", filename, err)
+		}
+		result.FromHistory = true
+	} else if err != nil {
+		return nil, fmt.Errorf("check snapshot %s: %w", filename, err)
+	}
+
+	// 4. Validate the snapshot before switching to it
+	data, err := os.ReadFile(snapshotPath)
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot %s: %w", filename, err)
+	}
+	cfg, err := s.parser.ParseString(ctx, string(data))
+	if err != nil {
+		return nil, fmt.Errorf("config version %s is invalid: %w", filename, err)
+	}
+
+	// 5. Activate, stage and commit
+	activated = true
+	if err := activateConfigSnapshot(s.zerbDir, filename, string(data)); err != nil {
+		return nil, err
+	}
+
+	// Remembered so a failed commit switches back from the host branch
+	if branch, err := s.git.CurrentBranch(ctx); err == nil {
+		originalBranch = branch
+	}
+	if err := checkoutSnapshotBranch(ctx, s.git, cfg, s.hostBranch); err != nil {
+		return nil, err
+	}
+	staged = true
+	if err := s.git.Stage(ctx, restoreStagePaths(filename)...); err != nil {
+		return nil, fmt.Errorf("stage files: %w", err)
+	}
+
+	subject := fmt.Sprintf("Roll back config to %s", filename)
+	body := fmt.Sprintf("Previously active: %s\n", previous)
+	if err := s.git.Commit(ctx, subject, body); err != nil {
+		return nil, fmt.Errorf("create commit: %w", err)
+	}
+	committed = true
+
+	commitHash, err := s.git.GetHeadCommit(ctx)
+	if err == nil {
+		result.CommitHash = commitHash
+	}
+
+	return result, nil
+}
+
+// undoRestore puts back the active config of a failed restore: HEAD is on
+// originalBranch again (unless it is empty), the marker and symlink point at
+// the previous snapshot, a recovered snapshot is removed, and the index
+// matches the working tree. Errors are ignored, as the restore's own error
+// is the one reported.
+func (s *ConfigHistoryService) undoRestore(ctx context.Context, result *RestoreResult, previousContent, originalBranch string, activated, staged bool) {
+	if originalBranch != "" {
+		if current, err := s.git.CurrentBranch(ctx); err == nil && current != originalBranch {
+			_ = s.git.CheckoutBranch(ctx, originalBranch)
+		}
+	}
+	if result.FromHistory {
+		_ = os.Remove(filepath.Join(s.zerbDir, "configs", result.Snapshot))
+	}
+	if activated {
+		_ = activateConfigSnapshot(s.zerbDir, result.Previous, previousContent)
+	}
+	if staged {
+		// One at a time, so a failure cannot leave the rest staged
+		for _, path := range restoreStagePaths(result.Snapshot) {
+			_ = s.git.Stage(ctx, path)
+		}
+	}
+}
+
+// restoreStagePaths returns the repository-relative paths a restore changes
+func restoreStagePaths(filename string) []string {
+	return []string{
+		filepath.Join("configs", filename),
+		".zerb-active",
+		"zerb.active.lua",
+	}
+}
+
+// snapshotTracks reports whether cfg tracks the normalized path, as a
+// config's path or its target
+func snapshotTracks(cfg *config.Config, normalized string) bool {
+	for _, cf := range cfg.Configs {
+		path, err := config.NormalizeConfigPath(cf.Path)
+		if err == nil && path == normalized {
+			return true
+		}
+		if cf.Target == "" {
+			continue
+		}
+		target, err := config.NormalizeConfigPath(cf.Target)
+		if err == nil && target == normalized {
+			return true
+		}
+	}
+	return false
+}
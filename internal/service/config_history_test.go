@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+)
+
+// commitSnapshot writes a snapshot tracking paths and commits it, returning the commit hash
+func commitSnapshot(t *testing.T, zerbDir, filename string, paths ...string) string {
+	t.Helper()
+
+	cfg := &config.Config{}
+	for _, path := range paths {
+		cfg.Configs = append(cfg.Configs, config.ConfigFile{Path: path})
+	}
+	content, err := config.NewGenerator().Generate(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(zerbDir, "configs", filename), []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write snapshot: %v", err)
+	}
+
+	gitOutput(t, zerbDir, "add", filepath.Join("configs", filename))
+	gitOutput(t, zerbDir, "commit", "-q", "-m", "Add "+filename)
+	return gitOutput(t, zerbDir, "rev-parse", "HEAD")
+}
+
+func TestListSnapshots(t *testing.T) {
+	zerbDir := t.TempDir()
+	configsDir := filepath.Join(zerbDir, "configs")
+	os.MkdirAll(filepath.Join(configsDir, "zerb.dir.lua"), 0755)
+	for _, name := range []string{"zerb.20250301T000000Z.lua", "zerb.20250101T000000Z.lua", "notes.txt", "zerb.20250201T000000Z.lua"} {
+		os.WriteFile(filepath.Join(configsDir, name), []byte("zerb = {}"), 0600)
+	}
+
+	got, err := ListSnapshots(zerbDir)
+	if err != nil {
+		t.Fatalf("ListSnapshots() error = %v", err)
+	}
+	want := []string{"zerb.20250101T000000Z.lua", "zerb.20250201T000000Z.lua", "zerb.20250301T000000Z.lua"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListSnapshots() = %v, want %v", got, want)
+	}
+
+	if _, err := ListSnapshots(filepath.Join(zerbDir, "missing")); !errors.Is(err, ErrNotInitialized) {
+		t.Errorf("ListSnapshots(missing) error = %v, want ErrNotInitialized", err)
+	}
+}
+
+func TestConfigHistoryService_History(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	zerbDir := setupAddTestRepo(t)
+
+	addedAt := commitSnapshot(t, zerbDir, "zerb.20250102T000000.000Z.lua", "~/.zshrc")
+	commitSnapshot(t, zerbDir, "zerb.20250103T000000.000Z.lua", "~/.zshrc", "~/.vimrc")
+	removedAt := commitSnapshot(t, zerbDir, "zerb.20250104T000000.000Z.lua", "~/.vimrc")
+	readdedAt := commitSnapshot(t, zerbDir, "zerb.20250105T000000.000Z.lua", "~/.vimrc", "~/.zshrc")
+
+	// An uncommitted snapshot that drops the file again
+	content, _ := config.NewGenerator().Generate(context.Background(), &config.Config{})
+	os.WriteFile(filepath.Join(zerbDir, "configs", "zerb.20250106T000000.000Z.lua"), []byte(content), 0600)
+
+	svc := NewConfigHistoryService(git.NewClient(zerbDir), config.NewParser(nil), zerbDir)
+	result, err := svc.History(context.Background(), filepath.Join(os.Getenv("HOME"), ".zshrc"))
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+
+	want := []HistoryEvent{
+		{Type: HistoryAdded, Snapshot: "zerb.20250102T000000.000Z.lua", Commit: addedAt},
+		{Type: HistoryRemoved, Snapshot: "zerb.20250104T000000.000Z.lua", Commit: removedAt},
+		{Type: HistoryAdded, Snapshot: "zerb.20250105T000000.000Z.lua", Commit: readdedAt},
+		{Type: HistoryRemoved, Snapshot: "zerb.20250106T000000.000Z.lua", Commit: ""},
+	}
+	if !reflect.DeepEqual(result.Events, want) {
+		t.Errorf("Events = %+v\nwant %+v", result.Events, want)
+	}
+	if result.Tracked {
+		t.Error("Tracked = true, want false")
+	}
+}
+
+func TestConfigHistoryService_History_SkipsUnparseable(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	zerbDir := setupAddTestRepo(t)
+
+	commitSnapshot(t, zerbDir, "zerb.20250102T000000.000Z.lua", "~/.zshrc")
+	os.WriteFile(filepath.Join(zerbDir, "configs", "zerb.20250103T000000.000Z.lua"), []byte("zerb = {"), 0600)
+
+	svc := NewConfigHistoryService(git.NewClient(zerbDir), config.NewParser(nil), zerbDir)
+	result, err := svc.History(context.Background(), "~/.zshrc")
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+
+	if len(result.Events) != 1 || result.Events[0].Type != HistoryAdded {
+		t.Errorf("Events = %+v, want a single add", result.Events)
+	}
+	if !result.Tracked {
+		t.Error("Tracked = false, want true")
+	}
+	if want := []string{"zerb.20250103T000000.000Z.lua"}; !reflect.DeepEqual(result.Skipped, want) {
+		t.Errorf("Skipped = %v, want %v", result.Skipped, want)
+	}
+}
+
+func TestConfigHistoryService_Snapshots(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	zerbDir := setupAddTestRepo(t)
+	initial := gitOutput(t, zerbDir, "rev-parse", "HEAD")
+
+	first := commitSnapshot(t, zerbDir, "zerb.20250102T000000.000Z.lua", "~/.zshrc")
+	second := commitSnapshot(t, zerbDir, "zerb.20250103T000000.000Z.lua", "~/.zshrc", "~/.vimrc")
+
+	svc := NewConfigHistoryService(git.NewClient(zerbDir), config.NewParser(nil), zerbDir)
+	got, err := svc.Snapshots(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("Snapshots() error = %v", err)
+	}
+
+	want := []struct{ hash, subject, snapshot string }{
+		{second, "Add zerb.20250103T000000.000Z.lua", "zerb.20250103T000000.000Z.lua"},
+		{first, "Add zerb.20250102T000000.000Z.lua", "zerb.20250102T000000.000Z.lua"},
+		{initial, "Initialize ZERB environment", "zerb.20250101T000000.000Z.lua"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Snapshots() returned %d commits, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Hash != w.hash || got[i].Subject != w.subject || got[i].Snapshot != w.snapshot {
+			t.Errorf("Snapshots()[%d] = %s %q %s, want %s %q %s", i, got[i].Hash, got[i].Subject, got[i].Snapshot, w.hash, w.subject, w.snapshot)
+		}
+	}
+
+	limited, err := svc.Snapshots(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Snapshots(1) error = %v", err)
+	}
+	if len(limited) != 1 || limited[0].Hash != second {
+		t.Errorf("Snapshots(1) = %+v, want only the newest commit", limited)
+	}
+}
+
+// failingCommitGit is a git client whose commits always fail
+type failingCommitGit struct {
+	git.Git
+}
+
+func (g failingCommitGit) Commit(ctx context.Context, msg, body string) error {
+	return errors.New("commit failed")
+}
+
+// activeSnapshot returns the snapshot named by the marker and the target of
+// the zerb.active.lua symlink
+func activeSnapshot(t *testing.T, zerbDir string) (marker, link string) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(zerbDir, ".zerb-active"))
+	if err != nil {
+		t.Fatalf("failed to read active marker: %v", err)
+	}
+	link, err = os.Readlink(filepath.Join(zerbDir, "zerb.active.lua"))
+	if err != nil {
+		t.Fatalf("failed to read active symlink: %v", err)
+	}
+	return strings.TrimSpace(string(data)), link
+}
+
+func TestConfigHistoryService_RestoreSnapshot(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	const (
+		initial = "zerb.20250101T000000.000Z.lua"
+		second  = "zerb.20250102T000000.000Z.lua"
+	)
+
+	// setup returns a repository whose active config is a second snapshot
+	setup := func(t *testing.T) string {
+		zerbDir := setupAddTestRepo(t)
+		commitSnapshot(t, zerbDir, second, "~/.zshrc")
+		if err := activateConfigSnapshot(zerbDir, second, ""); err != nil {
+			t.Fatalf("activateConfigSnapshot() error = %v", err)
+		}
+		gitOutput(t, zerbDir, "add", ".zerb-active", "zerb.active.lua")
+		gitOutput(t, zerbDir, "commit", "-q", "-m", "Activate "+second)
+		return zerbDir
+	}
+
+	t.Run("restores and commits", func(t *testing.T) {
+		zerbDir := setup(t)
+		svc := NewConfigHistoryService(git.NewClient(zerbDir), config.NewParser(nil), zerbDir)
+
+		result, err := svc.RestoreSnapshot(context.Background(), "configs/"+initial)
+		if err != nil {
+			t.Fatalf("RestoreSnapshot() error = %v", err)
+		}
+		if result.Snapshot != initial || result.Previous != second || result.FromHistory {
+			t.Errorf("RestoreSnapshot() = %+v", result)
+		}
+		if marker, link := activeSnapshot(t, zerbDir); marker != initial || link != filepath.Join("configs", initial) {
+			t.Errorf("active config = %s -> %s, want %s", marker, link, initial)
+		}
+		if result.CommitHash != gitOutput(t, zerbDir, "rev-parse", "HEAD") {
+			t.Errorf("CommitHash = %s, want HEAD", result.CommitHash)
+		}
+		if got := gitOutput(t, zerbDir, "log", "-1", "--format=%s%n%b"); got != "Roll back config to "+initial+"\nPreviously active: "+second {
+			t.Errorf("commit message = %q", got)
+		}
+		if got := gitOutput(t, zerbDir, "status", "--porcelain"); got != "" {
+			t.Errorf("working tree not clean after restore:\n%s", got)
+		}
+	})
+
+	t.Run("recovers a pruned snapshot", func(t *testing.T) {
+		zerbDir := setup(t)
+		gitOutput(t, zerbDir, "rm", "-q", filepath.Join("configs", initial))
+		gitOutput(t, zerbDir, "commit", "-q", "-m", "Prune "+initial)
+		svc := NewConfigHistoryService(git.NewClient(zerbDir), config.NewParser(nil), zerbDir)
+
+		result, err := svc.RestoreSnapshot(context.Background(), initial)
+		if err != nil {
+			t.Fatalf("RestoreSnapshot() error = %v", err)
+		}
+		if !result.FromHistory {
+			t.Error("FromHistory = false, want true")
+		}
+		if got := gitOutput(t, zerbDir, "ls-files", "configs/"+initial); got != "configs/"+initial {
+			t.Errorf("recovered snapshot not committed: ls-files = %q", got)
+		}
+		if got := gitOutput(t, zerbDir, "status", "--porcelain"); got != "" {
+			t.Errorf("working tree not clean after restore:\n%s", got)
+		}
+	})
+
+	t.Run("failed commit changes nothing", func(t *testing.T) {
+		zerbDir := setup(t)
+		gitOutput(t, zerbDir, "rm", "-q", filepath.Join("configs", initial))
+		gitOutput(t, zerbDir, "commit", "-q", "-m", "Prune "+initial)
+		head := gitOutput(t, zerbDir, "rev-parse", "HEAD")
+		svc := NewConfigHistoryService(failingCommitGit{git.NewClient(zerbDir)}, config.NewParser(nil), zerbDir)
+
+		if _, err := svc.RestoreSnapshot(context.Background(), initial); err == nil {
+			t.Fatal("RestoreSnapshot() error = nil, want commit error")
+		}
+		if marker, link := activeSnapshot(t, zerbDir); marker != second || link != filepath.Join("configs", second) {
+			t.Errorf("active config = %s -> %s, want %s", marker, link, second)
+		}
+		if _, err := os.Stat(filepath.Join(zerbDir, "configs", initial)); !os.IsNotExist(err) {
+			t.Errorf("recovered snapshot left behind: %v", err)
+		}
+		if got := gitOutput(t, zerbDir, "rev-parse", "HEAD"); got != head {
+			t.Errorf("HEAD = %s, want %s", got, head)
+		}
+		if got := gitOutput(t, zerbDir, "status", "--porcelain"); got != "" {
+			t.Errorf("working tree or index changed:\n%s", got)
+		}
+	})
+
+	t.Run("failed commit switches back from the host branch", func(t *testing.T) {
+		zerbDir := setup(t)
+		perHost := "zerb.20250103T000000.000Z.lua"
+		content, err := config.NewGenerator().Generate(context.Background(), &config.Config{Git: config.GitConfig{PerHostBranches: true}})
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(zerbDir, "configs", perHost), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write snapshot: %v", err)
+		}
+		gitOutput(t, zerbDir, "add", filepath.Join("configs", perHost))
+		gitOutput(t, zerbDir, "commit", "-q", "-m", "Add "+perHost)
+		baseBranch := gitOutput(t, zerbDir, "rev-parse", "--abbrev-ref", "HEAD")
+		svc := NewConfigHistoryService(failingCommitGit{git.NewClient(zerbDir)}, config.NewParser(nil), zerbDir).
+			WithHostBranch("hosts/test-machine")
+
+		if _, err := svc.RestoreSnapshot(context.Background(), perHost); err == nil {
+			t.Fatal("RestoreSnapshot() error = nil, want commit error")
+		}
+		if got := gitOutput(t, zerbDir, "rev-parse", "--abbrev-ref", "HEAD"); got != baseBranch {
+			t.Errorf("current branch = %q, want %q", got, baseBranch)
+		}
+		if marker, _ := activeSnapshot(t, zerbDir); marker != second {
+			t.Errorf("active config = %s, want %s", marker, second)
+		}
+		if got := gitOutput(t, zerbDir, "status", "--porcelain"); got != "" {
+			t.Errorf("working tree or index changed:\n%s", got)
+		}
+	})
+
+	t.Run("invalid snapshot is not activated", func(t *testing.T) {
+		zerbDir := setup(t)
+		broken := "zerb.20250103T000000.000Z.lua"
+		if err := os.WriteFile(filepath.Join(zerbDir, "configs", broken), []byte("zerb = {"), 0600); err != nil {
+			t.Fatalf("failed to write snapshot: %v", err)
+		}
+		svc := NewConfigHistoryService(git.NewClient(zerbDir), config.NewParser(nil), zerbDir)
+
+		if _, err := svc.RestoreSnapshot(context.Background(), broken); err == nil {
+			t.Fatal("RestoreSnapshot() error = nil, want parse error")
+		}
+		if marker, _ := activeSnapshot(t, zerbDir); marker != second {
+			t.Errorf("active config = %s, want %s", marker, second)
+		}
+		if _, err := os.Stat(filepath.Join(zerbDir, "configs", broken)); err != nil {
+			t.Errorf("snapshot already on disk was removed: %v", err)
+		}
+	})
+
+	errorTests := []struct {
+		name    string
+		version string
+		wantErr error
+	}{
+		{name: "already active", version: second, wantErr: ErrSnapshotActive},
+		{name: "unknown version", version: "zerb.20240101T000000.000Z.lua", wantErr: ErrSnapshotNotFound},
+		{name: "not a snapshot name", version: "../.zerb-active"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			zerbDir := setup(t)
+			svc := NewConfigHistoryService(git.NewClient(zerbDir), config.NewParser(nil), zerbDir)
+
+			_, err := svc.RestoreSnapshot(context.Background(), tt.version)
+			if err == nil {
+				t.Fatal("RestoreSnapshot() error = nil, want error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("RestoreSnapshot() error = %v, want %v", err, tt.wantErr)
+			}
+			if marker, _ := activeSnapshot(t, zerbDir); marker != second {
+				t.Errorf("active config = %s, want %s", marker, second)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 
@@ -31,9 +32,22 @@ func NewConfigListService(parser ConfigParser, detector config.StatusDetector, z
 	}
 }
 
+// ListSort is the order configs are listed in.
+type ListSort string
+
+const (
+	// ListSortPath sorts configs by path (the default).
+	ListSortPath ListSort = "path"
+	// ListSortStatus sorts configs by status name, then path.
+	ListSortStatus ListSort = "status"
+)
+
 // ListRequest contains parameters for listing configs.
 type ListRequest struct {
-	// Future: add flags like --all, --format, etc.
+	// Statuses keeps only configs with one of these statuses; empty keeps all.
+	Statuses []config.ConfigStatus
+	// Sort orders the configs; empty sorts by path.
+	Sort ListSort
 }
 
 // ListResult contains the results of the list operation.
@@ -49,6 +63,12 @@ func (s *ConfigListService) List(ctx context.Context, req ListRequest) (*ListRes
 		return nil, err
 	}
 
+	switch req.Sort {
+	case "", ListSortPath, ListSortStatus:
+	default:
+		return nil, fmt.Errorf("unknown sort order %q (valid: path, status)", req.Sort)
+	}
+
 	// Read active marker to get active config version
 	activeMarker := filepath.Join(s.zerbDir, ".zerb-active")
 	markerData, err := os.ReadFile(activeMarker)
@@ -101,6 +121,14 @@ func (s *ConfigListService) List(ctx context.Context, req ListRequest) (*ListRes
 			return nil, fmt.Errorf("normalize path %q: %w", cfg.Configs[i].Path, err)
 		}
 		cfg.Configs[i].Path = normalizedPath
+
+		if target := cfg.Configs[i].Target; target != "" {
+			normalizedTarget, err := config.NormalizeConfigPath(target)
+			if err != nil {
+				return nil, fmt.Errorf("normalize target %q: %w", target, err)
+			}
+			cfg.Configs[i].Target = normalizedTarget
+		}
 	}
 
 	// Check context before status detection
@@ -114,9 +142,20 @@ func (s *ConfigListService) List(ctx context.Context, req ListRequest) (*ListRes
 		return nil, fmt.Errorf("detect status: %w", err)
 	}
 
-	// Sort alphabetically by path
+	// Filter on the detected statuses, which are authoritative
+	if len(req.Statuses) > 0 {
+		configsWithStatus = slices.DeleteFunc(configsWithStatus, func(cfg config.ConfigWithStatus) bool {
+			return !slices.Contains(req.Statuses, cfg.Status)
+		})
+	}
+
+	// Sort alphabetically by path, grouped by status if requested
 	sort.Slice(configsWithStatus, func(i, j int) bool {
-		return configsWithStatus[i].ConfigFile.Path < configsWithStatus[j].ConfigFile.Path
+		a, b := configsWithStatus[i], configsWithStatus[j]
+		if req.Sort == ListSortStatus && a.Status != b.Status {
+			return a.Status.String() < b.Status.String()
+		}
+		return a.ConfigFile.Path < b.ConfigFile.Path
 	})
 
 	return &ListResult{
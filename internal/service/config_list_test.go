@@ -424,3 +424,86 @@ func TestConfigListService_List_MissingActiveConfig(t *testing.T) {
 		t.Fatal("expected error for missing active config, got nil")
 	}
 }
+
+func TestConfigListService_List_FilterAndSort(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
+
+	tmpDir := t.TempDir()
+	activeFilename := "zerb.20250116T143022Z.lua"
+	if err := os.WriteFile(filepath.Join(tmpDir, ".zerb-active"), []byte(activeFilename), 0644); err != nil {
+		t.Fatalf("failed to create active marker: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, "configs"), 0755); err != nil {
+		t.Fatalf("failed to create configs dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "configs", activeFilename), []byte("zerb = {}"), 0644); err != nil {
+		t.Fatalf("failed to create active config: %v", err)
+	}
+
+	parser := &mockListParser{
+		parseFunc: func(ctx context.Context, lua string) (*config.Config, error) {
+			return &config.Config{
+				Configs: []config.ConfigFile{
+					{Path: "~/.zshrc"},
+					{Path: "~/.bashrc"},
+					{Path: "~/.gitconfig"},
+					{Path: "~/.vimrc"},
+				},
+			}, nil
+		},
+	}
+
+	// Statuses are decided by the detector, from the normalized paths
+	statuses := map[string]config.ConfigStatus{
+		filepath.Join(home, ".zshrc"):     config.StatusModified,
+		filepath.Join(home, ".bashrc"):    config.StatusSynced,
+		filepath.Join(home, ".gitconfig"): config.StatusMissing,
+		filepath.Join(home, ".vimrc"):     config.StatusModified,
+	}
+	detector := &mockStatusDetector{
+		detectFunc: func(ctx context.Context, configs []config.ConfigFile) ([]config.ConfigWithStatus, error) {
+			results := make([]config.ConfigWithStatus, len(configs))
+			for i, cfg := range configs {
+				results[i] = config.ConfigWithStatus{ConfigFile: cfg, Status: statuses[cfg.Path]}
+			}
+			return results, nil
+		},
+	}
+	svc := NewConfigListService(parser, detector, tmpDir)
+
+	tests := []struct {
+		name string
+		req  ListRequest
+		want []string
+	}{
+		{name: "default", req: ListRequest{}, want: []string{".bashrc", ".gitconfig", ".vimrc", ".zshrc"}},
+		{name: "modified only", req: ListRequest{Statuses: []config.ConfigStatus{config.StatusModified}}, want: []string{".vimrc", ".zshrc"}},
+		{name: "several statuses", req: ListRequest{Statuses: []config.ConfigStatus{config.StatusMissing, config.StatusSynced}}, want: []string{".bashrc", ".gitconfig"}},
+		{name: "no match", req: ListRequest{Statuses: []config.ConfigStatus{config.StatusPartial}}, want: nil},
+		{name: "sort by status", req: ListRequest{Sort: ListSortStatus}, want: []string{".gitconfig", ".vimrc", ".zshrc", ".bashrc"}},
+		{name: "sort by path", req: ListRequest{Sort: ListSortPath}, want: []string{".bashrc", ".gitconfig", ".vimrc", ".zshrc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := svc.List(context.Background(), tt.req)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			var got []string
+			for _, cfg := range result.Configs {
+				got = append(got, filepath.Base(cfg.ConfigFile.Path))
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("List() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := svc.List(context.Background(), ListRequest{Sort: "size"}); err == nil {
+		t.Error("List() with unknown sort order error = nil, want error")
+	}
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/transaction"
+)
+
+// RecoverRequest contains the parameters for recovering an interrupted config add.
+type RecoverRequest struct {
+	DryRun bool // Report what recovery would do without changing anything
+
+	// ForceUnlock removes a held transaction lock first, for a lock whose
+	// holder's PID was reused by another process
+	ForceUnlock bool
+}
+
+// RecoverResult contains the results of the recover operation.
+type RecoverResult struct {
+	Action        transaction.RecoveryAction
+	TxnID         string   // Transaction recovered; empty if there was none
+	Paths         []string // Paths untracked (roll back) or committed (resume)
+	Pending       []string // Paths the transaction never started
+	ConfigVersion string   // Snapshot discarded (roll back) or activated (resume)
+	CommitHash    string
+}
+
+// Recover finishes or undoes the last config add that was interrupted, as
+// decided by transaction.Recover. A roll back untracks every path the add
+// started and discards its config snapshot unless it is active; a resume
+// activates the snapshot and creates the commit. Either way the transaction
+// file is removed, so running Recover again reports nothing to do.
+func (s *ConfigAddService) Recover(ctx context.Context, req RecoverRequest) (*RecoverResult, error) {
+	// 1. Acquire transaction lock
+	txnDir := filepath.Join(s.zerbDir, ".txn")
+	if req.ForceUnlock {
+		if err := transaction.ForceUnlock(txnDir); err != nil {
+			return nil, err
+		}
+	}
+	lock, err := transaction.AcquireLock(txnDir)
+	if err != nil {
+		return nil, fmt.Errorf("acquire transaction lock: %w", err)
+	}
+	defer func() { _ = lock.Release() }()
+
+	// 2. Load the last transaction and decide what to do
+	report, err := transaction.Recover(txnDir)
+	if err != nil {
+		return nil, fmt.Errorf("read transaction: %w", err)
+	}
+
+	result := &RecoverResult{Action: report.Action}
+	if report.Txn == nil {
+		return result, nil
+	}
+	result.TxnID = report.Txn.ID
+	for _, p := range report.Pending {
+		result.Pending = append(result.Pending, p.Path)
+	}
+
+	// 3. Recover
+	switch report.Action {
+	case transaction.RecoveryRollBack:
+		for _, p := range report.Undo() {
+			result.Paths = append(result.Paths, p.Path)
+		}
+		result.ConfigVersion = report.Txn.ConfigFile
+		if req.DryRun {
+			return result, nil
+		}
+		if err := s.rollBackAdd(ctx, report); err != nil {
+			return nil, err
+		}
+	case transaction.RecoveryResume:
+		for _, p := range report.Completed {
+			result.Paths = append(result.Paths, p.Path)
+		}
+		result.ConfigVersion = report.Txn.ConfigFile
+		if req.DryRun {
+			return result, nil
+		}
+		if err := s.resumeAdd(ctx, report, result); err != nil {
+			return nil, err
+		}
+	default:
+		if req.DryRun {
+			return result, nil
+		}
+	}
+
+	// 4. Nothing is left to recover
+	if err := report.Txn.Finish(txnDir); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// rollBackAdd untracks the paths an interrupted add started and removes the
+// snapshot it began to write. Paths no longer tracked are skipped, so an
+// interrupted roll back can be run again.
+func (s *ConfigAddService) rollBackAdd(ctx context.Context, report *transaction.RecoveryReport) error {
+	roots, err := s.pathRoots()
+	if err != nil {
+		return fmt.Errorf("resolve path roots: %w", err)
+	}
+
+	for _, p := range report.Undo() {
+		// The source state is named after where the config is applied
+		target := p.Target
+		if target == "" {
+			target = p.Path
+		}
+		absTarget, err := roots.Expand(target)
+		if err != nil {
+			return fmt.Errorf("invalid path %q: %w", target, err)
+		}
+
+		managed, err := s.chezmoi.HasFile(ctx, absTarget)
+		if err != nil {
+			return fmt.Errorf("check whether %q is tracked: %w", p.Path, err)
+		}
+		if !managed {
+			continue
+		}
+		if err := s.chezmoi.Forget(ctx, absTarget); err != nil {
+			return fmt.Errorf("failed to untrack %q: %w", p.Path, err)
+		}
+	}
+
+	filename := report.Txn.ConfigFile
+	if filename == "" {
+		return nil
+	}
+
+	// Never remove the snapshot the active config points at
+	marker, err := os.ReadFile(filepath.Join(s.zerbDir, ".zerb-active"))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("read active marker: %w", err)
+	}
+	if strings.TrimSpace(string(marker)) == filename {
+		return nil
+	}
+	if err := os.Remove(filepath.Join(s.zerbDir, "configs", filename)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove partial config: %w", err)
+	}
+	return nil
+}
+
+// resumeAdd activates and commits the snapshot an interrupted add wrote
+// after adding every path. A commit that already exists is not repeated.
+func (s *ConfigAddService) resumeAdd(ctx context.Context, report *transaction.RecoveryReport, result *RecoverResult) error {
+	filename := report.Txn.ConfigFile
+	content, err := os.ReadFile(filepath.Join(s.zerbDir, "configs", filename))
+	if err != nil {
+		return fmt.Errorf("read config %s: %w", filename, err)
+	}
+
+	cfg, err := s.parser.ParseString(ctx, string(content))
+	if err != nil {
+		return fmt.Errorf("config version %s is invalid: %w", filename, err)
+	}
+
+	if err := activateConfigSnapshot(s.zerbDir, filename, string(content)); err != nil {
+		return err
+	}
+
+	if err := checkoutSnapshotBranch(ctx, s.git, cfg, s.hostBranch); err != nil {
+		return err
+	}
+
+	var sourcePaths []string
+	for _, p := range report.Completed {
+		if len(p.CreatedSourceFiles) == 0 {
+			// Unknown source entry; stage the whole source directory
+			sourcePaths = append(sourcePaths, "")
+		}
+		sourcePaths = append(sourcePaths, p.CreatedSourceFiles...)
+	}
+	if err := s.git.Stage(ctx, addStagePaths(filename, sourcePaths)...); err != nil {
+		return fmt.Errorf("stage files: %w", err)
+	}
+
+	err = s.git.Commit(ctx, s.generateCommitMessage(result.Paths), s.generateCommitBody(result.Paths, sourcePaths))
+	if err != nil && !errors.Is(err, git.ErrNothingToCommit) {
+		return fmt.Errorf("create commit: %w", err)
+	}
+
+	commitHash, err := s.git.GetHeadCommit(ctx)
+	if err == nil {
+		result.CommitHash = commitHash
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/transaction"
+)
+
+// txnFiles returns the transaction files left in zerbDir
+func txnFiles(t *testing.T, zerbDir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(zerbDir, ".txn", "txn-config-add-*.json"))
+	if err != nil {
+		t.Fatalf("failed to list transactions: %v", err)
+	}
+	return files
+}
+
+func TestConfigAddService_Recover_RollBack(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	zerbDir := setupAddTestRepo(t)
+	head := gitOutput(t, zerbDir, "rev-parse", "HEAD")
+
+	// An add interrupted while adding its second path, after the first was
+	// added and before any config was written
+	txn := transaction.New([]string{"~/.zshrc", "~/.vimrc", "~/.tmux.conf"}, nil)
+	txn.UpdatePathState("~/.zshrc", transaction.StateCompleted, []string{"chezmoi/source/dot_zshrc"}, nil)
+	txn.UpdatePathState("~/.vimrc", transaction.StateInProgress, nil, nil)
+	txn.ConfigFile = "zerb.20250102T030405.000Z.lua"
+	if err := txn.Save(filepath.Join(zerbDir, ".txn")); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	partial := filepath.Join(zerbDir, "configs", txn.ConfigFile)
+	if err := os.WriteFile(partial, []byte("zerb = {"), 0600); err != nil {
+		t.Fatalf("failed to write partial config: %v", err)
+	}
+
+	cm := &mockChezmoi{added: []string{filepath.Join(home, ".zshrc")}}
+	svc := newTestAddService(zerbDir, cm)
+
+	// A dry run reports the plan without changing anything
+	preview, err := svc.Recover(context.Background(), RecoverRequest{DryRun: true})
+	if err != nil {
+		t.Fatalf("Recover() dry run error = %v", err)
+	}
+	if preview.Action != transaction.RecoveryRollBack || preview.TxnID != txn.ID {
+		t.Errorf("Recover() dry run = %+v, want roll back of %s", preview, txn.ID)
+	}
+	if len(cm.forgotten) != 0 || len(txnFiles(t, zerbDir)) != 1 {
+		t.Fatalf("dry run changed state: forgotten %v, transactions %v", cm.forgotten, txnFiles(t, zerbDir))
+	}
+
+	result, err := svc.Recover(context.Background(), RecoverRequest{})
+	if err != nil {
+		t.Fatalf("Recover() error = %v", err)
+	}
+	if want := []string{"~/.zshrc", "~/.vimrc"}; !reflect.DeepEqual(result.Paths, want) {
+		t.Errorf("Paths = %v, want %v", result.Paths, want)
+	}
+	if want := []string{"~/.tmux.conf"}; !reflect.DeepEqual(result.Pending, want) {
+		t.Errorf("Pending = %v, want %v", result.Pending, want)
+	}
+
+	// Only the path that is still tracked is forgotten
+	if want := []string{filepath.Join(home, ".zshrc")}; !reflect.DeepEqual(cm.forgotten, want) {
+		t.Errorf("forgotten = %v, want %v", cm.forgotten, want)
+	}
+	if _, err := os.Stat(partial); !os.IsNotExist(err) {
+		t.Errorf("partial config not removed: %v", err)
+	}
+	if files := txnFiles(t, zerbDir); len(files) != 0 {
+		t.Errorf("transaction files left after recovery: %v", files)
+	}
+	if got := gitOutput(t, zerbDir, "rev-parse", "HEAD"); got != head {
+		t.Errorf("HEAD = %s, want %s (roll back must not commit)", got, head)
+	}
+
+	// Recovering again finds nothing to do
+	again, err := svc.Recover(context.Background(), RecoverRequest{})
+	if err != nil {
+		t.Fatalf("second Recover() error = %v", err)
+	}
+	if again.Action != transaction.RecoveryNone || len(cm.forgotten) != 1 {
+		t.Errorf("second Recover() = %+v, forgotten %v; want nothing to do", again, cm.forgotten)
+	}
+}
+
+func TestConfigAddService_Recover_Resume(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	zerbDir := setupAddTestRepo(t)
+	cm := &mockChezmoi{}
+	newTestAddService(zerbDir, cm) // Installs the add stub
+	clock := TestClock{FixedTime: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	// Interrupt an add at the commit
+	interrupted := NewConfigAddService(cm, failingCommitGit{git.NewClient(zerbDir)}, config.NewParser(nil), config.NewGenerator().WithClock(clock), clock, zerbDir)
+	if _, err := interrupted.Execute(context.Background(), AddRequest{Paths: []string{"~/.zshrc"}, SkipCheck: true}); err == nil {
+		t.Fatal("Execute() error = nil, want commit error")
+	}
+	if files := txnFiles(t, zerbDir); len(files) != 1 {
+		t.Fatalf("transaction files after interrupted add = %v, want one", files)
+	}
+
+	svc := newTestAddService(zerbDir, cm)
+	result, err := svc.Recover(context.Background(), RecoverRequest{})
+	if err != nil {
+		t.Fatalf("Recover() error = %v", err)
+	}
+	if result.Action != transaction.RecoveryResume {
+		t.Fatalf("Action = %s, want resume", result.Action)
+	}
+	if result.CommitHash != gitOutput(t, zerbDir, "rev-parse", "HEAD") {
+		t.Errorf("CommitHash = %s, want HEAD", result.CommitHash)
+	}
+	if got := gitOutput(t, zerbDir, "log", "-1", "--format=%s"); got != "Add ~/.zshrc to tracked configs" {
+		t.Errorf("commit subject = %q", got)
+	}
+	if got := gitOutput(t, zerbDir, "status", "--porcelain"); got != "" {
+		t.Errorf("working tree not clean after resume:\n%s", got)
+	}
+	marker, err := os.ReadFile(filepath.Join(zerbDir, ".zerb-active"))
+	if err != nil || string(marker) != result.ConfigVersion+"\n" {
+		t.Errorf("active marker = %q, %v, want %s", marker, err, result.ConfigVersion)
+	}
+	if files := txnFiles(t, zerbDir); len(files) != 0 {
+		t.Errorf("transaction files left after recovery: %v", files)
+	}
+}
+
+func TestConfigAddService_Execute_FinishesTransaction(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	for _, noCommit := range []bool{false, true} {
+		zerbDir := setupAddTestRepo(t)
+		svc := newTestAddService(zerbDir, &mockChezmoi{})
+		if _, err := svc.Execute(context.Background(), AddRequest{Paths: []string{"~/.zshrc"}, SkipCheck: true, NoCommit: noCommit}); err != nil {
+			t.Fatalf("Execute(NoCommit=%v) error = %v", noCommit, err)
+		}
+		if files := txnFiles(t, zerbDir); len(files) != 0 {
+			t.Errorf("Execute(NoCommit=%v) left transaction files: %v", noCommit, files)
+		}
+	}
+}
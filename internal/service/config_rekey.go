@@ -0,0 +1,387 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/chezmoi"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/fsutil"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/transaction"
+)
+
+// ErrSameRecipient is returned when secrets are already encrypted to the
+// requested recipient.
+var ErrSameRecipient = errors.New("secrets are already encrypted to this recipient")
+
+// ConfigRekeyService orchestrates re-encrypting secret configs to a new
+// recipient, e.g. after rotating a GPG or age key.
+type ConfigRekeyService struct {
+	encryption chezmoi.Encryption
+	git        git.Git
+	parser     ConfigParser
+	generator  ConfigGenerator
+	zerbDir    string
+
+	hostBranch string
+}
+
+// NewConfigRekeyService creates a new config rekey service with dependency injection.
+func NewConfigRekeyService(
+	encryption chezmoi.Encryption,
+	gitClient git.Git,
+	parser ConfigParser,
+	generator ConfigGenerator,
+	zerbDir string,
+) *ConfigRekeyService {
+	return &ConfigRekeyService{
+		encryption: encryption,
+		git:        gitClient,
+		parser:     parser,
+		generator:  generator,
+		zerbDir:    zerbDir,
+	}
+}
+
+// WithHostBranch sets the branch commits go to when the config enables
+// per-host branches, instead of deriving it from the machine.
+func (s *ConfigRekeyService) WithHostBranch(name string) *ConfigRekeyService {
+	s.hostBranch = name
+	return s
+}
+
+// RekeyRequest contains the parameters for re-encrypting secret configs.
+type RekeyRequest struct {
+	Recipient string // New recipient (GPG key ID or age1... public key)
+	Identity  string // age private key file (supports ~); empty keeps the configured one
+	DryRun    bool   // List what would be re-encrypted without changing anything
+	NoCommit  bool   // Stage changes but leave the git commit to the user
+}
+
+// RekeyResult contains the results of the rekey operation.
+type RekeyResult struct {
+	OldRecipient string
+	Configs      []string // Secret configs, as written in the config
+	Files        []string // Re-encrypted source files, relative to the ZERB directory
+	CommitHash   string
+	Uncommitted  bool // Changes are staged but not committed (NoCommit)
+}
+
+// Execute re-encrypts every file of every secrets-flagged config to
+// req.Recipient and records the new key in a config snapshot, so every
+// machine encrypts to it. All files are decrypted with the current key
+// before anything is changed, and with the new key before anything is
+// committed; if a later step fails, the files, the recipient, the active
+// config and the branch are restored.
+func (s *ConfigRekeyService) Execute(ctx context.Context, req RekeyRequest) (*RekeyResult, error) {
+	if strings.TrimSpace(req.Recipient) == "" {
+		return nil, fmt.Errorf("recipient cannot be empty")
+	}
+
+	// 1. Acquire transaction lock
+	txnDir := filepath.Join(s.zerbDir, ".txn")
+	lock, err := transaction.AcquireLock(txnDir)
+	if err != nil {
+		return nil, fmt.Errorf("acquire transaction lock: %w", err)
+	}
+	defer func() { _ = lock.Release() }()
+
+	// 2. Read current config
+	activeConfigPath := filepath.Join(s.zerbDir, "zerb.active.lua")
+	cfgData, err := os.ReadFile(activeConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("read active config: %w", err)
+	}
+	currentConfig, err := s.parser.ParseString(ctx, string(cfgData))
+	if err != nil {
+		return nil, fmt.Errorf("parse current config: %w", err)
+	}
+	markerData, err := os.ReadFile(filepath.Join(s.zerbDir, ".zerb-active"))
+	if err != nil {
+		return nil, fmt.Errorf("read active marker: %w", err)
+	}
+	previous := strings.TrimSpace(string(markerData))
+
+	settings := config.EncryptionConfig{Recipient: req.Recipient, Identity: req.Identity}
+	if settings.Identity == "" && settings.IsAge() {
+		// Keep the identity file the repository already decrypts with
+		settings.Identity = config.DefaultAgeIdentity
+		if currentConfig.Encryption.IsAge() {
+			settings.Identity = currentConfig.Encryption.IdentityFile()
+		}
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid identity %q: %w", settings.Identity, err)
+	}
+
+	// 3. The committed recipient wins over a stale local one
+	result := &RekeyResult{OldRecipient: currentConfig.Encryption.Recipient}
+	if result.OldRecipient == "" {
+		result.OldRecipient, err = s.encryption.Recipient()
+		if err != nil {
+			return nil, fmt.Errorf("read current recipient: %w", err)
+		}
+	}
+	if result.OldRecipient == req.Recipient {
+		return nil, fmt.Errorf("%w: %s", ErrSameRecipient, req.Recipient)
+	}
+
+	// 4. Collect the encrypted files of every secret config
+	var files []string
+	for _, cf := range currentConfig.Configs {
+		if !cf.Secrets {
+			continue
+		}
+		result.Configs = append(result.Configs, cf.Path)
+
+		// The source state is named after where the config is applied
+		target, err := config.NormalizeConfigPath(cf.TargetPath())
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %q: %w", cf.Path, err)
+		}
+		secretFiles, err := s.encryption.SecretFiles(ctx, target)
+		if err != nil {
+			return nil, fmt.Errorf("find encrypted files of %q: %w", cf.Path, err)
+		}
+		files = append(files, secretFiles...)
+	}
+	for _, file := range files {
+		result.Files = append(result.Files, s.relPath(file))
+	}
+
+	if len(files) == 0 || req.DryRun {
+		return result, nil
+	}
+
+	// 5. Check every file decrypts with the current key before changing anything
+	if err := ApplyEncryptionConfig(s.encryption, currentConfig); err != nil {
+		return nil, err
+	}
+	plaintexts := make([][]byte, len(files))
+	for i, file := range files {
+		plaintext, err := s.encryption.Decrypt(ctx, file)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decrypt %s with the current key (nothing was changed): %w", s.relPath(file), err)
+		}
+		plaintexts[i] = plaintext
+	}
+
+	// 6. Switch the key and re-encrypt, restoring everything on failure
+	restore, err := s.reencrypt(ctx, settings, files, plaintexts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Undo every completed step unless the changes are committed, or
+	// staged for the user to commit
+	var newConfigFilename, originalBranch string
+	var staged, done bool
+	defer func() {
+		if done {
+			return
+		}
+		s.undoRekey(context.WithoutCancel(ctx), restore, previous, string(cfgData), newConfigFilename, originalBranch, staged)
+	}()
+
+	// 7. Record the new key in a config snapshot
+	updatedConfig := *currentConfig
+	updatedConfig.Encryption = settings
+	newConfigFilename, err = s.writeSnapshot(ctx, &updatedConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// 8. Stage and commit
+	// Remembered so a failed commit switches back from the host branch
+	if branch, err := s.git.CurrentBranch(ctx); err == nil {
+		originalBranch = branch
+	}
+	if err := checkoutSnapshotBranch(ctx, s.git, currentConfig, s.hostBranch); err != nil {
+		return nil, err
+	}
+	staged = true
+	if err := s.git.Stage(ctx, snapshotStagePaths(newConfigFilename)...); err != nil {
+		return nil, fmt.Errorf("stage files: %w", err)
+	}
+
+	if req.NoCommit {
+		done = true
+		result.Uncommitted = true
+		return result, nil
+	}
+
+	if err := s.git.Commit(ctx, s.generateCommitMessage(result.Configs), s.generateCommitBody(result.Configs)); err != nil {
+		return nil, fmt.Errorf("create commit: %w", err)
+	}
+	done = true
+
+	commitHash, err := s.git.GetHeadCommit(ctx)
+	if err == nil {
+		result.CommitHash = commitHash
+	}
+
+	return result, nil
+}
+
+// undoRekey puts back what a failed rekey changed after re-encrypting: the
+// files and encryption config (via restore), the active config, HEAD on
+// originalBranch (unless it is empty), and an index matching the working
+// tree. snapshot is the new config snapshot, empty if none was written.
+// Errors are ignored, as the rekey's own error is the one reported.
+func (s *ConfigRekeyService) undoRekey(ctx context.Context, restore func(), previous, previousContent, snapshot, originalBranch string, staged bool) {
+	restore()
+	if originalBranch != "" {
+		if current, err := s.git.CurrentBranch(ctx); err == nil && current != originalBranch {
+			_ = s.git.CheckoutBranch(ctx, originalBranch)
+		}
+	}
+	switch snapshot {
+	case "":
+	case previous:
+		// Written in the same second, so it replaced the previous snapshot
+		_ = writeConfigSnapshot(s.zerbDir, previous, previousContent)
+	default:
+		_ = os.Remove(filepath.Join(s.zerbDir, "configs", snapshot))
+	}
+	_ = activateConfigSnapshot(s.zerbDir, previous, previousContent)
+	if staged {
+		// One at a time, so a failure cannot leave the rest staged
+		for _, path := range snapshotStagePaths(snapshot) {
+			_ = s.git.Stage(ctx, path)
+		}
+	}
+}
+
+// ApplyEncryptionConfig writes the encryption settings of cfg to the
+// configuration manager's own config file. That file is not committed, so
+// this is how a fresh clone picks up the key the repository is encrypted
+// to. A config without settings leaves the file alone.
+func ApplyEncryptionConfig(encryption chezmoi.Encryption, cfg *config.Config) error {
+	if cfg.Encryption.Recipient == "" {
+		return nil
+	}
+	if err := encryption.SetRecipient(cfg.Encryption.Recipient, cfg.Encryption.IdentityFile()); err != nil {
+		return fmt.Errorf("apply encryption settings: %w", err)
+	}
+	return nil
+}
+
+// writeSnapshot generates cfg as a new config snapshot and activates it,
+// returning its filename.
+func (s *ConfigRekeyService) writeSnapshot(ctx context.Context, cfg *config.Config) (string, error) {
+	filename, content, err := s.generator.GenerateTimestamped(ctx, cfg, "")
+	if err != nil {
+		return "", fmt.Errorf("generate config: %w", err)
+	}
+	if err := writeConfigSnapshot(s.zerbDir, filename, content); err != nil {
+		return "", err
+	}
+	if err := activateConfigSnapshot(s.zerbDir, filename, content); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+// reencrypt sets the new key, rewrites each file with its plaintext
+// encrypted to it, and checks each file decrypts back to its plaintext, so
+// a recipient and identity that do not match are caught before anything is
+// committed. On failure the original files and encryption config are
+// restored, so no recipient is left configured if none was. On success the
+// returned restore func undoes the same changes.
+func (s *ConfigRekeyService) reencrypt(ctx context.Context, settings config.EncryptionConfig, files []string, plaintexts [][]byte) (restore func(), err error) {
+	originals := make(map[string][]byte, len(files))
+	modes := make(map[string]os.FileMode, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("stat %s: %w", s.relPath(file), err)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", s.relPath(file), err)
+		}
+		originals[file] = data
+		modes[file] = info.Mode().Perm()
+	}
+
+	originalConfig, err := s.encryption.EncryptionConfig()
+	if err != nil {
+		return nil, fmt.Errorf("read encryption config: %w", err)
+	}
+
+	undo := func() {
+		for file, data := range originals {
+			_ = fsutil.WriteFileAtomic(file, data, modes[file])
+		}
+		_ = s.encryption.RestoreEncryptionConfig(originalConfig)
+	}
+	defer func() {
+		if err != nil {
+			undo()
+		}
+	}()
+
+	if err := s.encryption.SetRecipient(settings.Recipient, settings.IdentityFile()); err != nil {
+		return nil, fmt.Errorf("set recipient: %w", err)
+	}
+
+	for i, file := range files {
+		ciphertext, err := s.encryption.Encrypt(ctx, plaintexts[i])
+		if err != nil {
+			return nil, fmt.Errorf("re-encrypt %s: %w", s.relPath(file), err)
+		}
+		if err := fsutil.WriteFileAtomic(file, ciphertext, modes[file]); err != nil {
+			return nil, fmt.Errorf("write %s: %w", s.relPath(file), err)
+		}
+	}
+
+	for i, file := range files {
+		decrypted, err := s.encryption.Decrypt(ctx, file)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decrypt re-encrypted %s with the new key (nothing was changed): %w", s.relPath(file), err)
+		}
+		if !bytes.Equal(decrypted, plaintexts[i]) {
+			return nil, fmt.Errorf("re-encrypted %s does not decrypt to its original content (nothing was changed)", s.relPath(file))
+		}
+	}
+
+	return undo, nil
+}
+
+// relPath returns path relative to the ZERB directory, for display and staging.
+func (s *ConfigRekeyService) relPath(path string) string {
+	if rel, err := filepath.Rel(s.zerbDir, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+// generateCommitMessage creates the commit subject line.
+func (s *ConfigRekeyService) generateCommitMessage(paths []string) string {
+	if len(paths) == 1 {
+		return fmt.Sprintf("Re-encrypt %s to new recipient", paths[0])
+	}
+	return fmt.Sprintf("Re-encrypt %d secret configs to new recipient", len(paths))
+}
+
+// generateCommitBody creates the commit body with details.
+func (s *ConfigRekeyService) generateCommitBody(paths []string) string {
+	if len(paths) == 1 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Re-encrypted configurations:\n")
+	for _, path := range paths {
+		sb.WriteString("- ")
+		sb.WriteString(path)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,437 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+)
+
+// fakeEncryption encrypts by prefixing "enc(<recipient>):", so tests can
+// see which key a file is encrypted to.
+type fakeEncryption struct {
+	recipient    string
+	identity     string
+	badIdentity  string              // Decrypt fails while this identity is set
+	secretFiles  map[string][]string // target -> encrypted source files
+	failEncrypts int                 // fail Encrypt after this many calls (0: never)
+	encrypted    [][]byte
+	recipients   []string // every SetRecipient call
+	restores     int      // RestoreEncryptionConfig calls
+}
+
+func (f *fakeEncryption) SecretFiles(ctx context.Context, target string) ([]string, error) {
+	return f.secretFiles[target], nil
+}
+
+func (f *fakeEncryption) Decrypt(ctx context.Context, sourceFile string) ([]byte, error) {
+	data, err := os.ReadFile(sourceFile)
+	if err != nil {
+		return nil, err
+	}
+	if f.badIdentity != "" && f.identity == f.badIdentity {
+		return nil, fmt.Errorf("identity %s does not match", f.identity)
+	}
+	plaintext, ok := strings.CutPrefix(string(data), "enc("+f.recipient+"):")
+	if !ok {
+		return nil, fmt.Errorf("no secret key for %s", filepath.Base(sourceFile))
+	}
+	return []byte(plaintext), nil
+}
+
+func (f *fakeEncryption) Encrypt(ctx context.Context, plaintext []byte) ([]byte, error) {
+	if f.failEncrypts > 0 && len(f.encrypted) == f.failEncrypts {
+		return nil, errors.New("encryption failed")
+	}
+	f.encrypted = append(f.encrypted, plaintext)
+	return []byte("enc(" + f.recipient + "):" + string(plaintext)), nil
+}
+
+func (f *fakeEncryption) Recipient() (string, error) { return f.recipient, nil }
+
+func (f *fakeEncryption) SetRecipient(recipient, identity string) error {
+	f.recipient = recipient
+	f.identity = identity
+	f.recipients = append(f.recipients, recipient)
+	return nil
+}
+
+// The fake's config file holds only the recipient, and does not exist
+// while none is set
+func (f *fakeEncryption) EncryptionConfig() ([]byte, error) {
+	if f.recipient == "" {
+		return nil, nil
+	}
+	return []byte(f.recipient), nil
+}
+
+func (f *fakeEncryption) RestoreEncryptionConfig(content []byte) error {
+	f.recipient = string(content)
+	f.restores++
+	return nil
+}
+
+// setupSecretConfigs tracks ~/.ssh/config and ~/.gnupg (recursive) as
+// secrets and ~/.zshrc as a plain config, with their source files
+// encrypted to "old-key". Returns the encryption fake and the source files.
+func setupSecretConfigs(t *testing.T, zerbDir string) (*fakeEncryption, []string) {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, dir := range []string{".ssh", ".gnupg"} {
+		if err := os.MkdirAll(filepath.Join(home, dir), 0700); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+	}
+	for _, name := range []string{".ssh/config", ".zshrc"} {
+		if err := os.WriteFile(filepath.Join(home, name), []byte("content"), 0600); err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+	}
+
+	sourceDir := filepath.Join(zerbDir, "chezmoi", "source")
+	sshSource := filepath.Join(sourceDir, "private_dot_ssh", "encrypted_private_config.asc")
+	gnupgSources := []string{
+		filepath.Join(sourceDir, "private_dot_gnupg", "encrypted_gpg.conf.asc"),
+		filepath.Join(sourceDir, "private_dot_gnupg", "encrypted_sshcontrol.asc"),
+	}
+	for _, file := range append([]string{sshSource}, gnupgSources...) {
+		if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+			t.Fatalf("failed to create source dir: %v", err)
+		}
+		if err := os.WriteFile(file, []byte("enc(old-key):secret "+filepath.Base(file)), 0600); err != nil {
+			t.Fatalf("failed to write source file: %v", err)
+		}
+	}
+
+	addSvc := newTestAddService(zerbDir, &mockChezmoi{})
+	_, err := addSvc.Execute(context.Background(), AddRequest{
+		Paths: []string{filepath.Join(home, ".ssh/config"), filepath.Join(home, ".gnupg"), filepath.Join(home, ".zshrc")},
+		Options: map[string]ConfigOptions{
+			filepath.Join(home, ".ssh/config"): {Secrets: true},
+			filepath.Join(home, ".gnupg"):      {Secrets: true, Recursive: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to track configs: %v", err)
+	}
+
+	enc := &fakeEncryption{
+		recipient: "old-key",
+		secretFiles: map[string][]string{
+			filepath.Join(home, ".ssh/config"): {sshSource},
+			filepath.Join(home, ".gnupg"):      gnupgSources,
+		},
+	}
+	return enc, append([]string{sshSource}, gnupgSources...)
+}
+
+func newTestRekeyService(zerbDir string, enc *fakeEncryption) *ConfigRekeyService {
+	return NewConfigRekeyService(enc, git.NewClient(zerbDir), config.NewParser(nil), config.NewGenerator(), zerbDir)
+}
+
+func TestConfigRekeyService_Execute(t *testing.T) {
+	zerbDir := setupAddTestRepo(t)
+	enc, files := setupSecretConfigs(t, zerbDir)
+
+	result, err := newTestRekeyService(zerbDir, enc).Execute(context.Background(), RekeyRequest{Recipient: "new-key"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	// Each secret file is re-encrypted once, to the new recipient
+	if len(enc.encrypted) != len(files) {
+		t.Errorf("Encrypt called %d times, want %d", len(enc.encrypted), len(files))
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", file, err)
+		}
+		if want := "enc(new-key):secret " + filepath.Base(file); string(data) != want {
+			t.Errorf("%s = %q, want %q", filepath.Base(file), data, want)
+		}
+	}
+	if enc.recipient != "new-key" {
+		t.Errorf("recipient = %q, want new-key", enc.recipient)
+	}
+
+	if result.OldRecipient != "old-key" || len(result.Configs) != 2 || len(result.Files) != 3 {
+		t.Errorf("result = %+v, want 2 configs and 3 files re-encrypted from old-key", result)
+	}
+	if result.CommitHash == "" {
+		t.Error("CommitHash is empty, want commit hash")
+	}
+	if subject := gitOutput(t, zerbDir, "log", "-1", "--format=%s"); subject != "Re-encrypt 2 secret configs to new recipient" {
+		t.Errorf("commit subject = %q", subject)
+	}
+	if staged := gitOutput(t, zerbDir, "status", "--porcelain", "chezmoi", "configs", ".zerb-active"); staged != "" {
+		t.Errorf("changes left uncommitted:\n%s", staged)
+	}
+
+	// The new recipient is committed in the active config
+	if got := activeEncryption(t, zerbDir); got != (config.EncryptionConfig{Recipient: "new-key"}) {
+		t.Errorf("active config encryption = %+v, want recipient new-key", got)
+	}
+}
+
+// activeEncryption returns the encryption settings of the active config.
+func activeEncryption(t *testing.T, zerbDir string) config.EncryptionConfig {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(zerbDir, "zerb.active.lua"))
+	if err != nil {
+		t.Fatalf("failed to read active config: %v", err)
+	}
+	cfg, err := config.NewParser(nil).ParseString(context.Background(), string(data))
+	if err != nil {
+		t.Fatalf("failed to parse active config: %v", err)
+	}
+	return cfg.Encryption
+}
+
+func TestConfigRekeyService_Execute_Age(t *testing.T) {
+	zerbDir := setupAddTestRepo(t)
+	enc, _ := setupSecretConfigs(t, zerbDir)
+	svc := newTestRekeyService(zerbDir, enc)
+
+	// An age recipient gets the default identity
+	if _, err := svc.Execute(context.Background(), RekeyRequest{Recipient: "age1first"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	want := config.EncryptionConfig{Recipient: "age1first", Identity: config.DefaultAgeIdentity}
+	if got := activeEncryption(t, zerbDir); got != want {
+		t.Errorf("active config encryption = %+v, want %+v", got, want)
+	}
+	if enc.identity != config.DefaultAgeIdentity {
+		t.Errorf("identity = %q, want %q", enc.identity, config.DefaultAgeIdentity)
+	}
+
+	// A later rekey keeps the configured identity
+	if _, err := svc.Execute(context.Background(), RekeyRequest{Recipient: "age1second", Identity: "~/work.txt"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if _, err := svc.Execute(context.Background(), RekeyRequest{Recipient: "age1third"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	want = config.EncryptionConfig{Recipient: "age1third", Identity: "~/work.txt"}
+	if got := activeEncryption(t, zerbDir); got != want {
+		t.Errorf("active config encryption = %+v, want %+v", got, want)
+	}
+}
+
+// TestConfigRekeyService_Execute_FreshClone checks the committed recipient
+// is used when the local encryption config has not been written yet.
+func TestConfigRekeyService_Execute_FreshClone(t *testing.T) {
+	zerbDir := setupAddTestRepo(t)
+	enc, _ := setupSecretConfigs(t, zerbDir)
+	svc := newTestRekeyService(zerbDir, enc)
+	if _, err := svc.Execute(context.Background(), RekeyRequest{Recipient: "new-key"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	// A clone has no local encryption config
+	enc.recipient = ""
+	result, err := svc.Execute(context.Background(), RekeyRequest{Recipient: "newer-key"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.OldRecipient != "new-key" {
+		t.Errorf("OldRecipient = %q, want the committed new-key", result.OldRecipient)
+	}
+	if want := []string{"new-key", "new-key", "newer-key"}; !slices.Equal(enc.recipients, want) {
+		t.Errorf("SetRecipient calls = %v, want %v", enc.recipients, want)
+	}
+}
+
+func TestConfigRekeyService_Execute_Failures(t *testing.T) {
+	tests := []struct {
+		name      string
+		recipient string
+		setup     func(t *testing.T, enc *fakeEncryption, files []string)
+		wantErr   string
+		wantSets  int    // SetRecipient calls made before giving up
+		wantOld   string // recipient configured before and after
+	}{
+		{
+			name:      "undecryptable file",
+			recipient: "new-key",
+			setup: func(t *testing.T, enc *fakeEncryption, files []string) {
+				if err := os.WriteFile(files[1], []byte("enc(lost-key):secret"), 0600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr:  "nothing was changed",
+			wantSets: 0,
+			wantOld:  "old-key",
+		},
+		{
+			name:      "encryption fails part way",
+			recipient: "new-key",
+			setup: func(t *testing.T, enc *fakeEncryption, files []string) {
+				enc.failEncrypts = 2
+			},
+			wantErr:  "encryption failed",
+			wantSets: 1, // switched to new-key, then the config is restored
+			wantOld:  "old-key",
+		},
+		{
+			name:      "encryption fails without a configured recipient",
+			recipient: "new-key",
+			setup: func(t *testing.T, enc *fakeEncryption, files []string) {
+				// Encrypted before a recipient was set in the config
+				enc.recipient = ""
+				for _, file := range files {
+					if err := os.WriteFile(file, []byte("enc():secret "+filepath.Base(file)), 0600); err != nil {
+						t.Fatal(err)
+					}
+				}
+				enc.failEncrypts = 2
+			},
+			wantErr:  "encryption failed",
+			wantSets: 1,
+			wantOld:  "",
+		},
+		{
+			name:      "new key does not decrypt",
+			recipient: "age1newkey",
+			setup: func(t *testing.T, enc *fakeEncryption, files []string) {
+				enc.badIdentity = config.DefaultAgeIdentity
+			},
+			wantErr:  "cannot decrypt re-encrypted",
+			wantSets: 1,
+			wantOld:  "old-key",
+		},
+		{
+			name:      "same recipient",
+			recipient: "old-key",
+			wantErr:   ErrSameRecipient.Error(),
+			wantSets:  0,
+			wantOld:   "old-key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zerbDir := setupAddTestRepo(t)
+			enc, files := setupSecretConfigs(t, zerbDir)
+			if tt.setup != nil {
+				tt.setup(t, enc, files)
+			}
+
+			before := make(map[string]string, len(files))
+			for _, file := range files {
+				data, _ := os.ReadFile(file)
+				before[file] = string(data)
+			}
+			head := gitOutput(t, zerbDir, "rev-parse", "HEAD")
+
+			_, err := newTestRekeyService(zerbDir, enc).Execute(context.Background(), RekeyRequest{Recipient: tt.recipient})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Execute() error = %v, want containing %q", err, tt.wantErr)
+			}
+
+			if len(enc.recipients) != tt.wantSets {
+				t.Errorf("SetRecipient calls = %v, want %d", enc.recipients, tt.wantSets)
+			}
+			if enc.recipient != tt.wantOld {
+				t.Errorf("recipient = %q, want %q", enc.recipient, tt.wantOld)
+			}
+			if wantRestores := min(tt.wantSets, 1); enc.restores != wantRestores {
+				t.Errorf("RestoreEncryptionConfig calls = %d, want %d", enc.restores, wantRestores)
+			}
+			for _, file := range files {
+				if data, _ := os.ReadFile(file); string(data) != before[file] {
+					t.Errorf("%s changed to %q", filepath.Base(file), data)
+				}
+			}
+			if got := gitOutput(t, zerbDir, "rev-parse", "HEAD"); got != head {
+				t.Error("a commit was made")
+			}
+			if got := activeEncryption(t, zerbDir); !got.IsZero() {
+				t.Errorf("active config encryption = %+v, want none", got)
+			}
+		})
+	}
+}
+
+// failingStageGit is a git client that fails to stage several paths at once
+type failingStageGit struct {
+	git.Git
+}
+
+func (g failingStageGit) Stage(ctx context.Context, paths ...string) error {
+	if len(paths) > 1 {
+		return errors.New("stage failed")
+	}
+	return g.Git.Stage(ctx, paths...)
+}
+
+func TestConfigRekeyService_Execute_GitFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		wrap    func(git.Git) git.Git
+		wantErr string
+	}{
+		{
+			name:    "stage fails",
+			wrap:    func(g git.Git) git.Git { return failingStageGit{g} },
+			wantErr: "stage files",
+		},
+		{
+			name:    "commit fails",
+			wrap:    func(g git.Git) git.Git { return failingCommitGit{g} },
+			wantErr: "create commit",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zerbDir := setupAddTestRepo(t)
+			enc, files := setupSecretConfigs(t, zerbDir)
+
+			before := make(map[string]string, len(files))
+			for _, file := range files {
+				data, _ := os.ReadFile(file)
+				before[file] = string(data)
+			}
+			marker, _ := activeSnapshot(t, zerbDir)
+			head := gitOutput(t, zerbDir, "rev-parse", "HEAD")
+			status := gitOutput(t, zerbDir, "status", "--porcelain")
+
+			svc := NewConfigRekeyService(enc, tt.wrap(git.NewClient(zerbDir)), config.NewParser(nil), config.NewGenerator(), zerbDir)
+			_, err := svc.Execute(context.Background(), RekeyRequest{Recipient: "new-key"})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Execute() error = %v, want containing %q", err, tt.wantErr)
+			}
+
+			if enc.recipient != "old-key" {
+				t.Errorf("recipient = %q, want old-key", enc.recipient)
+			}
+			for _, file := range files {
+				if data, _ := os.ReadFile(file); string(data) != before[file] {
+					t.Errorf("%s changed to %q", filepath.Base(file), data)
+				}
+			}
+			if got, link := activeSnapshot(t, zerbDir); got != marker || link != filepath.Join("configs", marker) {
+				t.Errorf("active config = %s -> %s, want %s", got, link, marker)
+			}
+			if got := activeEncryption(t, zerbDir); !got.IsZero() {
+				t.Errorf("active config encryption = %+v, want none", got)
+			}
+			if got := gitOutput(t, zerbDir, "rev-parse", "HEAD"); got != head {
+				t.Error("a commit was made")
+			}
+			if got := gitOutput(t, zerbDir, "status", "--porcelain"); got != status {
+				t.Errorf("working tree or index = %q, want %q", got, status)
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/chezmoi"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/fsutil"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/transaction"
+)
+
+// ConfigRemoveService orchestrates untracking config files.
+// Files are removed from tracking only; the files on disk are never touched.
+type ConfigRemoveService struct {
+	chezmoi   chezmoi.Chezmoi
+	git       git.Git
+	parser    ConfigParser
+	generator ConfigGenerator
+	clock     Clock
+	zerbDir   string
+
+	hostBranch string
+}
+
+// NewConfigRemoveService creates a new config remove service with dependency injection.
+func NewConfigRemoveService(
+	chezmoiClient chezmoi.Chezmoi,
+	gitClient git.Git,
+	parser ConfigParser,
+	generator ConfigGenerator,
+	clock Clock,
+	zerbDir string,
+) *ConfigRemoveService {
+	return &ConfigRemoveService{
+		chezmoi:   chezmoiClient,
+		git:       gitClient,
+		parser:    parser,
+		generator: generator,
+		clock:     clock,
+		zerbDir:   zerbDir,
+	}
+}
+
+// WithHostBranch sets the branch snapshot commits go to when the config
+// enables per-host branches, instead of deriving it from the machine.
+func (s *ConfigRemoveService) WithHostBranch(name string) *ConfigRemoveService {
+	s.hostBranch = name
+	return s
+}
+
+// RemoveRequest contains the parameters for untracking config files.
+type RemoveRequest struct {
+	Paths    []string
+	All      bool // Untrack every config in the active config (Paths is ignored)
+	DryRun   bool
+	NoCommit bool // Stage changes but leave the git commit to the user
+}
+
+// RemoveResult contains the results of the remove operation.
+type RemoveResult struct {
+	RemovedPaths    []string
+	NotTrackedPaths []string // Requested paths that are not tracked
+	CommitHash      string
+	ConfigVersion   string
+	Uncommitted     bool // Changes are staged but not committed (NoCommit)
+}
+
+// Execute performs the config remove operation as a single batch: every
+// selected path is forgotten, then one new snapshot and one commit are created.
+// If any path cannot be forgotten, none is.
+func (s *ConfigRemoveService) Execute(ctx context.Context, req RemoveRequest) (*RemoveResult, error) {
+	result := &RemoveResult{}
+
+	// 1. Acquire transaction lock
+	txnDir := filepath.Join(s.zerbDir, ".txn")
+	lock, err := transaction.AcquireLock(txnDir)
+	if err != nil {
+		return nil, fmt.Errorf("acquire transaction lock: %w", err)
+	}
+	defer func() { _ = lock.Release() }()
+
+	// 2. Read current config
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("operation cancelled: %w", err)
+	}
+
+	activeConfigPath := filepath.Join(s.zerbDir, "zerb.active.lua")
+	cfgData, err := os.ReadFile(activeConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("read active config: %w", err)
+	}
+
+	currentConfig, err := s.parser.ParseString(ctx, string(cfgData))
+	if err != nil {
+		return nil, fmt.Errorf("parse current config: %w", err)
+	}
+
+	// 3. Select entries to remove
+	remaining, removed, notTracked, err := selectConfigsForRemoval(currentConfig.Configs, req)
+	if err != nil {
+		return nil, err
+	}
+	result.NotTrackedPaths = notTracked
+	for _, cfg := range removed {
+		result.RemovedPaths = append(result.RemovedPaths, cfg.Path)
+	}
+
+	if len(removed) == 0 || req.DryRun {
+		return result, nil
+	}
+
+	// 4. Forget each path (source state only, never the file on disk). If a
+	// path cannot be forgotten or the new snapshot cannot be activated, the
+	// source entries already forgotten are put back so the source state
+	// keeps matching the active config.
+	sourceDir := filepath.Join(s.zerbDir, "chezmoi", "source")
+	recorded, err := recordSource(sourceDir)
+	if err != nil {
+		return nil, err
+	}
+	activated := false
+	defer func() {
+		if !activated {
+			restoreSource(recorded)
+		}
+	}()
+
+	for _, cfg := range removed {
+		// Pass an absolute path; "~" is not expanded by the config manager.
+		// The source state is named after where the config is applied.
+		target, err := config.NormalizeConfigPath(cfg.TargetPath())
+		if err != nil {
+			target = cfg.TargetPath()
+		}
+		if err := s.chezmoi.Forget(ctx, target); err != nil {
+			return nil, fmt.Errorf("failed to untrack %q: %w", cfg.Path, err)
+		}
+	}
+
+	// 5. Generate and activate the new snapshot
+	currentConfig.Configs = remaining
+	newConfigFilename, newConfigContent, err := s.generator.GenerateTimestamped(ctx, currentConfig, "")
+	if err != nil {
+		return nil, fmt.Errorf("generate config: %w", err)
+	}
+
+	if err := writeConfigSnapshot(s.zerbDir, newConfigFilename, newConfigContent); err != nil {
+		return nil, err
+	}
+	result.ConfigVersion = newConfigFilename
+
+	if err := activateConfigSnapshot(s.zerbDir, newConfigFilename, newConfigContent); err != nil {
+		return nil, err
+	}
+	activated = true
+
+	// 6. Stage and commit
+	if err := checkoutSnapshotBranch(ctx, s.git, currentConfig, s.hostBranch); err != nil {
+		return nil, err
+	}
+	if err := s.git.Stage(ctx, snapshotStagePaths(newConfigFilename)...); err != nil {
+		return nil, fmt.Errorf("stage files: %w", err)
+	}
+
+	if req.NoCommit {
+		result.Uncommitted = true
+		return result, nil
+	}
+
+	if err := s.git.Commit(ctx, s.generateCommitMessage(result.RemovedPaths), s.generateCommitBody(result.RemovedPaths)); err != nil {
+		return nil, fmt.Errorf("create commit: %w", err)
+	}
+
+	commitHash, err := s.git.GetHeadCommit(ctx)
+	if err == nil {
+		result.CommitHash = commitHash
+	}
+
+	return result, nil
+}
+
+// sourceFile is a file or directory of the source state, recorded so that
+// entries removed by Forget can be put back
+type sourceFile struct {
+	data []byte
+	mode os.FileMode
+}
+
+// recordSource returns the files and directories below dir by path. A
+// missing dir records nothing.
+func recordSource(dir string) (map[string]sourceFile, error) {
+	recorded := make(map[string]sourceFile)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			recorded[path] = sourceFile{mode: info.Mode()}
+		case info.Mode().IsRegular():
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			recorded[path] = sourceFile{data: data, mode: info.Mode()}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("record source state: %w", err)
+	}
+	return recorded, nil
+}
+
+// restoreSource recreates the recorded files and directories that no
+// longer exist. Errors are ignored, as the caller's own error is returned.
+func restoreSource(recorded map[string]sourceFile) {
+	paths := make([]string, 0, len(recorded))
+	for path := range recorded {
+		paths = append(paths, path)
+	}
+	// Parents sort before their children
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if _, err := os.Lstat(path); !os.IsNotExist(err) {
+			continue
+		}
+		file := recorded[path]
+		if file.mode.IsDir() {
+			_ = os.Mkdir(path, file.mode.Perm())
+			continue
+		}
+		_ = fsutil.WriteFileAtomic(path, file.data, file.mode.Perm())
+	}
+}
+
+// selectConfigsForRemoval splits configs into the entries to keep and the
+// entries to remove. Paths are matched after normalization so that "~/.zshrc"
+// and "$HOME/.zshrc" refer to the same entry. An entry with a target override
+// matches both its path and its target.
+func selectConfigsForRemoval(configs []config.ConfigFile, req RemoveRequest) (remaining, removed []config.ConfigFile, notTracked []string, err error) {
+	if req.All {
+		return []config.ConfigFile{}, configs, nil, nil
+	}
+
+	wanted := make(map[string]string, len(req.Paths)) // normalized -> original
+	for _, path := range req.Paths {
+		normalized, err := config.NormalizeConfigPath(path)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid path %q: %w", path, err)
+		}
+		wanted[normalized] = path
+	}
+
+	matched := make(map[string]bool, len(wanted))
+	remaining = []config.ConfigFile{}
+	for _, cfg := range configs {
+		if key, ok := matchConfig(cfg, wanted); ok {
+			removed = append(removed, cfg)
+			matched[key] = true
+			continue
+		}
+		remaining = append(remaining, cfg)
+	}
+
+	for _, path := range req.Paths {
+		normalized, _ := config.NormalizeConfigPath(path)
+		if !matched[normalized] {
+			notTracked = append(notTracked, path)
+		}
+	}
+
+	return remaining, removed, notTracked, nil
+}
+
+// matchConfig returns the normalized path or target of cfg found in wanted.
+func matchConfig(cfg config.ConfigFile, wanted map[string]string) (string, bool) {
+	candidates := []string{cfg.Path}
+	if cfg.Target != "" {
+		candidates = append(candidates, cfg.Target)
+	}
+	for _, path := range candidates {
+		normalized, err := config.NormalizeConfigPath(path)
+		if err != nil {
+			continue
+		}
+		if _, ok := wanted[normalized]; ok {
+			return normalized, true
+		}
+	}
+	return "", false
+}
+
+// generateCommitMessage creates the commit subject line.
+func (s *ConfigRemoveService) generateCommitMessage(paths []string) string {
+	if len(paths) == 1 {
+		return fmt.Sprintf("Remove %s from tracked configs", paths[0])
+	}
+	return fmt.Sprintf("Remove %d configs from tracked configs", len(paths))
+}
+
+// generateCommitBody creates the commit body with details.
+func (s *ConfigRemoveService) generateCommitBody(paths []string) string {
+	if len(paths) == 1 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Removed configurations:\n")
+	for _, path := range paths {
+		sb.WriteString("- ")
+		sb.WriteString(path)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/chezmoi"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+)
+
+// newTestRemoveService builds a ConfigRemoveService backed by a real git
+// client and parser, with chezmoi replaced by a stub that deletes source files.
+func newTestRemoveService(zerbDir string, cm *mockChezmoi) *ConfigRemoveService {
+	if cm.forgetFunc == nil {
+		cm.forgetFunc = func(ctx context.Context, path string) error {
+			name := "dot_" + strings.TrimPrefix(filepath.Base(path), ".")
+			return os.Remove(filepath.Join(zerbDir, "chezmoi", "source", name))
+		}
+	}
+	return NewConfigRemoveService(
+		cm,
+		git.NewClient(zerbDir),
+		config.NewParser(nil),
+		config.NewGenerator(),
+		TestClock{},
+		zerbDir,
+	)
+}
+
+// setupTrackedFiles creates files in a temporary home directory and tracks
+// them through the add service. Returns the absolute paths of the files.
+func setupTrackedFiles(t *testing.T, zerbDir string, names ...string) []string {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	paths := make([]string, 0, len(names))
+	for _, name := range names {
+		path := filepath.Join(home, name)
+		if err := os.WriteFile(path, []byte("user content for "+name), 0644); err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+		paths = append(paths, path)
+	}
+
+	addSvc := newTestAddService(zerbDir, &mockChezmoi{})
+	if _, err := addSvc.Execute(context.Background(), AddRequest{Paths: paths}); err != nil {
+		t.Fatalf("failed to track files: %v", err)
+	}
+
+	return paths
+}
+
+// readActiveConfig parses the config the active marker points at.
+func readActiveConfig(t *testing.T, zerbDir string) *config.Config {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join(zerbDir, "zerb.active.lua"))
+	if err != nil {
+		t.Fatalf("failed to read active config: %v", err)
+	}
+	cfg, err := config.NewParser(nil).ParseString(context.Background(), string(content))
+	if err != nil {
+		t.Fatalf("failed to parse active config: %v", err)
+	}
+	return cfg
+}
+
+func TestConfigRemoveService_Execute_All(t *testing.T) {
+	zerbDir := setupAddTestRepo(t)
+	paths := setupTrackedFiles(t, zerbDir, ".zshrc", ".bashrc", ".vimrc")
+
+	cm := &mockChezmoi{}
+	svc := newTestRemoveService(zerbDir, cm)
+
+	result, err := svc.Execute(context.Background(), RemoveRequest{All: true})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	// Every tracked path should have been forgotten
+	if len(cm.forgotten) != len(paths) {
+		t.Fatalf("forgot %d paths, want %d: %v", len(cm.forgotten), len(paths), cm.forgotten)
+	}
+	forgotten := make(map[string]bool, len(cm.forgotten))
+	for _, path := range cm.forgotten {
+		forgotten[path] = true
+	}
+	for _, path := range paths {
+		if !forgotten[path] {
+			t.Errorf("%s was not forgotten, forgotten: %v", path, cm.forgotten)
+		}
+	}
+	if len(result.RemovedPaths) != len(paths) {
+		t.Errorf("RemovedPaths = %v, want %d entries", result.RemovedPaths, len(paths))
+	}
+
+	// New snapshot should have zero configs
+	if cfg := readActiveConfig(t, zerbDir); len(cfg.Configs) != 0 {
+		t.Errorf("active config has %d configs, want 0", len(cfg.Configs))
+	}
+
+	// Files on disk must be untouched
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("file %s was removed from disk: %v", path, err)
+			continue
+		}
+		if !strings.HasPrefix(string(content), "user content for ") {
+			t.Errorf("file %s was modified: %q", path, content)
+		}
+	}
+
+	// Change should be committed
+	if result.CommitHash == "" {
+		t.Error("CommitHash is empty, want commit hash")
+	}
+	if staged := gitOutput(t, zerbDir, "diff", "--cached", "--name-only"); staged != "" {
+		t.Errorf("changes left staged after commit:\n%s", staged)
+	}
+}
+
+func TestConfigRemoveService_Execute_Paths(t *testing.T) {
+	zerbDir := setupAddTestRepo(t)
+	paths := setupTrackedFiles(t, zerbDir, ".zshrc", ".bashrc")
+
+	cm := &mockChezmoi{}
+	svc := newTestRemoveService(zerbDir, cm)
+
+	missing := filepath.Join(filepath.Dir(paths[0]), ".not-tracked")
+	result, err := svc.Execute(context.Background(), RemoveRequest{Paths: []string{paths[0], missing}})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if len(result.RemovedPaths) != 1 || result.RemovedPaths[0] != paths[0] {
+		t.Errorf("RemovedPaths = %v, want [%s]", result.RemovedPaths, paths[0])
+	}
+	if len(result.NotTrackedPaths) != 1 || result.NotTrackedPaths[0] != missing {
+		t.Errorf("NotTrackedPaths = %v, want [%s]", result.NotTrackedPaths, missing)
+	}
+
+	cfg := readActiveConfig(t, zerbDir)
+	if len(cfg.Configs) != 1 || cfg.Configs[0].Path != paths[1] {
+		t.Errorf("active config = %+v, want only %s", cfg.Configs, paths[1])
+	}
+}
+
+func TestConfigRemoveService_Execute_DryRun(t *testing.T) {
+	zerbDir := setupAddTestRepo(t)
+	setupTrackedFiles(t, zerbDir, ".zshrc")
+
+	cm := &mockChezmoi{}
+	svc := newTestRemoveService(zerbDir, cm)
+	headBefore := gitOutput(t, zerbDir, "rev-parse", "HEAD")
+
+	result, err := svc.Execute(context.Background(), RemoveRequest{All: true, DryRun: true})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if len(result.RemovedPaths) != 1 {
+		t.Errorf("RemovedPaths = %v, want 1 entry", result.RemovedPaths)
+	}
+	if len(cm.forgotten) != 0 {
+		t.Errorf("dry run forgot paths: %v", cm.forgotten)
+	}
+	if headAfter := gitOutput(t, zerbDir, "rev-parse", "HEAD"); headAfter != headBefore {
+		t.Error("dry run created a commit")
+	}
+}
+
+func TestConfigRemoveService_Execute_ForgetError(t *testing.T) {
+	zerbDir := setupAddTestRepo(t)
+	setupTrackedFiles(t, zerbDir, ".zshrc")
+
+	cm := &mockChezmoi{
+		forgetFunc: func(ctx context.Context, path string) error {
+			return chezmoi.ErrForgetFailed
+		},
+	}
+	svc := newTestRemoveService(zerbDir, cm)
+
+	_, err := svc.Execute(context.Background(), RemoveRequest{All: true})
+	if err == nil {
+		t.Fatal("Execute() error = nil, want error")
+	}
+
+	// Active config must be unchanged
+	if cfg := readActiveConfig(t, zerbDir); len(cfg.Configs) != 1 {
+		t.Errorf("active config has %d configs after failure, want 1", len(cfg.Configs))
+	}
+}
+
+func TestConfigRemoveService_Execute_ForgetErrorMidBatch(t *testing.T) {
+	zerbDir := setupAddTestRepo(t)
+	setupTrackedFiles(t, zerbDir, ".zshrc", ".bashrc")
+	sources := []string{
+		filepath.Join(zerbDir, "chezmoi", "source", "dot_zshrc"),
+		filepath.Join(zerbDir, "chezmoi", "source", "dot_bashrc"),
+	}
+
+	// The first path is forgotten, the second fails
+	cm := &mockChezmoi{}
+	cm.forgetFunc = func(ctx context.Context, path string) error {
+		if len(cm.forgotten) > 1 {
+			return chezmoi.ErrForgetFailed
+		}
+		name := "dot_" + strings.TrimPrefix(filepath.Base(path), ".")
+		return os.Remove(filepath.Join(zerbDir, "chezmoi", "source", name))
+	}
+	svc := newTestRemoveService(zerbDir, cm)
+
+	if _, err := svc.Execute(context.Background(), RemoveRequest{All: true}); err == nil {
+		t.Fatal("Execute() error = nil, want error")
+	}
+	if len(cm.forgotten) != 2 {
+		t.Fatalf("forgot %v, want both paths attempted", cm.forgotten)
+	}
+
+	// The path already forgotten is tracked again, like the config says
+	if cfg := readActiveConfig(t, zerbDir); len(cfg.Configs) != 2 {
+		t.Errorf("active config has %d configs after failure, want 2", len(cfg.Configs))
+	}
+	for _, source := range sources {
+		content, err := os.ReadFile(source)
+		if err != nil {
+			t.Errorf("source entry %s not restored: %v", filepath.Base(source), err)
+			continue
+		}
+		if string(content) != "content" {
+			t.Errorf("source entry %s = %q, want %q", filepath.Base(source), content, "content")
+		}
+	}
+	if status := gitOutput(t, zerbDir, "status", "--porcelain"); status != "" {
+		t.Errorf("working tree changed after failure:\n%s", status)
+	}
+}
+
+func TestConfigRemoveService_Execute_Target(t *testing.T) {
+	zerbDir := setupAddTestRepo(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	path := filepath.Join(home, "dotfiles", "zshrc")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("user content"), 0644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	addCM := &mockChezmoi{}
+	var gotTarget string
+	addCM.addFunc = func(ctx context.Context, path string, opts chezmoi.AddOptions) error {
+		gotTarget = opts.Target
+		return os.WriteFile(filepath.Join(zerbDir, "chezmoi", "source", "dot_zshrc"), []byte("content"), 0644)
+	}
+	_, err := newTestAddService(zerbDir, addCM).Execute(context.Background(), AddRequest{
+		Paths:   []string{path},
+		Options: map[string]ConfigOptions{path: {Target: "~/.zshrc"}},
+	})
+	if err != nil {
+		t.Fatalf("add Execute() error = %v", err)
+	}
+	if want := filepath.Join(home, ".zshrc"); gotTarget != want {
+		t.Errorf("chezmoi target = %q, want %q", gotTarget, want)
+	}
+	if cfg := readActiveConfig(t, zerbDir); len(cfg.Configs) != 1 || cfg.Configs[0].Target != "~/.zshrc" {
+		t.Fatalf("active config = %+v, want target ~/.zshrc", cfg.Configs)
+	}
+
+	// The entry is found by its target, and the source state is forgotten
+	// under the target
+	cm := &mockChezmoi{}
+	result, err := newTestRemoveService(zerbDir, cm).Execute(context.Background(), RemoveRequest{
+		Paths: []string{filepath.Join(home, ".zshrc")},
+	})
+	if err != nil {
+		t.Fatalf("remove Execute() error = %v", err)
+	}
+	if len(result.RemovedPaths) != 1 {
+		t.Errorf("RemovedPaths = %v, want one entry", result.RemovedPaths)
+	}
+	if want := filepath.Join(home, ".zshrc"); len(cm.forgotten) != 1 || cm.forgotten[0] != want {
+		t.Errorf("forgotten = %v, want [%s]", cm.forgotten, want)
+	}
+}
@@ -0,0 +1,565 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/binary"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/fsutil"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/layout"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/platform"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/shell"
+)
+
+// ErrAlreadyInitialized is returned by InitService.Run for a ZERB directory
+// that is already set up.
+var ErrAlreadyInitialized = errors.New("ZERB already initialized")
+
+// InitGit provides the git operations init needs.
+type InitGit interface {
+	git.Git
+	CheckOwnership(ctx context.Context) error
+}
+
+// BinaryInstaller installs the core components. *binary.Manager implements it.
+type BinaryInstaller interface {
+	EnsureKeyrings() error
+	InstallWithResult(ctx context.Context, opts binary.DownloadOptions) (*binary.DownloadResult, error)
+	InstalledVersion(b binary.Binary) (string, error)
+}
+
+// InitService orchestrates setting up a new ZERB directory.
+type InitService struct {
+	git      InitGit
+	detector platform.Detector
+	clock    Clock
+	zerbDir  string
+
+	newInstaller func(zerbDir string, platformInfo *platform.Info) (BinaryInstaller, error)
+}
+
+// NewInitService creates a new init service with dependency injection.
+func NewInitService(
+	gitClient InitGit,
+	detector platform.Detector,
+	clock Clock,
+	zerbDir string,
+) *InitService {
+	return &InitService{
+		git:          gitClient,
+		detector:     detector,
+		clock:        clock,
+		zerbDir:      zerbDir,
+		newInstaller: newBinaryInstaller,
+	}
+}
+
+// WithInstaller sets how the core components are installed once the
+// platform is known, instead of downloading them with a binary.Manager.
+func (s *InitService) WithInstaller(newInstaller func(zerbDir string, platformInfo *platform.Info) (BinaryInstaller, error)) *InitService {
+	s.newInstaller = newInstaller
+	return s
+}
+
+// newBinaryInstaller returns a binary.Manager for zerbDir
+func newBinaryInstaller(zerbDir string, platformInfo *platform.Info) (BinaryInstaller, error) {
+	manager, err := binary.NewManager(binary.Config{
+		ZerbDir:      zerbDir,
+		PlatformInfo: platformInfo,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manager, nil
+}
+
+// InitOptions contains the parameters for setting up ZERB.
+type InitOptions struct {
+	Template          string         // Validated zerb.lua to seed the first config with; empty generates one
+	AdoptExistingRepo bool           // Commit on top of a git repository with unrelated history
+	OfflineDir        string         // Install core components from release files in this directory
+	Versions          binary.Version // Core component versions; empty ones use binary.DefaultVersions
+	RegenerateConfig  bool           // Write a new initial config even if a valid one is active
+
+	// Progress, if set, is called as core components are downloaded
+	Progress func(b binary.Binary, downloaded, total int64)
+}
+
+// InitGitStatus describes what init did with the git repository.
+type InitGitStatus int
+
+const (
+	// InitGitCreated means a new repository was initialized
+	InitGitCreated InitGitStatus = iota
+	// InitGitExisting means a repository already existed and was kept
+	InitGitExisting
+	// InitGitInvalid means the existing .git is not a valid repository, so
+	// ZERB runs without versioning
+	InitGitInvalid
+	// InitGitFailed means the repository could not be initialized, so ZERB
+	// runs without versioning
+	InitGitFailed
+)
+
+// InstalledBinary describes one core component after init.
+type InstalledBinary struct {
+	Binary           binary.Binary
+	Version          string                    // Version the binary reports; empty if it could not be run
+	Verified         binary.VerificationMethod // How the download was verified
+	AlreadyInstalled bool                      // Nothing was downloaded; Verified is not set
+}
+
+// InitReport contains the results of setting up ZERB.
+type InitReport struct {
+	ZerbDir     string
+	Directories []string // Directories that did not exist before
+
+	Git           InitGitStatus
+	GitError      error             // Why git is unavailable (InitGitInvalid, InitGitFailed)
+	GitUser       *git.GitUserInfo  // Identity configured for a new repository, if any
+	InitialCommit bool              // The initial commit was created
+	Platform      *platform.Info    // Detected platform
+	Binaries      []InstalledBinary // Core components, in install order
+
+	ConfigFile    string // Active config snapshot, e.g. zerb.20250115T103000.000Z.lua
+	ConfigCreated bool   // ConfigFile was written by this run
+	FromTemplate  bool   // ConfigFile was seeded from InitOptions.Template
+	SensitiveData []config.SensitiveDataFinding
+
+	Shell          shell.ShellType
+	ShellDetection *shell.DetectionResult // nil if detection failed
+
+	// Warnings lists problems init worked around, e.g. a failed initial commit
+	Warnings []string
+}
+
+// IsInitialized reports whether ZERB is already initialized in zerbDir.
+func IsInitialized(zerbDir string) bool {
+	// Check for key indicators of an initialized ZERB environment
+	indicators := []string{
+		filepath.Join(zerbDir, "bin", "mise"),
+		filepath.Join(zerbDir, "configs"),
+		filepath.Join(zerbDir, ".zerb-active"),
+	}
+
+	// If any key indicator exists, consider it initialized
+	for _, path := range indicators {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsComplete reports whether an earlier init of the ZERB directory
+// finished: its active config is valid and committed, or git is
+// unavailable. An init interrupted before that, e.g. by a failed download,
+// is resumed by Run instead of refused.
+func (s *InitService) IsComplete(ctx context.Context) bool {
+	if !s.hasValidActiveConfig(ctx) {
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(s.zerbDir, ".zerb-no-git")); err == nil {
+		return true
+	}
+	_, err := s.git.GetHeadCommit(ctx)
+	return err == nil
+}
+
+// Run sets up ZERB: it creates the directory structure and git repository,
+// installs the core components, writes the initial config and detects the
+// user's shell. Problems that do not stop init are collected in the report.
+//
+// A completed init is refused unless opts.RegenerateConfig is set. An
+// interrupted one is resumed: installed components and a valid active
+// config are kept.
+func (s *InitService) Run(ctx context.Context, opts InitOptions) (*InitReport, error) {
+	if !opts.RegenerateConfig && s.IsComplete(ctx) {
+		return nil, fmt.Errorf("%w at %s\nThe environment is already set up", ErrAlreadyInitialized, s.zerbDir)
+	}
+
+	report := &InitReport{ZerbDir: s.zerbDir}
+
+	// 1. Refuse to commit into someone else's history before changing anything
+	if err := s.checkExistingRepo(ctx, opts.AdoptExistingRepo, report); err != nil {
+		return nil, err
+	}
+
+	// 2. Create directory structure
+	created, err := s.createDirectories()
+	if err != nil {
+		return nil, fmt.Errorf("create directories: %w", err)
+	}
+	report.Directories = created
+
+	// 3. Set up git repository
+	if err := git.WriteGitignore(filepath.Join(s.zerbDir, ".gitignore")); err != nil {
+		return nil, fmt.Errorf("write .gitignore: %w", err)
+	}
+	s.setupGit(ctx, report)
+
+	// 4. Detect platform
+	platformInfo, err := s.detector.Detect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("detect platform: %w", err)
+	}
+	report.Platform = platformInfo
+
+	// 5. Install core components
+	if err := s.installBinaries(ctx, platformInfo, opts, report); err != nil {
+		return nil, fmt.Errorf("install binaries: %w", err)
+	}
+
+	// 6. Write initial config
+	if opts.Template != "" {
+		report.ConfigCreated, err = s.seedInitialConfig(ctx, opts.Template, opts.RegenerateConfig, report)
+	} else {
+		report.ConfigCreated, err = s.generateInitialConfig(ctx, opts.RegenerateConfig, report)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("generate config: %w", err)
+	}
+	report.FromTemplate = opts.Template != ""
+	if marker, err := os.ReadFile(filepath.Join(s.zerbDir, ".zerb-active")); err == nil {
+		report.ConfigFile = strings.TrimSpace(string(marker))
+	}
+
+	// 7. Commit the config (if git is initialized). A kept config needs no
+	// commit unless an interrupted run stopped before making it.
+	if isRepo, _ := s.git.IsGitRepo(ctx); isRepo && report.ConfigFile != "" {
+		_, headErr := s.git.GetHeadCommit(ctx)
+		hasHistory := headErr == nil
+		if report.ConfigCreated || !hasHistory {
+			message := "Initialize ZERB environment"
+			if hasHistory {
+				message = "Regenerate ZERB config"
+			}
+			files := []string{".gitignore", filepath.Join("configs", report.ConfigFile)}
+			if err := s.git.CreateInitialCommit(ctx, message, files); err != nil {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("Failed to create initial commit: %v", err))
+			} else {
+				report.InitialCommit = true
+			}
+		}
+	}
+
+	// 8. Detect shell (for showing appropriate instructions)
+	report.Shell = shell.ShellUnknown
+	if detection, err := shell.DetectShell(); err == nil {
+		report.Shell = detection.Shell
+		report.ShellDetection = detection
+	}
+
+	return report, nil
+}
+
+// Reinstall installs the core components missing from an initialized ZERB
+// directory (installed ones are kept), leaving configs and history alone.
+// Only the directories, platform and binaries of the report are set.
+func (s *InitService) Reinstall(ctx context.Context, opts InitOptions) (*InitReport, error) {
+	if !IsInitialized(s.zerbDir) {
+		return nil, fmt.Errorf("%w at %s\nRun 'zerb init' to set up ZERB first", ErrNotInitialized, s.zerbDir)
+	}
+
+	report := &InitReport{ZerbDir: s.zerbDir}
+
+	platformInfo, err := s.detector.Detect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("detect platform: %w", err)
+	}
+	report.Platform = platformInfo
+
+	created, err := s.createDirectories()
+	if err != nil {
+		return nil, fmt.Errorf("create directories: %w", err)
+	}
+	report.Directories = created
+
+	if err := s.installBinaries(ctx, platformInfo, opts, report); err != nil {
+		return nil, fmt.Errorf("install binaries: %w", err)
+	}
+	return report, nil
+}
+
+// checkExistingRepo refuses a git repository in the ZERB directory that has
+// history not made by ZERB, e.g. a project ZERB_DIR was pointed at by
+// mistake, unless adopt is set. An invalid repository is left to setupGit.
+func (s *InitService) checkExistingRepo(ctx context.Context, adopt bool, report *InitReport) error {
+	if isRepo, err := s.git.IsGitRepo(ctx); err != nil || !isRepo {
+		return nil
+	}
+
+	err := s.git.CheckOwnership(ctx)
+	if !errors.Is(err, git.ErrForeignRepo) {
+		// Other errors surface when the repository is used
+		return nil
+	}
+	if adopt {
+		report.Warnings = append(report.Warnings,
+			fmt.Sprintf("%v\n  ZERB will commit its files on top of the existing history.", err))
+		return nil
+	}
+	return fmt.Errorf("%w\nZERB would commit its files into this repository's history.\n"+
+		"Set ZERB_DIR to another directory, or run 'zerb init --adopt-existing-repo' to use it anyway", err)
+}
+
+// createDirectories creates the directory layout and returns the
+// directories that did not exist before
+func (s *InitService) createDirectories() ([]string, error) {
+	var missing []string
+	for _, dir := range append([]string{""}, layout.Dirs()...) {
+		path := filepath.Join(s.zerbDir, dir)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			missing = append(missing, path)
+		}
+	}
+
+	if err := layout.Create(s.zerbDir); err != nil {
+		return nil, err
+	}
+	return missing, nil
+}
+
+// setupGit initializes the git repository and configures the ZERB git
+// identity. Git is optional: on failure ZERB continues without versioning
+// and the .zerb-no-git marker records why.
+func (s *InitService) setupGit(ctx context.Context, report *InitReport) {
+	isRepo, err := s.git.IsGitRepo(ctx)
+	switch {
+	case err != nil:
+		report.Git = InitGitInvalid
+		report.GitError = err
+		s.writeNoGitMarker("git initialization failed: invalid repository\n", report)
+		return
+	case isRepo:
+		report.Git = InitGitExisting
+		return
+	}
+
+	if err := s.git.InitRepo(ctx); err != nil {
+		report.Git = InitGitFailed
+		report.GitError = err
+		s.writeNoGitMarker("git initialization failed\n", report)
+		return
+	}
+	report.Git = InitGitCreated
+
+	userInfo := git.DetectGitUser()
+	if err := s.git.ConfigureUser(ctx, userInfo); err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("Failed to configure git user: %v", err))
+		return
+	}
+	report.GitUser = &userInfo
+}
+
+// writeNoGitMarker creates the .zerb-no-git marker with reason
+func (s *InitService) writeNoGitMarker(reason string, report *InitReport) {
+	markerPath := filepath.Join(s.zerbDir, ".zerb-no-git")
+	if err := fsutil.WriteFileAtomic(markerPath, []byte(reason), 0600); err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("Failed to create marker file: %v", err))
+	}
+}
+
+// installBinaries installs mise and chezmoi at the versions in opts and
+// records them in the report. A binary that does not report the requested
+// version afterwards is a warning, not an error.
+func (s *InitService) installBinaries(ctx context.Context, platformInfo *platform.Info, opts InitOptions, report *InitReport) error {
+	installer, err := s.newInstaller(s.zerbDir, platformInfo)
+	if err != nil {
+		return fmt.Errorf("create binary manager: %w", err)
+	}
+
+	// Extract embedded keyrings
+	if err := installer.EnsureKeyrings(); err != nil {
+		return fmt.Errorf("extract keyrings: %w", err)
+	}
+
+	versions := opts.Versions
+	if versions.Mise == "" {
+		versions.Mise = binary.DefaultVersions.Mise
+	}
+	if versions.Chezmoi == "" {
+		versions.Chezmoi = binary.DefaultVersions.Chezmoi
+	}
+
+	components := []struct {
+		binary  binary.Binary
+		version string
+	}{
+		{binary.BinaryMise, versions.Mise},
+		{binary.BinaryChezmoi, versions.Chezmoi},
+	}
+
+	for _, c := range components {
+		var progress binary.ProgressFunc
+		if opts.Progress != nil {
+			b := c.binary
+			progress = func(downloaded, total int64) { opts.Progress(b, downloaded, total) }
+		}
+
+		result, err := installer.InstallWithResult(ctx, binary.DownloadOptions{
+			Binary:   c.binary,
+			Version:  c.version,
+			Progress: progress,
+			LocalDir: opts.OfflineDir,
+		})
+		if err != nil {
+			return fmt.Errorf("install %s: %w", c.binary, err)
+		}
+
+		installed := InstalledBinary{Binary: c.binary, AlreadyInstalled: result == nil}
+		if result != nil {
+			installed.Verified = result.Verified
+		}
+		report.Binaries = append(report.Binaries, installed)
+	}
+
+	// Confirm the installed binaries report the requested versions
+	for i, c := range components {
+		want, err := binary.NormalizeVersion(c.binary, c.version)
+		if err != nil {
+			return err
+		}
+		// The error names the underlying binary, so it is not reported
+		got, err := installer.InstalledVersion(c.binary)
+		if err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("could not check the installed %s version", c.binary.Label()))
+			continue
+		}
+		report.Binaries[i].Version = got
+		if got != want {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("installed %s reports version %s, expected %s", c.binary.Label(), got, want))
+		}
+	}
+
+	return nil
+}
+
+// generateInitialConfig creates an empty initial configuration.
+// If an active config already exists and parses, it is kept and no new
+// snapshot is written, so re-running init is safe; force regenerates it
+// anyway. Returns true if a new config was created.
+func (s *InitService) generateInitialConfig(ctx context.Context, force bool, report *InitReport) (bool, error) {
+	if !force && s.hasValidActiveConfig(ctx) {
+		return false, nil
+	}
+
+	// Create initial minimal config
+	initialConfig := &config.Config{
+		Meta: config.Meta{
+			Name:        "My ZERB Environment",
+			Description: "Created by zerb init",
+		},
+		Tools:   []string{}, // Empty initially - user adds tools with 'zerb add'
+		Configs: []config.ConfigFile{},
+		Git: config.GitConfig{
+			Remote: "", // User can configure later
+			Branch: "main",
+		},
+		Options: config.Options{
+			BackupRetention: 5,
+		},
+	}
+
+	// Generate Lua code
+	generator := config.NewGenerator().WithClock(s.clock)
+	luaCode, err := generator.Generate(ctx, initialConfig)
+	if err != nil {
+		return false, fmt.Errorf("generate config: %w", err)
+	}
+
+	if err := s.writeInitialConfig(luaCode, report); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// seedInitialConfig writes a validated template as the first snapshot.
+// Like generateInitialConfig, an existing valid active config is kept
+// unless force is set. Returns true if a new config was created.
+func (s *InitService) seedInitialConfig(ctx context.Context, template string, force bool, report *InitReport) (bool, error) {
+	if !force && s.hasValidActiveConfig(ctx) {
+		return false, nil
+	}
+
+	if err := s.writeInitialConfig(template, report); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// writeInitialConfig writes luaCode as the first timestamped snapshot and
+// makes it the active config
+func (s *InitService) writeInitialConfig(luaCode string, report *InitReport) error {
+	// The empty generated config has no sensitive data, but a template
+	// might. For init this is reported, not refused.
+	report.SensitiveData = config.DetectSensitiveData(luaCode)
+
+	// Create timestamped config filename with milliseconds to ensure uniqueness
+	// Format: zerb.TIMESTAMP.lua (ending in .lua for editor syntax highlighting)
+	timestamp := s.clock.Now().UTC().Format("20060102T150405.000Z")
+	configFilename := fmt.Sprintf("zerb.%s.lua", timestamp)
+	configPath := filepath.Join(s.zerbDir, "configs", configFilename)
+
+	// Write config file (0600 for security - may contain sensitive data)
+	if err := fsutil.WriteFileAtomic(configPath, []byte(luaCode), 0600); err != nil {
+		return fmt.Errorf("write config file: %w", err)
+	}
+
+	// Create .zerb-active marker file (0600 for consistency)
+	markerPath := filepath.Join(s.zerbDir, ".zerb-active")
+	if err := fsutil.WriteFileAtomic(markerPath, []byte(configFilename), 0600); err != nil {
+		return fmt.Errorf("write marker file: %w", err)
+	}
+
+	// Create symlink to active config (idempotent: remove existing first)
+	symlinkPath := filepath.Join(s.zerbDir, "zerb.active.lua")
+	symlinkTarget := filepath.Join("configs", configFilename)
+
+	// Remove existing symlink/file if present
+	if err := os.Remove(symlinkPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove existing active symlink: %w", err)
+	}
+
+	// Create new symlink
+	if err := os.Symlink(symlinkTarget, symlinkPath); err != nil {
+		return fmt.Errorf("create symlink: %w", err)
+	}
+
+	return nil
+}
+
+// hasValidActiveConfig reports whether the .zerb-active marker names an
+// existing snapshot in configs/ that parses as a valid config, and the
+// zerb.active.lua symlink points to it
+func (s *InitService) hasValidActiveConfig(ctx context.Context) bool {
+	marker, err := os.ReadFile(filepath.Join(s.zerbDir, ".zerb-active"))
+	if err != nil {
+		return false
+	}
+
+	configFilename := strings.TrimSpace(string(marker))
+	if configFilename == "" || filepath.Base(configFilename) != configFilename {
+		return false
+	}
+
+	linkTarget, err := os.Readlink(filepath.Join(s.zerbDir, "zerb.active.lua"))
+	if err != nil || linkTarget != filepath.Join("configs", configFilename) {
+		return false
+	}
+
+	content, err := os.ReadFile(filepath.Join(s.zerbDir, "configs", configFilename))
+	if err != nil {
+		return false
+	}
+
+	_, err = config.NewParser(s.detector).ParseString(ctx, string(content))
+	return err == nil
+}
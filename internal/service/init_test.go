@@ -0,0 +1,695 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/binary"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/layout"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/platform"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/shell"
+)
+
+// fakeDetector reports a fixed platform
+type fakeDetector struct{}
+
+func (fakeDetector) Detect(ctx context.Context) (*platform.Info, error) {
+	return &platform.Info{OS: "linux", Arch: "amd64"}, nil
+}
+
+// fakeInstaller installs placeholder binaries into bin/ instead of
+// downloading them
+type fakeInstaller struct {
+	binDir   string
+	reported map[binary.Binary]string // Version each binary reports; missing fails
+	verified binary.VerificationMethod
+	err      error
+}
+
+func (f *fakeInstaller) EnsureKeyrings() error { return nil }
+
+func (f *fakeInstaller) InstallWithResult(ctx context.Context, opts binary.DownloadOptions) (*binary.DownloadResult, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	path := filepath.Join(f.binDir, opts.Binary.String())
+	if _, err := os.Stat(path); err == nil {
+		return nil, nil
+	}
+	if err := os.MkdirAll(f.binDir, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0755); err != nil {
+		return nil, err
+	}
+	if opts.Progress != nil {
+		opts.Progress(1, 1)
+	}
+	return &binary.DownloadResult{Binary: opts.Binary, Version: opts.Version, Verified: f.verified}, nil
+}
+
+func (f *fakeInstaller) InstalledVersion(b binary.Binary) (string, error) {
+	version, ok := f.reported[b]
+	if !ok {
+		return "", errors.New("cannot run " + b.String())
+	}
+	return version, nil
+}
+
+// defaultReported returns the versions installed binaries of the default
+// versions report
+func defaultReported(t *testing.T) map[binary.Binary]string {
+	t.Helper()
+	reported := map[binary.Binary]string{}
+	for b, version := range map[binary.Binary]string{
+		binary.BinaryMise:    binary.DefaultVersions.Mise,
+		binary.BinaryChezmoi: binary.DefaultVersions.Chezmoi,
+	} {
+		v, err := binary.NormalizeVersion(b, version)
+		if err != nil {
+			t.Fatalf("NormalizeVersion() error = %v", err)
+		}
+		reported[b] = v
+	}
+	return reported
+}
+
+// newTestInitService returns an init service for zerbDir with a fixed clock
+// that installs binaries with installer
+func newTestInitService(zerbDir string, installer *fakeInstaller) *InitService {
+	clk := TestClock{FixedTime: time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)}
+	return NewInitService(git.NewClient(zerbDir), fakeDetector{}, clk, zerbDir).
+		WithInstaller(func(dir string, info *platform.Info) (BinaryInstaller, error) {
+			installer.binDir = filepath.Join(dir, "bin")
+			return installer, nil
+		})
+}
+
+// setupInitTest isolates the git identity and shell detection of a test
+func setupInitTest(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SHELL", "/bin/zsh")
+	t.Setenv("ZERB_GIT_NAME", "Test User")
+	t.Setenv("ZERB_GIT_EMAIL", "test@example.com")
+}
+
+func TestInitService_Run(t *testing.T) {
+	setupInitTest(t)
+	zerbDir := filepath.Join(t.TempDir(), "zerb")
+	installer := &fakeInstaller{reported: defaultReported(t), verified: binary.VerificationCosign}
+
+	var progress []binary.Binary
+	report, err := newTestInitService(zerbDir, installer).Run(context.Background(), InitOptions{
+		Progress: func(b binary.Binary, downloaded, total int64) { progress = append(progress, b) },
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	for _, dir := range []string{zerbDir, filepath.Join(zerbDir, "configs"), filepath.Join(zerbDir, "bin")} {
+		if !slices.Contains(report.Directories, dir) {
+			t.Errorf("Directories = %v, missing %s", report.Directories, dir)
+		}
+	}
+	// Every layout directory is created user-only
+	for _, dir := range append([]string{""}, layout.Dirs()...) {
+		info, err := os.Stat(filepath.Join(zerbDir, dir))
+		if err != nil || info.Mode().Perm() != 0700 {
+			t.Errorf("directory %q = %v, %v, want mode 0700", dir, info, err)
+		}
+	}
+	if version, err := layout.ReadVersion(zerbDir); err != nil || version != layout.Version {
+		t.Errorf("layout version = %d, %v, want %d", version, err, layout.Version)
+	}
+	if report.Git != InitGitCreated {
+		t.Errorf("Git = %v, want InitGitCreated", report.Git)
+	}
+	if report.GitUser == nil || report.GitUser.Name != "Test User" {
+		t.Errorf("GitUser = %+v, want Test User", report.GitUser)
+	}
+	if !report.InitialCommit {
+		t.Error("InitialCommit = false, want true")
+	}
+	if report.Platform == nil || report.Platform.OS != "linux" {
+		t.Errorf("Platform = %+v, want linux", report.Platform)
+	}
+
+	reported := defaultReported(t)
+	want := []InstalledBinary{
+		{Binary: binary.BinaryMise, Version: reported[binary.BinaryMise], Verified: binary.VerificationCosign},
+		{Binary: binary.BinaryChezmoi, Version: reported[binary.BinaryChezmoi], Verified: binary.VerificationCosign},
+	}
+	if !slices.Equal(report.Binaries, want) {
+		t.Errorf("Binaries = %+v, want %+v", report.Binaries, want)
+	}
+	if !slices.Equal(progress, []binary.Binary{binary.BinaryMise, binary.BinaryChezmoi}) {
+		t.Errorf("progress reported for %v, want mise and chezmoi", progress)
+	}
+
+	if report.ConfigFile != "zerb.20250115T103000.000Z.lua" || !report.ConfigCreated || report.FromTemplate {
+		t.Errorf("config = %s (created %v, template %v), want a new generated zerb.20250115T103000.000Z.lua",
+			report.ConfigFile, report.ConfigCreated, report.FromTemplate)
+	}
+	if report.Shell != shell.ShellZsh || report.ShellDetection == nil || report.ShellDetection.Method != shell.MethodShellEnv {
+		t.Errorf("Shell = %s (%+v), want zsh from $SHELL", report.Shell, report.ShellDetection)
+	}
+	if len(report.Warnings) != 0 || len(report.SensitiveData) != 0 {
+		t.Errorf("unexpected warnings %v, sensitive data %v", report.Warnings, report.SensitiveData)
+	}
+
+	// The commit holds the .gitignore and the config
+	files := gitOutput(t, zerbDir, "ls-files")
+	if files != ".gitignore\nconfigs/zerb.20250115T103000.000Z.lua" {
+		t.Errorf("committed files = %q", files)
+	}
+
+	// A second run refuses the initialized directory
+	if _, err := newTestInitService(zerbDir, installer).Run(context.Background(), InitOptions{}); !errors.Is(err, ErrAlreadyInitialized) {
+		t.Errorf("second Run() error = %v, want ErrAlreadyInitialized", err)
+	}
+}
+
+func TestInitService_Run_Template(t *testing.T) {
+	setupInitTest(t)
+	zerbDir := t.TempDir()
+	template := "zerb = {\n  tools = { \"node@20.11.0\" },\n}\n-- api_key = \"sk-abcdefghijklmnopqrstuvwx\"\n"
+
+	report, err := newTestInitService(zerbDir, &fakeInstaller{reported: defaultReported(t)}).Run(context.Background(), InitOptions{Template: template})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !report.ConfigCreated || !report.FromTemplate {
+		t.Errorf("ConfigCreated = %v, FromTemplate = %v, want both", report.ConfigCreated, report.FromTemplate)
+	}
+	if len(report.SensitiveData) == 0 {
+		t.Error("SensitiveData is empty for a template with an API key")
+	}
+
+	// The snapshot is the template verbatim
+	content, err := os.ReadFile(filepath.Join(zerbDir, "zerb.active.lua"))
+	if err != nil {
+		t.Fatalf("failed to read active config: %v", err)
+	}
+	if string(content) != template {
+		t.Errorf("active config = %q, want the template", content)
+	}
+}
+
+// TestInitService_Run_Resume checks that re-running an interrupted init
+// completes it, keeping the config an earlier run wrote
+func TestInitService_Run_Resume(t *testing.T) {
+	setupInitTest(t)
+	zerbDir := t.TempDir()
+	installer := &fakeInstaller{reported: defaultReported(t), err: errors.New("network down")}
+
+	// The download fails after the directories were created
+	if _, err := newTestInitService(zerbDir, installer).Run(context.Background(), InitOptions{}); err == nil {
+		t.Fatal("Run() with a failing download succeeded")
+	}
+	if !IsInitialized(zerbDir) {
+		t.Fatal("interrupted init left nothing behind; the test does not cover resuming")
+	}
+
+	// An earlier run got as far as the config, but not the commit
+	installer.err = nil
+	svc := newTestInitService(zerbDir, installer)
+	if _, err := svc.generateInitialConfig(context.Background(), false, &InitReport{}); err != nil {
+		t.Fatalf("generateInitialConfig() error = %v", err)
+	}
+	if svc.IsComplete(context.Background()) {
+		t.Fatal("IsComplete() = true before the config was committed")
+	}
+
+	report, err := svc.Run(context.Background(), InitOptions{})
+	if err != nil {
+		t.Fatalf("resumed Run() error = %v", err)
+	}
+	if report.ConfigCreated || report.ConfigFile != "zerb.20250115T103000.000Z.lua" {
+		t.Errorf("config = %s (created %v), want the existing one kept", report.ConfigFile, report.ConfigCreated)
+	}
+	if !report.InitialCommit {
+		t.Error("InitialCommit = false, want the kept config committed")
+	}
+	if !svc.IsComplete(context.Background()) {
+		t.Error("IsComplete() = false after the resumed init")
+	}
+}
+
+func TestInitService_Run_RegenerateConfig(t *testing.T) {
+	setupInitTest(t)
+	zerbDir := t.TempDir()
+	clk := clock.NewFake(time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC))
+	installer := &fakeInstaller{reported: defaultReported(t)}
+	svc := NewInitService(git.NewClient(zerbDir), fakeDetector{}, clk, zerbDir).
+		WithInstaller(func(dir string, info *platform.Info) (BinaryInstaller, error) {
+			installer.binDir = filepath.Join(dir, "bin")
+			return installer, nil
+		})
+
+	if _, err := svc.Run(context.Background(), InitOptions{}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	clk.Advance(time.Second)
+	template := "zerb = {\n  tools = { \"node@20.11.0\" },\n}\n"
+	report, err := svc.Run(context.Background(), InitOptions{Template: template, RegenerateConfig: true})
+	if err != nil {
+		t.Fatalf("Run() with RegenerateConfig error = %v", err)
+	}
+	if !report.ConfigCreated || report.ConfigFile != "zerb.20250115T103001.000Z.lua" {
+		t.Errorf("config = %s (created %v), want a new zerb.20250115T103001.000Z.lua", report.ConfigFile, report.ConfigCreated)
+	}
+	if got := gitOutput(t, zerbDir, "log", "--format=%s"); got != "Regenerate ZERB config\nInitialize ZERB environment" {
+		t.Errorf("history = %q, want the regenerated config committed on top", got)
+	}
+	content, err := os.ReadFile(filepath.Join(zerbDir, "zerb.active.lua"))
+	if err != nil || string(content) != template {
+		t.Errorf("active config = %q, %v, want the template", content, err)
+	}
+}
+
+func TestInitService_Run_ForeignRepo(t *testing.T) {
+	setupInitTest(t)
+	zerbDir := t.TempDir()
+	ctx := context.Background()
+
+	gitClient := git.NewClient(zerbDir)
+	if err := gitClient.InitRepo(ctx); err != nil {
+		t.Fatalf("InitRepo() error = %v", err)
+	}
+	if err := gitClient.ConfigureUser(ctx, git.GitUserInfo{Name: "Test User", Email: "test@example.com"}); err != nil {
+		t.Fatalf("ConfigureUser() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(zerbDir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := gitClient.CreateInitialCommit(ctx, "Start project", []string{"main.go"}); err != nil {
+		t.Fatalf("CreateInitialCommit() error = %v", err)
+	}
+
+	svc := newTestInitService(zerbDir, &fakeInstaller{reported: defaultReported(t)})
+	if _, err := svc.Run(ctx, InitOptions{}); !errors.Is(err, git.ErrForeignRepo) {
+		t.Fatalf("Run() error = %v, want ErrForeignRepo", err)
+	}
+	if _, err := os.Stat(filepath.Join(zerbDir, "configs")); !os.IsNotExist(err) {
+		t.Error("configs/ was created in the foreign repository")
+	}
+
+	// Adopting the repository commits on top of its history, with a warning
+	report, err := svc.Run(ctx, InitOptions{AdoptExistingRepo: true})
+	if err != nil {
+		t.Fatalf("Run(adopt) error = %v", err)
+	}
+	if report.Git != InitGitExisting {
+		t.Errorf("Git = %v, want InitGitExisting", report.Git)
+	}
+	if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], "on top of the existing history") {
+		t.Errorf("Warnings = %v, want the adopted repository", report.Warnings)
+	}
+}
+
+func TestInitService_Run_WithoutGit(t *testing.T) {
+	setupInitTest(t)
+	zerbDir := t.TempDir()
+
+	// A .git file is not a valid repository
+	if err := os.WriteFile(filepath.Join(zerbDir, ".git"), []byte("corrupted"), 0644); err != nil {
+		t.Fatalf("failed to write .git: %v", err)
+	}
+
+	report, err := newTestInitService(zerbDir, &fakeInstaller{reported: defaultReported(t)}).Run(context.Background(), InitOptions{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Git != InitGitInvalid || report.GitError == nil {
+		t.Errorf("Git = %v (%v), want InitGitInvalid with an error", report.Git, report.GitError)
+	}
+	if report.InitialCommit {
+		t.Error("InitialCommit = true without a repository")
+	}
+	if _, err := os.Stat(filepath.Join(zerbDir, ".zerb-no-git")); err != nil {
+		t.Errorf(".zerb-no-git marker not written: %v", err)
+	}
+}
+
+func TestInitService_Run_Binaries(t *testing.T) {
+	tests := []struct {
+		name         string
+		installer    *fakeInstaller
+		wantErr      string
+		wantWarnings []string
+	}{
+		{
+			name:      "install fails",
+			installer: &fakeInstaller{err: errors.New("download failed")},
+			wantErr:   "install binaries: install mise: download failed",
+		},
+		{
+			name: "wrong version",
+			installer: &fakeInstaller{reported: map[binary.Binary]string{
+				binary.BinaryMise:    "2020.1.1",
+				binary.BinaryChezmoi: strings.TrimPrefix(binary.DefaultVersions.Chezmoi, "v"),
+			}},
+			wantWarnings: []string{"installed tool manager reports version 2020.1.1, expected "},
+		},
+		{
+			name:         "version not checked",
+			installer:    &fakeInstaller{reported: map[binary.Binary]string{binary.BinaryMise: strings.TrimPrefix(binary.DefaultVersions.Mise, "v")}},
+			wantWarnings: []string{"could not check the installed configuration manager version"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupInitTest(t)
+			report, err := newTestInitService(t.TempDir(), tt.installer).Run(context.Background(), InitOptions{})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Run() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if len(report.Warnings) != len(tt.wantWarnings) {
+				t.Fatalf("Warnings = %v, want %v", report.Warnings, tt.wantWarnings)
+			}
+			for i, want := range tt.wantWarnings {
+				if !strings.HasPrefix(report.Warnings[i], want) {
+					t.Errorf("Warnings[%d] = %q, want prefix %q", i, report.Warnings[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestInitService_Reinstall(t *testing.T) {
+	setupInitTest(t)
+	zerbDir := t.TempDir()
+	installer := &fakeInstaller{reported: defaultReported(t), verified: binary.VerificationGPG}
+	svc := newTestInitService(zerbDir, installer)
+	ctx := context.Background()
+
+	if _, err := svc.Reinstall(ctx, InitOptions{}); !errors.Is(err, ErrNotInitialized) {
+		t.Fatalf("Reinstall() error = %v, want ErrNotInitialized", err)
+	}
+	if _, err := os.Stat(filepath.Join(zerbDir, "bin")); !os.IsNotExist(err) {
+		t.Error("bin/ created for an uninitialized ZERB directory")
+	}
+
+	if _, err := svc.Run(ctx, InitOptions{}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if err := os.Remove(filepath.Join(zerbDir, "bin", "chezmoi")); err != nil {
+		t.Fatalf("failed to remove chezmoi: %v", err)
+	}
+
+	report, err := svc.Reinstall(ctx, InitOptions{})
+	if err != nil {
+		t.Fatalf("Reinstall() error = %v", err)
+	}
+	if len(report.Binaries) != 2 || !report.Binaries[0].AlreadyInstalled || report.Binaries[1].AlreadyInstalled {
+		t.Errorf("Binaries = %+v, want only chezmoi reinstalled", report.Binaries)
+	}
+}
+
+// TestInitService_GenerateInitialConfig tests the generated initial config
+func TestInitService_GenerateInitialConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Create directory structure first
+	if err := layout.Create(tmpDir); err != nil {
+		t.Fatalf("layout.Create failed: %v", err)
+	}
+
+	// Generate initial config
+	ctx := context.Background()
+	svc := NewInitService(git.NewClient(tmpDir), fakeDetector{}, clock.Real{}, tmpDir)
+	created, err := svc.generateInitialConfig(ctx, false, &InitReport{})
+	if err != nil {
+		t.Fatalf("generateInitialConfig failed: %v", err)
+	}
+	if !created {
+		t.Error("generateInitialConfig reported no config created in an empty directory")
+	}
+
+	// Verify .zerb-active marker exists and contains timestamp
+	markerPath := filepath.Join(tmpDir, ".zerb-active")
+	markerContent, err := os.ReadFile(markerPath)
+	if err != nil {
+		t.Fatalf("failed to read marker file: %v", err)
+	}
+
+	configFilename := strings.TrimSpace(string(markerContent))
+	if configFilename == "" {
+		t.Error("marker file is empty")
+	}
+
+	// Verify filename format (zerb.YYYYMMDDTHHMMSS.SSSZ.lua with milliseconds)
+	filenameRegex := regexp.MustCompile(`^zerb\.\d{8}T\d{6}\.\d{3}Z\.lua$`)
+	if !filenameRegex.MatchString(configFilename) {
+		t.Errorf("marker filename has invalid format: %s (expected zerb.YYYYMMDDTHHMMSS.SSSZ.lua)", configFilename)
+	}
+
+	// Verify timestamped config file exists
+	configPath := filepath.Join(tmpDir, "configs", configFilename)
+	configContent, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("failed to read config file: %v", err)
+	}
+
+	// Verify config contains expected minimal content
+	configStr := string(configContent)
+	requiredStrings := []string{
+		"zerb = {",
+		"-- ZERB Configuration",
+	}
+
+	for _, required := range requiredStrings {
+		if !strings.Contains(configStr, required) {
+			t.Errorf("config missing required content: %s", required)
+		}
+	}
+
+	// Verify meta section exists (config with name should have meta)
+	if !strings.Contains(configStr, "meta = {") {
+		t.Error("config should contain meta section")
+	}
+
+	// Verify it doesn't contain tool definitions (empty list)
+	if strings.Contains(configStr, `"node@`) || strings.Contains(configStr, `"python@`) {
+		t.Error("initial config should not contain any pre-defined tools")
+	}
+
+	// Verify symlink exists and points to correct file
+	symlinkPath := filepath.Join(tmpDir, "zerb.active.lua")
+	linkTarget, err := os.Readlink(symlinkPath)
+	if err != nil {
+		t.Fatalf("failed to read symlink: %v", err)
+	}
+
+	expectedTarget := filepath.Join("configs", configFilename)
+	if linkTarget != expectedTarget {
+		t.Errorf("symlink target = %s, want %s", linkTarget, expectedTarget)
+	}
+
+	// Verify symlink resolves correctly
+	_, err = os.Stat(symlinkPath)
+	if err != nil {
+		t.Errorf("symlink does not resolve: %v", err)
+	}
+}
+
+// TestInitService_GenerateInitialConfig_ParseableByParser tests that generated config can be parsed
+func TestInitService_GenerateInitialConfig_ParseableByParser(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Create directory structure
+	if err := layout.Create(tmpDir); err != nil {
+		t.Fatalf("layout.Create failed: %v", err)
+	}
+
+	// Generate initial config
+	ctx := context.Background()
+	svc := NewInitService(git.NewClient(tmpDir), fakeDetector{}, clock.Real{}, tmpDir)
+	if _, err := svc.generateInitialConfig(ctx, false, &InitReport{}); err != nil {
+		t.Fatalf("generateInitialConfig failed: %v", err)
+	}
+
+	// Read generated config
+	symlinkPath := filepath.Join(tmpDir, "zerb.active.lua")
+	configContent, err := os.ReadFile(symlinkPath)
+	if err != nil {
+		t.Fatalf("failed to read generated config: %v", err)
+	}
+
+	// Try to parse it with config parser
+	parser := config.NewParser(nil) // No platform detection needed for this test
+	parsedConfig, err := parser.ParseString(ctx, string(configContent))
+	if err != nil {
+		t.Fatalf("generated config cannot be parsed: %v", err)
+	}
+
+	// Verify parsed config has expected structure
+	if parsedConfig.Meta.Name == "" {
+		t.Error("parsed config missing meta.name")
+	}
+	if len(parsedConfig.Tools) != 0 {
+		t.Errorf("parsed config should have 0 tools, got %d", len(parsedConfig.Tools))
+	}
+	if parsedConfig.Git.Branch != "main" {
+		t.Errorf("parsed config git.branch = %s, want main", parsedConfig.Git.Branch)
+	}
+}
+
+// TestInitService_GenerateInitialConfig_Idempotent tests that a second call keeps the existing config
+func TestInitService_GenerateInitialConfig_Idempotent(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Create directory structure
+	if err := layout.Create(tmpDir); err != nil {
+		t.Fatalf("layout.Create failed: %v", err)
+	}
+
+	ctx := context.Background()
+
+	// Fake clock so snapshot timestamps are deterministic
+	clk := clock.NewFake(time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC))
+	svc := NewInitService(git.NewClient(tmpDir), fakeDetector{}, clk, tmpDir)
+
+	// Generate first config
+	if _, err := svc.generateInitialConfig(ctx, false, &InitReport{}); err != nil {
+		t.Fatalf("first generateInitialConfig failed: %v", err)
+	}
+
+	// Advance the clock so a new snapshot would get a different timestamp
+	clk.Advance(time.Second)
+
+	// Generate again; the existing valid config should be kept
+	created, err := svc.generateInitialConfig(ctx, false, &InitReport{})
+	if err != nil {
+		t.Fatalf("second generateInitialConfig failed: %v", err)
+	}
+	if created {
+		t.Error("second generateInitialConfig created a config, want no-op")
+	}
+
+	// Marker should still name the first snapshot
+	marker, err := os.ReadFile(filepath.Join(tmpDir, ".zerb-active"))
+	if err != nil {
+		t.Fatalf("failed to read marker: %v", err)
+	}
+	if string(marker) != "zerb.20250115T103000.000Z.lua" {
+		t.Errorf("marker = %q, want zerb.20250115T103000.000Z.lua", marker)
+	}
+
+	// No new snapshot should have been written
+	entries, err := os.ReadDir(filepath.Join(tmpDir, "configs"))
+	if err != nil {
+		t.Fatalf("failed to read configs dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected 1 config file, got %d", len(entries))
+	}
+
+	// A template is not seeded over the valid config either
+	created, err = svc.seedInitialConfig(ctx, "zerb = {}\n", false, &InitReport{})
+	if err != nil || created {
+		t.Errorf("seedInitialConfig() = %v, %v, want the existing config kept", created, err)
+	}
+}
+
+// TestInitService_GenerateInitialConfig_Regenerates tests when a new
+// snapshot is created even though one already exists
+func TestInitService_GenerateInitialConfig_Regenerates(t *testing.T) {
+	tests := []struct {
+		name  string
+		force bool
+		setup func(t *testing.T, zerbDir, snapshot string)
+	}{
+		{
+			name:  "force",
+			force: true,
+			setup: func(t *testing.T, zerbDir, snapshot string) {},
+		},
+		{
+			name: "invalid active config",
+			setup: func(t *testing.T, zerbDir, snapshot string) {
+				if err := os.WriteFile(filepath.Join(zerbDir, "configs", snapshot), []byte("zerb = {"), 0600); err != nil {
+					t.Fatalf("failed to corrupt config: %v", err)
+				}
+			},
+		},
+		{
+			name: "missing snapshot",
+			setup: func(t *testing.T, zerbDir, snapshot string) {
+				if err := os.Remove(filepath.Join(zerbDir, "configs", snapshot)); err != nil {
+					t.Fatalf("failed to remove config: %v", err)
+				}
+			},
+		},
+		{
+			name: "missing symlink",
+			setup: func(t *testing.T, zerbDir, snapshot string) {
+				if err := os.Remove(filepath.Join(zerbDir, "zerb.active.lua")); err != nil {
+					t.Fatalf("failed to remove symlink: %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			if err := layout.Create(tmpDir); err != nil {
+				t.Fatalf("layout.Create failed: %v", err)
+			}
+
+			ctx := context.Background()
+			clk := clock.NewFake(time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC))
+			svc := NewInitService(git.NewClient(tmpDir), fakeDetector{}, clk, tmpDir)
+
+			if _, err := svc.generateInitialConfig(ctx, false, &InitReport{}); err != nil {
+				t.Fatalf("first generateInitialConfig failed: %v", err)
+			}
+			tt.setup(t, tmpDir, "zerb.20250115T103000.000Z.lua")
+
+			clk.Advance(time.Second)
+			created, err := svc.generateInitialConfig(ctx, tt.force, &InitReport{})
+			if err != nil {
+				t.Fatalf("second generateInitialConfig failed: %v", err)
+			}
+			if !created {
+				t.Error("second generateInitialConfig did not create a config")
+			}
+
+			marker, err := os.ReadFile(filepath.Join(tmpDir, ".zerb-active"))
+			if err != nil {
+				t.Fatalf("failed to read marker: %v", err)
+			}
+			if string(marker) != "zerb.20250115T103001.000Z.lua" {
+				t.Errorf("marker = %q, want zerb.20250115T103001.000Z.lua", marker)
+			}
+
+			linkTarget, err := os.Readlink(filepath.Join(tmpDir, "zerb.active.lua"))
+			if err != nil {
+				t.Fatalf("failed to read symlink: %v", err)
+			}
+			if want := filepath.Join("configs", "zerb.20250115T103001.000Z.lua"); linkTarget != want {
+				t.Errorf("symlink target = %s, want %s", linkTarget, want)
+			}
+		})
+	}
+}
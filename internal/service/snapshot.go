@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/fsutil"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+)
+
+// writeConfigSnapshot writes a generated config to configs/<filename>.
+func writeConfigSnapshot(zerbDir, filename, content string) error {
+	configsDir := filepath.Join(zerbDir, "configs")
+	if err := os.MkdirAll(configsDir, ConfigDirPermissions); err != nil {
+		return fmt.Errorf("create configs directory: %w", err)
+	}
+
+	newConfigPath := filepath.Join(configsDir, filename)
+	if err := fsutil.WriteFileAtomic(newConfigPath, []byte(content), ConfigFilePermissions); err != nil {
+		return fmt.Errorf("write new config: %w", err)
+	}
+
+	return nil
+}
+
+// activateConfigSnapshot points the .zerb-active marker and the
+// zerb.active.lua symlink at configs/<filename>.
+func activateConfigSnapshot(zerbDir, filename, content string) error {
+	// Update .zerb-active marker
+	activeMarkerPath := filepath.Join(zerbDir, ".zerb-active")
+	if err := fsutil.WriteFileAtomic(activeMarkerPath, []byte(filename+"\n"), ConfigFilePermissions); err != nil {
+		return fmt.Errorf("update active marker: %w", err)
+	}
+
+	// Update zerb.active.lua symlink atomically (or copy on Windows)
+	activeConfigPath := filepath.Join(zerbDir, "zerb.active.lua")
+	tmpLink := activeConfigPath + ".tmp"
+	target := filepath.Join("configs", filename)
+
+	// Try to create symlink to temp location first
+	err := os.Symlink(target, tmpLink)
+	if err != nil {
+		// Check if symlinks are unsupported (Windows without dev mode)
+		errStr := err.Error()
+		if strings.Contains(errStr, "not supported") || strings.Contains(errStr, "not implemented") {
+			// Fallback to copy on systems without symlink support
+			if err := fsutil.WriteFileAtomic(activeConfigPath, []byte(content), ConfigFilePermissions); err != nil {
+				return fmt.Errorf("update active config: %w", err)
+			}
+			return nil
+		}
+		return fmt.Errorf("create symlink: %w", err)
+	}
+
+	// Atomic rename (overwrites existing)
+	if err := os.Rename(tmpLink, activeConfigPath); err != nil {
+		os.Remove(tmpLink) // Clean up temp
+		return fmt.Errorf("update active config link: %w", err)
+	}
+
+	return nil
+}
+
+// ActivateSnapshot points the active config at the existing snapshot
+// configs/<filename>, e.g. to repair a broken .zerb-active marker.
+func ActivateSnapshot(zerbDir, filename string) error {
+	content, err := os.ReadFile(filepath.Join(zerbDir, "configs", filename))
+	if err != nil {
+		return fmt.Errorf("read config snapshot: %w", err)
+	}
+	return activateConfigSnapshot(zerbDir, filename, string(content))
+}
+
+// snapshotStagePaths returns the repository-relative paths that change when a
+// new config snapshot is activated, including the chezmoi source tree.
+func snapshotStagePaths(filename string) []string {
+	return []string{
+		filepath.Join("configs", filename),
+		".zerb-active",
+		"zerb.active.lua",
+		filepath.Join("chezmoi", "source"),
+	}
+}
+
+// checkoutSnapshotBranch switches to this machine's snapshot branch when the
+// config enables git.per_host_branches, so the next commit lands there.
+// hostBranch overrides the branch name; when empty it is derived from the
+// machine id and hostname.
+func checkoutSnapshotBranch(ctx context.Context, gitClient git.Git, cfg *config.Config, hostBranch string) error {
+	if !cfg.Git.PerHostBranches {
+		return nil
+	}
+
+	if hostBranch == "" {
+		name, err := git.HostBranchName()
+		if err != nil {
+			return fmt.Errorf("determine host branch: %w", err)
+		}
+		hostBranch = name
+	}
+
+	current, err := gitClient.CurrentBranch(ctx)
+	if err == nil && current == hostBranch {
+		return nil
+	}
+
+	if err := gitClient.CheckoutBranch(ctx, hostBranch); err != nil {
+		return fmt.Errorf("switch to host branch: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/transaction"
+)
+
+// SnapshotCommitService commits config snapshots written outside the config
+// services, such as those zerb drift creates when adopting tools, so they
+// are recorded like every other snapshot. With git.per_host_branches the
+// commit lands on this machine's branch.
+type SnapshotCommitService struct {
+	git     git.Git
+	parser  ConfigParser
+	zerbDir string
+
+	hostBranch string
+}
+
+// NewSnapshotCommitService creates a new snapshot commit service with dependency injection.
+func NewSnapshotCommitService(gitClient git.Git, parser ConfigParser, zerbDir string) *SnapshotCommitService {
+	return &SnapshotCommitService{
+		git:     gitClient,
+		parser:  parser,
+		zerbDir: zerbDir,
+	}
+}
+
+// WithHostBranch sets the branch snapshot commits go to when the config
+// enables per-host branches, instead of deriving it from the machine.
+func (s *SnapshotCommitService) WithHostBranch(name string) *SnapshotCommitService {
+	s.hostBranch = name
+	return s
+}
+
+// CommitActive commits the active snapshot in configs/ and the active
+// config marker and link, with message as the subject and an optional
+// body. Other files in configs/ are left alone. The
+// active config decides whether the commit goes to the host branch.
+// Returns the commit hash, or "" if the ZERB directory is not a git
+// repository.
+func (s *SnapshotCommitService) CommitActive(ctx context.Context, message, body string) (string, error) {
+	// Check context first
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	if isRepo, err := s.git.IsGitRepo(ctx); err != nil || !isRepo {
+		return "", err
+	}
+
+	content, err := os.ReadFile(filepath.Join(s.zerbDir, "zerb.active.lua"))
+	if err != nil {
+		return "", fmt.Errorf("read active config: %w", err)
+	}
+	cfg, err := s.parser.ParseString(ctx, string(content))
+	if err != nil {
+		return "", fmt.Errorf("parse active config: %w", err)
+	}
+
+	marker, err := os.ReadFile(filepath.Join(s.zerbDir, ".zerb-active"))
+	if err != nil {
+		return "", fmt.Errorf("read active marker: %w", err)
+	}
+	filename := strings.TrimSpace(string(marker))
+
+	if err := checkoutSnapshotBranch(ctx, s.git, cfg, s.hostBranch); err != nil {
+		return "", err
+	}
+	if err := s.git.Stage(ctx, filepath.Join("configs", filename), ".zerb-active", "zerb.active.lua"); err != nil {
+		return "", fmt.Errorf("stage files: %w", err)
+	}
+	if err := s.git.Commit(ctx, message, body); err != nil {
+		return "", fmt.Errorf("create commit: %w", err)
+	}
+
+	commitHash, err := s.git.GetHeadCommit(ctx)
+	if err != nil {
+		return "", fmt.Errorf("get commit: %w", err)
+	}
+	return commitHash, nil
+}
+
+// SaveActive writes content as the snapshot configs/<filename>, makes it
+// the active config and commits it as CommitActive does, holding the
+// transaction lock so no other command changes the config meanwhile.
+// Returns the commit hash, or "" if the ZERB directory is not a git
+// repository.
+func (s *SnapshotCommitService) SaveActive(ctx context.Context, filename, content, message, body string) (string, error) {
+	lock, err := transaction.AcquireLock(filepath.Join(s.zerbDir, ".txn"))
+	if err != nil {
+		return "", fmt.Errorf("acquire transaction lock: %w", err)
+	}
+	defer func() { _ = lock.Release() }()
+
+	if err := writeConfigSnapshot(s.zerbDir, filename, content); err != nil {
+		return "", err
+	}
+	if err := activateConfigSnapshot(s.zerbDir, filename, content); err != nil {
+		return "", err
+	}
+	return s.CommitActive(ctx, message, body)
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/config"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/git"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/transaction"
+)
+
+func TestSnapshotCommitService_CommitActive_PerHostBranch(t *testing.T) {
+	zerbDir := setupAddTestRepo(t)
+	ctx := context.Background()
+	baseBranch := gitOutput(t, zerbDir, "rev-parse", "--abbrev-ref", "HEAD")
+
+	// A snapshot written outside the services, as drift adopt does
+	content, err := config.NewGenerator().Generate(ctx, &config.Config{
+		Tools: []string{"node@20.11.0"},
+		Git:   config.GitConfig{PerHostBranches: true},
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	filename := "zerb.20250102T000000.000Z.lua"
+	if err := writeConfigSnapshot(zerbDir, filename, content); err != nil {
+		t.Fatalf("writeConfigSnapshot() error = %v", err)
+	}
+	if err := activateConfigSnapshot(zerbDir, filename, content); err != nil {
+		t.Fatalf("activateConfigSnapshot() error = %v", err)
+	}
+
+	// An unrelated snapshot left in configs/ is not committed
+	stray := "zerb.20250101T120000.000Z.lua"
+	if err := writeConfigSnapshot(zerbDir, stray, "zerb = {}\n"); err != nil {
+		t.Fatalf("writeConfigSnapshot() error = %v", err)
+	}
+
+	svc := NewSnapshotCommitService(git.NewClient(zerbDir), config.NewParser(nil), zerbDir).WithHostBranch("hosts/test-machine")
+	hash, err := svc.CommitActive(ctx, "Adopt node from drift", "")
+	if err != nil {
+		t.Fatalf("CommitActive() error = %v", err)
+	}
+
+	if got := gitOutput(t, zerbDir, "rev-parse", "--abbrev-ref", "HEAD"); got != "hosts/test-machine" {
+		t.Errorf("current branch = %q, want %q", got, "hosts/test-machine")
+	}
+	if got := gitOutput(t, zerbDir, "rev-parse", "hosts/test-machine"); got != hash {
+		t.Errorf("host branch = %s, want snapshot commit %s", got, hash)
+	}
+	if got := gitOutput(t, zerbDir, "rev-list", "--count", baseBranch); got != "1" {
+		t.Errorf("%s has %s commits, want 1 (snapshot must not land there)", baseBranch, got)
+	}
+	if got := gitOutput(t, zerbDir, "show", "hosts/test-machine:.zerb-active"); got != filename {
+		t.Errorf("committed active marker = %q, want %q", got, filename)
+	}
+	if got, want := gitOutput(t, zerbDir, "status", "--porcelain"), "?? configs/"+stray; got != want {
+		t.Errorf("git status = %q, want only %q untracked", got, want)
+	}
+}
+
+func TestSnapshotCommitService_CommitActive_NotARepo(t *testing.T) {
+	zerbDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(zerbDir, "zerb.active.lua"), []byte("zerb = {}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewSnapshotCommitService(git.NewClient(zerbDir), config.NewParser(nil), zerbDir)
+	hash, err := svc.CommitActive(context.Background(), "Adopt node from drift", "")
+	if err != nil || hash != "" {
+		t.Errorf("CommitActive() = %q, %v; want no commit and no error", hash, err)
+	}
+}
+
+func TestSnapshotCommitService_SaveActive(t *testing.T) {
+	zerbDir := setupAddTestRepo(t)
+	ctx := context.Background()
+	svc := NewSnapshotCommitService(git.NewClient(zerbDir), config.NewParser(nil), zerbDir)
+
+	filename, content := "zerb.20250102T000000.000Z.lua", "zerb = {\n  tools = { \"node@20.11.0\" },\n}\n"
+
+	// Another command holding the lock blocks the save
+	lock, err := transaction.AcquireLock(filepath.Join(zerbDir, ".txn"))
+	if err != nil {
+		t.Fatalf("AcquireLock() error = %v", err)
+	}
+	if _, err := svc.SaveActive(ctx, filename, content, "Remove duplicate tool node@18.0.0", ""); err == nil {
+		t.Error("SaveActive() succeeded while the transaction lock was held")
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+
+	hash, err := svc.SaveActive(ctx, filename, content, "Remove duplicate tool node@18.0.0", "")
+	if err != nil {
+		t.Fatalf("SaveActive() error = %v", err)
+	}
+	if hash == "" || gitOutput(t, zerbDir, "log", "-1", "--format=%s") != "Remove duplicate tool node@18.0.0" {
+		t.Errorf("SaveActive() = %q, want the snapshot committed", hash)
+	}
+	if got := gitOutput(t, zerbDir, "show", "HEAD:configs/"+filename); got != strings.TrimSpace(content) {
+		t.Errorf("committed snapshot = %q, want %q", got, content)
+	}
+	if got := gitOutput(t, zerbDir, "status", "--porcelain"); got != "" {
+		t.Errorf("uncommitted changes left:\n%s", got)
+	}
+
+	// The lock is released afterwards
+	lock, err = transaction.AcquireLock(filepath.Join(zerbDir, ".txn"))
+	if err != nil {
+		t.Fatalf("lock still held after SaveActive: %v", err)
+	}
+	_ = lock.Release()
+}
@@ -16,6 +16,35 @@ func GenerateActivationCommand(shell ShellType) (string, error) {
 	case ShellFish:
 		// Fish uses pipe to source
 		return fmt.Sprintf("zerb activate %s | source", shell), nil
+	case ShellNushell:
+		// Nushell cannot eval generated code; `source` only reads files that
+		// exist when config.nu is parsed. Write the activation to a vendor
+		// autoload file instead, which Nushell loads after config.nu.
+		return fmt.Sprintf(`mkdir ($nu.data-dir | path join "vendor/autoload"); zerb activate %s | save --force ($nu.data-dir | path join "vendor/autoload/zerb.nu")`, shell), nil
+	case ShellElvish:
+		return fmt.Sprintf("eval (zerb activate %s | slurp)", shell), nil
+	case ShellPwsh:
+		return fmt.Sprintf("(& zerb activate %s) | Out-String | Invoke-Expression", shell), nil
+	default:
+		return "", &UnsupportedShellError{Shell: shell.String()}
+	}
+}
+
+// GenerateActiveMarker generates the line `zerb activate` appends to its
+// output to export ZERB_ACTIVE=1, so a shell (and VerifyActivation) can
+// tell that activation ran
+func GenerateActiveMarker(shell ShellType) (string, error) {
+	switch shell {
+	case ShellBash, ShellZsh:
+		return fmt.Sprintf("export %s=1", EnvZerbActive), nil
+	case ShellFish:
+		return fmt.Sprintf("set -gx %s 1", EnvZerbActive), nil
+	case ShellNushell:
+		return fmt.Sprintf(`$env.%s = "1"`, EnvZerbActive), nil
+	case ShellPwsh:
+		return fmt.Sprintf(`$env:%s = "1"`, EnvZerbActive), nil
+	case ShellElvish:
+		return fmt.Sprintf("set-env %s 1", EnvZerbActive), nil
 	default:
 		return "", &UnsupportedShellError{Shell: shell.String()}
 	}
@@ -30,6 +30,24 @@ func TestGenerateActivationCommand(t *testing.T) {
 			want:    "zerb activate fish | source",
 			wantErr: false,
 		},
+		{
+			name:    "Nushell activation",
+			shell:   ShellNushell,
+			want:    `mkdir ($nu.data-dir | path join "vendor/autoload"); zerb activate nu | save --force ($nu.data-dir | path join "vendor/autoload/zerb.nu")`,
+			wantErr: false,
+		},
+		{
+			name:    "Elvish activation",
+			shell:   ShellElvish,
+			want:    "eval (zerb activate elvish | slurp)",
+			wantErr: false,
+		},
+		{
+			name:    "PowerShell activation",
+			shell:   ShellPwsh,
+			want:    "(& zerb activate pwsh) | Out-String | Invoke-Expression",
+			wantErr: false,
+		},
 		{
 			name:    "Unknown shell",
 			shell:   ShellUnknown,
@@ -62,6 +80,34 @@ func TestGenerateActivationCommand(t *testing.T) {
 	}
 }
 
+func TestGenerateActiveMarker(t *testing.T) {
+	tests := []struct {
+		shell   ShellType
+		want    string
+		wantErr bool
+	}{
+		{shell: ShellBash, want: "export ZERB_ACTIVE=1"},
+		{shell: ShellZsh, want: "export ZERB_ACTIVE=1"},
+		{shell: ShellFish, want: "set -gx ZERB_ACTIVE 1"},
+		{shell: ShellNushell, want: `$env.ZERB_ACTIVE = "1"`},
+		{shell: ShellElvish, want: "set-env ZERB_ACTIVE 1"},
+		{shell: ShellPwsh, want: `$env:ZERB_ACTIVE = "1"`},
+		{shell: ShellUnknown, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell.String(), func(t *testing.T) {
+			got, err := GenerateActiveMarker(tt.shell)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GenerateActiveMarker() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateActiveMarker() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestGetMiseActivationCommand(t *testing.T) {
 	misePath := "/home/user/.config/zerb/bin/mise"
 
@@ -12,11 +12,25 @@ const (
 	EnvZerbDebug = "ZERB_DEBUG"
 )
 
-// Activation and backup markers
+// Activation and backup markers. Every supported shell (including nu,
+// elvish and pwsh) uses # for line comments, so the marker comments ZERB
+// writes to RC files and fragments are valid in all of them.
 const (
 	// ActivationMarker is the string that must appear in activation commands
 	ActivationMarker = "zerb activate"
 
+	// ActivationComment is the comment written above the activation line
+	ActivationComment = "# ZERB - Developer environment manager"
+
 	// BackupSuffix is the prefix for timestamped backup files
 	BackupSuffix = ".zerb-backup"
+
+	// FragmentMarker is the trailing comment that identifies the RC line
+	// sourcing an activation fragment
+	FragmentMarker = "# zerb-fragment"
+
+	// FragmentPrefix is the filename prefix of activation fragments
+	// (followed by the shell name, e.g. activate.bash, or activate.ps1
+	// for PowerShell)
+	FragmentPrefix = "activate."
 )
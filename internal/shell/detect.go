@@ -2,41 +2,67 @@ package shell
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
+// Detection methods reported in DetectionResult.Method
+const (
+	MethodShellEnv      = "$SHELL environment variable"
+	MethodParentProcess = "parent process"
+	MethodFailed        = "detection failed"
+)
+
+// multiplexers are parent processes that sit between the user's shell and
+// ZERB. Their process name says nothing about the shell, so when one is the
+// parent, only $SHELL is trusted.
+var multiplexers = []string{"tmux", "screen", "zellij", "byobu", "abduco", "dtach"}
+
+// parentProcess returns the name and executable path of the parent process.
+// It is a variable so tests can simulate different parents.
+var parentProcess = readParentProcess
+
 // DetectShell detects the user's shell using multiple methods
 func DetectShell() (*DetectionResult, error) {
+	parentName, parentPath := parentProcess()
+	multiplexer := multiplexerName(parentName)
+
 	// Method 1: Try $SHELL environment variable (most reliable)
-	if shell := os.Getenv("SHELL"); shell != "" {
+	if shell := strings.TrimSpace(os.Getenv("SHELL")); shell != "" {
 		shellType := parseShellFromPath(shell)
 		if shellType.IsValid() {
 			return &DetectionResult{
-				Shell:      shellType,
-				Method:     "$SHELL environment variable",
-				ShellPath:  shell,
-				Confidence: "high",
+				Shell:       shellType,
+				Method:      MethodShellEnv,
+				ShellPath:   shell,
+				Confidence:  "high",
+				Multiplexer: multiplexer,
 			}, nil
 		}
 	}
 
-	// Method 2: Try parent process (fallback)
-	if shellType, shellPath := detectFromParentProcess(); shellType.IsValid() {
-		return &DetectionResult{
-			Shell:      shellType,
-			Method:     "parent process",
-			ShellPath:  shellPath,
-			Confidence: "medium",
-		}, nil
+	// Method 2: Try parent process (fallback). Inside a multiplexer the
+	// parent is the multiplexer itself, so its name would be a wrong guess.
+	if multiplexer == "" {
+		if shellType := parseShellFromPath(parentName); shellType.IsValid() {
+			return &DetectionResult{
+				Shell:      shellType,
+				Method:     MethodParentProcess,
+				ShellPath:  parentPath,
+				Confidence: "medium",
+			}, nil
+		}
 	}
 
 	// Method 3: Could not detect shell
 	return &DetectionResult{
-		Shell:      ShellUnknown,
-		Method:     "detection failed",
-		ShellPath:  "",
-		Confidence: "none",
+		Shell:       ShellUnknown,
+		Method:      MethodFailed,
+		ShellPath:   "",
+		Confidence:  "none",
+		Multiplexer: multiplexer,
 	}, nil
 }
 
@@ -45,12 +71,19 @@ func DetectShell() (*DetectionResult, error) {
 //   - /bin/bash -> bash
 //   - /usr/bin/zsh -> zsh
 //   - /usr/local/bin/fish -> fish
+//   - -zsh (login shell) -> zsh
+//   - /opt/homebrew/bin/bash-5.2 -> bash
+//   - /usr/bin/nu -> nu
+//   - /opt/microsoft/powershell/7/pwsh -> pwsh
 func parseShellFromPath(shellPath string) ShellType {
 	// Get the base name (e.g., "/bin/bash" -> "bash")
-	baseName := filepath.Base(shellPath)
+	baseName := filepath.Base(strings.TrimSpace(shellPath))
 
-	// Normalize to lowercase
-	baseName = strings.ToLower(baseName)
+	// Normalize to lowercase; login shells are named with a leading dash
+	baseName = strings.TrimPrefix(strings.ToLower(baseName), "-")
+
+	// Drop a version suffix (e.g., "zsh-5.9", "bash5.2")
+	baseName = strings.TrimRight(baseName, "0123456789.-")
 
 	// Map to known shell types
 	switch baseName {
@@ -60,22 +93,51 @@ func parseShellFromPath(shellPath string) ShellType {
 		return ShellZsh
 	case "fish":
 		return ShellFish
+	case "nu", "nushell":
+		return ShellNushell
+	case "elvish":
+		return ShellElvish
+	case "pwsh", "powershell":
+		return ShellPwsh
 	default:
 		return ShellUnknown
 	}
 }
 
-// detectFromParentProcess attempts to detect the shell from the parent process
-// This is a fallback when $SHELL is not set
-func detectFromParentProcess() (ShellType, string) {
-	// Read /proc/self/stat to get parent process ID (Linux only)
-	// For MVP, we'll skip this complex implementation and return unknown
-	// This can be enhanced post-MVP using:
-	// - /proc filesystem on Linux
-	// - ps command on macOS
-	// - gopsutil library for cross-platform support
-
-	return ShellUnknown, ""
+// multiplexerName returns the multiplexer a process name belongs to (e.g.
+// "tmux" for "tmux: server"), or "" if it is not a multiplexer
+func multiplexerName(processName string) string {
+	name := strings.ToLower(filepath.Base(strings.TrimSpace(processName)))
+	for _, m := range multiplexers {
+		if name == m || strings.HasPrefix(name, m+":") || strings.HasPrefix(name, m+" ") {
+			return m
+		}
+	}
+	return ""
+}
+
+// readParentProcess returns the name and executable path of the parent
+// process, or empty strings if they cannot be determined. Linux reads
+// /proc; other systems ask ps.
+func readParentProcess() (name, path string) {
+	ppid := os.Getppid()
+	if ppid <= 1 {
+		return "", ""
+	}
+
+	procDir := filepath.Join("/proc", strconv.Itoa(ppid))
+	if comm, err := os.ReadFile(filepath.Join(procDir, "comm")); err == nil {
+		path, _ = os.Readlink(filepath.Join(procDir, "exe"))
+		return strings.TrimSpace(string(comm)), path
+	}
+
+	out, err := exec.Command("ps", "-o", "comm=", "-p", strconv.Itoa(ppid)).Output()
+	if err != nil {
+		return "", ""
+	}
+	// ps may print the full executable path
+	path = strings.TrimSpace(string(out))
+	return filepath.Base(path), path
 }
 
 // ValidateShell validates that a shell type is supported
@@ -88,5 +150,5 @@ func ValidateShell(shell ShellType) error {
 
 // GetSupportedShells returns a list of supported shells
 func GetSupportedShells() []ShellType {
-	return []ShellType{ShellBash, ShellZsh, ShellFish}
+	return []ShellType{ShellBash, ShellZsh, ShellFish, ShellNushell, ShellElvish, ShellPwsh}
 }
@@ -50,6 +50,9 @@ func TestDetectShell(t *testing.T) {
 		},
 	}
 
+	// Parent process that says nothing about the shell
+	stubParentProcess(t, "go", "/usr/local/go/bin/go")
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Set environment variable
@@ -86,6 +89,165 @@ func TestDetectShell(t *testing.T) {
 	}
 }
 
+// stubParentProcess makes DetectShell see the given parent process
+func stubParentProcess(t *testing.T, name, path string) {
+	t.Helper()
+	orig := parentProcess
+	parentProcess = func() (string, string) { return name, path }
+	t.Cleanup(func() { parentProcess = orig })
+}
+
+func TestDetectShell_ParentProcess(t *testing.T) {
+	tests := []struct {
+		name            string
+		shellEnv        string
+		parentName      string
+		parentPath      string
+		wantShell       ShellType
+		wantMethod      string
+		wantPath        string
+		wantMultiplexer string
+	}{
+		{
+			name:            "SHELL fish under tmux",
+			shellEnv:        "/usr/bin/fish",
+			parentName:      "tmux: server",
+			parentPath:      "/usr/bin/tmux",
+			wantShell:       ShellFish,
+			wantMethod:      MethodShellEnv,
+			wantPath:        "/usr/bin/fish",
+			wantMultiplexer: "tmux",
+		},
+		{
+			name:            "SHELL fish under tmux client",
+			shellEnv:        "/usr/bin/fish",
+			parentName:      "tmux",
+			wantShell:       ShellFish,
+			wantMethod:      MethodShellEnv,
+			wantPath:        "/usr/bin/fish",
+			wantMultiplexer: "tmux",
+		},
+		{
+			name:            "SHELL fish under screen",
+			shellEnv:        "/usr/bin/fish",
+			parentName:      "SCREEN",
+			wantShell:       ShellFish,
+			wantMethod:      MethodShellEnv,
+			wantPath:        "/usr/bin/fish",
+			wantMultiplexer: "screen",
+		},
+		{
+			name:       "SHELL wins over a different parent shell",
+			shellEnv:   "/usr/bin/fish",
+			parentName: "bash",
+			parentPath: "/bin/bash",
+			wantShell:  ShellFish,
+			wantMethod: MethodShellEnv,
+			wantPath:   "/usr/bin/fish",
+		},
+		{
+			name:       "login shell SHELL",
+			shellEnv:   "-zsh",
+			wantShell:  ShellZsh,
+			wantMethod: MethodShellEnv,
+			wantPath:   "-zsh",
+		},
+		{
+			name:       "versioned SHELL",
+			shellEnv:   "/opt/homebrew/bin/bash-5.2",
+			wantShell:  ShellBash,
+			wantMethod: MethodShellEnv,
+			wantPath:   "/opt/homebrew/bin/bash-5.2",
+		},
+		{
+			name:       "parent fallback without SHELL",
+			parentName: "zsh",
+			parentPath: "/usr/bin/zsh",
+			wantShell:  ShellZsh,
+			wantMethod: MethodParentProcess,
+			wantPath:   "/usr/bin/zsh",
+		},
+		{
+			name:       "SHELL nu",
+			shellEnv:   "/usr/bin/nu",
+			wantShell:  ShellNushell,
+			wantMethod: MethodShellEnv,
+			wantPath:   "/usr/bin/nu",
+		},
+		{
+			name:       "parent fallback to pwsh",
+			parentName: "pwsh",
+			parentPath: "/opt/microsoft/powershell/7/pwsh",
+			wantShell:  ShellPwsh,
+			wantMethod: MethodParentProcess,
+			wantPath:   "/opt/microsoft/powershell/7/pwsh",
+		},
+		{
+			name:       "parent fallback to elvish",
+			parentName: "elvish",
+			parentPath: "/usr/bin/elvish",
+			wantShell:  ShellElvish,
+			wantMethod: MethodParentProcess,
+			wantPath:   "/usr/bin/elvish",
+		},
+		{
+			name:       "parent fallback with unsupported SHELL",
+			shellEnv:   "/bin/ksh",
+			parentName: "-bash",
+			parentPath: "/bin/bash",
+			wantShell:  ShellBash,
+			wantMethod: MethodParentProcess,
+			wantPath:   "/bin/bash",
+		},
+		{
+			name:            "tmux parent is never used as a guess",
+			parentName:      "tmux: server",
+			parentPath:      "/usr/bin/tmux",
+			wantShell:       ShellUnknown,
+			wantMethod:      MethodFailed,
+			wantMultiplexer: "tmux",
+		},
+		{
+			name:            "zellij parent with unsupported SHELL",
+			shellEnv:        "/bin/ksh",
+			parentName:      "zellij",
+			wantShell:       ShellUnknown,
+			wantMethod:      MethodFailed,
+			wantMultiplexer: "zellij",
+		},
+		{
+			name:       "unknown parent",
+			parentName: "",
+			wantShell:  ShellUnknown,
+			wantMethod: MethodFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SHELL", tt.shellEnv)
+			stubParentProcess(t, tt.parentName, tt.parentPath)
+
+			result, err := DetectShell()
+			if err != nil {
+				t.Fatalf("DetectShell() error = %v", err)
+			}
+			if result.Shell != tt.wantShell {
+				t.Errorf("Shell = %v, want %v", result.Shell, tt.wantShell)
+			}
+			if result.Method != tt.wantMethod {
+				t.Errorf("Method = %q, want %q", result.Method, tt.wantMethod)
+			}
+			if result.ShellPath != tt.wantPath {
+				t.Errorf("ShellPath = %q, want %q", result.ShellPath, tt.wantPath)
+			}
+			if result.Multiplexer != tt.wantMultiplexer {
+				t.Errorf("Multiplexer = %q, want %q", result.Multiplexer, tt.wantMultiplexer)
+			}
+		})
+	}
+}
+
 func TestParseShellFromPath(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -122,6 +284,26 @@ func TestParseShellFromPath(t *testing.T) {
 			shellPath: "/usr/local/bin/fish",
 			want:      ShellFish,
 		},
+		{
+			name:      "Nushell - /usr/bin/nu",
+			shellPath: "/usr/bin/nu",
+			want:      ShellNushell,
+		},
+		{
+			name:      "Elvish - /usr/local/bin/elvish",
+			shellPath: "/usr/local/bin/elvish",
+			want:      ShellElvish,
+		},
+		{
+			name:      "PowerShell - /usr/bin/pwsh",
+			shellPath: "/usr/bin/pwsh",
+			want:      ShellPwsh,
+		},
+		{
+			name:      "PowerShell - powershell",
+			shellPath: "/usr/local/bin/powershell",
+			want:      ShellPwsh,
+		},
 		{
 			name:      "Unknown - /bin/ksh",
 			shellPath: "/bin/ksh",
@@ -137,6 +319,26 @@ func TestParseShellFromPath(t *testing.T) {
 			shellPath: "/bin/tcsh",
 			want:      ShellUnknown,
 		},
+		{
+			name:      "Login shell - -zsh",
+			shellPath: "-zsh",
+			want:      ShellZsh,
+		},
+		{
+			name:      "Versioned - zsh-5.9",
+			shellPath: "/usr/local/bin/zsh-5.9",
+			want:      ShellZsh,
+		},
+		{
+			name:      "Unknown - ksh93",
+			shellPath: "/bin/ksh93",
+			want:      ShellUnknown,
+		},
+		{
+			name:      "Unknown - sh",
+			shellPath: "/bin/sh",
+			want:      ShellUnknown,
+		},
 	}
 
 	for _, tt := range tests {
@@ -202,16 +404,19 @@ func TestValidateShell(t *testing.T) {
 func TestGetSupportedShells(t *testing.T) {
 	shells := GetSupportedShells()
 
-	// Should return exactly 3 shells
-	if len(shells) != 3 {
-		t.Errorf("GetSupportedShells() returned %d shells, want 3", len(shells))
+	// Should return exactly 6 shells
+	if len(shells) != 6 {
+		t.Errorf("GetSupportedShells() returned %d shells, want 6", len(shells))
 	}
 
 	// Check that all expected shells are present
 	expected := map[ShellType]bool{
-		ShellBash: false,
-		ShellZsh:  false,
-		ShellFish: false,
+		ShellBash:    false,
+		ShellZsh:     false,
+		ShellFish:    false,
+		ShellNushell: false,
+		ShellElvish:  false,
+		ShellPwsh:    false,
 	}
 
 	for _, shell := range shells {
@@ -238,6 +443,9 @@ func TestShellType_String(t *testing.T) {
 		{ShellBash, "bash"},
 		{ShellZsh, "zsh"},
 		{ShellFish, "fish"},
+		{ShellNushell, "nu"},
+		{ShellElvish, "elvish"},
+		{ShellPwsh, "pwsh"},
 		{ShellUnknown, "unknown"},
 	}
 
@@ -258,6 +466,9 @@ func TestShellType_IsValid(t *testing.T) {
 		{ShellBash, true},
 		{ShellZsh, true},
 		{ShellFish, true},
+		{ShellNushell, true},
+		{ShellElvish, true},
+		{ShellPwsh, true},
 		{ShellUnknown, false},
 		{ShellType("ksh"), false},
 		{ShellType(""), false},
@@ -1,7 +1,7 @@
 // Package shell provides shell integration functionality for ZERB.
 //
 // This package handles:
-//   - Detecting the user's shell (bash, zsh, fish)
+//   - Detecting the user's shell (bash, zsh, fish, nu, elvish, pwsh)
 //   - Locating shell configuration files (rc files)
 //   - Generating activation commands for ZERB
 //   - Safely modifying shell configuration files
@@ -51,6 +51,25 @@
 // 5. Safely add activation line to rc file
 // 6. Verify activation works
 //
+// Manager.VerifyActivation performs step 6: it sources the rc file in an
+// interactive shell without a terminal and checks that zerb is on PATH and
+// that activation ran (`zerb activate` exports ZERB_ACTIVE=1). `zerb init`
+// and `zerb doctor` run it for the detected shell.
+//
+// Manager.SetupAll runs steps 3-5 for every bash, zsh and fish shell on the
+// machine (`zerb init --all-shells`), so a secondary shell is not forgotten.
+// It reports each shell separately and keeps going when one fails.
+//
+// PristineBackup finds the backup of an rc file taken before ZERB first
+// changed it, which PruneBackups never deletes; PreviewRestore and
+// RestoreBackup put it back (`zerb uninit --restore-rc`), refusing backups
+// that already contain the activation line and moving the current file
+// aside first.
+//
+// Manager.WithLogger reports each rc file change (file created, backup
+// taken, activation added or removed) to a Logger with the same methods as
+// config.Logger, e.g. a *slog.Logger.
+//
 // # Example Usage
 //
 //	// Create shell manager
@@ -65,4 +84,10 @@
 //	err = manager.SetupIntegration(shellType, shell.SetupOptions{
 //	    Interactive: true, // Prompt user before changes
 //	})
+//
+//	// Check that a new shell picks up the activation
+//	check, err := manager.VerifyActivation(ctx, shellType)
+//	if err == nil && !check.OK() {
+//	    fmt.Println(check.Message)
+//	}
 package shell
@@ -0,0 +1,68 @@
+package shell
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Output formats supported by FormatEnv
+const (
+	EnvFormatBash = "bash" // export NAME='value' (also valid in zsh and sh)
+	EnvFormatFish = "fish" // set -gx NAME 'value'
+	EnvFormatJSON = "json" // {"NAME": "value"}
+)
+
+// envNameRegex matches variable names every supported format can assign
+var envNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// FormatEnv renders env as statements to eval in the given format, one
+// variable per line sorted by name, or as a JSON object. Values are quoted
+// so they are never expanded by the shell.
+func FormatEnv(env map[string]string, format string) (string, error) {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		if !envNameRegex.MatchString(name) {
+			return "", fmt.Errorf("invalid environment variable name %q", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	switch format {
+	case EnvFormatBash:
+		for _, name := range names {
+			fmt.Fprintf(&sb, "export %s=%s\n", name, quotePOSIX(env[name]))
+		}
+	case EnvFormatFish:
+		for _, name := range names {
+			fmt.Fprintf(&sb, "set -gx %s %s\n", name, quoteFish(env[name]))
+		}
+	case EnvFormatJSON:
+		data, err := json.MarshalIndent(env, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("encode environment: %w", err)
+		}
+		sb.Write(data)
+		sb.WriteString("\n")
+	default:
+		return "", fmt.Errorf("unsupported format: %s (supported: %s, %s, %s)", format, EnvFormatBash, EnvFormatFish, EnvFormatJSON)
+	}
+	return sb.String(), nil
+}
+
+// quotePOSIX single-quotes s for POSIX shells, closing the quotes around
+// each embedded single quote and escaping it
+func quotePOSIX(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// quoteFish single-quotes s for fish, where only \ and ' are escaped
+// inside single quotes
+func quoteFish(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
@@ -0,0 +1,76 @@
+package shell
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatEnv(t *testing.T) {
+	env := map[string]string{
+		"PATH":      "/home/user/.config/zerb/installs/node/20.11.0/bin:/usr/bin",
+		"GREETING":  "it's $HOME",
+		"BACKSLASH": `C:\tools`,
+	}
+
+	tests := []struct {
+		name    string
+		format  string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "bash",
+			format: EnvFormatBash,
+			want: "export BACKSLASH='C:\\tools'\n" +
+				"export GREETING='it'\\''s $HOME'\n" +
+				"export PATH='/home/user/.config/zerb/installs/node/20.11.0/bin:/usr/bin'\n",
+		},
+		{
+			name:   "fish",
+			format: EnvFormatFish,
+			want: "set -gx BACKSLASH 'C:\\\\tools'\n" +
+				"set -gx GREETING 'it\\'s $HOME'\n" +
+				"set -gx PATH '/home/user/.config/zerb/installs/node/20.11.0/bin:/usr/bin'\n",
+		},
+		{
+			name:   "json",
+			format: EnvFormatJSON,
+			want: "{\n" +
+				"  \"BACKSLASH\": \"C:\\\\tools\",\n" +
+				"  \"GREETING\": \"it's $HOME\",\n" +
+				"  \"PATH\": \"/home/user/.config/zerb/installs/node/20.11.0/bin:/usr/bin\"\n" +
+				"}\n",
+		},
+		{
+			name:    "unsupported format",
+			format:  "nu",
+			wantErr: "unsupported format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatEnv(env, tt.format)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("FormatEnv() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FormatEnv() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("FormatEnv() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatEnv_InvalidName(t *testing.T) {
+	for _, name := range []string{"", "1PATH", "FOO;rm -rf", "A B"} {
+		if _, err := FormatEnv(map[string]string{name: "x"}, EnvFormatBash); err == nil {
+			t.Errorf("FormatEnv() with name %q succeeded, want error", name)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/fsutil"
+)
+
+// GetFragmentPath returns the path to the managed activation fragment for a
+// shell, e.g. ~/.config/zerb/activate.bash
+func GetFragmentPath(zerbDir string, shell ShellType) (string, error) {
+	if err := ValidateShell(shell); err != nil {
+		return "", err
+	}
+
+	// Security: Path must be absolute
+	if !filepath.IsAbs(zerbDir) {
+		return "", fmt.Errorf("ZERB directory must be absolute")
+	}
+
+	return filepath.Join(zerbDir, FragmentPrefix+fragmentExtension(shell)), nil
+}
+
+// fragmentExtension returns the fragment file extension for a shell.
+// PowerShell only dot-sources files ending in .ps1.
+func fragmentExtension(shell ShellType) string {
+	if shell == ShellPwsh {
+		return "ps1"
+	}
+	return shell.String()
+}
+
+// GenerateFragmentSourceLine generates the single line added to the RC file
+// in fragment mode. The line sources the fragment only if it exists, so
+// deleting the fragment never breaks shell startup.
+func GenerateFragmentSourceLine(shell ShellType, fragmentPath string) (string, error) {
+	if err := ValidateShell(shell); err != nil {
+		return "", err
+	}
+
+	// Security: The path is embedded in a double-quoted shell string
+	if strings.ContainsAny(fragmentPath, "\"$`\\\n") {
+		return "", fmt.Errorf("fragment path contains unsupported characters: %q", fragmentPath)
+	}
+
+	switch shell {
+	case ShellBash, ShellZsh:
+		return fmt.Sprintf(`[ -f "%s" ] && . "%s" %s`, fragmentPath, fragmentPath, FragmentMarker), nil
+	case ShellFish:
+		return fmt.Sprintf(`test -f "%s"; and source "%s" %s`, fragmentPath, fragmentPath, FragmentMarker), nil
+	case ShellElvish:
+		return fmt.Sprintf(`use path; if (path:is-regular "%s") { eval (slurp < "%s") } %s`, fragmentPath, fragmentPath, FragmentMarker), nil
+	case ShellPwsh:
+		return fmt.Sprintf(`if (Test-Path "%s") { . "%s" } %s`, fragmentPath, fragmentPath, FragmentMarker), nil
+	case ShellNushell:
+		// Nushell resolves `source` paths at parse time, so a missing
+		// fragment would break shell startup
+		return "", fmt.Errorf("fragment mode is not supported for %s; use inline activation", shell)
+	default:
+		return "", &UnsupportedShellError{Shell: shell.String()}
+	}
+}
+
+// WriteFragment writes the activation command to the fragment file.
+// This is an atomic operation using a temporary file.
+func WriteFragment(fragmentPath string, activationCommand string) error {
+	// Security: Validate activation command format
+	if !strings.Contains(activationCommand, ActivationMarker) {
+		return &RCFileError{
+			Path:    fragmentPath,
+			Message: "invalid activation command format",
+		}
+	}
+
+	// Security: Check for symlinks (prevent symlink attack)
+	if info, err := os.Lstat(fragmentPath); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return &RCFileError{
+			Path:    fragmentPath,
+			Message: "fragment file is a symlink (security risk)",
+		}
+	}
+
+	dir := filepath.Dir(fragmentPath)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return &RCFileError{
+			Path:    fragmentPath,
+			Message: "failed to create parent directory",
+			Cause:   err,
+		}
+	}
+
+	content := fmt.Sprintf("%s\n# Managed by ZERB; changes will be overwritten.\n%s\n", ActivationComment, activationCommand)
+	if err := fsutil.WriteFileAtomic(fragmentPath, []byte(content), 0644); err != nil {
+		return &RCFileError{
+			Path:    fragmentPath,
+			Message: "failed to write fragment",
+			Cause:   err,
+		}
+	}
+
+	return nil
+}
+
+// RemoveFragment deletes the fragment file.
+// Returns nil if the fragment doesn't exist (idempotent)
+func RemoveFragment(fragmentPath string) error {
+	if err := os.Remove(fragmentPath); err != nil && !os.IsNotExist(err) {
+		return &RCFileError{
+			Path:    fragmentPath,
+			Message: "failed to remove fragment",
+			Cause:   err,
+		}
+	}
+	return nil
+}
+
+// HasFragmentSourceLine checks if the RC file sources a ZERB activation fragment
+func HasFragmentSourceLine(rcPath string) (bool, error) {
+	return scanRCFile(rcPath, func(line string) bool {
+		return strings.Contains(line, FragmentMarker)
+	})
+}
+
+// IsActivationLine reports whether s contains a ZERB activation line in
+// either the inline or the fragment form
+func IsActivationLine(s string) bool {
+	return strings.Contains(s, ActivationMarker) || strings.Contains(s, FragmentMarker)
+}
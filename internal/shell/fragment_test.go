@@ -0,0 +1,202 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetFragmentPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		zerbDir string
+		shell   ShellType
+		want    string
+		wantErr bool
+	}{
+		{name: "bash", zerbDir: "/home/user/.config/zerb", shell: ShellBash, want: "/home/user/.config/zerb/activate.bash"},
+		{name: "fish", zerbDir: "/home/user/.config/zerb", shell: ShellFish, want: "/home/user/.config/zerb/activate.fish"},
+		{name: "pwsh", zerbDir: "/home/user/.config/zerb", shell: ShellPwsh, want: "/home/user/.config/zerb/activate.ps1"},
+		{name: "elvish", zerbDir: "/home/user/.config/zerb", shell: ShellElvish, want: "/home/user/.config/zerb/activate.elvish"},
+		{name: "relative dir", zerbDir: "zerb", shell: ShellZsh, wantErr: true},
+		{name: "unsupported shell", zerbDir: "/home/user/.config/zerb", shell: ShellUnknown, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetFragmentPath(tt.zerbDir, tt.shell)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetFragmentPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetFragmentPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateFragmentSourceLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		shell   ShellType
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "bash",
+			shell: ShellBash,
+			path:  "/home/user/.config/zerb/activate.bash",
+			want:  `[ -f "/home/user/.config/zerb/activate.bash" ] && . "/home/user/.config/zerb/activate.bash" # zerb-fragment`,
+		},
+		{
+			name:  "fish",
+			shell: ShellFish,
+			path:  "/home/user/.config/zerb/activate.fish",
+			want:  `test -f "/home/user/.config/zerb/activate.fish"; and source "/home/user/.config/zerb/activate.fish" # zerb-fragment`,
+		},
+		{
+			name:  "elvish",
+			shell: ShellElvish,
+			path:  "/home/user/.config/zerb/activate.elvish",
+			want:  `use path; if (path:is-regular "/home/user/.config/zerb/activate.elvish") { eval (slurp < "/home/user/.config/zerb/activate.elvish") } # zerb-fragment`,
+		},
+		{
+			name:  "pwsh",
+			shell: ShellPwsh,
+			path:  "/home/user/.config/zerb/activate.ps1",
+			want:  `if (Test-Path "/home/user/.config/zerb/activate.ps1") { . "/home/user/.config/zerb/activate.ps1" } # zerb-fragment`,
+		},
+		{
+			name:    "nushell unsupported",
+			shell:   ShellNushell,
+			path:    "/home/user/.config/zerb/activate.nu",
+			wantErr: true,
+		},
+		{
+			name:    "path with shell expansion",
+			shell:   ShellBash,
+			path:    "/home/$(whoami)/activate.bash",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateFragmentSourceLine(tt.shell, tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GenerateFragmentSourceLine() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateFragmentSourceLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteFragment(t *testing.T) {
+	fragmentPath := filepath.Join(t.TempDir(), "zerb", "activate.bash")
+
+	if err := WriteFragment(fragmentPath, `eval "$(zerb activate bash)"`); err != nil {
+		t.Fatalf("WriteFragment() error = %v", err)
+	}
+
+	content, err := os.ReadFile(fragmentPath)
+	if err != nil {
+		t.Fatalf("failed to read fragment: %v", err)
+	}
+	if !strings.Contains(string(content), `eval "$(zerb activate bash)"`) {
+		t.Errorf("fragment missing activation command:\n%s", content)
+	}
+
+	// Rewriting is safe and replaces the content
+	if err := WriteFragment(fragmentPath, `eval "$(zerb activate bash)"`); err != nil {
+		t.Fatalf("WriteFragment() second call error = %v", err)
+	}
+
+	if err := WriteFragment(fragmentPath, "rm -rf ~"); err == nil {
+		t.Error("WriteFragment() should reject a command without the activation marker")
+	}
+}
+
+func TestWriteFragment_Symlink(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, "target")
+	if err := os.WriteFile(target, []byte("original\n"), 0644); err != nil {
+		t.Fatalf("failed to write target: %v", err)
+	}
+	fragmentPath := filepath.Join(tmpDir, "activate.bash")
+	if err := os.Symlink(target, fragmentPath); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	if err := WriteFragment(fragmentPath, `eval "$(zerb activate bash)"`); err == nil {
+		t.Error("WriteFragment() should refuse to write through a symlink")
+	}
+}
+
+func TestRemoveFragment(t *testing.T) {
+	fragmentPath := filepath.Join(t.TempDir(), "activate.zsh")
+	if err := WriteFragment(fragmentPath, `eval "$(zerb activate zsh)"`); err != nil {
+		t.Fatalf("WriteFragment() error = %v", err)
+	}
+
+	if err := RemoveFragment(fragmentPath); err != nil {
+		t.Fatalf("RemoveFragment() error = %v", err)
+	}
+	if _, err := os.Stat(fragmentPath); !os.IsNotExist(err) {
+		t.Errorf("fragment still exists after removal")
+	}
+
+	// Idempotent
+	if err := RemoveFragment(fragmentPath); err != nil {
+		t.Errorf("RemoveFragment() on missing file error = %v", err)
+	}
+}
+
+func TestFragmentSourceLine_DetectAndRemove(t *testing.T) {
+	rcPath := filepath.Join(t.TempDir(), ".bashrc")
+	initial := "export PATH=$PATH:/usr/local/bin\n"
+	if err := os.WriteFile(rcPath, []byte(initial), 0644); err != nil {
+		t.Fatalf("failed to write rc file: %v", err)
+	}
+
+	sourceLine, err := GenerateFragmentSourceLine(ShellBash, "/home/user/.config/zerb/activate.bash")
+	if err != nil {
+		t.Fatalf("GenerateFragmentSourceLine() error = %v", err)
+	}
+	if _, err := AddActivationLine(rcPath, sourceLine); err != nil {
+		t.Fatalf("AddActivationLine() error = %v", err)
+	}
+
+	has, err := HasActivationLine(rcPath)
+	if err != nil || !has {
+		t.Errorf("HasActivationLine() = %v, %v; want true, nil", has, err)
+	}
+	sourced, err := HasFragmentSourceLine(rcPath)
+	if err != nil || !sourced {
+		t.Errorf("HasFragmentSourceLine() = %v, %v; want true, nil", sourced, err)
+	}
+
+	// Adding the inline form afterwards must not duplicate activation
+	changed, err := AddActivationLine(rcPath, `eval "$(zerb activate bash)"`)
+	if err != nil {
+		t.Fatalf("AddActivationLine() error = %v", err)
+	}
+	if changed {
+		t.Error("AddActivationLine() changed = true, want false")
+	}
+	content, _ := os.ReadFile(rcPath)
+	if strings.Contains(string(content), `eval "$(zerb activate bash)"`) {
+		t.Errorf("inline activation added despite fragment source line:\n%s", content)
+	}
+
+	if err := RemoveActivationLine(rcPath); err != nil {
+		t.Fatalf("RemoveActivationLine() error = %v", err)
+	}
+	content, _ = os.ReadFile(rcPath)
+	if string(content) != initial {
+		t.Errorf("content after removal = %q, want %q", content, initial)
+	}
+}
@@ -0,0 +1,65 @@
+package shell
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// recordingLogger records the messages logged at each level
+type recordingLogger struct {
+	messages []string
+}
+
+func (r *recordingLogger) Debug(msg string, keysAndValues ...interface{}) {
+	r.messages = append(r.messages, "debug: "+msg)
+}
+func (r *recordingLogger) Info(msg string, keysAndValues ...interface{}) {
+	r.messages = append(r.messages, "info: "+msg)
+}
+func (r *recordingLogger) Warn(msg string, keysAndValues ...interface{}) {
+	r.messages = append(r.messages, "warn: "+msg)
+}
+func (r *recordingLogger) Error(msg string, keysAndValues ...interface{}) {
+	r.messages = append(r.messages, "error: "+msg)
+}
+
+func TestManager_WithLogger(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.WriteFile(filepath.Join(home, ".bashrc"), []byte("alias ll='ls -la'\n"), 0644); err != nil {
+		t.Fatalf("failed to write rc file: %v", err)
+	}
+
+	manager, err := NewManager(Config{ZerbDir: filepath.Join(home, ".config", "zerb")})
+	if err != nil {
+		t.Fatalf("NewManager() failed: %v", err)
+	}
+	logger := &recordingLogger{}
+	manager.WithLogger(logger)
+	ctx := context.Background()
+
+	if _, err := manager.SetupIntegration(ctx, ShellBash, SetupOptions{Backup: true}); err != nil {
+		t.Fatalf("SetupIntegration() error = %v", err)
+	}
+	if _, err := manager.SetupIntegration(ctx, ShellBash, SetupOptions{}); err != nil {
+		t.Fatalf("second SetupIntegration() error = %v", err)
+	}
+	if err := manager.RemoveIntegration(ctx, ShellBash); err != nil {
+		t.Fatalf("RemoveIntegration() error = %v", err)
+	}
+
+	want := []string{
+		"debug: setting up shell integration",
+		"info: rc backup created",
+		"info: activation added",
+		"debug: setting up shell integration",
+		"debug: activation already present",
+		"info: activation removed",
+	}
+	if !reflect.DeepEqual(logger.messages, want) {
+		t.Errorf("logged %q, want %q", logger.messages, want)
+	}
+}
@@ -3,11 +3,17 @@ package shell
 import (
 	"context"
 	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
 )
 
 // Manager orchestrates shell integration setup
 type Manager struct {
 	zerbDir string
+	logger  Logger
+	clock   clock.Clock
 }
 
 // NewManager creates a new shell manager
@@ -18,10 +24,33 @@ func NewManager(config Config) (*Manager, error) {
 
 	return &Manager{
 		zerbDir: config.ZerbDir,
+		logger:  defaultLogger(),
+		clock:   clock.Real{},
 	}, nil
 }
 
-// SetupIntegration sets up shell integration for the user's shell
+// WithLogger sets the logger that rc file changes are reported to
+// (default: no logging). Returns the manager for method chaining.
+func (m *Manager) WithLogger(logger Logger) *Manager {
+	if logger == nil {
+		logger = defaultLogger()
+	}
+	m.logger = logger
+	return m
+}
+
+// WithClock sets the clock that names rc file backups and returns the
+// manager for method chaining.
+func (m *Manager) WithClock(clk clock.Clock) *Manager {
+	m.clock = clk
+	return m
+}
+
+// SetupIntegration sets up shell integration for the user's shell.
+// Activation already present in the other form is converted: with
+// FragmentMode an inline activation line is replaced by the fragment source
+// line, and without it the fragment source line is replaced by the inline
+// activation line and the fragment file removed.
 func (m *Manager) SetupIntegration(ctx context.Context, shell ShellType, opts SetupOptions) (*SetupResult, error) {
 	// Check context cancellation
 	if err := ctx.Err(); err != nil {
@@ -44,72 +73,234 @@ func (m *Manager) SetupIntegration(ctx context.Context, shell ShellType, opts Se
 		return nil, fmt.Errorf("check RC file: %w", err)
 	}
 
-	// Create RC file if it doesn't exist
-	if !exists {
-		if err := CreateRCFile(rcPath); err != nil {
-			return nil, fmt.Errorf("create RC file: %w", err)
-		}
-	}
-
 	// Check if activation line already exists
 	hasActivation, err := HasActivationLine(rcPath)
 	if err != nil {
 		return nil, fmt.Errorf("check activation line: %w", err)
 	}
 
+	// Generate activation command, and the fragment source line in fragment mode
+	activationCmd, err := GenerateActivationCommand(shell)
+	if err != nil {
+		return nil, fmt.Errorf("generate activation command: %w", err)
+	}
+
+	rcLine := activationCmd
+	var fragmentPath string
+	if opts.FragmentMode {
+		fragmentPath, err = GetFragmentPath(m.zerbDir, shell)
+		if err != nil {
+			return nil, fmt.Errorf("get fragment path: %w", err)
+		}
+		rcLine, err = GenerateFragmentSourceLine(shell, fragmentPath)
+		if err != nil {
+			return nil, fmt.Errorf("generate fragment source line: %w", err)
+		}
+	}
+
+	// Activation in the other form (inline instead of fragment, or the
+	// reverse) is converted to the requested one
+	convert := false
+	if hasActivation {
+		convert, err = scanRCFile(rcPath, func(line string) bool {
+			if opts.FragmentMode {
+				return strings.Contains(line, ActivationMarker)
+			}
+			return strings.Contains(line, FragmentMarker)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("check activation form: %w", err)
+		}
+	}
+
+	m.logger.Debug("setting up shell integration",
+		"shell", string(shell), "rc_file", rcPath, "rc_exists", exists,
+		"activation_present", hasActivation, "convert", convert,
+		"fragment", opts.FragmentMode, "dry_run", opts.DryRun)
+
 	// If already present and not forcing, return early
-	if hasActivation && !opts.Force {
-		activationCmd, _ := GenerateActivationCommand(shell)
+	if hasActivation && !convert && !opts.Force {
+		// Restore a deleted or stale fragment the rc file still sources
+		if opts.FragmentMode && !opts.DryRun {
+			sourced, err := HasFragmentSourceLine(rcPath)
+			if err != nil {
+				return nil, fmt.Errorf("check fragment source line: %w", err)
+			}
+			if sourced {
+				if err := WriteFragment(fragmentPath, activationCmd); err != nil {
+					return nil, fmt.Errorf("write activation fragment: %w", err)
+				}
+			}
+		}
+		m.logger.Debug("activation already present", "shell", string(shell), "rc_file", rcPath)
 		return &SetupResult{
 			Shell:             shell,
 			RCFile:            rcPath,
 			Added:             false,
 			AlreadyPresent:    true,
-			ActivationCommand: activationCmd,
+			ActivationCommand: rcLine,
+			FragmentPath:      fragmentPath,
 		}, nil
 	}
 
-	// Generate activation command
-	activationCmd, err := GenerateActivationCommand(shell)
-	if err != nil {
-		return nil, fmt.Errorf("generate activation command: %w", err)
+	// Fail early with guidance if the RC file location is read-only,
+	// rather than with a confusing error deep in the atomic write
+	if !opts.DryRun {
+		if err := CheckRCFileWritable(rcPath); err != nil {
+			return nil, err
+		}
+	}
+
+	// Create RC file if it doesn't exist
+	if !exists && !opts.DryRun {
+		if err := CreateRCFile(rcPath); err != nil {
+			return nil, fmt.Errorf("create RC file: %w", err)
+		}
+		m.logger.Info("rc file created", "shell", string(shell), "rc_file", rcPath)
 	}
 
-	// Backup RC file if requested
+	// Backup RC file if requested. A dry run only reports the backup it
+	// would take (nothing to back up for a missing file)
 	var backupPath string
-	if opts.Backup {
-		backupPath, err = BackupRCFile(rcPath)
+	var pruned []string
+	if opts.Backup && !opts.DryRun {
+		backupPath, err = BackupRCFile(rcPath, m.clock)
 		if err != nil {
 			return nil, fmt.Errorf("backup RC file: %w", err)
 		}
+		m.logger.Info("rc backup created", "rc_file", rcPath, "backup", backupPath)
+		pruned, err = PruneBackups(rcPath, opts.BackupRetention)
+		if err != nil {
+			return nil, fmt.Errorf("prune RC file backups: %w", err)
+		}
+		if len(pruned) > 0 {
+			m.logger.Debug("old rc backups pruned", "rc_file", rcPath, "count", len(pruned))
+		}
+	} else if opts.Backup && exists {
+		backupPath = BackupPath(rcPath, m.clock.Now())
 	}
 
-	// Add activation line
-	if !opts.DryRun {
-		if err := AddActivationLine(rcPath, activationCmd); err != nil {
-			return nil, fmt.Errorf("add activation line: %w", err)
+	if opts.DryRun {
+		preview := PreviewActivationLine
+		if convert {
+			preview = PreviewReplaceActivationLine
 		}
-
-		// Verify the activation line was added successfully
-		verified, err := HasActivationLine(rcPath)
+		change, err := preview(rcPath, rcLine)
 		if err != nil {
-			return nil, fmt.Errorf("verify activation line: %w", err)
+			return nil, fmt.Errorf("preview activation line: %w", err)
+		}
+		result := &SetupResult{
+			Shell:             shell,
+			RCFile:            rcPath,
+			AlreadyPresent:    hasActivation,
+			BackupPath:        backupPath,
+			Converted:         convert && change.Changed,
+			ActivationCommand: rcLine,
+			FragmentPath:      fragmentPath,
+			NewContent:        change.NewContent,
+		}
+		if change.Changed {
+			result.Diff = dryRunPreamble(rcPath, backupPath) + change.Diff
+		}
+		return result, nil
+	}
+
+	// Add activation line (writing the fragment first, so the rc file never
+	// sources a fragment that doesn't exist yet)
+	if opts.FragmentMode {
+		if err := WriteFragment(fragmentPath, activationCmd); err != nil {
+			return nil, fmt.Errorf("write activation fragment: %w", err)
+		}
+	}
+
+	var added bool
+	if convert {
+		added, err = ReplaceActivationLine(rcPath, rcLine)
+	} else {
+		added, err = AddActivationLine(rcPath, rcLine)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("add activation line: %w", err)
+	}
+
+	// Verify the activation line was added successfully
+	verified, err := HasActivationLine(rcPath)
+	if err != nil {
+		return nil, fmt.Errorf("verify activation line: %w", err)
+	}
+	if !verified {
+		m.logger.Error("activation line missing after adding", "rc_file", rcPath)
+		return nil, fmt.Errorf("verification failed: activation line not found after adding")
+	}
+	// The rc file no longer sources a fragment converted to inline form
+	if convert && !opts.FragmentMode {
+		oldFragment, err := GetFragmentPath(m.zerbDir, shell)
+		if err == nil {
+			err = RemoveFragment(oldFragment)
 		}
-		if !verified {
-			return nil, fmt.Errorf("verification failed: activation line not found after adding")
+		if err != nil {
+			return nil, fmt.Errorf("remove activation fragment: %w", err)
 		}
 	}
+	m.logger.Info("activation added", "shell", string(shell), "rc_file", rcPath, "added", added,
+		"converted", convert, "fragment", fragmentPath)
 
 	return &SetupResult{
 		Shell:             shell,
 		RCFile:            rcPath,
-		Added:             !opts.DryRun,
+		Added:             added,
 		AlreadyPresent:    hasActivation,
+		Converted:         convert,
 		BackupPath:        backupPath,
-		ActivationCommand: activationCmd,
+		PrunedBackups:     pruned,
+		ActivationCommand: rcLine,
+		FragmentPath:      fragmentPath,
 	}, nil
 }
 
+// dryRunPreamble describes how a dry-run change would be applied: the
+// backup that would be taken and the atomic write
+func dryRunPreamble(rcPath, backupPath string) string {
+	var sb strings.Builder
+	if backupPath != "" {
+		fmt.Fprintf(&sb, "# Backup: %s (would be created)\n", backupPath)
+	}
+	fmt.Fprintf(&sb, "# Write: atomic (temporary file renamed over %s)\n", rcPath)
+	return sb.String()
+}
+
+// RemoveIntegration removes shell integration for a shell, in either the
+// inline or the fragment form. The rc line is removed first and then the
+// fragment file, so the shell never sources a missing fragment.
+// Returns nil if no integration is present (idempotent)
+func (m *Manager) RemoveIntegration(ctx context.Context, shell ShellType) error {
+	// Check context cancellation
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+
+	rcPath, err := GetRCFilePath(shell)
+	if err != nil {
+		return fmt.Errorf("get RC file path: %w", err)
+	}
+
+	if err := RemoveActivationLine(rcPath); err != nil {
+		return fmt.Errorf("remove activation line: %w", err)
+	}
+
+	fragmentPath, err := GetFragmentPath(m.zerbDir, shell)
+	if err != nil {
+		return fmt.Errorf("get fragment path: %w", err)
+	}
+
+	if err := RemoveFragment(fragmentPath); err != nil {
+		return fmt.Errorf("remove activation fragment: %w", err)
+	}
+
+	m.logger.Info("activation removed", "shell", string(shell), "rc_file", rcPath)
+	return nil
+}
+
 // DetectAndSetup detects the user's shell and sets up integration
 func (m *Manager) DetectAndSetup(ctx context.Context, opts SetupOptions) (*SetupResult, error) {
 	// Check context cancellation
@@ -130,3 +321,74 @@ func (m *Manager) DetectAndSetup(ctx context.Context, opts SetupOptions) (*Setup
 	// Setup integration
 	return m.SetupIntegration(ctx, detection.Shell, opts)
 }
+
+// setupAllShells are the shells SetupAll wires up
+var setupAllShells = []ShellType{ShellBash, ShellZsh, ShellFish}
+
+// SetupAll sets up integration for every bash, zsh and fish shell on this
+// machine: each shell whose rc file exists or that is installed (on PATH).
+// Missing rc files are created and every rc file is backed up before it
+// is changed. A failure for one shell is reported in its SetupReport and
+// does not stop the others; the returned error is only for a cancelled ctx.
+func (m *Manager) SetupAll(ctx context.Context, opts SetupOptions) ([]SetupReport, error) {
+	opts.Backup = true
+
+	var reports []SetupReport
+	for _, shell := range setupAllShells {
+		if err := ctx.Err(); err != nil {
+			return reports, fmt.Errorf("context cancelled: %w", err)
+		}
+
+		report := SetupReport{Shell: shell}
+
+		found, err := shellPresent(shell)
+		if err != nil {
+			report.Status = SetupFailed
+			report.Err = err
+			reports = append(reports, report)
+			continue
+		}
+		if !found {
+			report.Status = SetupSkipped
+			report.Reason = fmt.Sprintf("%s is not installed and has no rc file", shell)
+			reports = append(reports, report)
+			continue
+		}
+
+		result, err := m.SetupIntegration(ctx, shell, opts)
+		switch {
+		case err != nil:
+			m.logger.Warn("shell integration failed", "shell", string(shell), "error", err)
+			report.Status = SetupFailed
+			report.Err = err
+		case result.Added || (opts.DryRun && !result.AlreadyPresent):
+			// A dry run writes nothing; report what would be added
+			report.Status = SetupAdded
+			report.Result = result
+		default:
+			report.Status = SetupSkipped
+			report.Reason = "activation already present"
+			report.Result = result
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// shellPresent reports whether shell's rc file exists or the shell is on PATH
+func shellPresent(shell ShellType) (bool, error) {
+	rcPath, err := GetRCFilePath(shell)
+	if err != nil {
+		return false, fmt.Errorf("get RC file path: %w", err)
+	}
+	exists, err := RCFileExists(rcPath)
+	if err != nil {
+		return false, fmt.Errorf("check RC file: %w", err)
+	}
+	if exists {
+		return true, nil
+	}
+	_, err = exec.LookPath(string(shell))
+	return err == nil, nil
+}
@@ -2,11 +2,16 @@ package shell
 
 import (
 	"context"
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
 )
 
 func TestNewManager(t *testing.T) {
@@ -197,3 +202,446 @@ func TestSetupIntegration_Force(t *testing.T) {
 	// TODO: This test should verify that force mode adds even when
 	// activation already exists
 }
+
+// readOnlyHome makes creating files in home fail with a permission error
+// for the rest of the test, as a read-only home does (even for root)
+func readOnlyHome(t *testing.T, home string) {
+	t.Helper()
+	old := createTemp
+	createTemp = func(dir, pattern string) (*os.File, error) {
+		if dir == home {
+			return nil, &fs.PathError{Op: "open", Path: filepath.Join(dir, pattern), Err: fs.ErrPermission}
+		}
+		return old(dir, pattern)
+	}
+	t.Cleanup(func() { createTemp = old })
+}
+
+// TestSetupIntegration_ReadOnlyHome tests that a read-only home directory
+// produces ErrHomeReadOnly up front instead of a raw OS error
+func TestSetupIntegration_ReadOnlyHome(t *testing.T) {
+	home := t.TempDir()
+	readOnlyHome(t, home)
+	t.Setenv("HOME", home)
+
+	manager, err := NewManager(Config{ZerbDir: filepath.Join(t.TempDir(), "zerb")})
+	if err != nil {
+		t.Fatalf("NewManager() failed: %v", err)
+	}
+
+	_, err = manager.SetupIntegration(context.Background(), ShellBash, SetupOptions{Backup: true})
+	if err == nil {
+		t.Fatal("SetupIntegration() error = nil, want ErrHomeReadOnly")
+	}
+	if !errors.Is(err, ErrHomeReadOnly) {
+		t.Errorf("SetupIntegration() error = %v, want ErrHomeReadOnly", err)
+	}
+	if !strings.Contains(err.Error(), "ZERB_DIR") {
+		t.Errorf("error should include guidance, got: %v", err)
+	}
+
+	// Dry run must not probe or fail
+	if _, err := manager.SetupIntegration(context.Background(), ShellBash, SetupOptions{DryRun: true}); err != nil {
+		t.Errorf("SetupIntegration(DryRun) error = %v, want nil", err)
+	}
+}
+
+// TestSetupIntegration_ReadOnlyHome_AlreadyPresent tests that an existing
+// activation line is still reported when the home directory is read-only
+func TestSetupIntegration_ReadOnlyHome_AlreadyPresent(t *testing.T) {
+	home := t.TempDir()
+	rcPath := filepath.Join(home, ".bashrc")
+	if err := os.WriteFile(rcPath, []byte("eval \"$(zerb activate bash)\"\n"), 0644); err != nil {
+		t.Fatalf("failed to write rc file: %v", err)
+	}
+	readOnlyHome(t, home)
+	t.Setenv("HOME", home)
+
+	manager, err := NewManager(Config{ZerbDir: filepath.Join(t.TempDir(), "zerb")})
+	if err != nil {
+		t.Fatalf("NewManager() failed: %v", err)
+	}
+
+	result, err := manager.SetupIntegration(context.Background(), ShellBash, SetupOptions{})
+	if err != nil {
+		t.Fatalf("SetupIntegration() error = %v", err)
+	}
+	if !result.AlreadyPresent {
+		t.Error("AlreadyPresent = false, want true")
+	}
+}
+
+// TestSetupIntegration_FragmentMode tests that fragment mode writes the
+// activation to a fragment file and only a source line to the rc file
+func TestSetupIntegration_FragmentMode(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	zerbDir := filepath.Join(home, ".config", "zerb")
+
+	manager, err := NewManager(Config{ZerbDir: zerbDir})
+	if err != nil {
+		t.Fatalf("NewManager() failed: %v", err)
+	}
+	ctx := context.Background()
+
+	result, err := manager.SetupIntegration(ctx, ShellZsh, SetupOptions{FragmentMode: true})
+	if err != nil {
+		t.Fatalf("SetupIntegration() error = %v", err)
+	}
+	if !result.Added {
+		t.Error("Added = false, want true")
+	}
+	wantFragment := filepath.Join(zerbDir, "activate.zsh")
+	if result.FragmentPath != wantFragment {
+		t.Errorf("FragmentPath = %q, want %q", result.FragmentPath, wantFragment)
+	}
+
+	fragment, err := os.ReadFile(wantFragment)
+	if err != nil {
+		t.Fatalf("failed to read fragment: %v", err)
+	}
+	if !strings.Contains(string(fragment), `eval "$(zerb activate zsh)"`) {
+		t.Errorf("fragment missing activation command:\n%s", fragment)
+	}
+
+	rc, err := os.ReadFile(filepath.Join(home, ".zshrc"))
+	if err != nil {
+		t.Fatalf("failed to read rc file: %v", err)
+	}
+	if strings.Contains(string(rc), ActivationMarker) {
+		t.Errorf("rc file contains inline activation in fragment mode:\n%s", rc)
+	}
+	if !strings.Contains(string(rc), wantFragment) || !strings.Contains(string(rc), FragmentMarker) {
+		t.Errorf("rc file missing fragment source line:\n%s", rc)
+	}
+
+	// A deleted fragment is restored on the next setup, without touching the rc file
+	if err := os.Remove(wantFragment); err != nil {
+		t.Fatalf("failed to remove fragment: %v", err)
+	}
+	result, err = manager.SetupIntegration(ctx, ShellZsh, SetupOptions{FragmentMode: true})
+	if err != nil {
+		t.Fatalf("second SetupIntegration() error = %v", err)
+	}
+	if !result.AlreadyPresent {
+		t.Error("AlreadyPresent = false, want true")
+	}
+	if _, err := os.Stat(wantFragment); err != nil {
+		t.Errorf("fragment not restored: %v", err)
+	}
+	rcAfter, _ := os.ReadFile(filepath.Join(home, ".zshrc"))
+	if string(rcAfter) != string(rc) {
+		t.Errorf("rc file changed on second setup:\n%s", rcAfter)
+	}
+}
+
+// TestSetupIntegration_FragmentMode_DryRun tests that a fragment-mode dry run writes nothing
+func TestSetupIntegration_FragmentMode_DryRun(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	zerbDir := filepath.Join(home, ".config", "zerb")
+
+	manager, err := NewManager(Config{ZerbDir: zerbDir})
+	if err != nil {
+		t.Fatalf("NewManager() failed: %v", err)
+	}
+
+	result, err := manager.SetupIntegration(context.Background(), ShellBash, SetupOptions{FragmentMode: true, DryRun: true})
+	if err != nil {
+		t.Fatalf("SetupIntegration() error = %v", err)
+	}
+	if !strings.Contains(result.ActivationCommand, FragmentMarker) {
+		t.Errorf("ActivationCommand = %q, want fragment source line", result.ActivationCommand)
+	}
+	if _, err := os.Stat(result.FragmentPath); !os.IsNotExist(err) {
+		t.Error("fragment written during dry run")
+	}
+	if _, err := os.Stat(filepath.Join(home, ".bashrc")); !os.IsNotExist(err) {
+		t.Error("rc file created during dry run")
+	}
+}
+
+// TestSetupIntegration_FragmentConversion tests converting activation
+// between the inline and fragment forms
+func TestSetupIntegration_FragmentConversion(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	zerbDir := filepath.Join(home, ".config", "zerb")
+	rcPath := filepath.Join(home, ".bashrc")
+	fragmentPath := filepath.Join(zerbDir, "activate.bash")
+	if err := os.WriteFile(rcPath, []byte("alias ll='ls -la'\n"), 0644); err != nil {
+		t.Fatalf("failed to write rc file: %v", err)
+	}
+
+	manager, err := NewManager(Config{ZerbDir: zerbDir})
+	if err != nil {
+		t.Fatalf("NewManager() failed: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := manager.SetupIntegration(ctx, ShellBash, SetupOptions{}); err != nil {
+		t.Fatalf("inline SetupIntegration() error = %v", err)
+	}
+	inline, _ := os.ReadFile(rcPath)
+
+	tests := []struct {
+		name         string
+		fragmentMode bool
+		dryRun       bool
+		wantInline   bool
+		wantFragment bool // fragment file exists afterwards
+	}{
+		{name: "inline to fragment dry run", fragmentMode: true, dryRun: true, wantInline: true},
+		{name: "inline to fragment", fragmentMode: true, wantFragment: true},
+		{name: "fragment to inline", fragmentMode: false, wantInline: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := manager.SetupIntegration(ctx, ShellBash, SetupOptions{FragmentMode: tt.fragmentMode, DryRun: tt.dryRun})
+			if err != nil {
+				t.Fatalf("SetupIntegration() error = %v", err)
+			}
+			if !result.Converted {
+				t.Error("Converted = false, want true")
+			}
+
+			rc, err := os.ReadFile(rcPath)
+			if err != nil {
+				t.Fatalf("failed to read rc file: %v", err)
+			}
+			if !strings.HasPrefix(string(rc), "alias ll='ls -la'\n") {
+				t.Errorf("rc file lost its content:\n%s", rc)
+			}
+			if got := strings.Contains(string(rc), ActivationMarker); got != tt.wantInline {
+				t.Errorf("rc file has inline activation = %v, want %v:\n%s", got, tt.wantInline, rc)
+			}
+			if got := strings.Contains(string(rc), FragmentMarker); got == tt.wantInline {
+				t.Errorf("rc file has fragment source line = %v, want %v:\n%s", got, !tt.wantInline, rc)
+			}
+			if strings.Count(string(rc), ActivationComment) != 1 {
+				t.Errorf("rc file should have one ZERB block:\n%s", rc)
+			}
+			if _, err := os.Stat(fragmentPath); (err == nil) != tt.wantFragment {
+				t.Errorf("fragment exists = %v, want %v", err == nil, tt.wantFragment)
+			}
+			if tt.dryRun && !strings.Contains(result.Diff, FragmentMarker) {
+				t.Errorf("dry run diff missing fragment source line:\n%s", result.Diff)
+			}
+		})
+	}
+
+	// Converting back restores the original inline block
+	rc, _ := os.ReadFile(rcPath)
+	if string(rc) != string(inline) {
+		t.Errorf("rc file after round trip:\n%s\nwant:\n%s", rc, inline)
+	}
+}
+
+// TestManager_RemoveIntegration tests removal of both inline and fragment forms
+func TestManager_RemoveIntegration(t *testing.T) {
+	for _, fragmentMode := range []bool{false, true} {
+		name := "inline"
+		if fragmentMode {
+			name = "fragment"
+		}
+		t.Run(name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			rcPath := filepath.Join(home, ".bashrc")
+			original := "alias ll='ls -la'\n"
+			if err := os.WriteFile(rcPath, []byte(original), 0644); err != nil {
+				t.Fatalf("failed to write rc file: %v", err)
+			}
+
+			manager, err := NewManager(Config{ZerbDir: filepath.Join(home, ".config", "zerb")})
+			if err != nil {
+				t.Fatalf("NewManager() failed: %v", err)
+			}
+			ctx := context.Background()
+
+			result, err := manager.SetupIntegration(ctx, ShellBash, SetupOptions{FragmentMode: fragmentMode})
+			if err != nil {
+				t.Fatalf("SetupIntegration() error = %v", err)
+			}
+
+			if err := manager.RemoveIntegration(ctx, ShellBash); err != nil {
+				t.Fatalf("RemoveIntegration() error = %v", err)
+			}
+
+			has, err := HasActivationLine(rcPath)
+			if err != nil || has {
+				t.Errorf("HasActivationLine() = %v, %v; want false, nil", has, err)
+			}
+			content, _ := os.ReadFile(rcPath)
+			if string(content) != original {
+				t.Errorf("rc content = %q, want %q", content, original)
+			}
+			if fragmentMode {
+				if _, err := os.Stat(result.FragmentPath); !os.IsNotExist(err) {
+					t.Error("fragment file still exists after removal")
+				}
+			}
+
+			// Idempotent
+			if err := manager.RemoveIntegration(ctx, ShellBash); err != nil {
+				t.Errorf("second RemoveIntegration() error = %v", err)
+			}
+		})
+	}
+}
+
+// TestManager_SetupAll tests that SetupAll wires up every present shell,
+// is idempotent per rc file and keeps going after a failure
+func TestManager_SetupAll(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// zsh is installed but has no rc file yet; fish is neither
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "zsh"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("failed to write fake zsh: %v", err)
+	}
+	t.Setenv("PATH", binDir)
+
+	bashrc := filepath.Join(home, ".bashrc")
+	if err := os.WriteFile(bashrc, []byte("# my bashrc\n"), 0644); err != nil {
+		t.Fatalf("failed to write .bashrc: %v", err)
+	}
+
+	manager, err := NewManager(Config{ZerbDir: filepath.Join(t.TempDir(), "zerb")})
+	if err != nil {
+		t.Fatalf("NewManager() failed: %v", err)
+	}
+
+	statuses := func(reports []SetupReport) map[ShellType]SetupStatus {
+		got := make(map[ShellType]SetupStatus)
+		for _, r := range reports {
+			got[r.Shell] = r.Status
+		}
+		return got
+	}
+
+	reports, err := manager.SetupAll(context.Background(), SetupOptions{})
+	if err != nil {
+		t.Fatalf("SetupAll() error = %v", err)
+	}
+	want := map[ShellType]SetupStatus{ShellBash: SetupAdded, ShellZsh: SetupAdded, ShellFish: SetupSkipped}
+	if got := statuses(reports); !reflect.DeepEqual(got, want) {
+		t.Errorf("SetupAll() statuses = %v, want %v", got, want)
+	}
+	for _, r := range reports {
+		if r.Status == SetupAdded && r.Result.BackupPath == "" {
+			t.Errorf("%s: rc file was not backed up", r.Shell)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(home, ".config", "fish")); !os.IsNotExist(err) {
+		t.Errorf("fish config should not be created, stat error = %v", err)
+	}
+
+	// Running again changes nothing
+	reports, err = manager.SetupAll(context.Background(), SetupOptions{})
+	if err != nil {
+		t.Fatalf("second SetupAll() error = %v", err)
+	}
+	want = map[ShellType]SetupStatus{ShellBash: SetupSkipped, ShellZsh: SetupSkipped, ShellFish: SetupSkipped}
+	if got := statuses(reports); !reflect.DeepEqual(got, want) {
+		t.Errorf("second SetupAll() statuses = %v, want %v", got, want)
+	}
+	for _, rc := range []string{bashrc, filepath.Join(home, ".zshrc")} {
+		content, err := os.ReadFile(rc)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", rc, err)
+		}
+		if n := strings.Count(string(content), "zerb activate"); n != 1 {
+			t.Errorf("%s has %d activation lines, want 1", rc, n)
+		}
+	}
+}
+
+// TestManager_SetupAll_PartialFailure tests that a failure for one shell
+// does not stop the others
+func TestManager_SetupAll_PartialFailure(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("PATH", t.TempDir())
+
+	// A directory where .bashrc should be makes bash fail
+	if err := os.Mkdir(filepath.Join(home, ".bashrc"), 0755); err != nil {
+		t.Fatalf("failed to create .bashrc directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".zshrc"), nil, 0644); err != nil {
+		t.Fatalf("failed to write .zshrc: %v", err)
+	}
+
+	manager, err := NewManager(Config{ZerbDir: filepath.Join(t.TempDir(), "zerb")})
+	if err != nil {
+		t.Fatalf("NewManager() failed: %v", err)
+	}
+
+	reports, err := manager.SetupAll(context.Background(), SetupOptions{})
+	if err != nil {
+		t.Fatalf("SetupAll() error = %v", err)
+	}
+	if len(reports) != 3 {
+		t.Fatalf("SetupAll() returned %d reports, want 3", len(reports))
+	}
+	if reports[0].Status != SetupFailed || reports[0].Err == nil {
+		t.Errorf("bash report = %+v, want failure", reports[0])
+	}
+	if reports[1].Status != SetupAdded {
+		t.Errorf("zsh report = %+v, want added", reports[1])
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := manager.SetupAll(ctx, SetupOptions{}); err == nil {
+		t.Error("SetupAll() should fail with cancelled context")
+	}
+}
+
+// TestSetupIntegration_DryRunDiff tests that a dry run writes nothing and
+// reports the diff, the backup it would take and the atomic write
+func TestSetupIntegration_DryRunDiff(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	rcPath := filepath.Join(home, ".zshrc")
+	if err := os.WriteFile(rcPath, []byte("export EDITOR=vim\n"), 0644); err != nil {
+		t.Fatalf("failed to write .zshrc: %v", err)
+	}
+
+	manager, err := NewManager(Config{ZerbDir: filepath.Join(t.TempDir(), "zerb")})
+	if err != nil {
+		t.Fatalf("NewManager() failed: %v", err)
+	}
+	manager.WithClock(clock.NewFake(time.Date(2025, 1, 2, 3, 4, 5, 0, time.Local)))
+
+	result, err := manager.SetupIntegration(context.Background(), ShellZsh, SetupOptions{DryRun: true, Backup: true})
+	if err != nil {
+		t.Fatalf("SetupIntegration() error = %v", err)
+	}
+
+	if want := rcPath + BackupSuffix + ".20250102-030405"; result.BackupPath != want {
+		t.Errorf("BackupPath = %q, want %q", result.BackupPath, want)
+	}
+	for _, want := range []string{
+		"# Backup: " + result.BackupPath + " (would be created)\n",
+		"# Write: atomic (temporary file renamed over " + rcPath + ")\n",
+		" export EDITOR=vim\n",
+		"+eval \"$(zerb activate zsh)\"\n",
+	} {
+		if !strings.Contains(result.Diff, want) {
+			t.Errorf("Diff missing %q:\n%s", want, result.Diff)
+		}
+	}
+
+	content, err := os.ReadFile(rcPath)
+	if err != nil {
+		t.Fatalf("failed to read .zshrc: %v", err)
+	}
+	if string(content) != "export EDITOR=vim\n" {
+		t.Errorf(".zshrc changed to %q", content)
+	}
+	backups, _ := filepath.Glob(rcPath + BackupSuffix + ".*")
+	if len(backups) != 0 {
+		t.Errorf("dry run created backups: %v", backups)
+	}
+}
@@ -2,11 +2,19 @@ package shell
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"syscall"
 	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
+	"github.com/ZebulonRouseFrantzich/zerb/internal/fsutil"
 )
 
 // GetRCFilePath returns the path to the shell's RC file
@@ -33,6 +41,12 @@ func GetRCFilePath(shell ShellType) (string, error) {
 		rcPath = filepath.Join(homeDir, ".zshrc")
 	case ShellFish:
 		rcPath = filepath.Join(homeDir, ".config", "fish", "config.fish")
+	case ShellNushell:
+		rcPath = filepath.Join(homeDir, ".config", "nushell", "config.nu")
+	case ShellElvish:
+		rcPath = filepath.Join(homeDir, ".config", "elvish", "rc.elv")
+	case ShellPwsh:
+		rcPath = filepath.Join(homeDir, ".config", "powershell", "Microsoft.PowerShell_profile.ps1")
 	default:
 		return "", &UnsupportedShellError{Shell: shell.String()}
 	}
@@ -51,6 +65,57 @@ func GetRCFilePath(shell ShellType) (string, error) {
 	return rcPath, nil
 }
 
+// createTemp creates the probe file of CheckRCFileWritable (replaced in
+// tests to simulate a read-only home, which permissions can't do for root)
+var createTemp = os.CreateTemp
+
+// CheckRCFileWritable verifies up front that the RC file can be created and
+// atomically replaced, i.e. that its directory (or nearest existing ancestor)
+// accepts new files. Returns a *HomeReadOnlyError (matching ErrHomeReadOnly)
+// when the location is read-only.
+func CheckRCFileWritable(rcPath string) error {
+	// Find the nearest existing directory; missing parents will be created there
+	dir := filepath.Dir(rcPath)
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return &RCFileError{
+					Path:    rcPath,
+					Message: fmt.Sprintf("%s is not a directory", dir),
+				}
+			}
+			break
+		}
+		parent := filepath.Dir(dir)
+		if !os.IsNotExist(err) || parent == dir {
+			return &RCFileError{
+				Path:    rcPath,
+				Message: "failed to stat parent directory",
+				Cause:   err,
+			}
+		}
+		dir = parent
+	}
+
+	// Probe with a temporary file, the same way the atomic write will
+	probe, err := createTemp(dir, ".zerb-probe-*")
+	if err != nil {
+		if errors.Is(err, fs.ErrPermission) || errors.Is(err, syscall.EROFS) {
+			return &HomeReadOnlyError{Dir: dir, Cause: err}
+		}
+		return &RCFileError{
+			Path:    rcPath,
+			Message: "failed to check directory is writable",
+			Cause:   err,
+		}
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	return nil
+}
+
 // RCFileExists checks if the RC file exists
 func RCFileExists(rcPath string) (bool, error) {
 	info, err := os.Stat(rcPath)
@@ -128,8 +193,15 @@ func CreateRCFile(rcPath string) error {
 	return nil
 }
 
-// HasActivationLine checks if the RC file already contains a ZERB activation line
+// HasActivationLine checks if the RC file already contains a ZERB activation line,
+// either inline or as a line sourcing an activation fragment
 func HasActivationLine(rcPath string) (bool, error) {
+	return scanRCFile(rcPath, IsActivationLine)
+}
+
+// scanRCFile reports whether any line of the RC file satisfies match.
+// A missing file has no matching lines.
+func scanRCFile(rcPath string, match func(line string) bool) (bool, error) {
 	file, err := os.Open(rcPath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -146,8 +218,7 @@ func HasActivationLine(rcPath string) (bool, error) {
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
-		// Check for any variation of zerb activate
-		if strings.Contains(line, ActivationMarker) {
+		if match(line) {
 			return true, nil
 		}
 	}
@@ -163,9 +234,9 @@ func HasActivationLine(rcPath string) (bool, error) {
 	return false, nil
 }
 
-// BackupRCFile creates a timestamped backup of the RC file
+// BackupRCFile creates a backup of the RC file named after the time on clk
 // This prevents overwriting previous backups
-func BackupRCFile(rcPath string) (string, error) {
+func BackupRCFile(rcPath string, clk clock.Clock) (string, error) {
 	// Read the original file
 	content, err := os.ReadFile(rcPath)
 	if err != nil {
@@ -176,12 +247,10 @@ func BackupRCFile(rcPath string) (string, error) {
 		}
 	}
 
-	// Create backup path with timestamp (RFC3339 format, filesystem-safe)
-	timestamp := time.Now().Format("20060102-150405")
-	backupPath := fmt.Sprintf("%s%s.%s", rcPath, BackupSuffix, timestamp)
+	backupPath := BackupPath(rcPath, clk.Now())
 
 	// Write backup with same permissions as original
-	if err := os.WriteFile(backupPath, content, 0644); err != nil {
+	if err := fsutil.WriteFileAtomic(backupPath, content, 0644); err != nil {
 		return "", &RCFileError{
 			Path:    backupPath,
 			Message: "failed to write backup file",
@@ -192,13 +261,253 @@ func BackupRCFile(rcPath string) (string, error) {
 	return backupPath, nil
 }
 
+// backupTimeFormat is the timestamp format of backup file names
+// (filesystem-safe: no colons)
+const backupTimeFormat = "20060102-150405"
+
+// BackupPath returns the path BackupRCFile uses for a backup taken at t
+func BackupPath(rcPath string, t time.Time) string {
+	return fmt.Sprintf("%s%s.%s", rcPath, BackupSuffix, t.Format(backupTimeFormat))
+}
+
+// BackupGlob returns the glob pattern matching the ZERB backups of an RC file
+func BackupGlob(rcPath string) string {
+	return rcPath + BackupSuffix + ".*"
+}
+
+// PruneBackups deletes all but the newest keep ZERB backups of the RC file
+// and returns the paths it removed. keep = 0 keeps all backups. Only files
+// named by BackupRCFile (with a valid timestamp) are considered, so backups
+// made by hand or by other tools are never deleted. The pristine backup
+// (see PristineBackup) is always kept on top of the newest keep, since
+// `zerb uninit --restore-rc` needs it.
+func PruneBackups(rcPath string, keep int) ([]string, error) {
+	if keep < 0 {
+		return nil, fmt.Errorf("invalid backup retention %d: must be 0 (keep all) or more", keep)
+	}
+	if keep == 0 {
+		return nil, nil
+	}
+
+	backups, err := listBackups(rcPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(backups) <= keep {
+		return nil, nil
+	}
+
+	pristine, err := pristineBackup(backups)
+	if err != nil {
+		return nil, err
+	}
+
+	// Newest first
+	slices.Reverse(backups)
+
+	var removed []string
+	for _, path := range backups[keep:] {
+		if path == pristine {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return removed, &RCFileError{
+				Path:    path,
+				Message: "failed to remove old backup",
+				Cause:   err,
+			}
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
+
+// listBackups returns the ZERB backups of the RC file, oldest first. Only
+// regular files named by BackupRCFile (with a valid timestamp) are listed.
+func listBackups(rcPath string) ([]string, error) {
+	matches, err := filepath.Glob(BackupGlob(rcPath))
+	if err != nil {
+		return nil, fmt.Errorf("find backups: %w", err)
+	}
+
+	prefix := rcPath + BackupSuffix + "."
+	var backups []string
+	for _, path := range matches {
+		if _, err := time.Parse(backupTimeFormat, strings.TrimPrefix(path, prefix)); err != nil {
+			continue
+		}
+		if info, err := os.Lstat(path); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		backups = append(backups, path)
+	}
+
+	// The timestamp format sorts chronologically
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// PristineBackup returns the oldest ZERB backup of the RC file without an
+// activation line, normally the one taken before ZERB first changed it, or
+// "" if there is none
+func PristineBackup(rcPath string) (string, error) {
+	backups, err := listBackups(rcPath)
+	if err != nil {
+		return "", err
+	}
+	return pristineBackup(backups)
+}
+
+// pristineBackup returns the first of backups (oldest first) without an
+// activation line, or "" if they all have one
+func pristineBackup(backups []string) (string, error) {
+	for _, path := range backups {
+		activated, err := HasActivationLine(path)
+		if err != nil {
+			return "", err
+		}
+		if !activated {
+			return path, nil
+		}
+	}
+	return "", nil
+}
+
+// PreviewRestore returns the change RestoreBackup would make to the RC
+// file. It fails if the backup has a ZERB activation line, i.e. was taken
+// after ZERB changed the file, since restoring it would not remove ZERB.
+func PreviewRestore(rcPath, backupPath string) (*RCChange, error) {
+	// Security: Check for symlinks (prevent symlink attack)
+	if info, err := os.Lstat(rcPath); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return nil, &RCFileError{
+			Path:    rcPath,
+			Message: "RC file is a symlink (security risk)",
+		}
+	}
+
+	backup, err := os.ReadFile(backupPath)
+	if err != nil {
+		return nil, &RCFileError{
+			Path:    backupPath,
+			Message: "failed to read backup",
+			Cause:   err,
+		}
+	}
+	for _, line := range strings.Split(string(backup), "\n") {
+		if IsActivationLine(strings.TrimSpace(line)) {
+			return nil, &RCFileError{
+				Path:    backupPath,
+				Message: "backup does not predate ZERB (it contains the activation line)",
+			}
+		}
+	}
+
+	current, err := os.ReadFile(rcPath)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return nil, &RCFileError{
+			Path:    rcPath,
+			Message: "failed to read existing file",
+			Cause:   err,
+		}
+	}
+
+	change := &RCChange{
+		Path:       rcPath,
+		Changed:    !exists || string(current) != string(backup),
+		NewContent: string(backup),
+	}
+	if change.Changed {
+		change.Diff = unifiedDiff(rcPath, string(current), string(backup), exists)
+	}
+	return change, nil
+}
+
+// RestoreBackup replaces the RC file with a backup that predates ZERB (see
+// PreviewRestore). The current file is first moved aside to a new backup,
+// whose path is returned ("" if the file did not exist or was unchanged).
+// The new backup is named after the time on clk.
+func RestoreBackup(rcPath, backupPath string, clk clock.Clock) (string, error) {
+	change, err := PreviewRestore(rcPath, backupPath)
+	if err != nil {
+		return "", err
+	}
+	if !change.Changed {
+		return "", nil
+	}
+
+	mode := rcFileMode(rcPath)
+	var aside string
+	if exists, _ := RCFileExists(rcPath); exists {
+		aside, err = BackupRCFile(rcPath, clk)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// Atomic write, keeping the file's permissions
+	if err := fsutil.WriteFileAtomic(rcPath, []byte(change.NewContent), mode); err != nil {
+		return aside, &RCFileError{
+			Path:    rcPath,
+			Message: "failed to restore backup",
+			Cause:   err,
+		}
+	}
+
+	return aside, nil
+}
+
+// rcFileMode returns the permissions of an existing RC file, or 0644 for
+// a new one, so rewriting an RC file never changes its mode
+func rcFileMode(rcPath string) os.FileMode {
+	if info, err := os.Stat(rcPath); err == nil {
+		return info.Mode().Perm()
+	}
+	return 0644
+}
+
 // AddActivationLine adds the ZERB activation line to the RC file
 // This is an atomic operation using a temporary file
-// Returns nil if the activation line already exists (idempotent)
-func AddActivationLine(rcPath string, activationCommand string) error {
+// Returns true if the file was changed, or false if it already contains a ZERB
+// activation line (idempotent); callers need not check HasActivationLine first
+// The activation line may be an inline activation command or a fragment source line
+func AddActivationLine(rcPath string, activationCommand string) (bool, error) {
+	change, err := addActivationLine(rcPath, activationCommand, false, false)
+	if err != nil {
+		return false, err
+	}
+	return change.Changed, nil
+}
+
+// PreviewActivationLine returns the change AddActivationLine would make to
+// the RC file, without writing anything
+func PreviewActivationLine(rcPath string, activationCommand string) (*RCChange, error) {
+	return addActivationLine(rcPath, activationCommand, false, true)
+}
+
+// ReplaceActivationLine replaces the ZERB block in the RC file with one for
+// activationCommand in a single atomic write, e.g. to convert between the
+// inline and fragment forms. Returns true if the file was changed.
+func ReplaceActivationLine(rcPath string, activationCommand string) (bool, error) {
+	change, err := addActivationLine(rcPath, activationCommand, true, false)
+	if err != nil {
+		return false, err
+	}
+	return change.Changed, nil
+}
+
+// PreviewReplaceActivationLine returns the change ReplaceActivationLine
+// would make to the RC file, without writing anything
+func PreviewReplaceActivationLine(rcPath string, activationCommand string) (*RCChange, error) {
+	return addActivationLine(rcPath, activationCommand, true, true)
+}
+
+// addActivationLine implements AddActivationLine; with replace any existing
+// ZERB block is removed first, and with dryRun it only computes the change
+func addActivationLine(rcPath string, activationCommand string, replace, dryRun bool) (*RCChange, error) {
 	// Security: Validate activation command format
-	if !strings.Contains(activationCommand, ActivationMarker) {
-		return &RCFileError{
+	if !IsActivationLine(activationCommand) {
+		return nil, &RCFileError{
 			Path:    rcPath,
 			Message: "invalid activation command format",
 		}
@@ -207,7 +516,7 @@ func AddActivationLine(rcPath string, activationCommand string) error {
 	// Security: Check for symlinks (prevent symlink attack)
 	if info, err := os.Lstat(rcPath); err == nil {
 		if info.Mode()&os.ModeSymlink != 0 {
-			return &RCFileError{
+			return nil, &RCFileError{
 				Path:    rcPath,
 				Message: "RC file is a symlink (security risk)",
 			}
@@ -217,97 +526,134 @@ func AddActivationLine(rcPath string, activationCommand string) error {
 	// Read existing content
 	var existingContent []byte
 	var err error
+	base := ""
 
-	if exists, _ := RCFileExists(rcPath); exists {
+	exists, _ := RCFileExists(rcPath)
+	if exists {
 		existingContent, err = os.ReadFile(rcPath)
 		if err != nil {
-			return &RCFileError{
+			return nil, &RCFileError{
 				Path:    rcPath,
 				Message: "failed to read existing file",
 				Cause:   err,
 			}
 		}
 
+		base = string(existingContent)
+		if replace {
+			base, _ = stripActivation(base)
+		}
+
 		// Check if activation line already exists (fix TOCTOU race condition)
 		// Do this atomically while we have the content in memory
-		if strings.Contains(string(existingContent), ActivationMarker) {
+		if IsActivationLine(base) {
 			// Already present, nothing to do (idempotent)
-			return nil
+			return &RCChange{Path: rcPath, NewContent: string(existingContent)}, nil
 		}
 	}
 
-	// Create temporary file in the same directory (for atomic rename)
-	dir := filepath.Dir(rcPath)
-	tmpFile, err := os.CreateTemp(dir, ".zerb-tmp-*")
-	if err != nil {
-		return &RCFileError{
-			Path:    rcPath,
-			Message: "failed to create temporary file",
-			Cause:   err,
-		}
+	// Build new content: existing content, a separating newline if needed,
+	// then the ZERB activation section
+	var newContent strings.Builder
+	newContent.WriteString(base)
+	if len(base) > 0 && !strings.HasSuffix(base, "\n") {
+		newContent.WriteString("\n")
+	}
+	fmt.Fprintf(&newContent, "\n%s\n%s\n", ActivationComment, activationCommand)
+	if newContent.String() == string(existingContent) {
+		return &RCChange{Path: rcPath, NewContent: string(existingContent)}, nil
 	}
-	tmpPath := tmpFile.Name()
-	defer os.Remove(tmpPath) // Clean up on error
-
-	// Write existing content
-	if len(existingContent) > 0 {
-		if _, err := tmpFile.Write(existingContent); err != nil {
-			tmpFile.Close()
-			return &RCFileError{
-				Path:    rcPath,
-				Message: "failed to write existing content",
-				Cause:   err,
-			}
-		}
 
-		// Ensure there's a newline before our addition
-		if !strings.HasSuffix(string(existingContent), "\n") {
-			if _, err := tmpFile.WriteString("\n"); err != nil {
-				tmpFile.Close()
-				return &RCFileError{
-					Path:    rcPath,
-					Message: "failed to write newline",
-					Cause:   err,
-				}
-			}
-		}
+	change := &RCChange{
+		Path:       rcPath,
+		Changed:    true,
+		NewContent: newContent.String(),
+		Diff:       unifiedDiff(rcPath, string(existingContent), newContent.String(), exists),
+	}
+	if dryRun {
+		return change, nil
 	}
 
-	// Write ZERB activation section
-	zerbSection := fmt.Sprintf("\n# ZERB - Developer environment manager\n%s\n", activationCommand)
-	if _, err := tmpFile.WriteString(zerbSection); err != nil {
-		tmpFile.Close()
-		return &RCFileError{
+	// Atomic write, keeping the file's permissions
+	if err := fsutil.WriteFileAtomic(rcPath, []byte(change.NewContent), rcFileMode(rcPath)); err != nil {
+		return nil, &RCFileError{
 			Path:    rcPath,
 			Message: "failed to write activation line",
 			Cause:   err,
 		}
 	}
 
-	// Sync to disk
-	if err := tmpFile.Sync(); err != nil {
-		tmpFile.Close()
-		return &RCFileError{
-			Path:    rcPath,
-			Message: "failed to sync file",
-			Cause:   err,
-		}
+	return change, nil
+}
+
+// diffContext is the number of unchanged lines shown around a change
+const diffContext = 3
+
+// unifiedDiff returns a unified diff from oldContent to newContent as one
+// hunk from the first differing line to the end, which is minimal when
+// newContent only changes or extends the end of oldContent (as adding the
+// activation line does). A missing file is shown as /dev/null.
+func unifiedDiff(path, oldContent, newContent string, exists bool) string {
+	oldLines := strings.SplitAfter(oldContent, "\n")
+	if oldLines[len(oldLines)-1] == "" {
+		oldLines = oldLines[:len(oldLines)-1]
+	}
+	newLines := strings.SplitAfter(newContent, "\n")
+	if newLines[len(newLines)-1] == "" {
+		newLines = newLines[:len(newLines)-1]
 	}
 
-	tmpFile.Close()
+	// Lines are compared with their line endings, so a last line that
+	// gains a newline shows as changed
+	common := 0
+	for common < len(oldLines) && common < len(newLines) && oldLines[common] == newLines[common] {
+		common++
+	}
+	start := max(common-diffContext, 0)
 
-	// Atomic rename
-	if err := os.Rename(tmpPath, rcPath); err != nil {
-		return &RCFileError{
-			Path:    rcPath,
-			Message: "failed to rename temp file",
-			Cause:   err,
+	var sb strings.Builder
+	if exists {
+		fmt.Fprintf(&sb, "--- %s\n", path)
+	} else {
+		sb.WriteString("--- /dev/null\n")
+	}
+	fmt.Fprintf(&sb, "+++ %s\n", path)
+	fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(start, len(oldLines)-start), hunkRange(start, len(newLines)-start))
+
+	writeLine := func(prefix, line string) {
+		sb.WriteString(prefix + strings.TrimSuffix(line, "\n") + "\n")
+		if !strings.HasSuffix(line, "\n") {
+			sb.WriteString("\\ No newline at end of file\n")
 		}
 	}
+	for _, line := range oldLines[start:common] {
+		writeLine(" ", line)
+	}
+	for _, line := range oldLines[common:] {
+		writeLine("-", line)
+	}
+	for _, line := range newLines[common:] {
+		writeLine("+", line)
+	}
 
-	return nil
+	return sb.String()
+}
+
+// hunkRange formats a unified diff hunk range for count lines after the
+// first skip lines
+func hunkRange(skip, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", skip)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", skip+1)
+	}
+	return fmt.Sprintf("%d,%d", skip+1, count)
 }
-// RemoveActivationLine removes the ZERB activation line from the RC file
+
+// RemoveActivationLine removes the ZERB block (the marker comment and the
+// activation line) from the RC file, even when indented, with CRLF line
+// endings, or when one of the two lines was already removed by hand
 // This is an atomic operation using a temporary file
 // Returns nil if the activation line doesn't exist (idempotent)
 func RemoveActivationLine(rcPath string) error {
@@ -341,94 +687,90 @@ func RemoveActivationLine(rcPath string) error {
 		}
 	}
 
-	// Check if activation line exists
-	if !strings.Contains(string(existingContent), ActivationMarker) {
+	newContent, removed := stripActivation(string(existingContent))
+	if !removed {
 		// Activation line not present, nothing to do (idempotent)
 		return nil
 	}
 
-	// Filter out ZERB-related lines
-	lines := strings.Split(string(existingContent), "\n")
+	// Atomic write, keeping the file's permissions
+	if err := fsutil.WriteFileAtomic(rcPath, []byte(newContent), rcFileMode(rcPath)); err != nil {
+		return &RCFileError{
+			Path:    rcPath,
+			Message: "failed to write filtered content",
+			Cause:   err,
+		}
+	}
+
+	return nil
+}
+
+// stripActivation returns content without its ZERB block (the marker
+// comment and the activation or fragment source line), keeping its line
+// endings, and whether anything was removed
+func stripActivation(content string) (string, bool) {
+	// Keep the file's line endings (CRLF or LF)
+	eol := "\n"
+	if strings.Contains(content, "\r\n") {
+		eol = "\r\n"
+	}
+
+	// Filter out the ZERB block: the marker comment and the activation or
+	// fragment source line, matched regardless of indentation
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
 	var filteredLines []string
-	skipNext := false
+	removed := false
+	skipBlank := false
 
 	for _, line := range lines {
 		trimmed := strings.TrimSpace(line)
-		
-		// Skip the ZERB comment line
-		if trimmed == "# ZERB - Developer environment manager" {
-			skipNext = true
+
+		// Skip the marker comment, even if its activation line is already gone
+		if isActivationComment(trimmed) {
+			removed = true
+			skipBlank = true
 			continue
 		}
-		
-		// Skip any line containing the activation marker
-		if strings.Contains(line, ActivationMarker) {
+
+		// Skip any line containing the activation or fragment marker
+		if IsActivationLine(line) {
+			removed = true
+			skipBlank = true
 			continue
 		}
-		
-		// Skip empty lines immediately after ZERB comment (cleanup)
-		if skipNext && trimmed == "" {
-			skipNext = false
+
+		// Skip one empty line right after the block (cleanup)
+		if skipBlank && trimmed == "" {
+			skipBlank = false
 			continue
 		}
-		
-		skipNext = false
+
+		skipBlank = false
 		filteredLines = append(filteredLines, line)
 	}
 
+	if !removed {
+		return content, false
+	}
+
 	// Remove trailing empty lines that might have been left
 	for len(filteredLines) > 0 && strings.TrimSpace(filteredLines[len(filteredLines)-1]) == "" {
 		filteredLines = filteredLines[:len(filteredLines)-1]
 	}
 
-	// Create temporary file in the same directory (for atomic rename)
-	dir := filepath.Dir(rcPath)
-	tmpFile, err := os.CreateTemp(dir, ".zerb-tmp-*")
-	if err != nil {
-		return &RCFileError{
-			Path:    rcPath,
-			Message: "failed to create temporary file",
-			Cause:   err,
-		}
-	}
-	tmpPath := tmpFile.Name()
-	defer os.Remove(tmpPath) // Clean up on error
-
-	// Write filtered content
-	newContent := strings.Join(filteredLines, "\n")
+	newContent := strings.Join(filteredLines, eol)
 	if len(filteredLines) > 0 {
-		newContent += "\n" // Ensure trailing newline
-	}
-
-	if _, err := tmpFile.WriteString(newContent); err != nil {
-		tmpFile.Close()
-		return &RCFileError{
-			Path:    rcPath,
-			Message: "failed to write filtered content",
-			Cause:   err,
-		}
+		newContent += eol // Ensure trailing newline
 	}
 
-	// Sync to disk
-	if err := tmpFile.Sync(); err != nil {
-		tmpFile.Close()
-		return &RCFileError{
-			Path:    rcPath,
-			Message: "failed to sync file",
-			Cause:   err,
-		}
-	}
-
-	tmpFile.Close()
-
-	// Atomic rename
-	if err := os.Rename(tmpPath, rcPath); err != nil {
-		return &RCFileError{
-			Path:    rcPath,
-			Message: "failed to rename temp file",
-			Cause:   err,
-		}
-	}
+	return newContent, true
+}
 
-	return nil
+// isActivationComment reports whether a trimmed line is the marker comment
+// written above the activation line, allowing for hand-edited spacing
+// (e.g. "#ZERB - Developer environment manager")
+func isActivationComment(trimmed string) bool {
+	text := strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+	return strings.HasPrefix(trimmed, "#") &&
+		strings.EqualFold(text, strings.TrimSpace(strings.TrimPrefix(ActivationComment, "#")))
 }
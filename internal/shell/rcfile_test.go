@@ -5,6 +5,9 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/ZebulonRouseFrantzich/zerb/internal/clock"
 )
 
 func TestGetRCFilePath(t *testing.T) {
@@ -40,6 +43,24 @@ func TestGetRCFilePath(t *testing.T) {
 			want:    filepath.Join(testHome, ".config", "fish", "config.fish"),
 			wantErr: false,
 		},
+		{
+			name:    "Nushell RC file",
+			shell:   ShellNushell,
+			want:    filepath.Join(testHome, ".config", "nushell", "config.nu"),
+			wantErr: false,
+		},
+		{
+			name:    "Elvish RC file",
+			shell:   ShellElvish,
+			want:    filepath.Join(testHome, ".config", "elvish", "rc.elv"),
+			wantErr: false,
+		},
+		{
+			name:    "PowerShell RC file",
+			shell:   ShellPwsh,
+			want:    filepath.Join(testHome, ".config", "powershell", "Microsoft.PowerShell_profile.ps1"),
+			wantErr: false,
+		},
 		{
 			name:    "Unknown shell",
 			shell:   ShellUnknown,
@@ -241,20 +262,15 @@ func TestBackupRCFile(t *testing.T) {
 	}
 
 	// Create backup
-	backupPath, err := BackupRCFile(rcFile)
+	clk := clock.NewFake(time.Date(2025, 1, 2, 3, 4, 5, 0, time.Local))
+	backupPath, err := BackupRCFile(rcFile, clk)
 	if err != nil {
 		t.Fatalf("BackupRCFile() error = %v", err)
 	}
 
-	// Verify backup path has timestamp format: .zerb-backup.YYYYMMDD-HHMMSS
-	expectedPrefix := rcFile + BackupSuffix + "."
-	if !strings.HasPrefix(backupPath, expectedPrefix) {
-		t.Errorf("BackupRCFile() path = %v, want prefix %v", backupPath, expectedPrefix)
-	}
-	// Verify timestamp format (8 digits + dash + 6 digits)
-	timestampPart := strings.TrimPrefix(backupPath, expectedPrefix)
-	if len(timestampPart) != 15 || timestampPart[8] != '-' {
-		t.Errorf("BackupRCFile() timestamp format = %v, want YYYYMMDD-HHMMSS", timestampPart)
+	// The backup is named after the clock's time: .zerb-backup.YYYYMMDD-HHMMSS
+	if want := rcFile + BackupSuffix + ".20250102-030405"; backupPath != want {
+		t.Errorf("BackupRCFile() path = %v, want %v", backupPath, want)
 	}
 
 	// Verify backup exists
@@ -276,6 +292,261 @@ func TestBackupRCFile(t *testing.T) {
 	}
 }
 
+func TestPruneBackups(t *testing.T) {
+	stamps := []string{"20250101-090000", "20250102-090000", "20250103-090000", "20250104-090000"}
+
+	tests := []struct {
+		name        string
+		keep        int
+		wantRemoved []string
+		wantErr     bool
+	}{
+		{name: "keep two", keep: 2, wantRemoved: []string{"20250102-090000", "20250101-090000"}},
+		{name: "keep more than exist", keep: 10},
+		{name: "zero keeps all", keep: 0},
+		{name: "negative", keep: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			rcPath := filepath.Join(dir, ".bashrc")
+			// Written out of order: pruning goes by timestamp, not mtime.
+			// All have the activation line, so none is the pristine backup
+			for _, stamp := range []string{stamps[2], stamps[0], stamps[3], stamps[1]} {
+				if err := os.WriteFile(rcPath+BackupSuffix+"."+stamp, []byte(ActivationComment+"\neval \"$(zerb activate bash)\"\n"), 0644); err != nil {
+					t.Fatalf("failed to write backup: %v", err)
+				}
+			}
+			// Not ZERB backups: never removed
+			others := []string{rcPath + BackupSuffix + ".old", rcPath + ".bak", filepath.Join(dir, ".zshrc"+BackupSuffix+"."+stamps[0])}
+			for _, other := range others {
+				if err := os.WriteFile(other, []byte("other\n"), 0644); err != nil {
+					t.Fatalf("failed to write %s: %v", other, err)
+				}
+			}
+
+			removed, err := PruneBackups(rcPath, tt.keep)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PruneBackups() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var want []string
+			for _, stamp := range tt.wantRemoved {
+				want = append(want, rcPath+BackupSuffix+"."+stamp)
+			}
+			if strings.Join(removed, ",") != strings.Join(want, ",") {
+				t.Errorf("PruneBackups() removed %v, want %v", removed, want)
+			}
+
+			for _, stamp := range stamps {
+				path := rcPath + BackupSuffix + "." + stamp
+				_, err := os.Stat(path)
+				gone := os.IsNotExist(err)
+				wantGone := false
+				for _, w := range want {
+					wantGone = wantGone || w == path
+				}
+				if gone != wantGone {
+					t.Errorf("%s removed = %v, want %v", stamp, gone, wantGone)
+				}
+			}
+			for _, other := range others {
+				if _, err := os.Stat(other); err != nil {
+					t.Errorf("non-ZERB backup %s was touched: %v", other, err)
+				}
+			}
+		})
+	}
+}
+
+func TestPruneBackups_KeepsPristine(t *testing.T) {
+	const original = "export EDITOR=vim\n"
+	const activated = original + "\n" + ActivationComment + "\neval \"$(zerb activate zsh)\"\n"
+
+	dir := t.TempDir()
+	rcPath := filepath.Join(dir, ".zshrc")
+	if err := os.WriteFile(rcPath, []byte(activated), 0644); err != nil {
+		t.Fatalf("failed to write rc file: %v", err)
+	}
+	pristine := rcPath + BackupSuffix + ".20250101-090000"
+	if err := os.WriteFile(pristine, []byte(original), 0644); err != nil {
+		t.Fatalf("failed to write backup: %v", err)
+	}
+	for _, stamp := range []string{"20250102-090000", "20250103-090000", "20250104-090000"} {
+		if err := os.WriteFile(rcPath+BackupSuffix+"."+stamp, []byte(activated), 0644); err != nil {
+			t.Fatalf("failed to write backup: %v", err)
+		}
+	}
+
+	removed, err := PruneBackups(rcPath, 1)
+	if err != nil {
+		t.Fatalf("PruneBackups() error = %v", err)
+	}
+	want := []string{rcPath + BackupSuffix + ".20250103-090000", rcPath + BackupSuffix + ".20250102-090000"}
+	if strings.Join(removed, ",") != strings.Join(want, ",") {
+		t.Errorf("PruneBackups() removed %v, want %v", removed, want)
+	}
+
+	backup, err := PristineBackup(rcPath)
+	if err != nil {
+		t.Fatalf("PristineBackup() error = %v", err)
+	}
+	if backup != pristine {
+		t.Fatalf("PristineBackup() = %q, want %q", backup, pristine)
+	}
+	if _, err := RestoreBackup(rcPath, backup, clock.Real{}); err != nil {
+		t.Fatalf("RestoreBackup() error = %v", err)
+	}
+	got, err := os.ReadFile(rcPath)
+	if err != nil {
+		t.Fatalf("failed to read rc file: %v", err)
+	}
+	if string(got) != original {
+		t.Errorf("rc file after restore = %q, want %q", got, original)
+	}
+}
+
+func TestPristineBackup(t *testing.T) {
+	const activated = ActivationComment + "\neval \"$(zerb activate zsh)\"\n"
+
+	tests := []struct {
+		name    string
+		backups map[string]string
+		want    string
+	}{
+		{name: "no backups"},
+		{
+			name: "oldest",
+			backups: map[string]string{
+				".20250103-090000": "backup\n",
+				".20250101-090000": "backup\n",
+				".old":             "backup\n",
+			},
+			want: ".20250101-090000",
+		},
+		{
+			name: "skips backups with the activation line",
+			backups: map[string]string{
+				".20250101-090000": activated,
+				".20250102-090000": "backup\n",
+				".20250103-090000": activated,
+			},
+			want: ".20250102-090000",
+		},
+		{
+			name: "all have the activation line",
+			backups: map[string]string{
+				".20250101-090000": activated,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			rcPath := filepath.Join(dir, ".zshrc")
+			for suffix, content := range tt.backups {
+				if err := os.WriteFile(rcPath+BackupSuffix+suffix, []byte(content), 0644); err != nil {
+					t.Fatalf("failed to write backup: %v", err)
+				}
+			}
+
+			got, err := PristineBackup(rcPath)
+			if err != nil {
+				t.Fatalf("PristineBackup() error = %v", err)
+			}
+			want := ""
+			if tt.want != "" {
+				want = rcPath + BackupSuffix + tt.want
+			}
+			if got != want {
+				t.Errorf("PristineBackup() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestRestoreBackup(t *testing.T) {
+	const original = "export EDITOR=vim\n"
+	const current = "export EDITOR=vim\n\n" + ActivationComment + "\neval \"$(zerb activate zsh)\"\n"
+
+	tests := []struct {
+		name        string
+		backup      string
+		current     string
+		wantErr     bool
+		wantChanged bool
+	}{
+		{name: "restores pre-ZERB backup", backup: original, current: current, wantChanged: true},
+		{name: "backup already unchanged", backup: original, current: original},
+		{name: "backup taken after ZERB", backup: current, current: current, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			rcPath := filepath.Join(dir, ".zshrc")
+			backupPath := rcPath + BackupSuffix + ".20250101-090000"
+			if err := os.WriteFile(rcPath, []byte(tt.current), 0600); err != nil {
+				t.Fatalf("failed to write rc file: %v", err)
+			}
+			if err := os.WriteFile(backupPath, []byte(tt.backup), 0644); err != nil {
+				t.Fatalf("failed to write backup: %v", err)
+			}
+
+			change, err := PreviewRestore(rcPath, backupPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PreviewRestore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && change.Changed != tt.wantChanged {
+				t.Errorf("PreviewRestore().Changed = %v, want %v", change.Changed, tt.wantChanged)
+			}
+			if tt.wantChanged && !strings.Contains(change.Diff, "-eval \"$(zerb activate zsh)\"") {
+				t.Errorf("PreviewRestore().Diff does not remove the activation line:\n%s", change.Diff)
+			}
+
+			aside, err := RestoreBackup(rcPath, backupPath, clock.Real{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RestoreBackup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got, err := os.ReadFile(rcPath)
+			if err != nil {
+				t.Fatalf("failed to read rc file: %v", err)
+			}
+			want := tt.backup
+			if tt.wantErr {
+				want = tt.current
+			}
+			if string(got) != want {
+				t.Errorf("rc file = %q, want %q", got, want)
+			}
+
+			if !tt.wantChanged {
+				if aside != "" {
+					t.Errorf("RestoreBackup() moved the file aside to %s, want no change", aside)
+				}
+				return
+			}
+			moved, err := os.ReadFile(aside)
+			if err != nil {
+				t.Fatalf("failed to read moved-aside file: %v", err)
+			}
+			if string(moved) != tt.current {
+				t.Errorf("moved-aside file = %q, want %q", moved, tt.current)
+			}
+			info, err := os.Stat(rcPath)
+			if err != nil {
+				t.Fatalf("failed to stat rc file: %v", err)
+			}
+			if info.Mode().Perm() != 0600 {
+				t.Errorf("rc file mode = %v, want 0600", info.Mode().Perm())
+			}
+		})
+	}
+}
+
 func TestAddActivationLine(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -328,7 +599,7 @@ func TestAddActivationLine(t *testing.T) {
 			}
 
 			// Add activation line
-			err := AddActivationLine(rcFile, tt.activationCommand)
+			_, err := AddActivationLine(rcFile, tt.activationCommand)
 			if err != nil {
 				t.Fatalf("AddActivationLine() error = %v", err)
 			}
@@ -350,6 +621,43 @@ func TestAddActivationLine(t *testing.T) {
 	}
 }
 
+func TestActivationLine_PreservesMode(t *testing.T) {
+	activationCommand := `eval "$(zerb activate bash)"`
+
+	for _, mode := range []os.FileMode{0600, 0644, 0640} {
+		t.Run(mode.String(), func(t *testing.T) {
+			rcFile := filepath.Join(t.TempDir(), "test.rc")
+			if err := os.WriteFile(rcFile, []byte("export FOO=bar\n"), mode); err != nil {
+				t.Fatalf("Failed to write RC file: %v", err)
+			}
+			if err := os.Chmod(rcFile, mode); err != nil {
+				t.Fatalf("Failed to chmod RC file: %v", err)
+			}
+
+			assertMode := func(step string) {
+				t.Helper()
+				info, err := os.Stat(rcFile)
+				if err != nil {
+					t.Fatalf("Failed to stat RC file: %v", err)
+				}
+				if info.Mode().Perm() != mode {
+					t.Errorf("mode after %s = %v, want %v", step, info.Mode().Perm(), mode)
+				}
+			}
+
+			if _, err := AddActivationLine(rcFile, activationCommand); err != nil {
+				t.Fatalf("AddActivationLine() error = %v", err)
+			}
+			assertMode("AddActivationLine")
+
+			if err := RemoveActivationLine(rcFile); err != nil {
+				t.Fatalf("RemoveActivationLine() error = %v", err)
+			}
+			assertMode("RemoveActivationLine")
+		})
+	}
+}
+
 func TestAddActivationLine_Idempotent(t *testing.T) {
 	tmpDir := t.TempDir()
 	rcFile := filepath.Join(tmpDir, "test.rc")
@@ -357,10 +665,13 @@ func TestAddActivationLine_Idempotent(t *testing.T) {
 	activationCommand := `eval "$(zerb activate bash)"`
 
 	// Add activation line first time
-	err := AddActivationLine(rcFile, activationCommand)
+	changed, err := AddActivationLine(rcFile, activationCommand)
 	if err != nil {
 		t.Fatalf("First AddActivationLine() error = %v", err)
 	}
+	if !changed {
+		t.Error("First AddActivationLine() changed = false, want true")
+	}
 
 	// Read content after first add
 	firstContent, err := os.ReadFile(rcFile)
@@ -368,11 +679,14 @@ func TestAddActivationLine_Idempotent(t *testing.T) {
 		t.Fatalf("Failed to read file: %v", err)
 	}
 
-	// Add activation line second time
-	err = AddActivationLine(rcFile, activationCommand)
+	// Add activation line second time, without checking HasActivationLine
+	changed, err = AddActivationLine(rcFile, activationCommand)
 	if err != nil {
 		t.Fatalf("Second AddActivationLine() error = %v", err)
 	}
+	if changed {
+		t.Error("Second AddActivationLine() changed = true, want false")
+	}
 
 	// Read content after second add
 	secondContent, err := os.ReadFile(rcFile)
@@ -380,18 +694,119 @@ func TestAddActivationLine_Idempotent(t *testing.T) {
 		t.Fatalf("Failed to read file: %v", err)
 	}
 
-	// Count occurrences of activation command
-	secondCount := strings.Count(string(secondContent), "ZERB - Developer environment manager")
+	// Exactly one ZERB block, and the file is untouched by the second call
+	if count := strings.Count(string(secondContent), "# ZERB - Developer environment manager"); count != 1 {
+		t.Errorf("found %d ZERB blocks after two calls, want 1\n%s", count, secondContent)
+	}
+	if count := strings.Count(string(secondContent), activationCommand); count != 1 {
+		t.Errorf("found %d activation lines after two calls, want 1", count)
+	}
+	if string(secondContent) != string(firstContent) {
+		t.Errorf("second AddActivationLine() modified the file:\nbefore:\n%s\nafter:\n%s", firstContent, secondContent)
+	}
+}
+
+func TestPreviewActivationLine(t *testing.T) {
+	cmd := `eval "$(zerb activate zsh)"`
 
-	// Should have added the section twice (not idempotent by design in current implementation)
-	// Note: The idempotency check should be done at a higher level (HasActivationLine before calling AddActivationLine)
-	if secondCount != 2 {
-		t.Logf("Note: AddActivationLine is not idempotent by itself - this is expected behavior")
-		t.Logf("Idempotency should be handled by checking HasActivationLine() before calling AddActivationLine()")
-		t.Logf("First content length: %d, Second content length: %d", len(firstContent), len(secondContent))
+	tests := []struct {
+		name     string
+		content  *string // nil: file does not exist
+		wantDiff string
+	}{
+		{
+			name:    "missing file",
+			content: nil,
+			wantDiff: "--- /dev/null\n" +
+				"+++ RC\n" +
+				"@@ -0,0 +1,3 @@\n" +
+				"+\n" +
+				"+" + ActivationComment + "\n" +
+				"+" + cmd + "\n",
+		},
+		{
+			name:    "context lines",
+			content: ptr("one\ntwo\nthree\nfour\n"),
+			wantDiff: "--- RC\n" +
+				"+++ RC\n" +
+				"@@ -2,3 +2,6 @@\n" +
+				" two\n" +
+				" three\n" +
+				" four\n" +
+				"+\n" +
+				"+" + ActivationComment + "\n" +
+				"+" + cmd + "\n",
+		},
+		{
+			name:    "no trailing newline",
+			content: ptr("export A=1"),
+			wantDiff: "--- RC\n" +
+				"+++ RC\n" +
+				"@@ -1 +1,4 @@\n" +
+				"-export A=1\n" +
+				"\\ No newline at end of file\n" +
+				"+export A=1\n" +
+				"+\n" +
+				"+" + ActivationComment + "\n" +
+				"+" + cmd + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rcPath := filepath.Join(t.TempDir(), ".zshrc")
+			if tt.content != nil {
+				if err := os.WriteFile(rcPath, []byte(*tt.content), 0644); err != nil {
+					t.Fatalf("failed to write rc file: %v", err)
+				}
+			}
+
+			change, err := PreviewActivationLine(rcPath, cmd)
+			if err != nil {
+				t.Fatalf("PreviewActivationLine() error = %v", err)
+			}
+			if !change.Changed {
+				t.Error("Changed = false, want true")
+			}
+			if want := strings.ReplaceAll(tt.wantDiff, "RC", rcPath); change.Diff != want {
+				t.Errorf("Diff =\n%s\nwant:\n%s", change.Diff, want)
+			}
+
+			// Nothing is written
+			content, err := os.ReadFile(rcPath)
+			if tt.content == nil {
+				if !os.IsNotExist(err) {
+					t.Errorf("rc file was created, stat error = %v", err)
+				}
+			} else if string(content) != *tt.content {
+				t.Errorf("rc file changed to %q", content)
+			}
+
+			// The preview matches what AddActivationLine writes
+			if _, err := AddActivationLine(rcPath, cmd); err != nil {
+				t.Fatalf("AddActivationLine() error = %v", err)
+			}
+			written, err := os.ReadFile(rcPath)
+			if err != nil {
+				t.Fatalf("failed to read rc file: %v", err)
+			}
+			if string(written) != change.NewContent {
+				t.Errorf("NewContent = %q, written %q", change.NewContent, written)
+			}
+
+			again, err := PreviewActivationLine(rcPath, cmd)
+			if err != nil {
+				t.Fatalf("second PreviewActivationLine() error = %v", err)
+			}
+			if again.Changed || again.Diff != "" {
+				t.Errorf("preview after adding = %+v, want no change", again)
+			}
+		})
 	}
 }
 
+func ptr(s string) *string { return &s }
+
 func TestRemoveActivationLine(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -490,6 +905,71 @@ export ZSH_THEME="agnoster"
 			wantErr:      false,
 			shouldModify: true,
 		},
+		{
+			name: "Indented block preceded by a blank comment line",
+			initialContent: `if [[ -o interactive ]]; then
+  #
+  # ZERB - Developer environment manager
+  eval "$(zerb activate zsh)"
+fi
+`,
+			expectedContent: `if [[ -o interactive ]]; then
+  #
+fi
+`,
+			wantErr:      false,
+			shouldModify: true,
+		},
+		{
+			name:            "Tab-indented block with hand-edited comment spacing",
+			initialContent:  "export FOO=bar\n\t#ZERB - Developer environment manager\n\teval \"$(zerb activate bash)\"\nexport BAZ=qux\n",
+			expectedContent: "export FOO=bar\nexport BAZ=qux\n",
+			wantErr:         false,
+			shouldModify:    true,
+		},
+		{
+			name: "Comment separated from activation line",
+			initialContent: `export FOO=bar
+# ZERB - Developer environment manager
+
+eval "$(zerb activate bash)"
+export BAZ=qux
+`,
+			expectedContent: `export FOO=bar
+export BAZ=qux
+`,
+			wantErr:      false,
+			shouldModify: true,
+		},
+		{
+			name: "Orphaned comment after activation line was deleted by hand",
+			initialContent: `export FOO=bar
+
+# ZERB - Developer environment manager
+
+alias ll='ls -la'
+`,
+			expectedContent: `export FOO=bar
+
+alias ll='ls -la'
+`,
+			wantErr:      false,
+			shouldModify: true,
+		},
+		{
+			name:            "CRLF line endings",
+			initialContent:  "export FOO=bar\r\n\r\n# ZERB - Developer environment manager\r\neval \"$(zerb activate bash)\"\r\n\r\nalias ll='ls -la'\r\n",
+			expectedContent: "export FOO=bar\r\n\r\nalias ll='ls -la'\r\n",
+			wantErr:         false,
+			shouldModify:    true,
+		},
+		{
+			name:            "Indented CRLF block",
+			initialContent:  "export FOO=bar\r\n    # ZERB - Developer environment manager\r\n    eval \"$(zerb activate bash)\"\r\n",
+			expectedContent: "export FOO=bar\r\n",
+			wantErr:         false,
+			shouldModify:    true,
+		},
 		{
 			name:            "Empty file (idempotent)",
 			initialContent:  "",
@@ -527,6 +1007,15 @@ export ZSH_THEME="agnoster"
 			if got != tt.expectedContent {
 				t.Errorf("RemoveActivationLine() content mismatch\nGot:\n%q\n\nWant:\n%q", got, tt.expectedContent)
 			}
+
+			// A second removal changes nothing
+			if err := RemoveActivationLine(rcPath); err != nil {
+				t.Fatalf("second RemoveActivationLine() error = %v", err)
+			}
+			again, _ := os.ReadFile(rcPath)
+			if string(again) != got {
+				t.Errorf("second RemoveActivationLine() changed content\nGot:\n%q\n\nWant:\n%q", again, got)
+			}
 		})
 	}
 }
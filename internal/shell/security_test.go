@@ -15,7 +15,7 @@ func TestAddActivationLine_SecurityValidation(t *testing.T) {
 		rcFile := filepath.Join(tmpDir, "test.rc")
 
 		// Try to add malicious command
-		err := AddActivationLine(rcFile, "rm -rf /")
+		_, err := AddActivationLine(rcFile, "rm -rf /")
 		if err == nil {
 			t.Error("AddActivationLine() should reject invalid activation command")
 		}
@@ -35,7 +35,7 @@ func TestAddActivationLine_SecurityValidation(t *testing.T) {
 		_ = os.Symlink(realFile, symlinkFile)
 
 		// Try to add to symlink
-		err := AddActivationLine(symlinkFile, `eval "$(zerb activate bash)"`)
+		_, err := AddActivationLine(symlinkFile, `eval "$(zerb activate bash)"`)
 		if err == nil {
 			t.Error("AddActivationLine() should reject symlink")
 		}
@@ -49,7 +49,7 @@ func TestAddActivationLine_SecurityValidation(t *testing.T) {
 		rcFile := filepath.Join(tmpDir, "valid.rc")
 
 		// Valid command should work
-		err := AddActivationLine(rcFile, `eval "$(zerb activate bash)"`)
+		_, err := AddActivationLine(rcFile, `eval "$(zerb activate bash)"`)
 		if err != nil {
 			t.Errorf("AddActivationLine() should accept valid command: %v", err)
 		}
@@ -1,6 +1,9 @@
 package shell
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 // ShellType represents a supported shell
 type ShellType string
@@ -12,6 +15,12 @@ const (
 	ShellZsh ShellType = "zsh"
 	// ShellFish represents the Fish shell
 	ShellFish ShellType = "fish"
+	// ShellNushell represents Nushell
+	ShellNushell ShellType = "nu"
+	// ShellElvish represents the Elvish shell
+	ShellElvish ShellType = "elvish"
+	// ShellPwsh represents PowerShell (pwsh)
+	ShellPwsh ShellType = "pwsh"
 	// ShellUnknown represents an unknown or unsupported shell
 	ShellUnknown ShellType = "unknown"
 )
@@ -24,7 +33,7 @@ func (s ShellType) String() string {
 // IsValid returns true if the shell type is supported
 func (s ShellType) IsValid() bool {
 	switch s {
-	case ShellBash, ShellZsh, ShellFish:
+	case ShellBash, ShellZsh, ShellFish, ShellNushell, ShellElvish, ShellPwsh:
 		return true
 	default:
 		return false
@@ -45,8 +54,14 @@ type SetupOptions struct {
 	Force bool
 	// Backup creates a backup of the rc file before modification
 	Backup bool
+	// BackupRetention is how many backups of the rc file to keep when a new
+	// one is taken; older ones are pruned (0 keeps all)
+	BackupRetention int
 	// DryRun shows what would be done without making changes
 	DryRun bool
+	// FragmentMode writes the activation to a managed fragment file in the
+	// ZERB directory and adds only a line sourcing it to the rc file
+	FragmentMode bool
 }
 
 // SetupResult contains the result of shell integration setup
@@ -59,10 +74,63 @@ type SetupResult struct {
 	Added bool
 	// AlreadyPresent indicates if activation was already configured
 	AlreadyPresent bool
+	// Converted indicates that activation in the other form (inline or
+	// fragment) was converted to the requested one
+	Converted bool
 	// BackupPath is the path to the backup file (if created)
 	BackupPath string
+	// PrunedBackups are old backups removed to honor BackupRetention
+	PrunedBackups []string
 	// ActivationCommand is the command that was added
 	ActivationCommand string
+	// FragmentPath is the path to the activation fragment (fragment mode only)
+	FragmentPath string
+	// Diff is a unified diff of the change to the RC file, preceded by the
+	// backup that would be taken and how the file would be written (dry
+	// run only; empty if nothing would change)
+	Diff string
+	// NewContent is the RC file content after the change (dry run only)
+	NewContent string
+}
+
+// RCChange describes a change to an RC file
+type RCChange struct {
+	// Path is the RC file
+	Path string
+	// Changed is false if the file already had a ZERB activation line
+	Changed bool
+	// NewContent is the full file content after the change
+	NewContent string
+	// Diff is a unified diff of the change (empty if unchanged)
+	Diff string
+}
+
+// SetupStatus is the outcome of setting up one shell in SetupAll
+type SetupStatus string
+
+const (
+	// SetupAdded means the activation line was added to the rc file
+	SetupAdded SetupStatus = "added"
+	// SetupSkipped means nothing was changed: activation was already
+	// present, or the shell is not installed and has no rc file
+	SetupSkipped SetupStatus = "skipped"
+	// SetupFailed means setting up the shell failed; see SetupReport.Err
+	SetupFailed SetupStatus = "error"
+)
+
+// SetupReport is the per-shell result of SetupAll
+type SetupReport struct {
+	// Shell is the shell that was set up
+	Shell ShellType
+	// Status is the outcome for this shell
+	Status SetupStatus
+	// Reason explains a skipped shell
+	Reason string
+	// Result is the setup result (nil if the shell was skipped before
+	// setup or setup failed)
+	Result *SetupResult
+	// Err is the setup error (SetupFailed only)
+	Err error
 }
 
 // DetectionResult contains the result of shell detection
@@ -75,6 +143,9 @@ type DetectionResult struct {
 	ShellPath string
 	// Confidence is the confidence level (high, medium, low)
 	Confidence string
+	// Multiplexer names the terminal multiplexer (e.g. "tmux") ZERB runs
+	// under, if any. The parent process is not used for detection then.
+	Multiplexer string
 }
 
 // ValidationError represents a shell validation error
@@ -93,7 +164,7 @@ type UnsupportedShellError struct {
 }
 
 func (e *UnsupportedShellError) Error() string {
-	return fmt.Sprintf("unsupported shell: %s (supported: bash, zsh, fish)", e.Shell)
+	return fmt.Sprintf("unsupported shell: %s (supported: bash, zsh, fish, nu, elvish, pwsh)", e.Shell)
 }
 
 // RCFileError represents an error with shell rc file operations
@@ -113,3 +184,31 @@ func (e *RCFileError) Error() string {
 func (e *RCFileError) Unwrap() error {
 	return e.Cause
 }
+
+// ErrHomeReadOnly indicates that the home directory (or the directory holding
+// the shell RC file) cannot be written to, so shell integration cannot be set up.
+var ErrHomeReadOnly = errors.New("home directory is read-only")
+
+// HomeReadOnlyError reports a non-writable directory during shell setup,
+// with guidance on how to proceed. It matches ErrHomeReadOnly via errors.Is.
+type HomeReadOnlyError struct {
+	Dir   string
+	Cause error
+}
+
+func (e *HomeReadOnlyError) Error() string {
+	return fmt.Sprintf("%s: cannot write to %s\n"+
+		"To continue, either:\n"+
+		"  - set ZERB_DIR to a writable location, or\n"+
+		"  - skip shell integration and add the activation line to your shell config manually",
+		ErrHomeReadOnly, e.Dir)
+}
+
+func (e *HomeReadOnlyError) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether target is ErrHomeReadOnly.
+func (e *HomeReadOnlyError) Is(target error) bool {
+	return target == ErrHomeReadOnly
+}
@@ -0,0 +1,184 @@
+package shell
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ActivationStatus is the outcome of VerifyActivation
+type ActivationStatus string
+
+const (
+	// ActivationOK means zerb is on PATH and sourcing the rc file activates ZERB
+	ActivationOK ActivationStatus = "ok"
+	// ActivationZerbNotOnPath means the shell cannot find the zerb command,
+	// so the activation line in the rc file cannot run
+	ActivationZerbNotOnPath ActivationStatus = "zerb-not-on-path"
+	// ActivationNotSourced means zerb is on PATH but sourcing the rc file
+	// does not activate ZERB: the activation line is missing, or the rc
+	// file stops (or fails) before reaching it
+	ActivationNotSourced ActivationStatus = "rc-not-sourced"
+)
+
+// ErrVerifyUnsupported is returned by VerifyActivation for shells whose
+// activation cannot be checked non-interactively.
+var ErrVerifyUnsupported = errors.New("activation check not supported for this shell")
+
+// verifyTimeout bounds how long the spawned shell may run
+const verifyTimeout = 10 * time.Second
+
+// envVerifyRC passes the rc file path to the verification script, avoiding
+// quoting it into the script
+const envVerifyRC = "ZERB_VERIFY_RC"
+
+// ActivationCheck is the result of VerifyActivation
+type ActivationCheck struct {
+	// Shell is the shell that was checked
+	Shell ShellType
+	// RCFile is the rc file that was sourced
+	RCFile string
+	// Status is the outcome of the check
+	Status ActivationStatus
+	// ZerbPath is where the shell found the zerb command (empty if not found)
+	ZerbPath string
+	// Message explains the status and how to fix a failure
+	Message string
+}
+
+// OK reports whether activation works
+func (c *ActivationCheck) OK() bool {
+	return c.Status == ActivationOK
+}
+
+// verifyCommands returns the command that starts an interactive shell
+// without its own startup files, sources $ZERB_VERIFY_RC and prints
+// "zerb=<path>" and "active=<ZERB_ACTIVE>". The shell is interactive
+// because rc files commonly stop early otherwise (Debian's .bashrc returns
+// unless $- contains i, and fish configs activate inside
+// `if status is-interactive`).
+var verifyCommands = map[ShellType][]string{
+	ShellBash: {"bash", "--norc", "--noprofile", "-i", "-c",
+		`. "$ZERB_VERIFY_RC" >/dev/null 2>&1; printf 'zerb=%s\n' "$(command -v zerb)"; printf 'active=%s\n' "${ZERB_ACTIVE:-}"`},
+	ShellZsh: {"zsh", "-f", "-i", "-c",
+		`. "$ZERB_VERIFY_RC" >/dev/null 2>&1; printf 'zerb=%s\n' "$(command -v zerb)"; printf 'active=%s\n' "${ZERB_ACTIVE:-}"`},
+	ShellFish: {"fish", "--no-config", "-i", "-c",
+		`source $ZERB_VERIFY_RC >/dev/null 2>&1; printf 'zerb=%s\n' (command -v zerb); printf 'active=%s\n' "$ZERB_ACTIVE"`},
+}
+
+// VerifyActivation checks that shell integration works: it starts the shell
+// as an interactive shell without a terminal, sources its rc file and checks
+// that the zerb command is on PATH and that activation ran. The shell does
+// not inherit ZERB_ACTIVE or the PATH entries inside the ZERB directory, so
+// running it from an activated shell does not hide a broken rc file. The
+// shell is killed if it takes longer than 10 seconds or ctx is cancelled.
+// Returns ErrVerifyUnsupported for shells other than bash, zsh and fish.
+func (m *Manager) VerifyActivation(ctx context.Context, shell ShellType) (*ActivationCheck, error) {
+	if err := ValidateShell(shell); err != nil {
+		return nil, err
+	}
+
+	args, ok := verifyCommands[shell]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrVerifyUnsupported, shell)
+	}
+
+	rcPath, err := GetRCFilePath(shell)
+	if err != nil {
+		return nil, fmt.Errorf("get RC file path: %w", err)
+	}
+
+	check := &ActivationCheck{Shell: shell, RCFile: rcPath}
+
+	hasActivation, err := HasActivationLine(rcPath)
+	if err != nil {
+		return nil, fmt.Errorf("check activation line: %w", err)
+	}
+
+	shellPath, err := exec.LookPath(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("find %s: %w", shell, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, verifyTimeout)
+	defer cancel()
+
+	//nolint:gosec // G204: The shell and script are fixed per shell type; the rc path is passed via the environment
+	cmd := exec.CommandContext(ctx, shellPath, args[1:]...)
+	cmd.Env = append(m.verifyEnv(), envVerifyRC+"="+rcPath)
+	cmd.WaitDelay = time.Second
+	detachTerminal(cmd)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%s did not finish loading %s: %w", shell, rcPath, ctx.Err())
+		}
+		return nil, fmt.Errorf("run %s: %w", shell, err)
+	}
+
+	var active string
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if value, ok := strings.CutPrefix(line, "zerb="); ok {
+			check.ZerbPath = strings.TrimSpace(value)
+		} else if value, ok := strings.CutPrefix(line, "active="); ok {
+			active = strings.TrimSpace(value)
+		}
+	}
+
+	switch {
+	case check.ZerbPath == "":
+		check.Status = ActivationZerbNotOnPath
+		check.Message = fmt.Sprintf("zerb is not on PATH in %s\n"+
+			"Install zerb to a directory on PATH (e.g. ~/.local/bin) or add its directory to PATH in %s", shell, rcPath)
+	case !hasActivation:
+		activationCmd, err := GenerateActivationCommand(shell)
+		if err != nil {
+			return nil, fmt.Errorf("generate activation command: %w", err)
+		}
+		check.Status = ActivationNotSourced
+		check.Message = fmt.Sprintf("%s has no ZERB activation line\nAdd this line to it:\n  %s", rcPath, activationCmd)
+	case active != "1":
+		check.Status = ActivationNotSourced
+		check.Message = fmt.Sprintf("sourcing %s does not activate ZERB\n"+
+			"Check that the file is loaded by your shell and that nothing before the ZERB activation line exits or fails", rcPath)
+	default:
+		check.Status = ActivationOK
+		check.Message = fmt.Sprintf("ZERB activates in %s (zerb at %s)", shell, check.ZerbPath)
+	}
+
+	return check, nil
+}
+
+// verifyEnv returns the current environment without what ZERB activation
+// adds to it: ZERB_ACTIVE and the PATH entries inside the ZERB directory
+func (m *Manager) verifyEnv() []string {
+	zerbDir := filepath.Clean(m.zerbDir)
+	var env []string
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		switch name {
+		case EnvZerbActive:
+			continue
+		case "PATH":
+			var dirs []string
+			for _, dir := range filepath.SplitList(value) {
+				clean := filepath.Clean(dir)
+				if clean == zerbDir || strings.HasPrefix(clean, zerbDir+string(filepath.Separator)) {
+					continue
+				}
+				dirs = append(dirs, dir)
+			}
+			kv = "PATH=" + strings.Join(dirs, string(os.PathListSeparator))
+		}
+		env = append(env, kv)
+	}
+	return env
+}
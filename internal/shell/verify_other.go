@@ -0,0 +1,8 @@
+//go:build !unix
+
+package shell
+
+import "os/exec"
+
+// detachTerminal does nothing where sessions are not supported
+func detachTerminal(cmd *exec.Cmd) {}
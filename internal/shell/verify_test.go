@@ -0,0 +1,178 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupVerifyEnv points HOME at a temp dir with the given .bashrc and sets
+// PATH, with a fake zerb that prints the activation marker if withZerb
+func setupVerifyEnv(t *testing.T, bashrc string, withZerb bool) {
+	t.Helper()
+
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not installed")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.WriteFile(filepath.Join(home, ".bashrc"), []byte(bashrc), 0644); err != nil {
+		t.Fatalf("failed to write .bashrc: %v", err)
+	}
+
+	path := "/usr/bin:/bin"
+	if withZerb {
+		binDir := t.TempDir()
+		script := "#!/bin/sh\necho 'export ZERB_ACTIVE=1'\n"
+		if err := os.WriteFile(filepath.Join(binDir, "zerb"), []byte(script), 0755); err != nil {
+			t.Fatalf("failed to write fake zerb: %v", err)
+		}
+		path = binDir + ":" + path
+	}
+	t.Setenv("PATH", path)
+	t.Setenv(EnvZerbActive, "")
+}
+
+func TestVerifyActivation(t *testing.T) {
+	activation := "\n# ZERB - Developer environment manager\neval \"$(zerb activate bash)\"\n"
+
+	tests := []struct {
+		name        string
+		bashrc      string
+		withZerb    bool
+		wantStatus  ActivationStatus
+		wantMessage string
+	}{
+		{
+			name:       "activation works",
+			bashrc:     "export EDITOR=vim\n" + activation,
+			withZerb:   true,
+			wantStatus: ActivationOK,
+		},
+		{
+			name:        "zerb not on PATH",
+			bashrc:      activation,
+			withZerb:    false,
+			wantStatus:  ActivationZerbNotOnPath,
+			wantMessage: "zerb is not on PATH",
+		},
+		{
+			name:        "no activation line",
+			bashrc:      "export EDITOR=vim\n",
+			withZerb:    true,
+			wantStatus:  ActivationNotSourced,
+			wantMessage: `eval "$(zerb activate bash)"`,
+		},
+		{
+			name:       "rc file returns unless PS1 is set",
+			bashrc:     "[ -z \"$PS1\" ] && return\n" + activation,
+			withZerb:   true,
+			wantStatus: ActivationOK,
+		},
+		{
+			name:       "rc file returns in non-interactive shells",
+			bashrc:     "case $- in\n  *i*) ;;\n  *) return;;\nesac\n" + activation,
+			withZerb:   true,
+			wantStatus: ActivationOK,
+		},
+		{
+			name:        "rc file returns before activation",
+			bashrc:      "return\n" + activation,
+			withZerb:    true,
+			wantStatus:  ActivationNotSourced,
+			wantMessage: "does not activate ZERB",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupVerifyEnv(t, tt.bashrc, tt.withZerb)
+			manager, _ := NewManager(Config{ZerbDir: t.TempDir()})
+
+			check, err := manager.VerifyActivation(context.Background(), ShellBash)
+			if err != nil {
+				t.Fatalf("VerifyActivation() error = %v", err)
+			}
+			if check.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q (message: %s)", check.Status, tt.wantStatus, check.Message)
+			}
+			if check.OK() != (tt.wantStatus == ActivationOK) {
+				t.Errorf("OK() = %v for status %q", check.OK(), check.Status)
+			}
+			if tt.withZerb && check.ZerbPath == "" {
+				t.Error("ZerbPath is empty, want the fake zerb")
+			}
+			if !strings.Contains(check.Message, tt.wantMessage) {
+				t.Errorf("Message = %q, want it to contain %q", check.Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestVerifyActivation_FromActivatedShell(t *testing.T) {
+	activation := "\n# ZERB - Developer environment manager\neval \"$(zerb activate bash)\"\n"
+	setupVerifyEnv(t, "return\n"+activation, true)
+	zerbDir := t.TempDir()
+	manager, _ := NewManager(Config{ZerbDir: zerbDir})
+
+	// What an activated shell has: the marker, and the ZERB directory on PATH
+	t.Setenv(EnvZerbActive, "1")
+	check, err := manager.VerifyActivation(context.Background(), ShellBash)
+	if err != nil {
+		t.Fatalf("VerifyActivation() error = %v", err)
+	}
+	if check.Status != ActivationNotSourced {
+		t.Errorf("Status = %q, want %q (ZERB_ACTIVE must not be inherited)", check.Status, ActivationNotSourced)
+	}
+
+	// A zerb found only inside the ZERB directory is not on a new shell's PATH
+	binDir := filepath.Join(zerbDir, "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(binDir, "zerb"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+":/usr/bin:/bin")
+	check, err = manager.VerifyActivation(context.Background(), ShellBash)
+	if err != nil {
+		t.Fatalf("VerifyActivation() error = %v", err)
+	}
+	if check.Status != ActivationZerbNotOnPath {
+		t.Errorf("Status = %q, want %q (ZERB PATH entries must not be inherited)", check.Status, ActivationZerbNotOnPath)
+	}
+}
+
+func TestVerifyActivation_Timeout(t *testing.T) {
+	setupVerifyEnv(t, "sleep 30\n", true)
+	manager, _ := NewManager(Config{ZerbDir: t.TempDir()})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := manager.VerifyActivation(ctx, ShellBash)
+	if err == nil || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("VerifyActivation() error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("VerifyActivation() took %v, want it to stop at the deadline", elapsed)
+	}
+}
+
+func TestVerifyActivation_Unsupported(t *testing.T) {
+	manager, _ := NewManager(Config{ZerbDir: t.TempDir()})
+
+	if _, err := manager.VerifyActivation(context.Background(), ShellElvish); !errors.Is(err, ErrVerifyUnsupported) {
+		t.Errorf("VerifyActivation(elvish) error = %v, want ErrVerifyUnsupported", err)
+	}
+	if _, err := manager.VerifyActivation(context.Background(), ShellUnknown); err == nil {
+		t.Error("VerifyActivation(unknown) should fail")
+	}
+}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package shell
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detachTerminal starts cmd in a new session, without a controlling
+// terminal, so the interactive shell cannot take over the user's terminal
+func detachTerminal(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
@@ -1,10 +1,16 @@
 package transaction
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
 	"time"
 )
 
@@ -24,8 +30,39 @@ type Lock struct {
 	file *os.File
 }
 
+// lockInfo is the holder metadata written into a lock file
+type lockInfo struct {
+	PID       int
+	Host      string
+	Timestamp time.Time // When the lock was acquired
+}
+
+// processAlive reports whether a process with the given PID exists.
+// Replaced in tests.
+var processAlive = func(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		// Windows looks the process up here
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	// Signal 0 checks for existence without signalling; EPERM means the
+	// process exists but belongs to another user
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// reclaimCounter makes the names of reclaimed locks unique within a process
+var reclaimCounter atomic.Int64
+
 // AcquireLock attempts to acquire an exclusive lock for config operations.
-// Uses O_CREATE|O_EXCL for atomic lock creation.
+// Uses O_CREATE|O_EXCL for atomic lock creation, so of several concurrent
+// attempts exactly one wins. The lock records the holder's PID, host and
+// start time. A lock whose holder process no longer exists on this host is
+// stale and reclaimed automatically; a lock from another host, or one whose
+// metadata cannot be read, is stale once older than StaleLockThreshold.
 func AcquireLock(dir string) (*Lock, error) {
 	if err := os.MkdirAll(dir, 0700); err != nil {
 		return nil, fmt.Errorf("create lock directory: %w", err)
@@ -36,25 +73,24 @@ func AcquireLock(dir string) (*Lock, error) {
 	// Try to create lock file exclusively
 	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0600)
 	if err != nil {
-		if os.IsExist(err) {
-			// Lock exists - check if it's stale
-			if isStale, _ := isLockStale(lockPath); isStale {
-				// Remove stale lock and retry once
-				os.Remove(lockPath)
-				file, err = os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0600)
-				if err != nil {
-					return nil, ErrLockExists
-				}
-			} else {
-				return nil, ErrLockExists
-			}
-		} else {
+		if !os.IsExist(err) {
 			return nil, fmt.Errorf("create lock file: %w", err)
 		}
+
+		// Lock exists - reclaim it if stale and retry once
+		held, isStale := checkLock(lockPath)
+		if !isStale || !reclaimLock(lockPath, held) {
+			return nil, lockExistsError(held)
+		}
+		file, err = os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0600)
+		if err != nil {
+			return nil, lockExistsError(nil)
+		}
 	}
 
-	// Write lock metadata (PID and timestamp)
-	lockData := fmt.Sprintf("pid=%d\ntimestamp=%s\n", os.Getpid(), time.Now().UTC().Format(time.RFC3339))
+	// Write lock metadata (PID, host and timestamp)
+	host, _ := os.Hostname()
+	lockData := fmt.Sprintf("pid=%d\nhost=%s\ntimestamp=%s\n", os.Getpid(), host, time.Now().UTC().Format(time.RFC3339Nano))
 	if _, err := file.WriteString(lockData); err != nil {
 		file.Close()
 		os.Remove(lockPath)
@@ -73,6 +109,94 @@ func AcquireLock(dir string) (*Lock, error) {
 	}, nil
 }
 
+// ForceUnlock removes the lock in dir whatever its state. It is the escape
+// hatch for a lock that looks held because its holder's PID was reused;
+// running it while another operation holds the lock breaks that lock.
+func ForceUnlock(dir string) error {
+	if err := os.Remove(filepath.Join(dir, "config-add.lock")); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove lock file: %w", err)
+	}
+	return nil
+}
+
+// lockExistsError describes a held lock, naming its holder when known
+func lockExistsError(held []byte) error {
+	hint := "if no other zerb command is running, run 'zerb config recover --force-unlock'"
+	info, ok := parseLockInfo(held)
+	if !ok {
+		return fmt.Errorf("%w (%s)", ErrLockExists, hint)
+	}
+	return fmt.Errorf("%w (held by PID %d since %s; %s)", ErrLockExists, info.PID, info.Timestamp.Local().Format(time.DateTime), hint)
+}
+
+// checkLock reads a held lock and reports whether it is stale. The
+// returned content identifies the lock when it is reclaimed.
+func checkLock(lockPath string) ([]byte, bool) {
+	held, err := os.ReadFile(lockPath)
+	if err != nil {
+		// Released meanwhile; the retry decides
+		return nil, os.IsNotExist(err)
+	}
+
+	host, _ := os.Hostname()
+	if info, ok := parseLockInfo(held); ok && info.Host == host {
+		return held, !processAlive(info.PID)
+	}
+
+	// Another host's PIDs cannot be checked, and a lock being created has
+	// no metadata yet; fall back to the lock's age
+	isStale, _ := isLockStale(lockPath)
+	return held, isStale
+}
+
+// reclaimLock removes a stale lock, but only the one that was checked. It is
+// renamed away first, so of several processes reclaiming it only one
+// succeeds; a lock acquired since the check is put back.
+func reclaimLock(lockPath string, held []byte) bool {
+	if held == nil {
+		return true
+	}
+
+	tmp := fmt.Sprintf("%s.stale-%d-%d", lockPath, os.Getpid(), reclaimCounter.Add(1))
+	if err := os.Rename(lockPath, tmp); err != nil {
+		return false
+	}
+	defer os.Remove(tmp)
+
+	current, err := os.ReadFile(tmp)
+	if err != nil || !bytes.Equal(current, held) {
+		_ = os.Link(tmp, lockPath)
+		return false
+	}
+	return true
+}
+
+// parseLockInfo parses lock metadata; ok is false unless the PID and
+// timestamp are present. Locks from before the host was recorded have an
+// empty Host.
+func parseLockInfo(data []byte) (info lockInfo, ok bool) {
+	var hasPID, hasTimestamp bool
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "pid":
+			pid, err := strconv.Atoi(value)
+			hasPID = err == nil && pid > 0
+			info.PID = pid
+		case "host":
+			info.Host = value
+		case "timestamp":
+			t, err := time.Parse(time.RFC3339Nano, value)
+			hasTimestamp = err == nil
+			info.Timestamp = t
+		}
+	}
+	return info, hasPID && hasTimestamp
+}
+
 // Release releases the lock.
 func (l *Lock) Release() error {
 	if l.file != nil {
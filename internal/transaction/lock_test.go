@@ -0,0 +1,202 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeLock writes a lock file with content, optionally backdated
+func writeLock(t *testing.T, dir, content string, age time.Duration) {
+	t.Helper()
+	lockPath := filepath.Join(dir, "config-add.lock")
+	if err := os.WriteFile(lockPath, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write lock: %v", err)
+	}
+	if age > 0 {
+		old := time.Now().Add(-age)
+		if err := os.Chtimes(lockPath, old, old); err != nil {
+			t.Fatalf("failed to backdate lock: %v", err)
+		}
+	}
+}
+
+// stubProcesses makes processAlive report only the given PIDs as running
+func stubProcesses(t *testing.T, alive ...int) {
+	t.Helper()
+	orig := processAlive
+	processAlive = func(pid int) bool {
+		for _, p := range alive {
+			if p == pid {
+				return true
+			}
+		}
+		return false
+	}
+	t.Cleanup(func() { processAlive = orig })
+}
+
+func TestAcquireLock_Release(t *testing.T) {
+	dir := t.TempDir()
+
+	lock, err := AcquireLock(dir)
+	if err != nil {
+		t.Fatalf("AcquireLock() error = %v", err)
+	}
+
+	// The holder is recorded and checked against the running process
+	data, err := os.ReadFile(filepath.Join(dir, "config-add.lock"))
+	if err != nil {
+		t.Fatalf("failed to read lock: %v", err)
+	}
+	info, ok := parseLockInfo(data)
+	if !ok || info.PID != os.Getpid() || info.Timestamp.IsZero() {
+		t.Errorf("lock metadata = %+v (ok %v), want this process", info, ok)
+	}
+
+	_, err = AcquireLock(dir)
+	if !errors.Is(err, ErrLockExists) {
+		t.Fatalf("second AcquireLock() error = %v, want ErrLockExists", err)
+	}
+	if !strings.Contains(err.Error(), fmt.Sprintf("PID %d", os.Getpid())) {
+		t.Errorf("error %q does not name the holder", err)
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Errorf("second Release() error = %v", err)
+	}
+
+	lock, err = AcquireLock(dir)
+	if err != nil {
+		t.Fatalf("AcquireLock() after Release error = %v", err)
+	}
+	lock.Release()
+}
+
+func TestAcquireLock_Stale(t *testing.T) {
+	host, err := os.Hostname()
+	if err != nil {
+		t.Fatalf("Hostname() error = %v", err)
+	}
+	stamp := time.Now().UTC().Format(time.RFC3339Nano)
+
+	tests := []struct {
+		name      string
+		content   string
+		age       time.Duration
+		reclaimed bool
+	}{
+		{name: "holder exited", content: fmt.Sprintf("pid=4242\nhost=%s\ntimestamp=%s\n", host, stamp), reclaimed: true},
+		{name: "holder running", content: fmt.Sprintf("pid=4343\nhost=%s\ntimestamp=%s\n", host, stamp), age: time.Hour},
+		{name: "other host, recent", content: fmt.Sprintf("pid=4242\nhost=elsewhere\ntimestamp=%s\n", stamp)},
+		{name: "other host, old", content: fmt.Sprintf("pid=4242\nhost=elsewhere\ntimestamp=%s\n", stamp), age: time.Hour, reclaimed: true},
+		{name: "no host recorded, recent", content: fmt.Sprintf("pid=4242\ntimestamp=%s\n", stamp)},
+		{name: "being written", content: ""},
+		{name: "unreadable, old", content: "garbage", age: time.Hour, reclaimed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubProcesses(t, 4343)
+			dir := t.TempDir()
+			writeLock(t, dir, tt.content, tt.age)
+
+			lock, err := AcquireLock(dir)
+			if tt.reclaimed {
+				if err != nil {
+					t.Fatalf("AcquireLock() error = %v, want stale lock reclaimed", err)
+				}
+				lock.Release()
+				return
+			}
+			if !errors.Is(err, ErrLockExists) {
+				t.Fatalf("AcquireLock() error = %v, want ErrLockExists", err)
+			}
+			if data, _ := os.ReadFile(filepath.Join(dir, "config-add.lock")); string(data) != tt.content {
+				t.Errorf("held lock changed to %q", data)
+			}
+		})
+	}
+}
+
+func TestAcquireLock_Concurrent(t *testing.T) {
+	host, err := os.Hostname()
+	if err != nil {
+		t.Fatalf("Hostname() error = %v", err)
+	}
+	stubProcesses(t, os.Getpid())
+
+	for _, stale := range []bool{false, true} {
+		for round := 0; round < 50; round++ {
+			dir := t.TempDir()
+			if stale {
+				writeLock(t, dir, fmt.Sprintf("pid=4242\nhost=%s\ntimestamp=%s\n", host, time.Now().UTC().Format(time.RFC3339Nano)), 0)
+			}
+
+			var (
+				wg    sync.WaitGroup
+				start = make(chan struct{})
+				locks = make([]*Lock, 2)
+				errs  = make([]error, 2)
+			)
+			for i := range locks {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					<-start
+					locks[i], errs[i] = AcquireLock(dir)
+				}(i)
+			}
+			close(start)
+			wg.Wait()
+
+			winners := 0
+			for i, err := range errs {
+				switch {
+				case err == nil:
+					winners++
+					defer locks[i].Release()
+				case !errors.Is(err, ErrLockExists):
+					t.Fatalf("AcquireLock() error = %v", err)
+				}
+			}
+			if winners != 1 {
+				t.Fatalf("stale=%v round %d: %d goroutines acquired the lock, want exactly 1", stale, round, winners)
+			}
+
+			// Nothing but the winner's lock is left behind
+			entries, _ := os.ReadDir(dir)
+			if len(entries) != 1 {
+				t.Fatalf("stale=%v round %d: lock directory has %d entries, want 1", stale, round, len(entries))
+			}
+		}
+	}
+}
+
+func TestForceUnlock(t *testing.T) {
+	stubProcesses(t, 4343)
+	dir := t.TempDir()
+	writeLock(t, dir, "pid=4343\nhost=reused\ntimestamp=2025-01-01T00:00:00Z\n", 0)
+
+	if _, err := AcquireLock(dir); !errors.Is(err, ErrLockExists) {
+		t.Fatalf("AcquireLock() error = %v, want ErrLockExists", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := ForceUnlock(dir); err != nil {
+			t.Fatalf("ForceUnlock() error = %v", err)
+		}
+	}
+	lock, err := AcquireLock(dir)
+	if err != nil {
+		t.Fatalf("AcquireLock() after ForceUnlock error = %v", err)
+	}
+	lock.Release()
+}
@@ -0,0 +1,95 @@
+package transaction
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// RecoveryAction is what recovering the last transaction does.
+type RecoveryAction string
+
+const (
+	// RecoveryNone means there is no interrupted transaction to recover.
+	RecoveryNone RecoveryAction = "none"
+	// RecoveryRollBack means untracking the paths the transaction added and
+	// discarding the config snapshot it started to write.
+	RecoveryRollBack RecoveryAction = "roll-back"
+	// RecoveryResume means activating and committing the config snapshot
+	// the transaction wrote after every path was added.
+	RecoveryResume RecoveryAction = "resume"
+)
+
+// RecoveryReport describes the last transaction and how to recover it.
+type RecoveryReport struct {
+	Txn    *ConfigAddTxn // nil if there is no transaction
+	File   string        // Transaction file
+	Action RecoveryAction
+
+	Completed []PathTxn // Paths added to the configuration manager
+	Failed    []PathTxn // Paths that failed or were interrupted while being added
+	Pending   []PathTxn // Paths the transaction never started
+}
+
+// Undo returns the paths a roll back untracks: every path that was started,
+// since an interrupted add may have created source files.
+func (r *RecoveryReport) Undo() []PathTxn {
+	paths := make([]PathTxn, 0, len(r.Completed)+len(r.Failed))
+	paths = append(paths, r.Completed...)
+	return append(paths, r.Failed...)
+}
+
+// Recover loads the newest transaction in txnDir and decides how to recover
+// it. It only reads state; the caller performs the action and then calls
+// Finish on the transaction, so running recovery twice finds nothing to do.
+//
+// The policy is to resume a transaction that added every path and wrote its
+// config snapshot, since only activation and the commit are missing, and to
+// roll back any other unfinished transaction. Committed transactions need
+// no recovery.
+func Recover(txnDir string) (*RecoveryReport, error) {
+	files, err := filepath.Glob(filepath.Join(txnDir, "txn-config-add-*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("list transactions: %w", err)
+	}
+
+	report := &RecoveryReport{Action: RecoveryNone}
+	for _, file := range files {
+		txn, err := Load(file)
+		if err != nil {
+			return nil, fmt.Errorf("load %s: %w", filepath.Base(file), err)
+		}
+		if report.Txn == nil || txn.Timestamp.After(report.Txn.Timestamp) {
+			report.Txn = txn
+			report.File = file
+		}
+	}
+	if report.Txn == nil {
+		return report, nil
+	}
+
+	for _, p := range report.Txn.Paths {
+		switch p.State {
+		case StateCompleted:
+			report.Completed = append(report.Completed, p)
+		case StateFailed, StateInProgress:
+			report.Failed = append(report.Failed, p)
+		default:
+			report.Pending = append(report.Pending, p)
+		}
+	}
+
+	switch txn := report.Txn; {
+	case txn.GitCommitted:
+		// Finished
+	case txn.ConfigUpdated && txn.ConfigFile == "":
+		// Recorded by a version that kept finished --no-commit
+		// transactions and did not name the snapshot; nothing is known
+		// to be missing
+	case txn.ConfigUpdated && txn.AllPathsCompleted():
+		report.Action = RecoveryResume
+	default:
+		report.Action = RecoveryRollBack
+	}
+
+	return report, nil
+}
@@ -0,0 +1,160 @@
+package transaction
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecover(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(txn *ConfigAddTxn)
+		want   RecoveryAction
+		counts [3]int // completed, failed, pending
+	}{
+		{
+			name:   "interrupted before any path",
+			setup:  func(txn *ConfigAddTxn) {},
+			want:   RecoveryRollBack,
+			counts: [3]int{0, 0, 2},
+		},
+		{
+			name: "failed path",
+			setup: func(txn *ConfigAddTxn) {
+				txn.UpdatePathState("~/.zshrc", StateCompleted, nil, nil)
+				txn.UpdatePathState("~/.vimrc", StateFailed, nil, os.ErrPermission)
+			},
+			want:   RecoveryRollBack,
+			counts: [3]int{1, 1, 0},
+		},
+		{
+			name: "interrupted while writing the config",
+			setup: func(txn *ConfigAddTxn) {
+				txn.UpdatePathState("~/.zshrc", StateCompleted, nil, nil)
+				txn.UpdatePathState("~/.vimrc", StateInProgress, nil, nil)
+				txn.ConfigFile = "zerb.20250102T000000.000Z.lua"
+			},
+			want:   RecoveryRollBack,
+			counts: [3]int{1, 1, 0},
+		},
+		{
+			name: "interrupted before the commit",
+			setup: func(txn *ConfigAddTxn) {
+				txn.UpdatePathState("~/.zshrc", StateCompleted, nil, nil)
+				txn.UpdatePathState("~/.vimrc", StateCompleted, nil, nil)
+				txn.ConfigFile = "zerb.20250102T000000.000Z.lua"
+				txn.ConfigUpdated = true
+			},
+			want:   RecoveryResume,
+			counts: [3]int{2, 0, 0},
+		},
+		{
+			name: "committed",
+			setup: func(txn *ConfigAddTxn) {
+				txn.UpdatePathState("~/.zshrc", StateCompleted, nil, nil)
+				txn.UpdatePathState("~/.vimrc", StateCompleted, nil, nil)
+				txn.ConfigFile = "zerb.20250102T000000.000Z.lua"
+				txn.ConfigUpdated = true
+				txn.GitCommitted = true
+			},
+			want:   RecoveryNone,
+			counts: [3]int{2, 0, 0},
+		},
+		{
+			name: "updated config without a recorded snapshot",
+			setup: func(txn *ConfigAddTxn) {
+				txn.UpdatePathState("~/.zshrc", StateCompleted, nil, nil)
+				txn.UpdatePathState("~/.vimrc", StateCompleted, nil, nil)
+				txn.ConfigUpdated = true
+			},
+			want:   RecoveryNone,
+			counts: [3]int{2, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			txn := New([]string{"~/.zshrc", "~/.vimrc"}, nil)
+			tt.setup(txn)
+			if err := txn.Save(dir); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			report, err := Recover(dir)
+			if err != nil {
+				t.Fatalf("Recover() error = %v", err)
+			}
+			if report.Action != tt.want {
+				t.Errorf("Action = %s, want %s", report.Action, tt.want)
+			}
+			if report.Txn == nil || report.Txn.ID != txn.ID || report.File != txn.File(dir) {
+				t.Fatalf("Recover() loaded %+v from %s, want %s", report.Txn, report.File, txn.ID)
+			}
+			got := [3]int{len(report.Completed), len(report.Failed), len(report.Pending)}
+			if got != tt.counts {
+				t.Errorf("completed/failed/pending = %v, want %v", got, tt.counts)
+			}
+			if len(report.Undo()) != got[0]+got[1] {
+				t.Errorf("Undo() = %v, want completed and failed paths", report.Undo())
+			}
+		})
+	}
+}
+
+func TestRecover_NewestTransaction(t *testing.T) {
+	dir := t.TempDir()
+
+	old := New([]string{"~/.zshrc"}, nil)
+	old.Timestamp = time.Now().Add(-time.Hour)
+	newest := New([]string{"~/.vimrc"}, nil)
+	for _, txn := range []*ConfigAddTxn{newest, old} {
+		if err := txn.Save(dir); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	report, err := Recover(dir)
+	if err != nil {
+		t.Fatalf("Recover() error = %v", err)
+	}
+	if report.Txn == nil || report.Txn.ID != newest.ID {
+		t.Fatalf("Recover() loaded %+v, want the newest transaction", report.Txn)
+	}
+
+	// Once finished, the older transaction is next; finishing twice is fine
+	for i := 0; i < 2; i++ {
+		if err := newest.Finish(dir); err != nil {
+			t.Fatalf("Finish() error = %v", err)
+		}
+	}
+	report, err = Recover(dir)
+	if err != nil {
+		t.Fatalf("Recover() error = %v", err)
+	}
+	if report.Txn == nil || report.Txn.ID != old.ID {
+		t.Fatalf("Recover() after Finish loaded %+v, want the older transaction", report.Txn)
+	}
+}
+
+func TestRecover_Empty(t *testing.T) {
+	for _, dir := range []string{t.TempDir(), filepath.Join(t.TempDir(), "missing")} {
+		report, err := Recover(dir)
+		if err != nil {
+			t.Fatalf("Recover(%s) error = %v", dir, err)
+		}
+		if report.Action != RecoveryNone || report.Txn != nil {
+			t.Errorf("Recover(%s) = %+v, want nothing to recover", dir, report)
+		}
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "txn-config-add-broken.json"), []byte("{"), 0600); err != nil {
+		t.Fatalf("failed to write transaction: %v", err)
+	}
+	if _, err := Recover(dir); err == nil {
+		t.Error("Recover() with a corrupt transaction error = nil, want error")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/ZebulonRouseFrantzich/zerb/internal/fsutil"
 	"github.com/google/uuid"
 )
 
@@ -29,6 +30,7 @@ type ConfigAddTxn struct {
 	Operation     string    `json:"operation"`
 	Timestamp     time.Time `json:"timestamp"`
 	Paths         []PathTxn `json:"paths"`
+	ConfigFile    string    `json:"config_file,omitempty"` // Snapshot filename in configs/, recorded before it is written
 	ConfigUpdated bool      `json:"config_updated"`
 	GitCommitted  bool      `json:"git_committed"`
 }
@@ -41,7 +43,8 @@ type PathTxn struct {
 	Template           bool     `json:"template"`
 	Secrets            bool     `json:"secrets"`
 	Private            bool     `json:"private"`
-	CreatedSourceFiles []string `json:"created_source_files"` // For cleanup on abort
+	Target             string   `json:"target,omitempty"`
+	CreatedSourceFiles []string `json:"created_source_files"` // Relative to the ZERB directory; for cleanup on abort
 	LastError          string   `json:"last_error,omitempty"`
 }
 
@@ -58,6 +61,7 @@ func New(paths []string, opts map[string]AddOptions) *ConfigAddTxn {
 			Template:           opt.Template,
 			Secrets:            opt.Secrets,
 			Private:            opt.Private,
+			Target:             opt.Target,
 			CreatedSourceFiles: []string{},
 		})
 	}
@@ -80,6 +84,7 @@ type AddOptions struct {
 	Template  bool
 	Secrets   bool
 	Private   bool
+	Target    string
 }
 
 // Save writes the transaction to disk atomically.
@@ -89,9 +94,7 @@ func (t *ConfigAddTxn) Save(dir string) error {
 		return fmt.Errorf("create transaction directory: %w", err)
 	}
 
-	filename := fmt.Sprintf("txn-config-add-%s.json", t.ID)
-	finalPath := filepath.Join(dir, filename)
-	tmpPath := finalPath + ".tmp"
+	finalPath := t.File(dir)
 
 	// Marshal to JSON
 	data, err := json.MarshalIndent(t, "", "  ")
@@ -99,27 +102,26 @@ func (t *ConfigAddTxn) Save(dir string) error {
 		return fmt.Errorf("marshal transaction: %w", err)
 	}
 
-	// Write to temporary file
-	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
-		return fmt.Errorf("write temporary transaction file: %w", err)
+	// Atomic write (temp file, fsync, rename, directory fsync)
+	if err := fsutil.WriteFileAtomic(finalPath, data, 0600); err != nil {
+		return fmt.Errorf("write transaction file: %w", err)
 	}
 
-	// Atomic rename
-	if err := os.Rename(tmpPath, finalPath); err != nil {
-		os.Remove(tmpPath) // Clean up temp file on error
-		return fmt.Errorf("rename transaction file: %w", err)
-	}
+	return nil
+}
 
-	// Sync directory for durability
-	df, err := os.Open(dir)
-	if err == nil {
-		if syncErr := df.Sync(); syncErr != nil {
-			df.Close()
-			return fmt.Errorf("sync directory: %w", syncErr)
-		}
-		df.Close()
-	}
+// File returns the path the transaction is saved to in dir.
+func (t *ConfigAddTxn) File(dir string) string {
+	return filepath.Join(dir, fmt.Sprintf("txn-config-add-%s.json", t.ID))
+}
 
+// Finish removes the transaction file once the transaction has been
+// committed or recovered, so it is not recovered again. Removing a file
+// that is already gone is not an error.
+func (t *ConfigAddTxn) Finish(dir string) error {
+	if err := os.Remove(t.File(dir)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove transaction file: %w", err)
+	}
 	return nil
 }
 